@@ -0,0 +1,298 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+
+	v1 "go.k6.io/k6/api/v1"
+	"go.k6.io/k6/metrics"
+)
+
+// The quantiles that are exposed for the Trend metrics.
+var prometheusQuantiles = []float64{0.5, 0.9, 0.95, 0.99} //nolint:gochecknoglobals
+
+// handlePrometheusMetrics exposes the metrics engine's per-time-series sinks
+// in the Prometheus text or OpenMetrics exposition format, depending on the
+// Accept header of the request.
+//
+// The k6 metrics are mapped to Prometheus metrics with a `k6_` prefix:
+// Counters are exposed as counters, Gauges as gauges, Rates as `_rate` gauges
+// and Trends as summaries. Time values are converted from milliseconds to
+// seconds and the metric names get a `_seconds` suffix. The metric tags are
+// used as labels, so they contain only the enabled system tags.
+func handlePrometheusMetrics(cs *v1.ControlSurface) http.Handler {
+	warnings := &prometheusWarnings{seen: make(map[string]struct{})}
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if cs.MetricsEngine == nil || !cs.MetricsEngine.TimeSeriesSinksEnabled() {
+			rw.Header().Add("Content-Type", "text/plain; charset=utf-8")
+			rw.WriteHeader(http.StatusNotFound)
+			_, _ = rw.Write([]byte(
+				"To enable the Prometheus metrics endpoint, please run k6 with the --prometheus-enabled flag",
+			))
+			return
+		}
+
+		cs.MetricsEngine.MetricsLock.Lock()
+		families := newPrometheusMetricFamilies(cs.MetricsEngine.VisitTimeSeriesSinks, func(msg string) {
+			warnings.warn(cs.RunState.Logger, msg)
+		})
+		cs.MetricsEngine.MetricsLock.Unlock()
+
+		format := expfmt.NegotiateIncludingOpenMetrics(r.Header)
+		rw.Header().Set("Content-Type", string(format))
+		encoder := expfmt.NewEncoder(rw, format)
+		for _, family := range families {
+			if err := encoder.Encode(family); err != nil {
+				cs.RunState.Logger.WithError(err).Error("Error while encoding the Prometheus metrics")
+				return
+			}
+		}
+		if closer, ok := encoder.(expfmt.Closer); ok {
+			if err := closer.Close(); err != nil {
+				cs.RunState.Logger.WithError(err).Error("Error while encoding the Prometheus metrics")
+			}
+		}
+	})
+}
+
+// prometheusWarnings logs each warning only the first time it happens, since
+// the same name collisions are found again on every scrape.
+type prometheusWarnings struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+func (w *prometheusWarnings) warn(logger logrus.FieldLogger, msg string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.seen[msg]; ok {
+		return
+	}
+	w.seen[msg] = struct{}{}
+	logger.Warn(msg)
+}
+
+// newPrometheusMetricFamilies converts the time series sinks to sorted
+// Prometheus metric families.
+//
+// Distinct k6 metric or tag names can end up with the same Prometheus name,
+// e.g. the `my-tag` and `my_tag` tags or a `reqs` counter and a `reqs_total`
+// gauge, and Prometheus rejects duplicate label names and series. In that
+// case, only the entry with the first k6 name in the lexicographical order is
+// exposed and the others are skipped with a warning.
+func newPrometheusMetricFamilies(
+	visit func(func(metrics.TimeSeries, metrics.Sink)), warn func(string),
+) []*dto.MetricFamily {
+	familiesByName := make(map[string]*prometheusFamily)
+	visit(func(ts metrics.TimeSeries, sink metrics.Sink) {
+		name, metricType := prometheusMetricName(ts.Metric)
+		family, ok := familiesByName[name]
+		if ok && family.metricName != ts.Metric.Name {
+			first, second := family.metricName, ts.Metric.Name
+			if second < first {
+				first, second = second, first
+			}
+			warn(fmt.Sprintf("The k6 metrics '%s' and '%s' have the same Prometheus name '%s', "+
+				"only '%s' is exposed", first, second, name, first))
+			if ts.Metric.Name > family.metricName {
+				return
+			}
+			ok = false
+		}
+		if !ok {
+			family = &prometheusFamily{
+				MetricFamily: &dto.MetricFamily{
+					Name: proto.String(name),
+					Help: proto.String("k6 " + ts.Metric.Type.String() + " metric " + ts.Metric.Name),
+					Type: metricType.Enum(),
+				},
+				metricName: ts.Metric.Name,
+				series:     make(map[string]prometheusSeries),
+			}
+			familiesByName[name] = family
+		}
+		family.add(ts, newPrometheusMetric(ts, sink, warn), warn)
+	})
+
+	families := make([]*dto.MetricFamily, 0, len(familiesByName))
+	for _, family := range familiesByName {
+		for _, series := range family.series {
+			family.Metric = append(family.Metric, series.metric)
+		}
+		sort.Slice(family.Metric, func(i, j int) bool {
+			return labelsString(family.Metric[i].Label) < labelsString(family.Metric[j].Label)
+		})
+		families = append(families, family.MetricFamily)
+	}
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].GetName() < families[j].GetName()
+	})
+	return families
+}
+
+// prometheusFamily is a metric family with the k6 metric it's created from,
+// and its series by their labels, which are used for detecting collisions.
+type prometheusFamily struct {
+	*dto.MetricFamily
+	metricName string
+	series     map[string]prometheusSeries
+}
+
+type prometheusSeries struct {
+	metric *dto.Metric
+	tags   string
+}
+
+// add adds the metric to the family, unless it has the same labels as another
+// series of the family, because some of their tags were sanitized to the same
+// label names.
+func (f *prometheusFamily) add(ts metrics.TimeSeries, pm *dto.Metric, warn func(string)) {
+	labels := labelsString(pm.Label)
+	tags := tagsString(ts.Tags.Map())
+	if existing, ok := f.series[labels]; ok {
+		first, second := existing.tags, tags
+		if second < first {
+			first, second = second, first
+		}
+		warn(fmt.Sprintf("The '%s' metric's series with the tags {%s} and {%s} have the same Prometheus labels, "+
+			"only the one with the tags {%s} is exposed", f.metricName, first, second, first))
+		if tags > existing.tags {
+			return
+		}
+	}
+	f.series[labels] = prometheusSeries{metric: pm, tags: tags}
+}
+
+func newPrometheusMetric(ts metrics.TimeSeries, sink metrics.Sink, warn func(string)) *dto.Metric {
+	pm := &dto.Metric{}
+	tags := ts.Tags.Map()
+	tagNames := make([]string, 0, len(tags))
+	for name := range tags {
+		tagNames = append(tagNames, name)
+	}
+	sort.Strings(tagNames)
+	tagsByLabel := make(map[string]string, len(tags))
+	for _, name := range tagNames {
+		label := sanitizePrometheusName(name)
+		if other, ok := tagsByLabel[label]; ok {
+			warn(fmt.Sprintf("The '%s' metric's tags '%s' and '%s' have the same Prometheus label name '%s', "+
+				"only '%s' is exposed", ts.Metric.Name, other, name, label, other))
+			continue
+		}
+		tagsByLabel[label] = name
+		pm.Label = append(pm.Label, &dto.LabelPair{
+			Name:  proto.String(label),
+			Value: proto.String(tags[name]),
+		})
+	}
+	sort.Slice(pm.Label, func(i, j int) bool { return pm.Label[i].GetName() < pm.Label[j].GetName() })
+
+	scale := 1.0
+	if ts.Metric.Contains == metrics.Time {
+		scale = 0.001 // k6 records the time values in milliseconds
+	}
+
+	switch s := sink.(type) {
+	case *metrics.CounterSink:
+		pm.Counter = &dto.Counter{Value: proto.Float64(s.Value * scale)}
+	case *metrics.GaugeSink:
+		pm.Gauge = &dto.Gauge{Value: proto.Float64(s.Value * scale)}
+	case *metrics.RateSink:
+		var rate float64
+		if s.Total > 0 {
+			rate = float64(s.Trues) / float64(s.Total)
+		}
+		pm.Gauge = &dto.Gauge{Value: proto.Float64(rate)}
+	case *metrics.TrendSink:
+		summary := &dto.Summary{
+			SampleCount: proto.Uint64(s.Count()),
+			SampleSum:   proto.Float64(s.Total() * scale),
+		}
+		for _, q := range prometheusQuantiles {
+			summary.Quantile = append(summary.Quantile, &dto.Quantile{
+				Quantile: proto.Float64(q),
+				Value:    proto.Float64(s.P(q) * scale),
+			})
+		}
+		pm.Summary = summary
+	}
+	return pm
+}
+
+// prometheusMetricName returns the exposed name and type for the k6 metric.
+func prometheusMetricName(m *metrics.Metric) (string, dto.MetricType) {
+	name := "k6_" + sanitizePrometheusName(m.Name)
+	if m.Contains == metrics.Time {
+		name += "_seconds"
+	}
+
+	switch m.Type {
+	case metrics.Counter:
+		return name + "_total", dto.MetricType_COUNTER
+	case metrics.Rate:
+		return name + "_rate", dto.MetricType_GAUGE
+	case metrics.Trend:
+		return name, dto.MetricType_SUMMARY
+	default:
+		return name, dto.MetricType_GAUGE
+	}
+}
+
+// sanitizePrometheusName replaces all characters that are not allowed in the
+// Prometheus metric and label names with underscores.
+func sanitizePrometheusName(name string) string {
+	var sb strings.Builder
+	sb.Grow(len(name))
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			sb.WriteRune(r)
+		case r >= '0' && r <= '9' && i > 0:
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
+
+func tagsString(tags map[string]string) string {
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(name)
+		sb.WriteString(": ")
+		sb.WriteString(tags[name])
+	}
+	return sb.String()
+}
+
+func labelsString(labels []*dto.LabelPair) string {
+	var sb strings.Builder
+	for _, l := range labels {
+		sb.WriteString(l.GetName())
+		sb.WriteByte('=')
+		sb.WriteString(l.GetValue())
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "go.k6.io/k6/api/v1"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/metrics/engine"
+)
+
+func getPrometheusControlSurface(t *testing.T, seriesLimit int) *v1.ControlSurface {
+	t.Helper()
+
+	registry := metrics.NewRegistry()
+	logger := testutils.NewLogger(t)
+	me, err := engine.NewMetricsEngine(registry, logger)
+	require.NoError(t, err)
+	if seriesLimit > 0 {
+		me.EnableTimeSeriesSinks(seriesLimit)
+	}
+
+	return &v1.ControlSurface{
+		MetricsEngine: me,
+		RunState: &lib.TestRunState{
+			TestPreInitState: &lib.TestPreInitState{Registry: registry, Logger: logger},
+		},
+	}
+}
+
+func addPrometheusTestSamples(t *testing.T, cs *v1.ControlSurface) {
+	t.Helper()
+
+	registry := cs.RunState.Registry
+	reqs, err := registry.NewMetric("http_reqs", metrics.Counter)
+	require.NoError(t, err)
+	duration, err := registry.NewMetric("http_req_duration", metrics.Trend, metrics.Time)
+	require.NoError(t, err)
+	failed, err := registry.NewMetric("http_req_failed", metrics.Rate)
+	require.NoError(t, err)
+	vus, err := registry.NewMetric("vus", metrics.Gauge)
+	require.NoError(t, err)
+
+	var samples metrics.Samples
+	for _, status := range []string{"200", "200", "500"} {
+		tags := registry.RootTagSet().WithTagsFromMap(map[string]string{"status": status, "my-tag": "a"})
+		samples = append(samples,
+			metrics.Sample{TimeSeries: metrics.TimeSeries{Metric: reqs, Tags: tags}, Value: 1},
+			metrics.Sample{TimeSeries: metrics.TimeSeries{Metric: duration, Tags: tags}, Value: 200},
+			metrics.Sample{TimeSeries: metrics.TimeSeries{Metric: failed, Tags: tags}, Value: 0},
+		)
+	}
+	samples = append(samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{Metric: vus, Tags: registry.RootTagSet()},
+		Value:      5,
+	})
+
+	ingester := cs.MetricsEngine.CreateIngester()
+	require.NoError(t, ingester.Start())
+	ingester.AddMetricSamples([]metrics.SampleContainer{samples})
+	require.NoError(t, ingester.Stop())
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	t.Parallel()
+
+	cs := getPrometheusControlSurface(t, 100)
+	addPrometheusTestSamples(t, cs)
+
+	t.Run("text", func(t *testing.T) {
+		t.Parallel()
+
+		rw := httptest.NewRecorder()
+		handlePrometheusMetrics(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		res := rw.Result()
+		assert.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", res.Header.Get("Content-Type"))
+
+		body := rw.Body.String()
+		assert.Contains(t, body, "# TYPE k6_http_reqs_total counter\n")
+		assert.Contains(t, body, `k6_http_reqs_total{my_tag="a",status="200"} 2`+"\n")
+		assert.Contains(t, body, `k6_http_reqs_total{my_tag="a",status="500"} 1`+"\n")
+		assert.Contains(t, body, "# TYPE k6_http_req_duration_seconds summary\n")
+		assert.Contains(t, body, `k6_http_req_duration_seconds{my_tag="a",status="200",quantile="0.95"} 0.2`+"\n")
+		assert.Contains(t, body, `k6_http_req_duration_seconds_sum{my_tag="a",status="200"} 0.4`+"\n")
+		assert.Contains(t, body, `k6_http_req_duration_seconds_count{my_tag="a",status="200"} 2`+"\n")
+		assert.Contains(t, body, "# TYPE k6_http_req_failed_rate gauge\n")
+		assert.Contains(t, body, `k6_http_req_failed_rate{my_tag="a",status="500"} 0`+"\n")
+		assert.Contains(t, body, "k6_vus 5\n")
+	})
+
+	t.Run("openmetrics", func(t *testing.T) {
+		t.Parallel()
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("Accept", "application/openmetrics-text; version=0.0.1")
+		handlePrometheusMetrics(cs).ServeHTTP(rw, req)
+		res := rw.Result()
+		assert.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "application/openmetrics-text; version=0.0.1; charset=utf-8", res.Header.Get("Content-Type"))
+
+		body := rw.Body.String()
+		assert.Contains(t, body, "# TYPE k6_http_reqs counter\n")
+		assert.Contains(t, body, `k6_http_reqs_total{my_tag="a",status="200"} 2.0`+"\n")
+		assert.Contains(t, body, "# EOF\n")
+	})
+}
+
+func TestPrometheusMetricsSeriesLimit(t *testing.T) {
+	t.Parallel()
+
+	cs := getPrometheusControlSurface(t, 3)
+	addPrometheusTestSamples(t, cs)
+
+	rw := httptest.NewRecorder()
+	handlePrometheusMetrics(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	// The first 3 time series are exposed, everything else is aggregated
+	// per metric in the overflow time series.
+	body := rw.Body.String()
+	assert.Contains(t, body, `k6_http_reqs_total{my_tag="a",status="200"} 2`+"\n")
+	assert.Contains(t, body, `k6_http_req_duration_seconds_count{my_tag="a",status="200"} 2`+"\n")
+	assert.Contains(t, body, `k6_http_req_failed_rate{my_tag="a",status="200"} 0`+"\n")
+	assert.Contains(t, body, `k6_http_reqs_total{k6_series_overflow="true"} 1`+"\n")
+	assert.Contains(t, body, `k6_http_req_duration_seconds_count{k6_series_overflow="true"} 1`+"\n")
+	assert.Contains(t, body, `k6_vus{k6_series_overflow="true"} 5`+"\n")
+	assert.NotContains(t, body, `status="500"`)
+}
+
+func TestPrometheusMetricsDisabled(t *testing.T) {
+	t.Parallel()
+
+	cs := getPrometheusControlSurface(t, 0)
+
+	rw := httptest.NewRecorder()
+	handlePrometheusMetrics(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+	assert.Contains(t, rw.Body.String(), "--prometheus-enabled")
+
+	rw = httptest.NewRecorder()
+	handlePrometheusMetrics(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+}
+
+func TestPrometheusMetricsNameCollisions(t *testing.T) {
+	t.Parallel()
+
+	cs := getPrometheusControlSurface(t, 100)
+	logger, logHook := testutils.NewLoggerWithHook(t, logrus.WarnLevel)
+	cs.RunState.Logger = logger
+
+	registry := cs.RunState.Registry
+	reqs, err := registry.NewMetric("reqs", metrics.Counter)
+	require.NoError(t, err)
+	// exposed as k6_reqs_total, the same as the reqs counter
+	reqsTotal, err := registry.NewMetric("reqs_total", metrics.Gauge)
+	require.NoError(t, err)
+
+	root := registry.RootTagSet()
+	samples := metrics.Samples{
+		{TimeSeries: metrics.TimeSeries{Metric: reqs, Tags: root}, Value: 1},
+		{TimeSeries: metrics.TimeSeries{Metric: reqsTotal, Tags: root}, Value: 2},
+		// a single series with tags that have the same label name
+		{TimeSeries: metrics.TimeSeries{Metric: reqs, Tags: root.With("my-tag", "a").With("my_tag", "b")}, Value: 3},
+		// two series that end up with the same labels
+		{TimeSeries: metrics.TimeSeries{Metric: reqs, Tags: root.With("other-tag", "c")}, Value: 4},
+		{TimeSeries: metrics.TimeSeries{Metric: reqs, Tags: root.With("other_tag", "c")}, Value: 5},
+	}
+	ingester := cs.MetricsEngine.CreateIngester()
+	require.NoError(t, ingester.Start())
+	ingester.AddMetricSamples([]metrics.SampleContainer{samples})
+	require.NoError(t, ingester.Stop())
+
+	handler := handlePrometheusMetrics(cs)
+	for i := 0; i < 2; i++ {
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		body := rw.Body.String()
+		assert.Contains(t, body, "# TYPE k6_reqs_total counter\n")
+		assert.Contains(t, body, "k6_reqs_total 1\n")
+		assert.NotContains(t, body, "k6_reqs_total 2\n")
+		assert.Contains(t, body, `k6_reqs_total{my_tag="a"} 3`+"\n")
+		assert.NotContains(t, body, `my_tag="b"`)
+		assert.Contains(t, body, `k6_reqs_total{other_tag="c"} 4`+"\n")
+		assert.NotContains(t, body, `k6_reqs_total{other_tag="c"} 5`)
+	}
+
+	// every collision is logged only once
+	lines := logHook.Lines()
+	assert.ElementsMatch(t, []string{
+		"The k6 metrics 'reqs' and 'reqs_total' have the same Prometheus name 'k6_reqs_total', " +
+			"only 'reqs' is exposed",
+		"The 'reqs' metric's tags 'my-tag' and 'my_tag' have the same Prometheus label name 'my_tag', " +
+			"only 'my-tag' is exposed",
+		"The 'reqs' metric's series with the tags {other-tag: c} and {other_tag: c} have the same Prometheus labels, " +
+			"only the one with the tags {other-tag: c} is exposed",
+	}, lines)
+}
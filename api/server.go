@@ -23,6 +23,7 @@ func newHandler(cs *v1.ControlSurface, profilingEnabled bool) http.Handler {
 	mux := http.NewServeMux()
 	mux.Handle("/v1/", v1.NewHandler(cs))
 	mux.Handle("/ping", handlePing(cs.RunState.Logger))
+	mux.Handle("/metrics", handlePrometheusMetrics(cs))
 	mux.Handle("/", handlePing(cs.RunState.Logger))
 
 	injectProfilerHandler(mux, profilingEnabled)
@@ -80,6 +81,14 @@ func (w *wrappedResponseWriter) WriteHeader(status int) {
 	w.ResponseWriter.WriteHeader(w.status)
 }
 
+// Flush implements http.Flusher, so the responses can be streamed, e.g. the
+// live metrics stream.
+func (w *wrappedResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 // withLoggingHandler returns the middleware which logs response status for request.
 func withLoggingHandler(l logrus.FieldLogger, next http.Handler) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
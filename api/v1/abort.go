@@ -0,0 +1,14 @@
+package v1
+
+import (
+	"gopkg.in/guregu/null.v3"
+)
+
+// Abort contains the optional details of a test run abort from the REST API.
+type Abort struct {
+	// Reason is a message that explains why the test run was aborted.
+	Reason string `json:"reason" yaml:"reason"`
+	// ExitCode is the exit code k6 should exit with, instead of the default
+	// one for tests stopped from the REST API.
+	ExitCode null.Int `json:"exit-code" yaml:"exit-code"`
+}
@@ -0,0 +1,28 @@
+package v1
+
+// AbortJSONAPI is JSON API envelop for the test run abort details
+type AbortJSONAPI struct {
+	Data abortData `json:"data"`
+}
+
+// NewAbortJSONAPI creates the JSON API envelop for the test run abort details
+func NewAbortJSONAPI(a Abort) AbortJSONAPI {
+	return AbortJSONAPI{
+		Data: abortData{
+			ID:         "default",
+			Type:       "abort",
+			Attributes: a,
+		},
+	}
+}
+
+// Abort extract the v1.Abort from the JSON API envelop
+func (a AbortJSONAPI) Abort() Abort {
+	return a.Data.Attributes
+}
+
+type abortData struct {
+	Type       string `json:"type"`
+	ID         string `json:"id"`
+	Attributes Abort  `json:"attributes"`
+}
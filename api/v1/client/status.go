@@ -31,3 +31,27 @@ func (c *Client) SetStatus(ctx context.Context, patch v1.Status) (ret v1.Status,
 
 	return resp.Status(), nil
 }
+
+// Stop gracefully stops the test run and returns the new status.
+func (c *Client) Stop(ctx context.Context) (ret v1.Status, err error) {
+	var resp v1.StatusJSONAPI
+
+	if err = c.CallAPI(ctx, http.MethodPost, &url.URL{Path: "/v1/stop"}, nil, &resp); err != nil {
+		return ret, err
+	}
+
+	return resp.Status(), nil
+}
+
+// Abort immediately aborts the test run with the given details and returns
+// the new status.
+func (c *Client) Abort(ctx context.Context, abort v1.Abort) (ret v1.Status, err error) {
+	var resp v1.StatusJSONAPI
+
+	apiURL := &url.URL{Path: "/v1/abort"}
+	if err = c.CallAPI(ctx, http.MethodPost, apiURL, v1.NewAbortJSONAPI(abort), &resp); err != nil {
+		return ret, err
+	}
+
+	return resp.Status(), nil
+}
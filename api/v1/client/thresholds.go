@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	v1 "go.k6.io/k6/api/v1"
+)
+
+// Thresholds returns the current thresholds of all metrics.
+func (c *Client) Thresholds(ctx context.Context) (ret []v1.Thresholds, err error) {
+	var resp v1.ThresholdsListJSONAPI
+
+	err = c.CallAPI(ctx, http.MethodGet, &url.URL{Path: "/v1/thresholds"}, nil, &resp)
+	if err != nil {
+		return ret, err
+	}
+
+	return resp.Thresholds(), nil
+}
+
+// SetThresholds creates or replaces the thresholds of a metric and returns
+// them if it was successful.
+func (c *Client) SetThresholds(ctx context.Context, thresholds v1.Thresholds) (ret v1.Thresholds, err error) {
+	var resp v1.ThresholdsJSONAPI
+
+	apiURL := &url.URL{Path: "/v1/thresholds/" + thresholds.Name}
+	if err = c.CallAPI(ctx, http.MethodPut, apiURL, v1.NewThresholdsJSONAPI(thresholds), &resp); err != nil {
+		return ret, err
+	}
+
+	return resp.Thresholds(), nil
+}
+
+// DeleteThresholds removes the thresholds of a metric.
+func (c *Client) DeleteThresholds(ctx context.Context, name string) error {
+	return c.CallAPI(ctx, http.MethodDelete, &url.URL{Path: "/v1/thresholds/" + name}, nil, nil)
+}
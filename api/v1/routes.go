@@ -79,5 +79,55 @@ func NewHandler(cs *ControlSurface) http.Handler {
 		handleRunTeardown(cs, rw, r)
 	})
 
+	mux.HandleFunc("/v1/stop", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		handleStop(cs, rw, r)
+	})
+
+	mux.HandleFunc("/v1/abort", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		handleAbort(cs, rw, r)
+	})
+
+	mux.HandleFunc("/v1/thresholds", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		handleGetThresholds(cs, rw, r)
+	})
+
+	mux.HandleFunc("/v1/thresholds/", func(rw http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len("/v1/thresholds/"):]
+		switch r.Method {
+		case http.MethodGet:
+			handleGetMetricThresholds(cs, rw, r, id)
+		case http.MethodPut:
+			handleSetMetricThresholds(cs, rw, r, id)
+		case http.MethodDelete:
+			handleDeleteMetricThresholds(cs, rw, r, id)
+		default:
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/v1/stream", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		handleGetStream(cs, rw, r)
+	})
+
 	return mux
 }
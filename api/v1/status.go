@@ -16,6 +16,16 @@ type Status struct {
 	Stopped bool      `json:"stopped" yaml:"stopped"`
 	Running bool      `json:"running" yaml:"running"`
 	Tainted bool      `json:"tainted" yaml:"tainted"`
+
+	ArrivalRate *ArrivalRateStatus `json:"arrival-rate,omitempty" yaml:"arrival-rate,omitempty"`
+}
+
+// ArrivalRateStatus represents the current configuration of the
+// externally-controlled-arrival-rate executor, if one is configured.
+type ArrivalRateStatus struct {
+	Rate            null.Int `json:"rate" yaml:"rate"`
+	PreAllocatedVUs null.Int `json:"pre-allocated-vus" yaml:"pre-allocated-vus"`
+	MaxVUs          null.Int `json:"max-vus" yaml:"max-vus"`
 }
 
 func newStatus(cs *ControlSurface) Status {
@@ -26,7 +36,7 @@ func newStatus(cs *ControlSurface) Status {
 		isStopped = true
 	default:
 	}
-	return Status{
+	status := Status{
 		Status:  executionState.GetCurrentExecutionStatus(),
 		Running: executionState.HasStarted() && !executionState.HasEnded(),
 		Paused:  null.BoolFrom(executionState.IsPaused()),
@@ -35,4 +45,13 @@ func newStatus(cs *ControlSurface) Status {
 		VUsMax:  null.IntFrom(executionState.GetInitializedVUsCount()),
 		Tainted: cs.MetricsEngine.GetMetricsWithBreachedThresholdsCount() > 0,
 	}
+	if executor, err := getFirstExternallyControlledArrivalRateExecutor(cs.Scheduler); err == nil {
+		config := executor.GetCurrentConfig()
+		status.ArrivalRate = &ArrivalRateStatus{
+			Rate:            config.Rate,
+			PreAllocatedVUs: config.PreAllocatedVUs,
+			MaxVUs:          config.MaxVUs,
+		}
+	}
+	return status
 }
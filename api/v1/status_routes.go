@@ -34,6 +34,19 @@ func getFirstExternallyControlledExecutor(execScheduler *execution.Scheduler) (*
 	return nil, errors.New("an externally-controlled executor needs to be configured for live configuration updates")
 }
 
+func getFirstExternallyControlledArrivalRateExecutor(
+	execScheduler *execution.Scheduler,
+) (*executor.ExternallyControlledArrivalRate, error) {
+	executors := execScheduler.GetExecutors()
+	for _, s := range executors {
+		if mex, ok := s.(*executor.ExternallyControlledArrivalRate); ok {
+			return mex, nil
+		}
+	}
+	return nil, errors.New(
+		"an externally-controlled-arrival-rate executor needs to be configured for live arrival rate updates")
+}
+
 func handlePatchStatus(cs *ControlSurface, rw http.ResponseWriter, r *http.Request) {
 	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
 
@@ -88,6 +101,28 @@ func handlePatchStatus(cs *ControlSurface, rw http.ResponseWriter, r *http.Reque
 				return
 			}
 		}
+
+		if status.ArrivalRate != nil {
+			executor, updateErr := getFirstExternallyControlledArrivalRateExecutor(cs.Scheduler)
+			if updateErr != nil {
+				apiError(rw, "Execution config error", updateErr.Error(), http.StatusInternalServerError)
+				return
+			}
+			newConfig := executor.GetCurrentConfig().ExternallyControlledArrivalRateConfigParams
+			if status.ArrivalRate.Rate.Valid {
+				newConfig.Rate = status.ArrivalRate.Rate
+			}
+			if status.ArrivalRate.PreAllocatedVUs.Valid {
+				newConfig.PreAllocatedVUs = status.ArrivalRate.PreAllocatedVUs
+			}
+			if status.ArrivalRate.MaxVUs.Valid {
+				newConfig.MaxVUs = status.ArrivalRate.MaxVUs
+			}
+			if updateErr := executor.UpdateConfig(r.Context(), newConfig); updateErr != nil {
+				apiError(rw, "Config update error", updateErr.Error(), http.StatusBadRequest)
+				return
+			}
+		}
 	}
 
 	data, err := json.Marshal(newStatusJSONAPIFromEngine(cs))
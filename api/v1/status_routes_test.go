@@ -102,6 +102,18 @@ func TestPatchStatus(t *testing.T) {
 			ExpectedStatus:     Status{VUs: null.IntFrom(10), VUsMax: null.IntFrom(10)},
 			Payload:            []byte(`{"data":{"type":"status","id":"default","attributes":{"status":0,"paused":null,"vus":10,"vus-max":10,"stopped":false,"running":false,"tainted":false}}}`),
 		},
+		"arrival rate": {
+			ExpectedStatusCode: 200,
+			ExpectedStatus: Status{ArrivalRate: &ArrivalRateStatus{
+				Rate: null.IntFrom(20), PreAllocatedVUs: null.IntFrom(2), MaxVUs: null.IntFrom(5),
+			}},
+			Payload: []byte(`{"data":{"type":"status","id":"default","attributes":{"status":0,"paused":null,"vus":null,"vus-max":null,"stopped":false,"running":false,"tainted":false,"arrival-rate":{"rate":20,"pre-allocated-vus":2,"max-vus":5}}}}`),
+		},
+		"arrival rate max vus below pre-allocated": {
+			ExpectedStatusCode: 400,
+			ExpectedStatus:     Status{ArrivalRate: &ArrivalRateStatus{PreAllocatedVUs: null.IntFrom(2)}},
+			Payload:            []byte(`{"data":{"type":"status","id":"default","attributes":{"status":0,"paused":null,"vus":null,"vus-max":null,"stopped":false,"running":false,"tainted":false,"arrival-rate":{"rate":null,"pre-allocated-vus":2,"max-vus":null}}}}`),
+		},
 	}
 
 	for name, testCase := range testData {
@@ -112,7 +124,9 @@ func TestPatchStatus(t *testing.T) {
 			scenarios := lib.ScenarioConfigs{}
 			err := json.Unmarshal([]byte(`
 			{"external": {"executor": "externally-controlled",
-			"vus": 0, "maxVUs": 10, "duration": "0"}}`), &scenarios)
+			"vus": 0, "maxVUs": 10, "duration": "0"},
+			"external-arrival-rate": {"executor": "externally-controlled-arrival-rate",
+			"rate": 0, "preAllocatedVUs": 0, "maxVUs": 0, "duration": "0"}}`), &scenarios)
 			require.NoError(t, err)
 
 			testState := getTestRunState(t, lib.Options{Scenarios: scenarios}, &minirunner.MiniRunner{})
@@ -186,6 +200,9 @@ func TestPatchStatus(t *testing.T) {
 			if testCase.ExpectedStatus.VUsMax.Valid {
 				assert.Equal(t, testCase.ExpectedStatus.VUsMax, status.VUsMax)
 			}
+			if testCase.ExpectedStatus.ArrivalRate != nil {
+				assert.Equal(t, testCase.ExpectedStatus.ArrivalRate, status.ArrivalRate)
+			}
 		})
 	}
 }
@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/execution"
+)
+
+// handleStop gracefully stops the test run, i.e. the iterations in progress
+// can finish within the gracefulStop of their scenarios.
+func handleStop(cs *ControlSurface, rw http.ResponseWriter, _ *http.Request) {
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if err := cs.Scheduler.StopGracefully(); err != nil {
+		apiError(rw, "Stop error", err.Error(), http.StatusConflict)
+		return
+	}
+
+	data, err := json.Marshal(newStatusJSONAPIFromEngine(cs))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
+
+// handleAbort immediately aborts the test run, with the reason and the exit
+// code from the request body, if they are specified.
+func handleAbort(cs *ControlSurface, rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		apiError(rw, "Couldn't read request", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var abortEnvelop AbortJSONAPI
+	if len(body) > 0 {
+		if err = json.Unmarshal(body, &abortEnvelop); err != nil {
+			apiError(rw, "Invalid data", err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	abort := abortEnvelop.Abort()
+
+	exitCode := exitcodes.ScriptStoppedFromRESTAPI
+	if abort.ExitCode.Valid {
+		if abort.ExitCode.Int64 < 1 || abort.ExitCode.Int64 > 255 {
+			apiError(rw, "Invalid data", "the exit code should be between 1 and 255", http.StatusBadRequest)
+			return
+		}
+		exitCode = exitcodes.ExitCode(abort.ExitCode.Int64)
+	}
+
+	abortErr := errors.New("test run aborted from REST API")
+	if abort.Reason != "" {
+		abortErr = fmt.Errorf("test run aborted from REST API: %s", abort.Reason)
+	}
+	execution.AbortTestRun( //nolint:contextcheck // false-positive cs.RunCtx a right way of passing context there
+		cs.RunCtx,
+		errext.WithAbortReasonIfNone(errext.WithExitCodeIfNone(abortErr, exitCode), errext.AbortedByUser),
+	)
+
+	data, err := json.Marshal(newStatusJSONAPIFromEngine(cs))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
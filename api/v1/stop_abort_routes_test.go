@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/minirunner"
+)
+
+func TestStop(t *testing.T) {
+	t.Parallel()
+
+	cs := getControlSurface(t, getTestRunState(t, lib.Options{}, &minirunner.MiniRunner{}))
+
+	rw := httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/v1/stop", nil))
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.True(t, cs.Scheduler.GetState().IsStoppedGracefully())
+	assert.NoError(t, cs.RunCtx.Err(), "the test run shouldn't be aborted")
+
+	rw = httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/v1/stop", nil))
+	assert.Equal(t, http.StatusConflict, rw.Code)
+
+	rw = httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/stop", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+}
+
+func TestAbort(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		payload       string
+		expStatusCode int
+		expMessage    string
+		expExitCode   exitcodes.ExitCode
+		expNotAborted bool
+	}{
+		"default": {
+			expStatusCode: http.StatusOK,
+			expMessage:    "test run aborted from REST API",
+			expExitCode:   exitcodes.ScriptStoppedFromRESTAPI,
+		},
+		"custom": {
+			payload:       `{"data":{"type":"abort","id":"default","attributes":{"reason":"server is down","exit-code":3}}}`,
+			expStatusCode: http.StatusOK,
+			expMessage:    "test run aborted from REST API: server is down",
+			expExitCode:   3,
+		},
+		"invalid exit code": {
+			payload:       `{"data":{"type":"abort","id":"default","attributes":{"exit-code":256}}}`,
+			expStatusCode: http.StatusBadRequest,
+			expNotAborted: true,
+		},
+		"invalid data": {
+			payload:       `{"data":`,
+			expStatusCode: http.StatusBadRequest,
+			expNotAborted: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cs := getControlSurface(t, getTestRunState(t, lib.Options{}, &minirunner.MiniRunner{}))
+
+			rw := httptest.NewRecorder()
+			NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(
+				http.MethodPost, "/v1/abort", bytes.NewBufferString(tc.payload),
+			))
+			require.Equal(t, tc.expStatusCode, rw.Code)
+
+			reason := execution.GetCancelReasonIfTestAborted(cs.RunCtx)
+			if tc.expNotAborted {
+				assert.NoError(t, reason)
+				return
+			}
+			require.EqualError(t, reason, tc.expMessage)
+			var errWithExitCode errext.HasExitCode
+			require.True(t, errors.As(reason, &errWithExitCode))
+			assert.Equal(t, tc.expExitCode, errWithExitCode.ExitCode())
+
+			var statusEnvelop StatusJSONAPI
+			require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &statusEnvelop))
+			assert.True(t, statusEnvelop.Status().Stopped)
+		})
+	}
+}
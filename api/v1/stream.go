@@ -0,0 +1,43 @@
+package v1
+
+import (
+	"time"
+
+	"go.k6.io/k6/event"
+)
+
+// StreamMetrics is the data of the metrics events of the live stream. It
+// contains the aggregated values of the samples of every selected metric that
+// had any in the last interval.
+type StreamMetrics struct {
+	Time            time.Time                     `json:"time"`
+	TestRunDuration float64                       `json:"test_run_duration"` // in milliseconds
+	Metrics         map[string]map[string]float64 `json:"metrics"`
+}
+
+// StreamEvent is the data of the execution events of the live stream, which
+// are named after the event type, e.g. TestStart or ScenarioEnd.
+type StreamEvent struct {
+	Time     time.Time `json:"time"`
+	Scenario string    `json:"scenario,omitempty"`
+	Executor string    `json:"executor,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// streamEventTypes are the types of the events from the event system that are
+// sent to the live stream.
+//
+//nolint:gochecknoglobals
+var streamEventTypes = []event.Type{event.TestStart, event.TestEnd, event.ScenarioStart, event.ScenarioEnd}
+
+func newStreamEvent(evt *event.Event) StreamEvent {
+	streamEvent := StreamEvent{Time: time.Now()}
+	if data, ok := evt.Data.(*event.ScenarioData); ok {
+		streamEvent.Scenario = data.Name
+		streamEvent.Executor = data.Executor
+		if data.Error != nil {
+			streamEvent.Error = data.Error.Error()
+		}
+	}
+	return streamEvent
+}
@@ -0,0 +1,144 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.k6.io/k6/event"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	defaultStreamInterval = time.Second
+	minStreamInterval     = 100 * time.Millisecond
+)
+
+type streamedMetric struct {
+	name string
+	read func() metrics.Sink
+}
+
+// handleGetStream streams the aggregated values of the selected metrics every
+// interval, and the execution events, as server-sent events. The metrics are
+// selected with the metric query parameters, which can be sub-metrics, e.g.
+// `?metric=http_reqs&metric=http_req_duration{status:200}`. The stream ends
+// when the client disconnects or when the test run ends.
+func handleGetStream(cs *ControlSurface, rw http.ResponseWriter, r *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		apiError(rw, "Streaming error", "the response can't be streamed", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	interval := defaultStreamInterval
+	if value := query.Get("interval"); value != "" {
+		var err error
+		if interval, err = time.ParseDuration(value); err != nil {
+			apiError(rw, "Invalid interval", err.Error(), http.StatusBadRequest)
+			return
+		}
+		if interval < minStreamInterval {
+			apiError(rw, "Invalid interval", fmt.Sprintf("the interval should be at least %s", minStreamInterval),
+				http.StatusBadRequest)
+			return
+		}
+	}
+
+	metricNames := query["metric"]
+	if len(metricNames) > 0 && cs.RunState.LiveMetrics == nil {
+		apiError(rw, "Streaming error", "the metrics can't be streamed if both the end-of-test summary "+
+			"and the thresholds are disabled, since they aren't processed", http.StatusBadRequest)
+		return
+	}
+	streamedMetrics := make([]streamedMetric, 0, len(metricNames))
+	for _, name := range metricNames {
+		read, stop, err := cs.MetricsEngine.ObserveMetricWithStop(name)
+		if err != nil {
+			apiError(rw, "Invalid metric", err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer stop()
+		streamedMetrics = append(streamedMetrics, streamedMetric{name: name, read: read})
+	}
+
+	var events <-chan *event.Event
+	if cs.RunState.Events != nil {
+		var subID uint64
+		subID, events = cs.RunState.Events.Subscribe(streamEventTypes...)
+		defer func() {
+			cs.RunState.Events.Unsubscribe(subID)
+			for evt := range events {
+				evt.Done() // so the emitter doesn't wait for the events that weren't sent
+			}
+		}()
+	}
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	lastTime := time.Now()
+	writeMetrics := func() error {
+		now := time.Now()
+		data := StreamMetrics{
+			Time:            now,
+			TestRunDuration: float64(cs.Scheduler.GetState().GetCurrentTestRunDuration()) / float64(time.Millisecond),
+			Metrics:         make(map[string]map[string]float64, len(streamedMetrics)),
+		}
+		for _, m := range streamedMetrics {
+			if sink := m.read(); !sink.IsEmpty() {
+				data.Metrics[m.name] = sink.Format(now.Sub(lastTime))
+			}
+		}
+		lastTime = now
+		return writeStreamEvent(rw, flusher, "metrics", data)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if len(streamedMetrics) > 0 {
+				err = writeMetrics()
+			}
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			if evt.Type == event.TestEnd && len(streamedMetrics) > 0 {
+				err = writeMetrics()
+			}
+			if err == nil {
+				err = writeStreamEvent(rw, flusher, evt.Type.String(), newStreamEvent(evt))
+			}
+			evt.Done()
+			if evt.Type == event.TestEnd {
+				return
+			}
+		}
+		if err != nil {
+			cs.RunState.Logger.WithError(err).Debug("Couldn't write to the REST API's live stream")
+			return
+		}
+	}
+}
+
+func writeStreamEvent(rw http.ResponseWriter, flusher http.Flusher, name string, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", name, encoded); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
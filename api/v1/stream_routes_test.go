@@ -0,0 +1,157 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/event"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/minirunner"
+	"go.k6.io/k6/metrics"
+)
+
+type testStreamEvent struct {
+	name string
+	data string
+}
+
+// readTestStreamEvents sends the server-sent events of the response body to
+// the returned channel, until the stream ends.
+func readTestStreamEvents(t *testing.T, resp *http.Response) <-chan testStreamEvent {
+	t.Helper()
+	events := make(chan testStreamEvent, 100)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var evt testStreamEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				evt.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				evt.data = strings.TrimPrefix(line, "data: ")
+			case line == "":
+				events <- evt
+				evt = testStreamEvent{}
+			}
+		}
+	}()
+	return events
+}
+
+func TestGetStream(t *testing.T) {
+	t.Parallel()
+
+	testState := getTestRunState(t, lib.Options{}, &minirunner.MiniRunner{})
+	testState.Events = event.NewEventSystem(10, testState.Logger)
+	cs := getControlSurface(t, testState)
+	testState.LiveMetrics = cs.MetricsEngine
+	ingester := cs.MetricsEngine.CreateIngester()
+	require.NoError(t, ingester.Start())
+
+	srv := httptest.NewServer(NewHandler(cs))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/v1/stream?metric=http_reqs&metric=http_reqs{status:500}&interval=100ms")
+	require.NoError(t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	events := readTestStreamEvents(t, resp)
+
+	emit := func(evt *event.Event) {
+		waitDone := testState.Events.Emit(evt)
+		require.NoError(t, waitDone(context.Background()))
+	}
+	emit(&event.Event{Type: event.TestStart})
+	emit(&event.Event{
+		Type: event.ScenarioEnd,
+		Data: &event.ScenarioData{Name: "default", Executor: "shared-iterations", Error: errors.New("oops")},
+	})
+
+	ingester.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: testState.BuiltinMetrics.HTTPReqs,
+			Tags:   testState.Registry.RootTagSet().With("status", "200"),
+		},
+		Time:  time.Now(),
+		Value: 1,
+	}})
+	require.NoError(t, ingester.Stop())
+
+	evt := <-events
+	assert.Equal(t, "TestStart", evt.name)
+	evt = <-events
+	assert.Equal(t, "ScenarioEnd", evt.name)
+	var scenarioEvent StreamEvent
+	require.NoError(t, json.Unmarshal([]byte(evt.data), &scenarioEvent))
+	assert.Equal(t, "default", scenarioEvent.Scenario)
+	assert.Equal(t, "shared-iterations", scenarioEvent.Executor)
+	assert.Equal(t, "oops", scenarioEvent.Error)
+
+	evt = <-events
+	assert.Equal(t, "metrics", evt.name)
+	var metricsEvent StreamMetrics
+	require.NoError(t, json.Unmarshal([]byte(evt.data), &metricsEvent))
+	require.Contains(t, metricsEvent.Metrics, "http_reqs")
+	assert.Equal(t, float64(1), metricsEvent.Metrics["http_reqs"]["count"])
+	assert.NotContains(t, metricsEvent.Metrics, "http_reqs{status:500}", "there weren't any samples")
+
+	emit(&event.Event{Type: event.TestEnd})
+	for evt = range events {
+		if evt.name != "metrics" {
+			break
+		}
+	}
+	assert.Equal(t, "TestEnd", evt.name)
+	_, ok := <-events
+	assert.False(t, ok, "the stream should end with the test run")
+}
+
+func TestGetStreamErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		query       string
+		liveMetrics bool
+		expStatus   int
+	}{
+		"invalid interval":  {query: "interval=1x", liveMetrics: true, expStatus: http.StatusBadRequest},
+		"too short":         {query: "interval=10ms", liveMetrics: true, expStatus: http.StatusBadRequest},
+		"unknown metric":    {query: "metric=foo", liveMetrics: true, expStatus: http.StatusBadRequest},
+		"no metrics engine": {query: "metric=http_reqs", expStatus: http.StatusBadRequest},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cs := getControlSurface(t, getTestRunState(t, lib.Options{}, &minirunner.MiniRunner{}))
+			if tc.liveMetrics {
+				cs.RunState.LiveMetrics = cs.MetricsEngine
+			}
+			rw := httptest.NewRecorder()
+			NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/stream?"+tc.query, nil))
+			assert.Equal(t, tc.expStatus, rw.Code)
+		})
+	}
+
+	cs := getControlSurface(t, getTestRunState(t, lib.Options{}, &minirunner.MiniRunner{}))
+	rw := httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/v1/stream", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+}
@@ -0,0 +1,26 @@
+package v1
+
+import (
+	"go.k6.io/k6/metrics"
+)
+
+// Thresholds represents the thresholds of a single metric or sub-metric.
+type Thresholds struct {
+	// Name is the metric or sub-metric name, e.g. `http_req_duration{status:200}`.
+	Name string `json:"-" yaml:"name"`
+	// Thresholds are in the same format as in the thresholds option.
+	Thresholds metrics.Thresholds `json:"thresholds" yaml:"thresholds"`
+	// Tainted is true if any of the thresholds failed in their last evaluation.
+	Tainted bool `json:"tainted" yaml:"tainted"`
+}
+
+// NewThresholds returns the v1 representation of the given thresholds.
+func NewThresholds(name string, thresholds metrics.Thresholds) Thresholds {
+	tainted := false
+	for _, t := range thresholds.Thresholds {
+		if t.LastFailed {
+			tainted = true
+		}
+	}
+	return Thresholds{Name: name, Thresholds: thresholds, Tainted: tainted}
+}
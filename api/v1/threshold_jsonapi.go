@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"sort"
+
+	"go.k6.io/k6/metrics"
+)
+
+// ThresholdsJSONAPI is JSON API envelop for the thresholds of a single metric
+type ThresholdsJSONAPI struct {
+	Data thresholdsData `json:"data"`
+}
+
+// ThresholdsListJSONAPI is JSON API envelop for the thresholds of all metrics
+type ThresholdsListJSONAPI struct {
+	Data []thresholdsData `json:"data"`
+}
+
+type thresholdsData struct {
+	Type       string     `json:"type"`
+	ID         string     `json:"id"`
+	Attributes Thresholds `json:"attributes"`
+}
+
+// NewThresholdsJSONAPI creates the JSON API envelop for the thresholds of a
+// single metric
+func NewThresholdsJSONAPI(t Thresholds) ThresholdsJSONAPI {
+	return ThresholdsJSONAPI{Data: newThresholdsData(t)}
+}
+
+func newThresholdsListJSONAPI(list map[string]metrics.Thresholds) ThresholdsListJSONAPI {
+	names := make([]string, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	data := make([]thresholdsData, 0, len(list))
+	for _, name := range names {
+		data = append(data, newThresholdsData(NewThresholds(name, list[name])))
+	}
+	return ThresholdsListJSONAPI{Data: data}
+}
+
+func newThresholdsData(t Thresholds) thresholdsData {
+	return thresholdsData{
+		Type:       "thresholds",
+		ID:         t.Name,
+		Attributes: t,
+	}
+}
+
+// Thresholds extract the v1.Thresholds from the JSON API envelop
+func (t ThresholdsJSONAPI) Thresholds() Thresholds {
+	thresholds := t.Data.Attributes
+	thresholds.Name = t.Data.ID
+	return thresholds
+}
+
+// Thresholds extract the []v1.Thresholds from the JSON API envelop
+func (t ThresholdsListJSONAPI) Thresholds() []Thresholds {
+	list := make([]Thresholds, 0, len(t.Data))
+	for _, d := range t.Data {
+		thresholds := d.Attributes
+		thresholds.Name = d.ID
+		list = append(list, thresholds)
+	}
+	return list
+}
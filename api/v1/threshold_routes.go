@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+func handleGetThresholds(cs *ControlSurface, rw http.ResponseWriter, _ *http.Request) {
+	data, err := json.Marshal(newThresholdsListJSONAPI(cs.MetricsEngine.GetThresholds()))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
+
+func handleGetMetricThresholds(cs *ControlSurface, rw http.ResponseWriter, _ *http.Request, id string) {
+	name, thresholds, err := cs.MetricsEngine.GetMetricThresholds(id)
+	if err != nil {
+		apiError(rw, "Not Found", err.Error(), http.StatusNotFound)
+		return
+	}
+	data, err := json.Marshal(NewThresholdsJSONAPI(NewThresholds(name, thresholds)))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
+
+// handleSetMetricThresholds creates or replaces the thresholds of a metric,
+// they are evaluated by the metrics engine from then on.
+func handleSetMetricThresholds(cs *ControlSurface, rw http.ResponseWriter, r *http.Request, id string) {
+	if cs.RunState.RuntimeOptions.NoThresholds.Bool {
+		apiError(rw, "Thresholds error", "the thresholds are disabled with --no-thresholds", http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		apiError(rw, "Couldn't read request", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var envelop ThresholdsJSONAPI
+	if err = json.Unmarshal(body, &envelop); err != nil {
+		apiError(rw, "Invalid data", err.Error(), http.StatusBadRequest)
+		return
+	}
+	thresholds := envelop.Thresholds().Thresholds
+	if len(thresholds.Thresholds) == 0 {
+		apiError(rw, "Invalid data", "no thresholds were specified", http.StatusBadRequest)
+		return
+	}
+
+	name, err := cs.MetricsEngine.SetThresholds(id, thresholds)
+	if err != nil {
+		apiError(rw, "Invalid thresholds", err.Error(), http.StatusBadRequest)
+		return
+	}
+	handleGetMetricThresholds(cs, rw, r, name)
+}
+
+func handleDeleteMetricThresholds(cs *ControlSurface, rw http.ResponseWriter, _ *http.Request, id string) {
+	if _, err := cs.MetricsEngine.DeleteThresholds(id); err != nil {
+		apiError(rw, "Not Found", err.Error(), http.StatusNotFound)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
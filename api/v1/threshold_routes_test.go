@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/minirunner"
+	"go.k6.io/k6/metrics"
+)
+
+func TestThresholds(t *testing.T) {
+	t.Parallel()
+
+	testState := getTestRunState(t, lib.Options{}, &minirunner.MiniRunner{})
+	_, err := testState.Registry.NewMetric("my_metric", metrics.Trend, metrics.Time)
+	require.NoError(t, err)
+	cs := getControlSurface(t, testState)
+	handler := NewHandler(cs)
+
+	serve := func(method, name, body string) *httptest.ResponseRecorder {
+		target := "/v1/thresholds"
+		if name != "" {
+			target += "/" + url.PathEscape(name)
+		}
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest(method, target, bytes.NewBufferString(body)))
+		return rw
+	}
+
+	rw := serve(http.MethodPut, "my_metric{status:200}",
+		`{"data":{"type":"thresholds","attributes":{"thresholds":["p(95)<300",{"threshold":"max<1000","abortOnFail":true}]}}}`)
+	require.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+	var envelop ThresholdsJSONAPI
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &envelop))
+	thresholds := envelop.Thresholds()
+	assert.Equal(t, "my_metric{status:200}", thresholds.Name)
+	require.Len(t, thresholds.Thresholds.Thresholds, 2)
+	assert.Equal(t, "p(95)<300", thresholds.Thresholds.Thresholds[0].Source)
+	assert.True(t, thresholds.Thresholds.Thresholds[1].AbortOnFail)
+	assert.False(t, thresholds.Tainted)
+
+	for name, body := range map[string]string{
+		"my_metric":      `{"data":{"type":"thresholds","attributes":{"thresholds":["count<5"]}}}`,
+		"other_metric":   `{"data":{"type":"thresholds","attributes":{"thresholds":["p(95)<300"]}}}`,
+		"my_metric{a:b}": `{"data":{"type":"thresholds","attributes":{"thresholds":[]}}}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, name, body).Code, name)
+	}
+
+	rw = serve(http.MethodGet, "", "")
+	require.Equal(t, http.StatusOK, rw.Code)
+	var listEnvelop ThresholdsListJSONAPI
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &listEnvelop))
+	list := listEnvelop.Thresholds()
+	require.Len(t, list, 1)
+	assert.Equal(t, "my_metric{status:200}", list[0].Name)
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "my_metric", "").Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "my_metric{status:200}", "").Code)
+
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "my_metric{status:200}", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "my_metric{status:200}", "").Code)
+	assert.Empty(t, cs.MetricsEngine.GetThresholds())
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	v1 "go.k6.io/k6/api/v1"
+	"go.k6.io/k6/api/v1/client"
+	"go.k6.io/k6/cmd/state"
+)
+
+func getCmdAbort(gs *state.GlobalState) *cobra.Command {
+	exampleText := getExampleText(gs, `
+  # Abort a running test.
+  {{.}} abort
+
+  # Abort a running test with a custom reason and exit code.
+  {{.}} abort --reason "the system under test is down" --exit-code 3`[1:])
+
+	// abortCmd represents the abort command
+	abortCmd := &cobra.Command{
+		Use:   "abort",
+		Short: "Abort a running test",
+		Long: `Abort a running test.
+
+  The iterations in progress are interrupted right away, without waiting for
+  the gracefulStop of their scenarios. k6 exits with the code 103, unless
+  another one is specified with the --exit-code flag. Use the stop command to
+  stop the test gracefully instead.
+
+  Use the global --address flag to specify the URL to the API server.`,
+		Example: exampleText,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			reason, err := cmd.Flags().GetString("reason")
+			if err != nil {
+				return err
+			}
+
+			c, err := client.New(gs.Flags.Address)
+			if err != nil {
+				return err
+			}
+			status, err := c.Abort(gs.Ctx, v1.Abort{
+				Reason:   reason,
+				ExitCode: getNullInt64(cmd.Flags(), "exit-code"),
+			})
+			if err != nil {
+				return err
+			}
+			return yamlPrint(gs.Stdout, status)
+		},
+	}
+
+	abortCmd.Flags().String("reason", "", "the reason why the test is aborted")
+	abortCmd.Flags().Int64("exit-code", 0, "the exit code k6 should exit with")
+
+	return abortCmd
+}
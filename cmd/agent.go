@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/execution/distributed"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/loader"
+)
+
+// cmdAgent handles the `k6 agent` sub-command. It reuses the `k6 run` logic,
+// but it gets the test and its execution segment from the coordinator and uses
+// it as the execution.Controller.
+type cmdAgent struct {
+	cmdRun
+
+	conn       *grpc.ClientConn
+	controller *distributed.AgentController
+}
+
+func (c *cmdAgent) loadConfiguredTest(
+	cmd *cobra.Command, args []string,
+) (*loadedAndConfiguredTest, execution.Controller, error) {
+	var err error
+	c.conn, err = grpc.Dial(args[0], grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, nil, err
+	}
+	client := distributed.NewDistributedTestClient(c.conn)
+
+	// The agents may be started before the coordinator, so we wait for it.
+	resp, err := client.Register(c.gs.Ctx, &distributed.RegisterRequest{}, grpc.WaitForReady(true))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not register with the coordinator at '%s': %w", args[0], err)
+	}
+	c.gs.Logger.Debugf("Registered with the coordinator as instance %d", resp.InstanceID)
+
+	c.controller, err = distributed.NewAgentController(c.gs.Ctx, resp.InstanceID, client, c.gs.Logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var instanceOptions lib.Options
+	if err = json.Unmarshal(resp.Options, &instanceOptions); err != nil {
+		return nil, nil, err
+	}
+
+	pwd, err := c.gs.Getwd()
+	if err != nil {
+		return nil, nil, err
+	}
+	src := &loader.SourceData{
+		URL:  &url.URL{Scheme: "file", Path: "/archive.tar"},
+		Data: resp.Archive,
+	}
+	test, err := loadTestFromSource(c.gs, cmd, src.URL.Path, src, loader.CreateFilesystems(c.gs.FS), pwd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	configuredTest, err := test.consolidateDeriveAndValidateConfig(c.gs, cmd,
+		func(flags *pflag.FlagSet) (Config, error) {
+			out, err := flags.GetStringArray("out")
+			if err != nil {
+				return Config{}, err
+			}
+			return Config{
+				Options:       instanceOptions,
+				Out:           out,
+				Linger:        getNullBool(flags, "linger"),
+				NoUsageReport: getNullBool(flags, "no-usage-report"),
+			}, nil
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return configuredTest, c.controller, nil
+}
+
+func (c *cmdAgent) run(cmd *cobra.Command, args []string) error {
+	// Multiple agents are often started on the same machine, so we don't start
+	// the REST API server unless it was explicitly requested.
+	if !cmd.Flags().Changed("address") {
+		c.gs.Flags.Address = ""
+	}
+
+	printBanner(c.gs)
+	err := c.cmdRun.run(cmd, args)
+	if c.controller != nil {
+		if cerr := c.controller.Close(); cerr != nil {
+			c.gs.Logger.WithError(cerr).Debug("Error while disconnecting from the coordinator")
+		}
+	}
+	if c.conn != nil {
+		_ = c.conn.Close()
+	}
+	return err
+}
+
+func (c *cmdAgent) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.AddFlagSet(runtimeOptionFlagSet(false))
+	flags.AddFlagSet(configFlagSet())
+	return flags
+}
+
+func getCmdAgent(gs *state.GlobalState) *cobra.Command {
+	c := &cmdAgent{cmdRun: cmdRun{gs: gs}}
+	c.cmdRun.loadConfiguredTest = c.loadConfiguredTest
+
+	exampleText := getExampleText(gs, `
+  # Join the distributed test hosted by the coordinator on localhost.
+  {{.}} agent localhost:6566`[1:])
+
+	agentCmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Join a distributed load test",
+		Long: `Join a distributed load test.
+
+The agent connects to a coordinator started with 'k6 coordinator', receives the
+test archive and its execution segment from it, and then executes its part of
+the test, synchronizing with the other agents through the coordinator.
+
+Each agent evaluates thresholds and shows the end-of-test summary only for its
+own part of the test, so an output is needed to aggregate the full results.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should be the address of the coordinator"),
+		RunE:    c.run,
+	}
+
+	agentCmd.Flags().SortFlags = false
+	agentCmd.Flags().AddFlagSet(c.flagSet())
+
+	return agentCmd
+}
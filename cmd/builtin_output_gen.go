@@ -7,11 +7,11 @@ import (
 	"strings"
 )
 
-const _builtinOutputName = "cloudcsvdatadogexperimental-prometheus-rwinfluxdbjsonkafkastatsd"
+const _builtinOutputName = "cloudcsvdatadogexperimental-prometheus-rwinfluxdbjsonkafkaopentelemetrystatsdstore"
 
-var _builtinOutputIndex = [...]uint8{0, 5, 8, 15, 41, 49, 53, 58, 64}
+var _builtinOutputIndex = [...]uint8{0, 5, 8, 15, 41, 49, 53, 58, 71, 77, 82}
 
-const _builtinOutputLowerName = "cloudcsvdatadogexperimental-prometheus-rwinfluxdbjsonkafkastatsd"
+const _builtinOutputLowerName = "cloudcsvdatadogexperimental-prometheus-rwinfluxdbjsonkafkaopentelemetrystatsdstore"
 
 func (i builtinOutput) String() string {
 	if i >= builtinOutput(len(_builtinOutputIndex)-1) {
@@ -31,10 +31,12 @@ func _builtinOutputNoOp() {
 	_ = x[builtinOutputInfluxdb-(4)]
 	_ = x[builtinOutputJSON-(5)]
 	_ = x[builtinOutputKafka-(6)]
-	_ = x[builtinOutputStatsd-(7)]
+	_ = x[builtinOutputOpentelemetry-(7)]
+	_ = x[builtinOutputStatsd-(8)]
+	_ = x[builtinOutputStore-(9)]
 }
 
-var _builtinOutputValues = []builtinOutput{builtinOutputCloud, builtinOutputCSV, builtinOutputDatadog, builtinOutputExperimentalPrometheusRW, builtinOutputInfluxdb, builtinOutputJSON, builtinOutputKafka, builtinOutputStatsd}
+var _builtinOutputValues = []builtinOutput{builtinOutputCloud, builtinOutputCSV, builtinOutputDatadog, builtinOutputExperimentalPrometheusRW, builtinOutputInfluxdb, builtinOutputJSON, builtinOutputKafka, builtinOutputOpentelemetry, builtinOutputStatsd, builtinOutputStore}
 
 var _builtinOutputNameToValueMap = map[string]builtinOutput{
 	_builtinOutputName[0:5]:        builtinOutputCloud,
@@ -51,8 +53,12 @@ var _builtinOutputNameToValueMap = map[string]builtinOutput{
 	_builtinOutputLowerName[49:53]: builtinOutputJSON,
 	_builtinOutputName[53:58]:      builtinOutputKafka,
 	_builtinOutputLowerName[53:58]: builtinOutputKafka,
-	_builtinOutputName[58:64]:      builtinOutputStatsd,
-	_builtinOutputLowerName[58:64]: builtinOutputStatsd,
+	_builtinOutputName[58:71]:      builtinOutputOpentelemetry,
+	_builtinOutputLowerName[58:71]: builtinOutputOpentelemetry,
+	_builtinOutputName[71:77]:      builtinOutputStatsd,
+	_builtinOutputLowerName[71:77]: builtinOutputStatsd,
+	_builtinOutputName[77:82]:      builtinOutputStore,
+	_builtinOutputLowerName[77:82]: builtinOutputStore,
 }
 
 var _builtinOutputNames = []string{
@@ -63,7 +69,9 @@ var _builtinOutputNames = []string{
 	_builtinOutputName[41:49],
 	_builtinOutputName[49:53],
 	_builtinOutputName[53:58],
-	_builtinOutputName[58:64],
+	_builtinOutputName[58:71],
+	_builtinOutputName[71:77],
+	_builtinOutputName[77:82],
 }
 
 // builtinOutputString retrieves an enum value from the enum constants string name.
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"net"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/execution/distributed"
+)
+
+// cmdCoordinator handles the `k6 coordinator` sub-command
+type cmdCoordinator struct {
+	gs            *state.GlobalState
+	gRPCAddress   string
+	instanceCount int
+}
+
+func (c *cmdCoordinator) run(cmd *cobra.Command, args []string) (err error) {
+	test, err := loadAndConfigureLocalTest(c.gs, cmd, args, getPartialConfig)
+	if err != nil {
+		return err
+	}
+
+	// Similar to `k6 archive`, we only set the consolidated options back to
+	// the runner, since the agents will derive the execution config themselves.
+	testRunState, err := test.buildTestRunState(test.consolidatedConfig.Options)
+	if err != nil {
+		return err
+	}
+
+	coordinator, err := distributed.NewCoordinatorServer(
+		c.instanceCount, testRunState.Runner.MakeArchive(), c.gs.Logger,
+	)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", c.gRPCAddress)
+	if err != nil {
+		return err
+	}
+
+	grpcServer := grpc.NewServer()
+	distributed.RegisterDistributedTestServer(grpcServer, coordinator)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		c.gs.Logger.Infof("Starting the gRPC server on %s, waiting for %d agents...", c.gRPCAddress, c.instanceCount)
+		serveErr <- grpcServer.Serve(listener)
+	}()
+
+	select {
+	case <-coordinator.Done():
+		c.gs.Logger.Info("All agents have finished, stopping the coordinator...")
+		grpcServer.GracefulStop()
+	case err = <-serveErr:
+		return err
+	case <-c.gs.Ctx.Done():
+		c.gs.Logger.Info("The coordinator was interrupted, stopping...")
+		grpcServer.Stop()
+		return c.gs.Ctx.Err()
+	}
+
+	return coordinator.Err()
+}
+
+func (c *cmdCoordinator) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.AddFlagSet(optionFlagSet())
+	flags.AddFlagSet(runtimeOptionFlagSet(false))
+	flags.StringVar(&c.gRPCAddress, "grpc-address", c.gRPCAddress, "address on which to bind the gRPC server")
+	flags.IntVar(&c.instanceCount, "instance-count", c.instanceCount, "number of agents that will execute the test")
+	return flags
+}
+
+func getCmdCoordinator(gs *state.GlobalState) *cobra.Command {
+	c := &cmdCoordinator{
+		gs:            gs,
+		gRPCAddress:   "localhost:6566",
+		instanceCount: 1,
+	}
+
+	exampleText := getExampleText(gs, `
+  # Coordinate a test that will be split between 3 agents.
+  {{.}} coordinator --instance-count 3 script.js
+
+  # Join the test from 3 different processes or machines.
+  {{.}} agent localhost:6566`[1:])
+
+	coordinatorCmd := &cobra.Command{
+		Use:   "coordinator",
+		Short: "Start a distributed load test",
+		Long: `Start a distributed load test.
+
+The coordinator loads the test and waits for the specified number of agents to
+connect to it with the 'k6 agent' command. Every agent receives the test
+archive and an equal execution segment of it. The coordinator then hosts the
+barriers for the test start, setup() and teardown(), and it makes sure that
+setup() and teardown() are executed only once, by one of the agents, with the
+setup() result shared with all of them.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should either be \"-\", if reading script from stdin, or a path to a script file"),
+		RunE:    c.run,
+	}
+
+	coordinatorCmd.Flags().SortFlags = false
+	coordinatorCmd.Flags().AddFlagSet(c.flagSet())
+
+	return coordinatorCmd
+}
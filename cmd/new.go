@@ -1,14 +1,18 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"path"
+	"strings"
 	"text/template"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/converter"
 	"go.k6.io/k6/lib/fsext"
 )
 
@@ -84,12 +88,16 @@ type initScriptTemplateArgs struct {
 type newScriptCmd struct {
 	gs             *state.GlobalState
 	overwriteFiles bool
+	fromHAR        string
+	fromOpenAPI    string
 }
 
 func (c *newScriptCmd) flagSet() *pflag.FlagSet {
 	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
 	flags.SortFlags = false
 	flags.BoolVarP(&c.overwriteFiles, "force", "f", false, "Overwrite existing files")
+	flags.StringVar(&c.fromHAR, "from-har", "", "Generate the script from the requests in a HAR recording")
+	flags.StringVar(&c.fromOpenAPI, "from-openapi", "", "Generate the script from an OpenAPI 3.x specification in JSON or YAML")
 
 	return flags
 }
@@ -100,6 +108,10 @@ func (c *newScriptCmd) run(cmd *cobra.Command, args []string) error { //nolint:r
 		target = args[0]
 	}
 
+	if c.fromHAR != "" && c.fromOpenAPI != "" {
+		return errors.New("only one of the `--from-har` and `--from-openapi` flags can be used")
+	}
+
 	fileExists, err := fsext.Exists(c.gs.FS, target)
 	if err != nil {
 		return err
@@ -109,17 +121,14 @@ func (c *newScriptCmd) run(cmd *cobra.Command, args []string) error { //nolint:r
 		return fmt.Errorf("%s already exists, please use the `--force` flag if you want overwrite it", target)
 	}
 
-	fd, err := c.gs.FS.Create(target)
+	// the script is generated before the file is created, so invalid inputs
+	// don't leave empty or overwritten files behind
+	script, err := c.generateScript(target)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		_ = fd.Close() // we may think to check the error and log
-	}()
 
-	if err := defaultNewScriptTemplate.Execute(fd, initScriptTemplateArgs{
-		ScriptName: path.Base(target),
-	}); err != nil {
+	if err := fsext.WriteFile(c.gs.FS, target, []byte(script), 0o644); err != nil {
 		return err
 	}
 
@@ -134,6 +143,37 @@ func (c *newScriptCmd) run(cmd *cobra.Command, args []string) error { //nolint:r
 	return nil
 }
 
+// generateScript returns the content of the new script, either from the
+// default template or converted from the HAR or OpenAPI file in the flags.
+func (c *newScriptCmd) generateScript(target string) (string, error) {
+	switch {
+	case c.fromHAR != "":
+		data, err := fsext.ReadFile(c.gs.FS, c.fromHAR)
+		if err != nil {
+			return "", err
+		}
+		har := &converter.HAR{}
+		if err := json.Unmarshal(data, har); err != nil {
+			return "", fmt.Errorf("couldn't parse the HAR recording %s: %w", c.fromHAR, err)
+		}
+		return converter.ConvertHAR(har)
+	case c.fromOpenAPI != "":
+		data, err := fsext.ReadFile(c.gs.FS, c.fromOpenAPI)
+		if err != nil {
+			return "", err
+		}
+		return converter.ConvertOpenAPI(data)
+	default:
+		var buf strings.Builder
+		if err := defaultNewScriptTemplate.Execute(&buf, initScriptTemplateArgs{
+			ScriptName: path.Base(target),
+		}); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+}
+
 func getCmdNewScript(gs *state.GlobalState) *cobra.Command {
 	c := &newScriptCmd{gs: gs}
 
@@ -145,7 +185,13 @@ func getCmdNewScript(gs *state.GlobalState) *cobra.Command {
   {{.}} new test.js
 
   # Overwrite existing test.js with a minimal k6 script
-  {{.}} new -f test.js`[1:])
+  {{.}} new -f test.js
+
+  # Generate a script that replays the requests in a HAR recording
+  {{.}} new --from-har recording.har test.js
+
+  # Generate a script that calls the operations of an OpenAPI specification
+  {{.}} new --from-openapi spec.yaml test.js`[1:])
 
 	initCmd := &cobra.Command{
 		Use:   "new",
@@ -156,6 +202,10 @@ This command will create a minimal k6 script in the current directory and
 store it in the file specified by the first argument. If no argument is
 provided, the script will be stored in script.js.
 
+Instead of the minimal script, a script can also be generated from a HAR
+recording of a browser session with --from-har, or from an OpenAPI 3.x
+specification with --from-openapi.
+
 This command will not overwrite existing files.`,
 		Example: exampleText,
 		Args:    cobra.MaximumNArgs(1),
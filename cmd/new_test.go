@@ -2,6 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -95,3 +99,120 @@ func TestNewScriptCmd_FileExists_Overwrite(t *testing.T) {
 	assert.Contains(t, string(data), "export const options = {")
 	assert.Contains(t, string(data), "export default function() {")
 }
+
+func TestNewScriptCmd_FromHAR(t *testing.T) {
+	t.Parallel()
+
+	var requests int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		switch r.URL.Path {
+		case "/":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		case "/login":
+			if c, err := r.Cookie("session"); err != nil || c.Value != "abc" || r.FormValue("user") != "admin" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Location", "/")
+			w.WriteHeader(http.StatusFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	har := fmt.Sprintf(`{"log": {
+		"pages": [{"id": "page_1", "title": "Home"}],
+		"entries": [
+			{
+				"pageref": "page_1",
+				"startedDateTime": "2024-01-01T10:00:00.000Z",
+				"request": {"method": "GET", "url": "%[1]s/", "headers": [{"name": "Accept", "value": "text/html"}]},
+				"response": {"status": 200, "cookies": [{"name": "session", "value": "abc"}]}
+			},
+			{
+				"pageref": "page_1",
+				"startedDateTime": "2024-01-01T10:00:00.100Z",
+				"request": {
+					"method": "POST", "url": "%[1]s/login",
+					"cookies": [{"name": "session", "value": "recorded"}],
+					"postData": {"mimeType": "application/x-www-form-urlencoded", "text": "user=admin"},
+					"headers": [{"name": "Content-Type", "value": "application/x-www-form-urlencoded"}]
+				},
+				"response": {"status": 302}
+			}
+		]
+	}}`, srv.URL)
+
+	ts := tests.NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, "recording.har", []byte(har), 0o644))
+	ts.CmdArgs = []string{"k6", "new", "--from-har", "recording.har", "test.js"}
+	newRootCommand(ts.GlobalState).execute()
+
+	data, err := fsext.ReadFile(ts.FS, "test.js")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `group("Home", function () {`)
+	assert.NotContains(t, string(data), `"session"`) // the session cookie is in the cookie jar
+
+	// the generated script should be runnable and its checks should pass
+	ts = tests.NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), data, 0o644))
+	ts.CmdArgs = []string{"k6", "run", "--quiet", "test.js"}
+	newRootCommand(ts.GlobalState).execute()
+
+	assert.Equal(t, int64(2), atomic.LoadInt64(&requests))
+	assert.Contains(t, ts.Stdout.String(), "checks.........................: 100.00% ✓ 2")
+}
+
+func TestNewScriptCmd_FromOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	ts := tests.NewGlobalTestState(t)
+	spec := `{"openapi": "3.0.0", "paths": {"/pets": {"get": {"responses": {"200": {}}}}}}`
+	require.NoError(t, fsext.WriteFile(ts.FS, "spec.json", []byte(spec), 0o644))
+	ts.CmdArgs = []string{"k6", "new", "--from-openapi", "spec.json"}
+	newRootCommand(ts.GlobalState).execute()
+
+	data, err := fsext.ReadFile(ts.FS, defaultNewScriptName)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "res = http.get(`${BASE_URL}/pets`);")
+}
+
+func TestNewScriptCmd_InvalidSource(t *testing.T) {
+	t.Parallel()
+
+	ts := tests.NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, "spec.yaml", []byte(`swagger: "2.0"`), 0o644))
+	ts.CmdArgs = []string{"k6", "new", "--from-openapi", "spec.yaml"}
+	ts.ExpectedExitCode = -1
+	newRootCommand(ts.GlobalState).execute()
+
+	assert.Contains(t, ts.Stderr.String(), "swagger 2.0 specifications aren't supported")
+	exists, err := fsext.Exists(ts.FS, defaultNewScriptName)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestNewScriptCmd_UnsupportedExample(t *testing.T) {
+	t.Parallel()
+
+	ts := tests.NewGlobalTestState(t)
+	spec := `
+openapi: 3.0.0
+paths:
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            example: {weight: .inf}
+`
+	require.NoError(t, fsext.WriteFile(ts.FS, "spec.yaml", []byte(spec), 0o644))
+	ts.CmdArgs = []string{"k6", "new", "--from-openapi", "spec.yaml"}
+	ts.ExpectedExitCode = -1
+	newRootCommand(ts.GlobalState).execute()
+
+	assert.Contains(t, ts.Stderr.String(), "json: unsupported value: +Inf")
+	exists, err := fsext.Exists(ts.FS, defaultNewScriptName)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -42,6 +42,7 @@ func optionFlagSet() *pflag.FlagSet {
 	flags.Lookup("http-debug").NoOptDefVal = "headers"
 	flags.Bool("insecure-skip-tls-verify", false, "skip verification of TLS certificates")
 	flags.Bool("no-connection-reuse", false, "disable keep-alive connections")
+	flags.Bool("http3", false, "send https requests over HTTP/3 (QUIC)")
 	flags.Bool("no-vu-connection-reuse", false, "don't reuse connections between iterations")
 	flags.Duration("min-iteration-duration", 0, "minimum amount of time k6 will take executing a single iteration")
 	flags.BoolP("throw", "w", false, "throw warnings (like failed http requests) as errors")
@@ -57,6 +58,8 @@ func optionFlagSet() *pflag.FlagSet {
 	)
 	flags.StringSlice("summary-trend-stats", nil, sumTrendStatsHelp)
 	flags.String("summary-time-unit", "", "define the time unit used to display the trend stats. Possible units are: 's', 'ms' and 'us'") //nolint:lll
+	flags.String("trend-sink", "", "define the sink for trend metrics, 'values' keeps all values for exact percentiles, "+
+		"'histogram' has a bounded memory usage and percentiles with less than 1% error (default 'values')")
 	// system-tags must have a default value, but we can't specify it here, otherwiese, it will always override others.
 	// set it to nil here, and add the default in applyDefault() instead.
 	systemTagsCliHelpText := fmt.Sprintf(
@@ -94,6 +97,7 @@ func getOptions(flags *pflag.FlagSet) (lib.Options, error) {
 		HTTPDebug:               getNullString(flags, "http-debug"),
 		InsecureSkipTLSVerify:   getNullBool(flags, "insecure-skip-tls-verify"),
 		NoConnectionReuse:       getNullBool(flags, "no-connection-reuse"),
+		HTTP3:                   getNullBool(flags, "http3"),
 		NoVUConnectionReuse:     getNullBool(flags, "no-vu-connection-reuse"),
 		MinIterationDuration:    getNullDuration(flags, "min-iteration-duration"),
 		Throw:                   getNullBool(flags, "throw"),
@@ -210,6 +214,18 @@ func getOptions(flags *pflag.FlagSet) (lib.Options, error) {
 		opts.SummaryTimeUnit = null.StringFrom(summaryTimeUnit)
 	}
 
+	trendSink, err := flags.GetString("trend-sink")
+	if err != nil {
+		return opts, err
+	}
+	if trendSink != "" {
+		if trendSink != metrics.TrendSinkValues && trendSink != metrics.TrendSinkHistogram {
+			return opts, fmt.Errorf("invalid trend sink '%s', use '%s' or '%s'",
+				trendSink, metrics.TrendSinkValues, metrics.TrendSinkHistogram)
+		}
+		opts.TrendSink = null.StringFrom(trendSink)
+	}
+
 	runTags, err := flags.GetStringSlice("tag")
 	if err != nil {
 		return opts, err
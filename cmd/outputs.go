@@ -14,7 +14,9 @@ import (
 	"go.k6.io/k6/output/csv"
 	"go.k6.io/k6/output/influxdb"
 	"go.k6.io/k6/output/json"
+	"go.k6.io/k6/output/opentelemetry"
 	"go.k6.io/k6/output/statsd"
+	"go.k6.io/k6/output/store"
 
 	"github.com/grafana/xk6-dashboard/dashboard"
 	"github.com/grafana/xk6-output-prometheus-remote/pkg/remotewrite"
@@ -33,7 +35,9 @@ const (
 	builtinOutputInfluxdb
 	builtinOutputJSON
 	builtinOutputKafka
+	builtinOutputOpentelemetry
 	builtinOutputStatsd
+	builtinOutputStore
 )
 
 // TODO: move this to an output sub-module after we get rid of the old collectors?
@@ -48,6 +52,7 @@ func getAllOutputConstructors() (map[string]output.Constructor, error) {
 			return nil, errors.New("the kafka output was deprecated in k6 v0.32.0 and removed in k6 v0.34.0, " +
 				"please use the new xk6 kafka output extension instead - https://github.com/k6io/xk6-output-kafka")
 		},
+		builtinOutputOpentelemetry.String(): opentelemetry.New,
 		builtinOutputStatsd.String(): func(params output.Params) (output.Output, error) {
 			params.Logger.Warn("The statsd output is deprecated, and will be removed in a future k6 version. " +
 				"Please use the new xk6 statsd output extension instead. " +
@@ -62,7 +67,8 @@ func getAllOutputConstructors() (map[string]output.Constructor, error) {
 		builtinOutputExperimentalPrometheusRW.String(): func(params output.Params) (output.Output, error) {
 			return remotewrite.New(params)
 		},
-		"web-dashboard": dashboard.New,
+		builtinOutputStore.String(): store.New,
+		"web-dashboard":             dashboard.New,
 	}
 
 	exts := ext.Get(ext.OutputExtension)
@@ -119,23 +125,9 @@ func createOutputs(
 	result := make([]output.Output, 0, len(outputs))
 
 	for _, outputFullArg := range outputs {
-		outputType, outputArg := parseOutputArgument(outputFullArg)
-		outputConstructor, ok := outputConstructors[outputType]
-		if !ok {
-			return nil, fmt.Errorf(
-				"invalid output type '%s', available types are: %s",
-				outputType, getPossibleIDList(outputConstructors),
-			)
-		}
-
-		params := baseParams
-		params.OutputType = outputType
-		params.ConfigArgument = outputArg
-		params.JSONConfig = test.derivedConfig.Collectors[outputType]
-
-		out, err := outputConstructor(params)
+		out, err := createOutput(outputConstructors, baseParams, outputFullArg, test.derivedConfig)
 		if err != nil {
-			return nil, fmt.Errorf("could not create the '%s' output: %w", outputType, err)
+			return nil, err
 		}
 
 		if thresholdOut, ok := out.(output.WithThresholds); ok {
@@ -152,6 +144,32 @@ func createOutputs(
 	return result, nil
 }
 
+// createOutput creates a single output from its full `type=arg` CLI argument.
+func createOutput(
+	outputConstructors map[string]output.Constructor, baseParams output.Params,
+	outputFullArg string, conf Config,
+) (output.Output, error) {
+	outputType, outputArg := parseOutputArgument(outputFullArg)
+	outputConstructor, ok := outputConstructors[outputType]
+	if !ok {
+		return nil, fmt.Errorf(
+			"invalid output type '%s', available types are: %s",
+			outputType, getPossibleIDList(outputConstructors),
+		)
+	}
+
+	params := baseParams
+	params.OutputType = outputType
+	params.ConfigArgument = outputArg
+	params.JSONConfig = conf.Collectors[outputType]
+
+	out, err := outputConstructor(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the '%s' output: %w", outputType, err)
+	}
+	return out, nil
+}
+
 func parseOutputArgument(s string) (t, arg string) {
 	parts := strings.SplitN(s, "=", 2)
 	switch len(parts) {
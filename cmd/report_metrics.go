@@ -0,0 +1,412 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/loader"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/metrics/engine"
+	"go.k6.io/k6/output/store"
+)
+
+// The end-of-test summary is generated by a JS runner, so `k6 report` uses one
+// for an empty script.
+const reportScript = "export default function () {}"
+
+// cmdReport handles the `k6 report` sub-command
+type cmdReport struct {
+	gs *state.GlobalState
+
+	thresholds      []string
+	noThresholds    bool
+	tags            []string
+	from, to        time.Duration
+	trendStats      []string
+	timeUnit        string
+	summaryExport   string
+	tagFilter       map[string]string
+	thresholdsByCLI map[string][]string
+}
+
+// reportFilter selects the samples from the file that are used for the report.
+type reportFilter struct {
+	tags     map[string]string
+	from, to time.Time
+}
+
+func (f reportFilter) matches(s metrics.Sample) bool {
+	if s.Time.Before(f.from) || (!f.to.IsZero() && s.Time.After(f.to)) {
+		return false
+	}
+	for k, v := range f.tags {
+		if tv, ok := s.Tags.Get(k); !ok || tv != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *cmdReport) parseFlags() error {
+	if c.from < 0 || c.to < 0 || (c.to > 0 && c.to <= c.from) {
+		return errext.WithExitCodeIfNone(
+			errors.New("--from and --to should be positive durations and --to should be after --from"),
+			exitcodes.InvalidConfig,
+		)
+	}
+
+	c.tagFilter = make(map[string]string, len(c.tags))
+	for _, s := range c.tags {
+		name, value, err := parseTagNameValue(s)
+		if err != nil {
+			return errext.WithExitCodeIfNone(fmt.Errorf("error parsing tag '%s': %w", s, err), exitcodes.InvalidConfig)
+		}
+		c.tagFilter[name] = value
+	}
+
+	c.thresholdsByCLI = make(map[string][]string)
+	for _, s := range c.thresholds {
+		name, expr, err := parseTagNameValue(s)
+		if err != nil {
+			return errext.WithExitCodeIfNone(
+				fmt.Errorf("invalid threshold '%s', the format is 'metric=expression'", s), exitcodes.InvalidConfig,
+			)
+		}
+		c.thresholdsByCLI[name] = append(c.thresholdsByCLI[name], expr)
+	}
+
+	if c.timeUnit != "" && c.timeUnit != "s" && c.timeUnit != "ms" && c.timeUnit != "us" {
+		return errext.WithExitCodeIfNone(
+			fmt.Errorf("invalid summary time unit '%s', use 's', 'ms' or 'us'", c.timeUnit), exitcodes.InvalidConfig,
+		)
+	}
+	if len(c.trendStats) > 0 {
+		if _, err := metrics.GetResolversForTrendColumns(c.trendStats); err != nil {
+			return errext.WithExitCodeIfNone(err, exitcodes.InvalidConfig)
+		}
+	}
+	return nil
+}
+
+func (c *cmdReport) run(cmd *cobra.Command, args []string) error {
+	if err := c.parseFlags(); err != nil {
+		return err
+	}
+
+	file, err := c.gs.FS.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	footer, err := store.ReadFooter(file)
+	if err != nil {
+		return fmt.Errorf("couldn't read '%s': %w", args[0], err)
+	}
+	if footer == nil {
+		c.gs.Logger.Warnf("The metrics store file '%s' wasn't finalized, probably because k6 was stopped "+
+			"abruptly, so the report includes only the samples that were written to it", args[0])
+	}
+
+	test, err := c.loadReportRunner()
+	if err != nil {
+		return err
+	}
+	registry := test.preInitState.Registry
+
+	// The metrics have to exist before the thresholds are initialized, and the
+	// footer lists them. The unfinalized files have to be read once more instead.
+	var end time.Time
+	if footer != nil {
+		end = footer.EndTime
+		for _, m := range footer.Metrics {
+			if _, err := registry.NewMetric(m.Name, m.Type, m.Contains); err != nil {
+				return err
+			}
+		}
+	} else {
+		err = readStoreFile(file, registry, func(s metrics.Sample) {
+			if s.Time.After(end) {
+				end = s.Time
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	reader, err := store.NewReader(file, registry)
+	if err != nil {
+		return fmt.Errorf("couldn't read '%s': %w", args[0], err)
+	}
+	defer reader.Close()
+	header := reader.Header()
+
+	options, err := c.reportOptions(header.Options, registry)
+	if err != nil {
+		return err
+	}
+	if err := test.initRunner.SetOptions(options); err != nil {
+		return err
+	}
+
+	metricsEngine, err := engine.NewMetricsEngine(registry, c.gs.Logger)
+	if err != nil {
+		return err
+	}
+	if err := metricsEngine.InitSubMetricsAndThresholds(options, c.noThresholds); err != nil {
+		return errext.WithExitCodeIfNone(err, exitcodes.InvalidConfig)
+	}
+	ingester := metricsEngine.CreateIngester()
+	groupSummary := lib.NewGroupSummary(c.gs.Logger)
+	for _, o := range []interface{ Start() error }{ingester, groupSummary} {
+		if err := o.Start(); err != nil {
+			return err
+		}
+	}
+
+	filter := reportFilter{tags: c.tagFilter, from: header.StartTime.Add(c.from)}
+	if c.to > 0 {
+		filter.to = header.StartTime.Add(c.to)
+	}
+	testRunDuration := func() time.Duration {
+		duration := end.Sub(header.StartTime)
+		if c.to > 0 && c.to < duration {
+			duration = c.to
+		}
+		return duration - c.from
+	}
+
+	var finalizeThresholds func() []string
+	if !c.noThresholds {
+		finalizeThresholds = metricsEngine.StartThresholdCalculations(ingester, func(error) {}, testRunDuration)
+	}
+
+	var count int
+	for {
+		samples, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// the samples that were read so far are still reported
+			c.gs.Logger.WithError(err).Errorf("Couldn't read all of the samples from '%s'", args[0])
+			break
+		}
+
+		selected := make(metrics.Samples, 0, len(samples))
+		for _, s := range samples {
+			if filter.matches(s) {
+				selected = append(selected, s)
+			}
+		}
+		count += len(selected)
+		ingester.AddMetricSamples([]metrics.SampleContainer{selected})
+		groupSummary.AddMetricSamples([]metrics.SampleContainer{selected})
+	}
+	c.gs.Logger.Debugf("Read %d matching samples from '%s'", count, args[0])
+
+	var breachedThresholds []string
+	if finalizeThresholds != nil {
+		breachedThresholds = finalizeThresholds()
+	} else if err := ingester.Stop(); err != nil {
+		return err
+	}
+	if err := groupSummary.Stop(); err != nil {
+		return err
+	}
+
+	summaryResult, err := test.initRunner.HandleSummary(cmd.Context(), &lib.Summary{
+		Metrics:         metricsEngine.ObservedMetrics,
+		RootGroup:       groupSummary.Group(),
+		TestRunDuration: testRunDuration(),
+		NoColor:         c.gs.Flags.NoColor,
+		UIState: lib.UIState{
+			IsStdOutTTY: c.gs.Stdout.IsTTY,
+			IsStdErrTTY: c.gs.Stderr.IsTTY,
+		},
+	})
+	if err == nil {
+		err = handleSummaryResult(c.gs.FS, c.gs.Stdout, c.gs.Stderr, summaryResult)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(breachedThresholds) > 0 {
+		return errext.WithExitCodeIfNone(
+			fmt.Errorf("thresholds on metrics '%s' have been crossed", strings.Join(breachedThresholds, ", ")),
+			exitcodes.ThresholdsHaveFailed,
+		)
+	}
+	return nil
+}
+
+// loadReportRunner returns a test with a runner for an empty script, which
+// is used to generate the end-of-test summary.
+func (c *cmdReport) loadReportRunner() (*loadedTest, error) {
+	pwd, err := c.gs.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	src := &loader.SourceData{
+		URL:  &url.URL{Scheme: "file", Path: "/k6-report.js"},
+		Data: []byte(reportScript),
+	}
+	runtimeOptions := lib.RuntimeOptions{
+		TestType:          null.StringFrom(testTypeJS),
+		CompatibilityMode: null.StringFrom(lib.CompatibilityModeExtended.String()),
+		NoThresholds:      null.BoolFrom(c.noThresholds),
+		SummaryExport:     null.NewString(c.summaryExport, c.summaryExport != ""),
+		Env:               make(map[string]string),
+	}
+	return loadTestWithRuntimeOptions(c.gs, runtimeOptions, src.URL.String(), src, loader.CreateFilesystems(c.gs.FS), pwd)
+}
+
+// reportOptions returns the options that were used for the test run with the
+// thresholds and summary options from the CLI flags. The thresholds from the
+// flags replace the ones for the same metric that were used for the test run.
+func (c *cmdReport) reportOptions(options lib.Options, registry *metrics.Registry) (lib.Options, error) {
+	if len(c.trendStats) > 0 {
+		options.SummaryTrendStats = c.trendStats
+	}
+	if c.timeUnit != "" {
+		options.SummaryTimeUnit = null.StringFrom(c.timeUnit)
+	}
+
+	thresholds := make(map[string]metrics.Thresholds, len(options.Thresholds)+len(c.thresholdsByCLI))
+	for name, ts := range options.Thresholds {
+		if _, ok := c.thresholdsByCLI[name]; ok {
+			continue
+		}
+		if err := parseAndValidateThresholds(&ts, name, registry); err != nil {
+			// e.g. thresholds on custom metrics that didn't get any samples
+			c.gs.Logger.WithError(err).Warnf("Ignoring the thresholds on metric '%s' from the test run", name)
+			continue
+		}
+		thresholds[name] = ts
+	}
+
+	names := make([]string, 0, len(c.thresholdsByCLI))
+	for name := range c.thresholdsByCLI {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ts := metrics.NewThresholds(c.thresholdsByCLI[name])
+		if err := parseAndValidateThresholds(&ts, name, registry); err != nil {
+			return options, errext.WithExitCodeIfNone(
+				fmt.Errorf("invalid threshold on metric '%s': %w", name, err), exitcodes.InvalidConfig,
+			)
+		}
+		thresholds[name] = ts
+	}
+	options.Thresholds = thresholds
+
+	return options, nil
+}
+
+func parseAndValidateThresholds(ts *metrics.Thresholds, metricName string, registry *metrics.Registry) error {
+	if err := ts.Parse(); err != nil {
+		return err
+	}
+	return ts.Validate(metricName, registry)
+}
+
+// readStoreFile reads all of the samples in the file from its start.
+func readStoreFile(file io.ReadSeeker, registry *metrics.Registry, fn func(metrics.Sample)) error {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	reader, err := store.NewReader(file, registry)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for {
+		samples, err := reader.Next()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, s := range samples {
+			fn(s)
+		}
+	}
+}
+
+func (c *cmdReport) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.StringArrayVar(&c.thresholds, "threshold", nil,
+		"add a threshold as `metric=expression`, it replaces the thresholds of the test run for the same metric")
+	flags.BoolVar(&c.noThresholds, "no-thresholds", false, "don't run thresholds")
+	flags.StringArrayVar(&c.tags, "tag", nil,
+		"use only the samples with the tag `name=value`, can be used more than once")
+	flags.DurationVar(&c.from, "from", 0, "use only the samples after this time since the start of the test run")
+	flags.DurationVar(&c.to, "to", 0, "use only the samples before this time since the start of the test run")
+	flags.StringSliceVar(&c.trendStats, "summary-trend-stats", nil,
+		"define `stats` for trend metrics (response times), one or more as 'avg,p(95),...'")
+	flags.StringVar(&c.timeUnit, "summary-time-unit", "",
+		"define the time unit used to display the trend stats. Possible units are: 's', 'ms' and 'us'")
+	flags.StringVar(&c.summaryExport, "summary-export", "", "output the end-of-test summary report to JSON file")
+	return flags
+}
+
+func getCmdReport(gs *state.GlobalState) *cobra.Command {
+	c := &cmdReport{gs: gs}
+
+	exampleText := getExampleText(gs, `
+  # Write all metric samples of a test run to a metrics store file.
+  {{.}} run --out store=results.k6m script.js
+
+  # Generate the end-of-test summary of the test run again.
+  {{.}} report results.k6m
+
+  # Evaluate a different threshold for the requests to a single endpoint.
+  {{.}} report --threshold 'http_req_duration=p(95)<300' --tag name=http://example.com/login results.k6m
+
+  # Generate the summary for the second minute of the test run.
+  {{.}} report --from 1m --to 2m results.k6m`[1:])
+
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Generate the summary of a stored test run",
+		Long: `Generate the end-of-test summary of a stored test run.
+
+The metric samples of a test run can be written to a metrics store file with
+the "store" output. This command reads them back to generate the end-of-test
+summary and to evaluate the thresholds again, without running the test. The
+thresholds can be changed and the samples can be filtered by their tags and by
+the time since the start of the test run.
+
+The exit code is the same as the one of "k6 run" when the thresholds are crossed.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should be the path to a metrics store file"),
+		RunE:    c.run,
+	}
+
+	reportCmd.Flags().SortFlags = false
+	reportCmd.Flags().AddFlagSet(c.flagSet())
+
+	return reportCmd
+}
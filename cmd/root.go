@@ -61,9 +61,9 @@ func newRootCommand(gs *state.GlobalState) *rootCommand {
 	rootCmd.SetIn(gs.Stdin)
 
 	subCommands := []func(*state.GlobalState) *cobra.Command{
-		getCmdArchive, getCmdCloud, getCmdNewScript, getCmdInspect,
-		getCmdLogin, getCmdPause, getCmdResume, getCmdScale, getCmdRun,
-		getCmdStats, getCmdStatus, getCmdVersion,
+		getCmdAbort, getCmdAgent, getCmdArchive, getCmdCloud, getCmdCoordinator, getCmdNewScript,
+		getCmdInspect, getCmdLogin, getCmdPause, getCmdReport, getCmdResume, getCmdScale,
+		getCmdRun, getCmdStats, getCmdStatus, getCmdStop, getCmdSuite, getCmdVersion,
 	}
 
 	for _, sc := range subCommands {
@@ -190,6 +190,18 @@ func rootCmdPersistentFlagSet(gs *state.GlobalState) *pflag.FlagSet {
 		gs.DefaultFlags.ProfilingEnabled,
 		"enable profiling (pprof) endpoints, k6's REST API should be enabled as well",
 	)
+	flags.BoolVar(
+		&gs.Flags.PrometheusEnabled,
+		"prometheus-enabled",
+		gs.DefaultFlags.PrometheusEnabled,
+		"enable the Prometheus /metrics endpoint, k6's REST API should be enabled as well",
+	)
+	flags.IntVar(
+		&gs.Flags.PrometheusSeriesLimit,
+		"prometheus-series-limit",
+		gs.DefaultFlags.PrometheusSeriesLimit,
+		"maximum number of time series (i.e. label combinations) exposed by the Prometheus endpoint",
+	)
 
 	return flags
 }
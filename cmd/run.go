@@ -39,6 +39,10 @@ type cmdRun struct {
 
 	// TODO: figure out something more elegant?
 	loadConfiguredTest func(cmd *cobra.Command, args []string) (*loadedAndConfiguredTest, execution.Controller, error)
+
+	// createOutputs is used instead of the outputs from the test config, if
+	// set. It allows `k6 suite` to share the same outputs between its tests.
+	createOutputs func(test *loadedAndConfiguredTest, executionPlan []lib.ExecutionStep) ([]output.Output, error)
 }
 
 const (
@@ -65,7 +69,6 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 			logger.WithError(err).Debug("Everything has finished, exiting k6 with an error!")
 		}
 	}()
-	printBanner(c.gs)
 
 	globalCtx, globalCancel := context.WithCancel(c.gs.Ctx)
 	defer globalCancel()
@@ -160,7 +163,12 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 
 	// Create all outputs.
 	executionPlan := execScheduler.GetExecutionPlan()
-	outputs, err := createOutputs(c.gs, test, executionPlan)
+	var outputs []output.Output
+	if c.createOutputs != nil {
+		outputs, err = c.createOutputs(test, executionPlan)
+	} else {
+		outputs, err = createOutputs(c.gs, test, executionPlan)
+	}
 	if err != nil {
 		return err
 	}
@@ -172,10 +180,17 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 		return err
 	}
 
+	// The Prometheus endpoint needs the per-time-series sinks, but only when
+	// the REST API is enabled.
+	prometheusEnabled := c.gs.Flags.PrometheusEnabled && c.gs.Flags.Address != ""
+	if prometheusEnabled {
+		metricsEngine.EnableTimeSeriesSinks(c.gs.Flags.PrometheusSeriesLimit)
+	}
+
 	// We'll need to pipe metrics to the MetricsEngine and process them if any
-	// of these are enabled: thresholds, end-of-test summary
+	// of these are enabled: thresholds, end-of-test summary, Prometheus endpoint
 	shouldProcessMetrics := (!testRunState.RuntimeOptions.NoSummary.Bool ||
-		!testRunState.RuntimeOptions.NoThresholds.Bool)
+		!testRunState.RuntimeOptions.NoThresholds.Bool || prometheusEnabled)
 	var metricsIngester *engine.OutputIngester
 	if shouldProcessMetrics {
 		err = metricsEngine.InitSubMetricsAndThresholds(conf.Options, testRunState.RuntimeOptions.NoThresholds.Bool)
@@ -183,9 +198,13 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 			return err
 		}
 		// We'll need to pipe metrics to the MetricsEngine if either the
-		// thresholds or the end-of-test summary are enabled.
+		// thresholds, the end-of-test summary or the Prometheus endpoint are
+		// enabled.
 		metricsIngester = metricsEngine.CreateIngester()
 		outputs = append(outputs, metricsIngester)
+		// Some executors, e.g. adaptive-arrival-rate, adjust the execution
+		// based on the metric values during the test run.
+		testRunState.LiveMetrics = metricsEngine
 	}
 
 	executionState := execScheduler.GetState()
@@ -229,6 +248,7 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 	if err != nil {
 		return err
 	}
+	testRunState.FlushMetrics = outputManager.Flush
 	defer func() {
 		logger.Debug("Stopping outputs...")
 		// We call waitOutputsFlushed() below because the threshold calculations
@@ -247,7 +267,9 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 			// the OutputManager has flushed all of the cached samples to
 			// outputs (including MetricsEngine's ingester). So we are sure
 			// there won't be any more metrics being sent.
-			logger.Debug("Finalizing thresholds...")
+			if metricsEngine.HasThresholds() {
+				logger.Debug("Finalizing thresholds...")
+			}
 			breachedThresholds := finalizeThresholds()
 			if len(breachedThresholds) == 0 {
 				return
@@ -264,9 +286,7 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 				logger.WithError(tErr).Debug("Crossed thresholds, but test already exited with another error")
 			}
 		}
-		if finalizeThresholds != nil {
-			defer handleFinalThresholdCalculation()
-		}
+		defer handleFinalThresholdCalculation()
 	}
 
 	defer func() {
@@ -485,7 +505,10 @@ func getCmdRun(gs *state.GlobalState) *cobra.Command {
   {{.}} run -u 0 -s 10s:100 -s 60s:100 -s 10s:0
 
   # Send metrics to an influxdb server
-  {{.}} run -o influxdb=http://1.2.3.4:8086/k6`[1:])
+  {{.}} run -o influxdb=http://1.2.3.4:8086/k6
+
+  # Send metrics to an OpenTelemetry collector over gRPC
+  {{.}} run -o opentelemetry=localhost:4317`[1:])
 
 	runCmd := &cobra.Command{
 		Use:   "run",
@@ -496,7 +519,10 @@ This also exposes a REST API to interact with it. Various k6 subcommands offer
 a commandline interface for interacting with it.`,
 		Example: exampleText,
 		Args:    exactArgsWithMsg(1, "arg should either be \"-\", if reading script from stdin, or a path to a script file"),
-		RunE:    c.run,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printBanner(gs)
+			return c.run(cmd, args)
+		},
 	}
 
 	runCmd.Flags().SortFlags = false
@@ -4,12 +4,14 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/spf13/pflag"
 	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/cmd/state"
 	"go.k6.io/k6/lib"
+	secretenv "go.k6.io/k6/secretsource/env"
 )
 
 // TODO: move this whole file out of the cmd package? maybe when fixing
@@ -40,6 +42,10 @@ experimental_enhanced: esbuild-based transpiling for TypeScript and ES6+ support
 	)
 	flags.String("traces-output", "none",
 		"set the output for k6 traces, possible values are none,otel[=host:port]")
+	flags.String("fs-output-dir", "",
+		"directory the k6/experimental/fs module is allowed to write files to, writes are disabled when unset")
+	flags.StringArray("secret-source", nil,
+		"add a secret source for the k6/secrets module with `type=argument`, e.g. file=secrets.enc or env")
 	return flags
 }
 
@@ -70,6 +76,7 @@ func getRuntimeOptions(flags *pflag.FlagSet, environment map[string]string) (lib
 		NoSummary:            getNullBool(flags, "no-summary"),
 		SummaryExport:        getNullString(flags, "summary-export"),
 		TracesOutput:         getNullString(flags, "traces-output"),
+		FSOutputDir:          getNullString(flags, "fs-output-dir"),
 		Env:                  make(map[string]string),
 	}
 
@@ -114,8 +121,22 @@ func getRuntimeOptions(flags *pflag.FlagSet, environment map[string]string) (lib
 		}
 	}
 
+	if envVar, ok := environment["K6_FS_OUTPUT_DIR"]; ok {
+		if !opts.FSOutputDir.Valid {
+			opts.FSOutputDir = null.StringFrom(envVar)
+		}
+	}
+
+	if flags.Changed("secret-source") {
+		secretSources, err := flags.GetStringArray("secret-source")
+		if err != nil {
+			return opts, err
+		}
+		opts.SecretSources = secretSources
+	}
+
 	if opts.IncludeSystemEnvVars.Bool { // If enabled, gather the actual system environment variables
-		opts.Env = environment
+		opts.Env = withoutSecretEnvVars(environment)
 	}
 
 	// Set/overwrite environment variables with custom user-supplied values
@@ -134,3 +155,24 @@ func getRuntimeOptions(flags *pflag.FlagSet, environment map[string]string) (lib
 
 	return opts, nil
 }
+
+// withoutSecretEnvVars returns the given environment variables, without the secrets
+// of the env secret source. They are only available through k6/secrets, so they're
+// never part of an archive.
+func withoutSecretEnvVars(environment map[string]string) map[string]string {
+	for k := range environment {
+		if !strings.HasPrefix(k, secretenv.Prefix) {
+			continue
+		}
+
+		result := make(map[string]string, len(environment))
+		for k, v := range environment {
+			if !strings.HasPrefix(k, secretenv.Prefix) {
+				result[k] = v
+			}
+		}
+		return result
+	}
+
+	return environment
+}
@@ -406,6 +406,51 @@ func TestRuntimeOptions(t *testing.T) {
 				TracesOutput:         null.NewString("bar", true),
 			},
 		},
+		"fs output dir from env": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_FS_OUTPUT_DIR": "foo"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				TracesOutput:         null.NewString("none", false),
+				FSOutputDir:          null.NewString("foo", true),
+			},
+		},
+		"fs output dir from env overwritten by CLI": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_FS_OUTPUT_DIR": "foo"},
+			cliFlags:  []string{"--fs-output-dir", "bar"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				TracesOutput:         null.NewString("none", false),
+				FSOutputDir:          null.NewString("bar", true),
+			},
+		},
+		"secret sources": {
+			useSysEnv: false,
+			cliFlags:  []string{"--secret-source", "env", "--secret-source=file=name=vault,secrets.enc"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				TracesOutput:         null.NewString("none", false),
+				SecretSources:        []string{"env", "file=name=vault,secrets.enc"},
+			},
+		},
+		"secrets of the env secret source are excluded from the system env vars": {
+			useSysEnv: true,
+			systemEnv: map[string]string{"test1": "val1", "K6_SECRET_token": "s3cr3t"},
+			cliFlags:  []string{"--env", "K6_SECRET_explicit=value"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(true, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{"test1": "val1", "K6_SECRET_explicit": "value"},
+				TracesOutput:         null.NewString("none", false),
+			},
+		},
 	}
 	for name, tc := range runtimeOptionsTestCases {
 		tc := tc
@@ -11,25 +11,50 @@ import (
 )
 
 func getCmdScale(gs *state.GlobalState) *cobra.Command {
+	exampleText := getExampleText(gs, `
+  # Scale an externally-controlled scenario to 10 VUs.
+  {{.}} scale --vus 10 --max 20
+
+  # Start 50 iterations per timeUnit in an externally-controlled-arrival-rate scenario.
+  {{.}} scale --rate 50 --pre-allocated-vus 10 --max 100`[1:])
+
 	// scaleCmd represents the scale command
 	scaleCmd := &cobra.Command{
 		Use:   "scale",
 		Short: "Scale a running test",
 		Long: `Scale a running test.
 
+  The -u/--vus and -m/--max flags change the VUs of an externally-controlled
+  scenario. The -r/--rate and --pre-allocated-vus flags change the iteration
+  rate and the VUs of an externally-controlled-arrival-rate scenario, where
+  -m/--max is used for its maxVUs.
+
   Use the global --address flag to specify the URL to the API server.`,
+		Example: exampleText,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			vus := getNullInt64(cmd.Flags(), "vus")
 			max := getNullInt64(cmd.Flags(), "max")
-			if !vus.Valid && !max.Valid {
-				return errors.New("Specify either -u/--vus or -m/--max") //nolint:golint,stylecheck
+			rate := getNullInt64(cmd.Flags(), "rate")
+			preAllocatedVUs := getNullInt64(cmd.Flags(), "pre-allocated-vus")
+
+			var newStatus v1.Status
+			switch {
+			case rate.Valid || preAllocatedVUs.Valid:
+				if vus.Valid {
+					return errors.New("-u/--vus can't be used together with -r/--rate or --pre-allocated-vus")
+				}
+				newStatus.ArrivalRate = &v1.ArrivalRateStatus{Rate: rate, PreAllocatedVUs: preAllocatedVUs, MaxVUs: max}
+			case vus.Valid || max.Valid:
+				newStatus.VUs, newStatus.VUsMax = vus, max
+			default:
+				return errors.New("Specify either -u/--vus, -m/--max, -r/--rate or --pre-allocated-vus") //nolint:golint,stylecheck
 			}
 
 			c, err := client.New(gs.Flags.Address)
 			if err != nil {
 				return err
 			}
-			status, err := c.SetStatus(gs.Ctx, v1.Status{VUs: vus, VUsMax: max})
+			status, err := c.SetStatus(gs.Ctx, newStatus)
 			if err != nil {
 				return err
 			}
@@ -40,6 +65,8 @@ func getCmdScale(gs *state.GlobalState) *cobra.Command {
 
 	scaleCmd.Flags().Int64P("vus", "u", 1, "number of virtual users")
 	scaleCmd.Flags().Int64P("max", "m", 0, "max available virtual users")
+	scaleCmd.Flags().Int64P("rate", "r", 0, "number of iterations to start per timeUnit")
+	scaleCmd.Flags().Int64("pre-allocated-vus", 0, "number of pre-allocated virtual users for the arrival rate")
 
 	return scaleCmd
 }
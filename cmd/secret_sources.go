@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/ext"
+	"go.k6.io/k6/secretsource"
+	secretenv "go.k6.io/k6/secretsource/env"
+	secretfile "go.k6.io/k6/secretsource/file"
+)
+
+func getAllSecretSourceConstructors() (map[string]secretsource.Constructor, error) {
+	// Start with the built-in secret sources
+	result := map[string]secretsource.Constructor{
+		"env":  secretenv.New,
+		"file": secretfile.New,
+	}
+
+	exts := ext.Get(ext.SecretSourceExtension)
+	for _, e := range exts {
+		if _, ok := result[e.Name]; ok {
+			return nil, fmt.Errorf("invalid secret source extension %s, "+
+				"built-in secret source with the same type already exists", e.Name)
+		}
+		m, ok := e.Module.(secretsource.Constructor)
+		if !ok {
+			return nil, fmt.Errorf("unexpected secret source extension type %T", e.Module)
+		}
+		result[e.Name] = m
+	}
+
+	return result, nil
+}
+
+// createSecretsManager creates the secret sources from their --secret-source
+// $type=$argument configurations, and the manager handing out their secrets.
+//
+// On top of its own options, the argument of every source may hold the name=$name
+// option, the name defaulting to the type, and the default flag, which marks the
+// source used when the test doesn't ask for a specific one.
+func createSecretsManager(gs *state.GlobalState, configs []string, pwd string) (*secretsource.Manager, error) {
+	constructors, err := getAllSecretSourceConstructors()
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[string]secretsource.Source, len(configs))
+	var defaultSource string
+	for _, config := range configs {
+		typ, arg, _ := strings.Cut(config, "=")
+		constructor, ok := constructors[typ]
+		if !ok {
+			return nil, fmt.Errorf("invalid secret source type '%s', available types are: %s",
+				typ, getPossibleSecretSourceTypes(constructors))
+		}
+
+		name, isDefault, arg := parseSecretSourceArg(typ, arg)
+		if _, ok := sources[name]; ok {
+			return nil, fmt.Errorf("there is more than one secret source named '%s', "+
+				"use the name option to differentiate them", name)
+		}
+
+		source, err := constructor(secretsource.Params{
+			ConfigArgument: arg,
+			Logger:         gs.Logger.WithField("secret_source", name),
+			Environment:    gs.Env,
+			FS:             gs.FS,
+			Pwd:            pwd,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not create the '%s' secret source: %w", name, err)
+		}
+		gs.Logger.Debugf("Secret source '%s' created: %s", name, source.Description())
+		sources[name] = source
+
+		switch {
+		case isDefault && defaultSource != "" && defaultSource != name:
+			return nil, fmt.Errorf("both the '%s' and '%s' secret sources are set as the default one", defaultSource, name)
+		case isDefault, len(configs) == 1:
+			defaultSource = name
+		}
+	}
+
+	if defaultSource == "" && len(sources) > 0 {
+		return nil, errors.New("one of the secret sources must be set as the default one, with the default option")
+	}
+
+	return secretsource.NewManager(sources, defaultSource)
+}
+
+// parseSecretSourceArg extracts the name and default options, common to all the
+// secret sources, from the argument of a source.
+func parseSecretSourceArg(typ, arg string) (name string, isDefault bool, rest string) {
+	name = typ
+	if arg == "" {
+		return name, false, ""
+	}
+
+	var options []string
+	for _, option := range strings.Split(arg, ",") {
+		switch {
+		case option == "default":
+			isDefault = true
+		case strings.HasPrefix(option, "name="):
+			name = strings.TrimPrefix(option, "name=")
+		default:
+			options = append(options, option)
+		}
+	}
+
+	return name, isDefault, strings.Join(options, ",")
+}
+
+func getPossibleSecretSourceTypes(constructors map[string]secretsource.Constructor) string {
+	res := make([]string, 0, len(constructors))
+	for k := range constructors {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return strings.Join(res, ", ")
+}
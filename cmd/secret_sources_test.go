@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/cmd/tests"
+)
+
+func TestCreateSecretsManager(t *testing.T) {
+	t.Parallel()
+
+	ts := tests.NewGlobalTestState(t)
+	ts.Env["K6_SECRET_token"] = "s3cr3t"
+
+	manager, err := createSecretsManager(ts.GlobalState, []string{"env"}, ts.Cwd)
+	require.NoError(t, err)
+
+	token, err := manager.Get("", "token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", token)
+
+	manager, err = createSecretsManager(ts.GlobalState, []string{"env=name=first", "env=name=second,default"}, ts.Cwd)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, manager.SourceNames())
+
+	token, err = manager.Get("", "token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", token)
+}
+
+func TestCreateSecretsManagerErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		configs []string
+		err     string
+	}{
+		"unknown type": {
+			configs: []string{"vault=addr"},
+			err:     "invalid secret source type 'vault', available types are: env, file",
+		},
+		"invalid argument": {
+			configs: []string{"env=foo"},
+			err:     "could not create the 'env' secret source: the env secret source doesn't take any argument",
+		},
+		"duplicated name": {
+			configs: []string{"env", "env=default"},
+			err:     "there is more than one secret source named 'env'",
+		},
+		"no default": {
+			configs: []string{"env=name=first", "env=name=second"},
+			err:     "one of the secret sources must be set as the default one",
+		},
+		"several defaults": {
+			configs: []string{"env=name=first,default", "env=name=second,default"},
+			err:     "both the 'first' and 'second' secret sources are set as the default one",
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := tests.NewGlobalTestState(t)
+			_, err := createSecretsManager(ts.GlobalState, tc.configs, ts.Cwd)
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestParseSecretSourceArg(t *testing.T) {
+	t.Parallel()
+
+	name, isDefault, arg := parseSecretSourceArg("file", "secrets.enc")
+	assert.Equal(t, "file", name)
+	assert.False(t, isDefault)
+	assert.Equal(t, "secrets.enc", arg)
+
+	name, isDefault, arg = parseSecretSourceArg("file", "name=vault,filename=secrets.enc,default,iterations=10")
+	assert.Equal(t, "vault", name)
+	assert.True(t, isDefault)
+	assert.Equal(t, "filename=secrets.enc,iterations=10", arg)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"sync"
 
 	"github.com/mattn/go-colorable"
@@ -134,23 +135,27 @@ func NewGlobalState(ctx context.Context) *GlobalState {
 
 // GlobalFlags contains global config values that apply for all k6 sub-commands.
 type GlobalFlags struct {
-	ConfigFilePath   string
-	Quiet            bool
-	NoColor          bool
-	Address          string
-	ProfilingEnabled bool
-	LogOutput        string
-	LogFormat        string
-	Verbose          bool
+	ConfigFilePath        string
+	Quiet                 bool
+	NoColor               bool
+	Address               string
+	ProfilingEnabled      bool
+	PrometheusEnabled     bool
+	PrometheusSeriesLimit int
+	LogOutput             string
+	LogFormat             string
+	Verbose               bool
 }
 
 // GetDefaultFlags returns the default global flags.
 func GetDefaultFlags(homeDir string) GlobalFlags {
 	return GlobalFlags{
-		Address:          "localhost:6565",
-		ProfilingEnabled: false,
-		ConfigFilePath:   filepath.Join(homeDir, "loadimpact", "k6", defaultConfigFileName),
-		LogOutput:        "stderr",
+		Address:               "localhost:6565",
+		ProfilingEnabled:      false,
+		PrometheusEnabled:     false,
+		PrometheusSeriesLimit: 10000,
+		ConfigFilePath:        filepath.Join(homeDir, "loadimpact", "k6", defaultConfigFileName),
+		LogOutput:             "stderr",
 	}
 }
 
@@ -180,5 +185,13 @@ func getFlags(defaultFlags GlobalFlags, env map[string]string) GlobalFlags {
 	if _, ok := env["K6_PROFILING_ENABLED"]; ok {
 		result.ProfilingEnabled = true
 	}
+	if _, ok := env["K6_PROMETHEUS_ENABLED"]; ok {
+		result.PrometheusEnabled = true
+	}
+	if val, ok := env["K6_PROMETHEUS_SERIES_LIMIT"]; ok {
+		if limit, err := strconv.Atoi(val); err == nil {
+			result.PrometheusSeriesLimit = limit
+		}
+	}
 	return result
 }
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"go.k6.io/k6/api/v1/client"
+	"go.k6.io/k6/cmd/state"
+)
+
+func getCmdStop(gs *state.GlobalState) *cobra.Command {
+	// stopCmd represents the stop command
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Gracefully stop a running test",
+		Long: `Gracefully stop a running test.
+
+  No new iterations are started and the ones in progress have up to the
+  gracefulStop of their scenario to finish, while the scenarios that haven't
+  started yet are skipped. Use the abort command to stop the test immediately
+  instead.
+
+  Use the global --address flag to specify the URL to the API server.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			c, err := client.New(gs.Flags.Address)
+			if err != nil {
+				return err
+			}
+			status, err := c.Stop(gs.Ctx)
+			if err != nil {
+				return err
+			}
+			return yamlPrint(gs.Stdout, status)
+		},
+	}
+	return stopCmd
+}
@@ -0,0 +1,544 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/event"
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/execution/local"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/output"
+)
+
+// suiteManifest describes a test suite, i.e. a set of k6 tests that are
+// executed together, with dependencies between them.
+type suiteManifest struct {
+	// Outputs are shared by all of the tests, e.g. "json=results.json".
+	Outputs []string `json:"outputs"`
+	// Options are applied to all of the tests, before their own options.
+	Options lib.Options `json:"options"`
+	// MaxParallel limits how many tests can run at the same time, 0 means
+	// that there is no limit.
+	MaxParallel int         `json:"maxParallel"`
+	Tests       []suiteTest `json:"tests"`
+}
+
+// suiteTest is a single test in a suite. By default, every test runs after
+// the previous one in the manifest has finished successfully. That can be
+// changed with After, which lists the tests it has to wait for instead, or
+// with Parallel, which makes it start together with the previous test.
+type suiteTest struct {
+	Name     string            `json:"name"`
+	Script   string            `json:"script"`
+	After    []string          `json:"after"`
+	Parallel bool              `json:"parallel"`
+	Env      map[string]string `json:"env"`
+	Options  lib.Options       `json:"options"`
+}
+
+// dependencies returns the names of the tests that the i-th test should wait for.
+func (m *suiteManifest) dependencies(i int) []string {
+	test := m.Tests[i]
+	switch {
+	case test.After != nil:
+		return test.After
+	case i == 0:
+		return nil
+	case test.Parallel:
+		// it waits for the same tests as the previous one, so they start together
+		return m.dependencies(i - 1)
+	default:
+		return []string{m.Tests[i-1].Name}
+	}
+}
+
+// isSequential returns true if the tests are always executed one by one.
+func (m *suiteManifest) isSequential() bool {
+	if m.MaxParallel == 1 {
+		return true
+	}
+	for i := 1; i < len(m.Tests); i++ {
+		previous := m.Tests[i-1].Name
+		found := false
+		for _, dep := range m.dependencies(i) {
+			found = found || dep == previous
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *suiteManifest) validate() error {
+	if len(m.Tests) == 0 {
+		return errors.New("the suite doesn't have any tests")
+	}
+	if m.MaxParallel < 0 {
+		return errors.New("maxParallel can't be negative")
+	}
+
+	indexes := make(map[string]int, len(m.Tests))
+	for i, test := range m.Tests {
+		if test.Name == "" {
+			return fmt.Errorf("test #%d doesn't have a name", i+1)
+		}
+		if test.Script == "" {
+			return fmt.Errorf("test '%s' doesn't have a script", test.Name)
+		}
+		if _, ok := indexes[test.Name]; ok {
+			return fmt.Errorf("there is more than one test with the name '%s'", test.Name)
+		}
+		indexes[test.Name] = i
+	}
+	for i, test := range m.Tests {
+		for _, dep := range m.dependencies(i) {
+			if _, ok := indexes[dep]; !ok {
+				return fmt.Errorf("test '%s' should run after the unknown test '%s'", test.Name, dep)
+			}
+		}
+	}
+
+	// Depth-first search for dependency cycles, they would block forever.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(m.Tests))
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		path = append(path, m.Tests[i].Name)
+		switch states[i] {
+		case visiting:
+			return fmt.Errorf("the suite has a dependency cycle: %s", strings.Join(path, " -> "))
+		case visited:
+			return nil
+		}
+		states[i] = visiting
+		for _, dep := range m.dependencies(i) {
+			if err := visit(indexes[dep], path); err != nil {
+				return err
+			}
+		}
+		states[i] = visited
+		return nil
+	}
+	for i := range m.Tests {
+		if err := visit(i, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readSuiteManifest reads and validates the manifest. The relative script
+// paths in it are resolved relatively to the manifest's directory.
+func readSuiteManifest(gs *state.GlobalState, path string) (*suiteManifest, string, error) {
+	if !filepath.IsAbs(path) {
+		pwd, err := gs.Getwd()
+		if err != nil {
+			return nil, "", err
+		}
+		path = filepath.Join(pwd, path)
+	}
+	data, err := fsext.ReadFile(gs.FS, path)
+	if err != nil {
+		return nil, "", fmt.Errorf("couldn't read the suite manifest: %w", err)
+	}
+
+	manifest := &suiteManifest{}
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, "", fmt.Errorf("couldn't parse the suite manifest '%s': %w", path, err)
+	}
+	if err = manifest.validate(); err != nil {
+		return nil, "", fmt.Errorf("invalid suite manifest '%s': %w", path, err)
+	}
+
+	for i, test := range manifest.Tests {
+		if test.Script != "-" && !filepath.IsAbs(test.Script) && !strings.Contains(test.Script, "://") {
+			manifest.Tests[i].Script = filepath.Join(filepath.Dir(path), test.Script)
+		}
+	}
+	return manifest, path, nil
+}
+
+// suiteTestResult is the outcome of a single test in the suite.
+type suiteTestResult struct {
+	skipped  bool
+	err      error
+	duration time.Duration
+}
+
+// suiteOutput forwards the metric samples of a test to the outputs that are
+// shared between all of the tests in the suite.
+type suiteOutput struct {
+	description string
+	samples     chan<- metrics.SampleContainer
+}
+
+var _ output.Output = &suiteOutput{}
+
+func (o *suiteOutput) Description() string { return o.description }
+func (o *suiteOutput) Start() error        { return nil }
+func (o *suiteOutput) Stop() error         { return nil }
+
+func (o *suiteOutput) AddMetricSamples(samples []metrics.SampleContainer) {
+	for _, sc := range samples {
+		o.samples <- sc
+	}
+}
+
+// cmdSuite handles the `k6 suite` sub-command
+type cmdSuite struct {
+	gs *state.GlobalState
+}
+
+//nolint:funlen
+func (c *cmdSuite) run(cmd *cobra.Command, args []string) (err error) {
+	manifest, manifestPath, err := readSuiteManifest(c.gs, args[0])
+	if err != nil {
+		return err
+	}
+	printBanner(c.gs)
+
+	// suiteCtx is cancelled on interrupts, so no new tests are started. The
+	// running tests handle the signals by themselves, the same as `k6 run`.
+	suiteCtx, suiteCancel := context.WithCancel(c.gs.Ctx)
+	defer suiteCancel()
+	sigC := make(chan os.Signal, 1)
+	c.gs.SignalNotify(sigC, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer c.gs.SignalStop(sigC)
+	go func() {
+		select {
+		case <-sigC:
+			suiteCancel()
+		case <-suiteCtx.Done():
+		}
+	}()
+
+	outputs, err := c.createSharedOutputs(cmd, manifest, manifestPath)
+	if err != nil {
+		return err
+	}
+	outputManager := output.NewManager(outputs, c.gs.Logger, func(err error) {
+		if err != nil {
+			c.gs.Logger.WithError(err).Error("Received error to stop from output")
+		}
+		suiteCancel()
+	})
+	// The same size as the default metricSamplesBufferSize of the tests.
+	samples := make(chan metrics.SampleContainer, 1000)
+	waitOutputsFlushed, stopOutputs, err := outputManager.Start(samples)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		close(samples)
+		waitOutputsFlushed()
+		stopOutputs(err)
+	}()
+
+	descriptions := make([]string, 0, len(outputs))
+	for _, out := range outputs {
+		descriptions = append(descriptions, out.Description())
+	}
+	sharedOutput := &suiteOutput{description: strings.Join(descriptions, ", "), samples: samples}
+
+	// The suite controller is used only for the "test is done" events of the
+	// tests, the tests themselves have their own controllers.
+	controller := local.NewController()
+	var semaphore chan struct{}
+	if manifest.MaxParallel > 0 {
+		semaphore = make(chan struct{}, manifest.MaxParallel)
+	}
+
+	results := make([]suiteTestResult, len(manifest.Tests))
+	wg := sync.WaitGroup{}
+	wg.Add(len(manifest.Tests))
+	for i := range manifest.Tests {
+		go func(i int) {
+			defer wg.Done()
+			results[i] = c.runTest(suiteCtx, cmd, controller, semaphore, manifest, i, sharedOutput)
+		}(i)
+	}
+	wg.Wait()
+
+	printSuiteSummary(c.gs, manifestPath, manifest, results)
+	return suiteError(manifest, results)
+}
+
+func suiteTestDoneEvent(name string) string {
+	return "suite-test-done-" + name
+}
+
+func (c *cmdSuite) runTest(
+	ctx context.Context, cmd *cobra.Command, controller execution.Controller, semaphore chan struct{},
+	manifest *suiteManifest, i int, sharedOutput *suiteOutput,
+) (result suiteTestResult) {
+	test := manifest.Tests[i]
+	defer func() {
+		_ = controller.Signal(suiteTestDoneEvent(test.Name), result.err)
+	}()
+
+	for _, dep := range manifest.dependencies(i) {
+		if err := controller.Subscribe(suiteTestDoneEvent(dep))(); err != nil {
+			return suiteTestResult{skipped: true, err: fmt.Errorf("test '%s' didn't pass", dep)}
+		}
+	}
+	if semaphore != nil {
+		select {
+		case semaphore <- struct{}{}:
+			defer func() { <-semaphore }()
+		case <-ctx.Done():
+		}
+	}
+	if ctx.Err() != nil {
+		return suiteTestResult{skipped: true, err: errors.New("the suite was interrupted")}
+	}
+
+	c.gs.Logger.Infof("Starting test '%s' (%s)...", test.Name, test.Script)
+	start := time.Now()
+	err := c.newTestRun(manifest, test, sharedOutput).run(cmd, []string{test.Script})
+	result = suiteTestResult{err: err, duration: time.Since(start)}
+	if err != nil {
+		c.gs.Logger.WithError(err).Errorf("Test '%s' failed", test.Name)
+	} else {
+		c.gs.Logger.Infof("Test '%s' passed", test.Name)
+	}
+	return result
+}
+
+// newTestRun returns a `k6 run` command for a single test in the suite, which
+// uses its option overrides and env vars, and the shared suite outputs.
+func (c *cmdSuite) newTestRun(manifest *suiteManifest, test suiteTest, sharedOutput *suiteOutput) *cmdRun {
+	gs := *c.gs
+	gs.Flags.Address = "" // the tests can't use the same REST API address
+	gs.Events = event.NewEventSystem(100, c.gs.Logger)
+	if !manifest.isSequential() {
+		// The progress bars of parallel tests can't be rendered together.
+		gs.Flags.Quiet = true
+	}
+
+	return &cmdRun{
+		gs: &gs,
+		loadConfiguredTest: func(cmd *cobra.Command, args []string) (*loadedAndConfiguredTest, execution.Controller, error) {
+			src, fileSystems, pwd, err := readSource(&gs, args[0])
+			if err != nil {
+				return nil, nil, err
+			}
+			runtimeOptions, err := getRuntimeOptions(cmd.Flags(), gs.Env)
+			if err != nil {
+				return nil, nil, err
+			}
+			// The env may be the system env, which shouldn't be modified.
+			env := make(map[string]string, len(runtimeOptions.Env)+len(test.Env))
+			for k, v := range runtimeOptions.Env {
+				env[k] = v
+			}
+			for k, v := range test.Env {
+				env[k] = v
+			}
+			runtimeOptions.Env = env
+			lt, err := loadTestWithRuntimeOptions(&gs, runtimeOptions, args[0], src, fileSystems, pwd)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			// The overrides are applied on top of the default `k6 run` flag
+			// values, as if they were specified as CLI flags.
+			configuredTest, err := lt.consolidateDeriveAndValidateConfig(&gs, cmd,
+				func(_ *pflag.FlagSet) (Config, error) {
+					conf, err := getPartialConfig(optionFlagSet())
+					conf.Options = conf.Options.Apply(manifest.Options).Apply(test.Options)
+					return conf, err
+				},
+			)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			// All metrics are tagged with the test name, so the results of the
+			// different tests can be told apart in the shared outputs.
+			runTags := map[string]string{"test": test.Name}
+			for k, v := range configuredTest.derivedConfig.RunTags {
+				runTags[k] = v
+			}
+			configuredTest.derivedConfig.RunTags = runTags
+
+			return configuredTest, local.NewController(), nil
+		},
+		createOutputs: func(_ *loadedAndConfiguredTest, _ []lib.ExecutionStep) ([]output.Output, error) {
+			if sharedOutput.description == "" {
+				return nil, nil
+			}
+			return []output.Output{sharedOutput}, nil
+		},
+	}
+}
+
+// createSharedOutputs creates the outputs of the suite. They aren't created
+// for any specific test, so they don't get any test options or execution plan.
+func (c *cmdSuite) createSharedOutputs(
+	cmd *cobra.Command, manifest *suiteManifest, manifestPath string,
+) ([]output.Output, error) {
+	outputConstructors, err := getAllOutputConstructors()
+	if err != nil {
+		return nil, err
+	}
+	runtimeOptions, err := getRuntimeOptions(cmd.Flags(), c.gs.Env)
+	if err != nil {
+		return nil, err
+	}
+	diskConf, err := readDiskConfig(c.gs)
+	if err != nil {
+		return nil, err
+	}
+	baseParams := output.Params{
+		ScriptPath:     &url.URL{Scheme: "file", Path: filepath.ToSlash(manifestPath)},
+		Logger:         c.gs.Logger,
+		Environment:    c.gs.Env,
+		StdOut:         c.gs.Stdout,
+		StdErr:         c.gs.Stderr,
+		FS:             c.gs.FS,
+		RuntimeOptions: runtimeOptions,
+	}
+
+	result := make([]output.Output, 0, len(manifest.Outputs))
+	for _, outputFullArg := range manifest.Outputs {
+		if outputType, _ := parseOutputArgument(outputFullArg); outputType == builtinOutputCloud.String() {
+			return nil, errors.New("the cloud output can't be shared between the tests of a suite")
+		}
+		out, err := createOutput(outputConstructors, baseParams, outputFullArg, diskConf)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, out)
+	}
+	return result, nil
+}
+
+func printSuiteSummary(gs *state.GlobalState, manifestPath string, manifest *suiteManifest, results []suiteTestResult) {
+	noColor := gs.Flags.NoColor || !gs.Stdout.IsTTY
+	valueColor := getColor(noColor, color.FgCyan)
+	passedColor := getColor(noColor, color.FgGreen)
+	failedColor := getColor(noColor, color.FgRed)
+	skippedColor := getColor(noColor, color.Faint)
+
+	nameLen := 0
+	for _, test := range manifest.Tests {
+		if len(test.Name) > nameLen {
+			nameLen = len(test.Name)
+		}
+	}
+
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "\n         suite: %s\n\n", valueColor.Sprint(manifestPath))
+	for i, test := range manifest.Tests {
+		r := results[i]
+		name := test.Name + strings.Repeat(" ", nameLen-len(test.Name))
+		switch {
+		case r.skipped:
+			fmt.Fprintf(buf, "     %s\n", skippedColor.Sprintf("↷ %s skipped, %s", name, r.err))
+		case r.err != nil:
+			fmt.Fprintf(buf, "     %s %s\n", failedColor.Sprintf("✗ %s failed", name),
+				skippedColor.Sprintf("in %s, %s", r.duration.Round(time.Millisecond), r.err))
+		default:
+			fmt.Fprintf(buf, "     %s %s\n", passedColor.Sprintf("✓ %s passed", name),
+				skippedColor.Sprintf("in %s", r.duration.Round(time.Millisecond)))
+		}
+	}
+	fmt.Fprintf(buf, "\n")
+	printToStdout(gs, buf.String())
+}
+
+// suiteError combines the results of all tests. The exit code is the one of
+// the first test in the manifest that failed with a specific exit code.
+func suiteError(manifest *suiteManifest, results []suiteTestResult) error {
+	var failed []string
+	var exitCodeErr errext.HasExitCode
+	for i, r := range results {
+		if r.err == nil {
+			continue
+		}
+		failed = append(failed, manifest.Tests[i].Name)
+		if exitCodeErr == nil {
+			_ = errors.As(r.err, &exitCodeErr)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	err := fmt.Errorf("%d of %d tests in the suite didn't pass: %s",
+		len(failed), len(results), strings.Join(failed, ", "))
+	if exitCodeErr != nil {
+		err = errext.WithExitCodeIfNone(err, exitCodeErr.ExitCode())
+	}
+	return err
+}
+
+func getCmdSuite(gs *state.GlobalState) *cobra.Command {
+	c := &cmdSuite{gs: gs}
+
+	exampleText := getExampleText(gs, `
+  # Run the tests of the suite described in the manifest.
+  {{.}} suite suite.json`[1:])
+
+	suiteCmd := &cobra.Command{
+		Use:   "suite",
+		Short: "Run a suite of tests",
+		Long: `Run a suite of tests.
+
+The suite is described by a JSON manifest, which lists the tests with their
+scripts, the tests that each one of them should run after, and their own env
+vars and option overrides. For example:
+
+  {
+    "outputs": ["json=results.json"],
+    "maxParallel": 2,
+    "options": {"noConnectionReuse": true},
+    "tests": [
+      {"name": "smoke", "script": "smoke.js"},
+      {"name": "api", "script": "api.js", "options": {"vus": 10, "duration": "1m"}},
+      {"name": "web", "script": "web.js", "parallel": true, "env": {"HOST": "example.com"}},
+      {"name": "spike", "script": "spike.js", "after": ["api", "web"]}
+    ]
+  }
+
+By default, every test runs after the previous one in the manifest. A test with
+"parallel" starts together with the previous test instead, and a test with
+"after" waits only for the listed tests. A test is skipped if any of the tests
+it waits for didn't pass.
+
+The outputs are shared by all of the tests and every metric sample is tagged
+with the name of its test. Each test has its own end-of-test summary and the
+exit code of the suite is the one of the first failed test in the manifest.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should be the path to the suite manifest"),
+		RunE:    c.run,
+	}
+
+	suiteCmd.Flags().SortFlags = false
+	suiteCmd.Flags().AddFlagSet(runtimeOptionFlagSet(true))
+
+	return suiteCmd
+}
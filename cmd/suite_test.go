@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuiteManifestValidate(t *testing.T) {
+	t.Parallel()
+	testdata := map[string]string{
+		`{"tests": []}`:                   "the suite doesn't have any tests",
+		`{"tests": [{"script": "a.js"}]}`: "test #1 doesn't have a name",
+		`{"tests": [{"name": "a"}]}`:      "test 'a' doesn't have a script",
+		`{"tests": [{"name": "a", "script": "a.js"}, {"name": "a", "script": "b.js"}]}`: "there is more than one test with the name 'a'",
+		`{"tests": [{"name": "a", "script": "a.js", "after": ["b"]}]}`:                  "test 'a' should run after the unknown test 'b'",
+		`{"maxParallel": -1, "tests": [{"name": "a", "script": "a.js"}]}`:               "maxParallel can't be negative",
+		`{"tests": [
+			{"name": "a", "script": "a.js", "after": ["c"]},
+			{"name": "b", "script": "b.js"},
+			{"name": "c", "script": "c.js"}
+		]}`: "the suite has a dependency cycle: a -> c -> b -> a",
+	}
+	for data, expErr := range testdata {
+		m := &suiteManifest{}
+		require.NoError(t, json.Unmarshal([]byte(data), m))
+		assert.EqualError(t, m.validate(), expErr, data)
+	}
+}
+
+func TestSuiteManifestDependencies(t *testing.T) {
+	t.Parallel()
+
+	m := &suiteManifest{}
+	require.NoError(t, json.Unmarshal([]byte(`{"tests": [
+		{"name": "a", "script": "a.js"},
+		{"name": "b", "script": "b.js"},
+		{"name": "c", "script": "c.js", "parallel": true},
+		{"name": "d", "script": "d.js", "after": ["a", "c"]}
+	]}`), m))
+	require.NoError(t, m.validate())
+
+	assert.Empty(t, m.dependencies(0))
+	assert.Equal(t, []string{"a"}, m.dependencies(1))
+	assert.Equal(t, []string{"a"}, m.dependencies(2))
+	assert.Equal(t, []string{"a", "c"}, m.dependencies(3))
+	assert.False(t, m.isSequential())
+
+	m.MaxParallel = 1
+	assert.True(t, m.isSequential())
+
+	m.MaxParallel = 0
+	m.Tests = m.Tests[:2]
+	assert.True(t, m.isSequential())
+
+	// a parallel test starts together with the previous one, even if that one is also parallel
+	m.Tests = append(m.Tests,
+		suiteTest{Name: "c", Script: "c.js", Parallel: true},
+		suiteTest{Name: "d", Script: "d.js", Parallel: true},
+	)
+	assert.Equal(t, []string{"a"}, m.dependencies(3))
+	m.Tests[0].Parallel = true
+	assert.Empty(t, m.dependencies(0))
+}
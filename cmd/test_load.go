@@ -22,6 +22,7 @@ import (
 	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/loader"
 	"go.k6.io/k6/metrics"
+	"gopkg.in/guregu/null.v3"
 )
 
 const (
@@ -60,12 +61,30 @@ func loadLocalTest(gs *state.GlobalState, cmd *cobra.Command, args []string) (*l
 		sourceRootPath, resolvedPath, len(src.Data),
 	)
 
+	return loadTestFromSource(gs, cmd, sourceRootPath, src, fileSystems, pwd)
+}
+
+// loadTestFromSource initializes the first runner for an already read test
+// source, which may be a script or an archive bundle.
+func loadTestFromSource(
+	gs *state.GlobalState, cmd *cobra.Command, sourceRootPath string,
+	src *loader.SourceData, fileSystems map[string]fsext.Fs, pwd string,
+) (*loadedTest, error) {
 	gs.Logger.Debugf("Gathering k6 runtime options...")
 	runtimeOptions, err := getRuntimeOptions(cmd.Flags(), gs.Env)
 	if err != nil {
 		return nil, err
 	}
 
+	return loadTestWithRuntimeOptions(gs, runtimeOptions, sourceRootPath, src, fileSystems, pwd)
+}
+
+// loadTestWithRuntimeOptions is like loadTestFromSource, but it uses the
+// given runtime options instead of getting them from the CLI flags.
+func loadTestWithRuntimeOptions(
+	gs *state.GlobalState, runtimeOptions lib.RuntimeOptions, sourceRootPath string,
+	src *loader.SourceData, fileSystems map[string]fsext.Fs, pwd string,
+) (*loadedTest, error) {
 	registry := metrics.NewRegistry()
 	state := &lib.TestPreInitState{
 		Logger:         gs.Logger,
@@ -79,6 +98,20 @@ func loadLocalTest(gs *state.GlobalState, cmd *cobra.Command, args []string) (*l
 		},
 	}
 
+	if outputDir := runtimeOptions.FSOutputDir; outputDir.Valid && outputDir.String != "" {
+		state.OutputFS = fsext.NewBasePathFs(gs.FS, fsext.Abs(pwd, outputDir.String))
+	}
+
+	if len(runtimeOptions.SecretSources) > 0 {
+		secretsManager, err := createSecretsManager(gs, runtimeOptions.SecretSources, pwd)
+		if err != nil {
+			return nil, err
+		}
+		// The secrets are redacted from the logs before they are written or sent anywhere.
+		secretsManager.Redactor().AddHook(gs.Logger)
+		state.SecretsManager = secretsManager
+	}
+
 	test := &loadedTest{
 		pwd:            pwd,
 		sourceRootPath: sourceRootPath,
@@ -88,7 +121,7 @@ func loadLocalTest(gs *state.GlobalState, cmd *cobra.Command, args []string) (*l
 		preInitState:   state,
 	}
 
-	gs.Logger.Debugf("Initializing k6 runner for '%s' (%s)...", sourceRootPath, resolvedPath)
+	gs.Logger.Debugf("Initializing k6 runner for '%s' (%s)...", sourceRootPath, src.URL)
 	if err := test.initializeFirstRunner(gs); err != nil {
 		return nil, fmt.Errorf("could not initialize '%s': %w", sourceRootPath, err)
 	}
@@ -138,6 +171,13 @@ func (lt *loadedTest) initializeFirstRunner(gs *state.GlobalState) error {
 	case testTypeArchive:
 		logger.Debug("Trying to load test as an archive bundle...")
 
+		// Archives are meant to be portable, so they keep the read-only file system semantics.
+		if lt.preInitState.RuntimeOptions.FSOutputDir.Valid {
+			logger.Warn("Writing files isn't supported when running an archive bundle, ignoring the fs output directory")
+			lt.preInitState.RuntimeOptions.FSOutputDir = null.String{}
+			lt.preInitState.OutputFS = nil
+		}
+
 		var arc *lib.Archive
 		arc, err := lib.ReadArchive(bytes.NewReader(lt.source.Data))
 		if err != nil {
@@ -226,6 +266,14 @@ func (lt *loadedTest) consolidateDeriveAndValidateConfig(
 		return nil, err
 	}
 
+	// The data files of the scenarios are loaded like the files opened in the
+	// init context, so they are a part of the test archive too.
+	if arc := lt.initRunner.MakeArchive(); arc != nil {
+		if err = derivedConfig.Scenarios.LoadDataFiles(arc.Filesystems, arc.PwdURL); err != nil {
+			return nil, errext.WithExitCodeIfNone(err, exitcodes.InvalidConfig)
+		}
+	}
+
 	return &loadedAndConfiguredTest{
 		loadedTest:         lt,
 		consolidatedConfig: consolidatedConfig,
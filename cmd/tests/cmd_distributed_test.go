@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/cmd"
+	"go.k6.io/k6/lib/fsext"
+)
+
+func TestDistributedCoordinatorAndAgents(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		export const options = {
+			scenarios: {
+				test: {
+					executor: 'shared-iterations',
+					vus: 2,
+					iterations: 4,
+				},
+			},
+		};
+
+		export function setup() {
+			console.log('setup() was executed');
+			return { value: 42 };
+		}
+
+		export default function (data) {
+			if (data.value !== 42) {
+				throw new Error('unexpected setup data ' + JSON.stringify(data));
+			}
+		}
+
+		export function teardown() {
+			console.log('teardown() was executed');
+		}
+	`
+
+	coordinator := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(coordinator.FS, filepath.Join(coordinator.Cwd, "test.js"), []byte(script), 0o644))
+	grpcAddr := getFreeBindAddr(t)
+	coordinator.CmdArgs = []string{"k6", "coordinator", "--instance-count", "2", "--grpc-address", grpcAddr, "test.js"}
+
+	const agentsCount = 2
+	agents := make([]*GlobalTestState, agentsCount)
+	for i := range agents {
+		agents[i] = NewGlobalTestState(t)
+		agents[i].CmdArgs = []string{"k6", "agent", "--log-output=stdout", grpcAddr}
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(1 + agentsCount)
+	go func() {
+		defer wg.Done()
+		cmd.ExecuteWithGlobalState(coordinator.GlobalState)
+	}()
+	for _, agent := range agents {
+		agent := agent
+		go func() {
+			defer wg.Done()
+			cmd.ExecuteWithGlobalState(agent.GlobalState)
+		}()
+	}
+	wg.Wait()
+
+	var setupRuns, teardownRuns int
+	for _, agent := range agents {
+		stdout := agent.Stdout.String()
+		t.Log(stdout)
+		assert.Contains(t, stdout, "2 complete and 0 interrupted iterations")
+		setupRuns += strings.Count(stdout, "setup() was executed")
+		teardownRuns += strings.Count(stdout, "teardown() was executed")
+	}
+	assert.Equal(t, 1, setupRuns)
+	assert.Equal(t, 1, teardownRuns)
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/cmd"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/lib/fsext"
+)
+
+func TestReportFromMetricsStore(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		import { Counter } from 'k6/metrics';
+		import { check } from 'k6';
+		const counter = new Counter('test_counter');
+		export const options = { iterations: 5, thresholds: { test_counter: ['count == 15'] } };
+		export default function () {
+			counter.add(1, { kind: 'one' });
+			counter.add(2, { kind: 'two' });
+			check(null, { 'is null': (v) => v === null });
+		}
+	`
+	ts := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), []byte(script), 0o644))
+	ts.CmdArgs = []string{"k6", "run", "--quiet", "--out", "store=/results.k6m", "test.js"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Contains(t, ts.Stdout.String(), "test_counter")
+
+	report := func(t *testing.T, expExitCode exitcodes.ExitCode, args ...string) *GlobalTestState {
+		rts := NewGlobalTestState(t)
+		rts.FS = ts.FS
+		rts.CmdArgs = append([]string{"k6", "report"}, append(args, "/results.k6m")...)
+		rts.ExpectedExitCode = int(expExitCode)
+		cmd.ExecuteWithGlobalState(rts.GlobalState)
+		return rts
+	}
+
+	t.Run("stored thresholds", func(t *testing.T) {
+		t.Parallel()
+		rts := report(t, 0)
+		stdout := rts.Stdout.String()
+		t.Log(stdout)
+		assert.Contains(t, stdout, "✓ is null")
+		assert.Contains(t, stdout, "iterations...........: 5")
+		assert.Contains(t, stdout, "✓ test_counter.........: 15")
+	})
+
+	t.Run("new thresholds and tag filter", func(t *testing.T) {
+		t.Parallel()
+		rts := report(t, exitcodes.ThresholdsHaveFailed,
+			"--tag", "kind=two", "--threshold", "test_counter=count == 15", "--threshold", "test_counter=count > 5")
+		stdout := rts.Stdout.String()
+		t.Log(stdout)
+		assert.Contains(t, stdout, "✗ test_counter...: 10")
+		assert.NotContains(t, stdout, "iterations")
+		assert.Contains(t, rts.Stderr.String(), "thresholds on metrics 'test_counter' have been crossed")
+	})
+
+	t.Run("summary export", func(t *testing.T) {
+		t.Parallel()
+		rts := report(t, 0, "--no-thresholds", "--summary-export", "/summary.json")
+		data, err := fsext.ReadFile(rts.FS, "/summary.json")
+		require.NoError(t, err)
+
+		var summary struct {
+			Metrics map[string]map[string]interface{} `json:"metrics"`
+		}
+		require.NoError(t, json.Unmarshal(data, &summary))
+		assert.EqualValues(t, 15, summary.Metrics["test_counter"]["count"])
+		assert.EqualValues(t, 5, summary.Metrics["iterations"]["count"])
+	})
+
+	t.Run("invalid threshold", func(t *testing.T) {
+		t.Parallel()
+		rts := report(t, exitcodes.InvalidConfig, "--threshold", "unknown_metric=count > 1")
+		assert.Contains(t, rts.Stderr.String(), "invalid threshold on metric 'unknown_metric'")
+	})
+}
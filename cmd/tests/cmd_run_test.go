@@ -440,7 +440,7 @@ func TestExecutionTestOptionsDefaultValues(t *testing.T) {
 	loglines := ts.LoggerHook.Drain()
 	require.Len(t, loglines, 1)
 
-	expected := `{"paused":null,"executionSegment":null,"executionSegmentSequence":null,"noSetup":null,"setupTimeout":null,"noTeardown":null,"teardownTimeout":null,"rps":null,"dns":{"ttl":null,"select":null,"policy":null},"maxRedirects":null,"userAgent":null,"batch":null,"batchPerHost":null,"httpDebug":null,"insecureSkipTLSVerify":null,"tlsCipherSuites":null,"tlsVersion":null,"tlsAuth":null,"throw":null,"thresholds":null,"blacklistIPs":null,"blockHostnames":null,"hosts":null,"noConnectionReuse":null,"noVUConnectionReuse":null,"minIterationDuration":null,"ext":null,"summaryTrendStats":["avg", "min", "med", "max", "p(90)", "p(95)"],"summaryTimeUnit":null,"systemTags":["check","error","error_code","expected_response","group","method","name","proto","scenario","service","status","subproto","tls_version","url"],"tags":null,"metricSamplesBufferSize":null,"noCookiesReset":null,"discardResponseBodies":null,"consoleOutput":null,"scenarios":{"default":{"vus":null,"iterations":1,"executor":"shared-iterations","maxDuration":null,"startTime":null,"env":null,"tags":null,"gracefulStop":null,"exec":null}},"localIPs":null}`
+	expected := `{"paused":null,"executionSegment":null,"executionSegmentSequence":null,"noSetup":null,"setupTimeout":null,"noTeardown":null,"teardownTimeout":null,"rps":null,"dns":{"ttl":null,"select":null,"policy":null},"maxRedirects":null,"userAgent":null,"batch":null,"batchPerHost":null,"httpDebug":null,"insecureSkipTLSVerify":null,"tlsCipherSuites":null,"tlsVersion":null,"tlsAuth":null,"throw":null,"thresholds":null,"blacklistIPs":null,"blockHostnames":null,"hosts":null,"noConnectionReuse":null,"http3":null,"noVUConnectionReuse":null,"minIterationDuration":null,"ext":null,"summaryTrendStats":["avg", "min", "med", "max", "p(90)", "p(95)"],"summaryTimeUnit":null,"trendSink":null,"systemTags":["check","error","error_code","expected_response","group","method","name","proto","scenario","service","status","subproto","tls_version","url"],"tags":null,"metricSamplesBufferSize":null,"noCookiesReset":null,"discardResponseBodies":null,"consoleOutput":null,"scenarios":{"default":{"vus":null,"iterations":1,"executor":"shared-iterations","maxDuration":null,"startTime":null,"env":null,"tags":null,"gracefulStop":null,"exec":null}},"localIPs":null}`
 	assert.JSONEq(t, expected, loglines[0].Message)
 }
 
@@ -674,6 +674,38 @@ func TestThresholdsFailed(t *testing.T) {
 	assert.Contains(t, stdout, `     ✓ { scenario:sc3 }...: 0   0/s`)
 }
 
+func TestWindowedThresholdsFailed(t *testing.T) {
+	t.Parallel()
+	script := `
+		import { Trend } from 'k6/metrics';
+
+		const trend = new Trend('my_trend');
+
+		export const options = {
+			iterations: 1,
+			thresholds: {
+				'my_trend': ['max<500 over 1m', 'max<2000 window=1m'],
+			},
+		};
+
+		export default function () {
+			trend.add(1000);
+		};
+	`
+
+	ts := getSingleFileTestState(t, script, nil, exitcodes.ThresholdsHaveFailed)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	logs := ts.LoggerHook.Drain()
+	assert.True(t, testutils.LogContains(logs, logrus.ErrorLevel, "thresholds on metrics 'my_trend' have been crossed"))
+	assert.True(t, testutils.LogContains(logs, logrus.WarnLevel,
+		"Threshold 'max<500 over 1m' on metric 'my_trend' failed in the time windows 0s-"))
+	assert.False(t, testutils.LogContains(logs, logrus.WarnLevel, "Threshold 'max<2000 window=1m'"))
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, `✗ my_trend`)
+}
+
 func TestAbortedByThreshold(t *testing.T) {
 	t.Parallel()
 	script := `
@@ -863,6 +895,46 @@ func asyncWaitForStdoutAndStopTestFromRESTAPI(
 	})
 }
 
+func TestPrometheusEndpoint(t *testing.T) {
+	t.Parallel()
+	script := `
+		import http from 'k6/http';
+		import { sleep } from 'k6';
+		import { Counter } from 'k6/metrics';
+
+		const myCounter = new Counter('my_counter');
+
+		export const options = {
+			iterations: 3,
+			systemTags: ['scenario'],
+		};
+
+		export default function () {
+			myCounter.add(1, { my_tag: 'value' });
+		};
+
+		export function teardown() {
+			sleep(0.5); // wait for the metrics to be processed
+			const res = http.get('http://' + __ENV.API_ADDRESS + '/metrics');
+			console.log(res.body);
+		}
+	`
+
+	ts := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), []byte(script), 0o644))
+	ts.CmdArgs = []string{
+		"k6", "run", "--quiet", "--log-output=stdout", "--prometheus-enabled",
+		"-e", "API_ADDRESS=" + ts.Flags.Address, "test.js",
+	}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, `k6_my_counter_total{my_tag=\"value\",scenario=\"default\"} 3`)
+	assert.Contains(t, stdout, `k6_iterations_total{scenario=\"default\"} 3`)
+	assert.NotContains(t, stdout, `k6_iterations_total{group=`)
+}
+
 // TODO: add more abort scenario tests, see
 // https://github.com/grafana/k6/issues/2804
 
@@ -2350,3 +2422,306 @@ func TestSetupTimeout(t *testing.T) {
 	t.Log(stderr)
 	assert.Contains(t, stderr, "setup() execution timed out after 1 seconds")
 }
+
+func TestAdaptiveArrivalRate(t *testing.T) {
+	t.Parallel()
+	script := `
+		export const options = {
+			scenarios: {
+				adaptive: {
+					executor: 'adaptive-arrival-rate',
+					startRate: 10,
+					rateStep: 10,
+					stepDuration: '1s',
+					duration: '3500ms',
+					preAllocatedVUs: 5,
+					conditions: { iterations: ['count<25'] },
+				},
+			},
+		};
+		export default function () {};
+	`
+
+	ts := getSingleFileTestState(t, script, []string{"--quiet"}, 0)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Regexp(t, `adaptive_max_passing_rate\.+: 20 `, stdout)
+	assert.Contains(t, ts.Stderr.String(), "The highest passing rate was 20.00 iterations/s")
+
+	ts = getSingleFileTestState(t, script, []string{"--no-summary", "--no-thresholds"}, 0)
+	ts.ExpectedExitCode = -1
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Contains(t, ts.Stderr.String(), "the metrics aren't processed")
+}
+
+func TestReplayExecutor(t *testing.T) {
+	t.Parallel()
+	script := `
+		import exec from 'k6/execution';
+		import { Counter } from 'k6/metrics';
+
+		const replayed = new Counter('replayed_records');
+
+		export const options = {
+			scenarios: {
+				replay: {
+					executor: 'replay',
+					file: 'access.csv',
+					timestampField: 'time',
+					speed: 10,
+					duration: '5s',
+					preAllocatedVUs: 2,
+				},
+			},
+		};
+
+		export default function () {
+			const record = exec.scenario.record;
+			if (record.id != 'r' + exec.scenario.iterationInTest) {
+				throw new Error('unexpected record ' + JSON.stringify(record));
+			}
+			replayed.add(1, { path: record.path });
+		};
+	`
+
+	ts := getSingleFileTestState(t, script, []string{"--quiet"}, 0)
+	accessLog := "id,time,path\n" +
+		"r0,2023-11-14T22:13:20Z,/a\n" +
+		"r1,2023-11-14T22:13:21Z,/b\n" +
+		"r2,2023-11-14T22:13:21.5Z,/c\n"
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "access.csv"), []byte(accessLog), 0o644))
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Regexp(t, `replayed_records\.+: 3 `, stdout)
+	assert.Regexp(t, `iterations\.+: 3 `, stdout)
+	assert.Empty(t, ts.LoggerHook.Drain())
+
+	// the access log is a part of the archive, so the archive can be run
+	// without it, e.g. in the cloud or by the agents of a distributed test
+	archiveTS := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(archiveTS.FS, filepath.Join(archiveTS.Cwd, "test.js"), []byte(script), 0o644))
+	require.NoError(t, fsext.WriteFile(archiveTS.FS, filepath.Join(archiveTS.Cwd, "access.csv"), []byte(accessLog), 0o644))
+	archiveTS.CmdArgs = []string{"k6", "archive", "test.js"}
+	cmd.ExecuteWithGlobalState(archiveTS.GlobalState)
+	archive, err := fsext.ReadFile(archiveTS.FS, "archive.tar")
+	require.NoError(t, err)
+
+	runTS := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(runTS.FS, filepath.Join(runTS.Cwd, "archive.tar"), archive, 0o644))
+	runTS.CmdArgs = []string{"k6", "run", "--quiet", "archive.tar"}
+	cmd.ExecuteWithGlobalState(runTS.GlobalState)
+
+	stdout = runTS.Stdout.String()
+	t.Log(stdout)
+	assert.Regexp(t, `replayed_records\.+: 3 `, stdout)
+	assert.Empty(t, runTS.LoggerHook.Drain())
+}
+
+func TestScenarioStartAfterAndStartWhen(t *testing.T) {
+	t.Parallel()
+	script := `
+		import exec from 'k6/execution';
+		import { check, sleep } from 'k6';
+		import { Trend } from 'k6/metrics';
+
+		const startedAt = new Trend('started_at', true);
+
+		export const options = {
+			scenarios: {
+				warmup: {
+					executor: 'shared-iterations',
+					exec: 'warmup',
+				},
+				load: {
+					executor: 'shared-iterations',
+					exec: 'load',
+					startAfter: ['warmup'],
+					startWhen: { checks: ['rate==1'] },
+				},
+				spike: {
+					executor: 'shared-iterations',
+					exec: 'spike',
+					startAfter: ['load'],
+					startWhen: { checks: ['rate==1'] },
+				},
+			},
+		};
+
+		export function warmup() {
+			sleep(1);
+			check(null, { 'warmed up': () => true });
+		};
+
+		export function load() {
+			startedAt.add(exec.instance.currentTestRunDuration);
+			check(null, { 'loaded': () => false });
+		};
+
+		export function spike() {
+			throw new Error('the spike scenario should be skipped');
+		};
+	`
+
+	ts := getSingleFileTestState(t, script, []string{"--quiet", "--summary-trend-stats", "min"}, 0)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Regexp(t, `started_at\.+: min=1(\.\d+)?s`, stdout)
+	assert.Regexp(t, `iterations\.+: 2 `, stdout)
+
+	stderr := ts.Stderr.String()
+	assert.Contains(t, stderr, "Skipping scenario spike, since its startWhen conditions on checks didn't pass")
+	assert.NotContains(t, stderr, "the spike scenario should be skipped")
+}
+
+func getRestAPITestState(t *testing.T, script string, expExitCode exitcodes.ExitCode) *GlobalTestState {
+	ts := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), []byte(script), 0o644))
+	ts.CmdArgs = []string{
+		"k6", "run", "--quiet", "--log-output=stdout", "-e", "API_ADDRESS=" + ts.Flags.Address, "test.js",
+	}
+	ts.ExpectedExitCode = int(expExitCode)
+	return ts
+}
+
+func TestStoppedGracefullyWithRestAPI(t *testing.T) {
+	t.Parallel()
+	script := `
+		import http from 'k6/http';
+		import exec from 'k6/execution';
+		import { sleep } from 'k6';
+
+		export const options = {
+			scenarios: {
+				main: { executor: 'constant-vus', vus: 2, duration: '1m', gracefulStop: '5s' },
+				later: { executor: 'shared-iterations', startTime: '30s', exec: 'later' },
+			},
+		};
+
+		export default function () {
+			if (exec.scenario.iterationInTest == 0) {
+				const res = http.post('http://' + __ENV.API_ADDRESS + '/v1/stop');
+				console.log('stop response: ' + res.status);
+			}
+			sleep(1);
+			console.log('iteration finished');
+		};
+
+		export function later() {
+			console.log('the later scenario should be skipped');
+		}
+
+		export function teardown() {
+			console.log('teardown() called');
+		}
+	`
+
+	ts := getRestAPITestState(t, script, 0)
+	startTime := time.Now()
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Less(t, time.Since(startTime), 10*time.Second)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, "stop response: 200")
+	assert.Equal(t, 2, strings.Count(stdout, "iteration finished"))
+	assert.Contains(t, stdout, "teardown() called")
+	assert.NotContains(t, stdout, "the later scenario should be skipped")
+}
+
+func TestAbortedWithRestAPIAndExitCode(t *testing.T) {
+	t.Parallel()
+	script := `
+		import http from 'k6/http';
+		import { sleep } from 'k6';
+
+		export default function () {
+			http.post('http://' + __ENV.API_ADDRESS + '/v1/abort', JSON.stringify({
+				data: { type: 'abort', id: 'default', attributes: { reason: 'the system is down', 'exit-code': 42 } },
+			}));
+			sleep(10);
+		};
+
+		export function teardown() {
+			console.log('teardown() called');
+		}
+	`
+
+	ts := getRestAPITestState(t, script, 42)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, `level=error msg="test run aborted from REST API: the system is down"`)
+	assert.Contains(t, stdout, "teardown() called")
+}
+
+func TestThresholdsUpdatedWithRestAPI(t *testing.T) {
+	t.Parallel()
+	script := `
+		import http from 'k6/http';
+
+		export const options = {
+			iterations: 5,
+			thresholds: { checks: ['rate==1'] },
+		};
+
+		export function setup() {
+			const url = 'http://' + __ENV.API_ADDRESS + '/v1/thresholds/';
+			let res = http.put(url + 'iterations', JSON.stringify({
+				data: { type: 'thresholds', attributes: { thresholds: ['count<3'] } },
+			}));
+			console.log('put response: ' + res.status);
+			res = http.del(url + 'checks');
+			console.log('delete response: ' + res.status);
+		}
+
+		export default function () {};
+	`
+
+	ts := getRestAPITestState(t, script, exitcodes.ThresholdsHaveFailed)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, "put response: 200")
+	assert.Contains(t, stdout, "delete response: 204")
+	assert.Contains(t, stdout, `level=error msg="thresholds on metrics 'iterations' have been crossed"`)
+}
+
+func TestSecretsAreRedacted(t *testing.T) {
+	t.Parallel()
+	script := `
+		import secrets from 'k6/secrets';
+		import { group } from 'k6';
+
+		export const options = { iterations: 1 };
+
+		export default async function () {
+			const token = await secrets.get('token');
+			console.log('the token is ' + token);
+			group('group of ' + token, () => {});
+		};
+	`
+
+	ts := getSingleFileTestState(t, script, []string{"--secret-source", "env", "--log-output=stdout"}, 0)
+	ts.Env["K6_SECRET_token"] = "s3cr3t-t0k3n"
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.NotContains(t, stdout, "s3cr3t-t0k3n")
+	assert.Contains(t, stdout, "the token is ***SECRET_REDACTED***")
+	assert.Contains(t, stdout, "group of ***SECRET_REDACTED***")
+
+	logs := ts.LoggerHook.Drain()
+	assert.True(t, testutils.LogContains(logs, logrus.InfoLevel, "the token is ***SECRET_REDACTED***"))
+	for _, entry := range logs {
+		assert.NotContains(t, entry.Message, "s3cr3t-t0k3n")
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/cmd"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/lib/fsext"
+)
+
+func getSuiteTestState(t *testing.T, manifest string, scripts map[string]string, expExitCode exitcodes.ExitCode) *GlobalTestState {
+	t.Helper()
+	ts := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "suite", "suite.json"), []byte(manifest), 0o644))
+	for name, script := range scripts {
+		require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "suite", name), []byte(script), 0o644))
+	}
+	ts.CmdArgs = []string{"k6", "suite", "--log-output=stdout", "suite/suite.json"}
+	ts.ExpectedExitCode = int(expExitCode)
+	return ts
+}
+
+func TestSuiteDependencies(t *testing.T) {
+	t.Parallel()
+
+	passing := `
+		import { Counter } from 'k6/metrics';
+		const counter = new Counter('test_counter');
+		export const options = { iterations: 1, thresholds: { test_counter: ['count == 1'] } };
+		export default function () { counter.add(1); }
+	`
+	failing := `
+		import { Counter } from 'k6/metrics';
+		const counter = new Counter('test_counter');
+		export const options = { iterations: 1, thresholds: { test_counter: ['count == 2'] } };
+		export default function () { counter.add(1); }
+	`
+	manifest := `{
+		"tests": [
+			{"name": "first", "script": "passing.js"},
+			{"name": "second", "script": "failing.js"},
+			{"name": "third", "script": "passing.js"},
+			{"name": "fourth", "script": "passing.js", "after": ["first"]}
+		]
+	}`
+
+	ts := getSuiteTestState(t, manifest, map[string]string{"passing.js": passing, "failing.js": failing},
+		exitcodes.ThresholdsHaveFailed)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, "✓ first  passed")
+	assert.Contains(t, stdout, "✗ second failed")
+	assert.Contains(t, stdout, "thresholds on metrics 'test_counter' have been crossed")
+	assert.Contains(t, stdout, "↷ third  skipped, test 'second' didn't pass")
+	assert.Contains(t, stdout, "✓ fourth passed")
+	assert.Contains(t, stdout, "2 of 4 tests in the suite didn't pass: second, third")
+}
+
+func TestSuiteSharedOutputsAndOverrides(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		import { Counter } from 'k6/metrics';
+		const counter = new Counter('test_counter');
+		export const options = { iterations: 1 };
+		export default function () { counter.add(Number(__ENV.VALUE)); }
+	`
+	manifest := `{
+		"outputs": ["json=results.json"],
+		"options": {"tags": {"suite": "mine"}},
+		"tests": [
+			{"name": "one", "script": "test.js", "env": {"VALUE": "1"}},
+			{"name": "two", "script": "test.js", "parallel": true, "env": {"VALUE": "2"},
+			 "options": {"iterations": 3, "vus": 3}}
+		]
+	}`
+
+	ts := getSuiteTestState(t, manifest, map[string]string{"test.js": script}, 0)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, "✓ one passed")
+	assert.Contains(t, stdout, "✓ two passed")
+
+	jsonResults, err := fsext.ReadFile(ts.FS, "results.json")
+	require.NoError(t, err)
+	testdata := map[string]struct{ iterations, total float64 }{
+		"one": {iterations: 1, total: 1},
+		"two": {iterations: 3, total: 6},
+	}
+	for test, expected := range testdata {
+		tags := map[string]string{"test": test, "suite": "mine"}
+		assert.Equal(t, expected.iterations, sum(getSampleValues(t, jsonResults, "iterations", tags)), test)
+		assert.Equal(t, expected.total, sum(getSampleValues(t, jsonResults, "test_counter", tags)), test)
+	}
+}
+
+func TestSuiteInvalidManifest(t *testing.T) {
+	t.Parallel()
+
+	manifest := `{
+		"tests": [
+			{"name": "a", "script": "test.js", "after": ["b"]},
+			{"name": "b", "script": "test.js"}
+		]
+	}`
+	ts := getSuiteTestState(t, manifest, nil, 0)
+	ts.ExpectedExitCode = -1
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	assert.Contains(t, ts.Stdout.String(), "the suite has a dependency cycle: a -> b -> a")
+}
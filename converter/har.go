@@ -0,0 +1,235 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minThinkTime is the minimum pause between two recorded requests that is
+// converted to a sleep() call. Shorter pauses are usually just the browser
+// processing the previous responses.
+const minThinkTime = 500 * time.Millisecond
+
+// headers that are either set by k6 itself or handled separately
+//
+//nolint:gochecknoglobals
+var skippedHARHeaders = map[string]bool{
+	"host":              true,
+	"connection":        true,
+	"content-length":    true,
+	"cookie":            true,
+	"transfer-encoding": true,
+	"upgrade":           true,
+	"keep-alive":        true,
+}
+
+// ConvertHAR generates a k6 test script that replays the requests in the
+// given HAR recording.
+//
+// Requests are grouped by the page they were made for, with their headers
+// and bodies, and a check for the recorded status of their response. Pauses
+// between requests longer than half a second are converted to sleep() calls.
+// Cookies that are set by responses in the recording are not hardcoded, but
+// left to the cookie jar of the VU instead, so they are correlated.
+func ConvertHAR(h *HAR) (string, error) {
+	if h == nil || h.Log == nil {
+		return "", errors.New("the HAR recording doesn't have a log")
+	}
+
+	entries := make([]HAREntry, 0, len(h.Log.Entries))
+	for _, e := range h.Log.Entries {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil {
+			return "", fmt.Errorf("invalid URL '%s' in the HAR recording: %w", e.Request.URL, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) == 0 {
+		return "", errors.New("the HAR recording doesn't have any HTTP requests")
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedDateTime.Before(entries[j].StartedDateTime)
+	})
+
+	pages := make(map[string]HARPage, len(h.Log.Pages))
+	for _, p := range h.Log.Pages {
+		pages[p.ID] = p
+	}
+
+	w := &scriptWriter{}
+	w.line("import { check, group, sleep } from 'k6';")
+	w.line("import http from 'k6/http';")
+	w.line("")
+	if c := h.Log.Creator; c != nil && c.Name != "" {
+		w.line("// Generated from a HAR recording made with %s %s.", c.Name, c.Version)
+	} else {
+		w.line("// Generated from a HAR recording.")
+	}
+	w.line("")
+	w.block("export const options = {", "};", func() {
+		w.line("vus: 1,")
+		w.line("iterations: 1,")
+		w.line("// Redirects were recorded as separate requests, so they shouldn't be followed.")
+		w.line("maxRedirects: 0,")
+	})
+	w.line("")
+	var err error
+	w.block("export default function () {", "}", func() {
+		w.line("let res;")
+		c := &harConverter{w: w, pages: pages, setCookies: make(map[string]bool)}
+		err = c.writeEntries(entries)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return w.String(), nil
+}
+
+type harConverter struct {
+	w          *scriptWriter
+	pages      map[string]HARPage
+	setCookies map[string]bool
+}
+
+func (c *harConverter) writeEntries(entries []HAREntry) error {
+	var (
+		lastEnd time.Time
+		err     error
+	)
+	for i := 0; i < len(entries); {
+		// all consecutive requests for the same page are put in a single group
+		j := i + 1
+		for j < len(entries) && entries[j].Pageref == entries[i].Pageref {
+			j++
+		}
+
+		c.w.line("")
+		c.writeThinkTime(lastEnd, entries[i].StartedDateTime)
+		pageEntries := entries[i:j]
+		if entries[i].Pageref == "" {
+			lastEnd, err = c.writeRequests(pageEntries, lastEnd)
+		} else {
+			c.w.block(fmt.Sprintf("group(%s, function () {", jsString(c.groupName(entries[i].Pageref))), "});", func() {
+				lastEnd, err = c.writeRequests(pageEntries, time.Time{})
+			})
+		}
+		if err != nil {
+			return err
+		}
+		i = j
+	}
+	return nil
+}
+
+func (c *harConverter) groupName(pageref string) string {
+	page, ok := c.pages[pageref]
+	if !ok || page.Title == "" {
+		return pageref
+	}
+	return page.Title
+}
+
+func (c *harConverter) writeRequests(entries []HAREntry, lastEnd time.Time) (time.Time, error) {
+	for i, e := range entries {
+		if i > 0 {
+			c.w.line("")
+			c.writeThinkTime(lastEnd, e.StartedDateTime)
+		}
+		if err := c.writeRequest(e); err != nil {
+			return lastEnd, fmt.Errorf("couldn't convert the %s request to %s: %w", e.Request.Method, e.Request.URL, err)
+		}
+
+		end := e.StartedDateTime.Add(time.Duration(e.Time * float64(time.Millisecond)))
+		if end.After(lastEnd) {
+			lastEnd = end
+		}
+	}
+	return lastEnd, nil
+}
+
+func (c *harConverter) writeThinkTime(lastEnd, start time.Time) {
+	if lastEnd.IsZero() {
+		return
+	}
+	if pause := start.Sub(lastEnd); pause >= minThinkTime {
+		c.w.line("sleep(%s);", strconv.FormatFloat(pause.Round(10*time.Millisecond).Seconds(), 'f', -1, 64))
+		c.w.line("")
+	}
+}
+
+func (c *harConverter) writeRequest(e HAREntry) error {
+	req := e.Request
+
+	params := newOrderedMap()
+	headers := newOrderedMap()
+	for _, h := range req.Headers {
+		name := strings.ToLower(h.Name)
+		if strings.HasPrefix(name, ":") || skippedHARHeaders[name] {
+			continue
+		}
+		if v, ok := headers.values[h.Name].(string); ok {
+			headers.set(h.Name, v+", "+h.Value)
+		} else {
+			headers.set(h.Name, h.Value)
+		}
+	}
+	if headers.len() > 0 {
+		params.set("headers", headers)
+	}
+
+	// cookies that were set by earlier responses will be in the cookie jar
+	cookies := newOrderedMap()
+	for _, cookie := range req.Cookies {
+		if !c.setCookies[cookie.Name] {
+			cookies.set(cookie.Name, cookie.Value)
+		}
+	}
+	if cookies.len() > 0 {
+		params.set("cookies", cookies)
+	}
+	for _, cookie := range e.Response.Cookies {
+		c.setCookies[cookie.Name] = true
+	}
+
+	var err error
+	body := ""
+	if pd := req.PostData; pd != nil {
+		switch {
+		case pd.Text != "":
+			body = jsString(pd.Text)
+		case len(pd.Params) > 0:
+			form := newOrderedMap()
+			for _, p := range pd.Params {
+				if p.FileName == "" {
+					form.set(p.Name, p.Value)
+				}
+			}
+			if body, err = jsValue(form, c.w.indent); err != nil {
+				return err
+			}
+		}
+	}
+
+	paramsExpr := ""
+	if params.len() > 0 {
+		if paramsExpr, err = jsValue(params, c.w.indent); err != nil {
+			return err
+		}
+	}
+	c.w.writeRequest(req.Method, jsString(req.URL), body, paramsExpr)
+
+	// aborted and blocked requests have no status
+	if e.Response.Status > 0 {
+		c.w.writeStatusCheck([]int{e.Response.Status})
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package converter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHAR = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "WebInspector", "version": "537.36"},
+    "pages": [
+      {"startedDateTime": "2024-01-01T10:00:00.000Z", "id": "page_1", "title": "https://example.com/"},
+      {"startedDateTime": "2024-01-01T10:00:05.000Z", "id": "page_2", "title": "Login"}
+    ],
+    "entries": [
+      {
+        "pageref": "page_1",
+        "startedDateTime": "2024-01-01T10:00:00.000Z",
+        "time": 100,
+        "request": {
+          "method": "GET",
+          "url": "https://example.com/",
+          "headers": [
+            {"name": ":authority", "value": "example.com"},
+            {"name": "accept", "value": "text/html"},
+            {"name": "cookie", "value": "consent=yes"}
+          ],
+          "cookies": [{"name": "consent", "value": "yes"}]
+        },
+        "response": {
+          "status": 200,
+          "cookies": [{"name": "session", "value": "abc"}]
+        }
+      },
+      {
+        "pageref": "page_1",
+        "startedDateTime": "2024-01-01T10:00:00.050Z",
+        "time": 20,
+        "request": {"method": "GET", "url": "data:image/png;base64,AAAA"},
+        "response": {"status": 200}
+      },
+      {
+        "pageref": "page_1",
+        "startedDateTime": "2024-01-01T10:00:00.150Z",
+        "time": 50,
+        "request": {"method": "GET", "url": "https://example.com/style.css"},
+        "response": {"status": 0}
+      },
+      {
+        "pageref": "page_2",
+        "startedDateTime": "2024-01-01T10:00:05.200Z",
+        "time": 100,
+        "request": {
+          "method": "POST",
+          "url": "https://example.com/login",
+          "headers": [
+            {"name": "Content-Type", "value": "application/x-www-form-urlencoded"},
+            {"name": "Content-Length", "value": "20"},
+            {"name": "Cookie", "value": "consent=yes; session=abc"}
+          ],
+          "cookies": [{"name": "consent", "value": "yes"}, {"name": "session", "value": "abc"}],
+          "postData": {"mimeType": "application/x-www-form-urlencoded", "text": "user=admin&pass=123"}
+        },
+        "response": {"status": 302}
+      }
+    ]
+  }
+}`
+
+func TestConvertHAR(t *testing.T) {
+	t.Parallel()
+
+	har := &HAR{}
+	require.NoError(t, json.Unmarshal([]byte(testHAR), har))
+	script, err := ConvertHAR(har)
+	require.NoError(t, err)
+
+	expected := `import { check, group, sleep } from 'k6';
+import http from 'k6/http';
+
+// Generated from a HAR recording made with WebInspector 537.36.
+
+export const options = {
+  vus: 1,
+  iterations: 1,
+  // Redirects were recorded as separate requests, so they shouldn't be followed.
+  maxRedirects: 0,
+};
+
+export default function () {
+  let res;
+
+  group("https://example.com/", function () {
+    res = http.get("https://example.com/", {
+      "headers": {
+        "accept": "text/html"
+      },
+      "cookies": {
+        "consent": "yes"
+      }
+    });
+    check(res, { 'status is 200': (r) => r.status === 200 });
+
+    res = http.get("https://example.com/style.css");
+  });
+
+  sleep(5);
+
+  group("Login", function () {
+    res = http.post("https://example.com/login", "user=admin&pass=123", {
+      "headers": {
+        "Content-Type": "application/x-www-form-urlencoded"
+      },
+      "cookies": {
+        "consent": "yes"
+      }
+    });
+    check(res, { 'status is 302': (r) => r.status === 302 });
+  });
+}
+`
+	assert.Equal(t, expected, script)
+}
+
+func TestConvertHARErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := ConvertHAR(&HAR{})
+	assert.EqualError(t, err, "the HAR recording doesn't have a log")
+
+	_, err = ConvertHAR(&HAR{Log: &HARLog{Entries: []HAREntry{{Request: HARRequest{URL: "data:text/plain,a"}}}}})
+	assert.EqualError(t, err, "the HAR recording doesn't have any HTTP requests")
+}
@@ -0,0 +1,91 @@
+package converter
+
+import "time"
+
+// HAR is the root object of an HTTP Archive, as described in
+// http://www.softwareishard.com/blog/har-12-spec/
+type HAR struct {
+	Log *HARLog `json:"log"`
+}
+
+// HARLog is the log object of an HTTP Archive.
+type HARLog struct {
+	Version string      `json:"version"`
+	Creator *HARCreator `json:"creator"`
+	Pages   []HARPage   `json:"pages,omitempty"`
+	Entries []HAREntry  `json:"entries"`
+	Comment string      `json:"comment,omitempty"`
+}
+
+// HARCreator describes the application that created the HTTP Archive.
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HARPage is a single exported page.
+type HARPage struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	ID              string    `json:"id"`
+	Title           string    `json:"title"`
+}
+
+// HAREntry is a single exported HTTP request.
+type HAREntry struct {
+	Pageref         string      `json:"pageref,omitempty"`
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+}
+
+// HARRequest contains the details about a recorded request.
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+	Comment     string         `json:"comment,omitempty"`
+}
+
+// HARResponse contains the details about a recorded response.
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	RedirectURL string         `json:"redirectURL"`
+}
+
+// HARCookie is a cookie that was sent with a request or set by a response.
+type HARCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARNameValue is a name and value pair, used for headers and query strings.
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData describes the body of a recorded request.
+type HARPostData struct {
+	MimeType string         `json:"mimeType"`
+	Params   []HARPostParam `json:"params,omitempty"`
+	Text     string         `json:"text,omitempty"`
+}
+
+// HARPostParam is a single parameter of a form request body.
+type HARPostParam struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
@@ -0,0 +1,573 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// the order in which the operations of a single path are written
+//
+//nolint:gochecknoglobals
+var openAPIMethods = []string{"get", "post", "put", "patch", "delete", "head", "options", "trace"}
+
+type openAPISpec struct {
+	OpenAPI string `yaml:"openapi"`
+	Swagger string `yaml:"swagger"`
+	Info    struct {
+		Title   string `yaml:"title"`
+		Version string `yaml:"version"`
+	} `yaml:"info"`
+	Servers    []openAPIServer `yaml:"servers"`
+	Paths      yaml.Node       `yaml:"paths"`
+	Components struct {
+		Schemas       map[string]*openAPISchema      `yaml:"schemas"`
+		Parameters    map[string]*openAPIParameter   `yaml:"parameters"`
+		RequestBodies map[string]*openAPIRequestBody `yaml:"requestBodies"`
+	} `yaml:"components"`
+}
+
+type openAPIServer struct {
+	URL       string `yaml:"url"`
+	Variables map[string]struct {
+		Default string `yaml:"default"`
+	} `yaml:"variables"`
+}
+
+type openAPIOperation struct {
+	OperationID string               `yaml:"operationId"`
+	Summary     string               `yaml:"summary"`
+	Tags        []string             `yaml:"tags"`
+	Parameters  []*openAPIParameter  `yaml:"parameters"`
+	RequestBody *openAPIRequestBody  `yaml:"requestBody"`
+	Responses   map[string]yaml.Node `yaml:"responses"`
+}
+
+type openAPIParameter struct {
+	Ref      string         `yaml:"$ref"`
+	Name     string         `yaml:"name"`
+	In       string         `yaml:"in"`
+	Required bool           `yaml:"required"`
+	Schema   *openAPISchema `yaml:"schema"`
+	Example  interface{}    `yaml:"example"`
+}
+
+type openAPIRequestBody struct {
+	Ref     string                      `yaml:"$ref"`
+	Content map[string]openAPIMediaType `yaml:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema  *openAPISchema `yaml:"schema"`
+	Example interface{}    `yaml:"example"`
+}
+
+type openAPISchema struct {
+	Ref        string                    `yaml:"$ref"`
+	Type       interface{}               `yaml:"type"` // a string or, since OpenAPI 3.1, a list
+	Format     string                    `yaml:"format"`
+	Example    interface{}               `yaml:"example"`
+	Default    interface{}               `yaml:"default"`
+	Enum       []interface{}             `yaml:"enum"`
+	Properties map[string]*openAPISchema `yaml:"properties"`
+	Items      *openAPISchema            `yaml:"items"`
+	AllOf      []*openAPISchema          `yaml:"allOf"`
+	OneOf      []*openAPISchema          `yaml:"oneOf"`
+	AnyOf      []*openAPISchema          `yaml:"anyOf"`
+}
+
+type openAPIOperationRef struct {
+	path      string
+	method    string
+	operation *openAPIOperation
+	params    []*openAPIParameter
+}
+
+// ConvertOpenAPI generates a k6 test script that calls every operation of
+// the given OpenAPI 3.x specification, in either its JSON or YAML form.
+//
+// Operations are grouped by their first tag. Their parameters and request
+// bodies are filled with the examples from the specification or with values
+// generated from their schemas, and the statuses of their responses are
+// checked against the successful responses in the specification.
+func ConvertOpenAPI(data []byte) (string, error) {
+	spec := &openAPISpec{}
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return "", fmt.Errorf("couldn't parse the OpenAPI specification: %w", err)
+	}
+	if spec.Swagger != "" {
+		return "", fmt.Errorf("swagger %s specifications aren't supported, only OpenAPI 3.x ones are", spec.Swagger)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		return "", errors.New("the file isn't an OpenAPI 3.x specification")
+	}
+
+	groups, tags, err := spec.operationsByTag()
+	if err != nil {
+		return "", err
+	}
+	if len(tags) == 0 {
+		return "", errors.New("the OpenAPI specification doesn't have any operations")
+	}
+
+	w := &scriptWriter{}
+	w.line("import { check, group, sleep } from 'k6';")
+	w.line("import http from 'k6/http';")
+	w.line("")
+	if spec.Info.Title != "" {
+		w.line("// Generated from the OpenAPI specification of %s %s.", spec.Info.Title, spec.Info.Version)
+	} else {
+		w.line("// Generated from an OpenAPI specification.")
+	}
+	w.line("")
+	w.line("const BASE_URL = __ENV.BASE_URL || %s;", jsString(spec.baseURL()))
+	w.line("")
+	w.block("export const options = {", "};", func() {
+		w.line("vus: 1,")
+		w.line("iterations: 1,")
+	})
+	w.line("")
+	w.block("export default function () {", "}", func() {
+		w.line("let res;")
+		for _, tag := range tags {
+			w.line("")
+			if tag == "" {
+				err = spec.writeOperations(w, groups[tag])
+			} else {
+				w.block(fmt.Sprintf("group(%s, function () {", jsString(tag)), "});", func() {
+					err = spec.writeOperations(w, groups[tag])
+				})
+			}
+			if err != nil {
+				return
+			}
+		}
+		w.line("")
+		w.line("sleep(1);")
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return w.String(), nil
+}
+
+// operationsByTag returns the operations in the specification grouped by
+// their first tag, and the tags in the order in which they first appear.
+// Operations without tags are returned with an empty tag.
+func (s *openAPISpec) operationsByTag() (map[string][]openAPIOperationRef, []string, error) {
+	groups := make(map[string][]openAPIOperationRef)
+	var tags []string
+
+	if s.Paths.Kind != yaml.MappingNode {
+		return groups, tags, nil
+	}
+	// the paths are walked as nodes to keep the order from the specification
+	for i := 0; i+1 < len(s.Paths.Content); i += 2 {
+		path := s.Paths.Content[i].Value
+		// path items can have other fields too, like summary and servers
+		item := make(map[string]yaml.Node)
+		if err := s.Paths.Content[i+1].Decode(&item); err != nil {
+			return nil, nil, fmt.Errorf("invalid path item '%s' in the OpenAPI specification: %w", path, err)
+		}
+		var pathParams []*openAPIParameter
+		if node, ok := item["parameters"]; ok {
+			if err := node.Decode(&pathParams); err != nil {
+				return nil, nil, fmt.Errorf("invalid parameters of path '%s' in the OpenAPI specification: %w", path, err)
+			}
+		}
+		for _, method := range openAPIMethods {
+			node, ok := item[method]
+			if !ok {
+				continue
+			}
+			op := &openAPIOperation{}
+			if err := node.Decode(op); err != nil {
+				return nil, nil, fmt.Errorf("invalid operation %s %s in the OpenAPI specification: %w",
+					strings.ToUpper(method), path, err)
+			}
+			tag := ""
+			if len(op.Tags) > 0 {
+				tag = op.Tags[0]
+			}
+			if _, ok := groups[tag]; !ok {
+				tags = append(tags, tag)
+			}
+			groups[tag] = append(groups[tag], openAPIOperationRef{
+				path:      path,
+				method:    method,
+				operation: op,
+				params:    s.mergeParameters(pathParams, op.Parameters),
+			})
+		}
+	}
+	return groups, tags, nil
+}
+
+// mergeParameters returns the parameters of an operation, including the
+// ones defined for its whole path that it doesn't override.
+func (s *openAPISpec) mergeParameters(pathParams, opParams []*openAPIParameter) []*openAPIParameter {
+	var result []*openAPIParameter
+	seen := make(map[string]bool)
+	all := make([]*openAPIParameter, 0, len(opParams)+len(pathParams))
+	all = append(append(all, opParams...), pathParams...)
+	for _, p := range all {
+		p = s.resolveParameter(p)
+		if p == nil || seen[p.In+":"+p.Name] {
+			continue
+		}
+		seen[p.In+":"+p.Name] = true
+		result = append(result, p)
+	}
+	return result
+}
+
+//nolint:gochecknoglobals
+var serverVariableRegex = regexp.MustCompile(`\{([^}]+)\}`)
+
+func (s *openAPISpec) baseURL() string {
+	if len(s.Servers) == 0 {
+		return "http://localhost"
+	}
+	server := s.Servers[0]
+	u := serverVariableRegex.ReplaceAllStringFunc(server.URL, func(m string) string {
+		return server.Variables[m[1:len(m)-1]].Default
+	})
+	if !strings.Contains(u, "://") {
+		u = "http://localhost" + "/" + strings.TrimPrefix(u, "/")
+	}
+	return strings.TrimSuffix(u, "/")
+}
+
+func (s *openAPISpec) writeOperations(w *scriptWriter, ops []openAPIOperationRef) error {
+	for i, op := range ops {
+		if i > 0 {
+			w.line("")
+		}
+		if err := s.writeOperation(w, op); err != nil {
+			return fmt.Errorf("couldn't convert the %s %s operation: %w", strings.ToUpper(op.method), op.path, err)
+		}
+	}
+	return nil
+}
+
+func (s *openAPISpec) writeOperation(w *scriptWriter, op openAPIOperationRef) error {
+	description := op.operation.Summary
+	if description == "" {
+		description = op.operation.OperationID
+	}
+	if description != "" {
+		w.line("// %s %s - %s", strings.ToUpper(op.method), op.path, strings.Join(strings.Fields(description), " "))
+	} else {
+		w.line("// %s %s", strings.ToUpper(op.method), op.path)
+	}
+
+	path := op.path
+	query := make([]string, 0)
+	headers := newOrderedMap()
+	cookies := newOrderedMap()
+	for _, p := range op.params {
+		switch p.In {
+		case "path":
+			value := fmt.Sprint(s.parameterValue(p))
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(value))
+		case "query":
+			if p.Required {
+				value := fmt.Sprint(s.parameterValue(p))
+				query = append(query, url.QueryEscape(p.Name)+"="+url.QueryEscape(value))
+			}
+		case "header":
+			if p.Required {
+				headers.set(p.Name, fmt.Sprint(s.parameterValue(p)))
+			}
+		case "cookie":
+			if p.Required {
+				cookies.set(p.Name, fmt.Sprint(s.parameterValue(p)))
+			}
+		}
+	}
+	target := path
+	if len(query) > 0 {
+		target += "?" + strings.Join(query, "&")
+	}
+
+	body := ""
+	if rb := s.resolveRequestBody(op.operation.RequestBody); rb != nil {
+		var contentType string
+		var err error
+		contentType, body, err = s.requestBody(rb, w.indent)
+		if err != nil {
+			return fmt.Errorf("invalid request body: %w", err)
+		}
+		if contentType != "" {
+			headers.set("Content-Type", contentType)
+		}
+	}
+
+	params := newOrderedMap()
+	if headers.len() > 0 {
+		params.set("headers", headers)
+	}
+	if cookies.len() > 0 {
+		params.set("cookies", cookies)
+	}
+	paramsExpr := ""
+	if params.len() > 0 {
+		var err error
+		if paramsExpr, err = jsValue(params, w.indent); err != nil {
+			return fmt.Errorf("invalid parameters: %w", err)
+		}
+	}
+
+	w.writeRequest(op.method, "`${BASE_URL}"+escapeTemplateLiteral(target)+"`", body, paramsExpr)
+	w.writeStatusCheck(successfulStatuses(op.operation.Responses))
+	return nil
+}
+
+// requestBody returns the content type and a JavaScript expression for the
+// body of a request, preferring JSON and then form bodies.
+func (s *openAPISpec) requestBody(rb *openAPIRequestBody, indent int) (string, string, error) {
+	contentTypes := make([]string, 0, len(rb.Content))
+	for ct := range rb.Content {
+		contentTypes = append(contentTypes, ct)
+	}
+	sort.Strings(contentTypes)
+
+	rank := func(ct string) int {
+		switch {
+		case ct == "application/json" || strings.HasSuffix(ct, "+json"):
+			return 0
+		case ct == "application/x-www-form-urlencoded":
+			return 1
+		case ct == "multipart/form-data":
+			return 2
+		case strings.HasPrefix(ct, "text/"):
+			return 3
+		default:
+			return 4
+		}
+	}
+	sort.SliceStable(contentTypes, func(i, j int) bool {
+		return rank(contentTypes[i]) < rank(contentTypes[j])
+	})
+	if len(contentTypes) == 0 {
+		return "", "", nil
+	}
+
+	ct := contentTypes[0]
+	media := rb.Content[ct]
+	value := media.Example
+	if value == nil {
+		value = s.exampleValue(media.Schema, make(map[string]bool))
+	}
+	switch rank(ct) {
+	case 0:
+		if value == nil {
+			value = map[string]interface{}{}
+		}
+		body, err := jsValue(value, indent)
+		if err != nil {
+			return "", "", err
+		}
+		return ct, "JSON.stringify(" + body + ")", nil
+	case 1, 2:
+		// k6 encodes objects as forms, and sets the correct multipart content type itself
+		if value == nil {
+			value = map[string]interface{}{}
+		}
+		if ct == "multipart/form-data" {
+			ct = ""
+		}
+		body, err := jsValue(value, indent)
+		return ct, body, err
+	case 3:
+		if value == nil {
+			value = ""
+		}
+		return ct, jsString(fmt.Sprint(value)), nil
+	default:
+		return ct, "", nil
+	}
+}
+
+// successfulStatuses returns the explicitly specified successful statuses
+// of an operation. When there are none, any 2xx status is expected.
+func successfulStatuses(responses map[string]yaml.Node) []int {
+	var statuses []int
+	for code := range responses {
+		status, err := strconv.Atoi(code)
+		if err == nil && status >= 200 && status < 400 {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+func escapeTemplateLiteral(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "`", "\\`")
+	return strings.ReplaceAll(s, "${", "\\${")
+}
+
+func (s *openAPISpec) parameterValue(p *openAPIParameter) interface{} {
+	if p.Example != nil {
+		return p.Example
+	}
+	if v := s.exampleValue(p.Schema, make(map[string]bool)); v != nil {
+		return v
+	}
+	return p.Name
+}
+
+// exampleValue returns an example value that matches the given schema.
+// The expanding set contains the referenced schemas that are currently being
+// expanded, so recursive schemas are expanded only once.
+func (s *openAPISpec) exampleValue(schema *openAPISchema, expanding map[string]bool) interface{} {
+	if schema == nil {
+		return nil
+	}
+	if ref := schema.Ref; ref != "" {
+		if expanding[ref] {
+			return nil
+		}
+		expanding[ref] = true
+		defer delete(expanding, ref)
+		return s.exampleValue(s.resolveSchema(ref), expanding)
+	}
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.AllOf) > 0:
+		result := newOrderedMap()
+		for _, sub := range schema.AllOf {
+			if m, ok := s.exampleValue(sub, expanding).(*orderedMap); ok {
+				for _, k := range m.keys {
+					result.set(k, m.values[k])
+				}
+			}
+		}
+		return result
+	case len(schema.OneOf) > 0:
+		return s.exampleValue(schema.OneOf[0], expanding)
+	case len(schema.AnyOf) > 0:
+		return s.exampleValue(schema.AnyOf[0], expanding)
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		result := newOrderedMap()
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if v := s.exampleValue(schema.Properties[name], expanding); v != nil {
+				result.set(name, v)
+			}
+		}
+		return result
+	case "array":
+		if v := s.exampleValue(schema.Items, expanding); v != nil {
+			return []interface{}{v}
+		}
+		return []interface{}{}
+	case "integer", "number":
+		return 1
+	case "boolean":
+		return true
+	case "string":
+		return exampleString(schema.Format)
+	default:
+		return nil
+	}
+}
+
+func schemaType(schema *openAPISchema) string {
+	switch t := schema.Type.(type) {
+	case string:
+		return t
+	case []interface{}:
+		// OpenAPI 3.1 types can be lists, like ["string", "null"]
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	if len(schema.Properties) > 0 {
+		return "object"
+	}
+	if schema.Items != nil {
+		return "array"
+	}
+	return ""
+}
+
+func exampleString(format string) string {
+	switch format {
+	case "date":
+		return "2024-01-01"
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	case "ipv4":
+		return "127.0.0.1"
+	default:
+		return "string"
+	}
+}
+
+// componentName returns the name of a local component reference with the
+// given prefix, like #/components/schemas/Pet.
+func componentName(ref, prefix string) (string, bool) {
+	if !strings.HasPrefix(ref, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(ref, prefix), true
+}
+
+func (s *openAPISpec) resolveSchema(ref string) *openAPISchema {
+	name, ok := componentName(ref, "#/components/schemas/")
+	if !ok {
+		return nil
+	}
+	return s.Components.Schemas[name]
+}
+
+func (s *openAPISpec) resolveParameter(p *openAPIParameter) *openAPIParameter {
+	if p == nil || p.Ref == "" {
+		return p
+	}
+	name, ok := componentName(p.Ref, "#/components/parameters/")
+	if !ok {
+		return nil
+	}
+	return s.Components.Parameters[name]
+}
+
+func (s *openAPISpec) resolveRequestBody(rb *openAPIRequestBody) *openAPIRequestBody {
+	if rb == nil || rb.Ref == "" {
+		return rb
+	}
+	name, ok := componentName(rb.Ref, "#/components/requestBodies/")
+	if !ok {
+		return nil
+	}
+	return s.Components.RequestBodies[name]
+}
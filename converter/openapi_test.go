@@ -0,0 +1,168 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOpenAPISpec = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: 1.0.0
+servers:
+  - url: https://{env}.example.com/v1/
+    variables:
+      env:
+        default: api
+paths:
+  /pets:
+    get:
+      summary: List all pets
+      tags: [pets]
+      parameters:
+        - name: limit
+          in: query
+          required: true
+          schema: {type: integer, maximum: 100}
+        - name: offset
+          in: query
+          schema: {type: integer}
+      responses:
+        "200": {description: A list of pets}
+        default: {description: An error}
+    post:
+      operationId: createPet
+      tags: [pets]
+      requestBody:
+        $ref: '#/components/requestBodies/Pet'
+      responses:
+        "201": {description: Created}
+  /pets/{petId}:
+    summary: A single pet
+    parameters:
+      - $ref: '#/components/parameters/PetID'
+    get:
+      tags: [pets]
+      parameters:
+        - name: X-Request-ID
+          in: header
+          required: true
+          schema: {type: string, format: uuid}
+      responses:
+        "200": {description: A pet}
+        "304": {description: Not modified}
+  /health:
+    get:
+      responses:
+        default: {description: The health}
+components:
+  parameters:
+    PetID:
+      name: petId
+      in: path
+      required: true
+      example: 42
+  requestBodies:
+    Pet:
+      content:
+        application/xml:
+          schema: {$ref: '#/components/schemas/Pet'}
+        application/json:
+          schema: {$ref: '#/components/schemas/Pet'}
+  schemas:
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/NewPet'
+        - type: object
+          properties:
+            tags:
+              type: array
+              items: {type: string, enum: [cute, fluffy]}
+    NewPet:
+      type: object
+      properties:
+        name: {type: string, example: Rex}
+        born: {type: string, format: date}
+        parent: {$ref: '#/components/schemas/NewPet'}
+`
+
+func TestConvertOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	script, err := ConvertOpenAPI([]byte(testOpenAPISpec))
+	require.NoError(t, err)
+
+	expected := "import { check, group, sleep } from 'k6';\n" +
+		"import http from 'k6/http';\n" +
+		"\n" +
+		"// Generated from the OpenAPI specification of Petstore 1.0.0.\n" +
+		"\n" +
+		"const BASE_URL = __ENV.BASE_URL || \"https://api.example.com/v1\";\n" +
+		"\n" +
+		"export const options = {\n" +
+		"  vus: 1,\n" +
+		"  iterations: 1,\n" +
+		"};\n" +
+		"\n" +
+		"export default function () {\n" +
+		"  let res;\n" +
+		"\n" +
+		"  group(\"pets\", function () {\n" +
+		"    // GET /pets - List all pets\n" +
+		"    res = http.get(`${BASE_URL}/pets?limit=1`);\n" +
+		"    check(res, { 'status is 200': (r) => r.status === 200 });\n" +
+		"\n" +
+		"    // POST /pets - createPet\n" +
+		"    res = http.post(`${BASE_URL}/pets`, JSON.stringify({\n" +
+		"      \"born\": \"2024-01-01\",\n" +
+		"      \"name\": \"Rex\",\n" +
+		"      \"tags\": [\n" +
+		"        \"cute\"\n" +
+		"      ]\n" +
+		"    }), {\n" +
+		"      \"headers\": {\n" +
+		"        \"Content-Type\": \"application/json\"\n" +
+		"      }\n" +
+		"    });\n" +
+		"    check(res, { 'status is 201': (r) => r.status === 201 });\n" +
+		"\n" +
+		"    // GET /pets/{petId}\n" +
+		"    res = http.get(`${BASE_URL}/pets/42`, {\n" +
+		"      \"headers\": {\n" +
+		"        \"X-Request-ID\": \"00000000-0000-0000-0000-000000000000\"\n" +
+		"      }\n" +
+		"    });\n" +
+		"    check(res, { 'status is 200 or 304': (r) => [200, 304].includes(r.status) });\n" +
+		"  });\n" +
+		"\n" +
+		"  // GET /health\n" +
+		"  res = http.get(`${BASE_URL}/health`);\n" +
+		"  check(res, { 'status is 2xx': (r) => r.status >= 200 && r.status < 300 });\n" +
+		"\n" +
+		"  sleep(1);\n" +
+		"}\n"
+	assert.Equal(t, expected, script)
+}
+
+func TestConvertOpenAPIErrors(t *testing.T) {
+	t.Parallel()
+
+	testdata := map[string]string{
+		`swagger: "2.0"`:       "swagger 2.0 specifications aren't supported, only OpenAPI 3.x ones are",
+		`{"foo": "bar"}`:       "the file isn't an OpenAPI 3.x specification",
+		`{"openapi": "3.1.0"}`: "the OpenAPI specification doesn't have any operations",
+		`{"openapi": "3.1.0", "paths": {"/": []}}`: "invalid path item '/' in the OpenAPI specification: " +
+			"yaml: unmarshal errors:\n  line 1: cannot unmarshal !!seq into map[string]yaml.Node",
+		// NaN is valid in YAML, but not in JSON
+		`{"openapi": "3.1.0", "paths": {"/": {"post": {"requestBody": {"content": {"application/json": ` +
+			`{"example": {"value": .nan}}}}}}}}`: "couldn't convert the POST / operation: " +
+			"invalid request body: json: unsupported value: NaN",
+	}
+	for data, expErr := range testdata {
+		_, err := ConvertOpenAPI([]byte(data))
+		assert.EqualError(t, err, expErr, data)
+	}
+}
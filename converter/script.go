@@ -0,0 +1,170 @@
+// Package converter contains generators of k6 test scripts from other
+// formats, like HAR recordings and OpenAPI specifications.
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// scriptWriter is a helper for writing indented JavaScript code.
+type scriptWriter struct {
+	buf    strings.Builder
+	indent int
+}
+
+// line writes a single line of code at the current indentation level.
+func (w *scriptWriter) line(format string, args ...interface{}) {
+	if format == "" {
+		w.buf.WriteString("\n")
+		return
+	}
+	w.buf.WriteString(strings.Repeat("  ", w.indent))
+	fmt.Fprintf(&w.buf, format, args...)
+	w.buf.WriteString("\n")
+}
+
+// block writes the given opening line, calls fn with an increased
+// indentation and then writes the closing line.
+func (w *scriptWriter) block(opening, closing string, fn func()) {
+	w.line("%s", opening)
+	w.indent++
+	fn()
+	w.indent--
+	w.line("%s", closing)
+}
+
+func (w *scriptWriter) String() string {
+	return w.buf.String()
+}
+
+// jsValue returns the given value as a JavaScript literal, indented for the
+// given indentation level. JSON is valid JavaScript, so it's used for that.
+// It fails for the values JSON can't represent, e.g. NaN and infinite numbers,
+// which are valid in YAML examples.
+func jsValue(v interface{}, indent int) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(strings.Repeat("  ", indent), "  ")
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// jsString returns the given string as a JavaScript string literal.
+func jsString(s string) string {
+	// strings are always valid JSON values, so there is no error
+	value, _ := jsValue(s, 0)
+	return value
+}
+
+// orderedMap is a JSON object that keeps the order of its keys.
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: make(map[string]interface{})}
+}
+
+func (m *orderedMap) set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *orderedMap) len() int {
+	return len(m.keys)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// writeStatusCheck writes a check() call that validates the status of the
+// last response against the given expected statuses.
+func (w *scriptWriter) writeStatusCheck(statuses []int) {
+	if len(statuses) == 0 {
+		w.line("check(res, { 'status is 2xx': (r) => r.status >= 200 && r.status < 300 });")
+		return
+	}
+	sort.Ints(statuses)
+	if len(statuses) == 1 {
+		w.line("check(res, { 'status is %d': (r) => r.status === %d });", statuses[0], statuses[0])
+		return
+	}
+	names := make([]string, len(statuses))
+	for i, s := range statuses {
+		names[i] = fmt.Sprint(s)
+	}
+	w.line("check(res, { 'status is %s': (r) => %s.includes(r.status) });",
+		strings.Join(names, " or "), "["+strings.Join(names, ", ")+"]")
+}
+
+// writeRequest writes an HTTP request call, assigning the response to res.
+// body and params are JavaScript expressions and can be empty.
+func (w *scriptWriter) writeRequest(method, url, body, params string) {
+	args := []string{url}
+	fn := ""
+	switch strings.ToUpper(method) {
+	case "GET":
+		fn = "get"
+	case "HEAD":
+		fn = "head"
+	case "POST":
+		fn = "post"
+	case "PUT":
+		fn = "put"
+	case "PATCH":
+		fn = "patch"
+	case "DELETE":
+		fn = "del"
+	case "OPTIONS":
+		fn = "options"
+	default:
+		fn = "request"
+		args = append([]string{jsString(method)}, args...)
+	}
+
+	hasBody := fn != "get" && fn != "head"
+	switch {
+	case params != "":
+		if hasBody {
+			if body == "" {
+				body = "null"
+			}
+			args = append(args, body)
+		}
+		args = append(args, params)
+	case hasBody && body != "":
+		args = append(args, body)
+	}
+	w.line("res = http.%s(%s);", fn, strings.Join(args, ", "))
+}
@@ -18,6 +18,10 @@ const (
 	IterEnd
 	// Exit is emitted when the k6 process is about to exit.
 	Exit
+	// ScenarioStart is emitted when the executor of a scenario starts running.
+	ScenarioStart
+	// ScenarioEnd is emitted when the executor of a scenario finishes running.
+	ScenarioEnd
 )
 
 //nolint:gochecknoglobals
@@ -26,6 +30,8 @@ var (
 	GlobalEvents = []Type{Init, TestStart, TestEnd, Exit}
 	// VUEvents are emitted multiple times per each VU.
 	VUEvents = []Type{IterStart, IterEnd}
+	// ScenarioEvents are emitted once per each scenario that is executed.
+	ScenarioEvents = []Type{ScenarioStart, ScenarioEnd}
 )
 
 // ExitData is the data sent in the Exit event. Error is the error returned by
@@ -41,3 +47,11 @@ type IterData struct {
 	ScenarioName string
 	Error        error
 }
+
+// ScenarioData is the data sent in the ScenarioStart and ScenarioEnd events.
+// Error is the error returned by the executor of the scenario, if any.
+type ScenarioData struct {
+	Name     string
+	Executor string
+	Error    error
+}
@@ -10,4 +10,15 @@ export default function() {
     hasher.update("some other text")
     console.log(hasher.digest("hex"))
     console.log(hasher.digest("base64"))
+
+    // AES-GCM encryption, the nonce must never be reused with the same key.
+    let key = crypto.randomBytes(32);
+    let iv = crypto.randomBytes(12);
+    let ciphertext = crypto.encrypt("aes-gcm", key, "some secret text", { iv: iv });
+    let plaintext = crypto.decrypt("aes-gcm", key, ciphertext, { iv: iv });
+    console.log(crypto.hexEncode(ciphertext), String.fromCharCode(...new Uint8Array(plaintext)))
+
+    // Signatures, with HMAC, RSA, ECDSA or Ed25519 keys.
+    let signature = crypto.sign("HS256", "secret", "some signed text");
+    console.log(crypto.verify("HS256", "secret", "some signed text", signature))
 }
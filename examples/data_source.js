@@ -0,0 +1,27 @@
+import http from "k6/http";
+import { check } from "k6";
+import { DataSource } from "k6/data";
+
+// The records of the file are read on demand, instead of being loaded in memory,
+// so the file can be much larger than what a SharedArray could hold.
+//
+// In the default "sequential" mode, each record is handed out once to whichever
+// VU asks for the next one. The "per-vu" mode gives each VU its own slice of the
+// records, and the "random" mode a random record on each call.
+const users = new DataSource("users", "./users.csv", { mode: "sequential" });
+
+export const options = {
+  vus: 5,
+  duration: "10s",
+};
+
+export default function () {
+  const user = users.next();
+  if (user === null) {
+    // All the records have been used, set the loop option to start over instead.
+    return;
+  }
+
+  const res = http.post("https://httpbin.test.k6.io/post", { username: user.username });
+  check(res, { "status is 200": (r) => r.status === 200 });
+}
@@ -0,0 +1,35 @@
+import { appendFile, glob, mkdir, open, readdir, writeFile } from "k6/experimental/fs";
+import exec from "k6/execution";
+
+// Writing files requires an output directory, all the written paths being relative to it:
+//
+//   k6 run --fs-output-dir=out write.js
+export const options = {
+	vus: 10,
+	iterations: 100,
+};
+
+// Listing the fixtures, and opening them, is only possible in the init context.
+let fixtures = [];
+(async function () {
+	console.log(`the directory holds ${(await readdir(".")).length} entries`);
+
+	for (const path of await glob("*.txt")) {
+		fixtures.push(await open(path));
+	}
+})();
+
+export async function setup() {
+	await mkdir("tokens", { recursive: true });
+}
+
+export default async function () {
+	const vu = exec.vu.idInTest;
+	const iteration = exec.vu.iterationInScenario;
+
+	// Each VU writes to its own file.
+	await writeFile(`tokens/vu-${vu}.txt`, `last token of VU ${vu}: ${iteration}\n`);
+
+	// While all the VUs append to the same one, their lines are never interleaved.
+	await appendFile("ids.txt", `${vu}-${iteration} read ${fixtures.length} fixtures\n`);
+}
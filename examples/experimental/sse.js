@@ -0,0 +1,44 @@
+import { EventSource } from "k6/experimental/sse";
+import { check } from "k6";
+
+export const options = {
+	vus: 1,
+	iterations: 1,
+	thresholds: {
+		sse_time_to_first_event: ["p(95)<1000"],
+	},
+};
+
+export default function () {
+	// The server could be an LLM streaming API, or any other SSE endpoint.
+	const es = new EventSource("https://sse.dev/test?interval=1", {
+		headers: { Authorization: "Bearer token" },
+		tags: { endpoint: "test" },
+	});
+
+	let received = 0;
+	es.onopen = () => {
+		console.log(`connected to ${es.url}`);
+	};
+
+	es.onmessage = (e) => {
+		received++;
+		check(e, { "event has data": (e) => e.data.length > 0 });
+		console.log(`received event ${e.lastEventId}: ${e.data}`);
+
+		if (received === 5) {
+			es.close();
+		}
+	};
+
+	// custom event types can be listened for with addEventListener
+	es.addEventListener("update", (e) => {
+		console.log(`received an update: ${e.data}`);
+	});
+
+	// errors are emitted before every reconnection, and when the connection fails
+	es.onerror = (e) => {
+		console.error(`error: ${e.error}`);
+		es.close();
+	};
+}
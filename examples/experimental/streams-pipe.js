@@ -0,0 +1,35 @@
+import { ReadableStream, TransformStream, WritableStream } from 'k6/experimental/streams'
+
+function numbersStream() {
+	let currentNumber = 0
+
+	return new ReadableStream({
+		pull(controller) {
+			if (currentNumber < 5) {
+				controller.enqueue(++currentNumber)
+				return
+			}
+
+			controller.close()
+		},
+	})
+}
+
+export default async function () {
+	const doubler = new TransformStream({
+		transform(chunk, controller) {
+			controller.enqueue(chunk * 2)
+		},
+	})
+
+	const logger = new WritableStream({
+		write(chunk) {
+			console.log(`received number ${chunk} from stream`)
+		},
+		close() {
+			console.log('we are done')
+		},
+	})
+
+	await numbersStream().pipeThrough(doubler).pipeTo(logger)
+}
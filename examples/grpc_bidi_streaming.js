@@ -0,0 +1,53 @@
+import { Client, Stream } from 'k6/net/grpc';
+
+// to run this sample, you need to start the grpc server first.
+// to start the grpc server, run the following command in k6 repository's root:
+// go run -mod=mod examples/grpc_server/*.go
+// (golang should be installed)
+const GRPC_ADDR = __ENV.GRPC_ADDR || '127.0.0.1:10000';
+const GRPC_PROTO_PATH = __ENV.GRPC_PROTO_PATH || '../lib/testutils/grpcservice/route_guide.proto';
+
+export const options = {
+  thresholds: {
+    // the time between writing a note and receiving it back
+    grpc_streams_msgs_duration: ['p(95)<100'],
+    grpc_streams_time_to_first_msg: ['p(95)<200'],
+    grpc_streams_duration: ['p(95)<2000'],
+  },
+};
+
+let client = new Client();
+client.load([], GRPC_PROTO_PATH);
+
+// the example below sends route notes on a bidirectional stream. The server
+// responds with all the notes at the location of every received one, so the
+// responses are matched to the written notes by their messages, and at most 5
+// notes are waiting for their responses at any time.
+export default () => {
+  if (__ITER == 0) {
+    client.connect(GRPC_ADDR, { plaintext: true });
+  }
+
+  const stream = new Stream(client, 'main.RouteGuide/RouteChat', {
+    correlate: (note) => note.message,
+    maxInFlight: 5,
+  });
+
+  let received = 0;
+  stream.on('data', (note) => {
+    if (note.message.startsWith(`${__VU}-${__ITER}-`) && ++received === 20) {
+      stream.end();
+    }
+  });
+
+  stream.on('error', (err) => {
+    console.log('Stream Error: ' + JSON.stringify(err));
+  });
+
+  for (let i = 0; i < 20; i++) {
+    stream.write({
+      location: { latitude: 409146138 + i, longitude: -746188906 },
+      message: `${__VU}-${__ITER}-${i}`,
+    });
+  }
+};
@@ -0,0 +1,28 @@
+import grpc from 'k6/net/grpc';
+import { check } from "k6";
+
+// to run this sample, you need to start the grpc server first, on every one of
+// the backends of GRPC_ADDR, i.e. every IP its host resolves to:
+// go run -mod=mod examples/grpc_server/*.go
+// the backend tag of the requests is only emitted if it's enabled, e.g. with
+// k6 run --system-tags=status,method,name,url,service,backend examples/grpc_load_balancing.js
+const GRPC_ADDR = __ENV.GRPC_ADDR || 'localhost:10000';
+const GRPC_PROTO_PATH = __ENV.GRPC_PROTO_PATH || '../lib/testutils/grpcservice/route_guide.proto';
+
+let client = new grpc.Client();
+
+client.load([], GRPC_PROTO_PATH);
+
+export default () => {
+    if (__ITER == 0) {
+        // every backend is checked with the grpc.health.v1 protocol before the first request
+        client.connect(GRPC_ADDR, { plaintext: true, loadBalancing: "round_robin", healthCheck: true });
+    }
+
+    const response = client.invoke("main.FeatureExplorer/GetFeature", {
+        latitude: 410248224,
+        longitude: -747127767
+    })
+
+    check(response, { "status is OK": (r) => r && r.status === grpc.StatusOK });
+}
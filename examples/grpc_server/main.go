@@ -34,6 +34,8 @@ import (
 
 	"go.k6.io/k6/lib/testutils/grpcservice"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/testdata"
 
 	"google.golang.org/grpc/reflection"
@@ -79,5 +81,6 @@ func main() {
 	grpcservice.RegisterRouteGuideServer(grpcServer, grpcservice.NewRouteGuideServer(features...))
 	grpcservice.RegisterFeatureExplorerServer(grpcServer, grpcservice.NewFeatureExplorerServer(features...))
 	reflection.Register(grpcServer)
+	healthpb.RegisterHealthServer(grpcServer, health.NewServer())
 	grpcServer.Serve(lis)
 }
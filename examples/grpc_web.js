@@ -0,0 +1,29 @@
+import grpc from 'k6/net/grpc';
+import { check } from "k6";
+
+// to run this sample, you need a gRPC-Web proxy (e.g. Envoy with the grpc_web filter)
+// or a Connect server in front of the example grpc server, which can be started with:
+// go run -mod=mod examples/grpc_server/*.go
+// the protocol can be "grpc-web" or "connect", the address can have a path prefix,
+// e.g. https://example.com/api
+const GRPC_WEB_ADDR = __ENV.GRPC_WEB_ADDR || 'http://127.0.0.1:8080';
+const GRPC_PROTOCOL = __ENV.GRPC_PROTOCOL || 'grpc-web';
+const GRPC_PROTO_PATH = __ENV.GRPC_PROTO_PATH || '../lib/testutils/grpcservice/route_guide.proto';
+
+let client = new grpc.Client();
+
+client.load([], GRPC_PROTO_PATH);
+
+export default () => {
+    client.connect(GRPC_WEB_ADDR, { protocol: GRPC_PROTOCOL });
+
+    const response = client.invoke("main.FeatureExplorer/GetFeature", {
+        latitude: 410248224,
+        longitude: -747127767
+    })
+
+    check(response, { "status is OK": (r) => r && r.status === grpc.StatusOK });
+    console.log(JSON.stringify(response.message))
+
+    client.close()
+}
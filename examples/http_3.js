@@ -0,0 +1,19 @@
+import http from "k6/http";
+import { check } from "k6";
+
+export const options = {
+  // send the https requests over HTTP/3, the same as the --http3 flag
+  http3: true,
+};
+
+export default function () {
+  check(http.get("https://cloudflare-quic.com/"), {
+    "status is 200": (r) => r.status == 200,
+    "protocol is HTTP/3": (r) => r.proto == "HTTP/3",
+  });
+
+  // it can also be turned on or off for a single request
+  check(http.get("https://test-api.k6.io/", { http3: false }), {
+    "protocol is HTTP/2": (r) => r.proto == "HTTP/2.0",
+  });
+}
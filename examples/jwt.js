@@ -1,46 +1,33 @@
 import crypto from "k6/crypto";
-import encoding from "k6/encoding";
 import {sleep} from "k6";
 
-const algToHash = {
-    HS256: "sha256",
-    HS384: "sha384",
-    HS512: "sha512"
-};
-
-function sign(data, hashAlg, secret) {
-    let hasher = crypto.createHMAC(hashAlg, secret);
-    hasher.update(data);
-
-    // Some manual base64 rawurl encoding as `Hasher.digest(encodingType)`
-    // doesn't support that encoding type yet.
-    return hasher.digest("base64").replace(/\//g, "_").replace(/\+/g, "-").replace(/=/g, "");
-}
-
-function encode(payload, secret, algorithm) {
-    algorithm = algorithm || "HS256";
-    let header = encoding.b64encode(JSON.stringify({ typ: "JWT", alg: algorithm }), "rawurl");
-    payload = encoding.b64encode(JSON.stringify(payload), "rawurl", "s");
-    let sig = sign(header + "." + payload, algToHash[algorithm], secret);
-    return [header, payload, sig].join(".");
-}
+const secret = "secret";
 
-function decode(token, secret, algorithm) {
-    let parts = token.split('.');
-    let header = JSON.parse(encoding.b64decode(parts[0], "rawurl", "s"));
-    let payload = JSON.parse(encoding.b64decode(parts[1], "rawurl", "s"));
-    algorithm = algorithm || algToHash[header.alg];
-    if (sign(parts[0] + "." + parts[1], algorithm, secret) != parts[2]) {
-        throw Error("JWT signature verification failed");
-    }
-    return payload;
-}
+// An Ed25519 private key, in the JWK format. PEM encoded keys are supported as well.
+const privateKey = {
+    kty: "OKP",
+    crv: "Ed25519",
+    d: "nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A",
+    x: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo",
+};
+const publicKey = { kty: privateKey.kty, crv: privateKey.crv, x: privateKey.x };
 
 export default function() {
-    let message = { key2: "value2" };
-    let token = encode(message, "secret");
+    let now = Math.floor(Date.now() / 1000);
+    let message = { key2: "value2", iss: "k6", exp: now + 60 };
+
+    // HMAC signed token.
+    let token = crypto.signJWT(message, "HS256", secret);
     console.log("encoded", token);
-    let payload = decode(token, "secret");
+    let { payload } = crypto.verifyJWT(token, "HS256", secret, { issuer: "k6" });
     console.log("decoded", JSON.stringify(payload));
+
+    // Ed25519 signed token, with a key ID in its header.
+    token = crypto.signJWT(message, "EdDSA", privateKey, { header: { kid: "key-1" } });
+    let jwt = crypto.verifyJWT(token, "EdDSA", publicKey);
+    console.log("verified", JSON.stringify(jwt.header), JSON.stringify(jwt.payload));
+
+    // Tokens can be decoded without being verified.
+    console.log("header", JSON.stringify(crypto.decodeJWT(token).header));
     sleep(1)
 }
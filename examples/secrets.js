@@ -0,0 +1,28 @@
+import http from "k6/http";
+import secrets from "k6/secrets";
+
+// The secrets come from the sources set with the --secret-source flag, e.g.:
+//
+//   K6_SECRET_token=... k6 run --secret-source=env secrets.js
+//
+// or, from a file of key=value pairs encrypted with OpenSSL:
+//
+//   openssl enc -aes-256-cbc -pbkdf2 -salt -in secrets.txt -out secrets.enc -pass env:K6_SECRETS_PASSPHRASE
+//   K6_SECRETS_PASSPHRASE=... k6 run --secret-source=file=secrets.enc secrets.js
+//
+// With several sources, one of them is set as the default one, and the others
+// are accessed by their name:
+//
+//   k6 run --secret-source=env --secret-source=file=name=vault,secrets.enc,default secrets.js
+//
+// The values of the secrets are redacted from the logs and the end-of-test summary.
+export default async function () {
+  const token = await secrets.get("token");
+
+  // This logs "token: ***SECRET_REDACTED***".
+  console.log("token: " + token);
+
+  http.get("https://quickpizza.grafana.com/api/bearer", {
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
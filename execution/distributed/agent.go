@@ -0,0 +1,217 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AgentController implements the execution.Controller interface for a single
+// instance of a distributed test run, by relaying all operations to the
+// coordinator over the CommandAndControl gRPC stream.
+type AgentController struct {
+	instanceID uint32
+	cnc        DistributedTest_CommandAndControlClient
+	logger     logrus.FieldLogger
+
+	sendMx sync.Mutex
+
+	mx            sync.Mutex
+	subscriptions map[string][]chan error
+	doneEvents    map[string]error
+	dataRequests  map[string]chan *ControllerMessage
+
+	recvDone chan struct{}
+	recvErr  error
+}
+
+// NewAgentController opens the CommandAndControl stream to the coordinator for
+// the given instance and returns a controller that uses it.
+func NewAgentController(
+	ctx context.Context, instanceID uint32, client DistributedTestClient, logger logrus.FieldLogger,
+) (*AgentController, error) {
+	cnc, err := client.CommandAndControl(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &AgentController{
+		instanceID:    instanceID,
+		cnc:           cnc,
+		logger:        logger.WithField("instance", instanceID),
+		subscriptions: make(map[string][]chan error),
+		doneEvents:    make(map[string]error),
+		dataRequests:  make(map[string]chan *ControllerMessage),
+		recvDone:      make(chan struct{}),
+	}
+
+	if err = c.send(&AgentMessage{InitInstanceID: instanceID}); err != nil {
+		return nil, err
+	}
+	go c.receiveLoop()
+
+	return c, nil
+}
+
+func (c *AgentController) send(msg *AgentMessage) error {
+	c.sendMx.Lock()
+	defer c.sendMx.Unlock()
+	return c.cnc.Send(msg)
+}
+
+func (c *AgentController) receiveLoop() {
+	defer close(c.recvDone)
+	for {
+		msg, err := c.cnc.Recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				c.logger.WithError(err).Error("Lost connection to the coordinator")
+			}
+			c.mx.Lock()
+			c.recvErr = fmt.Errorf("the connection to the coordinator was closed: %w", err)
+			c.mx.Unlock()
+			return
+		}
+
+		switch m := msg.Message.(type) {
+		case *ControllerMessage_EventDone:
+			c.handleEventDone(m.EventDone)
+		case *ControllerMessage_DataWithID:
+			c.handleDataMessage(m.DataWithID.Id, msg)
+		case *ControllerMessage_CreateDataWithID:
+			c.handleDataMessage(m.CreateDataWithID, msg)
+		default:
+			c.logger.Warnf("Received an unknown message type %T from the coordinator", m)
+		}
+	}
+}
+
+func (c *AgentController) handleEventDone(signal *Signal) {
+	var err error
+	if signal.Error != "" {
+		err = fmt.Errorf("event '%s' failed: %s", signal.EventID, signal.Error)
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.doneEvents[signal.EventID] = err
+	for _, ch := range c.subscriptions[signal.EventID] {
+		ch <- err
+	}
+	delete(c.subscriptions, signal.EventID)
+}
+
+func (c *AgentController) handleDataMessage(dataID string, msg *ControllerMessage) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	ch, ok := c.dataRequests[dataID]
+	if !ok {
+		c.logger.Warnf("Received data '%s' from the coordinator without requesting it", dataID)
+		return
+	}
+	ch <- msg
+	delete(c.dataRequests, dataID)
+}
+
+// connErr has to be called after recvDone is closed.
+func (c *AgentController) connErr() error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.recvErr
+}
+
+// GetOrCreateData asks the coordinator for the data with the given ID. If no
+// other instance has requested it yet, the coordinator instructs this instance
+// to run the callback and the result is then shared with all other instances.
+func (c *AgentController) GetOrCreateData(dataID string, callback func() ([]byte, error)) ([]byte, error) {
+	ch := make(chan *ControllerMessage, 1)
+	c.mx.Lock()
+	if _, ok := c.dataRequests[dataID]; ok {
+		c.mx.Unlock()
+		return nil, fmt.Errorf("data '%s' is already being requested", dataID)
+	}
+	c.dataRequests[dataID] = ch
+	c.mx.Unlock()
+
+	msg := &AgentMessage{Message: &AgentMessage_GetOrCreateDataWithID{GetOrCreateDataWithID: dataID}}
+	if err := c.send(msg); err != nil {
+		return nil, err
+	}
+
+	var resp *ControllerMessage
+	select {
+	case resp = <-ch:
+	case <-c.recvDone:
+		return nil, c.connErr()
+	}
+
+	if dwid, ok := resp.Message.(*ControllerMessage_DataWithID); ok {
+		if dwid.DataWithID.Error != "" {
+			return nil, fmt.Errorf("data '%s' couldn't be created: %s", dataID, dwid.DataWithID.Error)
+		}
+		return dwid.DataWithID.Data, nil
+	}
+
+	c.logger.Debugf("Creating data '%s'...", dataID)
+	data, err := callback()
+	packet := &DataPacket{Id: dataID, Data: data}
+	if err != nil {
+		packet.Error = err.Error()
+	}
+	if sendErr := c.send(&AgentMessage{Message: &AgentMessage_CreatedData{CreatedData: packet}}); sendErr != nil {
+		c.logger.WithError(sendErr).Errorf("Could not send data '%s' to the coordinator", dataID)
+		if err == nil {
+			err = sendErr
+		}
+	}
+	return data, err
+}
+
+// Subscribe creates a listener for the specified event ID and returns a
+// callback that waits until the coordinator reports that all instances have
+// reached it, or that one of them has had an error.
+func (c *AgentController) Subscribe(eventID string) func() error {
+	ch := make(chan error, 1)
+	c.mx.Lock()
+	if err, done := c.doneEvents[eventID]; done {
+		ch <- err
+	} else {
+		c.subscriptions[eventID] = append(c.subscriptions[eventID], ch)
+	}
+	c.mx.Unlock()
+
+	return func() error {
+		c.logger.Debugf("Waiting for event '%s'...", eventID)
+		select {
+		case err := <-ch:
+			return err
+		case <-c.recvDone:
+			return c.connErr()
+		}
+	}
+}
+
+// Signal notifies the coordinator that the current instance has reached the
+// given event ID, or that it has had an error.
+func (c *AgentController) Signal(eventID string, sigErr error) error {
+	signal := &Signal{EventID: eventID}
+	if sigErr != nil {
+		signal.Error = sigErr.Error()
+	}
+	c.logger.Debugf("Signaling event '%s'...", eventID)
+	return c.send(&AgentMessage{Message: &AgentMessage_Signal{Signal: signal}})
+}
+
+// Close gracefully closes the stream to the coordinator and waits for it to
+// acknowledge that.
+func (c *AgentController) Close() error {
+	c.sendMx.Lock()
+	err := c.cnc.CloseSend()
+	c.sendMx.Unlock()
+	<-c.recvDone
+	return err
+}
@@ -0,0 +1,332 @@
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/lib"
+)
+
+// CoordinatorServer hosts the barriers and the shared data for a distributed
+// test run. It hands out the test archive and a different execution segment to
+// every agent that registers with it, and then relays the execution.Controller
+// operations of the agents between them.
+type CoordinatorServer struct {
+	UnimplementedDistributedTestServer
+
+	instanceCount uint32
+	archive       []byte
+	ess           lib.ExecutionSegmentSequence
+	logger        logrus.FieldLogger
+
+	mx               sync.Mutex
+	registered       uint32
+	agents           map[uint32]*agentStream
+	disconnected     map[uint32]bool
+	events           map[string]*eventState
+	data             map[string]*dataState
+	firstAgentErr    error
+	allDisconnected  chan struct{}
+	disconnectedOnce sync.Once
+}
+
+// agentStream wraps the server side of the CommandAndControl stream for a
+// single agent, since gRPC streams don't support concurrent Send() calls.
+type agentStream struct {
+	mx     sync.Mutex
+	stream DistributedTest_CommandAndControlServer
+}
+
+func (as *agentStream) send(msg *ControllerMessage) error {
+	as.mx.Lock()
+	defer as.mx.Unlock()
+	return as.stream.Send(msg)
+}
+
+type eventState struct {
+	signaled map[uint32]bool
+	done     bool
+	err      string
+}
+
+type dataState struct {
+	creator uint32
+	done    bool
+	packet  *DataPacket
+	waiting []uint32
+}
+
+// NewCoordinatorServer initializes and returns a new CoordinatorServer. The
+// execution segment of the given test is split evenly between instanceCount
+// agents.
+func NewCoordinatorServer(
+	instanceCount int, test *lib.Archive, logger logrus.FieldLogger,
+) (*CoordinatorServer, error) {
+	segments, err := test.Options.ExecutionSegment.Split(int64(instanceCount))
+	if err != nil {
+		return nil, err
+	}
+	ess, err := lib.NewExecutionSegmentSequence(segments...)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err = test.Write(buf); err != nil {
+		return nil, err
+	}
+
+	return &CoordinatorServer{
+		instanceCount:   uint32(instanceCount),
+		archive:         buf.Bytes(),
+		ess:             ess,
+		logger:          logger,
+		agents:          make(map[uint32]*agentStream),
+		disconnected:    make(map[uint32]bool),
+		events:          make(map[string]*eventState),
+		data:            make(map[string]*dataState),
+		allDisconnected: make(chan struct{}),
+	}, nil
+}
+
+// Register assigns a new instance ID and execution segment to the calling
+// agent and returns them together with the test archive.
+func (cs *CoordinatorServer) Register(context.Context, *RegisterRequest) (*RegisterResponse, error) {
+	cs.mx.Lock()
+	if cs.registered >= cs.instanceCount {
+		cs.mx.Unlock()
+		return nil, fmt.Errorf("all %d instances have already registered", cs.instanceCount)
+	}
+	cs.registered++
+	instanceID := cs.registered
+	cs.mx.Unlock()
+
+	cs.logger.Infof("Instance %d of %d connected!", instanceID, cs.instanceCount)
+
+	instanceOptions := lib.Options{
+		ExecutionSegment:         cs.ess[instanceID-1],
+		ExecutionSegmentSequence: &cs.ess,
+	}
+	options, err := json.Marshal(instanceOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RegisterResponse{
+		InstanceID: instanceID,
+		Archive:    cs.archive,
+		Options:    options,
+	}, nil
+}
+
+// CommandAndControl handles the bidirectional stream of a single agent for the
+// whole duration of its test run.
+func (cs *CoordinatorServer) CommandAndControl(stream DistributedTest_CommandAndControlServer) (err error) {
+	msg, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	instanceID := msg.InitInstanceID
+
+	cs.mx.Lock()
+	if instanceID == 0 || instanceID > cs.registered {
+		cs.mx.Unlock()
+		return fmt.Errorf("unregistered instance ID %d", instanceID)
+	}
+	if _, ok := cs.agents[instanceID]; ok || cs.disconnected[instanceID] {
+		cs.mx.Unlock()
+		return fmt.Errorf("instance %d has already connected", instanceID)
+	}
+	cs.agents[instanceID] = &agentStream{stream: stream}
+	cs.mx.Unlock()
+
+	logger := cs.logger.WithField("instance", instanceID)
+	logger.Debug("Command and control stream opened")
+	defer func() {
+		cs.handleDisconnect(instanceID, err)
+		logger.WithError(err).Debug("Command and control stream closed")
+	}()
+
+	for {
+		switch m := msg.Message.(type) {
+		case *AgentMessage_Signal:
+			cs.handleSignal(instanceID, m.Signal)
+		case *AgentMessage_GetOrCreateDataWithID:
+			cs.handleGetOrCreateData(instanceID, m.GetOrCreateDataWithID)
+		case *AgentMessage_CreatedData:
+			cs.handleCreatedData(instanceID, m.CreatedData)
+		case nil:
+			// only the initial message has no payload
+		default:
+			return fmt.Errorf("unknown message type %T from instance %d", m, instanceID)
+		}
+
+		msg, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Done returns a channel that is closed once all of the instances have
+// registered, connected and then disconnected from the coordinator.
+func (cs *CoordinatorServer) Done() <-chan struct{} {
+	return cs.allDisconnected
+}
+
+// Err returns the first error that any of the agents reported, if any.
+func (cs *CoordinatorServer) Err() error {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+	return cs.firstAgentErr
+}
+
+// send delivers the message to the given instance, if it's still connected.
+// It has to be called while holding the cs.mx lock.
+func (cs *CoordinatorServer) send(instanceID uint32, msg *ControllerMessage) {
+	agent, ok := cs.agents[instanceID]
+	if !ok {
+		return
+	}
+	if err := agent.send(msg); err != nil {
+		cs.logger.WithError(err).Warnf("Could not send a message to instance %d", instanceID)
+	}
+}
+
+// finishEvent marks the event as done and notifies all connected instances.
+// It has to be called while holding the cs.mx lock.
+func (cs *CoordinatorServer) finishEvent(eventID string, event *eventState, errMsg string) {
+	event.done = true
+	event.err = errMsg
+	for id := range cs.agents {
+		cs.send(id, &ControllerMessage{Message: &ControllerMessage_EventDone{
+			EventDone: &Signal{EventID: eventID, Error: errMsg},
+		}})
+	}
+}
+
+func (cs *CoordinatorServer) handleSignal(instanceID uint32, signal *Signal) {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	event, ok := cs.events[signal.EventID]
+	if !ok {
+		event = &eventState{signaled: make(map[uint32]bool)}
+		cs.events[signal.EventID] = event
+	}
+	if event.done {
+		// The event was already finished, most likely because of an error
+		// from another instance, so we just send the result back.
+		cs.send(instanceID, &ControllerMessage{Message: &ControllerMessage_EventDone{
+			EventDone: &Signal{EventID: signal.EventID, Error: event.err},
+		}})
+		return
+	}
+	event.signaled[instanceID] = true
+
+	switch {
+	case signal.Error != "":
+		cs.logger.WithField("instance", instanceID).Errorf(
+			"Instance reported an error at event '%s': %s", signal.EventID, signal.Error,
+		)
+		if cs.firstAgentErr == nil {
+			cs.firstAgentErr = fmt.Errorf("instance %d: %s", instanceID, signal.Error)
+		}
+		cs.finishEvent(signal.EventID, event, signal.Error)
+	case len(cs.disconnected) > 0:
+		cs.finishEvent(signal.EventID, event, cs.disconnectedErrMsg())
+	case uint32(len(event.signaled)) == cs.instanceCount:
+		cs.logger.Debugf("All instances reached event '%s'", signal.EventID)
+		cs.finishEvent(signal.EventID, event, "")
+	}
+}
+
+func (cs *CoordinatorServer) handleGetOrCreateData(instanceID uint32, dataID string) {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	data, ok := cs.data[dataID]
+	switch {
+	case !ok:
+		cs.logger.WithField("instance", instanceID).Debugf("Instance will create data '%s'", dataID)
+		cs.data[dataID] = &dataState{creator: instanceID}
+		cs.send(instanceID, &ControllerMessage{Message: &ControllerMessage_CreateDataWithID{
+			CreateDataWithID: dataID,
+		}})
+	case data.done:
+		cs.send(instanceID, &ControllerMessage{Message: &ControllerMessage_DataWithID{DataWithID: data.packet}})
+	default:
+		data.waiting = append(data.waiting, instanceID)
+	}
+}
+
+func (cs *CoordinatorServer) handleCreatedData(instanceID uint32, packet *DataPacket) {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	data, ok := cs.data[packet.Id]
+	if !ok || data.done || data.creator != instanceID {
+		cs.logger.WithField("instance", instanceID).Warnf("Received unexpected data '%s'", packet.Id)
+		return
+	}
+	cs.resolveData(data, packet)
+}
+
+// resolveData saves the final data packet and sends it to all of the instances
+// that were waiting for it. It has to be called while holding the cs.mx lock.
+func (cs *CoordinatorServer) resolveData(data *dataState, packet *DataPacket) {
+	data.done = true
+	data.packet = packet
+	for _, id := range data.waiting {
+		cs.send(id, &ControllerMessage{Message: &ControllerMessage_DataWithID{DataWithID: packet}})
+	}
+	data.waiting = nil
+}
+
+// disconnectedErrMsg has to be called while holding the cs.mx lock.
+func (cs *CoordinatorServer) disconnectedErrMsg() string {
+	for id := range cs.disconnected {
+		return fmt.Sprintf("instance %d has disconnected from the coordinator", id)
+	}
+	return ""
+}
+
+// handleDisconnect makes sure that the remaining instances aren't left waiting
+// for an instance that will never signal them, and closes the Done() channel
+// once all of them have disconnected.
+func (cs *CoordinatorServer) handleDisconnect(instanceID uint32, streamErr error) {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	delete(cs.agents, instanceID)
+	cs.disconnected[instanceID] = true
+	if streamErr != nil && cs.firstAgentErr == nil {
+		cs.firstAgentErr = fmt.Errorf("instance %d: %w", instanceID, streamErr)
+	}
+
+	errMsg := cs.disconnectedErrMsg()
+	for eventID, event := range cs.events {
+		if !event.done && !event.signaled[instanceID] {
+			cs.finishEvent(eventID, event, errMsg)
+		}
+	}
+	for dataID, data := range cs.data {
+		if !data.done && data.creator == instanceID {
+			cs.resolveData(data, &DataPacket{Id: dataID, Error: errMsg})
+		}
+	}
+
+	if uint32(len(cs.disconnected)) == cs.instanceCount {
+		cs.disconnectedOnce.Do(func() { close(cs.allDisconnected) })
+	}
+}
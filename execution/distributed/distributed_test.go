@@ -0,0 +1,187 @@
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/testutils"
+)
+
+func newTestCoordinator(t *testing.T, instanceCount int) (*CoordinatorServer, DistributedTestClient) {
+	t.Helper()
+
+	script := []byte(`export default function() {}`)
+	fs := fsext.NewMemMapFs()
+	require.NoError(t, fsext.WriteFile(fs, "/script.js", script, 0o644))
+	arc := &lib.Archive{
+		Type:        "js",
+		FilenameURL: &url.URL{Scheme: "file", Path: "/script.js"},
+		PwdURL:      &url.URL{Scheme: "file", Path: "/"},
+		Data:        script,
+		Filesystems: map[string]fsext.Fs{"file": fs},
+	}
+	coordinator, err := NewCoordinatorServer(instanceCount, arc, testutils.NewLogger(t))
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	RegisterDistributedTestServer(srv, coordinator)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return coordinator, NewDistributedTestClient(conn)
+}
+
+func newTestAgents(t *testing.T, client DistributedTestClient, count int) []*AgentController {
+	t.Helper()
+
+	agents := make([]*AgentController, count)
+	for i := range agents {
+		resp, err := client.Register(context.Background(), &RegisterRequest{})
+		require.NoError(t, err)
+		require.Equal(t, uint32(i+1), resp.InstanceID)
+		require.NotEmpty(t, resp.Archive)
+
+		agents[i], err = NewAgentController(context.Background(), resp.InstanceID, client, testutils.NewLogger(t))
+		require.NoError(t, err)
+	}
+	return agents
+}
+
+func TestCoordinatorRegister(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestCoordinator(t, 2)
+
+	var segments []string
+	for i := 0; i < 2; i++ {
+		resp, err := client.Register(context.Background(), &RegisterRequest{})
+		require.NoError(t, err)
+		var opts lib.Options
+		require.NoError(t, json.Unmarshal(resp.Options, &opts))
+		require.NotNil(t, opts.ExecutionSegmentSequence)
+		assert.Equal(t, "0,1/2,1", opts.ExecutionSegmentSequence.String())
+		segments = append(segments, opts.ExecutionSegment.String())
+	}
+	assert.Equal(t, []string{"0:1/2", "1/2:1"}, segments)
+
+	_, err := client.Register(context.Background(), &RegisterRequest{})
+	require.ErrorContains(t, err, "all 2 instances have already registered")
+}
+
+func TestDistributedBarriersAndData(t *testing.T) {
+	t.Parallel()
+
+	const instances = 3
+	coordinator, client := newTestCoordinator(t, instances)
+	agents := newTestAgents(t, client, instances)
+
+	var callbackCalls, reachedBarrier int64
+	results := make([][]byte, instances)
+	wg := sync.WaitGroup{}
+	for i, agent := range agents {
+		i, agent := i, agent
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := agent.GetOrCreateData("setup", func() ([]byte, error) {
+				atomic.AddInt64(&callbackCalls, 1)
+				time.Sleep(50 * time.Millisecond) // make sure the others have to wait
+				return []byte("setup data"), nil
+			})
+			assert.NoError(t, err)
+			results[i] = data
+
+			atomic.AddInt64(&reachedBarrier, 1)
+			assert.NoError(t, execution.SignalAndWait(agent, "barrier"))
+			assert.Equal(t, int64(instances), atomic.LoadInt64(&reachedBarrier))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&callbackCalls))
+	for _, res := range results {
+		assert.Equal(t, []byte("setup data"), res)
+	}
+
+	for _, agent := range agents {
+		require.NoError(t, agent.Close())
+	}
+	select {
+	case <-coordinator.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the coordinator didn't finish")
+	}
+	assert.NoError(t, coordinator.Err())
+}
+
+func TestDistributedErrorPropagation(t *testing.T) {
+	t.Parallel()
+
+	coordinator, client := newTestCoordinator(t, 2)
+	agents := newTestAgents(t, client, 2)
+
+	_, err := agents[0].GetOrCreateData("setup", func() ([]byte, error) {
+		return nil, errors.New("setup failed")
+	})
+	require.ErrorContains(t, err, "setup failed")
+	_, err = agents[1].GetOrCreateData("setup", func() ([]byte, error) {
+		t.Error("the callback shouldn't be called twice")
+		return nil, nil
+	})
+	require.ErrorContains(t, err, "setup failed")
+
+	waitErr := make(chan error)
+	go func() {
+		waitErr <- execution.SignalAndWait(agents[1], "setup-done")
+	}()
+	require.Error(t, execution.SignalErrorOrWait(agents[0], "setup-done", errors.New("oops")))
+
+	select {
+	case err = <-waitErr:
+		require.ErrorContains(t, err, "oops")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the second agent wasn't notified about the error")
+	}
+	require.ErrorContains(t, coordinator.Err(), "instance 1: oops")
+}
+
+func TestDistributedDisconnect(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestCoordinator(t, 2)
+	agents := newTestAgents(t, client, 2)
+
+	waitErr := make(chan error)
+	go func() {
+		waitErr <- execution.SignalAndWait(agents[1], "barrier")
+	}()
+	require.NoError(t, agents[0].Close())
+
+	select {
+	case err := <-waitErr:
+		require.ErrorContains(t, err, "instance 1 has disconnected")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the second agent wasn't notified about the disconnect")
+	}
+}
@@ -0,0 +1,6 @@
+// Package distributed implements the execution.Controller interface for
+// distributed (multi-instance) k6 execution, where a coordinator hosts the
+// barriers and the shared data, and agents connect to it over gRPC.
+package distributed
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ./distributed.proto
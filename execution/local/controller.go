@@ -2,44 +2,95 @@
 // (single-machine) k6 execution.
 package local
 
-// Controller "controls" local tests. It doesn't actually do anything, it just
-// implements the execution.Controller interface with no-op operations. The
-// methods don't do anything because local tests have only a single instance.
+import "sync"
+
+// Controller "controls" local tests. Since local tests have only a single
+// instance, every event is reached as soon as it's signaled once and all
+// data is created by the first GetOrCreateData() call for its ID.
 //
-// However, for test suites (https://github.com/grafana/k6/issues/1342) in the
-// future, we will probably need to actually implement some of these methods and
-// introduce simple synchronization primitives even for a single machine...
-type Controller struct{}
+// That makes it a no-op for a single test run, but it also allows the
+// Controller to be shared by multiple tests that are executed by the same k6
+// process, e.g. in test suites, where it's used for flows like "test C is
+// executed only after test A and test B finish".
+type Controller struct {
+	mx     sync.Mutex
+	events map[string]*eventState
+	data   map[string]*dataState
+}
+
+type eventState struct {
+	done chan struct{}
+	err  error
+}
+
+type dataState struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
 
 // NewController creates a new local execution Controller.
 func NewController() *Controller {
-	return &Controller{}
+	return &Controller{
+		events: make(map[string]*eventState),
+		data:   make(map[string]*dataState),
+	}
+}
+
+// GetOrCreateData calls the given callback only the first time it's called
+// for the given ID and returns its results. Any concurrent or subsequent calls
+// with the same ID wait for the first one to finish and get the same results.
+func (c *Controller) GetOrCreateData(id string, callback func() ([]byte, error)) ([]byte, error) {
+	c.mx.Lock()
+	ds, ok := c.data[id]
+	if ok {
+		c.mx.Unlock()
+		<-ds.done
+		return ds.data, ds.err
+	}
+	ds = &dataState{done: make(chan struct{})}
+	c.data[id] = ds
+	c.mx.Unlock()
+
+	ds.data, ds.err = callback()
+	close(ds.done)
+	return ds.data, ds.err
 }
 
-// GetOrCreateData immediately calls the given callback and returns its results.
-func (c *Controller) GetOrCreateData(_ string, callback func() ([]byte, error)) ([]byte, error) {
-	return callback()
+func (c *Controller) getEvent(eventID string) *eventState {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	es, ok := c.events[eventID]
+	if !ok {
+		es = &eventState{done: make(chan struct{})}
+		c.events[eventID] = es
+	}
+	return es
 }
 
-// Subscribe is a no-op, it doesn't actually wait for anything, because there is
-// nothing to wait on - we only have one instance in local tests.
-//
-// TODO: actually use waitgroups, since this may actually matter for test
-// suites, even for local test runs. That's because multiple tests might be
-// executed even by a single instance, and if we have complicated flows (e.g.
-// "test C is executed only after test A and test B finish"), the easiest way
-// would be for different tests in the suite to reuse this Controller API *both*
-// local and distributed runs.
-func (c *Controller) Subscribe(_ string) func() error {
+// Subscribe returns a callback that waits until the given event ID is
+// signaled and returns the error it was signaled with, if any.
+func (c *Controller) Subscribe(eventID string) func() error {
+	es := c.getEvent(eventID)
 	return func() error {
-		return nil
+		<-es.done
+		return es.err
 	}
 }
 
-// Signal is a no-op, it doesn't actually do anything for local test runs.
-//
-// TODO: similar to Wait() above, this might actually be required for
-// complex/branching test suites, even during local non-distributed execution.
-func (c *Controller) Signal(_ string, _ error) error {
+// Signal marks the given event ID as reached, with the given error. There is
+// only a single instance, so only the first signal for every event matters.
+func (c *Controller) Signal(eventID string, err error) error {
+	es := c.getEvent(eventID)
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	select {
+	case <-es.done:
+		// already signaled
+	default:
+		es.err = err
+		close(es.done)
+	}
 	return nil
 }
@@ -0,0 +1,77 @@
+package local
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/execution"
+)
+
+func TestControllerSignalAndWait(t *testing.T) {
+	t.Parallel()
+	c := NewController()
+
+	// A single instance reaches the barrier immediately.
+	require.NoError(t, execution.SignalAndWait(c, "event"))
+
+	// Waiting for an event signaled with an error returns it.
+	errTest := errors.New("test error")
+	wait := c.Subscribe("failed")
+	require.NoError(t, c.Signal("failed", errTest))
+	require.NoError(t, c.Signal("failed", nil)) // only the first signal matters
+	assert.Equal(t, errTest, wait())
+	assert.Equal(t, errTest, c.Subscribe("failed")())
+}
+
+func TestControllerWaitsForSignal(t *testing.T) {
+	t.Parallel()
+	c := NewController()
+
+	done := make(chan error)
+	go func() {
+		done <- c.Subscribe("event")()
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("the wait returned before the event was signaled")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, c.Signal("event", nil))
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("the wait didn't return after the event was signaled")
+	}
+}
+
+func TestControllerGetOrCreateData(t *testing.T) {
+	t.Parallel()
+	c := NewController()
+
+	var calls int64
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := c.GetOrCreateData("setup", func() ([]byte, error) {
+				atomic.AddInt64(&calls, 1)
+				time.Sleep(10 * time.Millisecond)
+				return []byte("data"), nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("data"), data)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+}
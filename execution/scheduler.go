@@ -2,8 +2,10 @@ package execution
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,6 +13,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"go.k6.io/k6/errext"
+	"go.k6.io/k6/event"
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/metrics"
 	"go.k6.io/k6/ui/pb"
@@ -29,6 +32,11 @@ type Scheduler struct {
 	maxDuration     time.Duration // cached value derived from the execution plan
 	maxPossibleVUs  uint64        // cached value derived from the execution plan
 	state           *lib.ExecutionState
+
+	// closed when the executor of each scenario has finished, or right away
+	// for the ones without work, so the scenarios with startAfter can wait
+	finishedScenarios map[string]chan struct{}
+	startConditions   map[string][]*lib.MetricCondition
 }
 
 // NewScheduler creates and returns a new Scheduler instance, without
@@ -50,9 +58,12 @@ func NewScheduler(trs *lib.TestRunState, controller Controller) (*Scheduler, err
 
 	executorConfigs := options.Scenarios.GetSortedConfigs()
 	executors := make([]lib.Executor, 0, len(executorConfigs))
+	finishedScenarios := make(map[string]chan struct{}, len(executorConfigs))
 	// Only take executors which have work.
 	for _, sc := range executorConfigs {
+		finishedScenarios[sc.GetName()] = make(chan struct{})
 		if !sc.HasWork(et) {
+			close(finishedScenarios[sc.GetName()])
 			trs.Logger.Warnf(
 				"Executor '%s' is disabled for segment %s due to lack of work!",
 				sc.GetName(), options.ExecutionSegment,
@@ -84,6 +95,9 @@ func NewScheduler(trs *lib.TestRunState, controller Controller) (*Scheduler, err
 		maxPossibleVUs:  maxPossibleVUs,
 		state:           executionState,
 		controller:      controller,
+
+		finishedScenarios: finishedScenarios,
+		startConditions:   make(map[string][]*lib.MetricCondition),
 	}, nil
 }
 
@@ -316,6 +330,9 @@ func (e *Scheduler) initVUsAndExecutors(ctx context.Context, samplesOut chan<- m
 		if err := exec.Init(ctx); err != nil {
 			return fmt.Errorf("error while initializing executor %s: %w", executorConfig.GetName(), err)
 		}
+		if err := e.initStartConditions(executorConfig); err != nil {
+			return fmt.Errorf("error while initializing executor %s: %w", executorConfig.GetName(), err)
+		}
 		logger.Debugf("Initialized executor %s", executorConfig.GetName())
 	}
 
@@ -325,9 +342,12 @@ func (e *Scheduler) initVUsAndExecutors(ctx context.Context, samplesOut chan<- m
 }
 
 // runExecutor gets called by the public Run() method once per configured
-// executor, each time in a new goroutine. It is responsible for waiting out the
-// configured startTime for the specific executor and then running its Run()
-// method.
+// executor, each time in a new goroutine. It is responsible for waiting for the
+// scenarios it should start after to finish, waiting out the configured
+// startTime for the specific executor, checking its start conditions and then
+// running its Run() method.
+//
+//nolint:funlen
 func (e *Scheduler) runExecutor(
 	runCtx context.Context, runResults chan<- error, engineOut chan<- metrics.SampleContainer, executor lib.Executor,
 ) {
@@ -340,6 +360,29 @@ func (e *Scheduler) runExecutor(
 	})
 	executorProgress := executor.GetProgress()
 
+	finished := e.finishedScenarios[executorConfig.GetName()]
+	defer close(finished)
+
+	if startAfter := executorConfig.GetStartAfter(); len(startAfter) > 0 {
+		executorProgress.Modify(
+			pb.WithStatus(pb.Waiting),
+			pb.WithConstProgress(0, "waiting for "+strings.Join(startAfter, ", ")),
+		)
+		executorLogger.Debugf("Waiting for the scenarios it should start after...")
+		for _, name := range startAfter {
+			select {
+			case <-runCtx.Done():
+				runResults <- nil // no error since executor hasn't started yet
+				return
+			case <-e.state.GracefulStopNotify():
+				e.skipStoppedExecutor(runResults, executor)
+				return
+			case <-e.finishedScenarios[name]:
+				// continue
+			}
+		}
+	}
+
 	// Check if we have to wait before starting the actual executor execution
 	if executorStartTime > 0 {
 		startTime := time.Now()
@@ -356,25 +399,73 @@ func (e *Scheduler) runExecutor(
 		case <-runCtx.Done():
 			runResults <- nil // no error since executor hasn't started yet
 			return
+		case <-e.state.GracefulStopNotify():
+			e.skipStoppedExecutor(runResults, executor)
+			return
 		case <-time.After(executorStartTime):
 			// continue
 		}
 	}
 
+	if failed := e.evaluateStartConditions(runCtx, executorConfig.GetName()); len(failed) > 0 {
+		executorLogger.Warnf(
+			"Skipping scenario %s, since its startWhen conditions on %s didn't pass",
+			executorConfig.GetName(), strings.Join(failed, ", "),
+		)
+		executorProgress.Modify(
+			pb.WithStatus(pb.Interrupted),
+			pb.WithConstProgress(0, "skipped"),
+		)
+		runResults <- nil
+		return
+	}
+
+	if e.state.IsStoppedGracefully() {
+		e.skipStoppedExecutor(runResults, executor)
+		return
+	}
+
 	executorProgress.Modify(
 		pb.WithStatus(pb.Running),
 		pb.WithConstProgress(0, "started"),
 	)
 	executorLogger.Debugf("Starting executor")
+	e.emitScenarioEvent(event.ScenarioStart, executorConfig, nil)
 	err := executor.Run(runCtx, engineOut) // executor should handle context cancel itself
 	if err == nil {
 		executorLogger.Debugf("Executor finished successfully")
 	} else {
 		executorLogger.WithField("error", err).Errorf("Executor error")
 	}
+	e.emitScenarioEvent(event.ScenarioEnd, executorConfig, err)
 	runResults <- err
 }
 
+// emitScenarioEvent emits the given scenario event, without waiting for the
+// subscribers to process it, so the scenarios aren't delayed by them.
+func (e *Scheduler) emitScenarioEvent(eventType event.Type, config lib.ExecutorConfig, err error) {
+	if e.state.Test.Events == nil {
+		return
+	}
+	e.state.Test.Events.Emit(&event.Event{
+		Type: eventType,
+		Data: &event.ScenarioData{Name: config.GetName(), Executor: config.GetType(), Error: err},
+	})
+}
+
+// skipStoppedExecutor is used for the executors that haven't started yet when
+// the test is gracefully stopped, they are simply skipped.
+func (e *Scheduler) skipStoppedExecutor(runResults chan<- error, executor lib.Executor) {
+	e.state.Test.Logger.WithField("executor", executor.GetConfig().GetName()).Debugf(
+		"Skipping executor, since the test was stopped before it started",
+	)
+	executor.GetProgress().Modify(
+		pb.WithStatus(pb.Interrupted),
+		pb.WithConstProgress(0, "skipped"),
+	)
+	runResults <- nil
+}
+
 // Init concurrently initializes all of the planned VUs and then sequentially
 // initializes all of the configured executors. It also starts the measurement
 // and emission of the `vus` and `vus_max` metrics.
@@ -544,6 +635,25 @@ func (e *Scheduler) Run(globalCtx, runCtx context.Context, samplesOut chan<- met
 	return firstErr
 }
 
+// StopGracefully stops the test run the same way it would end at the end of
+// the regular duration of all scenarios: no new iterations are started and
+// the ones in progress have up to the gracefulStop of their scenario to
+// finish, after which they are interrupted. The scenarios that haven't started
+// yet are skipped.
+func (e *Scheduler) StopGracefully() error {
+	if e.state.HasEnded() {
+		return errors.New("test execution has already ended")
+	}
+	if err := e.state.StopGracefully(); err != nil {
+		return err
+	}
+	e.state.Test.Logger.Debug("Stopping the execution gracefully")
+	if !e.state.HasStarted() && e.state.IsPaused() {
+		return e.state.Resume() // it was paused before it started, so nothing will be executed
+	}
+	return nil
+}
+
 // SetPaused pauses the test, or start/resumes it. To check if a test is paused,
 // use GetState().IsPaused().
 //
@@ -557,7 +667,7 @@ func (e *Scheduler) Run(globalCtx, runCtx context.Context, samplesOut chan<- met
 // is that some of the executors for the test don't support pausing after the
 // test has been started.
 //
-// IMPORTANT: Currently only the externally controlled executor can be paused
+// IMPORTANT: Currently only the externally controlled executors can be paused
 // and resumed multiple times in the middle of the test execution! Even then,
 // "pausing" is a bit misleading, since k6 won't pause in the middle of the
 // currently executing iterations. It will allow the currently in-progress
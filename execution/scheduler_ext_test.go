@@ -210,6 +210,13 @@ func TestSchedulerRunEnv(t *testing.T) {
 		"externally-controlled": `
 			vus: 1,
 			duration: "1s",`,
+		"externally-controlled-arrival-rate": `
+			rate: 1,
+			timeUnit: "1s",
+			duration: "1s",
+			preAllocatedVUs: 1,
+			maxVUs: 2,
+			gracefulStop: "0.5s",`,
 		"per-vu-iterations": `
 			vus: 1,
 			iterations: 1,
@@ -424,6 +431,13 @@ func TestSchedulerRunCustomTags(t *testing.T) {
 		"externally-controlled": `
 			vus: 1,
 			duration: "1s",`,
+		"externally-controlled-arrival-rate": `
+			rate: 1,
+			timeUnit: "1s",
+			duration: "1s",
+			preAllocatedVUs: 1,
+			maxVUs: 2,
+			gracefulStop: "0.5s",`,
 		"per-vu-iterations": `
 			vus: 1,
 			iterations: 1,
@@ -1373,3 +1387,77 @@ func TestNewSchedulerHasWork(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "doesn't support pause and resume operations after its start")
 }
+
+func TestSchedulerStartAfterFinishedEarly(t *testing.T) {
+	t.Parallel()
+	// warmup is planned to end after 10s+30s, but it finishes right away, so
+	// load runs at the same time as other and needs VUs that wouldn't be
+	// reserved if only the planned start times were considered
+	script := []byte(`
+		import { sleep } from 'k6';
+
+		export let options = {
+			scenarios: {
+				warmup: {
+					executor: "shared-iterations",
+					vus: 1,
+					iterations: 1,
+					maxDuration: "10s",
+				},
+				load: {
+					executor: "constant-vus",
+					vus: 2,
+					duration: "3s",
+					startAfter: ["warmup"],
+				},
+				other: {
+					executor: "constant-vus",
+					vus: 2,
+					duration: "1s",
+					startTime: "200ms",
+				},
+			},
+		};
+
+		export default function() {
+			sleep(0.1);
+		};
+`)
+
+	logger, hook := testutils.NewLoggerWithHook(t, logrus.WarnLevel)
+	registry := metrics.NewRegistry()
+	piState := &lib.TestPreInitState{
+		Logger:         logger,
+		Registry:       registry,
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+	}
+	runner, err := js.New(piState, &loader.SourceData{URL: &url.URL{Path: "/script.js"}, Data: script}, nil)
+	require.NoError(t, err)
+
+	testRunState := getTestRunState(t, piState, runner.GetOptions(), runner)
+	execScheduler, err := execution.NewScheduler(testRunState, local.NewController())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), lib.GetMaxPlannedVUs(execScheduler.GetExecutionPlan()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	samples := make(chan metrics.SampleContainer, 1000)
+	go func() {
+		for {
+			select {
+			case <-samples:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	stopEmission, err := execScheduler.Init(ctx, samples)
+	require.NoError(t, err)
+	defer stopEmission()
+
+	start := time.Now()
+	require.NoError(t, execScheduler.Run(ctx, ctx, samples))
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Empty(t, hook.Lines())
+}
@@ -0,0 +1,65 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.k6.io/k6/lib"
+)
+
+// initStartConditions parses the startWhen conditions of the given scenario
+// and starts observing their metrics, so they can be evaluated over all
+// samples of the test until the scenario is supposed to start.
+func (e *Scheduler) initStartConditions(config lib.ExecutorConfig) error {
+	startWhen := config.GetStartWhen()
+	if len(startWhen) == 0 {
+		return nil
+	}
+
+	liveMetrics := e.state.Test.LiveMetrics
+	if liveMetrics == nil {
+		return errors.New("startWhen can't be used if both the end-of-test summary " +
+			"and the thresholds are disabled, since the metrics aren't processed")
+	}
+
+	conditions, err := lib.InitMetricConditions(startWhen, e.state.Test.Registry, liveMetrics)
+	if err != nil {
+		return fmt.Errorf("invalid startWhen: %w", err)
+	}
+	e.startConditions[config.GetName()] = conditions
+	return nil
+}
+
+// evaluateStartConditions evaluates the startWhen conditions of the given
+// scenario and returns the names of the metrics for which they failed.
+func (e *Scheduler) evaluateStartConditions(ctx context.Context, scenarioName string) (failed []string) {
+	conditions := e.startConditions[scenarioName]
+	if len(conditions) == 0 {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return nil // the executor won't start anyway
+	}
+	// the samples of the scenarios that have just finished are buffered by the
+	// output manager and by the ingester, so they need to reach the metrics first
+	if flush := e.state.Test.FlushMetrics; flush != nil {
+		flush()
+	}
+
+	testRunDuration := e.state.GetCurrentTestRunDuration()
+	for _, cond := range conditions {
+		passed, err := cond.Evaluate(testRunDuration)
+		if err != nil {
+			e.state.Test.Logger.WithError(err).Errorf(
+				"Error while evaluating the startWhen conditions of scenario %s on metric '%s'",
+				scenarioName, cond.MetricName,
+			)
+			passed = false
+		}
+		if !passed {
+			failed = append(failed, cond.MetricName)
+		}
+	}
+	return failed
+}
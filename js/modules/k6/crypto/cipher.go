@@ -0,0 +1,186 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+)
+
+const (
+	cipherAESGCM = "aes-gcm"
+	cipherAESCBC = "aes-cbc"
+)
+
+// aesCipher holds what's needed by an AES encryption or decryption.
+type aesCipher struct {
+	block cipher.Block
+	// aead is set for AES-GCM, and nil for AES-CBC.
+	aead cipher.AEAD
+	opts cipherOptions
+}
+
+// cipherOptions holds the options of the encrypt and decrypt functions.
+type cipherOptions struct {
+	// iv is the initialization vector, or the nonce for AES-GCM.
+	iv []byte
+	// additionalData is the data authenticated, but not encrypted, by AES-GCM.
+	additionalData []byte
+}
+
+// encrypt encrypts the data with the given algorithm and key, and returns the
+// ciphertext as an ArrayBuffer.
+//
+// For AES-GCM, the authentication tag is appended to the ciphertext, as WebCrypto
+// does. For AES-CBC, the data is padded with PKCS #7.
+func (c *Crypto) encrypt(algorithm string, key, data, options goja.Value) (*goja.ArrayBuffer, error) {
+	ac, err := c.newAESCipher(algorithm, key, options)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := toBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var ciphertext []byte
+	if ac.aead != nil {
+		ciphertext = ac.aead.Seal(nil, ac.opts.iv, plaintext, ac.opts.additionalData)
+	} else {
+		ciphertext = pkcs7Pad(plaintext, aes.BlockSize)
+		cipher.NewCBCEncrypter(ac.block, ac.opts.iv).CryptBlocks(ciphertext, ciphertext)
+	}
+
+	ab := c.vu.Runtime().NewArrayBuffer(ciphertext)
+	return &ab, nil
+}
+
+// decrypt decrypts the data with the given algorithm and key, and returns the
+// plaintext as an ArrayBuffer. It fails if the data can't be authenticated,
+// or if its padding is invalid.
+func (c *Crypto) decrypt(algorithm string, key, data, options goja.Value) (*goja.ArrayBuffer, error) {
+	ac, err := c.newAESCipher(algorithm, key, options)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := toBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var plaintext []byte
+	if ac.aead != nil {
+		plaintext, err = ac.aead.Open(nil, ac.opts.iv, ciphertext, ac.opts.additionalData)
+		if err != nil {
+			return nil, errors.New("decryption failed: the data or the additional data can't be authenticated")
+		}
+	} else {
+		if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+			return nil, errors.New("decryption failed: the data isn't a multiple of the block size")
+		}
+		plaintext = make([]byte, len(ciphertext))
+		cipher.NewCBCDecrypter(ac.block, ac.opts.iv).CryptBlocks(plaintext, ciphertext)
+		if plaintext, err = pkcs7Unpad(plaintext, aes.BlockSize); err != nil {
+			return nil, fmt.Errorf("decryption failed: %w", err)
+		}
+	}
+
+	ab := c.vu.Runtime().NewArrayBuffer(plaintext)
+	return &ab, nil
+}
+
+// newAESCipher validates the algorithm, the key and the options of a cipher operation.
+func (c *Crypto) newAESCipher(algorithm string, key, options goja.Value) (*aesCipher, error) {
+	if algorithm != cipherAESGCM && algorithm != cipherAESCBC {
+		return nil, fmt.Errorf("unsupported cipher algorithm %q, it must be one of %q or %q",
+			algorithm, cipherAESGCM, cipherAESCBC)
+	}
+
+	secret, err := parseSecretKey(key)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s key: it must be 16, 24 or 32 bytes long, got %d", algorithm, len(secret))
+	}
+
+	opts, err := c.parseCipherOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if algorithm == cipherAESCBC {
+		if len(opts.additionalData) > 0 {
+			return nil, errors.New("the additionalData option is only supported by " + cipherAESGCM)
+		}
+		if len(opts.iv) != aes.BlockSize {
+			return nil, fmt.Errorf("the iv option must be %d bytes long, got %d", aes.BlockSize, len(opts.iv))
+		}
+		return &aesCipher{block: block, opts: opts}, nil
+	}
+
+	if len(opts.iv) == 0 {
+		return nil, errors.New("the iv option must not be empty")
+	}
+	aead, err := cipher.NewGCMWithNonceSize(block, len(opts.iv))
+	if err != nil {
+		return nil, err
+	}
+
+	return &aesCipher{block: block, aead: aead, opts: opts}, nil
+}
+
+func (c *Crypto) parseCipherOptions(options goja.Value) (cipherOptions, error) {
+	var opts cipherOptions
+
+	if common.IsNullish(options) {
+		return opts, errors.New("the options, holding at least the iv, are required")
+	}
+
+	obj := options.ToObject(c.vu.Runtime())
+
+	iv := obj.Get("iv")
+	if common.IsNullish(iv) {
+		return opts, errors.New("the iv option is required")
+	}
+	var err error
+	if opts.iv, err = toBytes(iv); err != nil {
+		return opts, fmt.Errorf("invalid iv option: %w", err)
+	}
+
+	if ad := obj.Get("additionalData"); !common.IsNullish(ad) {
+		if opts.additionalData, err = toBytes(ad); err != nil {
+			return opts, fmt.Errorf("invalid additionalData option: %w", err)
+		}
+	}
+
+	return opts, nil
+}
+
+// pkcs7Pad returns a copy of the data, padded to a multiple of the block size.
+func pkcs7Pad(data []byte, blockSize int) []byte {
+	padding := blockSize - len(data)%blockSize
+	return append(append(make([]byte, 0, len(data)+padding), data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+}
+
+// pkcs7Unpad returns the data without its padding.
+func pkcs7Unpad(data []byte, blockSize int) ([]byte, error) {
+	padding := int(data[len(data)-1])
+	if padding == 0 || padding > blockSize || padding > len(data) {
+		return nil, errors.New("invalid padding")
+	}
+	for _, b := range data[len(data)-padding:] {
+		if int(b) != padding {
+			return nil, errors.New("invalid padding")
+		}
+	}
+	return data[:len(data)-padding], nil
+}
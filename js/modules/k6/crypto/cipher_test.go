@@ -0,0 +1,157 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeCipherRuntime returns a runtime with a fromHex function, returning the
+// ArrayBuffer of the given hex string.
+func makeCipherRuntime(t *testing.T) *goja.Runtime {
+	t.Helper()
+
+	rt := makeRuntime(t)
+	_, err := rt.RunString(`
+		function fromHex(s) {
+			var bytes = new Uint8Array(s.length / 2);
+			for (var i = 0; i < bytes.length; i++) {
+				bytes[i] = parseInt(s.substr(i * 2, 2), 16);
+			}
+			return bytes.buffer;
+		}
+		function toString(buf) {
+			return String.fromCharCode.apply(null, new Uint8Array(buf));
+		}
+	`)
+	require.NoError(t, err)
+
+	return rt
+}
+
+func TestCipher(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"AES-GCM known answer": `
+			var ct = crypto.encrypt("aes-gcm", fromHex("00000000000000000000000000000000"),
+				fromHex("00000000000000000000000000000000"), { iv: fromHex("000000000000000000000000") });
+			var expected = "0388dace60b6a392f328c2b971b2fe78" + "ab6e47d42cec13bdf53a67b21257bddf";
+			if (crypto.hexEncode(ct) !== expected) {
+				throw new Error("unexpected ciphertext " + crypto.hexEncode(ct));
+			}
+		`,
+		"AES-GCM round trip with additional data": `
+			var key = crypto.randomBytes(32), iv = crypto.randomBytes(12);
+			var ct = crypto.encrypt("aes-gcm", key, "secret", { iv: iv, additionalData: "header" });
+			var pt = crypto.decrypt("aes-gcm", key, ct, { iv: iv, additionalData: "header" });
+			if (toString(pt) !== "secret") {
+				throw new Error("unexpected plaintext " + toString(pt));
+			}
+		`,
+		"AES-GCM rejects tampered data": `
+			var key = crypto.randomBytes(16), iv = crypto.randomBytes(12);
+			var ct = new Uint8Array(crypto.encrypt("aes-gcm", key, "secret", { iv: iv }));
+			ct[0] ^= 1;
+			try {
+				crypto.decrypt("aes-gcm", key, ct.buffer, { iv: iv });
+				throw new Error("expected the decryption to fail");
+			} catch (e) {
+				if (e.message.indexOf("can't be authenticated") < 0) {
+					throw e;
+				}
+			}
+		`,
+		"AES-CBC known answer": `
+			var ct = crypto.encrypt("aes-cbc", fromHex("000102030405060708090a0b0c0d0e0f"), "hello world",
+				{ iv: fromHex("0f0e0d0c0b0a09080706050403020100") });
+			if (crypto.hexEncode(ct) !== "3fb51c0ccbcb533bb82a08e6817013ea") {
+				throw new Error("unexpected ciphertext " + crypto.hexEncode(ct));
+			}
+		`,
+		"AES-CBC round trip": `
+			var key = crypto.randomBytes(24), iv = crypto.randomBytes(16);
+			var data = "0123456789abcdef";
+			var ct = crypto.encrypt("aes-cbc", key, data, { iv: iv });
+			if (ct.byteLength !== 32) {
+				throw new Error("unexpected ciphertext length " + ct.byteLength);
+			}
+			if (toString(crypto.decrypt("aes-cbc", key, ct, { iv: iv })) !== data) {
+				throw new Error("unexpected plaintext");
+			}
+		`,
+		"JWK key": `
+			var jwk = { kty: "oct", k: "AAECAwQFBgcICQoLDA0ODw" };
+			var ct = crypto.encrypt("aes-cbc", jwk, "hello world", { iv: fromHex("0f0e0d0c0b0a09080706050403020100") });
+			if (crypto.hexEncode(ct) !== "3fb51c0ccbcb533bb82a08e6817013ea") {
+				throw new Error("unexpected ciphertext " + crypto.hexEncode(ct));
+			}
+		`,
+	}
+
+	for name, script := range tests {
+		script := script
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := makeCipherRuntime(t).RunString(script)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCipherErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		code, err string
+	}{
+		"unsupported algorithm": {
+			code: `crypto.encrypt("aes-ctr", crypto.randomBytes(16), "data", { iv: crypto.randomBytes(16) })`,
+			err:  `unsupported cipher algorithm "aes-ctr"`,
+		},
+		"invalid key size": {
+			code: `crypto.encrypt("aes-gcm", crypto.randomBytes(10), "data", { iv: crypto.randomBytes(12) })`,
+			err:  "it must be 16, 24 or 32 bytes long, got 10",
+		},
+		"asymmetric JWK": {
+			code: `crypto.encrypt("aes-gcm", { kty: "OKP", crv: "Ed25519", x: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo" },
+				"data", { iv: crypto.randomBytes(12) })`,
+			err: "a secret key is expected",
+		},
+		"missing options": {
+			code: `crypto.encrypt("aes-gcm", crypto.randomBytes(16), "data")`,
+			err:  "the options, holding at least the iv, are required",
+		},
+		"missing iv": {
+			code: `crypto.encrypt("aes-gcm", crypto.randomBytes(16), "data", {})`,
+			err:  "the iv option is required",
+		},
+		"invalid CBC iv": {
+			code: `crypto.encrypt("aes-cbc", crypto.randomBytes(16), "data", { iv: crypto.randomBytes(12) })`,
+			err:  "the iv option must be 16 bytes long, got 12",
+		},
+		"CBC additional data": {
+			code: `crypto.encrypt("aes-cbc", crypto.randomBytes(16), "data",
+				{ iv: crypto.randomBytes(16), additionalData: "x" })`,
+			err: "the additionalData option is only supported by aes-gcm",
+		},
+		"invalid CBC padding": {
+			code: `crypto.decrypt("aes-cbc", crypto.randomBytes(16), crypto.randomBytes(15), { iv: crypto.randomBytes(16) })`,
+			err:  "the data isn't a multiple of the block size",
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := makeCipherRuntime(t).RunString(tc.code)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Package crypto provides common hashing function for the k6
+// Package crypto provides common hashing, encryption and signing functions for the k6
 package crypto
 
 import (
@@ -69,6 +69,15 @@ func (c *Crypto) Exports() modules.Exports {
 			"sha512_224":  c.sha512_224,
 			"sha512_256":  c.sha512_256,
 			"hexEncode":   c.hexEncode,
+			"encrypt":     c.encrypt,
+			"decrypt":     c.decrypt,
+			"sign":        c.sign,
+			"verify":      c.verify,
+			"signJWS":     c.signJWS,
+			"verifyJWS":   c.verifyJWS,
+			"signJWT":     c.signJWT,
+			"verifyJWT":   c.verifyJWT,
+			"decodeJWT":   c.decodeJWT,
 		},
 	}
 }
@@ -0,0 +1,349 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+)
+
+// jws is a JSON Web Signature (RFC 7515) in its compact serialization.
+type jws struct {
+	// header and payload are decoded, signature is raw.
+	header    []byte
+	payload   []byte
+	signature []byte
+
+	// signingInput is the encoded header and payload, joined by a dot.
+	signingInput string
+}
+
+// parseJWS splits and decodes the given compact JWS, without verifying it.
+func parseJWS(token string) (*jws, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid token: 3 dot-separated parts are expected, got %d", len(parts))
+	}
+
+	var (
+		t   = &jws{signingInput: parts[0] + "." + parts[1]}
+		err error
+	)
+	if t.header, err = base64.RawURLEncoding.DecodeString(parts[0]); err != nil {
+		return nil, fmt.Errorf("invalid token header: %w", err)
+	}
+	if t.payload, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+		return nil, fmt.Errorf("invalid token payload: %w", err)
+	}
+	if t.signature, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return nil, fmt.Errorf("invalid token signature: %w", err)
+	}
+
+	return t, nil
+}
+
+// verify ensures the token's header declares the given algorithm, and that its
+// signature is valid for the given key.
+//
+// The algorithm is chosen by the caller rather than by the token, so that
+// a token can't downgrade its own verification, as with the "none" algorithm.
+func (t *jws) verify(algorithm string, key goja.Value) error {
+	alg, err := getSignatureAlgorithm(algorithm)
+	if err != nil {
+		return err
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(t.header, &header); err != nil {
+		return fmt.Errorf("invalid token header: %w", err)
+	}
+	if header.Alg != alg.name {
+		return fmt.Errorf("the token's algorithm %q doesn't match the expected %q", header.Alg, alg.name)
+	}
+
+	valid, err := alg.verify(key, []byte(t.signingInput), t.signature)
+	if err != nil {
+		return fmt.Errorf("%s verification failed: %w", alg.name, err)
+	}
+	if !valid {
+		return errors.New("invalid token signature")
+	}
+
+	return nil
+}
+
+// signJWS returns the compact JWS of the given payload, a string or an ArrayBuffer,
+// signed with the algorithm and key. The fields of options.header are added to
+// the protected header.
+func (c *Crypto) signJWS(payload goja.Value, algorithm string, key, options goja.Value) (string, error) {
+	p, err := toBytes(payload)
+	if err != nil {
+		return "", err
+	}
+
+	return c.signCompact(p, algorithm, key, options, false)
+}
+
+// verifyJWS verifies the given compact JWS, and returns an object holding its
+// header, and its payload as an ArrayBuffer.
+func (c *Crypto) verifyJWS(token, algorithm string, key goja.Value) (*goja.Object, error) {
+	t, err := parseJWS(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.verify(algorithm, key); err != nil {
+		return nil, err
+	}
+
+	return c.tokenObject(t, false, false)
+}
+
+// signJWT returns a JSON Web Token (RFC 7519) holding the given claims, signed with
+// the algorithm and key. The typ header defaults to JWT, and the fields of
+// options.header are added to the header.
+func (c *Crypto) signJWT(payload goja.Value, algorithm string, key, options goja.Value) (string, error) {
+	if common.IsNullish(payload) {
+		return "", errors.New("the payload must be an object")
+	}
+	p, err := c.stringify(payload)
+	if err != nil {
+		return "", fmt.Errorf("invalid payload: %w", err)
+	}
+
+	return c.signCompact([]byte(p), algorithm, key, options, true)
+}
+
+// verifyJWT verifies the signature and the claims of the given JSON Web Token, and
+// returns an object holding its header and its payload.
+//
+// The exp and nbf claims are always checked, with options.clockTolerance seconds
+// of leeway. The iss and aud claims are checked when options.issuer and
+// options.audience are set.
+func (c *Crypto) verifyJWT(token, algorithm string, key, options goja.Value) (*goja.Object, error) {
+	t, err := parseJWS(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.verify(algorithm, key); err != nil {
+		return nil, err
+	}
+
+	opts, err := c.parseJWTOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.validate(t.payload, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return c.tokenObject(t, true, false)
+}
+
+// decodeJWT returns an object holding the header, the payload and the signature, as
+// an ArrayBuffer, of the given JSON Web Token. The token is not verified.
+func (c *Crypto) decodeJWT(token string) (*goja.Object, error) {
+	t, err := parseJWS(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.tokenObject(t, true, true)
+}
+
+func (c *Crypto) signCompact(payload []byte, algorithm string, key, options goja.Value, jwt bool) (string, error) {
+	alg, err := getSignatureAlgorithm(algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	rt := c.vu.Runtime()
+	header := rt.NewObject()
+	if err := header.Set("alg", alg.name); err != nil {
+		return "", err
+	}
+	if jwt {
+		if err := header.Set("typ", "JWT"); err != nil {
+			return "", err
+		}
+	}
+
+	if !common.IsNullish(options) {
+		if extra := options.ToObject(rt).Get("header"); !common.IsNullish(extra) {
+			extraObj := extra.ToObject(rt)
+			for _, k := range extraObj.Keys() {
+				if k == "alg" && extraObj.Get(k).String() != alg.name {
+					return "", fmt.Errorf("the alg header can't differ from the %q algorithm", alg.name)
+				}
+				if err := header.Set(k, extraObj.Get(k)); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+
+	h, err := c.stringify(header)
+	if err != nil {
+		return "", fmt.Errorf("invalid header: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(h)) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+
+	signature, err := alg.sign(key, []byte(signingInput))
+	if err != nil {
+		return "", fmt.Errorf("%s signing failed: %w", alg.name, err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// tokenObject returns the JS object of the given token. Its payload is parsed as
+// JSON if jsonPayload is true, and left as an ArrayBuffer otherwise.
+func (c *Crypto) tokenObject(t *jws, jsonPayload, withSignature bool) (*goja.Object, error) {
+	rt := c.vu.Runtime()
+
+	header, err := c.parse(t.header)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token header: %w", err)
+	}
+
+	var payload goja.Value
+	if jsonPayload {
+		if payload, err = c.parse(t.payload); err != nil {
+			return nil, fmt.Errorf("invalid token payload: %w", err)
+		}
+	} else {
+		payload = rt.ToValue(rt.NewArrayBuffer(t.payload))
+	}
+
+	obj := rt.NewObject()
+	if err := obj.Set("header", header); err != nil {
+		return nil, err
+	}
+	if err := obj.Set("payload", payload); err != nil {
+		return nil, err
+	}
+	if withSignature {
+		if err := obj.Set("signature", rt.NewArrayBuffer(t.signature)); err != nil {
+			return nil, err
+		}
+	}
+
+	return obj, nil
+}
+
+// stringify returns the JSON of the given value, as JSON.stringify does.
+func (c *Crypto) stringify(value goja.Value) (string, error) {
+	rt := c.vu.Runtime()
+	stringify, _ := goja.AssertFunction(rt.GlobalObject().Get("JSON").ToObject(rt).Get("stringify"))
+
+	v, err := stringify(goja.Undefined(), value)
+	if err != nil {
+		return "", err
+	}
+	if common.IsNullish(v) {
+		return "", errors.New("the value can't be serialized to JSON")
+	}
+
+	return v.String(), nil
+}
+
+// parse returns the value of the given JSON, as JSON.parse does.
+func (c *Crypto) parse(data []byte) (goja.Value, error) {
+	rt := c.vu.Runtime()
+	parse, _ := goja.AssertFunction(rt.GlobalObject().Get("JSON").ToObject(rt).Get("parse"))
+
+	return parse(goja.Undefined(), rt.ToValue(string(data)))
+}
+
+// jwtOptions holds the options of the verification of a JWT's claims.
+type jwtOptions struct {
+	clockTolerance time.Duration
+	issuer         string
+	audience       string
+}
+
+func (c *Crypto) parseJWTOptions(options goja.Value) (jwtOptions, error) {
+	var opts jwtOptions
+	if common.IsNullish(options) {
+		return opts, nil
+	}
+
+	obj := options.ToObject(c.vu.Runtime())
+	if v := obj.Get("clockTolerance"); !common.IsNullish(v) {
+		seconds := v.ToFloat()
+		if math.IsNaN(seconds) || seconds < 0 {
+			return opts, fmt.Errorf("the clockTolerance option must be a positive number of seconds, got %s", v)
+		}
+		opts.clockTolerance = time.Duration(seconds * float64(time.Second))
+	}
+	if v := obj.Get("issuer"); !common.IsNullish(v) {
+		opts.issuer = v.String()
+	}
+	if v := obj.Get("audience"); !common.IsNullish(v) {
+		opts.audience = v.String()
+	}
+
+	return opts, nil
+}
+
+// validate checks the registered claims of the given JWT payload.
+func (opts jwtOptions) validate(payload []byte, now time.Time) error {
+	var claims struct {
+		Exp *float64        `json:"exp"`
+		Nbf *float64        `json:"nbf"`
+		Iss *string         `json:"iss"`
+		Aud json.RawMessage `json:"aud"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return fmt.Errorf("invalid token claims: %w", err)
+	}
+
+	if claims.Exp != nil && !now.Add(-opts.clockTolerance).Before(numericDate(*claims.Exp)) {
+		return errors.New("the token has expired")
+	}
+	if claims.Nbf != nil && now.Add(opts.clockTolerance).Before(numericDate(*claims.Nbf)) {
+		return errors.New("the token is not valid yet")
+	}
+
+	if opts.issuer != "" && (claims.Iss == nil || *claims.Iss != opts.issuer) {
+		return fmt.Errorf("the token's issuer doesn't match the expected %q", opts.issuer)
+	}
+
+	if opts.audience != "" {
+		// The audience is either a single string, or an array of them.
+		var audiences []string
+		var audience string
+		if err := json.Unmarshal(claims.Aud, &audience); err == nil {
+			audiences = []string{audience}
+		} else {
+			_ = json.Unmarshal(claims.Aud, &audiences)
+		}
+
+		found := false
+		for _, a := range audiences {
+			found = found || a == opts.audience
+		}
+		if !found {
+			return fmt.Errorf("the token's audience doesn't include the expected %q", opts.audience)
+		}
+	}
+
+	return nil
+}
+
+// numericDate returns the time of the given JWT NumericDate, a number of seconds
+// since the epoch.
+func numericDate(seconds float64) time.Time {
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*float64(time.Second)))
+}
@@ -0,0 +1,157 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWS(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"RFC 7515 HS256 example": `
+			var token = "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9" +
+				".eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ" +
+				".dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk";
+			var key = { kty: "oct", k: "AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow" };
+
+			var jws = crypto.verifyJWS(token, "HS256", key);
+			if (jws.header.alg !== "HS256" || !(jws.payload instanceof ArrayBuffer)) {
+				throw new Error("unexpected JWS " + JSON.stringify(jws));
+			}
+			var claims = JSON.parse(String.fromCharCode.apply(null, new Uint8Array(jws.payload)));
+			if (claims.iss !== "joe") {
+				throw new Error("unexpected claims " + JSON.stringify(claims));
+			}
+		`,
+		"RFC 8037 EdDSA example": `
+			var key = {
+				kty: "OKP", crv: "Ed25519",
+				d: "nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A",
+				x: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo",
+			};
+			var expected = "eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc" +
+				".hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg";
+
+			var token = crypto.signJWS("Example of Ed25519 signing", "EdDSA", key);
+			if (token !== expected) {
+				throw new Error("unexpected token " + token);
+			}
+			crypto.verifyJWS(token, "EdDSA", { kty: key.kty, crv: key.crv, x: key.x });
+		`,
+		"custom header": `
+			var token = crypto.signJWS("data", "HS256", "secret", { header: { kid: "key-1" } });
+			var jws = crypto.verifyJWS(token, "HS256", "secret");
+			if (jws.header.kid !== "key-1" || jws.header.typ !== undefined) {
+				throw new Error("unexpected header " + JSON.stringify(jws.header));
+			}
+		`,
+	}
+
+	for name, script := range tests {
+		script := script
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := makeRuntime(t).RunString(script)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestJWT(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"round trip": `
+			var now = Math.floor(Date.now() / 1000);
+			var token = crypto.signJWT({ sub: "user", iss: "k6", aud: ["api", "web"], exp: now + 60, nbf: now },
+				"ES256", keys.p256.privateJWK, { header: { kid: "key-1" } });
+
+			var jwt = crypto.verifyJWT(token, "ES256", keys.p256.publicPEM, { issuer: "k6", audience: "web" });
+			if (jwt.header.typ !== "JWT" || jwt.header.kid !== "key-1" || jwt.payload.sub !== "user") {
+				throw new Error("unexpected JWT " + JSON.stringify(jwt));
+			}
+		`,
+		"decode": `
+			var token = crypto.signJWT({ sub: "user" }, "RS256", keys.rsa.privatePEM);
+			var jwt = crypto.decodeJWT(token);
+			if (jwt.header.alg !== "RS256" || jwt.payload.sub !== "user" || jwt.signature.byteLength !== 256) {
+				throw new Error("unexpected JWT " + JSON.stringify(jwt));
+			}
+		`,
+		"clock tolerance": `
+			var now = Math.floor(Date.now() / 1000);
+			var token = crypto.signJWT({ exp: now - 10 }, "HS256", "secret");
+			crypto.verifyJWT(token, "HS256", "secret", { clockTolerance: 60 });
+		`,
+	}
+
+	for name, script := range tests {
+		script := script
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := makeSignatureRuntime(t).RunString(script)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestJWTErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		code, err string
+	}{
+		"expired": {
+			code: `crypto.verifyJWT(crypto.signJWT({ exp: Date.now() / 1000 - 10 }, "HS256", "secret"), "HS256", "secret")`,
+			err:  "the token has expired",
+		},
+		"not valid yet": {
+			code: `crypto.verifyJWT(crypto.signJWT({ nbf: Date.now() / 1000 + 60 }, "HS256", "secret"), "HS256", "secret")`,
+			err:  "the token is not valid yet",
+		},
+		"issuer mismatch": {
+			code: `crypto.verifyJWT(crypto.signJWT({ iss: "a" }, "HS256", "secret"), "HS256", "secret", { issuer: "b" })`,
+			err:  `the token's issuer doesn't match the expected "b"`,
+		},
+		"audience mismatch": {
+			code: `crypto.verifyJWT(crypto.signJWT({ aud: ["a"] }, "HS256", "secret"), "HS256", "secret", { audience: "b" })`,
+			err:  `the token's audience doesn't include the expected "b"`,
+		},
+		"invalid signature": {
+			code: `crypto.verifyJWT(crypto.signJWT({}, "HS256", "secret"), "HS256", "other secret")`,
+			err:  "invalid token signature",
+		},
+		"algorithm mismatch": {
+			code: `crypto.verifyJWT(crypto.signJWT({}, "HS256", keys.rsa.publicPEM), "RS256", keys.rsa.publicPEM)`,
+			err:  `the token's algorithm "HS256" doesn't match the expected "RS256"`,
+		},
+		"none algorithm": {
+			code: `crypto.verifyJWT("eyJhbGciOiJub25lIn0.e30.", "none", "")`,
+			err:  `unsupported signature algorithm "none"`,
+		},
+		"malformed token": {
+			code: `crypto.decodeJWT("a.b")`,
+			err:  "3 dot-separated parts are expected, got 2",
+		},
+		"conflicting alg header": {
+			code: `crypto.signJWT({}, "HS256", "secret", { header: { alg: "none" } })`,
+			err:  `the alg header can't differ from the "HS256" algorithm`,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := makeSignatureRuntime(t).RunString(tc.code)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
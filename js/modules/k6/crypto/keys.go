@@ -0,0 +1,347 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+)
+
+// parsePrivateKey returns the private key held by the given value, either a PEM
+// encoded key, as a string or an ArrayBuffer, or a JWK object.
+//
+// The supported PEM blocks are PKCS #8 keys, PKCS #1 RSA keys and SEC 1 EC keys.
+func parsePrivateKey(value goja.Value) (crypto.Signer, error) {
+	var (
+		key any
+		err error
+	)
+
+	if jwk, ok := exportJWK(value); ok {
+		key, err = parseJWK(jwk)
+	} else {
+		key, err = parsePEMKey(value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("a private key is expected, got a public key")
+	}
+
+	return signer, nil
+}
+
+// parsePublicKey returns the public key held by the given value, either a PEM
+// encoded key or certificate, as a string or an ArrayBuffer, or a JWK object.
+//
+// The supported PEM blocks are PKIX and PKCS #1 RSA public keys, certificates, and
+// the private keys supported by parsePrivateKey, whose public key is then used.
+func parsePublicKey(value goja.Value) (crypto.PublicKey, error) {
+	var (
+		key any
+		err error
+	)
+
+	if jwk, ok := exportJWK(value); ok {
+		key, err = parseJWK(jwk)
+	} else {
+		key, err = parsePEMKey(value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if signer, ok := key.(crypto.Signer); ok {
+		return signer.Public(), nil
+	}
+
+	return key, nil
+}
+
+// parseSecretKey returns the secret key held by the given value, either raw bytes,
+// as a string or an ArrayBuffer, or a JWK object of the oct type.
+func parseSecretKey(value goja.Value) ([]byte, error) {
+	if jwk, ok := exportJWK(value); ok {
+		key, err := parseJWK(jwk)
+		if err != nil {
+			return nil, err
+		}
+
+		secret, ok := key.([]byte)
+		if !ok {
+			return nil, errors.New("a secret key is expected, got an asymmetric key")
+		}
+		return secret, nil
+	}
+
+	return toBytes(value)
+}
+
+// exportJWK returns the given value as a JWK, if it's a plain object.
+func exportJWK(value goja.Value) (map[string]any, bool) {
+	if common.IsNullish(value) {
+		return nil, false
+	}
+
+	jwk, ok := value.Export().(map[string]any)
+	return jwk, ok
+}
+
+func parsePEMKey(value goja.Value) (any, error) {
+	data, err := toBytes(value)
+	if err != nil {
+		return nil, fmt.Errorf("a PEM encoded key or a JWK is expected: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("a PEM encoded key or a JWK is expected, no PEM block was found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
+
+// parseJWK returns the key described by the given JWK, as defined by RFC 7517
+// and RFC 8037: a *rsa.PrivateKey, *rsa.PublicKey, *ecdsa.PrivateKey,
+// *ecdsa.PublicKey, ed25519.PrivateKey, ed25519.PublicKey, or a []byte
+// for the secret keys.
+func parseJWK(jwk map[string]any) (any, error) {
+	j := jwkFields(jwk)
+
+	kty, err := j.string("kty")
+	if err != nil {
+		return nil, err
+	}
+
+	switch kty {
+	case "oct":
+		return j.bytes("k")
+	case "RSA":
+		return parseRSAJWK(j)
+	case "EC":
+		return parseECJWK(j)
+	case "OKP":
+		return parseOKPJWK(j)
+	default:
+		return nil, fmt.Errorf("unsupported JWK key type %q", kty)
+	}
+}
+
+type jwkFields map[string]any
+
+func (j jwkFields) has(name string) bool {
+	_, ok := j[name]
+	return ok
+}
+
+func (j jwkFields) string(name string) (string, error) {
+	s, ok := j[name].(string)
+	if !ok {
+		return "", fmt.Errorf("the JWK's %q member must be a string", name)
+	}
+	return s, nil
+}
+
+// bytes returns the value of the base64url encoded member with the given name.
+func (j jwkFields) bytes(name string) ([]byte, error) {
+	s, err := j.string(name)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, fmt.Errorf("the JWK's %q member isn't base64url encoded: %w", name, err)
+	}
+	return b, nil
+}
+
+func (j jwkFields) bigInt(name string) (*big.Int, error) {
+	b, err := j.bytes(name)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func parseRSAJWK(j jwkFields) (any, error) {
+	n, err := j.bigInt("n")
+	if err != nil {
+		return nil, err
+	}
+	e, err := j.bigInt("e")
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, errors.New("the JWK's public exponent is too large")
+	}
+
+	public := rsa.PublicKey{N: n, E: int(e.Int64())}
+	if !j.has("d") {
+		return &public, nil
+	}
+
+	key := &rsa.PrivateKey{PublicKey: public}
+	if key.D, err = j.bigInt("d"); err != nil {
+		return nil, err
+	}
+	p, err := j.bigInt("p")
+	if err != nil {
+		return nil, err
+	}
+	q, err := j.bigInt("q")
+	if err != nil {
+		return nil, err
+	}
+	key.Primes = []*big.Int{p, q}
+
+	if err := key.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid RSA JWK: %w", err)
+	}
+	key.Precompute()
+
+	return key, nil
+}
+
+func parseECJWK(j jwkFields) (any, error) {
+	crv, err := j.string("crv")
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		curve     elliptic.Curve
+		ecdhCurve ecdh.Curve
+	)
+	switch crv {
+	case "P-256":
+		curve, ecdhCurve = elliptic.P256(), ecdh.P256()
+	case "P-384":
+		curve, ecdhCurve = elliptic.P384(), ecdh.P384()
+	case "P-521":
+		curve, ecdhCurve = elliptic.P521(), ecdh.P521()
+	default:
+		return nil, fmt.Errorf("unsupported JWK curve %q", crv)
+	}
+
+	size := (curve.Params().BitSize + 7) / 8
+	x, err := j.bytes("x")
+	if err != nil {
+		return nil, err
+	}
+	y, err := j.bytes("y")
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != size || len(y) != size {
+		return nil, fmt.Errorf("the JWK's coordinates must be %d bytes long for the %s curve", size, crv)
+	}
+
+	// The point is validated by the ecdh package, as the crypto/elliptic low-level
+	// functions are deprecated.
+	point := append(append([]byte{4}, x...), y...)
+	if _, err := ecdhCurve.NewPublicKey(point); err != nil {
+		return nil, fmt.Errorf("invalid EC JWK: %w", err)
+	}
+
+	public := ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	if !j.has("d") {
+		return &public, nil
+	}
+
+	d, err := j.bytes("d")
+	if err != nil {
+		return nil, err
+	}
+	private, err := ecdhCurve.NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EC JWK: %w", err)
+	}
+	if !bytes.Equal(private.PublicKey().Bytes(), point) {
+		return nil, errors.New("invalid EC JWK: the private key doesn't match the public key")
+	}
+
+	return &ecdsa.PrivateKey{PublicKey: public, D: new(big.Int).SetBytes(d)}, nil
+}
+
+func parseOKPJWK(j jwkFields) (any, error) {
+	crv, err := j.string("crv")
+	if err != nil {
+		return nil, err
+	}
+	if crv != "Ed25519" {
+		return nil, fmt.Errorf("unsupported JWK curve %q", crv)
+	}
+
+	x, err := j.bytes("x")
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("the JWK's public key must be %d bytes long", ed25519.PublicKeySize)
+	}
+	if !j.has("d") {
+		return ed25519.PublicKey(x), nil
+	}
+
+	d, err := j.bytes("d")
+	if err != nil {
+		return nil, err
+	}
+	if len(d) != ed25519.SeedSize {
+		return nil, fmt.Errorf("the JWK's private key must be %d bytes long", ed25519.SeedSize)
+	}
+
+	key := ed25519.NewKeyFromSeed(d)
+	if !bytes.Equal(key.Public().(ed25519.PublicKey), x) { //nolint:forcetypeassert
+		return nil, errors.New("invalid OKP JWK: the private key doesn't match the public key")
+	}
+
+	return key, nil
+}
+
+// toBytes returns the bytes of the given string, ArrayBuffer or Uint8Array.
+func toBytes(value goja.Value) ([]byte, error) {
+	if common.IsNullish(value) {
+		return nil, errors.New("a string or an ArrayBuffer is expected, got null or undefined")
+	}
+
+	return common.ToBytes(value.Export())
+}
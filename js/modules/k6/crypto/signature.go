@@ -0,0 +1,233 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/dop251/goja"
+)
+
+// signatureAlgorithm is a signature algorithm, named as in JSON Web Algorithms (RFC 7518).
+type signatureAlgorithm struct {
+	name string
+	kind signatureKind
+	hash crypto.Hash
+
+	// curveSize is the size, in bytes, of the ECDSA curve's values.
+	curveSize int
+	curveName string
+}
+
+type signatureKind uint8
+
+const (
+	signatureHMAC signatureKind = iota + 1
+	signatureRSA
+	signatureRSAPSS
+	signatureECDSA
+	signatureEdDSA
+)
+
+//nolint:gochecknoglobals
+var signatureAlgorithms = map[string]signatureAlgorithm{
+	"HS256": {name: "HS256", kind: signatureHMAC, hash: crypto.SHA256},
+	"HS384": {name: "HS384", kind: signatureHMAC, hash: crypto.SHA384},
+	"HS512": {name: "HS512", kind: signatureHMAC, hash: crypto.SHA512},
+	"RS256": {name: "RS256", kind: signatureRSA, hash: crypto.SHA256},
+	"RS384": {name: "RS384", kind: signatureRSA, hash: crypto.SHA384},
+	"RS512": {name: "RS512", kind: signatureRSA, hash: crypto.SHA512},
+	"PS256": {name: "PS256", kind: signatureRSAPSS, hash: crypto.SHA256},
+	"PS384": {name: "PS384", kind: signatureRSAPSS, hash: crypto.SHA384},
+	"PS512": {name: "PS512", kind: signatureRSAPSS, hash: crypto.SHA512},
+	"ES256": {name: "ES256", kind: signatureECDSA, hash: crypto.SHA256, curveSize: 32, curveName: "P-256"},
+	"ES384": {name: "ES384", kind: signatureECDSA, hash: crypto.SHA384, curveSize: 48, curveName: "P-384"},
+	"ES512": {name: "ES512", kind: signatureECDSA, hash: crypto.SHA512, curveSize: 66, curveName: "P-521"},
+	"EdDSA": {name: "EdDSA", kind: signatureEdDSA},
+}
+
+func getSignatureAlgorithm(name string) (signatureAlgorithm, error) {
+	alg, ok := signatureAlgorithms[name]
+	if !ok {
+		return alg, fmt.Errorf("unsupported signature algorithm %q", name)
+	}
+	return alg, nil
+}
+
+// sign returns the signature of the data, as an ArrayBuffer, with the given algorithm
+// and key. The key is a secret for the HMAC algorithms, and a private key otherwise,
+// see parseSecretKey and parsePrivateKey.
+//
+// The ECDSA signatures are the concatenation of the r and s values, as in JWS and
+// WebCrypto, rather than ASN.1 DER encoded.
+func (c *Crypto) sign(algorithm string, key, data goja.Value) (*goja.ArrayBuffer, error) {
+	alg, err := getSignatureAlgorithm(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := toBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := alg.sign(key, d)
+	if err != nil {
+		return nil, fmt.Errorf("%s signing failed: %w", alg.name, err)
+	}
+
+	ab := c.vu.Runtime().NewArrayBuffer(signature)
+	return &ab, nil
+}
+
+// verify returns true if the signature of the data is valid for the given algorithm
+// and key. The key is a secret for the HMAC algorithms, and a public key otherwise,
+// see parseSecretKey and parsePublicKey.
+func (c *Crypto) verify(algorithm string, key, data, signature goja.Value) (bool, error) {
+	alg, err := getSignatureAlgorithm(algorithm)
+	if err != nil {
+		return false, err
+	}
+
+	d, err := toBytes(data)
+	if err != nil {
+		return false, err
+	}
+	s, err := toBytes(signature)
+	if err != nil {
+		return false, err
+	}
+
+	valid, err := alg.verify(key, d, s)
+	if err != nil {
+		return false, fmt.Errorf("%s verification failed: %w", alg.name, err)
+	}
+
+	return valid, nil
+}
+
+func (alg signatureAlgorithm) digest(data []byte) []byte {
+	h := alg.hash.New()
+	_, _ = h.Write(data)
+	return h.Sum(nil)
+}
+
+func (alg signatureAlgorithm) sign(keyValue goja.Value, data []byte) ([]byte, error) {
+	if alg.kind == signatureHMAC {
+		secret, err := parseSecretKey(keyValue)
+		if err != nil {
+			return nil, err
+		}
+		mac := hmac.New(alg.hash.New, secret)
+		_, _ = mac.Write(data)
+		return mac.Sum(nil), nil
+	}
+
+	key, err := parsePrivateKey(keyValue)
+	if err != nil {
+		return nil, err
+	}
+	if err := alg.checkKey(key.Public()); err != nil {
+		return nil, err
+	}
+
+	switch alg.kind { //nolint:exhaustive
+	case signatureRSA:
+		return rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), alg.hash, alg.digest(data)) //nolint:forcetypeassert
+	case signatureRSAPSS:
+		return rsa.SignPSS(rand.Reader, key.(*rsa.PrivateKey), alg.hash, alg.digest(data), //nolint:forcetypeassert
+			&rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	case signatureECDSA:
+		r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), alg.digest(data)) //nolint:forcetypeassert
+		if err != nil {
+			return nil, err
+		}
+		signature := make([]byte, 2*alg.curveSize)
+		r.FillBytes(signature[:alg.curveSize])
+		s.FillBytes(signature[alg.curveSize:])
+		return signature, nil
+	default:
+		return ed25519.Sign(key.(ed25519.PrivateKey), data), nil //nolint:forcetypeassert
+	}
+}
+
+func (alg signatureAlgorithm) verify(keyValue goja.Value, data, signature []byte) (bool, error) {
+	if alg.kind == signatureHMAC {
+		expected, err := alg.sign(keyValue, data)
+		if err != nil {
+			return false, err
+		}
+		return hmac.Equal(expected, signature), nil
+	}
+
+	key, err := parsePublicKey(keyValue)
+	if err != nil {
+		return false, err
+	}
+	if err := alg.checkKey(key); err != nil {
+		return false, err
+	}
+
+	switch alg.kind { //nolint:exhaustive
+	case signatureRSA:
+		err := rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), alg.hash, alg.digest(data), signature) //nolint:forcetypeassert
+		return err == nil, nil
+	case signatureRSAPSS:
+		return rsa.VerifyPSS(key.(*rsa.PublicKey), alg.hash, alg.digest(data), signature, //nolint:forcetypeassert
+			&rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto}) == nil, nil
+	case signatureECDSA:
+		if len(signature) != 2*alg.curveSize {
+			return false, nil
+		}
+		r := new(big.Int).SetBytes(signature[:alg.curveSize])
+		s := new(big.Int).SetBytes(signature[alg.curveSize:])
+		return ecdsa.Verify(key.(*ecdsa.PublicKey), alg.digest(data), r, s), nil //nolint:forcetypeassert
+	default:
+		return ed25519.Verify(key.(ed25519.PublicKey), data, signature), nil //nolint:forcetypeassert
+	}
+}
+
+// checkKey ensures the given public key, or the public key of the private key
+// being used, is of the type required by the algorithm.
+func (alg signatureAlgorithm) checkKey(key crypto.PublicKey) error {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if alg.kind == signatureRSA || alg.kind == signatureRSAPSS {
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		if alg.kind == signatureECDSA {
+			if k.Curve.Params().Name != alg.curveName {
+				return fmt.Errorf("a key on the %s curve is required, got one on the %s curve",
+					alg.curveName, k.Curve.Params().Name)
+			}
+			return nil
+		}
+	case ed25519.PublicKey:
+		if alg.kind == signatureEdDSA {
+			return nil
+		}
+	}
+
+	return errors.New(alg.keyTypeError(key))
+}
+
+func (alg signatureAlgorithm) keyTypeError(key crypto.PublicKey) string {
+	var required string
+	switch alg.kind { //nolint:exhaustive
+	case signatureRSA, signatureRSAPSS:
+		required = "an RSA key"
+	case signatureECDSA:
+		required = "an EC key"
+	default:
+		required = "an Ed25519 key"
+	}
+
+	return fmt.Sprintf("%s is required, got a key of type %T", required, key)
+}
@@ -0,0 +1,265 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKeysJSON holds the JSON of the keys used by the tests, generated once. Each key
+// type is keyed by its name, and holds the keys in the PEM and JWK formats.
+var testKeysJSON struct { //nolint:gochecknoglobals
+	once sync.Once
+	json string
+}
+
+// makeSignatureRuntime returns a runtime whose keys global holds the test keys.
+func makeSignatureRuntime(t *testing.T) *goja.Runtime {
+	t.Helper()
+
+	testKeysJSON.once.Do(func() {
+		testKeysJSON.json = generateTestKeys(t)
+	})
+
+	rt := makeRuntime(t)
+	require.NoError(t, rt.Set("keysJSON", testKeysJSON.json))
+	_, err := rt.RunString(`var keys = JSON.parse(keysJSON);`)
+	require.NoError(t, err)
+
+	return rt
+}
+
+func generateTestKeys(t *testing.T) string {
+	t.Helper()
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	fixed := func(n *big.Int, size int) string {
+		return b64(n.FillBytes(make([]byte, size)))
+	}
+	pemOf := func(typ string, der []byte) string {
+		return string(pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}))
+	}
+	pkcs8 := func(key any) string {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		return pemOf("PRIVATE KEY", der)
+	}
+	pkix := func(key any) string {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		require.NoError(t, err)
+		return pemOf("PUBLIC KEY", der)
+	}
+
+	keys := map[string]map[string]any{}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPublicJWK := map[string]any{
+		"kty": "RSA", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}
+	rsaPrivateJWK := map[string]any{
+		"d": b64(rsaKey.D.Bytes()), "p": b64(rsaKey.Primes[0].Bytes()), "q": b64(rsaKey.Primes[1].Bytes()),
+	}
+	for k, v := range rsaPublicJWK {
+		rsaPrivateJWK[k] = v
+	}
+	keys["rsa"] = map[string]any{
+		"privatePEM":      pkcs8(rsaKey),
+		"pkcs1PrivatePEM": pemOf("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)),
+		"publicPEM":       pkix(&rsaKey.PublicKey),
+		"privateJWK":      rsaPrivateJWK,
+		"publicJWK":       rsaPublicJWK,
+	}
+
+	for name, curve := range map[string]elliptic.Curve{
+		"p256": elliptic.P256(), "p384": elliptic.P384(), "p521": elliptic.P521(),
+	} {
+		ecKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+		size := (curve.Params().BitSize + 7) / 8
+
+		sec1, err := x509.MarshalECPrivateKey(ecKey)
+		require.NoError(t, err)
+
+		publicJWK := map[string]any{
+			"kty": "EC", "crv": curve.Params().Name, "x": fixed(ecKey.X, size), "y": fixed(ecKey.Y, size),
+		}
+		privateJWK := map[string]any{"d": fixed(ecKey.D, size)}
+		for k, v := range publicJWK {
+			privateJWK[k] = v
+		}
+		keys[name] = map[string]any{
+			"privatePEM":     pkcs8(ecKey),
+			"sec1PrivatePEM": pemOf("EC PRIVATE KEY", sec1),
+			"publicPEM":      pkix(&ecKey.PublicKey),
+			"privateJWK":     privateJWK,
+			"publicJWK":      publicJWK,
+		}
+	}
+
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keys["ed25519"] = map[string]any{
+		"privatePEM": pkcs8(edPrivate),
+		"publicPEM":  pkix(edPublic),
+		"privateJWK": map[string]any{"kty": "OKP", "crv": "Ed25519", "x": b64(edPublic), "d": b64(edPrivate.Seed())},
+		"publicJWK":  map[string]any{"kty": "OKP", "crv": "Ed25519", "x": b64(edPublic)},
+	}
+
+	data, err := json.Marshal(keys)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestSignature(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		algorithm string
+		key       string
+		// signatureLength is the expected length of the signatures.
+		signatureLength int
+	}{
+		"RS256": {algorithm: "RS256", key: "rsa", signatureLength: 256},
+		"RS512": {algorithm: "RS512", key: "rsa", signatureLength: 256},
+		"PS256": {algorithm: "PS256", key: "rsa", signatureLength: 256},
+		"PS384": {algorithm: "PS384", key: "rsa", signatureLength: 256},
+		"ES256": {algorithm: "ES256", key: "p256", signatureLength: 64},
+		"ES384": {algorithm: "ES384", key: "p384", signatureLength: 96},
+		"ES512": {algorithm: "ES512", key: "p521", signatureLength: 132},
+		"EdDSA": {algorithm: "EdDSA", key: "ed25519", signatureLength: 64},
+		"HS256": {algorithm: "HS256", key: "hmac", signatureLength: 32},
+		"HS384": {algorithm: "HS384", key: "hmac", signatureLength: 48},
+		"HS512": {algorithm: "HS512", key: "hmac", signatureLength: 64},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rt := makeSignatureRuntime(t)
+			require.NoError(t, rt.Set("algorithm", tc.algorithm))
+			require.NoError(t, rt.Set("signatureLength", tc.signatureLength))
+
+			keysCode := `var key = keys[` + "`" + tc.key + "`" + `];
+				var privateKeys = [key.privatePEM, key.privateJWK, key.pkcs1PrivatePEM, key.sec1PrivatePEM];
+				var publicKeys = [key.publicPEM, key.publicJWK, key.privatePEM];`
+			if tc.key == "hmac" {
+				keysCode = `var privateKeys = ["secret", { kty: "oct", k: "c2VjcmV0" }];
+					var publicKeys = privateKeys;`
+			}
+
+			_, err := rt.RunString(keysCode + `
+				privateKeys.filter(function (k) { return k !== undefined; }).forEach(function (privateKey) {
+					var signature = crypto.sign(algorithm, privateKey, "data");
+					if (!(signature instanceof ArrayBuffer) || signature.byteLength !== signatureLength) {
+						throw new Error("unexpected signature length " + signature.byteLength);
+					}
+
+					publicKeys.forEach(function (publicKey) {
+						if (!crypto.verify(algorithm, publicKey, "data", signature)) {
+							throw new Error("expected the signature to be valid");
+						}
+						if (crypto.verify(algorithm, publicKey, "other data", signature)) {
+							throw new Error("expected the signature to be invalid");
+						}
+					});
+				});
+			`)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSignatureErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		code, err string
+	}{
+		"unsupported algorithm": {
+			code: `crypto.sign("RS1", keys.rsa.privatePEM, "data")`,
+			err:  `unsupported signature algorithm "RS1"`,
+		},
+		"none algorithm": {
+			code: `crypto.verify("none", "", "data", "")`,
+			err:  `unsupported signature algorithm "none"`,
+		},
+		"public key used to sign": {
+			code: `crypto.sign("RS256", keys.rsa.publicPEM, "data")`,
+			err:  "a private key is expected, got a public key",
+		},
+		"key type mismatch": {
+			code: `crypto.sign("ES256", keys.rsa.privatePEM, "data")`,
+			err:  "an EC key is required, got a key of type *rsa.PublicKey",
+		},
+		"curve mismatch": {
+			code: `crypto.verify("ES256", keys.p384.publicJWK, "data", new ArrayBuffer(64))`,
+			err:  "a key on the P-256 curve is required, got one on the P-384 curve",
+		},
+		"invalid PEM": {
+			code: `crypto.sign("RS256", "not a key", "data")`,
+			err:  "a PEM encoded key or a JWK is expected",
+		},
+		"mismatching EC JWK": {
+			code: `crypto.sign("ES256", Object.assign({}, keys.p256.privateJWK, { x: keys.p256.privateJWK.y }), "data")`,
+			err:  "invalid EC JWK",
+		},
+		"unsupported JWK type": {
+			code: `crypto.sign("ES256", { kty: "foo" }, "data")`,
+			err:  `unsupported JWK key type "foo"`,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := makeSignatureRuntime(t).RunString(tc.code)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestSignatureECDSAFormat(t *testing.T) {
+	t.Parallel()
+
+	rt := makeSignatureRuntime(t)
+	v, err := rt.RunString(`
+		var signature = crypto.sign("ES256", keys.p256.privatePEM, "data");
+		[keys.p256.publicJWK.x, keys.p256.publicJWK.y, new Uint8Array(signature)]
+	`)
+	require.NoError(t, err)
+
+	var values []any
+	require.NoError(t, rt.ExportTo(v, &values))
+	x, err := base64.RawURLEncoding.DecodeString(values[0].(string)) //nolint:forcetypeassert
+	require.NoError(t, err)
+	y, err := base64.RawURLEncoding.DecodeString(values[1].(string)) //nolint:forcetypeassert
+	require.NoError(t, err)
+	signature := values[2].([]byte) //nolint:forcetypeassert
+
+	// The signature is r||s, as expected by JWS and WebCrypto, rather than ASN.1.
+	key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	digest := sha256.Sum256([]byte("data"))
+	assert.True(t, ecdsa.Verify(key, digest[:],
+		new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])))
+}
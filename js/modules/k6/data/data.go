@@ -4,31 +4,42 @@ package data
 
 import (
 	"errors"
+	"fmt"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/dop251/goja"
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/fsext"
 )
 
 type (
 	// RootModule is the global module instance that will create module
 	// instances for each VU.
 	RootModule struct {
-		shared sharedArrays
+		shared  sharedArrays
+		sources dataSources
 	}
 
 	// Data represents an instance of the data module.
 	Data struct {
-		vu     modules.VU
-		shared *sharedArrays
+		vu      modules.VU
+		shared  *sharedArrays
+		sources *dataSources
 	}
 
 	sharedArrays struct {
 		data map[string]sharedArray
 		mu   sync.RWMutex
 	}
+
+	dataSources struct {
+		data map[string]*dataSource
+		mu   sync.Mutex
+	}
 )
 
 var (
@@ -42,6 +53,9 @@ func New() *RootModule {
 		shared: sharedArrays{
 			data: make(map[string]sharedArray),
 		},
+		sources: dataSources{
+			data: make(map[string]*dataSource),
+		},
 	}
 }
 
@@ -49,8 +63,9 @@ func New() *RootModule {
 // a new instance for each VU.
 func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 	return &Data{
-		vu:     vu,
-		shared: &rm.shared,
+		vu:      vu,
+		shared:  &rm.shared,
+		sources: &rm.sources,
 	}
 }
 
@@ -59,6 +74,7 @@ func (d *Data) Exports() modules.Exports {
 	return modules.Exports{
 		Named: map[string]interface{}{
 			"SharedArray": d.sharedArray,
+			"DataSource":  d.dataSource,
 		},
 	}
 }
@@ -133,3 +149,163 @@ func getShareArrayFromCall(rt *goja.Runtime, call goja.Callable) sharedArray {
 
 	return sharedArray{arr: arr}
 }
+
+// dataSource is a constructor returning a data source identified by the name, whose
+// records are read on demand from a CSV or JSON-lines file.
+//
+// Unlike SharedArray, the content of the file is never materialized: see [dataSource].
+func (d *Data) dataSource(call goja.ConstructorCall) *goja.Object {
+	rt := d.vu.Runtime()
+
+	if d.vu.State() != nil {
+		common.Throw(rt, errors.New("new DataSource must be called in the init context"))
+	}
+
+	name := call.Argument(0).String()
+	if name == "" {
+		common.Throw(rt, errors.New("empty name provided to DataSource's constructor"))
+	}
+
+	pathArg := call.Argument(1)
+	if common.IsNullish(pathArg) || pathArg.String() == "" {
+		common.Throw(rt, errors.New("a file path is expected as the second argument of DataSource's constructor"))
+	}
+	path := pathArg.String()
+
+	opts, err := newDataSourceOptions(rt, path, call.Argument(2))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	source, err := d.sources.get(name, func() (*dataSource, error) {
+		return openDataSource(d.vu.InitEnv(), path, opts)
+	})
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	return newWrappedDataSource(d.vu, source)
+}
+
+func (s *dataSources) get(name string, open func() (*dataSource, error)) (*dataSource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if source, ok := s.data[name]; ok {
+		return source, nil
+	}
+
+	source, err := open()
+	if err != nil {
+		return nil, err
+	}
+	s.data[name] = source
+
+	return source, nil
+}
+
+// openDataSource opens the file at the given path, relative to the entrypoint script,
+// and returns the data source reading its records.
+//
+// The file is read through the file system of the test, so it's part of the test's
+// archive, and the data source works the same way locally, in a distributed test, or
+// in the cloud. Locally, it's read directly from the disk and only copied to the
+// archive when one is made, as caching the whole file in memory would defeat the purpose.
+func openDataSource(initEnv *common.InitEnvironment, path string, opts dataSourceOptions) (*dataSource, error) {
+	path = fsext.Abs(initEnv.CWD.Path, path)
+
+	fs, ok := initEnv.FileSystems["file"]
+	if !ok {
+		return nil, errors.New("unable to access the file system")
+	}
+
+	var f fsext.File
+	var err error
+	if uncachedfs, ok := fs.(fsext.UncachedOpener); ok {
+		f, err = uncachedfs.OpenUncached(path)
+	} else {
+		f, err = fs.Open(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the DataSource's file %q: %w", path, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("unable to stat the DataSource's file %q: %w", path, err)
+	}
+	if info.IsDir() {
+		_ = f.Close()
+		return nil, fmt.Errorf("the DataSource's file %q is a directory", path)
+	}
+
+	// The file is kept open, as the records are read from it until the end of the test.
+	source, err := newDataSource(f, info.Size(), opts)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("unable to read the DataSource's file %q: %w", path, err)
+	}
+
+	return source, nil
+}
+
+// newDataSourceOptions returns the options of a data source, from the given object.
+//
+// The format defaults to the one matching the file's extension.
+func newDataSourceOptions(rt *goja.Runtime, path string, value goja.Value) (dataSourceOptions, error) {
+	opts := dataSourceOptions{
+		mode:      dataSourceModeSequential,
+		header:    true,
+		delimiter: ',',
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		opts.format = dataSourceFormatCSV
+	case ".jsonl", ".ndjson":
+		opts.format = dataSourceFormatJSONL
+	}
+
+	if !common.IsNullish(value) {
+		obj := value.ToObject(rt)
+
+		if v := obj.Get("format"); !common.IsNullish(v) {
+			opts.format = v.String()
+		}
+		if v := obj.Get("mode"); !common.IsNullish(v) {
+			opts.mode = v.String()
+		}
+		if v := obj.Get("loop"); v != nil {
+			opts.loop = v.ToBoolean()
+		}
+		if v := obj.Get("header"); !common.IsNullish(v) {
+			opts.header = v.ToBoolean()
+		}
+		if v := obj.Get("delimiter"); !common.IsNullish(v) {
+			delimiter := []rune(v.String())
+			if len(delimiter) != 1 {
+				return opts, fmt.Errorf("the DataSource's delimiter must be a single character, got %q", v.String())
+			}
+			opts.delimiter = delimiter[0]
+		}
+	}
+
+	switch opts.format {
+	case dataSourceFormatCSV, dataSourceFormatJSONL:
+	case "":
+		return opts, fmt.Errorf("unable to detect the format of %q, the format option must be set", path)
+	default:
+		return opts, fmt.Errorf("unsupported DataSource format %q, expected %q or %q",
+			opts.format, dataSourceFormatCSV, dataSourceFormatJSONL)
+	}
+
+	switch opts.mode {
+	case dataSourceModeSequential, dataSourceModePerVU, dataSourceModeRandom:
+	default:
+		return opts, fmt.Errorf("unsupported DataSource mode %q, expected %q, %q or %q",
+			opts.mode, dataSourceModeSequential, dataSourceModePerVU, dataSourceModeRandom)
+	}
+
+	return opts, nil
+}
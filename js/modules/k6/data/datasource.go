@@ -0,0 +1,413 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
+)
+
+// dataSourceIndexInterval is the number of records between two entries of the
+// sparse index of a data source. Reaching an arbitrary record takes reading at
+// most this many records from the closest indexed one.
+const dataSourceIndexInterval = 64
+
+// dataSourceReaderSize is the size of the buffer used to read the records.
+const dataSourceReaderSize = 64 * 1024
+
+const (
+	dataSourceFormatCSV   = "csv"
+	dataSourceFormatJSONL = "jsonl"
+
+	// dataSourceModeSequential hands out each record once, in order, to whichever VU asks
+	// for the next one: the cursor is shared by all the VUs of the instance.
+	dataSourceModeSequential = "sequential"
+	// dataSourceModePerVU splits the records in as many contiguous partitions as there
+	// are VUs, each VU reading its own partition in order.
+	dataSourceModePerVU = "per-vu"
+	// dataSourceModeRandom hands out a random record on each call.
+	dataSourceModeRandom = "random"
+)
+
+// dataSourceOptions holds the options of a data source.
+type dataSourceOptions struct {
+	format string
+	mode   string
+
+	// loop restarts from the first record once all of them have been read,
+	// instead of reporting the end of the data.
+	loop bool
+
+	// header is true when the first line of a CSV file holds the names of
+	// the columns, the records then being objects instead of arrays.
+	header    bool
+	delimiter rune
+}
+
+// dataSource is a CSV or JSON-lines file whose records are read on demand, and
+// shared by all the VUs.
+//
+// The records and their parsed values aren't held in memory: the file is scanned
+// once to count its records and build a sparse index of their offsets, then each
+// record is read from the file when it's requested. Locally, the file is read from
+// the disk, whereas an archived test holds it in memory, like all its files. The records are
+// partitioned between the instances of a distributed test according to their
+// execution segment, so that each instance reads a distinct slice of them.
+type dataSource struct {
+	opts dataSourceOptions
+
+	file io.ReaderAt
+	size int64
+
+	// count is the total number of records of the file.
+	count int64
+	// index holds the offset of every dataSourceIndexInterval-th record.
+	index []int64
+	// columns holds the names of the columns, when the CSV file has a header.
+	columns []string
+
+	// mu guards the reader, which is kept positioned on the record following the
+	// last one read, so reading the records in order is efficient.
+	mu        sync.Mutex
+	reader    recordReader
+	readerPos int64
+
+	// segment holds the records of the instance's execution segment, it's initialized
+	// on first use, as the segment is only known in the VU context.
+	segmentOnce sync.Once
+	segment     *dataSourceSegment
+
+	// cursor is the number of records read in the sequential mode.
+	cursor atomic.Int64
+
+	// partitions is the number of VUs the records are split between in the per-VU mode.
+	partitionsOnce sync.Once
+	partitions     int64
+}
+
+// dataSourceSegment maps the records of an instance's execution segment to the
+// records of the file, using the same striping as the iterations of the test.
+type dataSourceSegment struct {
+	count   int64
+	start   int64
+	lcd     int64
+	offsets []int64
+}
+
+// newDataSource scans the given file to build the index of its records.
+func newDataSource(file io.ReaderAt, size int64, opts dataSourceOptions) (*dataSource, error) {
+	ds := &dataSource{opts: opts, file: file, size: size, readerPos: -1}
+
+	reader := ds.newRecordReader(0)
+	for {
+		record, offset, err := reader.read()
+		if err != nil {
+			return nil, err
+		}
+		if record == nil {
+			break
+		}
+
+		if ds.opts.format == dataSourceFormatCSV && ds.opts.header && ds.columns == nil {
+			ds.columns = record
+			continue
+		}
+
+		if ds.count%dataSourceIndexInterval == 0 {
+			ds.index = append(ds.index, offset)
+		}
+		ds.count++
+	}
+
+	return ds, nil
+}
+
+// recordReader reads the records of a data source one after the other.
+type recordReader interface {
+	// read returns the next record, along with its offset in the file. The record is
+	// nil at the end of the data.
+	read() ([]string, int64, error)
+}
+
+// newRecordReader returns a reader of the records of the data source, starting at the
+// given offset of the file.
+func (ds *dataSource) newRecordReader(offset int64) recordReader {
+	reader := bufio.NewReaderSize(io.NewSectionReader(ds.file, offset, ds.size-offset), dataSourceReaderSize)
+
+	if ds.opts.format != dataSourceFormatCSV {
+		return &lineReader{reader: reader, offset: offset}
+	}
+
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma = ds.opts.delimiter
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+
+	return &csvRecordReader{reader: csvReader, offset: offset}
+}
+
+// lineReader reads the non-blank lines of a JSON-lines file, each record holding
+// a single line.
+type lineReader struct {
+	reader *bufio.Reader
+	offset int64
+}
+
+func (r *lineReader) read() ([]string, int64, error) {
+	for {
+		offset := r.offset
+		line, err := r.reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, offset, err
+		}
+		r.offset += int64(len(line))
+
+		// The last line may not end with a line ending, the end of the data then comes on the next call.
+		if trimmed := bytes.TrimRight(line, "\r\n"); len(bytes.TrimSpace(trimmed)) > 0 {
+			return []string{string(trimmed)}, offset, nil
+		}
+		if err != nil {
+			return nil, r.offset, nil
+		}
+	}
+}
+
+// csvRecordReader reads the records of a CSV file, whose quoted fields may span
+// several lines.
+type csvRecordReader struct {
+	reader *csv.Reader
+	offset int64
+}
+
+func (r *csvRecordReader) read() ([]string, int64, error) {
+	// The reader skips the blank lines, so the record may start after the offset.
+	// It doesn't matter, as reading from the offset skips them again.
+	offset := r.offset + r.reader.InputOffset()
+
+	record, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, offset, nil
+	}
+	if err != nil {
+		return nil, offset, fmt.Errorf("invalid CSV record: %w", err)
+	}
+
+	return record, offset, nil
+}
+
+// read returns the record at the given index of the file.
+func (ds *dataSource) read(record int64) ([]string, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if ds.reader == nil || ds.readerPos > record || record-ds.readerPos >= dataSourceIndexInterval {
+		// The first indexed record is the first one after the header, if any. So is the
+		// offset, and there is no need to skip the header again.
+		offset := ds.index[record/dataSourceIndexInterval]
+		ds.reader = ds.newRecordReader(offset)
+		ds.readerPos = record / dataSourceIndexInterval * dataSourceIndexInterval
+	}
+
+	for {
+		fields, _, err := ds.reader.read()
+		if err != nil {
+			ds.reader = nil
+			return nil, err
+		}
+		if fields == nil {
+			ds.reader = nil
+			return nil, fmt.Errorf("record %d is beyond the end of the data", record)
+		}
+
+		ds.readerPos++
+		if ds.readerPos-1 == record {
+			return fields, nil
+		}
+	}
+}
+
+// initSegment initializes the records of the instance's execution segment.
+func (ds *dataSource) initSegment(et *lib.ExecutionTuple) {
+	ds.segmentOnce.Do(func() {
+		if et == nil {
+			ds.segment = &dataSourceSegment{count: ds.count, lcd: 1, offsets: []int64{1}}
+			return
+		}
+
+		start, offsets, lcd := et.GetStripedOffsets()
+		ds.segment = &dataSourceSegment{
+			count:   et.ScaleInt64(ds.count),
+			start:   start,
+			lcd:     lcd,
+			offsets: offsets,
+		}
+	})
+}
+
+// fileRecord returns the index, within the file, of the given record of the segment.
+func (s *dataSourceSegment) fileRecord(record int64) int64 {
+	cycles, within := record/int64(len(s.offsets)), record%int64(len(s.offsets))
+
+	fileRecord := cycles*s.lcd + s.start
+	for _, offset := range s.offsets[:within] {
+		fileRecord += offset
+	}
+
+	return fileRecord
+}
+
+// nextIndex returns the index, within the instance's segment, of the next record
+// to be read for the given cursor, or -1 when all of them have been read.
+//
+// The cursor is the number of records read so far, within a partition of the given
+// size starting at the given index of the segment.
+func (ds *dataSource) nextIndex(cursor, partitionStart, partitionSize int64) int64 {
+	if partitionSize <= 0 {
+		return -1
+	}
+
+	if cursor >= partitionSize {
+		if !ds.opts.loop {
+			return -1
+		}
+		cursor %= partitionSize
+	}
+
+	return partitionStart + cursor
+}
+
+// next returns the next record, for the given VU and according to
+// the data source's mode, or nil when all the records have been read.
+//
+// The cursor is the VU's own cursor, only used in the per-VU mode.
+func (ds *dataSource) next(vuID uint64, vuCursor *int64, vus func() int64) ([]string, error) {
+	segment := ds.segment
+
+	var index int64
+	switch ds.opts.mode {
+	case dataSourceModeRandom:
+		if segment.count == 0 {
+			return nil, nil
+		}
+		index = rand.Int63n(segment.count) //nolint:gosec // it's not used for security purposes
+	case dataSourceModePerVU:
+		ds.partitionsOnce.Do(func() {
+			ds.partitions = vus()
+			if ds.partitions < 1 {
+				ds.partitions = 1
+			}
+		})
+
+		// The VU IDs start at 1, and VUs beyond the initial ones share the partitions.
+		partition := (int64(vuID) + ds.partitions - 1) % ds.partitions //nolint:gosec
+		start := segment.count * partition / ds.partitions
+		end := segment.count * (partition + 1) / ds.partitions
+
+		index = ds.nextIndex(*vuCursor, start, end-start)
+		*vuCursor++
+	default:
+		index = ds.nextIndex(ds.cursor.Add(1)-1, 0, segment.count)
+	}
+
+	if index < 0 {
+		return nil, nil
+	}
+
+	return ds.read(segment.fileRecord(index))
+}
+
+// wrappedDataSource is the view of a data source of a single VU.
+type wrappedDataSource struct {
+	*dataSource
+
+	vu    modules.VU
+	parse goja.Callable
+
+	// cursor is the number of records read by the VU in the per-VU mode.
+	cursor int64
+}
+
+func newWrappedDataSource(vu modules.VU, source *dataSource) *goja.Object {
+	rt := vu.Runtime()
+	parse, _ := goja.AssertFunction(rt.GlobalObject().Get("JSON").ToObject(rt).Get("parse"))
+
+	w := &wrappedDataSource{dataSource: source, vu: vu, parse: parse}
+
+	obj := rt.NewObject()
+	if err := obj.Set("next", w.Next); err != nil {
+		common.Throw(rt, err)
+	}
+
+	return obj
+}
+
+// Next returns the next record, according to the data source's mode, or null once all
+// the records have been read. The CSV records are objects keyed by the names of the
+// columns when the file has a header, and arrays otherwise.
+func (w *wrappedDataSource) Next() goja.Value {
+	rt := w.vu.Runtime()
+
+	state := w.vu.State()
+	if state == nil {
+		common.Throw(rt, errors.New("DataSource's next() can only be called in the VU context"))
+	}
+
+	vus := func() int64 { return 1 }
+	if es := lib.GetExecutionState(w.vu.Context()); es != nil {
+		w.initSegment(es.ExecutionTuple)
+		vus = es.GetInitializedVUsCount
+	} else {
+		et, err := lib.NewExecutionTuple(state.Options.ExecutionSegment, state.Options.ExecutionSegmentSequence)
+		if err != nil {
+			common.Throw(rt, err)
+		}
+		w.initSegment(et)
+	}
+
+	record, err := w.next(state.VUID, &w.cursor, vus)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	if record == nil {
+		return goja.Null()
+	}
+
+	if w.opts.format == dataSourceFormatJSONL {
+		val, err := w.parse(goja.Undefined(), rt.ToValue(record[0]))
+		if err != nil {
+			common.Throw(rt, err)
+		}
+		return val
+	}
+
+	if w.columns == nil {
+		values := make([]any, len(record))
+		for i, field := range record {
+			values[i] = field
+		}
+		return rt.NewArray(values...)
+	}
+
+	obj := rt.NewObject()
+	for i, column := range w.columns {
+		value := ""
+		if i < len(record) {
+			value = record[i]
+		}
+		if err := obj.Set(column, value); err != nil {
+			common.Throw(rt, err)
+		}
+	}
+
+	return obj
+}
@@ -0,0 +1,395 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+)
+
+const usersCSV = "name,email\r\n" +
+	"alice,alice@example.com\r\n" +
+	"\r\n" +
+	"bob,bob@example.com\r\n" +
+	"\"carol, jr\",carol@example.com\r\n"
+
+// newDataSourceRuntime returns a runtime whose file system holds the given files.
+func newDataSourceRuntime(t testing.TB, files map[string]string) *modulestest.Runtime {
+	t.Helper()
+
+	runtime, err := newConfiguredRuntime(t)
+	require.NoError(t, err)
+
+	fs := fsext.NewMemMapFs()
+	for name, content := range files {
+		require.NoError(t, fsext.WriteFile(fs, "/"+name, []byte(content), 0o644))
+	}
+	runtime.VU.InitEnvField.FileSystems = map[string]fsext.Fs{"file": fs}
+	runtime.VU.InitEnvField.CWD = &url.URL{Scheme: "file", Path: "/"}
+
+	return runtime
+}
+
+func TestDataSource(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		files  map[string]string
+		init   string
+		vuCode string
+	}{
+		"CSV with a header": {
+			files: map[string]string{"users.csv": usersCSV},
+			init:  `var users = new data.DataSource("users", "users.csv");`,
+			vuCode: `
+				var names = [];
+				for (var user = users.next(); user !== null; user = users.next()) {
+					names.push(user.name + "=" + user.email);
+				}
+				if (names.join("|") !== "alice=alice@example.com|bob=bob@example.com|carol, jr=carol@example.com") {
+					throw new Error("unexpected records " + names.join("|"));
+				}
+			`,
+		},
+		"CSV without a header and with a custom delimiter": {
+			files: map[string]string{"users.txt": "alice;1\nbob;2\n"},
+			init:  `var users = new data.DataSource("users", "users.txt", { format: "csv", header: false, delimiter: ";" });`,
+			vuCode: `
+				var first = users.next();
+				if (!Array.isArray(first) || first.join() !== "alice,1") {
+					throw new Error("unexpected record " + JSON.stringify(first));
+				}
+			`,
+		},
+		"CSV with multi-line fields": {
+			files: map[string]string{
+				"users.csv": "name,bio\n\"alice\",\"line 1\nline 2\"\nbob,\"a \"\"quoted\"\"\r\n\r\nbio\"\n",
+			},
+			init: `var users = new data.DataSource("users", "users.csv");`,
+			vuCode: `
+				var first = users.next(), second = users.next();
+				if (first.name !== "alice" || first.bio !== "line 1\nline 2" || second.name !== "bob" ||
+					second.bio !== 'a "quoted"\n\nbio' || users.next() !== null) {
+					throw new Error("unexpected records " + JSON.stringify([first, second]));
+				}
+			`,
+		},
+		"JSON lines": {
+			files: map[string]string{"users.jsonl": "{\"id\": 1}\n\n{\"id\": 2, \"tags\": [\"a\"]}"},
+			init:  `var users = new data.DataSource("users", "users.jsonl");`,
+			vuCode: `
+				var first = users.next(), second = users.next();
+				if (first.id !== 1 || second.id !== 2 || second.tags[0] !== "a" || users.next() !== null) {
+					throw new Error("unexpected records " + JSON.stringify([first, second]));
+				}
+			`,
+		},
+		"looping": {
+			files: map[string]string{"users.csv": usersCSV},
+			init:  `var users = new data.DataSource("users", "users.csv", { loop: true });`,
+			vuCode: `
+				var names = [];
+				for (var i = 0; i < 5; i++) {
+					names.push(users.next().name);
+				}
+				if (names.join() !== "alice,bob,carol, jr,alice,bob") {
+					throw new Error("unexpected records " + names.join());
+				}
+			`,
+		},
+		"random": {
+			files: map[string]string{"users.csv": usersCSV},
+			init:  `var users = new data.DataSource("users", "users.csv", { mode: "random" });`,
+			vuCode: `
+				for (var i = 0; i < 20; i++) {
+					var user = users.next();
+					if (["alice", "bob", "carol, jr"].indexOf(user.name) < 0) {
+						throw new Error("unexpected record " + JSON.stringify(user));
+					}
+				}
+			`,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime := newDataSourceRuntime(t, tc.files)
+			_, err := runtime.VU.Runtime().RunString(tc.init)
+			require.NoError(t, err)
+
+			runtime.MoveToVUContext(&lib.State{VUID: 1})
+			_, err = runtime.VU.Runtime().RunString(tc.vuCode)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDataSourceSharedCursor(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{"users.csv": usersCSV}
+	const init = `var users = new data.DataSource("users", "users.csv");`
+
+	first := newDataSourceRuntime(t, files)
+	_, err := first.VU.Runtime().RunString(init)
+	require.NoError(t, err)
+
+	// The second VU gets the data source created by the first one, even though it
+	// doesn't have access to the file.
+	second, err := configuredRuntimeFromAnother(t, first)
+	require.NoError(t, err)
+	_, err = second.VU.Runtime().RunString(init)
+	require.NoError(t, err)
+
+	first.MoveToVUContext(&lib.State{VUID: 1})
+	second.MoveToVUContext(&lib.State{VUID: 2})
+
+	var names []string
+	for i, runtime := range []*modulestest.Runtime{first, second, first, second} {
+		v, err := runtime.VU.Runtime().RunString(`var user = users.next(); user === null ? null : user.name`)
+		require.NoError(t, err)
+
+		if i < 3 {
+			names = append(names, v.String())
+		} else {
+			assert.Nil(t, v.Export(), "all the records should have been read")
+		}
+	}
+
+	assert.Equal(t, []string{"alice", "bob", "carol, jr"}, names)
+}
+
+func TestDataSourceNotCached(t *testing.T) {
+	t.Parallel()
+
+	runtime, err := newConfiguredRuntime(t)
+	require.NoError(t, err)
+
+	base := fsext.NewMemMapFs()
+	require.NoError(t, fsext.WriteFile(base, "/users.csv", []byte(usersCSV), 0o644))
+	fs := fsext.NewCacheOnReadFs(base, fsext.NewMemMapFs(), 0)
+	runtime.VU.InitEnvField.FileSystems = map[string]fsext.Fs{"file": fs}
+	runtime.VU.InitEnvField.CWD = &url.URL{Scheme: "file", Path: "/"}
+
+	_, err = runtime.VU.Runtime().RunString(`var users = new data.DataSource("users", "users.csv");`)
+	require.NoError(t, err)
+
+	runtime.MoveToVUContext(&lib.State{VUID: 1})
+	v, err := runtime.VU.Runtime().RunString(`users.next().name`)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", v.String())
+
+	// The file is read from the base file system, it's only copied to the cache
+	// layer when an archive is made.
+	cache := fs.(fsext.CacheLayerGetter).GetCachingFs()
+	exists, err := fsext.Exists(cache, "/users.csv")
+	require.NoError(t, err)
+	assert.False(t, exists, "the file shouldn't be cached")
+
+	require.NoError(t, fs.(fsext.UncachedOpener).CacheUncached())
+	content, err := fsext.ReadFile(cache, "/users.csv")
+	require.NoError(t, err)
+	assert.Equal(t, usersCSV, string(content))
+}
+
+func TestDataSourceConstructorExceptions(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		code, err string
+	}{
+		"empty name": {
+			code: `new data.DataSource("", "users.csv")`,
+			err:  "empty name provided to DataSource's constructor",
+		},
+		"missing path": {
+			code: `new data.DataSource("users")`,
+			err:  "a file path is expected",
+		},
+		"missing file": {
+			code: `new data.DataSource("users", "missing.csv")`,
+			err:  "unable to open the DataSource's file",
+		},
+		"unknown format": {
+			code: `new data.DataSource("users", "users.txt")`,
+			err:  "the format option must be set",
+		},
+		"unsupported format": {
+			code: `new data.DataSource("users", "users.csv", { format: "xml" })`,
+			err:  `unsupported DataSource format "xml"`,
+		},
+		"unsupported mode": {
+			code: `new data.DataSource("users", "users.csv", { mode: "shuffled" })`,
+			err:  `unsupported DataSource mode "shuffled"`,
+		},
+		"invalid delimiter": {
+			code: `new data.DataSource("users", "users.csv", { delimiter: ";;" })`,
+			err:  "delimiter must be a single character",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime := newDataSourceRuntime(t, map[string]string{"users.csv": usersCSV, "users.txt": usersCSV})
+			_, err := runtime.VU.Runtime().RunString(tc.code)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+
+	t.Run("VU context", func(t *testing.T) {
+		t.Parallel()
+
+		runtime := newDataSourceRuntime(t, map[string]string{"users.csv": usersCSV})
+		runtime.MoveToVUContext(&lib.State{})
+
+		_, err := runtime.VU.Runtime().RunString(`new data.DataSource("users", "users.csv")`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "new DataSource must be called in the init context")
+	})
+}
+
+// newTestDataSource returns a data source of the given number of JSON lines,
+// holding their own index.
+func newTestDataSource(t *testing.T, records int, mode string, loop bool) *dataSource {
+	t.Helper()
+
+	var buf bytes.Buffer
+	for i := 0; i < records; i++ {
+		fmt.Fprintf(&buf, "%d\n", i)
+	}
+
+	source, err := newDataSource(
+		bytes.NewReader(buf.Bytes()), int64(buf.Len()),
+		dataSourceOptions{format: dataSourceFormatJSONL, mode: mode, loop: loop},
+	)
+	require.NoError(t, err)
+
+	return source
+}
+
+func TestDataSourceRead(t *testing.T) {
+	t.Parallel()
+
+	source := newTestDataSource(t, 1000, dataSourceModeSequential, false)
+	require.EqualValues(t, 1000, source.count)
+	require.Len(t, source.index, (1000+dataSourceIndexInterval-1)/dataSourceIndexInterval)
+
+	// Records are read both in order, and out of order.
+	for _, record := range []int64{0, 1, 2, 999, 500, 63, 64, 65, 0, 998, 999} {
+		got, err := source.read(record)
+		require.NoError(t, err)
+		assert.Equal(t, []string{fmt.Sprint(record)}, got)
+	}
+
+	_, err := source.read(1000)
+	assert.ErrorContains(t, err, "beyond the end of the data")
+}
+
+func TestDataSourceReadMultiLineCSV(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	buf.WriteString("id,text\n")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&buf, "%d,\"record\n%d\"\n", i, i)
+	}
+
+	source, err := newDataSource(
+		bytes.NewReader(buf.Bytes()), int64(buf.Len()),
+		dataSourceOptions{format: dataSourceFormatCSV, header: true, delimiter: ','},
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 200, source.count)
+	assert.Equal(t, []string{"id", "text"}, source.columns)
+
+	for _, record := range []int64{0, 1, 199, 64, 63, 128, 130, 5} {
+		got, err := source.read(record)
+		require.NoError(t, err)
+		assert.Equal(t, []string{fmt.Sprint(record), fmt.Sprintf("record\n%d", record)}, got)
+	}
+}
+
+func TestDataSourceExecutionSegments(t *testing.T) {
+	t.Parallel()
+
+	seq, err := lib.NewExecutionSegmentSequenceFromString("0,1/3,1/2,1")
+	require.NoError(t, err)
+
+	var all []string
+	for _, segment := range seq {
+		et, err := lib.NewExecutionTuple(segment, &seq)
+		require.NoError(t, err)
+
+		source := newTestDataSource(t, 100, dataSourceModeSequential, false)
+		source.initSegment(et)
+
+		var records []string
+		for {
+			record, err := source.next(1, nil, nil)
+			require.NoError(t, err)
+			if record == nil {
+				break
+			}
+			records = append(records, record[0])
+		}
+
+		assert.Len(t, records, int(et.ScaleInt64(100)), segment.String())
+		all = append(all, records...)
+	}
+
+	// The instances read distinct records, all of them being read once.
+	expected := make([]string, 100)
+	for i := range expected {
+		expected[i] = fmt.Sprint(i)
+	}
+	assert.ElementsMatch(t, expected, all)
+}
+
+func TestDataSourcePerVU(t *testing.T) {
+	t.Parallel()
+
+	source := newTestDataSource(t, 10, dataSourceModePerVU, false)
+	source.initSegment(nil)
+
+	vus := func() int64 { return 3 }
+	cursors := make([]int64, 4)
+
+	read := func(vuID uint64) string {
+		record, err := source.next(vuID, &cursors[vuID], vus)
+		require.NoError(t, err)
+		if record == nil {
+			return "end"
+		}
+		return record[0]
+	}
+
+	var got []string
+	for vuID := uint64(1); vuID <= 3; vuID++ {
+		var records []string
+		for i := 0; i < 5; i++ {
+			records = append(records, read(vuID))
+		}
+		got = append(got, strings.Join(records, ","))
+	}
+
+	assert.Equal(t, []string{
+		"0,1,2,end,end",
+		"3,4,5,end,end",
+		"6,7,8,9,end",
+	}, got)
+}
@@ -122,6 +122,17 @@ func (mi *ModuleInstance) newScenarioInfo() (*goja.Object, error) {
 
 			return vuState.GetScenarioGlobalVUIter()
 		},
+		"record": func() interface{} {
+			ss := getScenarioState()
+			if ss.GetRecord == nil || vuState.GetScenarioLocalVUIter == nil {
+				return nil
+			}
+			record, ok := ss.GetRecord(vuState.GetScenarioLocalVUIter())
+			if !ok {
+				return nil
+			}
+			return record
+		},
 	}
 
 	return newInfoObj(rt, si)
@@ -37,6 +37,9 @@ const (
 
 	// EOFError is emitted when the end of a file has been reached.
 	EOFError
+
+	// AlreadyExistsError is emitted when a resource to be created already exists.
+	AlreadyExistsError
 )
 
 // fsError represents a custom error object emitted by the fs module.
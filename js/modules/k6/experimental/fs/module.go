@@ -5,13 +5,17 @@
 package fs
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"reflect"
+	"sync"
 
 	"github.com/dop251/goja"
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/js/modules/k6/experimental/streams"
 	"go.k6.io/k6/js/promises"
 	"go.k6.io/k6/lib/fsext"
 )
@@ -21,12 +25,17 @@ type (
 	// module for each VU.
 	RootModule struct {
 		cache *cache
+
+		// writer is shared by all the VUs, it's nil when writing files is disabled.
+		writer     *writer
+		writerOnce sync.Once
 	}
 
 	// ModuleInstance represents an instance of the fs module for a single VU.
 	ModuleInstance struct {
-		vu    modules.VU
-		cache *cache
+		vu     modules.VU
+		cache  *cache
+		writer *writer
 	}
 )
 
@@ -45,7 +54,18 @@ func New() *RootModule {
 // NewModuleInstance implements the modules.Module interface and returns a new
 // instance of our module for the given VU.
 func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
-	return &ModuleInstance{vu: vu, cache: rm.cache}
+	// Writing files is only possible when an output directory has been configured,
+	// which is never the case when running an archive, locally or in the cloud.
+	rm.writerOnce.Do(func() {
+		initEnv := vu.InitEnv()
+		if initEnv == nil || initEnv.TestPreInitState == nil || initEnv.OutputFS == nil {
+			return
+		}
+
+		rm.writer = newWriter(initEnv.OutputFS)
+	})
+
+	return &ModuleInstance{vu: vu, cache: rm.cache, writer: rm.writer}
 }
 
 // Exports implements the modules.Module interface and returns the exports of
@@ -53,7 +73,12 @@ func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 func (mi *ModuleInstance) Exports() modules.Exports {
 	return modules.Exports{
 		Named: map[string]any{
-			"open": mi.Open,
+			"open":       mi.Open,
+			"readdir":    mi.Readdir,
+			"glob":       mi.Glob,
+			"writeFile":  mi.WriteFile,
+			"appendFile": mi.AppendFile,
+			"mkdir":      mi.Mkdir,
 			"SeekMode": map[string]any{
 				"Start":   SeekModeStart,
 				"Current": SeekModeCurrent,
@@ -146,6 +171,237 @@ func (mi *ModuleInstance) openImpl(path string) (*File, error) {
 	}, nil
 }
 
+// Readdir returns a promise that will resolve to the sorted names of the entries
+// of the directory at the given path.
+//
+// Like [ModuleInstance.Open], it's only allowed in the init context, and the path
+// is resolved relative to the entrypoint script.
+func (mi *ModuleInstance) Readdir(path goja.Value) *goja.Promise {
+	promise, resolve, reject := promises.New(mi.vu)
+
+	pathStr, err := mi.initContextPath("readdir", path)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	go func() {
+		names, err := mi.readdirImpl(pathStr)
+		if err != nil {
+			reject(err)
+			return
+		}
+
+		resolve(names)
+	}()
+
+	return promise
+}
+
+func (mi *ModuleInstance) readdirImpl(path string) ([]string, error) {
+	initEnv := mi.vu.InitEnv()
+	path = fsext.Abs(initEnv.CWD.Path, path)
+
+	fs, ok := initEnv.FileSystems["file"]
+	if !ok {
+		return nil, errors.New("readdir() failed; reason: unable to access the file system")
+	}
+
+	if exists, err := fsext.Exists(fs, path); err != nil {
+		return nil, fmt.Errorf("readdir() failed, unable to verify if %q exists; reason: %w", path, err)
+	} else if !exists {
+		return nil, newFsError(NotFoundError, fmt.Sprintf("no such file or directory %q", path))
+	}
+
+	if isDir, err := fsext.IsDir(fs, path); err != nil {
+		return nil, fmt.Errorf("readdir() failed, unable to verify if %q is a directory; reason: %w", path, err)
+	} else if !isDir {
+		return nil, newFsError(InvalidResourceError, fmt.Sprintf("cannot read %q: not a directory", path))
+	}
+
+	entries, err := fsext.ReadDir(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("readdir() failed, unable to read the directory %q; reason: %w", path, err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	return names, nil
+}
+
+// Glob returns a promise that will resolve to the sorted paths of the files matching
+// the given pattern, as supported by [filepath.Match].
+//
+// Like [ModuleInstance.Open], it's only allowed in the init context. Relative patterns
+// are resolved relative to the entrypoint script, and so are the resulting paths, so
+// they can be passed to [ModuleInstance.Open] as is.
+func (mi *ModuleInstance) Glob(pattern goja.Value) *goja.Promise {
+	promise, resolve, reject := promises.New(mi.vu)
+
+	patternStr, err := mi.initContextPath("glob", pattern)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	go func() {
+		matches, err := mi.globImpl(patternStr)
+		if err != nil {
+			reject(err)
+			return
+		}
+
+		resolve(matches)
+	}()
+
+	return promise
+}
+
+func (mi *ModuleInstance) globImpl(pattern string) ([]string, error) {
+	initEnv := mi.vu.InitEnv()
+	root := fsext.Abs(initEnv.CWD.Path, ".")
+	absPattern := fsext.Abs(root, pattern)
+	isAbs := pattern[0] == '/' || pattern[0] == '\\' || filepath.IsAbs(pattern)
+
+	fs, ok := initEnv.FileSystems["file"]
+	if !ok {
+		return nil, errors.New("glob() failed; reason: unable to access the file system")
+	}
+
+	matches, err := fsext.Glob(fs, absPattern)
+	if errors.Is(err, filepath.ErrBadPattern) {
+		return nil, newFsError(TypeError, fmt.Sprintf("glob() failed; reason: invalid pattern %q", pattern))
+	} else if err != nil {
+		return nil, fmt.Errorf("glob() failed, unable to match %q; reason: %w", pattern, err)
+	}
+
+	if isAbs {
+		return matches, nil
+	}
+
+	for i, match := range matches {
+		if rel, err := filepath.Rel(root, match); err == nil {
+			matches[i] = rel
+		}
+	}
+
+	return matches, nil
+}
+
+// initContextPath returns the path held by the given value, ensuring the
+// calling method is used in the init context.
+func (mi *ModuleInstance) initContextPath(method string, path goja.Value) (string, error) {
+	if mi.vu.State() != nil {
+		return "", newFsError(
+			ForbiddenError,
+			method+"() failed; reason: it is allowed only in the Init context",
+		)
+	}
+
+	return exportPath(method, path)
+}
+
+// WriteFile writes the given data to the file at the given path of the output
+// directory, replacing its content if it already exists.
+//
+// The data can be a string, an ArrayBuffer, or a view of an ArrayBuffer such
+// as a Uint8Array. The returned promise resolves once the data has been written.
+func (mi *ModuleInstance) WriteFile(path goja.Value, data goja.Value) *goja.Promise {
+	return mi.write("writeFile", path, data, false)
+}
+
+// AppendFile appends the given data to the file at the given path of the
+// output directory, creating the file if it doesn't exist.
+//
+// The data can be a string, an ArrayBuffer, or a view of an ArrayBuffer such
+// as a Uint8Array. The returned promise resolves once the data has been written.
+func (mi *ModuleInstance) AppendFile(path goja.Value, data goja.Value) *goja.Promise {
+	return mi.write("appendFile", path, data, true)
+}
+
+func (mi *ModuleInstance) write(method string, path goja.Value, data goja.Value, appendMode bool) *goja.Promise {
+	promise, resolve, reject := promises.New(mi.vu)
+
+	pathStr, err := mi.outputPath(method, path)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	dataBytes, err := exportData(mi.vu.Runtime(), data)
+	if err != nil {
+		reject(newFsError(TypeError, method+"() failed; reason: the data argument "+err.Error()))
+		return promise
+	}
+
+	go func() {
+		if err := mi.writer.writeFile(pathStr, dataBytes, appendMode); err != nil {
+			reject(err)
+			return
+		}
+
+		resolve(goja.Undefined())
+	}()
+
+	return promise
+}
+
+// Mkdir creates a directory at the given path of the output directory.
+//
+// When the recursive option is set to true, the missing parent directories are
+// created too, and the directory already existing isn't an error.
+func (mi *ModuleInstance) Mkdir(path goja.Value, options *goja.Object) *goja.Promise {
+	promise, resolve, reject := promises.New(mi.vu)
+
+	pathStr, err := mi.outputPath("mkdir", path)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	recursive := false
+	if options != nil {
+		if v := options.Get("recursive"); v != nil {
+			recursive = v.ToBoolean()
+		}
+	}
+
+	go func() {
+		if err := mi.writer.mkdir(pathStr, recursive); err != nil {
+			reject(err)
+			return
+		}
+
+		resolve(goja.Undefined())
+	}()
+
+	return promise
+}
+
+// outputPath returns the path held by the given value, ensuring the calling
+// method, which writes to the output directory, can be used.
+func (mi *ModuleInstance) outputPath(method string, path goja.Value) (string, error) {
+	if mi.vu.State() == nil {
+		return "", newFsError(
+			ForbiddenError,
+			method+"() failed; reason: writing files is allowed only in the VU context",
+		)
+	}
+
+	if mi.writer == nil {
+		return "", newFsError(
+			ForbiddenError,
+			method+"() failed; reason: writing files requires an output directory, "+
+				"set with the --fs-output-dir option, and isn't supported when running an archive",
+		)
+	}
+
+	return exportPath(method, path)
+}
+
 // File represents a file and exposes methods to interact with it.
 //
 // It is a wrapper around the [file] struct, which is meant to be directly
@@ -252,6 +508,15 @@ func (f *File) Read(into goja.Value) *goja.Promise {
 	return promise
 }
 
+// Readable returns a readable byte stream, as exposed by the k6/experimental/streams
+// module, of the file's content.
+//
+// The stream always starts from the beginning of the file, and reading from it
+// doesn't move the file's offset.
+func (f *File) Readable() *goja.Object {
+	return streams.NewReadableStreamFromReader(f.vu, bytes.NewReader(f.file.data))
+}
+
 // Seek seeks to the given `offset` in the file, under the given `whence` mode.
 //
 // The returned promise resolves to the new `offset` (position) within the file, which
@@ -307,6 +572,69 @@ func isUint8Array(rt *goja.Runtime, o *goja.Object) bool {
 	return true
 }
 
+func exportPath(method string, path goja.Value) (string, error) {
+	if common.IsNullish(path) {
+		return "", newFsError(TypeError, method+"() failed; reason: path cannot be null or undefined")
+	}
+
+	pathStr := path.String()
+	if pathStr == "" {
+		return "", newFsError(TypeError, method+"() failed; reason: path cannot be empty")
+	}
+
+	return pathStr, nil
+}
+
+// exportData returns a copy of the bytes held by the given value, which can be a
+// string, an ArrayBuffer, or a view of an ArrayBuffer.
+//
+// The bytes are copied, so they can be used outside of the event loop without
+// racing with the script modifying the buffer.
+func exportData(rt *goja.Runtime, v goja.Value) ([]byte, error) {
+	if common.IsNullish(v) {
+		return nil, errors.New("cannot be null or undefined")
+	}
+
+	switch data := v.Export().(type) {
+	case string:
+		return []byte(data), nil
+	case goja.ArrayBuffer:
+		return append([]byte(nil), data.Bytes()...), nil
+	}
+
+	if !isArrayBufferView(rt, v) {
+		return nil, errors.New("must be a string, an ArrayBuffer or a view of an ArrayBuffer")
+	}
+
+	// The properties of the view can still be shadowed by the script, so they are checked.
+	obj := v.ToObject(rt)
+	ab, ok := obj.Get("buffer").Export().(goja.ArrayBuffer)
+	if !ok {
+		return nil, errors.New("must be a view of an ArrayBuffer")
+	}
+
+	buf := ab.Bytes()
+	offset := obj.Get("byteOffset").ToInteger()
+	length := obj.Get("byteLength").ToInteger()
+	if offset < 0 || length < 0 || offset > int64(len(buf)) || length > int64(len(buf))-offset {
+		return nil, errors.New("is a view out of the bounds of its ArrayBuffer")
+	}
+
+	return append([]byte(nil), buf[offset:offset+length]...), nil
+}
+
+// isArrayBufferView returns true if the given value is a typed array or a DataView,
+// as reported by ArrayBuffer.isView.
+func isArrayBufferView(rt *goja.Runtime, v goja.Value) bool {
+	isView, ok := goja.AssertFunction(rt.Get("ArrayBuffer").ToObject(rt).Get("isView"))
+	if !ok {
+		return false
+	}
+
+	res, err := isView(goja.Undefined(), v)
+	return err == nil && res.ToBoolean()
+}
+
 func exportInt(v goja.Value) (int64, error) {
 	if common.IsNullish(v) {
 		return 0, errors.New("cannot be null or undefined")
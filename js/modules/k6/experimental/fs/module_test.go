@@ -595,6 +595,349 @@ func TestFile(t *testing.T) {
 
 		assert.NoError(t, err)
 	})
+
+	t.Run("readable method should stream the file's content", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		testFilePath := fsext.FilePathSeparator + testFileName
+		fs := newTestFs(t, func(fs fsext.Fs) error {
+			return fsext.WriteFile(fs, testFilePath, []byte("Bonjour, le monde"), 0o644)
+		})
+		runtime.VU.InitEnvField.FileSystems["file"] = fs
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const reader = file.readable().getReader();
+
+			let content = '';
+			while (true) {
+				const { done, value } = await reader.read();
+				if (done) {
+					break;
+				}
+
+				content += String.fromCharCode(...value);
+			}
+
+			if (content !== 'Bonjour, le monde') {
+				throw 'unexpected file content ' + content + '; expected \'Bonjour, le monde\'';
+			}
+
+			// Reading the stream doesn't move the file's offset.
+			const buffer = new Uint8Array(7);
+			const bytesRead = await file.read(buffer);
+			if (bytesRead !== 7 || String.fromCharCode(...buffer) !== 'Bonjour') {
+				throw 'unexpected read of ' + bytesRead + ' bytes: ' + buffer;
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestReaddir(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reading an existing directory should succeed", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		runtime.VU.InitEnvField.FileSystems["file"] = newTestFs(t, func(fs fsext.Fs) error {
+			for _, name := range []string{"b.json", "a.json", "sub/c.json"} {
+				if err := fsext.WriteFile(fs, "/fixtures/"+name, []byte("{}"), 0o644); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			const names = await fs.readdir('fixtures');
+			if (names.join() !== 'a.json,b.json,sub') {
+				throw 'unexpected entries ' + names;
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("reading a missing directory or a file should fail", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		runtime.VU.InitEnvField.FileSystems["file"] = newTestFs(t, func(fs fsext.Fs) error {
+			return fsext.WriteFile(fs, fsext.FilePathSeparator+testFileName, []byte("Bonjour"), 0o644)
+		})
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			try {
+				await fs.readdir('missing');
+				throw 'unexpected promise resolution';
+			} catch (err) {
+				if (err.name !== 'NotFoundError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+
+			try {
+				await fs.readdir('bonjour.txt');
+				throw 'unexpected promise resolution';
+			} catch (err) {
+				if (err.name !== 'InvalidResourceError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("reading a directory in VU context should fail", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		runtime.MoveToVUContext(&lib.State{})
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			try {
+				await fs.readdir('.');
+				throw 'unexpected promise resolution';
+			} catch (err) {
+				if (err.name !== 'ForbiddenError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestGlob(t *testing.T) {
+	t.Parallel()
+
+	runtime, err := newConfiguredRuntime(t)
+	require.NoError(t, err)
+
+	runtime.VU.InitEnvField.FileSystems["file"] = newTestFs(t, func(fs fsext.Fs) error {
+		for _, name := range []string{"b.json", "a.json", "c.csv"} {
+			if err := fsext.WriteFile(fs, "/scripts/fixtures/"+name, []byte("{}"), 0o644); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	runtime.VU.InitEnvField.CWD = &url.URL{Scheme: "file", Path: "/scripts/"}
+
+	_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+		const relative = await fs.glob('fixtures/*.json');
+		if (relative.join() !== 'fixtures/a.json,fixtures/b.json') {
+			throw 'unexpected relative matches ' + relative;
+		}
+
+		const absolute = await fs.glob('/scripts/fixtures/*.csv');
+		if (absolute.join() !== '/scripts/fixtures/c.csv') {
+			throw 'unexpected absolute matches ' + absolute;
+		}
+
+		const file = await fs.open(relative[0]);
+		if (file.path !== '/scripts/fixtures/a.json') {
+			throw 'unexpected file path ' + file.path;
+		}
+
+		try {
+			await fs.glob('fixtures/[');
+			throw 'unexpected promise resolution';
+		} catch (err) {
+			if (err.name !== 'TypeError') {
+				throw 'unexpected error: ' + err;
+			}
+		}
+	`))
+
+	assert.NoError(t, err)
+}
+
+func TestWriteFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writing and appending to files should succeed", func(t *testing.T) {
+		t.Parallel()
+
+		fs := fsext.NewMemMapFs()
+		runtime, err := newConfiguredRuntimeWithOutputDir(t, fs, "/out")
+		require.NoError(t, err)
+
+		runtime.MoveToVUContext(&lib.State{})
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			await fs.mkdir('tokens/vu1', { recursive: true });
+			await fs.writeFile('tokens/vu1/token.txt', 'first');
+			await fs.writeFile('tokens/vu1/token.txt', 'second');
+			await fs.appendFile('tokens/vu1/token.txt', new Uint8Array([10, 104, 105]));
+			await fs.appendFile('ids.txt', new Uint8Array([52, 50]).buffer);
+		`))
+		require.NoError(t, err)
+
+		got, err := fsext.ReadFile(fs, "/out/tokens/vu1/token.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "second\nhi", string(got))
+
+		got, err = fsext.ReadFile(fs, "/out/ids.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "42", string(got))
+	})
+
+	t.Run("writing outside of the output directory should fail", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntimeWithOutputDir(t, fsext.NewMemMapFs(), "/out")
+		require.NoError(t, err)
+
+		runtime.MoveToVUContext(&lib.State{})
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			for (const path of ['../escape.txt', '/etc/passwd', 'dir/../../escape.txt']) {
+				try {
+					await fs.writeFile(path, 'data');
+					throw 'unexpected promise resolution for ' + path;
+				} catch (err) {
+					if (err.name !== 'ForbiddenError') {
+						throw 'unexpected error: ' + err;
+					}
+				}
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("writing to a missing directory or creating an existing one should fail", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntimeWithOutputDir(t, fsext.NewMemMapFs(), "/out")
+		require.NoError(t, err)
+
+		runtime.MoveToVUContext(&lib.State{})
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			try {
+				await fs.writeFile('missing/file.txt', 'data');
+				throw 'unexpected promise resolution';
+			} catch (err) {
+				if (err.name !== 'NotFoundError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+
+			await fs.mkdir('dir');
+			try {
+				await fs.mkdir('dir');
+				throw 'unexpected promise resolution';
+			} catch (err) {
+				if (err.name !== 'AlreadyExistsError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+			await fs.mkdir('dir', { recursive: true });
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("writing invalid data should fail", func(t *testing.T) {
+		t.Parallel()
+
+		fs := fsext.NewMemMapFs()
+		runtime, err := newConfiguredRuntimeWithOutputDir(t, fs, "/out")
+		require.NoError(t, err)
+
+		runtime.MoveToVUContext(&lib.State{})
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			const outOfBounds = new Uint8Array(4);
+			Object.defineProperty(outOfBounds, 'byteOffset', { value: 2 });
+			Object.defineProperty(outOfBounds, 'byteLength', { value: 3 });
+
+			const invalid = [
+				null,
+				42,
+				{ buffer: new ArrayBuffer(1), byteOffset: 5, byteLength: 10 },
+				{ buffer: new ArrayBuffer(8), byteOffset: 0, byteLength: 1 },
+				outOfBounds,
+			];
+			for (const data of invalid) {
+				try {
+					await fs.writeFile('a.txt', data);
+					throw 'unexpected promise resolution for ' + data;
+				} catch (err) {
+					if (err.name !== 'TypeError') {
+						throw 'unexpected error: ' + err;
+					}
+				}
+			}
+
+			await fs.writeFile('view.txt', new Uint8Array([104, 101, 121]).subarray(1));
+			await fs.appendFile('view.txt', new DataView(new Uint8Array([33, 33]).buffer, 1));
+		`))
+		require.NoError(t, err)
+
+		exists, err := fsext.Exists(fs, "/out/a.txt")
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		got, err := fsext.ReadFile(fs, "/out/view.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "ey!", string(got))
+	})
+
+	t.Run("writing without an output directory or in the init context should fail", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntimeWithOutputDir(t, fsext.NewMemMapFs(), "/out")
+		require.NoError(t, err)
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			try {
+				await fs.writeFile('file.txt', 'data');
+				throw 'unexpected promise resolution';
+			} catch (err) {
+				if (err.name !== 'ForbiddenError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+		`))
+		require.NoError(t, err)
+
+		runtime, err = newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		runtime.MoveToVUContext(&lib.State{})
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			try {
+				await fs.appendFile('file.txt', 'data');
+				throw 'unexpected promise resolution';
+			} catch (err) {
+				if (err.name !== 'ForbiddenError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
 }
 
 func TestOpenImpl(t *testing.T) {
@@ -686,8 +1029,19 @@ const initGlobals = `
 `
 
 func newConfiguredRuntime(t testing.TB) (*modulestest.Runtime, error) {
+	return configureRuntime(modulestest.NewRuntime(t))
+}
+
+// newConfiguredRuntimeWithOutputDir is like newConfiguredRuntime, but the module is
+// allowed to write to the given directory of the provided file system.
+func newConfiguredRuntimeWithOutputDir(t testing.TB, fs fsext.Fs, dir string) (*modulestest.Runtime, error) {
 	runtime := modulestest.NewRuntime(t)
+	runtime.VU.InitEnvField.OutputFS = fsext.NewBasePathFs(fs, dir)
+
+	return configureRuntime(runtime)
+}
 
+func configureRuntime(runtime *modulestest.Runtime) (*modulestest.Runtime, error) {
 	err := runtime.SetupModuleSystem(map[string]interface{}{"k6/experimental/fs": New()}, nil, compiler.New(runtime.VU.InitEnv().Logger))
 	if err != nil {
 		return nil, err
@@ -0,0 +1,171 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.k6.io/k6/lib/fsext"
+)
+
+// writer performs the write operations of the module, on the files of the output
+// directory configured for the test.
+//
+// A single writer is shared by all the VUs, and it serializes the operations targeting
+// the same file. That way, the content written by different VUs to a same file is
+// never interleaved, and a file is never truncated while another VU appends to it.
+type writer struct {
+	// fs holds the file system of the output directory. All the paths are
+	// relative to it, and no operation can escape it.
+	fs fsext.Fs
+
+	// locks holds a safe for concurrent use map, holding the *sync.Mutex
+	// guarding the operations on each file. Keys are the cleaned paths.
+	locks sync.Map
+
+	// root ensures the output directory is created, once, before the first operation.
+	root    sync.Once
+	rootErr error
+}
+
+// newWriter returns a writer for the provided file system of the output directory.
+func newWriter(outputFs fsext.Fs) *writer {
+	return &writer{fs: outputFs}
+}
+
+// writeFile writes the data to the file at the given path, creating it if needed.
+//
+// If appendMode is true, the data is added to the end of the file, otherwise the file is
+// truncated beforehand.
+func (w *writer) writeFile(path string, data []byte, appendMode bool) error {
+	path, err := w.resolve(path)
+	if err != nil {
+		return err
+	}
+
+	unlock := w.lock(path)
+	defer unlock()
+
+	if err := w.checkParent(path); err != nil {
+		return err
+	}
+
+	if isDir, err := fsext.IsDir(w.fs, path); err == nil && isDir {
+		return newFsError(InvalidResourceError, fmt.Sprintf("cannot write to %q: it is a directory", path))
+	}
+
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appendMode {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+
+	f, err := w.fs.OpenFile(path, flag, 0o644)
+	if err != nil {
+		return fsErrorFrom(path, err)
+	}
+
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write to file %s: %w", path, err)
+	}
+
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("failed to close file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// mkdir creates the directory at the given path.
+//
+// If recursive is true, the missing parent directories are created as well, and
+// the directory already existing isn't an error.
+func (w *writer) mkdir(path string, recursive bool) error {
+	path, err := w.resolve(path)
+	if err != nil {
+		return err
+	}
+
+	unlock := w.lock(path)
+	defer unlock()
+
+	if recursive {
+		err = w.fs.MkdirAll(path, 0o755)
+	} else if err = w.checkParent(path); err == nil {
+		err = w.fs.Mkdir(path, 0o755)
+	}
+	if err != nil {
+		return fsErrorFrom(path, err)
+	}
+
+	return nil
+}
+
+// resolve returns the path, within the output directory, designated by the given
+// path, and ensures the output directory exists.
+//
+// Paths are relative to the output directory, and those which are absolute or
+// that would escape it are forbidden.
+func (w *writer) resolve(path string) (string, error) {
+	if !filepath.IsLocal(path) {
+		return "", newFsError(
+			ForbiddenError,
+			fmt.Sprintf("%q must be a relative path within the output directory", path),
+		)
+	}
+
+	w.root.Do(func() {
+		w.rootErr = w.fs.MkdirAll(fsext.FilePathSeparator, 0o755)
+	})
+	if w.rootErr != nil {
+		return "", fmt.Errorf("unable to create the output directory; reason: %w", w.rootErr)
+	}
+
+	return fsext.FilePathSeparator + filepath.Clean(path), nil
+}
+
+// checkParent ensures the parent directory of the given path exists.
+//
+// Not all the file systems report it, some of them creating the missing
+// directories implicitly, so it's explicitly checked beforehand.
+func (w *writer) checkParent(path string) error {
+	parent := filepath.Dir(path)
+
+	isDir, err := fsext.IsDir(w.fs, parent)
+	if errors.Is(err, fs.ErrNotExist) {
+		return newFsError(NotFoundError, fmt.Sprintf("no such directory %q", parent))
+	} else if err != nil {
+		return fmt.Errorf("unable to verify if %q is a directory; reason: %w", parent, err)
+	} else if !isDir {
+		return newFsError(InvalidResourceError, fmt.Sprintf("%q is not a directory", parent))
+	}
+
+	return nil
+}
+
+// lock acquires the lock guarding the given path, and returns the function releasing it.
+func (w *writer) lock(path string) func() {
+	l, _ := w.locks.LoadOrStore(path, &sync.Mutex{})
+	mu, ok := l.(*sync.Mutex)
+	if !ok {
+		panic(fmt.Errorf("writer's lock for %s is not stored as a *sync.Mutex", path))
+	}
+
+	mu.Lock()
+	return mu.Unlock
+}
+
+// fsErrorFrom converts the errors returned by the file system into the matching [fsError].
+func fsErrorFrom(path string, err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return newFsError(NotFoundError, fmt.Sprintf("no such file or directory %q", path))
+	case errors.Is(err, fs.ErrExist):
+		return newFsError(AlreadyExistsError, fmt.Sprintf("%q already exists", path))
+	default:
+		return err
+	}
+}
@@ -0,0 +1,66 @@
+package fs
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/lib/fsext"
+)
+
+func TestWriterWriteFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("concurrent appends should never be interleaved", func(t *testing.T) {
+		t.Parallel()
+
+		fs := fsext.NewMemMapFs()
+		w := newWriter(fsext.NewBasePathFs(fs, "/out"))
+
+		const writers, writes = 10, 50
+		line := strings.Repeat("x", 1024) + "\n"
+
+		var wg sync.WaitGroup
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < writes; j++ {
+					assert.NoError(t, w.writeFile("lines.txt", []byte(line), true))
+				}
+			}()
+		}
+		wg.Wait()
+
+		got, err := fsext.ReadFile(fs, "/out/lines.txt")
+		require.NoError(t, err)
+		assert.Equal(t, strings.Repeat(line, writers*writes), string(got))
+	})
+
+	t.Run("writing should create the output directory", func(t *testing.T) {
+		t.Parallel()
+
+		fs := fsext.NewMemMapFs()
+		w := newWriter(fsext.NewBasePathFs(fs, "/some/out"))
+
+		require.NoError(t, w.writeFile("file.txt", []byte("data"), false))
+
+		got, err := fsext.ReadFile(fs, "/some/out/file.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "data", string(got))
+	})
+
+	t.Run("writing to a directory should fail", func(t *testing.T) {
+		t.Parallel()
+
+		w := newWriter(fsext.NewBasePathFs(fsext.NewMemMapFs(), "/out"))
+		require.NoError(t, w.mkdir("dir", false))
+
+		err := w.writeFile("dir", []byte("data"), false)
+		var fsErr *fsError
+		require.ErrorAs(t, err, &fsErr)
+		assert.Equal(t, InvalidResourceError, fsErr.kind)
+	})
+}
@@ -0,0 +1,397 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/mstoykov/k6-taskqueue-lib/taskqueue"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/metrics"
+)
+
+// ReadyState is the EventSource specification's readyState
+type ReadyState uint8
+
+const (
+	// CONNECTING is the state while the connection is being established,
+	// or reestablished after it was lost
+	CONNECTING ReadyState = iota
+	// OPEN is the state while events are being received
+	OPEN
+	// CLOSED is the state after the EventSource was closed, or failed
+	CLOSED
+)
+
+// defaultReconnectionTime is the time to wait before reconnecting, until the
+// server sends another one with the retry field
+const defaultReconnectionTime = 3 * time.Second
+
+// errStreamEnded is the error with which the error event is emitted when the
+// server ends the event stream, before the EventSource reconnects
+var errStreamEnded = errors.New("the event stream was ended by the server")
+
+// fatalError is an error after which the EventSource doesn't reconnect
+type fatalError struct {
+	err error
+}
+
+func (e fatalError) Error() string {
+	return e.err.Error()
+}
+
+type eventSource struct {
+	vu          modules.VU
+	url         *url.URL
+	params      *params
+	metrics     *instanceMetrics
+	tagsAndMeta *metrics.TagsAndMeta
+	tq          *taskqueue.TaskQueue
+	client      *http.Client
+	obj         *goja.Object // the object that is given to js to interact with the EventSource
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	eventListeners *eventListeners
+
+	// fields that are only used by the connection goroutine
+	reconnectionTime time.Duration
+	lastEventID      string
+
+	// fields that should be seen by js only be updated on the event loop
+	readyState ReadyState
+}
+
+func newEventSource(vu modules.VU, m *instanceMetrics, u *url.URL, p *params) *eventSource {
+	client := &http.Client{Transport: vu.State().Transport}
+	// this is needed because of how interfaces work and that client.Jar is http.CookieJar
+	if p.cookieJar != nil {
+		client.Jar = p.cookieJar
+	}
+
+	ctx, cancel := context.WithCancel(vu.Context())
+	return &eventSource{
+		vu:               vu,
+		url:              u,
+		params:           p,
+		metrics:          m,
+		tagsAndMeta:      p.tagsAndMeta,
+		tq:               taskqueue.New(vu.RegisterCallback),
+		client:           client,
+		obj:              vu.Runtime().NewObject(),
+		ctx:              ctx,
+		cancel:           cancel,
+		eventListeners:   newEventListeners(),
+		reconnectionTime: defaultReconnectionTime,
+		readyState:       CONNECTING,
+	}
+}
+
+// parseURL parses the url from the first constructor calls argument or returns an error
+func parseURL(urlValue goja.Value) (*url.URL, error) {
+	if common.IsNullish(urlValue) {
+		return nil, errors.New("EventSource requires a url")
+	}
+
+	urlString := urlValue.String()
+	u, err := url.Parse(urlString)
+	if err != nil {
+		return nil, fmt.Errorf("EventSource requires valid url, but got %q which resulted in %w", urlString, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("EventSource requires url with scheme http or https, but got %q", u.Scheme)
+	}
+
+	return u, nil
+}
+
+// defineEventSource defines all properties and methods for the EventSource
+func defineEventSource(rt *goja.Runtime, es *eventSource) {
+	must(rt, es.obj.DefineDataProperty(
+		"addEventListener", rt.ToValue(es.addEventListener), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	must(rt, es.obj.DefineDataProperty(
+		"close", rt.ToValue(es.close), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	must(rt, es.obj.DefineDataProperty(
+		"url", rt.ToValue(es.url.String()), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	must(rt, es.obj.DefineDataProperty(
+		"withCredentials", rt.ToValue(false), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	must(rt, es.obj.DefineAccessorProperty( // this needs to be with an accessor as we change the value
+		"readyState", rt.ToValue(func() ReadyState {
+			return es.readyState
+		}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE))
+
+	setOn := func(property string, eventType string) {
+		must(rt, es.obj.DefineAccessorProperty(
+			property, rt.ToValue(func() goja.Value {
+				if fn := es.eventListeners.getOn(eventType); fn != nil {
+					return rt.ToValue(fn)
+				}
+				return goja.Null()
+			}), rt.ToValue(func(call goja.FunctionCall) goja.Value {
+				arg := call.Argument(0)
+
+				// it's possible to unset handlers by setting them to null
+				if common.IsNullish(arg) {
+					es.eventListeners.setOn(eventType, nil)
+
+					return nil
+				}
+
+				fn, isFunc := goja.AssertFunction(arg)
+				if !isFunc {
+					common.Throw(rt, fmt.Errorf("a value for '%s' should be callable", property))
+				}
+
+				es.eventListeners.setOn(eventType, func(v goja.Value) (goja.Value, error) { return fn(goja.Undefined(), v) })
+
+				return nil
+			}), goja.FLAG_FALSE, goja.FLAG_TRUE))
+	}
+
+	setOn("onopen", eventOpen)
+	setOn("onmessage", eventMessage)
+	setOn("onerror", eventError)
+}
+
+// addEventListener adds a listener for events of the given type, which can
+// be any of the types that the server sends, besides open and error
+func (es *eventSource) addEventListener(eventType string, handler goja.Value) {
+	fn, isFunc := goja.AssertFunction(handler)
+	if !isFunc {
+		common.Throw(es.vu.Runtime(), fmt.Errorf("a handler for '%s' event should be callable", eventType))
+	}
+
+	es.eventListeners.add(eventType, func(v goja.Value) (goja.Value, error) { return fn(goja.Undefined(), v) })
+}
+
+// close closes the EventSource, no more events are dispatched after it
+func (es *eventSource) close() {
+	if es.readyState == CLOSED {
+		return
+	}
+	es.readyState = CLOSED
+	es.cancel()
+}
+
+// run connects to the server and keeps reconnecting to it whenever the
+// connection is lost, until the EventSource is closed or it fails
+func (es *eventSource) run() {
+	started := time.Now()
+	defer func() {
+		metrics.PushIfNotDone(es.vu.Context(), es.vu.State().Samples, metrics.Sample{
+			TimeSeries: metrics.TimeSeries{Metric: es.metrics.SessionDuration, Tags: es.tagsAndMeta.Tags},
+			Time:       time.Now(),
+			Metadata:   es.tagsAndMeta.Metadata,
+			Value:      metrics.D(time.Since(started)),
+		})
+		es.cancel()
+		es.tq.Close()
+	}()
+
+	es.setURLTags()
+	es.pushCounter(es.metrics.Sessions, started)
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			es.pushCounter(es.metrics.Reconnects, time.Now())
+		}
+
+		err := es.connect()
+		if es.ctx.Err() != nil {
+			// the EventSource was closed or the VU is shutting down during
+			// an interrupt, so no more events will be forwarded to the VU
+			return
+		}
+
+		var fErr fatalError
+		if errors.As(err, &fErr) {
+			es.tq.Queue(func() error {
+				if es.readyState == CLOSED {
+					return nil
+				}
+				es.readyState = CLOSED
+				return es.callErrorListeners(fErr)
+			})
+			return
+		}
+
+		es.vu.State().Logger.WithError(err).Debugf("reconnecting to %s in %s", es.url, es.reconnectionTime)
+		es.tq.Queue(func() error {
+			if es.readyState == CLOSED {
+				return nil
+			}
+			es.readyState = CONNECTING
+			return es.callErrorListeners(err)
+		})
+
+		timer := time.NewTimer(es.reconnectionTime)
+		select {
+		case <-timer.C:
+		case <-es.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// connect makes a single request to the server and dispatches the events it
+// sends, until the connection is lost. It always returns a non-nil error.
+func (es *eventSource) connect() error {
+	var body io.Reader
+	if es.params.body != "" {
+		body = strings.NewReader(es.params.body)
+	}
+	req, err := http.NewRequestWithContext(es.ctx, es.params.method, es.url.String(), body)
+	if err != nil {
+		return fatalError{err: err}
+	}
+	req.Header = es.params.headers.Clone()
+	if es.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", es.lastEventID)
+	}
+
+	start := time.Now()
+	resp, err := es.client.Do(req) //nolint:bodyclose
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	systemTags := es.vu.State().Options.SystemTags
+	es.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagStatus, strconv.Itoa(resp.StatusCode))
+
+	if resp.StatusCode != http.StatusOK {
+		return fatalError{err: fmt.Errorf("EventSource got an unexpected response status %d", resp.StatusCode)}
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt != "text/event-stream" {
+		return fatalError{err: fmt.Errorf("EventSource got an unexpected response content type %q", mt)}
+	}
+
+	es.tq.Queue(func() error {
+		if es.readyState == CLOSED {
+			return nil
+		}
+		es.readyState = OPEN
+		return es.callEventListeners(eventOpen, es.newEvent(eventOpen))
+	})
+
+	p := newParser(resp.Body, es.lastEventID)
+	for received := 0; ; received++ {
+		ev, err := p.next()
+		if p.retry >= 0 {
+			es.reconnectionTime = p.retry
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return errStreamEnded
+			}
+			return err
+		}
+		es.lastEventID = ev.lastEventID
+
+		if received == 0 {
+			metrics.PushIfNotDone(es.vu.Context(), es.vu.State().Samples, metrics.Sample{
+				TimeSeries: metrics.TimeSeries{Metric: es.metrics.TimeToFirstEvent, Tags: es.tagsAndMeta.Tags},
+				Time:       ev.received,
+				Metadata:   es.tagsAndMeta.Metadata,
+				Value:      metrics.D(ev.received.Sub(start)),
+			})
+		}
+		es.queueEvent(ev)
+	}
+}
+
+// setURLTags sets the url and name tags the same way as other protocols.
+func (es *eventSource) setURLTags() {
+	systemTags := es.vu.State().Options.SystemTags
+	// After k6 v0.41.0, the `name` and `url` tags have the exact same values:
+	if nameTagValue, ok := es.params.tagsAndMeta.Tags.Get(metrics.TagName.String()); ok {
+		es.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagURL, nameTagValue)
+	} else {
+		es.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagURL, es.url.String())
+		es.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagName, es.url.String())
+	}
+}
+
+func (es *eventSource) pushCounter(metric *metrics.Metric, t time.Time) {
+	metrics.PushIfNotDone(es.vu.Context(), es.vu.State().Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{Metric: metric, Tags: es.tagsAndMeta.Tags},
+		Time:       t,
+		Metadata:   es.tagsAndMeta.Metadata,
+		Value:      1,
+	})
+}
+
+func (es *eventSource) queueEvent(ev *event) {
+	es.pushCounter(es.metrics.EventsReceived, ev.received)
+
+	es.tq.Queue(func() error {
+		if es.readyState == CLOSED {
+			return nil
+		}
+		obj := es.newEvent(ev.typ)
+		must(es.vu.Runtime(), obj.DefineDataProperty("data",
+			es.vu.Runtime().ToValue(ev.data), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+		must(es.vu.Runtime(), obj.DefineDataProperty("lastEventId",
+			es.vu.Runtime().ToValue(ev.lastEventID), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+		must(es.vu.Runtime(), obj.DefineDataProperty("timestamp",
+			es.vu.Runtime().ToValue(ev.received.UnixMilli()), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+		return es.callEventListeners(ev.typ, obj)
+	})
+}
+
+func (es *eventSource) newEvent(eventType string) *goja.Object {
+	rt := es.vu.Runtime()
+	obj := rt.NewObject()
+	must(rt, obj.DefineDataProperty("type", rt.ToValue(eventType), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	must(rt, obj.DefineDataProperty("origin",
+		rt.ToValue(es.url.Scheme+"://"+es.url.Host), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	must(rt, obj.DefineDataProperty("target", es.obj, goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	return obj
+}
+
+func (es *eventSource) callErrorListeners(e error) error {
+	rt := es.vu.Runtime()
+	obj := es.newEvent(eventError)
+	must(rt, obj.DefineDataProperty("error", rt.ToValue(e.Error()), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+
+	listeners := es.eventListeners.all(eventError)
+	if len(listeners) == 0 {
+		es.vu.State().Logger.Warnf("no handlers for error registered, but an error happened: %s", e)
+		return nil
+	}
+	return es.callEventListeners(eventError, obj)
+}
+
+func (es *eventSource) callEventListeners(eventType string, ev *goja.Object) error {
+	for _, listener := range es.eventListeners.all(eventType) {
+		if _, err := listener(ev); err != nil {
+			// an exception in a listener interrupts the iteration, so the
+			// connection shouldn't be kept around either
+			es.readyState = CLOSED
+			es.cancel()
+			return err
+		}
+	}
+	return nil
+}
+
+// must is a small helper that will panic if err is not nil.
+func must(rt *goja.Runtime, err error) {
+	if err != nil {
+		common.Throw(rt, err)
+	}
+}
@@ -0,0 +1,256 @@
+package sse
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/metrics"
+)
+
+type testState struct {
+	*modulestest.Runtime
+	tb         *httpmultibin.HTTPMultiBin
+	samples    chan metrics.SampleContainer
+	loggerHook *testutils.SimpleLogrusHook
+}
+
+func newTestState(t testing.TB) testState {
+	tb := httpmultibin.NewHTTPMultiBin(t)
+
+	testRuntime := modulestest.NewRuntime(t)
+	samples := make(chan metrics.SampleContainer, 1000)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.InfoLevel)
+	logger.Out = io.Discard
+	hook := testutils.NewLogHook()
+	logger.AddHook(hook)
+
+	registry := metrics.NewRegistry()
+	state := &lib.State{
+		Dialer:    tb.Dialer,
+		Transport: tb.HTTPTransport,
+		Options: lib.Options{
+			SystemTags: metrics.NewSystemTagSet(
+				metrics.TagURL,
+				metrics.TagName,
+				metrics.TagStatus,
+			),
+			UserAgent: null.StringFrom("TestUserAgent"),
+		},
+		Samples:        samples,
+		TLSConfig:      tb.TLSClientConfig,
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+		Logger:         logger,
+	}
+
+	m := New().NewModuleInstance(testRuntime.VU)
+	require.NoError(t, testRuntime.VU.RuntimeField.Set("EventSource", m.Exports().Named["EventSource"]))
+	testRuntime.MoveToVUContext(state)
+
+	return testState{
+		Runtime:    testRuntime,
+		tb:         tb,
+		samples:    samples,
+		loggerHook: hook,
+	}
+}
+
+func (ts *testState) run(t *testing.T, code string) {
+	t.Helper()
+	_, err := ts.RunOnEventLoop(ts.tb.Replacer.Replace(code))
+	require.NoError(t, err)
+}
+
+func (ts *testState) results(t *testing.T) []string {
+	t.Helper()
+	var results []string
+	require.NoError(t, ts.VU.Runtime().ExportTo(ts.VU.Runtime().Get("results"), &results))
+	return results
+}
+
+func (ts *testState) metricSamples() map[string][]metrics.Sample {
+	close(ts.samples)
+	result := make(map[string][]metrics.Sample)
+	for container := range ts.samples {
+		for _, s := range container.GetSamples() {
+			result[s.Metric.Name] = append(result[s.Metric.Name], s)
+		}
+	}
+	return result
+}
+
+func TestEventSource(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	ts.tb.Mux.HandleFunc("/events", func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "text/event-stream", req.Header.Get("Accept"))
+		assert.Equal(t, "TestUserAgent", req.Header.Get("User-Agent"))
+		assert.Equal(t, "value", req.Header.Get("X-Custom"))
+
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		_, _ = io.WriteString(w, ": ping\n\ndata: first\n\nevent: update\nid: 1\ndata: second\ndata: line\n\n")
+		w.(http.Flusher).Flush()
+		<-req.Context().Done() // until the client closes the EventSource
+	})
+
+	ts.run(t, `
+		var results = [];
+		var es = new EventSource("HTTPBIN_URL/events", { headers: { "X-Custom": "value" }, tags: { tag: "custom" } });
+		results.push("state " + es.readyState);
+		es.onopen = (e) => results.push("open " + es.readyState);
+		es.onmessage = (e) => results.push(e.type + " " + e.data + " " + e.lastEventId);
+		es.addEventListener("update", (e) => {
+			results.push(e.type + " " + e.data + " " + e.lastEventId);
+			es.close();
+			results.push("state " + es.readyState);
+		});
+		es.onerror = (e) => results.push("error " + e.error);
+	`)
+
+	assert.Equal(t, []string{
+		"state 0",
+		"open 1",
+		"message first ",
+		"update second\nline 1",
+		"state 2",
+	}, ts.results(t))
+
+	samples := ts.metricSamples()
+	require.Len(t, samples["sse_sessions"], 1)
+	require.Len(t, samples["sse_event_received"], 2)
+	require.Len(t, samples["sse_time_to_first_event"], 1)
+	require.Len(t, samples["sse_session_duration"], 1)
+	assert.Empty(t, samples["sse_reconnects"])
+
+	tags := samples["sse_event_received"][0].Tags.Map()
+	assert.Equal(t, map[string]string{
+		"url":    ts.tb.Replacer.Replace("HTTPBIN_URL/events"),
+		"name":   ts.tb.Replacer.Replace("HTTPBIN_URL/events"),
+		"status": "200",
+		"tag":    "custom",
+	}, tags)
+}
+
+func TestEventSourceReconnect(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	var mx sync.Mutex
+	var lastEventIDs []string
+	ts.tb.Mux.HandleFunc("/events", func(w http.ResponseWriter, req *http.Request) {
+		mx.Lock()
+		lastEventIDs = append(lastEventIDs, req.Header.Get("Last-Event-ID"))
+		attempt := len(lastEventIDs)
+		mx.Unlock()
+
+		assert.Equal(t, http.MethodPost, req.Method)
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"prompt":"hi"}`, string(body))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		// the connection is ended after the event, so the client has to reconnect
+		_, _ = fmt.Fprintf(w, "retry: 10\nid: %d\ndata: attempt %d\n\n", attempt, attempt)
+	})
+
+	ts.run(t, `
+		var results = [];
+		var es = new EventSource("HTTPBIN_URL/events", { method: "POST", body: JSON.stringify({ prompt: "hi" }) });
+		es.onmessage = (e) => {
+			results.push(e.data);
+			if (e.lastEventId === "3") {
+				es.close();
+			}
+		};
+		es.onerror = (e) => results.push("error " + e.error + " " + es.readyState);
+	`)
+
+	assert.Equal(t, []string{
+		"attempt 1",
+		"error the event stream was ended by the server 0",
+		"attempt 2",
+		"error the event stream was ended by the server 0",
+		"attempt 3",
+	}, ts.results(t))
+
+	mx.Lock()
+	assert.Equal(t, []string{"", "1", "2"}, lastEventIDs)
+	mx.Unlock()
+
+	samples := ts.metricSamples()
+	assert.Len(t, samples["sse_sessions"], 1)
+	assert.Len(t, samples["sse_reconnects"], 2)
+	assert.Len(t, samples["sse_time_to_first_event"], 3)
+}
+
+func TestEventSourceFailure(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		handler http.HandlerFunc
+		err     string
+	}{
+		"status": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			err: "EventSource got an unexpected response status 204",
+		},
+		"content type": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, "{}")
+			},
+			err: `EventSource got an unexpected response content type "application/json"`,
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestState(t)
+			ts.tb.Mux.HandleFunc("/events", tc.handler)
+			ts.run(t, `
+				var results = [];
+				var es = new EventSource("HTTPBIN_URL/events");
+				es.onopen = () => results.push("open");
+				es.onerror = (e) => results.push(e.error + " " + es.readyState);
+			`)
+
+			assert.Equal(t, []string{tc.err + " 2"}, ts.results(t))
+			assert.Empty(t, ts.metricSamples()["sse_reconnects"])
+		})
+	}
+}
+
+func TestEventSourceInvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		`new EventSource()`:                           "EventSource requires a url",
+		`new EventSource("ws://example.com")`:         `EventSource requires url with scheme http or https, but got "ws"`,
+		`new EventSource("http://a", { foo: "bar" })`: "unknown EventSource's option foo",
+	}
+	for code, expErr := range testCases {
+		ts := newTestState(t)
+		_, err := ts.RunOnEventLoop(code)
+		assert.ErrorContains(t, err, expErr)
+	}
+}
@@ -0,0 +1,60 @@
+package sse
+
+import "github.com/dop251/goja"
+
+const (
+	eventOpen    = "open"
+	eventMessage = "message"
+	eventError   = "error"
+)
+
+// listenerFunc is an event listener. It returns goja.Value *and* error in
+// order to return an error on exception instead of panicking, see
+// https://pkg.go.dev/github.com/dop251/goja#hdr-Functions
+type listenerFunc func(goja.Value) (goja.Value, error)
+
+// eventListeners keeps track of the listeners for each event type. Unlike
+// most other event targets, event streams can have events of any type,
+// so the listeners are kept in a map instead of in a fixed set of fields.
+type eventListeners struct {
+	// on keeps the listeners set with the on* properties, like onmessage
+	on map[string]listenerFunc
+	// list keeps any other listeners that were added with addEventListener
+	list map[string][]listenerFunc
+}
+
+func newEventListeners() *eventListeners {
+	return &eventListeners{
+		on:   make(map[string]listenerFunc),
+		list: make(map[string][]listenerFunc),
+	}
+}
+
+// add adds a listener for the given event type
+func (l *eventListeners) add(t string, fn listenerFunc) {
+	l.list[t] = append(l.list[t], fn)
+}
+
+// setOn sets the on* property listener for the given event type, a nil
+// listener unsets it
+func (l *eventListeners) setOn(t string, fn listenerFunc) {
+	if fn == nil {
+		delete(l.on, t)
+		return
+	}
+	l.on[t] = fn
+}
+
+// getOn returns the on* property listener for the given event type
+func (l *eventListeners) getOn(t string) listenerFunc {
+	return l.on[t]
+}
+
+// all returns all listeners for the given event type
+func (l *eventListeners) all(t string) []listenerFunc {
+	on, ok := l.on[t]
+	if !ok {
+		return l.list[t]
+	}
+	return append([]listenerFunc{on}, l.list[t]...)
+}
@@ -0,0 +1,40 @@
+package sse
+
+import "go.k6.io/k6/metrics"
+
+// instanceMetrics contains the metrics for the sse module.
+type instanceMetrics struct {
+	Sessions         *metrics.Metric
+	EventsReceived   *metrics.Metric
+	Reconnects       *metrics.Metric
+	TimeToFirstEvent *metrics.Metric
+	SessionDuration  *metrics.Metric
+}
+
+// registerMetrics registers and returns the metrics in the provided registry
+func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
+	var err error
+	m := &instanceMetrics{}
+
+	if m.Sessions, err = registry.NewMetric("sse_sessions", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.EventsReceived, err = registry.NewMetric("sse_event_received", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.Reconnects, err = registry.NewMetric("sse_reconnects", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.TimeToFirstEvent, err = registry.NewMetric("sse_time_to_first_event", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.SessionDuration, err = registry.NewMetric("sse_session_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,83 @@
+// Package sse implements a Server-Sent Events client for k6, modeled after
+// the EventSource API, see https://html.spec.whatwg.org/multipage/server-sent-events.html
+package sse
+
+import (
+	"fmt"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the SSE module for every VU.
+	ModuleInstance struct {
+		vu      modules.VU
+		metrics *instanceMetrics
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new RootModule instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface to return
+// a new instance for each VU.
+func (r *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	metrics, err := registerMetrics(vu.InitEnv().Registry)
+	if err != nil {
+		common.Throw(vu.Runtime(), fmt.Errorf("failed to register SSE module metrics: %w", err))
+	}
+
+	return &ModuleInstance{
+		vu:      vu,
+		metrics: metrics,
+	}
+}
+
+// Exports returns the exports of the SSE module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"EventSource": mi.eventSource,
+		},
+	}
+}
+
+// eventSource is the JS constructor of the EventSource.
+func (mi *ModuleInstance) eventSource(c goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+	state := mi.vu.State()
+	if state == nil {
+		common.Throw(rt, common.NewInitContextError("Creating an EventSource in the init context is not supported"))
+	}
+
+	u, err := parseURL(c.Argument(0))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	p, err := buildParams(state, rt, c.Argument(1))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	es := newEventSource(mi.vu, mi.metrics, u, p)
+	defineEventSource(rt, es)
+
+	go es.run()
+
+	return es.obj
+}
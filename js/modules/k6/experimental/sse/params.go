@@ -0,0 +1,75 @@
+package sse
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	httpModule "go.k6.io/k6/js/modules/k6/http"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+// params represent the parameters bag of an EventSource
+type params struct {
+	method      string
+	body        string
+	headers     http.Header
+	cookieJar   *cookiejar.Jar
+	tagsAndMeta *metrics.TagsAndMeta
+}
+
+// buildParams builds the EventSource params from the constructor's argument
+func buildParams(state *lib.State, rt *goja.Runtime, raw goja.Value) (*params, error) {
+	tagsAndMeta := state.Tags.GetCurrentValues()
+
+	parsed := &params{
+		method:      http.MethodGet,
+		headers:     make(http.Header),
+		cookieJar:   state.CookieJar,
+		tagsAndMeta: &tagsAndMeta,
+	}
+
+	parsed.headers.Set("User-Agent", state.Options.UserAgent.String)
+	parsed.headers.Set("Accept", "text/event-stream")
+	parsed.headers.Set("Cache-Control", "no-cache")
+
+	if common.IsNullish(raw) {
+		return parsed, nil
+	}
+
+	params := raw.ToObject(rt)
+	for _, k := range params.Keys() {
+		v := params.Get(k)
+		if common.IsNullish(v) {
+			continue
+		}
+		switch k {
+		case "method":
+			parsed.method = strings.ToUpper(v.String())
+		case "body":
+			parsed.body = v.String()
+		case "headers":
+			headersObj := v.ToObject(rt)
+			for _, key := range headersObj.Keys() {
+				parsed.headers.Set(key, headersObj.Get(key).String())
+			}
+		case "tags":
+			if err := common.ApplyCustomUserTags(rt, parsed.tagsAndMeta, v); err != nil {
+				return nil, fmt.Errorf("invalid EventSource tags option: %w", err)
+			}
+		case "jar":
+			if jar, ok := v.Export().(*httpModule.CookieJar); ok {
+				parsed.cookieJar = jar.Jar
+			}
+		default:
+			return nil, fmt.Errorf("unknown EventSource's option %s", k)
+		}
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,143 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLineLength is the maximum length of a single line in an event stream.
+const maxLineLength = 16 * 1024 * 1024
+
+// event is a single event dispatched from an event stream.
+type event struct {
+	typ         string
+	data        string
+	lastEventID string
+	received    time.Time
+}
+
+// parser parses a text/event-stream, as described in
+// https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation
+type parser struct {
+	scanner *bufio.Scanner
+	first   bool
+
+	eventType   string
+	data        strings.Builder
+	lastEventID string
+
+	// retry is the last valid reconnection time sent by the server, or -1
+	retry time.Duration
+}
+
+func newParser(r io.Reader, lastEventID string) *parser {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineLength)
+	scanner.Split(scanLines)
+	return &parser{scanner: scanner, first: true, lastEventID: lastEventID, retry: -1}
+}
+
+// next returns the next event from the stream. It returns io.EOF when the
+// stream ends, in which case any incomplete event is discarded.
+func (p *parser) next() (*event, error) {
+	for p.scanner.Scan() {
+		line := p.scanner.Bytes()
+		if p.first {
+			line = bytes.TrimPrefix(line, []byte("\xEF\xBB\xBF")) // the UTF-8 BOM
+			p.first = false
+		}
+		if len(line) == 0 {
+			if ev := p.dispatch(); ev != nil {
+				return ev, nil
+			}
+			continue
+		}
+		p.processLine(line)
+	}
+	if err := p.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func (p *parser) processLine(line []byte) {
+	if line[0] == ':' {
+		return // a comment
+	}
+
+	field, value := line, []byte{}
+	if i := bytes.IndexByte(line, ':'); i >= 0 {
+		field, value = line[:i], line[i+1:]
+		value = bytes.TrimPrefix(value, []byte(" "))
+	}
+
+	switch string(field) {
+	case "event":
+		p.eventType = string(value)
+	case "data":
+		p.data.Write(value)
+		p.data.WriteByte('\n')
+	case "id":
+		if bytes.IndexByte(value, 0) < 0 {
+			p.lastEventID = string(value)
+		}
+	case "retry":
+		if ms, err := strconv.ParseUint(string(value), 10, 63); err == nil {
+			p.retry = time.Duration(ms) * time.Millisecond
+		}
+	}
+}
+
+// dispatch returns the buffered event, if there is one, and resets the
+// buffers for the next one.
+func (p *parser) dispatch() *event {
+	defer func() {
+		p.eventType = ""
+		p.data.Reset()
+	}()
+
+	if p.data.Len() == 0 {
+		return nil
+	}
+	ev := &event{
+		typ:         p.eventType,
+		data:        strings.TrimSuffix(p.data.String(), "\n"),
+		lastEventID: p.lastEventID,
+		received:    time.Now(),
+	}
+	if ev.typ == "" {
+		ev.typ = eventMessage
+	}
+	return ev
+}
+
+// scanLines is a bufio.SplitFunc for the line endings of event streams,
+// which can be CRLF, LF or a single CR.
+func scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// a CR at the end of the buffer could be the start of a CRLF
+		if i+1 == len(data) && !atEOF {
+			return 0, nil, nil
+		}
+		if i+1 < len(data) && data[i+1] == '\n' {
+			return i + 2, data[:i], nil
+		}
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		// an incomplete last line is still returned, but it will never be
+		// followed by the empty line that dispatches its event
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,81 @@
+package sse
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParser(t *testing.T) {
+	t.Parallel()
+
+	type expectedEvent struct {
+		typ, data, lastEventID string
+	}
+	testCases := []struct {
+		name     string
+		stream   string
+		expected []expectedEvent
+		retry    time.Duration
+	}{
+		{
+			name:     "simple",
+			stream:   "data: hello\n\n",
+			expected: []expectedEvent{{"message", "hello", ""}},
+			retry:    -1,
+		},
+		{
+			name:   "multiline data and line endings",
+			stream: "\xEF\xBB\xBFdata: first\r\ndata:second\rdata\n\r\n",
+			expected: []expectedEvent{
+				{"message", "first\nsecond\n", ""},
+			},
+			retry: -1,
+		},
+		{
+			name: "types, ids and comments",
+			stream: ": a comment\n" +
+				"event: update\nid: 1\ndata: {\"a\": 1}\n\n" +
+				"data: no type\n\n" +
+				"id\ndata: reset id\n\n" +
+				"id: 2\x00\ndata: ignored id\n\n",
+			expected: []expectedEvent{
+				{"update", `{"a": 1}`, "1"},
+				{"message", "no type", "1"},
+				{"message", "reset id", ""},
+				{"message", "ignored id", ""},
+			},
+			retry: -1,
+		},
+		{
+			name:     "events without data aren't dispatched",
+			stream:   "event: empty\n\nid: 3\n\nretry: 1500\n\nretry: 1x\n\ndata: incomplete",
+			expected: nil,
+			retry:    1500 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := newParser(strings.NewReader(tc.stream), "")
+			var events []expectedEvent
+			for {
+				ev, err := p.next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				events = append(events, expectedEvent{ev.typ, ev.data, ev.lastEventID})
+			}
+			assert.Equal(t, tc.expected, events)
+			assert.Equal(t, tc.retry, p.retry)
+		})
+	}
+}
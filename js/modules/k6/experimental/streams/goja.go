@@ -1,6 +1,7 @@
 package streams
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 
@@ -23,6 +24,150 @@ func newRejectedPromise(vu modules.VU, with any) *goja.Promise {
 	return promise
 }
 
+// promiseFromCall calls the given function, and returns a promise resolved with the returned
+// value, or rejected with the thrown exception, as the specification does when invoking the
+// callbacks of the underlying sinks and transformers, following the [invoke] algorithm.
+//
+// [invoke]: https://webidl.spec.whatwg.org/#invoke-a-callback-function
+func promiseFromCall(vu modules.VU, fn goja.Callable, this goja.Value, args ...goja.Value) *goja.Promise {
+	v, err := fn(this, args...)
+	if err != nil {
+		var ex *goja.Exception
+		if errors.As(err, &ex) {
+			return newRejectedPromise(vu, ex.Value())
+		}
+		return newRejectedPromise(vu, err)
+	}
+
+	if p, ok := exportPromise(v); ok {
+		return p
+	}
+
+	return newResolvedPromise(vu, v)
+}
+
+// exportPromise returns the promise held by the given value, if any.
+func exportPromise(value goja.Value) (*goja.Promise, bool) {
+	if common.IsNullish(value) {
+		return nil, false
+	}
+
+	p, ok := value.Export().(*goja.Promise)
+	return p, ok
+}
+
+// streamObject is the [goja.DynamicObject] behind the JavaScript objects of the streams.
+//
+// It exposes the fields and methods of the stream, as goja does for any Go value, but
+// it also accepts other properties, e.g. `stream.events = []`, as any JavaScript
+// object does, which goja doesn't allow on the objects of Go values.
+type streamObject struct {
+	stream any
+	host   *goja.Object
+	props  map[string]goja.Value
+	keys   []string
+}
+
+var _ goja.DynamicObject = &streamObject{}
+
+// newStreamObject returns the JavaScript object of the given stream, with the given
+// prototype, or Object.prototype if it's nil.
+func newStreamObject(rt *goja.Runtime, stream any, proto *goja.Object) *goja.Object {
+	host := rt.ToValue(stream).ToObject(rt)
+
+	// The properties the stream doesn't have are looked up in the prototype of
+	// the returned object instead, as usual.
+	if err := host.SetPrototype(nil); err != nil {
+		common.Throw(rt, newError(RuntimeError, err.Error()))
+	}
+
+	obj := rt.NewDynamicObject(&streamObject{
+		stream: stream,
+		host:   host,
+		props:  make(map[string]goja.Value),
+	})
+	if proto != nil {
+		if err := obj.SetPrototype(proto); err != nil {
+			common.Throw(rt, newError(RuntimeError, err.Error()))
+		}
+	}
+
+	return obj
+}
+
+// Get implements the [goja.DynamicObject] interface.
+func (o *streamObject) Get(key string) goja.Value {
+	if v, ok := o.props[key]; ok {
+		return v
+	}
+	return o.host.Get(key)
+}
+
+// Set implements the [goja.DynamicObject] interface.
+//
+// The fields and methods of the stream are read-only.
+func (o *streamObject) Set(key string, val goja.Value) bool {
+	if o.host.Get(key) != nil {
+		return false
+	}
+	if _, ok := o.props[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.props[key] = val
+	return true
+}
+
+// Has implements the [goja.DynamicObject] interface.
+func (o *streamObject) Has(key string) bool {
+	_, ok := o.props[key]
+	return ok || o.host.Get(key) != nil
+}
+
+// Delete implements the [goja.DynamicObject] interface.
+func (o *streamObject) Delete(key string) bool {
+	if _, ok := o.props[key]; !ok {
+		return o.host.Get(key) == nil
+	}
+	delete(o.props, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Keys implements the [goja.DynamicObject] interface.
+func (o *streamObject) Keys() []string {
+	return o.keys
+}
+
+var (
+	streamObjectType   = reflect.TypeOf((*streamObject)(nil))
+	readableStreamType = reflect.TypeOf((*ReadableStream)(nil))
+	writableStreamType = reflect.TypeOf((*WritableStream)(nil))
+)
+
+// exportStream returns the stream held by the given value, if any.
+//
+// It doesn't export any other value, which could recurse infinitely, e.g. on
+// the prototypes of the streams.
+func exportStream(value goja.Value) any {
+	if common.IsNullish(value) {
+		return nil
+	}
+
+	switch value.ExportType() {
+	case streamObjectType:
+		return value.Export().(*streamObject).stream //nolint:forcetypeassert
+	case readableStreamType, writableStreamType:
+		return value.Export()
+	default:
+		return nil
+	}
+}
+
 // promiseThen facilitates instantiating a new promise and defining callbacks for to be executed
 // on fulfillment as well as rejection, directly from Go.
 func promiseThen(
@@ -30,35 +175,125 @@ func promiseThen(
 	promise *goja.Promise,
 	onFulfilled, onRejected func(goja.Value),
 ) (*goja.Promise, error) {
-	val, err := rt.RunString(
-		`(function(promise, onFulfilled, onRejected) { return promise.then(onFulfilled, onRejected) })`)
-	if err != nil {
-		return nil, newError(RuntimeError, "unable to initialize promiseThen internal helper function")
+	var onRejectedValue goja.Value = goja.Undefined()
+	if onRejected != nil {
+		onRejectedValue = rt.ToValue(onRejected)
 	}
 
-	cal, ok := goja.AssertFunction(val)
-	if !ok {
-		return nil, newError(RuntimeError, "the internal promiseThen helper is not a function")
+	return then(rt, promise, rt.ToValue(onFulfilled), onRejectedValue)
+}
+
+// promiseReact returns the promise resulting from reacting to the settlement of the
+// given promise, as described by the specification's [react] algorithm.
+//
+// Unlike with promiseThen, the reactions return the value the resulting promise is
+// resolved with, and they can reject it by panicking with the reason. Any nil
+// reaction is skipped, so the settlement is passed through.
+//
+// [react]: https://webidl.spec.whatwg.org/#dfn-perform-steps-once-promise-is-settled
+func promiseReact(
+	rt *goja.Runtime,
+	promise *goja.Promise,
+	onFulfilled, onRejected func(goja.Value) goja.Value,
+) *goja.Promise {
+	var onFulfilledValue, onRejectedValue goja.Value = goja.Undefined(), goja.Undefined()
+	if onFulfilled != nil {
+		onFulfilledValue = rt.ToValue(onFulfilled)
+	}
+	if onRejected != nil {
+		onRejectedValue = rt.ToValue(onRejected)
+	}
+
+	p, err := then(rt, promise, onFulfilledValue, onRejectedValue)
+	if err != nil {
+		common.Throw(rt, err)
 	}
 
+	return p
+}
+
+// uponPromise runs the given steps once the promise is settled, and is a shorthand for
+// the specification's "upon fulfillment" and "upon rejection" steps.
+func uponPromise(rt *goja.Runtime, promise *goja.Promise, onFulfilled, onRejected func(goja.Value)) {
+	if onFulfilled == nil {
+		onFulfilled = func(goja.Value) {}
+	}
 	if onRejected == nil {
-		val, err = cal(goja.Undefined(), rt.ToValue(promise), rt.ToValue(onFulfilled))
-	} else {
-		val, err = cal(goja.Undefined(), rt.ToValue(promise), rt.ToValue(onFulfilled), rt.ToValue(onRejected))
+		onRejected = func(goja.Value) {}
+	}
+
+	if _, err := promiseThen(rt, promise, onFulfilled, onRejected); err != nil {
+		common.Throw(rt, err)
+	}
+}
+
+// setPromiseIsHandled marks the promise as handled, so its rejection isn't reported
+// as unhandled. It's the equivalent of setting its [[PromiseIsHandled]] slot to true.
+//
+// See https://github.com/dop251/goja/issues/565
+func setPromiseIsHandled(rt *goja.Runtime, promise *goja.Promise) {
+	uponPromise(rt, promise, nil, nil)
+}
+
+// then calls the `then` method of the given promise with the given reactions.
+func then(rt *goja.Runtime, promise *goja.Promise, onFulfilled, onRejected goja.Value) (*goja.Promise, error) {
+	promiseObj := rt.ToValue(promise).ToObject(rt)
+
+	thenFunc, ok := goja.AssertFunction(promiseObj.Get("then"))
+	if !ok {
+		return nil, newError(RuntimeError, "the promise's then method is not a function")
 	}
 
+	val, err := thenFunc(promiseObj, onFulfilled, onRejected)
 	if err != nil {
 		return nil, err
 	}
 
 	newPromise, ok := val.Export().(*goja.Promise)
 	if !ok {
-		return nil, newError(RuntimeError, "unable to cast the internal promiseThen helper's return value to a promise")
+		return nil, newError(RuntimeError, "unable to cast the return value of the promise's then method to a promise")
 	}
 
 	return newPromise, nil
 }
 
+// promiseCapability holds a promise along with the functions to settle it.
+type promiseCapability struct {
+	promise *goja.Promise
+	resolve func(any)
+	reject  func(any)
+}
+
+// newPromiseCapability instantiates a new pending promise.
+func newPromiseCapability(rt *goja.Runtime) *promiseCapability {
+	promise, resolve, reject := rt.NewPromise()
+	return &promiseCapability{
+		promise: promise,
+		resolve: resolve,
+		reject: func(reason any) {
+			reject(reasonValue(reason))
+		},
+	}
+}
+
+// isPending returns true if the promise is neither fulfilled nor rejected yet.
+func (pc *promiseCapability) isPending() bool {
+	return pc.promise.State() == goja.PromiseStatePending
+}
+
+// reasonValue returns the value a promise is rejected with, or an exception is thrown
+// with, for the given error.
+func reasonValue(reason any) any {
+	switch r := reason.(type) {
+	case *jsError:
+		return r.Err()
+	case *goja.Exception:
+		return r.Value()
+	default:
+		return reason
+	}
+}
+
 // isNumber returns true if the given goja value holds a number
 func isNumber(value goja.Value) bool {
 	_, isFloat := value.Export().(float64)
@@ -105,3 +340,108 @@ func setReadOnlyPropertyOf(obj *goja.Object, objName, propName string, propValue
 func isObject(val goja.Value) bool {
 	return val != nil && val.ExportType() != nil && val.ExportType().Kind() == reflect.Map
 }
+
+// arrayBufferView holds the details of an ArrayBufferView, i.e. a typed array or
+// a DataView, needed to operate on its underlying ArrayBuffer.
+type arrayBufferView struct {
+	buffer      goja.ArrayBuffer
+	byteOffset  int
+	byteLength  int
+	elementSize int
+
+	// constructor holds the constructor of the view, so another view of the same
+	// type can be created.
+	constructor goja.Value
+}
+
+// exportArrayBufferView returns the details of the given ArrayBufferView, or
+// false if the value isn't one.
+func exportArrayBufferView(rt *goja.Runtime, value goja.Value) (arrayBufferView, bool) {
+	if !isArrayBufferView(rt, value) {
+		return arrayBufferView{}, false
+	}
+
+	obj := value.ToObject(rt)
+	buffer, ok := obj.Get("buffer").Export().(goja.ArrayBuffer)
+	if !ok {
+		return arrayBufferView{}, false
+	}
+
+	// A DataView has no BYTES_PER_ELEMENT property, and its element size is 1.
+	elementSize := 1
+	if bpe := obj.Get("BYTES_PER_ELEMENT"); !common.IsNullish(bpe) {
+		elementSize = int(bpe.ToInteger())
+	}
+
+	return arrayBufferView{
+		buffer:      buffer,
+		byteOffset:  int(obj.Get("byteOffset").ToInteger()),
+		byteLength:  int(obj.Get("byteLength").ToInteger()),
+		elementSize: elementSize,
+		constructor: obj.Get("constructor"),
+	}, true
+}
+
+// isArrayBufferView returns true if the given value is a typed array or a DataView.
+func isArrayBufferView(rt *goja.Runtime, value goja.Value) bool {
+	if common.IsNullish(value) {
+		return false
+	}
+
+	isView, ok := goja.AssertFunction(rt.Get("ArrayBuffer").ToObject(rt).Get("isView"))
+	if !ok {
+		return false
+	}
+
+	res, err := isView(goja.Undefined(), value)
+	return err == nil && res.ToBoolean()
+}
+
+// newArrayBufferView instantiates a view of the given type over the given ArrayBuffer,
+// where length is expressed in elements of the view.
+func newArrayBufferView(
+	rt *goja.Runtime,
+	constructor goja.Value,
+	buffer goja.ArrayBuffer,
+	byteOffset, length int,
+) *goja.Object {
+	ctor, ok := goja.AssertConstructor(constructor)
+	if !ok {
+		throw(rt, newTypeError(rt, "the view's constructor is not a constructor"))
+	}
+
+	view, err := ctor(nil, rt.ToValue(buffer), rt.ToValue(byteOffset), rt.ToValue(length))
+	if err != nil {
+		throw(rt, err)
+	}
+
+	return view
+}
+
+// newUint8Array instantiates a Uint8Array over the given ArrayBuffer.
+func newUint8Array(rt *goja.Runtime, buffer goja.ArrayBuffer, byteOffset, byteLength int) *goja.Object {
+	return newArrayBufferView(rt, rt.Get("Uint8Array"), buffer, byteOffset, byteLength)
+}
+
+// transferArrayBuffer implements the [TransferArrayBuffer] abstract operation.
+//
+// It returns a new ArrayBuffer holding the same data, and detaches the given one,
+// so the data cannot be observed from it anymore.
+//
+// [TransferArrayBuffer]: https://streams.spec.whatwg.org/#transfer-array-buffer
+func transferArrayBuffer(rt *goja.Runtime, buffer goja.ArrayBuffer) (goja.ArrayBuffer, error) {
+	// 1. Assert: ! IsDetachedBuffer(O) is false.
+	if buffer.Detached() {
+		return goja.ArrayBuffer{}, newTypeError(rt, "cannot transfer a detached ArrayBuffer")
+	}
+
+	// 2. Let arrayBufferData be O.[[ArrayBufferData]].
+	data := buffer.Bytes()
+
+	// 4. Perform ? DetachArrayBuffer(O).
+	buffer.Detach()
+
+	// 5. Return a new ArrayBuffer object, created in the current Realm, whose
+	// [[ArrayBufferData]] internal slot value is arrayBufferData.
+	return rt.NewArrayBuffer(data), nil
+}
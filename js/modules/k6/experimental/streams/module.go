@@ -14,6 +14,12 @@ type (
 	// ModuleInstance is the module instance that will be created for each VU.
 	ModuleInstance struct {
 		vu modules.VU
+
+		// readableStreamPrototype and writableStreamPrototype are the prototypes of
+		// the streams, also used by the ones created by the module itself, e.g. the
+		// readable and writable sides of a TransformStream.
+		readableStreamPrototype *goja.Object
+		writableStreamPrototype *goja.Object
 	}
 )
 
@@ -37,13 +43,50 @@ func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 
 // Exports returns the module exports, that will be available in the runtime.
 func (mi *ModuleInstance) Exports() modules.Exports {
+	rt := mi.vu.Runtime()
+
+	readableStream := rt.ToValue(mi.NewReadableStream).ToObject(rt)
+	mi.readableStreamPrototype = readableStream.Get("prototype").ToObject(rt)
+
+	writableStream := rt.ToValue(mi.NewWritableStream).ToObject(rt)
+	mi.writableStreamPrototype = writableStream.Get("prototype").ToObject(rt)
+
+	defineLockedGetter(rt, mi.readableStreamPrototype, func(v goja.Value) (bool, bool) {
+		rs, ok := exportStream(v).(*ReadableStream)
+		return ok && rs.isLocked(), ok
+	})
+	defineLockedGetter(rt, mi.writableStreamPrototype, func(v goja.Value) (bool, bool) {
+		ws, ok := exportStream(v).(*WritableStream)
+		return ok && ws.isLocked(), ok
+	})
+
 	return modules.Exports{Named: map[string]interface{}{
-		"ReadableStream":              mi.NewReadableStream,
+		"ReadableStream":              readableStream,
 		"CountQueuingStrategy":        mi.NewCountQueuingStrategy,
 		"ReadableStreamDefaultReader": mi.NewReadableStreamDefaultReader,
+		"ReadableStreamBYOBReader":    mi.NewReadableStreamBYOBReader,
+		"ByteLengthQueuingStrategy":   mi.NewByteLengthQueuingStrategy,
+		"WritableStream":              writableStream,
+		"WritableStreamDefaultWriter": mi.NewWritableStreamDefaultWriter,
+		"TransformStream":             mi.NewTransformStream,
 	}}
 }
 
+// defineLockedGetter defines the `locked` getter on the given prototype of streams,
+// which throws a TypeError if isLocked says its receiver isn't a stream.
+func defineLockedGetter(rt *goja.Runtime, proto *goja.Object, isLocked func(goja.Value) (locked, ok bool)) {
+	err := proto.DefineAccessorProperty("locked", rt.ToValue(func(call goja.FunctionCall) goja.Value {
+		locked, ok := isLocked(call.This)
+		if !ok {
+			throw(rt, newTypeError(rt, "locked getter called on an invalid object"))
+		}
+		return rt.ToValue(locked)
+	}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE)
+	if err != nil {
+		common.Throw(rt, newError(RuntimeError, err.Error()))
+	}
+}
+
 // NewReadableStream is the constructor for the ReadableStream object.
 func (mi *ModuleInstance) NewReadableStream(call goja.ConstructorCall) *goja.Object {
 	rt := mi.vu.Runtime()
@@ -85,8 +128,21 @@ func (mi *ModuleInstance) NewReadableStream(call goja.ConstructorCall) *goja.Obj
 	stream.initialize()
 
 	// 4. If underlyingSourceDict["type"] is "bytes":
-	if underlyingSourceDict.Type == "bytes" {
-		common.Throw(stream.runtime, newError(NotSupportedError, "'bytes' stream is not supported yet"))
+	if underlyingSourceDict.Type == ReadableStreamTypeBytes {
+		// 4.1. If strategy["size"] exists, throw a RangeError exception.
+		if !common.IsNullish(strategy.Get("size")) {
+			throw(rt, newRangeError(rt, "size must not be set for byte streams"))
+		}
+
+		// 4.2. Let highWaterMark be ? ExtractHighWaterMark(strategy, 0).
+		highWaterMark := extractHighWaterMark(rt, strategy, 0)
+
+		// 4.3. Perform ? SetUpReadableByteStreamControllerFromUnderlyingSource(...).
+		stream.setupReadableByteStreamControllerFromUnderlyingSource(
+			underlyingSource,
+			underlyingSourceDict,
+			highWaterMark,
+		)
 	} else { // 5. Otherwise,
 		// 5.1. Assert: underlyingSourceDict["type"] does not exist.
 		if underlyingSourceDict.Type != "" {
@@ -108,39 +164,34 @@ func (mi *ModuleInstance) NewReadableStream(call goja.ConstructorCall) *goja.Obj
 		)
 	}
 
-	streamObj := rt.ToValue(stream).ToObject(rt)
-
-	proto := call.This.Prototype()
-	if proto.Get("locked") == nil {
-		err = proto.DefineAccessorProperty("locked", rt.ToValue(func() goja.Value {
-			return rt.ToValue(stream.Locked)
-		}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE)
-		if err != nil {
-			common.Throw(rt, newError(RuntimeError, err.Error()))
-		}
-	}
-
-	err = streamObj.SetPrototype(proto)
-	if err != nil {
-		common.Throw(rt, newError(RuntimeError, err.Error()))
-	}
-
-	return streamObj
+	stream.prototype = call.This.Prototype()
+	return newStreamObject(rt, stream, stream.prototype)
 }
 func defaultSizeFunc(_ goja.Value) (float64, error) { return 1.0, nil }
 
 func (mi *ModuleInstance) initializeStrategy(call goja.ConstructorCall) *goja.Object {
 	runtime := mi.vu.Runtime()
 
+	isBytes := false
+	if len(call.Arguments) > 0 && !common.IsNullish(call.Arguments[0]) {
+		srcArg := call.Arguments[0].ToObject(runtime)
+		srcTypeArg := srcArg.Get("type")
+		isBytes = !common.IsNullish(srcTypeArg) && srcTypeArg.String() == ReadableStreamTypeBytes
+	}
+
 	// Either if the strategy is not provided or if it doesn't have a 'highWaterMark',
-	// we need to set its default value (highWaterMark=1).
+	// we need to set its default value (highWaterMark=1, or 0 for byte streams).
 	// https://streams.spec.whatwg.org/#rs-prototype
 	strArg := runtime.NewObject()
 	if len(call.Arguments) > 1 && !common.IsNullish(call.Arguments[1]) {
 		strArg = call.Arguments[1].ToObject(runtime)
 	}
 	if common.IsNullish(strArg.Get("highWaterMark")) {
-		if err := strArg.Set("highWaterMark", runtime.ToValue(1)); err != nil {
+		defaultHWM := 1
+		if isBytes {
+			defaultHWM = 0
+		}
+		if err := strArg.Set("highWaterMark", runtime.ToValue(defaultHWM)); err != nil {
 			common.Throw(runtime, newError(RuntimeError, err.Error()))
 		}
 	}
@@ -148,12 +199,8 @@ func (mi *ModuleInstance) initializeStrategy(call goja.ConstructorCall) *goja.Ob
 	// If the stream type is 'bytes', we don't want the size function.
 	// Except, when it is manually specified.
 	size := runtime.ToValue(defaultSizeFunc)
-	if len(call.Arguments) > 0 && !common.IsNullish(call.Arguments[0]) {
-		srcArg := call.Arguments[0].ToObject(runtime)
-		srcTypeArg := srcArg.Get("type")
-		if !common.IsNullish(srcTypeArg) && srcTypeArg.String() == ReadableStreamTypeBytes {
-			size = nil
-		}
+	if isBytes {
+		size = nil
 	}
 	if strArg.Get("size") != nil {
 		size = strArg.Get("size")
@@ -181,9 +228,40 @@ func (mi *ModuleInstance) newCountQueuingStrategy(
 	rt *goja.Runtime,
 	call goja.ConstructorCall,
 	size goja.Value,
+) *goja.Object {
+	return newQueuingStrategy(rt, "CountQueuingStrategy", call, size)
+}
+
+// NewByteLengthQueuingStrategy is the constructor for the [ByteLengthQueuingStrategy] object.
+//
+// [ByteLengthQueuingStrategy]: https://streams.spec.whatwg.org/#blqs-class
+func (mi *ModuleInstance) NewByteLengthQueuingStrategy(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+	return newQueuingStrategy(rt, "ByteLengthQueuingStrategy", call, rt.ToValue(byteLengthSizeFunc))
+}
+
+// byteLengthSizeFunc is the size function of the [ByteLengthQueuingStrategy], which
+// returns the byteLength of the given chunk.
+//
+// [ByteLengthQueuingStrategy]: https://streams.spec.whatwg.org/#blqs-internal-slots
+func byteLengthSizeFunc(call goja.FunctionCall, rt *goja.Runtime) goja.Value {
+	chunk := call.Argument(0)
+	if common.IsNullish(chunk) {
+		throw(rt, newTypeError(rt, "chunk must have a 'byteLength' property"))
+	}
+
+	return chunk.ToObject(rt).Get("byteLength")
+}
+
+// newQueuingStrategy is the underlying constructor for the queuing strategy objects,
+// which are made of a 'highWaterMark' property and, optionally, a 'size' function.
+func newQueuingStrategy(
+	rt *goja.Runtime,
+	objName string,
+	call goja.ConstructorCall,
+	size goja.Value,
 ) *goja.Object {
 	obj := rt.NewObject()
-	objName := "CountQueuingStrategy"
 
 	if len(call.Arguments) != 1 {
 		throw(rt, newTypeError(rt, objName+" takes a single argument"))
@@ -269,7 +347,7 @@ func (mi *ModuleInstance) NewReadableStreamDefaultReader(call goja.ConstructorCa
 		throw(rt, newTypeError(rt, "ReadableStreamDefaultReader takes a single argument"))
 	}
 
-	stream, ok := call.Argument(0).Export().(*ReadableStream)
+	stream, ok := exportStream(call.Argument(0)).(*ReadableStream)
 	if !ok {
 		throw(rt, newTypeError(rt, "ReadableStreamDefaultReader argument must be a ReadableStream"))
 	}
@@ -285,3 +363,209 @@ func (mi *ModuleInstance) NewReadableStreamDefaultReader(call goja.ConstructorCa
 
 	return object
 }
+
+// NewReadableStreamBYOBReader is the constructor for the [ReadableStreamBYOBReader] object.
+//
+// [ReadableStreamBYOBReader]: https://streams.spec.whatwg.org/#byob-reader-class
+func (mi *ModuleInstance) NewReadableStreamBYOBReader(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+
+	if len(call.Arguments) != 1 {
+		throw(rt, newTypeError(rt, "ReadableStreamBYOBReader takes a single argument"))
+	}
+
+	stream, ok := exportStream(call.Argument(0)).(*ReadableStream)
+	if !ok {
+		throw(rt, newTypeError(rt, "ReadableStreamBYOBReader argument must be a ReadableStream"))
+	}
+
+	// 1. Perform ? SetUpReadableStreamBYOBReader(this, stream).
+	reader := &ReadableStreamBYOBReader{}
+	reader.setup(stream)
+
+	object, err := NewReadableStreamBYOBReaderObject(reader)
+	if err != nil {
+		throw(rt, err)
+	}
+
+	return object
+}
+
+// NewWritableStream is the constructor for the [WritableStream] object.
+//
+// [WritableStream]: https://streams.spec.whatwg.org/#ws-class
+func (mi *ModuleInstance) NewWritableStream(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+	var err error
+
+	// 1. If underlyingSink is missing, set it to null.
+	var underlyingSink *goja.Object
+
+	var underlyingSinkDict UnderlyingSink
+
+	// 2. Let underlyingSinkDict be underlyingSink, converted to an IDL value of type UnderlyingSink.
+	if len(call.Arguments) > 0 && !goja.IsUndefined(call.Arguments[0]) {
+		if !isObject(call.Arguments[0]) {
+			throw(rt, newTypeError(rt, "underlyingSink must be an object"))
+		}
+
+		underlyingSink = call.Arguments[0].ToObject(rt)
+		underlyingSinkDict, err = NewUnderlyingSinkFromObject(rt, underlyingSink)
+		if err != nil {
+			throw(rt, err)
+		}
+	}
+
+	strategy := rt.NewObject()
+	if len(call.Arguments) > 1 && !common.IsNullish(call.Arguments[1]) {
+		strategy = call.Arguments[1].ToObject(rt)
+	}
+
+	// 3. If underlyingSinkDict["type"] exists, throw a RangeError exception.
+	// This is already enforced by NewUnderlyingSinkFromObject.
+
+	// 4. Perform ! InitializeWritableStream(this).
+	stream := &WritableStream{
+		runtime: rt,
+		vu:      mi.vu,
+	}
+	stream.initialize()
+
+	// 5. Let sizeAlgorithm be ! ExtractSizeAlgorithm(strategy).
+	sizeAlgorithm := extractSizeAlgorithm(rt, strategy)
+
+	// 6. Let highWaterMark be ? ExtractHighWaterMark(strategy, 1).
+	highWaterMark := extractHighWaterMark(rt, strategy, 1)
+
+	// 7. Perform ? SetUpWritableStreamDefaultControllerFromUnderlyingSink(...).
+	stream.setupWritableStreamDefaultControllerFromUnderlyingSink(
+		underlyingSink,
+		underlyingSinkDict,
+		highWaterMark,
+		sizeAlgorithm,
+	)
+
+	stream.prototype = call.This.Prototype()
+	return newStreamObject(rt, stream, stream.prototype)
+}
+
+// NewWritableStreamDefaultWriter is the constructor for the [WritableStreamDefaultWriter] object.
+//
+// [WritableStreamDefaultWriter]: https://streams.spec.whatwg.org/#default-writer-class
+func (mi *ModuleInstance) NewWritableStreamDefaultWriter(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+
+	if len(call.Arguments) != 1 {
+		throw(rt, newTypeError(rt, "WritableStreamDefaultWriter takes a single argument"))
+	}
+
+	stream, ok := exportStream(call.Argument(0)).(*WritableStream)
+	if !ok {
+		throw(rt, newTypeError(rt, "WritableStreamDefaultWriter argument must be a WritableStream"))
+	}
+
+	// 1. Perform ? SetUpWritableStreamDefaultWriter(this, stream).
+	writer := &WritableStreamDefaultWriter{}
+	writer.setup(stream)
+
+	object, err := NewWritableStreamDefaultWriterObject(writer)
+	if err != nil {
+		throw(rt, err)
+	}
+
+	return object
+}
+
+// NewTransformStream is the constructor for the [TransformStream] object.
+//
+// [TransformStream]: https://streams.spec.whatwg.org/#ts-class
+func (mi *ModuleInstance) NewTransformStream(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+	var err error
+
+	// 1. If transformer is missing, set it to null.
+	var transformer *goja.Object
+
+	// 2. Let transformerDict be transformer, converted to an IDL value of type Transformer.
+	var transformerDict Transformer
+	if len(call.Arguments) > 0 && !goja.IsUndefined(call.Arguments[0]) {
+		if !isObject(call.Arguments[0]) {
+			throw(rt, newTypeError(rt, "transformer must be an object"))
+		}
+
+		transformer = call.Arguments[0].ToObject(rt)
+
+		// 3. If transformerDict["readableType"] exists, throw a RangeError exception.
+		// 4. If transformerDict["writableType"] exists, throw a RangeError exception.
+		transformerDict, err = NewTransformerFromObject(rt, transformer)
+		if err != nil {
+			throw(rt, err)
+		}
+	}
+
+	writableStrategy := rt.NewObject()
+	if len(call.Arguments) > 1 && !common.IsNullish(call.Arguments[1]) {
+		writableStrategy = call.Arguments[1].ToObject(rt)
+	}
+
+	readableStrategy := rt.NewObject()
+	if len(call.Arguments) > 2 && !common.IsNullish(call.Arguments[2]) {
+		readableStrategy = call.Arguments[2].ToObject(rt)
+	}
+
+	// 5. Let readableHighWaterMark be ? ExtractHighWaterMark(readableStrategy, 0).
+	readableHighWaterMark := extractHighWaterMark(rt, readableStrategy, 0)
+
+	// 6. Let readableSizeAlgorithm be ! ExtractSizeAlgorithm(readableStrategy).
+	readableSizeAlgorithm := extractSizeAlgorithm(rt, readableStrategy)
+
+	// 7. Let writableHighWaterMark be ? ExtractHighWaterMark(writableStrategy, 1).
+	writableHighWaterMark := extractHighWaterMark(rt, writableStrategy, 1)
+
+	// 8. Let writableSizeAlgorithm be ! ExtractSizeAlgorithm(writableStrategy).
+	writableSizeAlgorithm := extractSizeAlgorithm(rt, writableStrategy)
+
+	// 9. Let startPromise be a new promise.
+	startPromise := newPromiseCapability(rt)
+
+	// 10. Perform ! InitializeTransformStream(this, startPromise, writableHighWaterMark,
+	// writableSizeAlgorithm, readableHighWaterMark, readableSizeAlgorithm).
+	stream := &TransformStream{
+		runtime: rt,
+		vu:      mi.vu,
+	}
+	stream.initialize(
+		startPromise.promise,
+		writableHighWaterMark,
+		writableSizeAlgorithm,
+		readableHighWaterMark,
+		readableSizeAlgorithm,
+	)
+
+	// 11. Perform ? SetUpTransformStreamDefaultControllerFromTransformer(this, transformer, transformerDict).
+	stream.setupTransformStreamDefaultControllerFromTransformer(transformer, transformerDict)
+
+	// 12. If transformerDict["start"] exists, then resolve startPromise with the result of invoking
+	// transformerDict["start"] with argument list « this.[[controller]] » and callback this value transformer.
+	// 13. Otherwise, resolve startPromise with undefined.
+	if transformerDict.startSet {
+		start, _ := goja.AssertFunction(transformerDict.Start)
+		result, err := start(transformer, stream.controller.toObject())
+		if err != nil {
+			panic(err)
+		}
+		startPromise.resolve(result)
+	} else {
+		startPromise.resolve(goja.Undefined())
+	}
+
+	stream.readable.prototype = mi.readableStreamPrototype
+	stream.writable.prototype = mi.writableStreamPrototype
+
+	object, err := NewTransformStreamObject(stream)
+	if err != nil {
+		common.Throw(rt, newError(RuntimeError, err.Error()))
+	}
+
+	return object
+}
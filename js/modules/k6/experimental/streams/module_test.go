@@ -0,0 +1,490 @@
+package streams
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+)
+
+func TestWritableStream(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"writes are forwarded to the sink in order": `
+			const written = [];
+			let closed = false;
+			const stream = new WritableStream({
+				write(chunk) { written.push(chunk); },
+				close() { closed = true; },
+			});
+
+			const writer = stream.getWriter();
+			if (!stream.locked) {
+				throw 'expected the stream to be locked';
+			}
+
+			writer.write('a');
+			writer.write('b');
+			await writer.close();
+
+			if (written.join() !== 'a,b' || !closed) {
+				throw 'unexpected sink state: ' + written + ', closed: ' + closed;
+			}
+		`,
+		"backpressure is reported through desiredSize": `
+			const stream = new WritableStream({
+				write() { return new Promise(() => {}); },
+			}, new CountQueuingStrategy({ highWaterMark: 2 }));
+
+			const writer = stream.getWriter();
+			await writer.ready;
+			if (writer.desiredSize !== 2) {
+				throw 'unexpected desiredSize ' + writer.desiredSize;
+			}
+
+			writer.write('a');
+			writer.write('b');
+			if (writer.desiredSize !== 0) {
+				throw 'unexpected desiredSize ' + writer.desiredSize;
+			}
+		`,
+		"abort rejects the pending writes": `
+			let abortReason;
+			const stream = new WritableStream({
+				write() { return new Promise(() => {}); },
+				abort(reason) { abortReason = reason; },
+			});
+
+			const writer = stream.getWriter();
+			await writer.ready;
+			writer.write('a');
+			const pending = writer.write('b');
+
+			await writer.abort('bye');
+			try {
+				await pending;
+				throw 'expected the pending write to be rejected';
+			} catch (e) {
+				if (e !== 'bye') {
+					throw 'unexpected rejection ' + e;
+				}
+			}
+
+			if (abortReason !== 'bye') {
+				throw 'unexpected abort reason ' + abortReason;
+			}
+		`,
+		"a released writer rejects writes": `
+			const writer = new WritableStream().getWriter();
+			writer.releaseLock();
+
+			try {
+				await writer.write('a');
+				throw 'expected the write to be rejected';
+			} catch (e) {
+				if (!(e instanceof TypeError)) {
+					throw 'unexpected rejection ' + e;
+				}
+			}
+		`,
+		"the sink type is reserved": `
+			try {
+				new WritableStream({ type: 'bytes' });
+				throw 'expected the constructor to throw';
+			} catch (e) {
+				if (!(e instanceof RangeError)) {
+					throw 'unexpected exception ' + e;
+				}
+			}
+		`,
+	}
+
+	for name, script := range tests {
+		script := script
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newTestRuntime(t).RunOnEventLoop(wrapInAsyncLambda(script))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestTransformStream(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"chunks are transformed": `
+			const stream = new TransformStream({
+				transform(chunk, controller) { controller.enqueue(chunk.toUpperCase()); },
+				flush(controller) { controller.enqueue('!'); },
+			});
+
+			const writer = stream.writable.getWriter();
+			writer.write('a');
+			writer.write('b');
+			writer.close();
+
+			const chunks = await readAll(stream.readable);
+			if (chunks.join('') !== 'AB!') {
+				throw 'unexpected chunks ' + chunks;
+			}
+		`,
+		"the identity transform is used by default": `
+			const stream = new TransformStream();
+			const writer = stream.writable.getWriter();
+			writer.write(1);
+			writer.close();
+
+			const chunks = await readAll(stream.readable);
+			if (chunks.join() !== '1') {
+				throw 'unexpected chunks ' + chunks;
+			}
+		`,
+		"terminate closes the readable side and errors the writable side": `
+			const stream = new TransformStream({
+				transform(chunk, controller) { controller.terminate(); },
+			});
+
+			const writer = stream.writable.getWriter();
+			writer.write('a').catch(() => {});
+
+			const chunks = await readAll(stream.readable);
+			if (chunks.length !== 0) {
+				throw 'unexpected chunks ' + chunks;
+			}
+
+			try {
+				await writer.closed;
+				throw 'expected the writable side to be errored';
+			} catch (e) {
+				if (!(e instanceof TypeError)) {
+					throw 'unexpected rejection ' + e;
+				}
+			}
+		`,
+		"a transform error errors both sides": `
+			const stream = new TransformStream({
+				transform() { throw 'boom'; },
+			});
+
+			const writer = stream.writable.getWriter();
+			try {
+				await writer.write('a');
+				throw 'expected the write to be rejected';
+			} catch (e) {
+				if (e !== 'boom') {
+					throw 'unexpected rejection ' + e;
+				}
+			}
+
+			try {
+				await stream.readable.getReader().read();
+				throw 'expected the read to be rejected';
+			} catch (e) {
+				if (e !== 'boom') {
+					throw 'unexpected rejection ' + e;
+				}
+			}
+		`,
+	}
+
+	for name, script := range tests {
+		script := script
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newTestRuntime(t).RunOnEventLoop(wrapInAsyncLambda(script))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestReadableStreamPipeAndTee(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"pipeThrough and pipeTo": `
+			let i = 0;
+			const source = new ReadableStream({
+				pull(controller) {
+					if (i < 5) {
+						controller.enqueue('c' + i++);
+					} else {
+						controller.close();
+					}
+				},
+			});
+
+			const written = [];
+			await source
+				.pipeThrough(new TransformStream({
+					transform(chunk, controller) { controller.enqueue(chunk.toUpperCase()); },
+				}))
+				.pipeTo(new WritableStream({ write(chunk) { written.push(chunk); } }));
+
+			if (written.join() !== 'C0,C1,C2,C3,C4') {
+				throw 'unexpected chunks ' + written;
+			}
+
+			if (source.locked) {
+				throw 'expected the source to be unlocked once piped';
+			}
+		`,
+		"pipeTo propagates errors backward": `
+			let cancelReason;
+			const source = new ReadableStream({
+				pull(controller) { controller.enqueue('a'); },
+				cancel(reason) { cancelReason = reason; },
+			});
+
+			try {
+				await source.pipeTo(new WritableStream({ write() { throw 'boom'; } }));
+				throw 'expected pipeTo to be rejected';
+			} catch (e) {
+				if (e !== 'boom') {
+					throw 'unexpected rejection ' + e;
+				}
+			}
+
+			if (cancelReason !== 'boom') {
+				throw 'unexpected cancel reason ' + cancelReason;
+			}
+		`,
+		"pipeTo rejects the signal option": `
+			try {
+				await new ReadableStream().pipeTo(new WritableStream(), { signal: {} });
+				throw 'expected pipeTo to be rejected';
+			} catch (e) {
+				if (e.name !== 'NotSupportedError') {
+					throw 'unexpected rejection ' + e;
+				}
+			}
+		`,
+		"tee of a default stream": `
+			const stream = new ReadableStream({
+				start(controller) {
+					controller.enqueue(1);
+					controller.enqueue(2);
+					controller.close();
+				},
+			});
+
+			const [branch1, branch2] = stream.tee();
+			const [chunks1, chunks2] = await Promise.all([readAll(branch1), readAll(branch2)]);
+			if (chunks1.join() !== '1,2' || chunks2.join() !== '1,2') {
+				throw 'unexpected chunks ' + chunks1 + ' and ' + chunks2;
+			}
+		`,
+		"tee of a byte stream": `
+			const stream = new ReadableStream({
+				type: 'bytes',
+				start(controller) {
+					controller.enqueue(new Uint8Array([1, 2, 3, 4]));
+					controller.close();
+				},
+			});
+
+			const [branch1, branch2] = stream.tee();
+
+			const { value, done } = await branch1.getReader({ mode: 'byob' }).read(new Uint8Array(2));
+			if (done || Array.from(value).join() !== '1,2') {
+				throw 'unexpected BYOB read ' + value;
+			}
+
+			const chunks = await readAll(branch2);
+			if (chunks.map(c => Array.from(c).join()).join('|') !== '1,2,3,4') {
+				throw 'unexpected chunks ' + chunks;
+			}
+		`,
+	}
+
+	for name, script := range tests {
+		script := script
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newTestRuntime(t).RunOnEventLoop(wrapInAsyncLambda(script))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestReadableByteStream(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"BYOB reads are fulfilled from the queue": `
+			const stream = new ReadableStream({
+				type: 'bytes',
+				start(controller) {
+					controller.enqueue(new Uint8Array([1, 2, 3]));
+					controller.close();
+				},
+			});
+
+			const reader = stream.getReader({ mode: 'byob' });
+			const first = await reader.read(new Uint8Array(2));
+			const second = await reader.read(new Uint8Array(2));
+			const last = await reader.read(new Uint8Array(2));
+
+			if (Array.from(first.value).join() !== '1,2' || Array.from(second.value).join() !== '3' || !last.done) {
+				throw 'unexpected reads ' + first.value + ', ' + second.value + ', ' + last.done;
+			}
+		`,
+		"BYOB requests are responded by the source": `
+			const stream = new ReadableStream({
+				type: 'bytes',
+				pull(controller) {
+					const view = controller.byobRequest.view;
+					view[0] = 42;
+					controller.byobRequest.respond(1);
+				},
+			});
+
+			const { value } = await stream.getReader({ mode: 'byob' }).read(new Uint8Array(4));
+			if (value.length !== 1 || value[0] !== 42) {
+				throw 'unexpected read ' + value;
+			}
+		`,
+		"ByteLengthQueuingStrategy": `
+			const strategy = new ByteLengthQueuingStrategy({ highWaterMark: 16 });
+			if (strategy.highWaterMark !== 16 || strategy.size(new Uint8Array(4)) !== 4) {
+				throw 'unexpected strategy ' + strategy.highWaterMark;
+			}
+		`,
+	}
+
+	for name, script := range tests {
+		script := script
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newTestRuntime(t).RunOnEventLoop(wrapInAsyncLambda(script))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestStreamObjects(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"streams accept other properties": `
+			const streams = [new ReadableStream(), new WritableStream(), ...new ReadableStream().tee()];
+			for (const stream of streams) {
+				stream.events = [];
+				stream.events.push('a');
+				if (stream.events.join() !== 'a' || Object.keys(stream).join() !== 'events') {
+					throw 'unexpected properties ' + Object.keys(stream);
+				}
+				if (typeof stream.locked !== 'boolean') {
+					throw 'expected the locked property to be kept';
+				}
+			}
+		`,
+		"streams created by the module have the prototypes of the streams": `
+			const [branch1, branch2] = new ReadableStream().tee();
+			const transform = new TransformStream();
+			const readables = [branch1, branch2, transform.readable];
+			if (!readables.every((stream) => stream instanceof ReadableStream)) {
+				throw 'expected ReadableStream instances';
+			}
+			if (!(transform.writable instanceof WritableStream)) {
+				throw 'expected a WritableStream instance';
+			}
+		`,
+		"the locked getters check their receiver": `
+			const stream = new ReadableStream();
+			stream.getReader();
+			const getters = [ReadableStream, WritableStream]
+				.map((c) => Object.getOwnPropertyDescriptor(c.prototype, 'locked').get);
+			if (getters[0].call(stream) !== true || getters[1].call(new WritableStream()) !== false) {
+				throw 'unexpected locked values';
+			}
+			for (const getter of getters) {
+				try {
+					getter.call({});
+					throw 'expected the locked getter to throw';
+				} catch (e) {
+					if (!(e instanceof TypeError)) {
+						throw e;
+					}
+				}
+			}
+		`,
+	}
+
+	for name, script := range tests {
+		script := script
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newTestRuntime(t).RunOnEventLoop(wrapInAsyncLambda(script))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewReadableStreamFromReader(t *testing.T) {
+	t.Parallel()
+
+	content := strings.Repeat("k6", readerChunkSize)
+
+	runtime := newTestRuntime(t)
+	require.NoError(t, runtime.VU.RuntimeField.Set("newStream", func() *goja.Object {
+		return NewReadableStreamFromReader(runtime.VU, strings.NewReader(content))
+	}))
+
+	_, err := runtime.RunOnEventLoop(wrapInAsyncLambda(`
+		const chunks = await readAll(newStream());
+		const total = chunks.reduce((size, chunk) => size + chunk.length, 0);
+		if (total !== ` + "2 * " + `65536) {
+			throw 'unexpected total size ' + total;
+		}
+
+		const reader = newStream().getReader({ mode: 'byob' });
+		const { value } = await reader.read(new Uint8Array(3));
+		if (String.fromCharCode(...value) !== 'k6k') {
+			throw 'unexpected BYOB read ' + value;
+		}
+		await reader.cancel();
+	`))
+	assert.NoError(t, err)
+}
+
+func newTestRuntime(t testing.TB) *modulestest.Runtime {
+	runtime := modulestest.NewRuntime(t)
+
+	m := new(RootModule).NewModuleInstance(runtime.VU)
+	for k, v := range m.Exports().Named {
+		require.NoError(t, runtime.VU.RuntimeField.Set(k, v))
+	}
+
+	_, err := runtime.VU.Runtime().RunString(`
+		async function readAll(stream) {
+			const reader = stream.getReader();
+			const chunks = [];
+			while (true) {
+				const { done, value } = await reader.read();
+				if (done) {
+					return chunks;
+				}
+				chunks.push(value);
+			}
+		}
+	`)
+	require.NoError(t, err)
+
+	return runtime
+}
+
+func wrapInAsyncLambda(input string) string {
+	// This makes it possible to use `await` freely on the "top" level
+	return "(async () => {\n " + input + "\n })()"
+}
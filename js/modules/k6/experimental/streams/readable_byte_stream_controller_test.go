@@ -0,0 +1,23 @@
+//go:build wpt
+
+package streams
+
+import "testing"
+
+func TestReadableByteStreamWPT(t *testing.T) {
+	t.Parallel()
+
+	// Excluded suites:
+	//  - bad-buffers-and-views.any.js and enqueue-with-detached-buffer.any.js: they
+	//    detach the buffers with structuredClone(), which k6 doesn't support.
+	//  - construct-byob-request.any.js: the ReadableByteStreamController and
+	//    ReadableStreamBYOBRequest interfaces aren't exported by the module.
+	//  - non-transferable-buffers.any.js: it uses WebAssembly, which k6 doesn't support.
+	suites := []string{
+		"general.any.js",
+		"respond-after-enqueue.any.js",
+		"tee.any.js",
+	}
+
+	runTestSuites(t, "tests/wpt/streams/readable-byte-streams", suites)
+}
@@ -0,0 +1,353 @@
+package streams
+
+import (
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+)
+
+// ReadableStreamBYOBReader represents a BYOB ("bring your own buffer") reader designed to
+// be vended by a readable byte stream, which reads directly into a buffer provided by the
+// consumer.
+//
+// [specification]: https://streams.spec.whatwg.org/#byob-reader-class
+type ReadableStreamBYOBReader struct {
+	BaseReadableStreamReader
+
+	// readIntoRequests holds a list of read-into requests, used when a consumer requests
+	// chunks sooner than they are available.
+	readIntoRequests []ReadIntoRequest
+}
+
+// ReadIntoRequest is a struct containing three algorithms to perform in reaction to filling
+// the readable byte stream's internal queue or changing its state.
+//
+// [specification]: https://streams.spec.whatwg.org/#read-into-request
+type ReadIntoRequest struct {
+	// chunkSteps is an algorithm taking a chunk, called when a chunk is available for reading.
+	chunkSteps func(chunk any)
+
+	// closeSteps is an algorithm taking a chunk or undefined, called when no chunks are available
+	// because the stream is closed.
+	closeSteps func(chunk any)
+
+	// errorSteps is an algorithm taking a JavaScript value, called when no chunks are available
+	// because the stream is errored.
+	errorSteps func(e any)
+}
+
+// NewReadableStreamBYOBReaderObject creates a new goja.Object from a [ReadableStreamBYOBReader] instance.
+func NewReadableStreamBYOBReaderObject(reader *ReadableStreamBYOBReader) (*goja.Object, error) {
+	rt := reader.stream.runtime
+	obj := rt.NewObject()
+	objName := "ReadableStreamBYOBReader"
+
+	err := obj.DefineAccessorProperty("closed", rt.ToValue(func() *goja.Promise {
+		p, _, _ := reader.GetClosed()
+		return p
+	}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setReadOnlyPropertyOf(obj, objName, "cancel", rt.ToValue(reader.Cancel)); err != nil {
+		return nil, err
+	}
+
+	if err := setReadOnlyPropertyOf(obj, objName, "read", rt.ToValue(reader.Read)); err != nil {
+		return nil, err
+	}
+
+	if err := setReadOnlyPropertyOf(obj, objName, "releaseLock", rt.ToValue(reader.ReleaseLock)); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// Read reads bytes into the given view, and returns a [goja.Promise] resolved with the
+// view, transferred, once it is filled with at least `options.min` elements.
+//
+// It implements the ReadableStreamBYOBReader.read(view, options) [specification] algorithm.
+//
+// [specification]: https://streams.spec.whatwg.org/#byob-reader-read
+func (reader *ReadableStreamBYOBReader) Read(view goja.Value, options *goja.Object) *goja.Promise {
+	rt := reader.runtime
+
+	v, ok := exportArrayBufferView(rt, view)
+	if !ok {
+		return newRejectedPromise(reader.vu, newTypeError(rt, "view must be an ArrayBufferView").Err())
+	}
+
+	// 1. If view.[[ByteLength]] is 0, return a promise rejected with a TypeError exception.
+	if v.byteLength == 0 {
+		return newRejectedPromise(reader.vu, newTypeError(rt, "view must not be empty").Err())
+	}
+
+	// 2. If view.[[ViewedArrayBuffer]].[[ArrayBufferByteLength]] is 0, return a promise
+	// rejected with a TypeError exception.
+	if len(v.buffer.Bytes()) == 0 {
+		return newRejectedPromise(reader.vu, newTypeError(rt, "view's buffer must not be empty").Err())
+	}
+
+	// 3. If ! IsDetachedBuffer(view.[[ViewedArrayBuffer]]) is true, return a promise
+	// rejected with a TypeError exception.
+	if v.buffer.Detached() {
+		return newRejectedPromise(reader.vu, newTypeError(rt, "view's buffer is detached").Err())
+	}
+
+	minimum := 1.0
+	if options != nil && !common.IsNullish(options.Get("min")) {
+		minimum = options.Get("min").ToFloat()
+	}
+
+	// 4. If options["min"] is 0, return a promise rejected with a TypeError exception.
+	// 5. If view has a [[TypedArrayName]] internal slot, if options["min"] > view.[[ArrayLength]],
+	// return a promise rejected with a RangeError exception.
+	// 6. Otherwise (i.e., it is a DataView), if options["min"] > view.[[ByteLength]], return a
+	// promise rejected with a RangeError exception.
+	if minimum <= 0 {
+		return newRejectedPromise(reader.vu, newTypeError(rt, "options.min must be greater than 0").Err())
+	}
+	if !isValidMinimumFill(v, minimum) {
+		return newRejectedPromise(reader.vu, newRangeError(rt, "options.min is out of range").Err())
+	}
+
+	// 7. If this.[[stream]] is undefined, return a promise rejected with a TypeError exception.
+	if reader.stream == nil {
+		return newRejectedPromise(reader.vu, newTypeError(rt, "stream is undefined").Err())
+	}
+
+	// 8. Let promise be a new promise.
+	promise, resolve, reject := rt.NewPromise()
+
+	// 9. Let readIntoRequest be a new read-into request with the following items:
+	readIntoRequest := ReadIntoRequest{
+		chunkSteps: func(chunk any) {
+			// Resolve promise with «[ "value" → chunk, "done" → false ]».
+			resolve(map[string]any{"value": chunk, "done": false})
+		},
+		closeSteps: func(chunk any) {
+			// Resolve promise with «[ "value" → chunk, "done" → true ]».
+			resolve(map[string]any{"value": chunk, "done": true})
+		},
+		errorSteps: func(e any) {
+			// Reject promise with e.
+			reject(e)
+		},
+	}
+
+	// 10. Perform ! ReadableStreamBYOBReaderRead(this, view, options["min"], readIntoRequest).
+	reader.read(v, int(minimum), readIntoRequest)
+
+	// 11. Return promise.
+	return promise
+}
+
+// Cancel returns a [goja.Promise] that resolves when the stream is canceled.
+func (reader *ReadableStreamBYOBReader) Cancel(reason goja.Value) *goja.Promise {
+	// 1. If this.[[stream]] is undefined, return a promise rejected with a TypeError exception.
+	if reader.stream == nil {
+		return newRejectedPromise(reader.vu, newTypeError(reader.runtime, "stream is undefined").Err())
+	}
+
+	// 2. Return ! ReadableStreamReaderGenericCancel(this, reason).
+	return reader.BaseReadableStreamReader.Cancel(reason)
+}
+
+// ReleaseLock releases the reader's lock on the stream.
+func (reader *ReadableStreamBYOBReader) ReleaseLock() {
+	// 1. If this.[[stream]] is undefined, return.
+	if reader.stream == nil {
+		return
+	}
+
+	// 2. Perform ! ReadableStreamBYOBReaderRelease(this).
+	reader.release()
+}
+
+// release implements the [ReadableStreamBYOBReaderRelease] algorithm.
+//
+// [ReadableStreamBYOBReaderRelease]: https://streams.spec.whatwg.org/#abstract-opdef-readablestreambyobreaderrelease
+func (reader *ReadableStreamBYOBReader) release() {
+	// 1. Perform ! ReadableStreamReaderGenericRelease(reader).
+	reader.BaseReadableStreamReader.release()
+
+	// 2. Let e be a new TypeError exception.
+	e := newTypeError(reader.runtime, "reader released")
+
+	// 3. Perform ! ReadableStreamBYOBReaderErrorReadIntoRequests(reader, e).
+	reader.errorReadIntoRequests(e.Err())
+}
+
+// setup implements the [SetUpReadableStreamBYOBReader] algorithm.
+//
+// [SetUpReadableStreamBYOBReader]: https://streams.spec.whatwg.org/#set-up-readable-stream-byob-reader
+func (reader *ReadableStreamBYOBReader) setup(stream *ReadableStream) {
+	rt := stream.runtime
+
+	// 1. If ! IsReadableStreamLocked(stream) is true, throw a TypeError exception.
+	if stream.isLocked() {
+		throw(rt, newTypeError(rt, "stream is locked"))
+	}
+
+	// 2. If stream.[[controller]] does not implement ReadableByteStreamController, throw a TypeError exception.
+	if _, ok := stream.controller.(*ReadableByteStreamController); !ok {
+		throw(rt, newTypeError(rt, "a BYOB reader can only be acquired for a byte stream"))
+	}
+
+	// 3. Perform ! ReadableStreamReaderGenericInitialize(reader, stream).
+	ReadableStreamReaderGenericInitialize(reader, stream)
+
+	// 4. Set reader.[[readIntoRequests]] to a new empty list.
+	reader.readIntoRequests = []ReadIntoRequest{}
+}
+
+// errorReadIntoRequests implements the [ReadableStreamBYOBReaderErrorReadIntoRequests] algorithm.
+//
+// [ReadableStreamBYOBReaderErrorReadIntoRequests]: https://streams.spec.whatwg.org/#abstract-opdef-readablestreambyobreadererrorreadintorequests
+func (reader *ReadableStreamBYOBReader) errorReadIntoRequests(e any) {
+	// 1. Let readIntoRequests be reader.[[readIntoRequests]].
+	readIntoRequests := reader.readIntoRequests
+
+	// 2. Set reader.[[readIntoRequests]] to a new empty list.
+	reader.readIntoRequests = []ReadIntoRequest{}
+
+	// 3. For each readIntoRequest of readIntoRequests,
+	for _, request := range readIntoRequests {
+		// 3.1. Perform readIntoRequest’s error steps, given e.
+		request.errorSteps(e)
+	}
+}
+
+// read implements the [ReadableStreamBYOBReaderRead] algorithm.
+//
+// [ReadableStreamBYOBReaderRead]: https://streams.spec.whatwg.org/#readable-stream-byob-reader-read
+func (reader *ReadableStreamBYOBReader) read(view arrayBufferView, minimum int, readIntoRequest ReadIntoRequest) {
+	// 1. Let stream be reader.[[stream]].
+	stream := reader.stream
+
+	// 2. Assert: stream is not undefined.
+	if stream == nil {
+		common.Throw(reader.runtime, newError(AssertionError, "stream is undefined"))
+	}
+
+	// 3. Set stream.[[disturbed]] to true.
+	stream.disturbed = true
+
+	// 4. If stream.[[state]] is "errored", perform readIntoRequest’s error steps given stream.[[storedError]].
+	if stream.state == ReadableStreamStateErrored {
+		readIntoRequest.errorSteps(reasonValue(stream.storedError))
+		return
+	}
+
+	// 5. Otherwise, perform ! ReadableByteStreamControllerPullInto(stream.[[controller]], view, min, readIntoRequest).
+	controller, ok := stream.controller.(*ReadableByteStreamController)
+	if !ok {
+		common.Throw(reader.runtime, newError(AssertionError, "controller is not a ReadableByteStreamController"))
+	}
+	controller.pullInto(view, minimum, readIntoRequest)
+}
+
+// ReadableStreamBYOBRequest represents a pull-into request in a [ReadableByteStreamController].
+//
+// [specification]: https://streams.spec.whatwg.org/#rs-byob-request-class
+type ReadableStreamBYOBRequest struct {
+	// controller is the parent [ReadableByteStreamController] instance.
+	controller *ReadableByteStreamController
+
+	// view is the destination region to which the controller can write generated data,
+	// or nil after the BYOB request has been invalidated.
+	view *goja.Object
+
+	runtime *goja.Runtime
+}
+
+// NewReadableStreamBYOBRequestObject creates a new goja.Object from a [ReadableStreamBYOBRequest] instance.
+func NewReadableStreamBYOBRequestObject(request *ReadableStreamBYOBRequest) (*goja.Object, error) {
+	rt := request.runtime
+	obj := rt.NewObject()
+	objName := "ReadableStreamBYOBRequest"
+
+	err := obj.DefineAccessorProperty("view", rt.ToValue(func() goja.Value {
+		if request.view == nil {
+			return goja.Null()
+		}
+		return request.view
+	}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setReadOnlyPropertyOf(obj, objName, "respond", rt.ToValue(request.Respond)); err != nil {
+		return nil, err
+	}
+
+	if err := setReadOnlyPropertyOf(obj, objName, "respondWithNewView", rt.ToValue(request.RespondWithNewView)); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// Respond signals to the associated readable byte stream that bytesWritten bytes were
+// written into the view.
+//
+// It implements the ReadableStreamBYOBRequest.respond(bytesWritten) [specification] algorithm.
+//
+// [specification]: https://streams.spec.whatwg.org/#rs-byob-request-respond
+func (request *ReadableStreamBYOBRequest) Respond(bytesWritten goja.Value) {
+	rt := request.runtime
+
+	// 1. If this.[[controller]] is undefined, throw a TypeError exception.
+	if request.controller == nil {
+		throw(rt, newTypeError(rt, "the BYOB request has been invalidated"))
+	}
+
+	if !isNonNegativeNumber(bytesWritten) {
+		throw(rt, newTypeError(rt, "bytesWritten must be a non-negative number"))
+	}
+
+	// 2. If ! IsDetachedBuffer(this.[[view]].[[ArrayBuffer]]) is true, throw a TypeError exception.
+	view, ok := exportArrayBufferView(rt, request.view)
+	if !ok || view.buffer.Detached() {
+		throw(rt, newTypeError(rt, "the BYOB request's buffer is detached"))
+	}
+
+	// 3. Assert: this.[[view]].[[ByteLength]] > 0.
+	// 4. Assert: this.[[view]].[[ViewedArrayBuffer]].[[ByteLength]] > 0.
+	// 5. Perform ? ReadableByteStreamControllerRespond(this.[[controller]], bytesWritten).
+	if err := request.controller.respond(int(bytesWritten.ToInteger())); err != nil {
+		throw(rt, err)
+	}
+}
+
+// RespondWithNewView signals to the associated readable byte stream that, instead of
+// writing into the view, the underlying byte source is providing a new ArrayBufferView,
+// which will be given to the consumer.
+//
+// It implements the ReadableStreamBYOBRequest.respondWithNewView(view) [specification] algorithm.
+//
+// [specification]: https://streams.spec.whatwg.org/#rs-byob-request-respond-with-new-view
+func (request *ReadableStreamBYOBRequest) RespondWithNewView(view goja.Value) {
+	rt := request.runtime
+
+	// 1. If this.[[controller]] is undefined, throw a TypeError exception.
+	if request.controller == nil {
+		throw(rt, newTypeError(rt, "the BYOB request has been invalidated"))
+	}
+
+	v, ok := exportArrayBufferView(rt, view)
+	if !ok {
+		throw(rt, newTypeError(rt, "view must be an ArrayBufferView"))
+	}
+
+	// 2. If ! IsDetachedBuffer(view.[[ViewedArrayBuffer]]) is true, throw a TypeError exception.
+	if v.buffer.Detached() {
+		throw(rt, newTypeError(rt, "the view's buffer is detached"))
+	}
+
+	// 3. Return ? ReadableByteStreamControllerRespondWithNewView(this.[[controller]], view).
+	if err := request.controller.respondWithNewView(v); err != nil {
+		throw(rt, err)
+	}
+}
@@ -0,0 +1,124 @@
+package streams
+
+import (
+	"errors"
+	"io"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+// readerChunkSize is the maximum number of bytes read from an [io.Reader] for each
+// pull of a readable stream created with [NewReadableStreamFromReader].
+const readerChunkSize = 64 * 1024
+
+// NewReadableStreamFromReader returns a readable byte stream, as a [goja.Object], whose
+// chunks are read from the given [io.Reader].
+//
+// The reads happen outside the event loop, one chunk at a time and only when the stream
+// is pulled, so the data is never fully buffered in memory. If the reader implements
+// [io.Closer], it is closed once the stream is canceled.
+//
+// It's meant to be used by other modules, willing to expose their data as a stream.
+func NewReadableStreamFromReader(vu modules.VU, reader io.Reader) *goja.Object {
+	rt := vu.Runtime()
+
+	var stream *ReadableStream
+
+	startAlgorithm := func(*goja.Object) goja.Value {
+		return goja.Undefined()
+	}
+
+	pullAlgorithm := func(*goja.Object) *goja.Promise {
+		controller, _ := stream.controller.(*ReadableByteStreamController)
+
+		// When a BYOB read is pending, we don't read more than what the reader asked for.
+		size := readerChunkSize
+		if req := controller.getBYOBRequest(); req != nil {
+			if view, ok := exportArrayBufferView(rt, req.view); ok && view.byteLength < size {
+				size = view.byteLength
+			}
+		}
+
+		promise := newPromiseCapability(rt)
+		callback := vu.RegisterCallback()
+
+		go func() {
+			buf := make([]byte, size)
+			n, err := reader.Read(buf)
+
+			callback(func() error {
+				if stream.state != ReadableStreamStateReadable {
+					promise.resolve(goja.Undefined())
+					return nil
+				}
+
+				if n > 0 {
+					controller.respondWithBytes(buf[:n])
+				}
+
+				switch {
+				case errors.Is(err, io.EOF):
+					if closeErr := controller.close(); closeErr != nil {
+						promise.reject(closeErr)
+						return nil
+					}
+
+					// A pending BYOB read is fulfilled with an empty view once the stream is closed.
+					if len(controller.pendingPullIntos) > 0 {
+						if respondErr := controller.respond(0); respondErr != nil {
+							promise.reject(respondErr)
+							return nil
+						}
+					}
+				case err != nil:
+					promise.reject(newError(RuntimeError, err.Error()))
+					return nil
+				}
+
+				promise.resolve(goja.Undefined())
+				return nil
+			})
+		}()
+
+		return promise.promise
+	}
+
+	cancelAlgorithm := func(any) goja.Value {
+		if closer, ok := reader.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				return rt.ToValue(newRejectedPromise(vu, newError(RuntimeError, err.Error())))
+			}
+		}
+
+		return rt.ToValue(newResolvedPromise(vu, goja.Undefined()))
+	}
+
+	stream = createReadableByteStream(vu, startAlgorithm, pullAlgorithm, cancelAlgorithm)
+
+	return newStreamObject(rt, stream, nil)
+}
+
+// respondWithBytes hands the given bytes to the stream, either by filling the pending
+// BYOB request's view with them, or by enqueuing them as a new chunk.
+func (controller *ReadableByteStreamController) respondWithBytes(data []byte) {
+	rt := controller.stream.runtime
+
+	if req := controller.getBYOBRequest(); req != nil {
+		view, ok := exportArrayBufferView(rt, req.view)
+		if ok && view.byteLength >= len(data) {
+			copy(view.buffer.Bytes()[view.byteOffset:], data)
+			if err := controller.respond(len(data)); err != nil {
+				common.Throw(rt, err)
+			}
+			return
+		}
+	}
+
+	buffer := rt.NewArrayBuffer(data)
+	chunk, _ := exportArrayBufferView(rt, newUint8Array(rt, buffer, 0, len(data)))
+	if err := controller.enqueue(chunk); err != nil {
+		common.Throw(rt, err)
+	}
+}
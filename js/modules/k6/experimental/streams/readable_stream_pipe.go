@@ -0,0 +1,363 @@
+package streams
+
+import (
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+)
+
+// PipeTo pipes the stream to the given [WritableStream], and returns a promise that fulfills
+// when the piping process completes successfully, or rejects if any errors were encountered.
+//
+// It implements the ReadableStream.pipeTo(destination, options) [specification] algorithm.
+//
+// [specification]: https://streams.spec.whatwg.org/#rs-pipe-to
+func (stream *ReadableStream) PipeTo(destination goja.Value, options *goja.Object) *goja.Promise {
+	rt := stream.runtime
+
+	dest, ok := exportStream(destination).(*WritableStream)
+	if !ok {
+		return newRejectedPromise(stream.vu, newTypeError(rt, "destination must be a WritableStream").Err())
+	}
+
+	// 1. If ! IsReadableStreamLocked(this) is true, return a promise rejected with a TypeError exception.
+	if stream.isLocked() {
+		return newRejectedPromise(stream.vu, newTypeError(rt, "cannot pipe a locked stream").Err())
+	}
+
+	// 2. If ! IsWritableStreamLocked(destination) is true, return a promise rejected with a TypeError exception.
+	if dest.isLocked() {
+		return newRejectedPromise(stream.vu, newTypeError(rt, "cannot pipe to a locked stream").Err())
+	}
+
+	// 3. Let signal be options["signal"] if it exists, or undefined otherwise.
+	opts, err := newPipeOptions(options)
+	if err != nil {
+		return newRejectedPromise(stream.vu, reasonValue(err))
+	}
+
+	// 4. Return ! ReadableStreamPipeTo(this, destination, options["preventClose"], options["preventAbort"],
+	// options["preventCancel"], signal).
+	return stream.pipeTo(dest, opts)
+}
+
+// PipeThrough pipes the stream through the given transform stream, or any other
+// { writable, readable } pair, and returns its readable side.
+//
+// It implements the ReadableStream.pipeThrough(transform, options) [specification] algorithm.
+//
+// [specification]: https://streams.spec.whatwg.org/#rs-pipe-through
+func (stream *ReadableStream) PipeThrough(transform goja.Value, options *goja.Object) goja.Value {
+	rt := stream.runtime
+
+	if common.IsNullish(transform) {
+		throw(rt, newTypeError(rt, "transform must be an object with readable and writable properties"))
+	}
+
+	transformObj := transform.ToObject(rt)
+	readable := transformObj.Get("readable")
+	writableValue := transformObj.Get("writable")
+
+	if common.IsNullish(readable) {
+		throw(rt, newTypeError(rt, "transform.readable must be a ReadableStream"))
+	}
+	if _, ok := exportStream(readable).(*ReadableStream); !ok {
+		throw(rt, newTypeError(rt, "transform.readable must be a ReadableStream"))
+	}
+
+	if common.IsNullish(writableValue) {
+		throw(rt, newTypeError(rt, "transform.writable must be a WritableStream"))
+	}
+	writable, ok := exportStream(writableValue).(*WritableStream)
+	if !ok {
+		throw(rt, newTypeError(rt, "transform.writable must be a WritableStream"))
+	}
+
+	// 1. If ! IsReadableStreamLocked(this) is true, throw a TypeError exception.
+	if stream.isLocked() {
+		throw(rt, newTypeError(rt, "cannot pipe a locked stream"))
+	}
+
+	// 2. If ! IsWritableStreamLocked(transform["writable"]) is true, throw a TypeError exception.
+	if writable.isLocked() {
+		throw(rt, newTypeError(rt, "cannot pipe to a locked stream"))
+	}
+
+	// 3. Let signal be options["signal"] if it exists, or undefined otherwise.
+	opts, err := newPipeOptions(options)
+	if err != nil {
+		throw(rt, err)
+	}
+
+	// 4. Let promise be ! ReadableStreamPipeTo(this, transform["writable"], options["preventClose"],
+	// options["preventAbort"], options["preventCancel"], signal).
+	promise := stream.pipeTo(writable, opts)
+
+	// 5. Set promise.[[PromiseIsHandled]] to true.
+	setPromiseIsHandled(rt, promise)
+
+	// 6. Return transform["readable"].
+	return readable
+}
+
+// pipeOptions holds the options of the pipeTo and pipeThrough operations.
+//
+// [specification]: https://streams.spec.whatwg.org/#dictdef-streampipeoptions
+type pipeOptions struct {
+	preventClose  bool
+	preventAbort  bool
+	preventCancel bool
+}
+
+// newPipeOptions returns the pipe options held by the given object.
+func newPipeOptions(options *goja.Object) (pipeOptions, error) {
+	var opts pipeOptions
+
+	if options == nil || common.IsNullish(options) {
+		return opts, nil
+	}
+
+	// The options are read in the lexicographical order of their names, as
+	// for any dictionary, which matters if their getters have side effects.
+	for _, option := range []struct {
+		name  string
+		value *bool
+	}{
+		{"preventAbort", &opts.preventAbort},
+		{"preventCancel", &opts.preventCancel},
+		{"preventClose", &opts.preventClose},
+	} {
+		if v := options.Get(option.name); v != nil {
+			*option.value = v.ToBoolean()
+		}
+	}
+
+	// The AbortSignal API isn't available yet.
+	if !common.IsNullish(options.Get("signal")) {
+		return opts, newError(NotSupportedError, "the signal option is not supported yet")
+	}
+
+	return opts, nil
+}
+
+// pipeTo implements the specification's [ReadableStreamPipeTo] abstract operation.
+//
+// [ReadableStreamPipeTo]: https://streams.spec.whatwg.org/#readable-stream-pipe-to
+func (stream *ReadableStream) pipeTo(dest *WritableStream, opts pipeOptions) *goja.Promise {
+	rt := stream.runtime
+	source := stream
+
+	// 1-6. Assert: source implements ReadableStream, dest implements WritableStream, the options are
+	// booleans, and neither source nor dest are locked.
+
+	// 7. If source.[[controller]] implements ReadableByteStreamController, let reader be either
+	// ! AcquireReadableStreamBYOBReader(source) or ! AcquireReadableStreamDefaultReader(source),
+	// at the user agent's discretion.
+	// 8. Otherwise, let reader be ! AcquireReadableStreamDefaultReader(source).
+	reader := source.acquireDefaultReader()
+
+	// 9. Let writer be ! AcquireWritableStreamDefaultWriter(dest).
+	writer := dest.acquireDefaultWriter()
+
+	// 10. Set source.[[disturbed]] to true.
+	source.disturbed = true
+
+	// 11. Let shuttingDown be false.
+	shuttingDown := false
+
+	// 12. Let promise be a new promise.
+	promise := newPromiseCapability(rt)
+
+	// currentWrite holds the promise of the last write to dest, so the shutdown can wait
+	// until every chunk that has been read has been written.
+	currentWrite := newResolvedPromise(stream.vu, goja.Undefined())
+
+	// reading is true while a read from source is in progress. Because settling a promise from Go
+	// can run the pending reactions, the source might be seen as closed before the chunk steps of
+	// its last read ran, so the steps waiting for the writes are held until the read completes.
+	reading := false
+	var afterRead []func()
+
+	readCompleted := func() {
+		reading = false
+		steps := afterRead
+		afterRead = nil
+		for _, step := range steps {
+			step()
+		}
+	}
+
+	// waitForWrites runs the given steps once every chunk that has been read has been written.
+	var waitForWrites func(steps func())
+	waitForWrites = func(steps func()) {
+		if reading {
+			afterRead = append(afterRead, func() { waitForWrites(steps) })
+			return
+		}
+
+		oldWrite := currentWrite
+		settled := func(goja.Value) {
+			if oldWrite != currentWrite {
+				waitForWrites(steps)
+				return
+			}
+			steps()
+		}
+		uponPromise(rt, oldWrite, settled, settled)
+	}
+
+	// finalize performs the finalize steps, given an optional error.
+	finalize := func(err goja.Value) {
+		// 1. Perform ! WritableStreamDefaultWriterRelease(writer).
+		writer.release()
+
+		// 2. If reader implements ReadableStreamBYOBReader, perform ! ReadableStreamBYOBReaderRelease(reader).
+		// 3. Otherwise, perform ! ReadableStreamDefaultReaderRelease(reader).
+		reader.release()
+
+		// 4. If signal is not undefined, remove abortAlgorithm from signal.
+		// 5. If error was given, reject promise with error.
+		if err != nil {
+			promise.reject(err)
+			return
+		}
+
+		// 6. Otherwise, resolve promise with undefined.
+		promise.resolve(goja.Undefined())
+	}
+
+	// shutdownWithAction performs the "shutdown with an action" steps, given an action and an optional error.
+	shutdownWithAction := func(action func() *goja.Promise, originalError goja.Value) {
+		// 1. If shuttingDown is true, abort these substeps.
+		if shuttingDown {
+			return
+		}
+
+		// 2. Set shuttingDown to true.
+		shuttingDown = true
+
+		// 4. Let p be the result of performing action.
+		// 5. Upon fulfillment of p, finalize, passing along originalError if it was given.
+		// 6. Upon rejection of p with reason newError, finalize with newError.
+		run := func() {
+			uponPromise(rt, action(),
+				func(goja.Value) { finalize(originalError) },
+				func(newError goja.Value) { finalize(newError) },
+			)
+		}
+
+		// 3. If dest.[[state]] is "writable" and ! WritableStreamCloseQueuedOrInFlight(dest) is false,
+		// 3.1. If any chunks have been read but not yet written, write them to dest.
+		// 3.2. Wait until every chunk that has been read has been written.
+		if dest.state == WritableStreamStateWritable && !dest.closeQueuedOrInFlight() {
+			waitForWrites(run)
+			return
+		}
+
+		run()
+	}
+
+	// shutdown performs the "shutdown" steps, given an optional error.
+	shutdown := func(err goja.Value) {
+		// 1. If shuttingDown is true, abort these substeps.
+		if shuttingDown {
+			return
+		}
+
+		// 2. Set shuttingDown to true.
+		shuttingDown = true
+
+		// 3. If dest.[[state]] is "writable" and ! WritableStreamCloseQueuedOrInFlight(dest) is false,
+		// wait until every chunk that has been read has been written.
+		// 4. Finalize, passing along error if it was given.
+		if dest.state == WritableStreamStateWritable && !dest.closeQueuedOrInFlight() {
+			waitForWrites(func() { finalize(err) })
+			return
+		}
+
+		finalize(err)
+	}
+
+	// Errors must be propagated forward: if source.[[state]] is or becomes "errored", then
+	// if preventAbort is false, shutdown with an action of ! WritableStreamAbort(dest, source.[[storedError]])
+	// and with source.[[storedError]]; otherwise, shutdown with source.[[storedError]].
+	//
+	// Closing must be propagated forward: if source.[[state]] is or becomes "closed", then
+	// if preventClose is false, shutdown with an action of ! WritableStreamDefaultWriterCloseWithErrorPropagation(writer);
+	// otherwise, shutdown.
+	readerClosed, _, _ := reader.GetClosed()
+	uponPromise(rt, readerClosed,
+		func(goja.Value) {
+			if !opts.preventClose {
+				shutdownWithAction(writer.closeWithErrorPropagation, nil)
+			} else {
+				shutdown(nil)
+			}
+		},
+		func(storedError goja.Value) {
+			if !opts.preventAbort {
+				shutdownWithAction(func() *goja.Promise { return dest.abort(storedError) }, storedError)
+			} else {
+				shutdown(storedError)
+			}
+		},
+	)
+
+	// Errors must be propagated backward: if dest.[[state]] is or becomes "errored", then
+	// if preventCancel is false, shutdown with an action of ! ReadableStreamCancel(source, dest.[[storedError]])
+	// and with dest.[[storedError]]; otherwise, shutdown with dest.[[storedError]].
+	uponPromise(rt, writer.closedPromise.promise, nil, func(storedError goja.Value) {
+		if !opts.preventCancel {
+			shutdownWithAction(func() *goja.Promise { return source.cancel(storedError) }, storedError)
+		} else {
+			shutdown(storedError)
+		}
+	})
+
+	// Closing must be propagated backward: if ! WritableStreamCloseQueuedOrInFlight(dest) is true or
+	// dest.[[state]] is "closed", then if preventCancel is false, shutdown with an action of
+	// ! ReadableStreamCancel(source, destClosed) and with destClosed; otherwise, shutdown with destClosed.
+	if dest.closeQueuedOrInFlight() || dest.state == WritableStreamStateClosed {
+		destClosed := newTypeError(rt, "the destination stream is closed").Err()
+		if !opts.preventCancel {
+			shutdownWithAction(func() *goja.Promise { return source.cancel(destClosed) }, destClosed)
+		} else {
+			shutdown(destClosed)
+		}
+	}
+
+	// 15. In parallel, using reader and writer, read all chunks from source and write them to dest.
+	// Backpressure is respected by waiting for the writer's ready promise before each read.
+	var pipeStep func()
+	pipeStep = func() {
+		if shuttingDown {
+			return
+		}
+
+		uponPromise(rt, writer.readyPromise.promise, func(goja.Value) {
+			if shuttingDown {
+				return
+			}
+
+			reading = true
+			reader.read(ReadRequest{
+				chunkSteps: func(chunk any) {
+					// The writer is released when the pipe has been finalized without waiting
+					// for the writes, because dest isn't writable anymore.
+					if writer.stream != nil {
+						currentWrite = writer.write(rt.ToValue(chunk))
+						setPromiseIsHandled(rt, currentWrite)
+					}
+					readCompleted()
+					pipeStep()
+				},
+				// The closing and the errors of the source are handled by the reader's closed promise.
+				closeSteps: readCompleted,
+				errorSteps: func(any) { readCompleted() },
+			})
+		}, nil)
+	}
+	pipeStep()
+
+	// 16. Return promise.
+	return promise.promise
+}
@@ -0,0 +1,29 @@
+//go:build wpt
+
+package streams
+
+import "testing"
+
+func TestReadableStreamPipeWPT(t *testing.T) {
+	t.Parallel()
+
+	// Excluded suites:
+	//  - abort.any.js: the AbortSignal API, and so the signal option, isn't supported yet.
+	//  - general.any.js and pipe-through.any.js: they check the brand of the streams by
+	//    calling the methods of their prototypes, while the methods of the streams are
+	//    defined on their instances.
+	//  - then-interception.any.js: it patches Object.prototype.then, which the module
+	//    goes through when it reacts to the promises, unlike the browsers.
+	suites := []string{
+		"close-propagation-backward.any.js",
+		"close-propagation-forward.any.js",
+		"error-propagation-backward.any.js",
+		"error-propagation-forward.any.js",
+		"flow-control.any.js",
+		"multiple-propagation.any.js",
+		"throwing-options.any.js",
+		"transform-streams.any.js",
+	}
+
+	runTestSuites(t, "tests/wpt/streams/piping", suites)
+}
@@ -0,0 +1,399 @@
+package streams
+
+import (
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"gopkg.in/guregu/null.v3"
+)
+
+// tee implements the specification's [ReadableStreamTee] abstract operation.
+//
+// It returns the two branches the stream has been teed into.
+//
+// [ReadableStreamTee]: https://streams.spec.whatwg.org/#readable-stream-tee
+func (stream *ReadableStream) tee() (*ReadableStream, *ReadableStream) {
+	// 1. Assert: stream implements ReadableStream.
+	// 2. Assert: cloneForBranch2 is a boolean.
+	// 3. If stream.[[controller]] implements ReadableByteStreamController, return ? ReadableByteStreamTee(stream).
+	if _, ok := stream.controller.(*ReadableByteStreamController); ok {
+		return stream.byteTee()
+	}
+
+	// 4. Return ? ReadableStreamDefaultTee(stream, cloneForBranch2).
+	return stream.defaultTee()
+}
+
+// teeState holds the state shared by the pull and cancel algorithms of the branches
+// of a teed stream.
+type teeState struct {
+	reading              bool
+	readAgain            bool
+	canceled1, canceled2 bool
+	reason1, reason2     goja.Value
+	branch1, branch2     *ReadableStream
+	cancelPromise        *promiseCapability
+}
+
+// cancelAlgorithm returns the cancel algorithm of the first or second branch of a teed stream,
+// which cancels the teed stream once both branches have been canceled.
+func (state *teeState) cancelAlgorithm(stream *ReadableStream, second bool) UnderlyingSourceCancelCallback {
+	rt := stream.runtime
+
+	return func(reason any) goja.Value {
+		reasonVal := rt.ToValue(reasonValue(reason))
+
+		// 1. Set canceled1 to true.
+		// 2. Set reason1 to reason.
+		canceledOther := state.canceled2
+		if second {
+			state.canceled2, state.reason2 = true, reasonVal
+			canceledOther = state.canceled1
+		} else {
+			state.canceled1, state.reason1 = true, reasonVal
+		}
+
+		// 3. If canceled2 is true,
+		if canceledOther {
+			// 3.1. Let compositeReason be ! CreateArrayFromList(« reason1, reason2 »).
+			compositeReason := rt.NewArray(state.reason1, state.reason2)
+
+			// 3.2. Let cancelResult be ! ReadableStreamCancel(stream, compositeReason).
+			cancelResult := stream.cancel(compositeReason)
+
+			// 3.3. Resolve cancelPromise with cancelResult.
+			state.cancelPromise.resolve(cancelResult)
+		}
+
+		// 4. Return cancelPromise.
+		return rt.ToValue(state.cancelPromise.promise)
+	}
+}
+
+// queueMicrotask runs the given steps in a microtask, as the specification does
+// to avoid the reentrancy of the read request's chunk steps.
+func queueMicrotask(stream *ReadableStream, steps func()) {
+	uponPromise(stream.runtime, newResolvedPromise(stream.vu, goja.Undefined()), func(goja.Value) {
+		steps()
+	}, nil)
+}
+
+// defaultTee implements the specification's [ReadableStreamDefaultTee] abstract operation.
+//
+// Chunks aren't cloned for the second branch, as the structured clone algorithm isn't available.
+//
+// [ReadableStreamDefaultTee]: https://streams.spec.whatwg.org/#abstract-opdef-readablestreamdefaulttee
+func (stream *ReadableStream) defaultTee() (*ReadableStream, *ReadableStream) {
+	rt := stream.runtime
+
+	// 3. Let reader be ? AcquireReadableStreamDefaultReader(stream).
+	reader := stream.acquireDefaultReader()
+
+	// 4. Let reading be false.
+	// 5. Let readAgain be false.
+	// 6. Let canceled1 be false.
+	// 7. Let canceled2 be false.
+	// 8. Let reason1 be undefined.
+	// 9. Let reason2 be undefined.
+	// 10. Let branch1 be undefined.
+	// 11. Let branch2 be undefined.
+	// 12. Let cancelPromise be a new promise.
+	state := &teeState{cancelPromise: newPromiseCapability(rt)}
+
+	branchController := func(branch *ReadableStream) *ReadableStreamDefaultController {
+		controller, _ := branch.controller.(*ReadableStreamDefaultController)
+		return controller
+	}
+
+	// 13. Let pullAlgorithm be the following steps:
+	var pullAlgorithm UnderlyingSourcePullCallback
+	pullAlgorithm = func(*goja.Object) *goja.Promise {
+		// 13.1. If reading is true,
+		if state.reading {
+			// 13.1.1. Set readAgain to true.
+			state.readAgain = true
+
+			// 13.1.2. Return a promise resolved with undefined.
+			return newResolvedPromise(stream.vu, goja.Undefined())
+		}
+
+		// 13.2. Set reading to true.
+		state.reading = true
+
+		// 13.3. Let readRequest be a read request with the following items:
+		readRequest := ReadRequest{
+			chunkSteps: func(chunk any) {
+				// Queue a microtask to perform the following steps:
+				queueMicrotask(stream, func() {
+					// Set readAgain to false.
+					state.readAgain = false
+
+					// Let chunk1 and chunk2 be chunk.
+					chunkVal := rt.ToValue(chunk)
+
+					// If canceled1 is false, perform ! ReadableStreamDefaultControllerEnqueue(branch1.[[controller]], chunk1).
+					if !state.canceled1 {
+						_ = branchController(state.branch1).enqueue(chunkVal)
+					}
+
+					// If canceled2 is false, perform ! ReadableStreamDefaultControllerEnqueue(branch2.[[controller]], chunk2).
+					if !state.canceled2 {
+						_ = branchController(state.branch2).enqueue(chunkVal)
+					}
+
+					// Set reading to false.
+					state.reading = false
+
+					// If readAgain is true, perform pullAlgorithm.
+					if state.readAgain {
+						pullAlgorithm(nil)
+					}
+				})
+			},
+			closeSteps: func() {
+				// 1. Set reading to false.
+				state.reading = false
+
+				// 2. If canceled1 is false, perform ! ReadableStreamDefaultControllerClose(branch1.[[controller]]).
+				if !state.canceled1 {
+					branchController(state.branch1).close()
+				}
+
+				// 3. If canceled2 is false, perform ! ReadableStreamDefaultControllerClose(branch2.[[controller]]).
+				if !state.canceled2 {
+					branchController(state.branch2).close()
+				}
+
+				// 4. If canceled1 is false or canceled2 is false, resolve cancelPromise with undefined.
+				if !state.canceled1 || !state.canceled2 {
+					state.cancelPromise.resolve(goja.Undefined())
+				}
+			},
+			errorSteps: func(any) {
+				// 1. Set reading to false.
+				state.reading = false
+			},
+		}
+
+		// 13.4. Perform ! ReadableStreamDefaultReaderRead(reader, readRequest).
+		reader.read(readRequest)
+
+		// 13.5. Return a promise resolved with undefined.
+		return newResolvedPromise(stream.vu, goja.Undefined())
+	}
+
+	// 16. Let startAlgorithm be an algorithm that returns undefined.
+	startAlgorithm := func(*goja.Object) goja.Value {
+		return goja.Undefined()
+	}
+
+	// 17. Set branch1 to ! CreateReadableStream(startAlgorithm, pullAlgorithm, cancel1Algorithm).
+	state.branch1 = createReadableStream(
+		stream.vu, startAlgorithm, pullAlgorithm, state.cancelAlgorithm(stream, false), 1, nil,
+	)
+
+	// 18. Set branch2 to ! CreateReadableStream(startAlgorithm, pullAlgorithm, cancel2Algorithm).
+	state.branch2 = createReadableStream(
+		stream.vu, startAlgorithm, pullAlgorithm, state.cancelAlgorithm(stream, true), 1, nil,
+	)
+
+	// 19. Upon rejection of reader.[[closedPromise]] with reason r,
+	closedPromise, _, _ := reader.GetClosed()
+	uponPromise(rt, closedPromise, nil, func(r goja.Value) {
+		// 19.1. Perform ! ReadableStreamDefaultControllerError(branch1.[[controller]], r).
+		branchController(state.branch1).error(r)
+
+		// 19.2. Perform ! ReadableStreamDefaultControllerError(branch2.[[controller]], r).
+		branchController(state.branch2).error(r)
+
+		// 19.3. If canceled1 is false or canceled2 is false, resolve cancelPromise with undefined.
+		if !state.canceled1 || !state.canceled2 {
+			state.cancelPromise.resolve(goja.Undefined())
+		}
+	})
+
+	// 20. Return « branch1, branch2 ».
+	return state.branch1, state.branch2
+}
+
+// byteTee implements the specification's [ReadableByteStreamTee] abstract operation.
+//
+// The teed stream is always read with a default reader, and the branches fulfill the
+// pending BYOB requests of their own readers from the chunks read, as the specification
+// does when the branches are read with default readers. The second branch receives a copy
+// of each chunk, so the branches never share the same underlying buffer.
+//
+// [ReadableByteStreamTee]: https://streams.spec.whatwg.org/#abstract-opdef-readablebytestreamtee
+func (stream *ReadableStream) byteTee() (*ReadableStream, *ReadableStream) {
+	rt := stream.runtime
+
+	// 3. Let reader be ? AcquireReadableStreamDefaultReader(stream).
+	reader := stream.acquireDefaultReader()
+
+	// 4-12. Let reading, readAgainForBranch1, readAgainForBranch2, canceled1, canceled2 be false,
+	// reason1, reason2, branch1 and branch2 be undefined, and cancelPromise be a new promise.
+	state := &teeState{cancelPromise: newPromiseCapability(rt)}
+
+	branchController := func(branch *ReadableStream) *ReadableByteStreamController {
+		controller, _ := branch.controller.(*ReadableByteStreamController)
+		return controller
+	}
+
+	// 15. Let pullWithDefaultReader be the following steps:
+	var pullAlgorithm UnderlyingSourcePullCallback
+	pullAlgorithm = func(*goja.Object) *goja.Promise {
+		if state.reading {
+			state.readAgain = true
+			return newResolvedPromise(stream.vu, goja.Undefined())
+		}
+
+		state.reading = true
+
+		// 15.2. Let readRequest be a read request with the following items:
+		readRequest := ReadRequest{
+			chunkSteps: func(chunk any) {
+				// Queue a microtask to perform the following steps:
+				queueMicrotask(stream, func() {
+					// Set readAgainForBranch1 to false.
+					// Set readAgainForBranch2 to false.
+					state.readAgain = false
+
+					view, ok := exportArrayBufferView(rt, rt.ToValue(chunk))
+					if !ok {
+						common.Throw(rt, newError(AssertionError, "chunk is not an ArrayBufferView"))
+					}
+
+					// Let chunk1 and chunk2 be chunk.
+					chunk1, chunk2 := view, view
+
+					// If canceled1 is false and canceled2 is false,
+					if !state.canceled1 && !state.canceled2 {
+						// Let cloneResult be CloneAsUint8Array(chunk).
+						data := make([]byte, view.byteLength)
+						copy(data, view.buffer.Bytes()[view.byteOffset:view.byteOffset+view.byteLength])
+
+						// Otherwise, set chunk2 to cloneResult.[[Value]].
+						chunk2, _ = exportArrayBufferView(rt, newUint8Array(rt, rt.NewArrayBuffer(data), 0, len(data)))
+					}
+
+					// If canceled1 is false, perform ! ReadableByteStreamControllerEnqueue(branch1.[[controller]], chunk1).
+					if !state.canceled1 {
+						_ = branchController(state.branch1).enqueue(chunk1)
+					}
+
+					// If canceled2 is false, perform ! ReadableByteStreamControllerEnqueue(branch2.[[controller]], chunk2).
+					if !state.canceled2 {
+						_ = branchController(state.branch2).enqueue(chunk2)
+					}
+
+					// Set reading to false.
+					state.reading = false
+
+					// If readAgainForBranch1 is true, perform pull1Algorithm.
+					// Otherwise, if readAgainForBranch2 is true, perform pull2Algorithm.
+					if state.readAgain {
+						pullAlgorithm(nil)
+					}
+				})
+			},
+			closeSteps: func() {
+				// 1. Set reading to false.
+				state.reading = false
+
+				for _, branch := range []struct {
+					stream   *ReadableStream
+					canceled bool
+				}{
+					{state.branch1, state.canceled1},
+					{state.branch2, state.canceled2},
+				} {
+					if branch.canceled {
+						continue
+					}
+
+					// 4. If canceled1 is false, perform ! ReadableByteStreamControllerClose(branch1.[[controller]]).
+					controller := branchController(branch.stream)
+					_ = controller.close()
+
+					// 6. If branch1.[[controller]].[[pendingPullIntos]] is not empty, perform
+					// ! ReadableByteStreamControllerRespond(branch1.[[controller]], 0).
+					if len(controller.pendingPullIntos) > 0 {
+						_ = controller.respond(0)
+					}
+				}
+
+				// 8. If canceled1 is false or canceled2 is false, resolve cancelPromise with undefined.
+				if !state.canceled1 || !state.canceled2 {
+					state.cancelPromise.resolve(goja.Undefined())
+				}
+			},
+			errorSteps: func(any) {
+				// 1. Set reading to false.
+				state.reading = false
+			},
+		}
+
+		// 15.3. Perform ! ReadableStreamDefaultReaderRead(reader, readRequest).
+		reader.read(readRequest)
+
+		return newResolvedPromise(stream.vu, goja.Undefined())
+	}
+
+	// 21. Let startAlgorithm be an algorithm that returns undefined.
+	startAlgorithm := func(*goja.Object) goja.Value {
+		return goja.Undefined()
+	}
+
+	// 22. Set branch1 to ! CreateReadableByteStream(startAlgorithm, pull1Algorithm, cancel1Algorithm).
+	state.branch1 = createReadableByteStream(stream.vu, startAlgorithm, pullAlgorithm, state.cancelAlgorithm(stream, false))
+
+	// 23. Set branch2 to ! CreateReadableByteStream(startAlgorithm, pull2Algorithm, cancel2Algorithm).
+	state.branch2 = createReadableByteStream(stream.vu, startAlgorithm, pullAlgorithm, state.cancelAlgorithm(stream, true))
+
+	// 24. Perform forwardReaderError(reader).
+	closedPromise, _, _ := reader.GetClosed()
+	uponPromise(rt, closedPromise, nil, func(r goja.Value) {
+		// 1. Perform ! ReadableByteStreamControllerError(branch1.[[controller]], r).
+		branchController(state.branch1).error(r)
+
+		// 2. Perform ! ReadableByteStreamControllerError(branch2.[[controller]], r).
+		branchController(state.branch2).error(r)
+
+		// 3. If canceled1 is false or canceled2 is false, resolve cancelPromise with undefined.
+		if !state.canceled1 || !state.canceled2 {
+			state.cancelPromise.resolve(goja.Undefined())
+		}
+	})
+
+	// 25. Return « branch1, branch2 ».
+	return state.branch1, state.branch2
+}
+
+// createReadableByteStream implements the specification's [CreateReadableByteStream] abstract operation.
+//
+// [CreateReadableByteStream]: https://streams.spec.whatwg.org/#abstract-opdef-createreadablebytestream
+func createReadableByteStream(
+	vu modules.VU,
+	startAlgorithm UnderlyingSourceStartCallback,
+	pullAlgorithm UnderlyingSourcePullCallback,
+	cancelAlgorithm UnderlyingSourceCancelCallback,
+) *ReadableStream {
+	// 1. Let stream be a new ReadableStream.
+	stream := &ReadableStream{
+		runtime: vu.Runtime(),
+		vu:      vu,
+	}
+
+	// 2. Perform ! InitializeReadableStream(stream).
+	stream.initialize()
+
+	// 3. Let controller be a new ReadableByteStreamController.
+	controller := &ReadableByteStreamController{}
+
+	// 4. Perform ? SetUpReadableByteStreamController(stream, controller, startAlgorithm, pullAlgorithm,
+	// cancelAlgorithm, 0, undefined).
+	stream.setupByteController(controller, startAlgorithm, pullAlgorithm, cancelAlgorithm, 0, null.Int{})
+
+	// 5. Return stream.
+	return stream
+}
@@ -38,6 +38,10 @@ type ReadableStream struct {
 
 	Source *goja.Object
 
+	// prototype holds the prototype of the JavaScript object of the stream, which is
+	// also the one of its branches once teed, or nil for Object.prototype.
+	prototype *goja.Object
+
 	runtime *goja.Runtime
 	vu      modules.VU
 }
@@ -80,16 +84,26 @@ func (stream *ReadableStream) GetReader(options *goja.Object) goja.Value {
 	}
 
 	// 3. Return ? AcquireReadableStreamBYOBReader(this).
-	common.Throw(stream.runtime, newError(NotSupportedError, "'byob' mode is not supported yet"))
-	return goja.Undefined()
+	byobReader := stream.acquireBYOBReader()
+	byobReaderObj, err := NewReadableStreamBYOBReaderObject(byobReader)
+	if err != nil {
+		common.Throw(stream.runtime, err)
+	}
+
+	return byobReaderObj
 }
 
 // Tee implements the [tee] operation.
 //
 // [tee]: https://streams.spec.whatwg.org/#rs-tee
 func (stream *ReadableStream) Tee() goja.Value {
-	common.Throw(stream.runtime, newError(NotSupportedError, "'tee()' is not supported yet"))
-	return goja.Undefined()
+	// 1. Return ? ReadableStreamTee(this, false).
+	branch1, branch2 := stream.tee()
+
+	branch1.prototype, branch2.prototype = stream.prototype, stream.prototype
+
+	rt := stream.runtime
+	return rt.NewArray(newStreamObject(rt, branch1, branch1.prototype), newStreamObject(rt, branch2, branch2.prototype))
 }
 
 // ReadableStreamState represents the current state of a ReadableStream
@@ -132,6 +146,47 @@ func (stream *ReadableStream) initialize() {
 	stream.disturbed = false
 }
 
+// createReadableStream implements the specification's [CreateReadableStream] abstract operation.
+//
+// It is used to create readable streams from Go, such as the readable side of a [TransformStream],
+// or the branches of a teed stream.
+//
+// [CreateReadableStream]: https://streams.spec.whatwg.org/#create-readable-stream
+func createReadableStream(
+	vu modules.VU,
+	startAlgorithm UnderlyingSourceStartCallback,
+	pullAlgorithm UnderlyingSourcePullCallback,
+	cancelAlgorithm UnderlyingSourceCancelCallback,
+	highWaterMark float64,
+	sizeAlgorithm SizeAlgorithm,
+) *ReadableStream {
+	// 1. If highWaterMark was not passed, set it to 1.
+	// 2. If sizeAlgorithm was not passed, set it to an algorithm that returns 1.
+	if sizeAlgorithm == nil {
+		sizeAlgorithm, _ = goja.AssertFunction(vu.Runtime().ToValue(defaultSizeFunc))
+	}
+
+	// 3. Assert: ! IsNonNegativeNumber(highWaterMark) is true.
+	// 4. Let stream be a new ReadableStream.
+	stream := &ReadableStream{
+		runtime: vu.Runtime(),
+		vu:      vu,
+	}
+
+	// 5. Perform ! InitializeReadableStream(stream).
+	stream.initialize()
+
+	// 6. Let controller be a new ReadableStreamDefaultController.
+	controller := &ReadableStreamDefaultController{}
+
+	// 7. Perform ? SetUpReadableStreamDefaultController(stream, controller, startAlgorithm,
+	// pullAlgorithm, cancelAlgorithm, highWaterMark, sizeAlgorithm).
+	stream.setupDefaultController(controller, startAlgorithm, pullAlgorithm, cancelAlgorithm, highWaterMark, sizeAlgorithm)
+
+	// 8. Return stream.
+	return stream
+}
+
 // setupReadableStreamDefaultControllerFromUnderlyingSource implements the [specification]'s
 // SetUpReadableStreamDefaultController abstract operation.
 //
@@ -364,6 +419,41 @@ func (stream *ReadableStream) acquireDefaultReader() *ReadableStreamDefaultReade
 	return reader
 }
 
+// acquireBYOBReader implements the specification's [AcquireReadableStreamBYOBReader] algorithm.
+//
+// [AcquireReadableStreamBYOBReader]: https://streams.spec.whatwg.org/#acquire-readable-stream-byob-reader
+func (stream *ReadableStream) acquireBYOBReader() *ReadableStreamBYOBReader {
+	// 1. Let reader be a new ReadableStreamBYOBReader.
+	reader := &ReadableStreamBYOBReader{}
+
+	// 2. Perform ? SetUpReadableStreamBYOBReader(reader, stream).
+	reader.setup(stream)
+
+	// 3. Return reader.
+	return reader
+}
+
+// addReadIntoRequest implements the specification's [ReadableStreamAddReadIntoRequest()] abstract operation.
+//
+// [ReadableStreamAddReadIntoRequest()]: https://streams.spec.whatwg.org/#readable-stream-add-read-into-request
+func (stream *ReadableStream) addReadIntoRequest(readIntoRequest ReadIntoRequest) {
+	// 1. Assert: stream.[[reader]] implements ReadableStreamBYOBReader.
+	byobReader, ok := stream.reader.(*ReadableStreamBYOBReader)
+	if !ok {
+		readIntoRequest.errorSteps(newError(RuntimeError, "reader is not a ReadableStreamBYOBReader"))
+		return
+	}
+
+	// 2. Assert: stream.[[state]] is "readable" or "closed".
+	if stream.state == ReadableStreamStateErrored {
+		readIntoRequest.errorSteps(newError(AssertionError, "stream is errored"))
+		return
+	}
+
+	// 3. Append readIntoRequest to stream.[[reader]].[[readIntoRequests]].
+	byobReader.readIntoRequests = append(byobReader.readIntoRequests, readIntoRequest)
+}
+
 // addReadRequest implements the specification's [ReadableStreamAddReadRequest()] abstract operation.
 //
 // [ReadableStreamAddReadRequest()]: https://streams.spec.whatwg.org/#readable-stream-add-read-request
@@ -410,7 +500,19 @@ func (stream *ReadableStream) cancel(reason goja.Value) *goja.Promise {
 
 	// 5. Let reader be stream.[[reader]].
 	// 6. If reader is not undefined and reader implements ReadableStreamBYOBReader,
-	// Not implemented yet: ReadableStreamBYOBReader is not supported yet.
+	if byobReader, ok := stream.reader.(*ReadableStreamBYOBReader); ok {
+		// 6.1. Let readIntoRequests be reader.[[readIntoRequests]].
+		readIntoRequests := byobReader.readIntoRequests
+
+		// 6.2. Set reader.[[readIntoRequests]] to an empty list.
+		byobReader.readIntoRequests = []ReadIntoRequest{}
+
+		// 6.3. For each readIntoRequest of readIntoRequests,
+		for _, readIntoRequest := range readIntoRequests {
+			// 6.3.1. Perform readIntoRequest’s close steps, given undefined.
+			readIntoRequest.closeSteps(goja.Undefined())
+		}
+	}
 
 	// 7. Let sourceCancelPromise be ! stream.[[controller]].[[CancelSteps]](reason).
 	sourceCancelPromise := stream.controller.cancelSteps(reason)
@@ -530,7 +632,13 @@ func (stream *ReadableStream) error(e any) {
 
 	// 9. OTHERWISE, reader is a ReadableStreamBYOBReader
 	// 9.1. Assert: reader implements ReadableStreamBYOBReader.
-	common.Throw(stream.vu.Runtime(), newError(NotSupportedError, "ReadableStreamBYOBReader is not supported yet"))
+	byobReader, ok := reader.(*ReadableStreamBYOBReader)
+	if !ok {
+		common.Throw(stream.vu.Runtime(), newError(AssertionError, "reader is not a ReadableStreamBYOBReader"))
+	}
+
+	// 9.2. Perform ! ReadableStreamBYOBReaderErrorReadIntoRequests(reader, e).
+	byobReader.errorReadIntoRequests(reasonValue(e))
 }
 
 // fulfillReadRequest implements the [ReadableStreamFulfillReadRequest()] algorithm.
@@ -602,3 +710,60 @@ func (stream *ReadableStream) hasDefaultReader() bool {
 	_, ok := reader.(*ReadableStreamDefaultReader)
 	return ok
 }
+
+// fulfillReadIntoRequest implements the [ReadableStreamFulfillReadIntoRequest()] algorithm.
+//
+// [ReadableStreamFulfillReadIntoRequest()]: https://streams.spec.whatwg.org/#readable-stream-fulfill-read-into-request
+func (stream *ReadableStream) fulfillReadIntoRequest(chunk any, done bool) {
+	// 1. Assert: ! ReadableStreamHasBYOBReader(stream) is true.
+	// 2. Let reader be stream.[[reader]].
+	reader, ok := stream.reader.(*ReadableStreamBYOBReader)
+	if !ok {
+		common.Throw(stream.vu.Runtime(), newError(AssertionError, "stream does not have a BYOB reader"))
+	}
+
+	// 3. Assert: reader.[[readIntoRequests]] is not empty.
+	if len(reader.readIntoRequests) == 0 {
+		common.Throw(stream.vu.Runtime(), newError(AssertionError, "reader.[[readIntoRequests]] is empty"))
+	}
+
+	// 4. Let readIntoRequest be reader.[[readIntoRequests]][0].
+	readIntoRequest := reader.readIntoRequests[0]
+
+	// 5. Remove readIntoRequest from reader.[[readIntoRequests]].
+	reader.readIntoRequests = reader.readIntoRequests[1:]
+
+	if done {
+		// 6. If done is true, perform readIntoRequest’s close steps, given chunk.
+		readIntoRequest.closeSteps(chunk)
+	} else {
+		// 7. Otherwise, perform readIntoRequest’s chunk steps, given chunk.
+		readIntoRequest.chunkSteps(chunk)
+	}
+}
+
+// getNumReadIntoRequests implements the [ReadableStreamGetNumReadIntoRequests()] algorithm.
+//
+// [ReadableStreamGetNumReadIntoRequests()]: https://streams.spec.whatwg.org/#readable-stream-get-num-read-into-requests
+func (stream *ReadableStream) getNumReadIntoRequests() int {
+	// 1. Assert: ! ReadableStreamHasBYOBReader(stream) is true.
+	reader, ok := stream.reader.(*ReadableStreamBYOBReader)
+	if !ok {
+		common.Throw(stream.vu.Runtime(), newError(AssertionError, "stream does not have a BYOB reader"))
+	}
+
+	// 2. Return stream.[[reader]].[[readIntoRequests]]'s size.
+	return len(reader.readIntoRequests)
+}
+
+// hasBYOBReader implements the [ReadableStreamHasBYOBReader()] algorithm.
+//
+// [ReadableStreamHasBYOBReader()]: https://streams.spec.whatwg.org/#readable-stream-has-byob-reader
+func (stream *ReadableStream) hasBYOBReader() bool {
+	// 1. Let reader be stream.[[reader]].
+	// 2. If reader is undefined, return false.
+	// 3. If reader implements ReadableStreamBYOBReader, return true.
+	// 4. Return false.
+	_, ok := stream.reader.(*ReadableStreamBYOBReader)
+	return ok
+}
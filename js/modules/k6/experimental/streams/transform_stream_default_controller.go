@@ -0,0 +1,219 @@
+package streams
+
+import (
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+)
+
+// TransformStreamDefaultController provides ways to manipulate the associated [ReadableStream]
+// and [WritableStream] of a [TransformStream].
+//
+// [specification]: https://streams.spec.whatwg.org/#ts-default-controller-class
+type TransformStreamDefaultController struct {
+	// cancelAlgorithm is a promise-returning algorithm, taking one argument (the reason for
+	// cancellation), which communicates a requested cancellation to the transformer.
+	cancelAlgorithm TransformerCancelCallback
+
+	// finishPromise is a promise which resolves on completion of either the cancel algorithm
+	// or the flush algorithm. It is nil until either of them is called.
+	finishPromise *promiseCapability
+
+	// flushAlgorithm is a promise-returning algorithm which communicates a requested close
+	// to the transformer.
+	flushAlgorithm TransformerFlushCallback
+
+	// stream is the [TransformStream] instance controlled.
+	stream *TransformStream
+
+	// transformAlgorithm is a promise-returning algorithm, taking one argument (the chunk to
+	// transform), which requests the transformer perform its transformation.
+	transformAlgorithm TransformerTransformCallback
+
+	// object holds the JS representation of the controller, passed to the transformer callbacks.
+	object *goja.Object
+}
+
+// NewTransformStreamDefaultControllerObject creates a new [goja.Object] from a
+// [TransformStreamDefaultController] instance.
+func NewTransformStreamDefaultControllerObject(controller *TransformStreamDefaultController) (*goja.Object, error) {
+	rt := controller.stream.runtime
+	obj := rt.NewObject()
+	objName := "TransformStreamDefaultController"
+
+	err := obj.DefineAccessorProperty("desiredSize", rt.ToValue(func() goja.Value {
+		desiredSize := controller.stream.readableController().getDesiredSize()
+		if !desiredSize.Valid {
+			return goja.Null()
+		}
+
+		return rt.ToValue(desiredSize.Float64)
+	}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setReadOnlyPropertyOf(obj, objName, "enqueue", rt.ToValue(controller.Enqueue)); err != nil {
+		return nil, err
+	}
+
+	if err := setReadOnlyPropertyOf(obj, objName, "error", rt.ToValue(controller.Error)); err != nil {
+		return nil, err
+	}
+
+	if err := setReadOnlyPropertyOf(obj, objName, "terminate", rt.ToValue(controller.Terminate)); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// Enqueue enqueues the given chunk in the readable side of the controlled transform stream.
+//
+// It implements the TransformStreamDefaultController.enqueue(chunk) [specification] algorithm.
+//
+// [specification]: https://streams.spec.whatwg.org/#ts-default-controller-enqueue
+func (controller *TransformStreamDefaultController) Enqueue(chunk goja.Value) {
+	if chunk == nil {
+		chunk = goja.Undefined()
+	}
+
+	// 1. Perform ? TransformStreamDefaultControllerEnqueue(this, chunk).
+	if err := controller.enqueue(chunk); err != nil {
+		throw(controller.stream.runtime, err)
+	}
+}
+
+// Error errors both the readable side and the writable side of the controlled transform stream.
+//
+// It implements the TransformStreamDefaultController.error(e) [specification] algorithm.
+//
+// [specification]: https://streams.spec.whatwg.org/#ts-default-controller-error
+func (controller *TransformStreamDefaultController) Error(e goja.Value) {
+	if e == nil {
+		e = goja.Undefined()
+	}
+
+	// 1. Perform ? TransformStreamDefaultControllerError(this, e).
+	controller.stream.error(e)
+}
+
+// Terminate closes the readable side and errors the writable side of the controlled transform stream.
+//
+// It implements the TransformStreamDefaultController.terminate() [specification] algorithm.
+//
+// [specification]: https://streams.spec.whatwg.org/#ts-default-controller-terminate
+func (controller *TransformStreamDefaultController) Terminate() {
+	// 1. Perform ? TransformStreamDefaultControllerTerminate(this).
+	controller.terminate()
+}
+
+// toObject returns the JS representation of the controller, creating it if needed.
+func (controller *TransformStreamDefaultController) toObject() *goja.Object {
+	if controller.object != nil {
+		return controller.object
+	}
+
+	obj, err := NewTransformStreamDefaultControllerObject(controller)
+	if err != nil {
+		common.Throw(controller.stream.runtime, newError(RuntimeError, err.Error()))
+	}
+	controller.object = obj
+
+	return obj
+}
+
+// clearAlgorithms implements the specification's [TransformStreamDefaultControllerClearAlgorithms]
+// abstract operation.
+//
+// [TransformStreamDefaultControllerClearAlgorithms]: https://streams.spec.whatwg.org/#transform-stream-default-controller-clear-algorithms
+func (controller *TransformStreamDefaultController) clearAlgorithms() {
+	// 1. Set controller.[[transformAlgorithm]] to undefined.
+	controller.transformAlgorithm = nil
+
+	// 2. Set controller.[[flushAlgorithm]] to undefined.
+	controller.flushAlgorithm = nil
+
+	// 3. Set controller.[[cancelAlgorithm]] to undefined.
+	controller.cancelAlgorithm = nil
+}
+
+// enqueue implements the specification's [TransformStreamDefaultControllerEnqueue] abstract operation.
+//
+// [TransformStreamDefaultControllerEnqueue]: https://streams.spec.whatwg.org/#transform-stream-default-controller-enqueue
+func (controller *TransformStreamDefaultController) enqueue(chunk goja.Value) error {
+	rt := controller.stream.runtime
+
+	// 1. Let stream be controller.[[stream]].
+	stream := controller.stream
+
+	// 2. Let readableController be stream.[[readable]].[[controller]].
+	readableController := stream.readableController()
+
+	// 3. If ! ReadableStreamDefaultControllerCanCloseOrEnqueue(readableController) is false, throw a TypeError exception.
+	if !readableController.canCloseOrEnqueue() {
+		return newTypeError(rt, "the readable side cannot be enqueued to")
+	}
+
+	// 4. Let enqueueResult be ReadableStreamDefaultControllerEnqueue(readableController, chunk).
+	// 5. If enqueueResult is an abrupt completion,
+	if err := readableController.enqueue(chunk); err != nil {
+		// 5.1. Perform ! TransformStreamErrorWritableAndUnblockWrite(stream, enqueueResult.[[Value]]).
+		stream.errorWritableAndUnblockWrite(rt.ToValue(reasonValue(err)))
+
+		// 5.2. Throw stream.[[readable]].[[storedError]].
+		// The readable side has been errored with enqueueResult.[[Value]] itself.
+		return err
+	}
+
+	// 6. Let backpressure be ! ReadableStreamDefaultControllerHasBackpressure(readableController).
+	backpressure := !readableController.shouldCallPull()
+
+	// 7. If backpressure is not stream.[[backpressure]],
+	if backpressure != stream.backpressure {
+		// 7.1. Assert: backpressure is true.
+		// 7.2. Perform ! TransformStreamSetBackpressure(stream, true).
+		stream.setBackpressure(true)
+	}
+
+	return nil
+}
+
+// performTransform implements the specification's [TransformStreamDefaultControllerPerformTransform]
+// abstract operation.
+//
+// [TransformStreamDefaultControllerPerformTransform]: https://streams.spec.whatwg.org/#transform-stream-default-controller-perform-transform
+func (controller *TransformStreamDefaultController) performTransform(chunk goja.Value) *goja.Promise {
+	stream := controller.stream
+
+	// 1. Let transformPromise be the result of performing controller.[[transformAlgorithm]], passing chunk.
+	transformPromise := controller.transformAlgorithm(chunk)
+
+	// 2. Return the result of reacting to transformPromise with the following rejection steps given the argument r:
+	return promiseReact(stream.runtime, transformPromise, nil, func(r goja.Value) goja.Value {
+		// 2.1. Perform ! TransformStreamError(controller.[[stream]], r).
+		stream.error(r)
+
+		// 2.2. Throw r.
+		panic(r)
+	})
+}
+
+// terminate implements the specification's [TransformStreamDefaultControllerTerminate] abstract operation.
+//
+// [TransformStreamDefaultControllerTerminate]: https://streams.spec.whatwg.org/#transform-stream-default-controller-terminate
+func (controller *TransformStreamDefaultController) terminate() {
+	rt := controller.stream.runtime
+
+	// 1. Let stream be controller.[[stream]].
+	stream := controller.stream
+
+	// 2. Let readableController be stream.[[readable]].[[controller]].
+	// 3. Perform ! ReadableStreamDefaultControllerClose(readableController).
+	stream.readableController().close()
+
+	// 4. Let error be a TypeError exception indicating that the stream has been terminated.
+	err := newTypeError(rt, "the stream has been terminated").Err()
+
+	// 5. Perform ! TransformStreamErrorWritableAndUnblockWrite(stream, error).
+	stream.errorWritableAndUnblockWrite(err)
+}
@@ -0,0 +1,533 @@
+package streams
+
+import (
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+// TransformStream is a concrete instance of the general [transform stream] concept.
+//
+// It consists of a pair of streams: a writable stream, known as its writable side, and a
+// readable stream, known as its readable side. Writes to the writable side result in new
+// data being made available for reading from the readable side, after being transformed.
+//
+// [transform stream]: https://streams.spec.whatwg.org/#ts-class
+type TransformStream struct {
+	// backpressure holds whether there was backpressure on the readable side the last time it was observed.
+	backpressure bool
+
+	// backpressureChangePromise is a promise which is fulfilled and replaced every time the value
+	// of backpressure changes.
+	backpressureChangePromise *promiseCapability
+
+	// controller holds the [TransformStreamDefaultController] created with the ability to control
+	// the readable and writable sides of this stream.
+	controller *TransformStreamDefaultController
+
+	// readable holds the readable side of the stream.
+	readable *ReadableStream
+
+	// writable holds the writable side of the stream.
+	writable *WritableStream
+
+	runtime *goja.Runtime
+	vu      modules.VU
+}
+
+// NewTransformStreamObject creates a new goja.Object from a [TransformStream] instance.
+func NewTransformStreamObject(stream *TransformStream) (*goja.Object, error) {
+	rt := stream.runtime
+	obj := rt.NewObject()
+
+	readable := newStreamObject(rt, stream.readable, stream.readable.prototype)
+	err := obj.DefineAccessorProperty("readable", rt.ToValue(func() goja.Value {
+		return readable
+	}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE)
+	if err != nil {
+		return nil, err
+	}
+
+	writable := newStreamObject(rt, stream.writable, stream.writable.prototype)
+	err = obj.DefineAccessorProperty("writable", rt.ToValue(func() goja.Value {
+		return writable
+	}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE)
+	if err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// initialize implements the specification's [InitializeTransformStream] abstract operation.
+//
+// [InitializeTransformStream]: https://streams.spec.whatwg.org/#initialize-transform-stream
+func (stream *TransformStream) initialize(
+	startPromise *goja.Promise,
+	writableHighWaterMark float64,
+	writableSizeAlgorithm SizeAlgorithm,
+	readableHighWaterMark float64,
+	readableSizeAlgorithm SizeAlgorithm,
+) {
+	rt := stream.runtime
+
+	// 1. Let startAlgorithm be an algorithm that returns startPromise.
+	writableStartAlgorithm := func(*goja.Object) goja.Value {
+		return rt.ToValue(startPromise)
+	}
+	readableStartAlgorithm := func(*goja.Object) goja.Value {
+		return rt.ToValue(startPromise)
+	}
+
+	// 2. Let writeAlgorithm be the following steps, taking a chunk argument:
+	//   2.1. Return ! TransformStreamDefaultSinkWriteAlgorithm(stream, chunk).
+	writeAlgorithm := stream.sinkWriteAlgorithm
+
+	// 3. Let abortAlgorithm be the following steps, taking a reason argument:
+	//   3.1. Return ! TransformStreamDefaultSinkAbortAlgorithm(stream, reason).
+	abortAlgorithm := stream.sinkAbortAlgorithm
+
+	// 4. Let closeAlgorithm be the following steps:
+	//   4.1. Return ! TransformStreamDefaultSinkCloseAlgorithm(stream).
+	closeAlgorithm := stream.sinkCloseAlgorithm
+
+	// 5. Set stream.[[writable]] to ! CreateWritableStream(startAlgorithm, writeAlgorithm, closeAlgorithm,
+	// abortAlgorithm, writableHighWaterMark, writableSizeAlgorithm).
+	stream.writable = createWritableStream(
+		stream.vu,
+		writableStartAlgorithm,
+		writeAlgorithm,
+		closeAlgorithm,
+		abortAlgorithm,
+		writableHighWaterMark,
+		writableSizeAlgorithm,
+	)
+
+	// 6. Let pullAlgorithm be the following steps:
+	//   6.1. Return ! TransformStreamDefaultSourcePullAlgorithm(stream).
+	pullAlgorithm := func(*goja.Object) *goja.Promise {
+		return stream.sourcePullAlgorithm()
+	}
+
+	// 7. Let cancelAlgorithm be the following steps, taking a reason argument:
+	//   7.1. Return ! TransformStreamDefaultSourceCancelAlgorithm(stream, reason).
+	cancelAlgorithm := func(reason any) goja.Value {
+		return rt.ToValue(stream.sourceCancelAlgorithm(rt.ToValue(reasonValue(reason))))
+	}
+
+	// 8. Set stream.[[readable]] to ! CreateReadableStream(startAlgorithm, pullAlgorithm, cancelAlgorithm,
+	// readableHighWaterMark, readableSizeAlgorithm).
+	stream.readable = createReadableStream(
+		stream.vu,
+		readableStartAlgorithm,
+		pullAlgorithm,
+		cancelAlgorithm,
+		readableHighWaterMark,
+		readableSizeAlgorithm,
+	)
+
+	// 9. Set stream.[[backpressure]] and stream.[[backpressureChangePromise]] to undefined.
+	stream.backpressureChangePromise = nil
+
+	// 10. Perform ! TransformStreamSetBackpressure(stream, true).
+	stream.setBackpressure(true)
+
+	// 11. Set stream.[[controller]] to undefined.
+	stream.controller = nil
+}
+
+// readableController returns the controller of the readable side of the stream.
+func (stream *TransformStream) readableController() *ReadableStreamDefaultController {
+	controller, ok := stream.readable.controller.(*ReadableStreamDefaultController)
+	if !ok {
+		common.Throw(stream.runtime, newError(AssertionError, "the readable side's controller is not a default controller"))
+	}
+
+	return controller
+}
+
+// error implements the specification's [TransformStreamError] abstract operation.
+//
+// [TransformStreamError]: https://streams.spec.whatwg.org/#transform-stream-error
+func (stream *TransformStream) error(e goja.Value) {
+	// 1. Perform ! ReadableStreamDefaultControllerError(stream.[[readable]].[[controller]], e).
+	stream.readableController().error(e)
+
+	// 2. Perform ! TransformStreamErrorWritableAndUnblockWrite(stream, e).
+	stream.errorWritableAndUnblockWrite(e)
+}
+
+// errorWritableAndUnblockWrite implements the specification's [TransformStreamErrorWritableAndUnblockWrite]
+// abstract operation.
+//
+// [TransformStreamErrorWritableAndUnblockWrite]: https://streams.spec.whatwg.org/#transform-stream-error-writable-and-unblock-write
+func (stream *TransformStream) errorWritableAndUnblockWrite(e goja.Value) {
+	// 1. Perform ! TransformStreamDefaultControllerClearAlgorithms(stream.[[controller]]).
+	stream.controller.clearAlgorithms()
+
+	// 2. Perform ! WritableStreamDefaultControllerErrorIfNeeded(stream.[[writable]].[[controller]], e).
+	stream.writable.controller.errorIfNeeded(e)
+
+	// 3. Perform ! TransformStreamUnblockWrite(stream).
+	stream.unblockWrite()
+}
+
+// setBackpressure implements the specification's [TransformStreamSetBackpressure] abstract operation.
+//
+// [TransformStreamSetBackpressure]: https://streams.spec.whatwg.org/#transform-stream-set-backpressure
+func (stream *TransformStream) setBackpressure(backpressure bool) {
+	// 1. Assert: stream.[[backpressure]] is not backpressure.
+	// 2. If stream.[[backpressureChangePromise]] is not undefined, resolve stream.[[backpressureChangePromise]].
+	if stream.backpressureChangePromise != nil {
+		stream.backpressureChangePromise.resolve(goja.Undefined())
+	}
+
+	// 3. Set stream.[[backpressureChangePromise]] to a new promise.
+	stream.backpressureChangePromise = newPromiseCapability(stream.runtime)
+
+	// 4. Set stream.[[backpressure]] to backpressure.
+	stream.backpressure = backpressure
+}
+
+// unblockWrite implements the specification's [TransformStreamUnblockWrite] abstract operation.
+//
+// [TransformStreamUnblockWrite]: https://streams.spec.whatwg.org/#transform-stream-unblock-write
+func (stream *TransformStream) unblockWrite() {
+	// 1. If stream.[[backpressure]] is true, perform ! TransformStreamSetBackpressure(stream, false).
+	if stream.backpressure {
+		stream.setBackpressure(false)
+	}
+}
+
+// sinkWriteAlgorithm implements the specification's [TransformStreamDefaultSinkWriteAlgorithm] abstract operation.
+//
+// [TransformStreamDefaultSinkWriteAlgorithm]: https://streams.spec.whatwg.org/#transform-stream-default-sink-write-algorithm
+func (stream *TransformStream) sinkWriteAlgorithm(chunk goja.Value) *goja.Promise {
+	// 1. Assert: stream.[[writable]].[[state]] is "writable".
+	if stream.writable.state != WritableStreamStateWritable {
+		common.Throw(stream.runtime, newError(AssertionError, "the writable side is not writable"))
+	}
+
+	// 2. Let controller be stream.[[controller]].
+	controller := stream.controller
+
+	// 3. If stream.[[backpressure]] is true,
+	if stream.backpressure {
+		// 3.1. Let backpressureChangePromise be stream.[[backpressureChangePromise]].
+		// 3.2. Assert: backpressureChangePromise is not undefined.
+		backpressureChangePromise := stream.backpressureChangePromise.promise
+
+		// 3.3. Return the result of reacting to backpressureChangePromise with the following fulfillment steps:
+		return promiseReact(stream.runtime, backpressureChangePromise, func(goja.Value) goja.Value {
+			// 3.3.1. Let writable be stream.[[writable]].
+			writable := stream.writable
+
+			// 3.3.2. Let state be writable.[[state]].
+			// 3.3.3. If state is "erroring", throw writable.[[storedError]].
+			if writable.state == WritableStreamStateErroring {
+				panic(writable.storedError)
+			}
+
+			// 3.3.4. Assert: state is "writable".
+			// 3.3.5. Return ! TransformStreamDefaultControllerPerformTransform(controller, chunk).
+			return stream.runtime.ToValue(controller.performTransform(chunk))
+		}, nil)
+	}
+
+	// 4. Return ! TransformStreamDefaultControllerPerformTransform(controller, chunk).
+	return controller.performTransform(chunk)
+}
+
+// sinkAbortAlgorithm implements the specification's [TransformStreamDefaultSinkAbortAlgorithm] abstract operation.
+//
+// [TransformStreamDefaultSinkAbortAlgorithm]: https://streams.spec.whatwg.org/#transform-stream-default-sink-abort-algorithm
+func (stream *TransformStream) sinkAbortAlgorithm(reason goja.Value) *goja.Promise {
+	// 1. Let controller be stream.[[controller]].
+	controller := stream.controller
+
+	// 2. If controller.[[finishPromise]] is not undefined, return controller.[[finishPromise]].
+	if controller.finishPromise != nil {
+		return controller.finishPromise.promise
+	}
+
+	// 3. Let readable be stream.[[readable]].
+	readable := stream.readable
+
+	// 4. Let controller.[[finishPromise]] be a new promise.
+	finishPromise := newPromiseCapability(stream.runtime)
+	controller.finishPromise = finishPromise
+
+	// 5. Let cancelPromise be the result of performing controller.[[cancelAlgorithm]], passing reason.
+	cancelPromise := controller.cancelAlgorithm(reason)
+
+	// 6. Perform ! TransformStreamDefaultControllerClearAlgorithms(controller).
+	controller.clearAlgorithms()
+
+	// 7. React to cancelPromise:
+	uponPromise(stream.runtime, cancelPromise,
+		// 7.1. If cancelPromise was fulfilled, then:
+		func(goja.Value) {
+			// 7.1.1. If readable.[[state]] is "errored", reject controller.[[finishPromise]] with
+			// readable.[[storedError]].
+			if readable.state == ReadableStreamStateErrored {
+				finishPromise.reject(readable.storedError)
+				return
+			}
+
+			// 7.1.2. Otherwise:
+			// 7.1.2.1. Perform ! ReadableStreamDefaultControllerError(readable.[[controller]], reason).
+			stream.readableController().error(reason)
+
+			// 7.1.2.2. Resolve controller.[[finishPromise]] with undefined.
+			finishPromise.resolve(goja.Undefined())
+		},
+		// 7.2. If cancelPromise was rejected with reason r, then:
+		func(r goja.Value) {
+			// 7.2.1. Perform ! ReadableStreamDefaultControllerError(readable.[[controller]], r).
+			stream.readableController().error(r)
+
+			// 7.2.2. Reject controller.[[finishPromise]] with r.
+			finishPromise.reject(r)
+		},
+	)
+
+	// 8. Return controller.[[finishPromise]].
+	return finishPromise.promise
+}
+
+// sinkCloseAlgorithm implements the specification's [TransformStreamDefaultSinkCloseAlgorithm] abstract operation.
+//
+// [TransformStreamDefaultSinkCloseAlgorithm]: https://streams.spec.whatwg.org/#transform-stream-default-sink-close-algorithm
+func (stream *TransformStream) sinkCloseAlgorithm() *goja.Promise {
+	// 1. Let controller be stream.[[controller]].
+	controller := stream.controller
+
+	// 2. If controller.[[finishPromise]] is not undefined, return controller.[[finishPromise]].
+	if controller.finishPromise != nil {
+		return controller.finishPromise.promise
+	}
+
+	// 3. Let readable be stream.[[readable]].
+	readable := stream.readable
+
+	// 4. Let controller.[[finishPromise]] be a new promise.
+	finishPromise := newPromiseCapability(stream.runtime)
+	controller.finishPromise = finishPromise
+
+	// 5. Let flushPromise be the result of performing controller.[[flushAlgorithm]].
+	flushPromise := controller.flushAlgorithm()
+
+	// 6. Perform ! TransformStreamDefaultControllerClearAlgorithms(controller).
+	controller.clearAlgorithms()
+
+	// 7. React to flushPromise:
+	uponPromise(stream.runtime, flushPromise,
+		// 7.1. If flushPromise was fulfilled, then:
+		func(goja.Value) {
+			// 7.1.1. If readable.[[state]] is "errored", reject controller.[[finishPromise]] with
+			// readable.[[storedError]].
+			if readable.state == ReadableStreamStateErrored {
+				finishPromise.reject(readable.storedError)
+				return
+			}
+
+			// 7.1.2. Otherwise:
+			// 7.1.2.1. Perform ! ReadableStreamDefaultControllerClose(readable.[[controller]]).
+			stream.readableController().close()
+
+			// 7.1.2.2. Resolve controller.[[finishPromise]] with undefined.
+			finishPromise.resolve(goja.Undefined())
+		},
+		// 7.2. If flushPromise was rejected with reason r, then:
+		func(r goja.Value) {
+			// 7.2.1. Perform ! ReadableStreamDefaultControllerError(readable.[[controller]], r).
+			stream.readableController().error(r)
+
+			// 7.2.2. Reject controller.[[finishPromise]] with r.
+			finishPromise.reject(r)
+		},
+	)
+
+	// 8. Return controller.[[finishPromise]].
+	return finishPromise.promise
+}
+
+// sourcePullAlgorithm implements the specification's [TransformStreamDefaultSourcePullAlgorithm] abstract operation.
+//
+// [TransformStreamDefaultSourcePullAlgorithm]: https://streams.spec.whatwg.org/#transform-stream-default-source-pull
+func (stream *TransformStream) sourcePullAlgorithm() *goja.Promise {
+	// 1. Assert: stream.[[backpressure]] is true.
+	// 2. Assert: stream.[[backpressureChangePromise]] is not undefined.
+	if !stream.backpressure || stream.backpressureChangePromise == nil {
+		common.Throw(stream.runtime, newError(AssertionError, "the stream has no backpressure"))
+	}
+
+	// 3. Perform ! TransformStreamSetBackpressure(stream, false).
+	stream.setBackpressure(false)
+
+	// 4. Return stream.[[backpressureChangePromise]].
+	return stream.backpressureChangePromise.promise
+}
+
+// sourceCancelAlgorithm implements the specification's [TransformStreamDefaultSourceCancelAlgorithm]
+// abstract operation.
+//
+// [TransformStreamDefaultSourceCancelAlgorithm]: https://streams.spec.whatwg.org/#transform-stream-default-source-cancel
+func (stream *TransformStream) sourceCancelAlgorithm(reason goja.Value) *goja.Promise {
+	// 1. Let controller be stream.[[controller]].
+	controller := stream.controller
+
+	// 2. If controller.[[finishPromise]] is not undefined, return controller.[[finishPromise]].
+	if controller.finishPromise != nil {
+		return controller.finishPromise.promise
+	}
+
+	// 3. Let writable be stream.[[writable]].
+	writable := stream.writable
+
+	// 4. Let controller.[[finishPromise]] be a new promise.
+	finishPromise := newPromiseCapability(stream.runtime)
+	controller.finishPromise = finishPromise
+
+	// 5. Let cancelPromise be the result of performing controller.[[cancelAlgorithm]], passing reason.
+	cancelPromise := controller.cancelAlgorithm(reason)
+
+	// 6. Perform ! TransformStreamDefaultControllerClearAlgorithms(controller).
+	controller.clearAlgorithms()
+
+	// 7. React to cancelPromise:
+	uponPromise(stream.runtime, cancelPromise,
+		// 7.1. If cancelPromise was fulfilled, then:
+		func(goja.Value) {
+			// 7.1.1. If writable.[[state]] is "errored", reject controller.[[finishPromise]] with
+			// writable.[[storedError]].
+			if writable.state == WritableStreamStateErrored {
+				finishPromise.reject(writable.storedError)
+				return
+			}
+
+			// 7.1.2. Otherwise:
+			// 7.1.2.1. Perform ! WritableStreamDefaultControllerErrorIfNeeded(writable.[[controller]], reason).
+			writable.controller.errorIfNeeded(reason)
+
+			// 7.1.2.2. Perform ! TransformStreamUnblockWrite(stream).
+			stream.unblockWrite()
+
+			// 7.1.2.3. Resolve controller.[[finishPromise]] with undefined.
+			finishPromise.resolve(goja.Undefined())
+		},
+		// 7.2. If cancelPromise was rejected with reason r, then:
+		func(r goja.Value) {
+			// 7.2.1. Perform ! WritableStreamDefaultControllerErrorIfNeeded(writable.[[controller]], r).
+			writable.controller.errorIfNeeded(r)
+
+			// 7.2.2. Perform ! TransformStreamUnblockWrite(stream).
+			stream.unblockWrite()
+
+			// 7.2.3. Reject controller.[[finishPromise]] with r.
+			finishPromise.reject(r)
+		},
+	)
+
+	// 8. Return controller.[[finishPromise]].
+	return finishPromise.promise
+}
+
+// setupTransformStreamDefaultControllerFromTransformer implements the specification's
+// [SetUpTransformStreamDefaultControllerFromTransformer] abstract operation.
+//
+// [SetUpTransformStreamDefaultControllerFromTransformer]: https://streams.spec.whatwg.org/#set-up-transform-stream-default-controller-from-transformer
+func (stream *TransformStream) setupTransformStreamDefaultControllerFromTransformer(
+	transformer *goja.Object,
+	transformerDict Transformer,
+) {
+	// 1. Let controller be a new TransformStreamDefaultController.
+	controller := &TransformStreamDefaultController{}
+
+	// 2. Let transformAlgorithm be the following steps, taking a chunk argument:
+	var transformAlgorithm TransformerTransformCallback = func(chunk goja.Value) *goja.Promise {
+		// 2.1. Let result be TransformStreamDefaultControllerEnqueue(controller, chunk).
+		// 2.2. If result is an abrupt completion, return a promise rejected with result.[[Value]].
+		if err := controller.enqueue(chunk); err != nil {
+			return newRejectedPromise(stream.vu, reasonValue(err))
+		}
+
+		// 2.3. Otherwise, return a promise resolved with undefined.
+		return newResolvedPromise(stream.vu, goja.Undefined())
+	}
+
+	// 3. Let flushAlgorithm be an algorithm which returns a promise resolved with undefined.
+	var flushAlgorithm TransformerFlushCallback = func() *goja.Promise {
+		return newResolvedPromise(stream.vu, goja.Undefined())
+	}
+
+	// 4. Let cancelAlgorithm be an algorithm which returns a promise resolved with undefined.
+	var cancelAlgorithm TransformerCancelCallback = func(goja.Value) *goja.Promise {
+		return newResolvedPromise(stream.vu, goja.Undefined())
+	}
+
+	// 5. If transformerDict["transform"] exists, set transformAlgorithm to an algorithm which takes an
+	// argument chunk and returns the result of invoking transformerDict["transform"] with argument list
+	// « chunk, controller » and callback this value transformer.
+	if transformerDict.transformSet {
+		transform, _ := goja.AssertFunction(transformerDict.Transform)
+		transformAlgorithm = func(chunk goja.Value) *goja.Promise {
+			return promiseFromCall(stream.vu, transform, transformer, chunk, controller.toObject())
+		}
+	}
+
+	// 6. If transformerDict["flush"] exists, set flushAlgorithm to an algorithm which returns the result
+	// of invoking transformerDict["flush"] with argument list « controller » and callback this value transformer.
+	if transformerDict.flushSet {
+		flush, _ := goja.AssertFunction(transformerDict.Flush)
+		flushAlgorithm = func() *goja.Promise {
+			return promiseFromCall(stream.vu, flush, transformer, controller.toObject())
+		}
+	}
+
+	// 7. If transformerDict["cancel"] exists, set cancelAlgorithm to an algorithm which takes an argument
+	// reason and returns the result of invoking transformerDict["cancel"] with argument list « reason »
+	// and callback this value transformer.
+	if transformerDict.cancelSet {
+		cancel, _ := goja.AssertFunction(transformerDict.Cancel)
+		cancelAlgorithm = func(reason goja.Value) *goja.Promise {
+			return promiseFromCall(stream.vu, cancel, transformer, reason)
+		}
+	}
+
+	// 8. Perform ! SetUpTransformStreamDefaultController(stream, controller, transformAlgorithm,
+	// flushAlgorithm, cancelAlgorithm).
+	stream.setupDefaultController(controller, transformAlgorithm, flushAlgorithm, cancelAlgorithm)
+}
+
+// setupDefaultController implements the specification's [SetUpTransformStreamDefaultController]
+// abstract operation.
+//
+// [SetUpTransformStreamDefaultController]: https://streams.spec.whatwg.org/#set-up-transform-stream-default-controller
+func (stream *TransformStream) setupDefaultController(
+	controller *TransformStreamDefaultController,
+	transformAlgorithm TransformerTransformCallback,
+	flushAlgorithm TransformerFlushCallback,
+	cancelAlgorithm TransformerCancelCallback,
+) {
+	// 1. Assert: stream implements TransformStream.
+	// 2. Assert: stream.[[controller]] is undefined.
+	if stream.controller != nil {
+		common.Throw(stream.runtime, newError(AssertionError, "stream.[[controller]] is not undefined"))
+	}
+
+	// 3. Set controller.[[stream]] to stream.
+	controller.stream = stream
+
+	// 4. Set stream.[[controller]] to controller.
+	stream.controller = controller
+
+	// 5. Set controller.[[transformAlgorithm]] to transformAlgorithm.
+	controller.transformAlgorithm = transformAlgorithm
+
+	// 6. Set controller.[[flushAlgorithm]] to flushAlgorithm.
+	controller.flushAlgorithm = flushAlgorithm
+
+	// 7. Set controller.[[cancelAlgorithm]] to cancelAlgorithm.
+	controller.cancelAlgorithm = cancelAlgorithm
+}
@@ -0,0 +1,27 @@
+//go:build wpt
+
+package streams
+
+import "testing"
+
+func TestTransformStreamWPT(t *testing.T) {
+	t.Parallel()
+
+	// Excluded suites:
+	//  - patched-global.any.js: it patches Promise.prototype.then, which the module
+	//    uses to react to the promises, unlike the browsers.
+	//  - properties.any.js: the methods and attributes of the streams are defined on
+	//    their instances, not on their prototypes.
+	suites := []string{
+		"backpressure.any.js",
+		"errors.any.js",
+		"flush.any.js",
+		"general.any.js",
+		"lipfuzz.any.js",
+		"reentrant-strategies.any.js",
+		"strategies.any.js",
+		"terminate.any.js",
+	}
+
+	runTestSuites(t, "tests/wpt/streams/transform-streams", suites)
+}
@@ -0,0 +1,104 @@
+package streams
+
+import (
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+)
+
+// Transformer represents the transformer of a TransformStream, and defines how the chunks
+// written to its writable side are transformed into the chunks read from its readable side.
+//
+// [specification]: https://streams.spec.whatwg.org/#transformer-api
+type Transformer struct {
+	// Start is called immediately during the creation of a TransformStream.
+	//
+	// Typically, this is used to enqueue prefix chunks, using the controller. If the setup
+	// process is asynchronous, it can return a Promise to signal success or failure.
+	Start goja.Value `json:"start"`
+
+	// Transform is called when a new chunk originally written to the writable side is ready
+	// to be transformed.
+	//
+	// If no transform function is provided, the identity transform is used, which enqueues
+	// chunks unchanged from the writable side to the readable side.
+	Transform goja.Value `json:"transform"`
+
+	// Flush is called after all chunks written to the writable side have been transformed,
+	// and the writable side is about to be closed.
+	Flush goja.Value `json:"flush"`
+
+	// Cancel is called when the readable side is cancelled, or the writable side is aborted.
+	Cancel goja.Value `json:"cancel"`
+
+	// ReadableType is reserved for future use, and any attempts to supply a value will throw an exception.
+	ReadableType goja.Value `json:"readableType"`
+
+	// WritableType is reserved for future use, and any attempts to supply a value will throw an exception.
+	WritableType goja.Value `json:"writableType"`
+
+	// startSet is true if the start function was set by the user.
+	startSet bool
+
+	// transformSet is true if the transform function was set by the user.
+	transformSet bool
+
+	// flushSet is true if the flush function was set by the user.
+	flushSet bool
+
+	// cancelSet is true if the cancel function was set by the user.
+	cancelSet bool
+}
+
+// TransformerTransformCallback is a function that is called when a new chunk is ready to be transformed.
+type TransformerTransformCallback func(chunk goja.Value) *goja.Promise
+
+// TransformerFlushCallback is a function that is called once all the written chunks have been transformed.
+type TransformerFlushCallback func() *goja.Promise
+
+// TransformerCancelCallback is a function that is called when the stream is cancelled or aborted.
+type TransformerCancelCallback func(reason goja.Value) *goja.Promise
+
+// NewTransformerFromObject creates a new Transformer from a goja.Object.
+func NewTransformerFromObject(rt *goja.Runtime, obj *goja.Object) (Transformer, error) {
+	var transformer Transformer
+
+	if common.IsNullish(obj) {
+		// If the user didn't provide a transformer, use the identity transform.
+		return transformer, nil
+	}
+
+	if err := rt.ExportTo(obj, &transformer); err != nil {
+		return transformer, newTypeError(rt, "invalid transformer object")
+	}
+
+	// The transformer.[[readableType]] and transformer.[[writableType]] are reserved for future use.
+	if !common.IsNullish(transformer.ReadableType) {
+		return transformer, newRangeError(rt, "transformer.readableType must not be set")
+	}
+	if !common.IsNullish(transformer.WritableType) {
+		return transformer, newRangeError(rt, "transformer.writableType must not be set")
+	}
+
+	for _, member := range []struct {
+		name  string
+		value goja.Value
+		set   *bool
+	}{
+		{"start", transformer.Start, &transformer.startSet},
+		{"transform", transformer.Transform, &transformer.transformSet},
+		{"flush", transformer.Flush, &transformer.flushSet},
+		{"cancel", transformer.Cancel, &transformer.cancelSet},
+	} {
+		if common.IsNullish(member.value) {
+			continue
+		}
+
+		if _, ok := goja.AssertFunction(member.value); !ok {
+			return transformer, newTypeError(rt, "transformer."+member.name+" must be a function")
+		}
+
+		*member.set = true
+	}
+
+	return transformer, nil
+}
@@ -0,0 +1,106 @@
+package streams
+
+import (
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+)
+
+// UnderlyingSink represents the underlying sink of a WritableStream, and defines how
+// the data written to the stream is written to the sink.
+//
+// [specification]: https://streams.spec.whatwg.org/#underlying-sink-api
+type UnderlyingSink struct {
+	// Start is called immediately during the creation of a WritableStream.
+	//
+	// Typically, this is used to acquire access to the underlying sink resource being represented.
+	// If the setup process is asynchronous, it can return a Promise to signal success or
+	// failure; a rejected promise will error the stream.
+	Start goja.Value `json:"start"`
+
+	// Write is called when a new chunk of data is ready to be written to the underlying sink.
+	//
+	// It is guaranteed to be called only after previous writes have succeeded, and never
+	// before `start()` has succeeded or after `close()` or `abort()` have been called.
+	Write goja.Value `json:"write"`
+
+	// Close is called after the producer signals, via `writer.close()`, that they are done
+	// writing chunks to the stream, and subsequently all queued-up writes have successfully
+	// completed.
+	Close goja.Value `json:"close"`
+
+	// Abort is called after the producer signals, via `stream.abort()` or `writer.abort()`,
+	// that they wish to abort the stream. It takes as its argument the same value as was
+	// passed to those methods by the producer.
+	Abort goja.Value `json:"abort"`
+
+	// Type is reserved for future use, and any attempts to supply a value will throw an exception.
+	Type goja.Value `json:"type"`
+
+	// startSet is true if the start function was set by the user.
+	startSet bool
+
+	// writeSet is true if the write function was set by the user.
+	writeSet bool
+
+	// closeSet is true if the close function was set by the user.
+	closeSet bool
+
+	// abortSet is true if the abort function was set by the user.
+	abortSet bool
+}
+
+// UnderlyingSinkStartCallback is a function that is called immediately during the creation of a WritableStream.
+type UnderlyingSinkStartCallback func(controller *goja.Object) goja.Value
+
+// UnderlyingSinkWriteCallback is a function that is called when a new chunk of data is ready to be
+// written to the underlying sink.
+type UnderlyingSinkWriteCallback func(chunk goja.Value) *goja.Promise
+
+// UnderlyingSinkCloseCallback is a function that is called once all the written chunks have
+// been written to the underlying sink, and the stream is closing.
+type UnderlyingSinkCloseCallback func() *goja.Promise
+
+// UnderlyingSinkAbortCallback is a function that is called when the stream is aborted.
+type UnderlyingSinkAbortCallback func(reason goja.Value) *goja.Promise
+
+// NewUnderlyingSinkFromObject creates a new UnderlyingSink from a goja.Object.
+func NewUnderlyingSinkFromObject(rt *goja.Runtime, obj *goja.Object) (UnderlyingSink, error) {
+	var underlyingSink UnderlyingSink
+
+	if common.IsNullish(obj) {
+		// If the user didn't provide an underlying sink, use the default one.
+		return underlyingSink, nil
+	}
+
+	if err := rt.ExportTo(obj, &underlyingSink); err != nil {
+		return underlyingSink, newTypeError(rt, "invalid underlying sink object")
+	}
+
+	// The underlyingSink.[[type]] is reserved for future use.
+	if !common.IsNullish(underlyingSink.Type) {
+		return underlyingSink, newRangeError(rt, "underlyingSink.type must not be set")
+	}
+
+	for _, member := range []struct {
+		name  string
+		value goja.Value
+		set   *bool
+	}{
+		{"start", underlyingSink.Start, &underlyingSink.startSet},
+		{"write", underlyingSink.Write, &underlyingSink.writeSet},
+		{"close", underlyingSink.Close, &underlyingSink.closeSet},
+		{"abort", underlyingSink.Abort, &underlyingSink.abortSet},
+	} {
+		if common.IsNullish(member.value) {
+			continue
+		}
+
+		if _, ok := goja.AssertFunction(member.value); !ok {
+			return underlyingSink, newTypeError(rt, "underlyingSink."+member.name+" must be a function")
+		}
+
+		*member.set = true
+	}
+
+	return underlyingSink, nil
+}
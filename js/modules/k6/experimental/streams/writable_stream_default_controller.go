@@ -0,0 +1,388 @@
+package streams
+
+import (
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+)
+
+// WritableStreamDefaultController is the default controller for a WritableStream. It
+// allows the underlying sink to control the stream's state.
+//
+// For more details, see the [specification].
+//
+// [specification]: https://streams.spec.whatwg.org/#ws-default-controller-class
+type WritableStreamDefaultController struct {
+	// abortAlgorithm is a promise-returning algorithm, taking one argument (the abort reason),
+	// which communicates a requested abort to the underlying sink.
+	abortAlgorithm UnderlyingSinkAbortCallback
+
+	// closeAlgorithm is a promise-returning algorithm which communicates a requested close to
+	// the underlying sink.
+	closeAlgorithm UnderlyingSinkCloseCallback
+
+	// queue is a list representing the stream's internal queue of chunks.
+	queue *QueueWithSizes
+
+	// started is a boolean flag indicating whether the underlying sink has finished starting.
+	started bool
+
+	// strategyHWM is a number supplied by the creator of the stream as part of the stream's
+	// queuing strategy, indicating the point at which the stream will apply backpressure to
+	// its underlying sink.
+	strategyHWM float64
+
+	// strategySizeAlgorithm is an algorithm to calculate the size of enqueued chunks, as part
+	// of the stream's queuing strategy.
+	strategySizeAlgorithm SizeAlgorithm
+
+	// stream is the WritableStream instance controlled.
+	stream *WritableStream
+
+	// writeAlgorithm is a promise-returning algorithm, taking one argument (the chunk to write),
+	// which writes data to the underlying sink.
+	writeAlgorithm UnderlyingSinkWriteCallback
+
+	// closeSentinel is the value enqueued to signal that the stream is closing. It is unique
+	// to the controller, so it cannot be confused with any chunk written to the stream.
+	closeSentinel *goja.Object
+
+	// object holds the [goja.Object] representing the controller, which is passed to the
+	// underlying sink's algorithms.
+	object *goja.Object
+}
+
+// NewWritableStreamDefaultControllerObject creates a new [goja.Object] from a
+// [WritableStreamDefaultController] instance.
+func NewWritableStreamDefaultControllerObject(controller *WritableStreamDefaultController) (*goja.Object, error) {
+	rt := controller.stream.runtime
+	obj := rt.NewObject()
+	objName := "WritableStreamDefaultController"
+
+	if err := setReadOnlyPropertyOf(obj, objName, "error", rt.ToValue(controller.Error)); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// Error errors the stream, making all future interactions with it fail with the given error.
+//
+// It implements the WritableStreamDefaultController.error(e) [specification] algorithm.
+//
+// [specification]: https://streams.spec.whatwg.org/#ws-default-controller-error
+func (controller *WritableStreamDefaultController) Error(e goja.Value) {
+	if e == nil {
+		e = goja.Undefined()
+	}
+
+	// 1. Let state be this.[[stream]].[[state]].
+	// 2. If state is not "writable", return.
+	if controller.stream.state != WritableStreamStateWritable {
+		return
+	}
+
+	// 3. Perform ! WritableStreamDefaultControllerError(this, e).
+	controller.error(e)
+}
+
+// abortSteps implements the controller's [AbortSteps] contract.
+//
+// [AbortSteps]: https://streams.spec.whatwg.org/#ws-default-controller-private-abort
+func (controller *WritableStreamDefaultController) abortSteps(reason goja.Value) *goja.Promise {
+	// 1. Let result be the result of performing this.[[abortAlgorithm]], passing reason.
+	result := controller.abortAlgorithm(reason)
+
+	// 2. Perform ! WritableStreamDefaultControllerClearAlgorithms(this).
+	controller.clearAlgorithms()
+
+	// 3. Return result.
+	return result
+}
+
+// errorSteps implements the controller's [ErrorSteps] contract.
+//
+// [ErrorSteps]: https://streams.spec.whatwg.org/#ws-default-controller-private-error
+func (controller *WritableStreamDefaultController) errorSteps() {
+	// 1. Perform ! ResetQueue(this).
+	controller.resetQueue()
+}
+
+func (controller *WritableStreamDefaultController) toObject() *goja.Object {
+	if controller.object == nil {
+		obj, err := NewWritableStreamDefaultControllerObject(controller)
+		if err != nil {
+			common.Throw(controller.stream.runtime, newError(RuntimeError, err.Error()))
+		}
+		controller.object = obj
+	}
+
+	return controller.object
+}
+
+// resetQueue implements the [ResetQueue] abstract operation.
+//
+// [ResetQueue]: https://streams.spec.whatwg.org/#reset-queue
+func (controller *WritableStreamDefaultController) resetQueue() {
+	controller.queue = NewQueueWithSizes(controller.stream.runtime)
+}
+
+// advanceQueueIfNeeded implements the [WritableStreamDefaultControllerAdvanceQueueIfNeeded] algorithm.
+//
+// [WritableStreamDefaultControllerAdvanceQueueIfNeeded]: https://streams.spec.whatwg.org/#writable-stream-default-controller-advance-queue-if-needed
+func (controller *WritableStreamDefaultController) advanceQueueIfNeeded() {
+	// 1. Let stream be controller.[[stream]].
+	stream := controller.stream
+
+	// 2. If controller.[[started]] is false, return.
+	if !controller.started {
+		return
+	}
+
+	// 3. If stream.[[inFlightWriteRequest]] is not undefined, return.
+	if stream.inFlightWriteRequest != nil {
+		return
+	}
+
+	// 4. Let state be stream.[[state]].
+	// 5. Assert: state is not "closed" or "errored".
+	// 6. If state is "erroring",
+	if stream.state == WritableStreamStateErroring {
+		// 6.1. Perform ! WritableStreamFinishErroring(stream).
+		stream.finishErroring()
+		// 6.2. Return.
+		return
+	}
+
+	// 7. If controller.[[queue]] is empty, return.
+	if controller.queue.Len() == 0 {
+		return
+	}
+
+	// 8. Let value be ! PeekQueueValue(controller).
+	value, err := controller.queue.Peek()
+	if err != nil {
+		common.Throw(stream.runtime, newError(AssertionError, err.Error()))
+	}
+
+	// 9. If value is the close sentinel, perform ! WritableStreamDefaultControllerProcessClose(controller).
+	if value == controller.closeSentinel {
+		controller.processClose()
+	} else { // 10. Otherwise, perform ! WritableStreamDefaultControllerProcessWrite(controller, value).
+		controller.processWrite(value)
+	}
+}
+
+// clearAlgorithms implements the [WritableStreamDefaultControllerClearAlgorithms] algorithm.
+//
+// [WritableStreamDefaultControllerClearAlgorithms]: https://streams.spec.whatwg.org/#writable-stream-default-controller-clear-algorithms
+func (controller *WritableStreamDefaultController) clearAlgorithms() {
+	// 1. Set controller.[[writeAlgorithm]] to undefined.
+	controller.writeAlgorithm = nil
+
+	// 2. Set controller.[[closeAlgorithm]] to undefined.
+	controller.closeAlgorithm = nil
+
+	// 3. Set controller.[[abortAlgorithm]] to undefined.
+	controller.abortAlgorithm = nil
+
+	// 4. Set controller.[[strategySizeAlgorithm]] to undefined.
+	controller.strategySizeAlgorithm = nil
+}
+
+// close implements the [WritableStreamDefaultControllerClose] algorithm.
+//
+// [WritableStreamDefaultControllerClose]: https://streams.spec.whatwg.org/#writable-stream-default-controller-close
+func (controller *WritableStreamDefaultController) close() {
+	if controller.closeSentinel == nil {
+		controller.closeSentinel = controller.stream.runtime.NewObject()
+	}
+
+	// 1. Perform ! EnqueueValueWithSize(controller, close sentinel, 0).
+	if err := controller.queue.Enqueue(controller.closeSentinel, 0); err != nil {
+		common.Throw(controller.stream.runtime, newError(AssertionError, err.Error()))
+	}
+
+	// 2. Perform ! WritableStreamDefaultControllerAdvanceQueueIfNeeded(controller).
+	controller.advanceQueueIfNeeded()
+}
+
+// error implements the [WritableStreamDefaultControllerError] algorithm.
+//
+// [WritableStreamDefaultControllerError]: https://streams.spec.whatwg.org/#writable-stream-default-controller-error
+func (controller *WritableStreamDefaultController) error(e goja.Value) {
+	// 1. Let stream be controller.[[stream]].
+	stream := controller.stream
+
+	// 2. Assert: stream.[[state]] is "writable".
+	// 3. Perform ! WritableStreamDefaultControllerClearAlgorithms(controller).
+	controller.clearAlgorithms()
+
+	// 4. Perform ! WritableStreamStartErroring(stream, error).
+	stream.startErroring(e)
+}
+
+// errorIfNeeded implements the [WritableStreamDefaultControllerErrorIfNeeded] algorithm.
+//
+// [WritableStreamDefaultControllerErrorIfNeeded]: https://streams.spec.whatwg.org/#writable-stream-default-controller-error-if-needed
+func (controller *WritableStreamDefaultController) errorIfNeeded(e goja.Value) {
+	// 1. If controller.[[stream]].[[state]] is "writable", perform ! WritableStreamDefaultControllerError(controller, error).
+	if controller.stream.state == WritableStreamStateWritable {
+		controller.error(e)
+	}
+}
+
+// getBackpressure implements the [WritableStreamDefaultControllerGetBackpressure] algorithm.
+//
+// [WritableStreamDefaultControllerGetBackpressure]: https://streams.spec.whatwg.org/#writable-stream-default-controller-get-backpressure
+func (controller *WritableStreamDefaultController) getBackpressure() bool {
+	// 1. Let desiredSize be ! WritableStreamDefaultControllerGetDesiredSize(controller).
+	// 2. Return true if desiredSize ≤ 0, or false otherwise.
+	return controller.getDesiredSize() <= 0
+}
+
+// getChunkSize implements the [WritableStreamDefaultControllerGetChunkSize] algorithm.
+//
+// [WritableStreamDefaultControllerGetChunkSize]: https://streams.spec.whatwg.org/#writable-stream-default-controller-get-chunk-size
+func (controller *WritableStreamDefaultController) getChunkSize(chunk goja.Value) float64 {
+	// 1. If controller.[[strategySizeAlgorithm]] is undefined, then:
+	if controller.strategySizeAlgorithm == nil {
+		// 1.1. Assert: controller.[[stream]].[[state]] is not "writable".
+		// 1.2. Return 1.
+		return 1
+	}
+
+	// 2. Let returnValue be the result of performing controller.[[strategySizeAlgorithm]], passing in
+	// chunk, and interpreting the result as a completion record.
+	size, err := controller.strategySizeAlgorithm(goja.Undefined(), chunk)
+
+	// 3. If returnValue is an abrupt completion,
+	if err != nil {
+		// 3.1. Perform ! WritableStreamDefaultControllerErrorIfNeeded(controller, returnValue.[[Value]]).
+		controller.errorIfNeeded(controller.stream.runtime.ToValue(reasonValue(err)))
+		// 3.2. Return 1.
+		return 1
+	}
+
+	// 4. Return returnValue.[[Value]].
+	return size.ToFloat()
+}
+
+// getDesiredSize implements the [WritableStreamDefaultControllerGetDesiredSize] algorithm.
+//
+// [WritableStreamDefaultControllerGetDesiredSize]: https://streams.spec.whatwg.org/#writable-stream-default-controller-get-desired-size
+func (controller *WritableStreamDefaultController) getDesiredSize() float64 {
+	// 1. Return controller.[[strategyHWM]] − controller.[[queueTotalSize]].
+	return controller.strategyHWM - controller.queue.QueueTotalSize
+}
+
+// processClose implements the [WritableStreamDefaultControllerProcessClose] algorithm.
+//
+// [WritableStreamDefaultControllerProcessClose]: https://streams.spec.whatwg.org/#writable-stream-default-controller-process-close
+func (controller *WritableStreamDefaultController) processClose() {
+	// 1. Let stream be controller.[[stream]].
+	stream := controller.stream
+
+	// 2. Perform ! WritableStreamMarkCloseRequestInFlight(stream).
+	stream.markCloseRequestInFlight()
+
+	// 3. Perform ! DequeueValue(controller).
+	if _, err := controller.queue.Dequeue(); err != nil {
+		common.Throw(stream.runtime, err)
+	}
+
+	// 4. Assert: controller.[[queue]] is empty.
+	// 5. Let sinkClosePromise be the result of performing controller.[[closeAlgorithm]].
+	sinkClosePromise := controller.closeAlgorithm()
+
+	// 6. Perform ! WritableStreamDefaultControllerClearAlgorithms(controller).
+	controller.clearAlgorithms()
+
+	uponPromise(stream.runtime, sinkClosePromise,
+		// 7. Upon fulfillment of sinkClosePromise,
+		func(goja.Value) {
+			// 7.1. Perform ! WritableStreamFinishInFlightClose(stream).
+			stream.finishInFlightClose()
+		},
+		// 8. Upon rejection of sinkClosePromise with reason reason,
+		func(reason goja.Value) {
+			// 8.1. Perform ! WritableStreamFinishInFlightCloseWithError(stream, reason).
+			stream.finishInFlightCloseWithError(reason)
+		},
+	)
+}
+
+// processWrite implements the [WritableStreamDefaultControllerProcessWrite] algorithm.
+//
+// [WritableStreamDefaultControllerProcessWrite]: https://streams.spec.whatwg.org/#writable-stream-default-controller-process-write
+func (controller *WritableStreamDefaultController) processWrite(chunk goja.Value) {
+	// 1. Let stream be controller.[[stream]].
+	stream := controller.stream
+
+	// 2. Perform ! WritableStreamMarkFirstWriteRequestInFlight(stream).
+	stream.markFirstWriteRequestInFlight()
+
+	// 3. Let sinkWritePromise be the result of performing controller.[[writeAlgorithm]], passing in chunk.
+	sinkWritePromise := controller.writeAlgorithm(chunk)
+
+	uponPromise(stream.runtime, sinkWritePromise,
+		// 4. Upon fulfillment of sinkWritePromise,
+		func(goja.Value) {
+			// 4.1. Perform ! WritableStreamFinishInFlightWrite(stream).
+			stream.finishInFlightWrite()
+
+			// 4.2. Let state be stream.[[state]].
+			// 4.3. Assert: state is "writable" or "erroring".
+			// 4.4. Perform ! DequeueValue(controller).
+			if _, err := controller.queue.Dequeue(); err != nil {
+				common.Throw(stream.runtime, err)
+			}
+
+			// 4.5. If ! WritableStreamCloseQueuedOrInFlight(stream) is false and state is "writable",
+			if !stream.closeQueuedOrInFlight() && stream.state == WritableStreamStateWritable {
+				// 4.5.1. Let backpressure be ! WritableStreamDefaultControllerGetBackpressure(controller).
+				// 4.5.2. Perform ! WritableStreamUpdateBackpressure(stream, backpressure).
+				stream.updateBackpressure(controller.getBackpressure())
+			}
+
+			// 4.6. Perform ! WritableStreamDefaultControllerAdvanceQueueIfNeeded(controller).
+			controller.advanceQueueIfNeeded()
+		},
+		// 5. Upon rejection of sinkWritePromise with reason,
+		func(reason goja.Value) {
+			// 5.1. If stream.[[state]] is "writable", perform ! WritableStreamDefaultControllerClearAlgorithms(controller).
+			if stream.state == WritableStreamStateWritable {
+				controller.clearAlgorithms()
+			}
+
+			// 5.2. Perform ! WritableStreamFinishInFlightWriteWithError(stream, reason).
+			stream.finishInFlightWriteWithError(reason)
+		},
+	)
+}
+
+// write implements the [WritableStreamDefaultControllerWrite] algorithm.
+//
+// [WritableStreamDefaultControllerWrite]: https://streams.spec.whatwg.org/#writable-stream-default-controller-write
+func (controller *WritableStreamDefaultController) write(chunk goja.Value, chunkSize float64) {
+	// 1. Let enqueueResult be EnqueueValueWithSize(controller, chunk, chunkSize).
+	// 2. If enqueueResult is an abrupt completion,
+	if err := controller.queue.Enqueue(chunk, chunkSize); err != nil {
+		// 2.1. Perform ! WritableStreamDefaultControllerErrorIfNeeded(controller, enqueueResult.[[Value]]).
+		controller.errorIfNeeded(controller.stream.runtime.ToValue(reasonValue(err)))
+		// 2.2. Return.
+		return
+	}
+
+	// 3. Let stream be controller.[[stream]].
+	stream := controller.stream
+
+	// 4. If ! WritableStreamCloseQueuedOrInFlight(stream) is false and stream.[[state]] is "writable",
+	if !stream.closeQueuedOrInFlight() && stream.state == WritableStreamStateWritable {
+		// 4.1. Let backpressure be ! WritableStreamDefaultControllerGetBackpressure(controller).
+		// 4.2. Perform ! WritableStreamUpdateBackpressure(stream, backpressure).
+		stream.updateBackpressure(controller.getBackpressure())
+	}
+
+	// 5. Perform ! WritableStreamDefaultControllerAdvanceQueueIfNeeded(controller).
+	controller.advanceQueueIfNeeded()
+}
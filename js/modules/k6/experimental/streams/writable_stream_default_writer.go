@@ -0,0 +1,403 @@
+package streams
+
+import (
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+// WritableStreamDefaultWriter represents a writer designed to be vended by a [WritableStream].
+//
+// [specification]: https://streams.spec.whatwg.org/#default-writer-class
+type WritableStreamDefaultWriter struct {
+	// closedPromise is a promise returned by the writer's closed getter.
+	closedPromise *promiseCapability
+
+	// readyPromise is a promise returned by the writer's ready getter.
+	readyPromise *promiseCapability
+
+	// stream is the [WritableStream] instance that owns this writer.
+	stream *WritableStream
+
+	runtime *goja.Runtime
+	vu      modules.VU
+}
+
+// NewWritableStreamDefaultWriterObject creates a new goja.Object from a [WritableStreamDefaultWriter] instance.
+func NewWritableStreamDefaultWriterObject(writer *WritableStreamDefaultWriter) (*goja.Object, error) {
+	rt := writer.stream.runtime
+	obj := rt.NewObject()
+	objName := "WritableStreamDefaultWriter"
+
+	err := obj.DefineAccessorProperty("closed", rt.ToValue(func() *goja.Promise {
+		return writer.closedPromise.promise
+	}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.DefineAccessorProperty("ready", rt.ToValue(func() *goja.Promise {
+		return writer.readyPromise.promise
+	}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.DefineAccessorProperty("desiredSize", rt.ToValue(func() goja.Value {
+		// 1. If this.[[stream]] is undefined, throw a TypeError exception.
+		if writer.stream == nil {
+			throw(rt, newTypeError(rt, "the writer has been released"))
+		}
+
+		// 2. Return ! WritableStreamDefaultWriterGetDesiredSize(this).
+		return writer.getDesiredSize()
+	}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setReadOnlyPropertyOf(obj, objName, "abort", rt.ToValue(writer.Abort)); err != nil {
+		return nil, err
+	}
+
+	if err := setReadOnlyPropertyOf(obj, objName, "close", rt.ToValue(writer.Close)); err != nil {
+		return nil, err
+	}
+
+	if err := setReadOnlyPropertyOf(obj, objName, "releaseLock", rt.ToValue(writer.ReleaseLock)); err != nil {
+		return nil, err
+	}
+
+	if err := setReadOnlyPropertyOf(obj, objName, "write", rt.ToValue(writer.Write)); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// Abort aborts the stream the writer is locked to.
+//
+// It implements the WritableStreamDefaultWriter.abort(reason) [specification] algorithm.
+//
+// [specification]: https://streams.spec.whatwg.org/#default-writer-abort
+func (writer *WritableStreamDefaultWriter) Abort(reason goja.Value) *goja.Promise {
+	// 1. If this.[[stream]] is undefined, return a promise rejected with a TypeError exception.
+	if writer.stream == nil {
+		return writer.rejectedWithReleasedError()
+	}
+
+	// 2. Return ! WritableStreamDefaultWriterAbort(this, reason).
+	return writer.stream.abort(reason)
+}
+
+// Close closes the stream the writer is locked to.
+//
+// It implements the WritableStreamDefaultWriter.close() [specification] algorithm.
+//
+// [specification]: https://streams.spec.whatwg.org/#default-writer-close
+func (writer *WritableStreamDefaultWriter) Close() *goja.Promise {
+	// 1. Let stream be this.[[stream]].
+	stream := writer.stream
+
+	// 2. If stream is undefined, return a promise rejected with a TypeError exception.
+	if stream == nil {
+		return writer.rejectedWithReleasedError()
+	}
+
+	// 3. If ! WritableStreamCloseQueuedOrInFlight(stream) is true, return a promise rejected with a TypeError exception.
+	if stream.closeQueuedOrInFlight() {
+		return newRejectedPromise(stream.vu, newTypeError(stream.runtime, "the stream is already closing").Err())
+	}
+
+	// 4. Return ! WritableStreamDefaultWriterClose(this).
+	return writer.close()
+}
+
+// ReleaseLock releases the writer's lock on the stream.
+//
+// It implements the WritableStreamDefaultWriter.releaseLock() [specification] algorithm.
+//
+// [specification]: https://streams.spec.whatwg.org/#default-writer-release-lock
+func (writer *WritableStreamDefaultWriter) ReleaseLock() {
+	// 1. Let stream be this.[[stream]].
+	// 2. If stream is undefined, return.
+	if writer.stream == nil {
+		return
+	}
+
+	// 3. Assert: stream.[[writer]] is not undefined.
+	// 4. Perform ! WritableStreamDefaultWriterRelease(this).
+	writer.release()
+}
+
+// Write writes the given chunk to the stream, and returns a promise fulfilled once the chunk
+// has been successfully written to the underlying sink.
+//
+// It implements the WritableStreamDefaultWriter.write(chunk) [specification] algorithm.
+//
+// [specification]: https://streams.spec.whatwg.org/#default-writer-write
+func (writer *WritableStreamDefaultWriter) Write(chunk goja.Value) *goja.Promise {
+	if chunk == nil {
+		chunk = goja.Undefined()
+	}
+
+	// 1. If this.[[stream]] is undefined, return a promise rejected with a TypeError exception.
+	if writer.stream == nil {
+		return writer.rejectedWithReleasedError()
+	}
+
+	// 2. Return ! WritableStreamDefaultWriterWrite(this, chunk).
+	return writer.write(chunk)
+}
+
+// rejectedWithReleasedError returns a promise rejected with the error of a released writer.
+func (writer *WritableStreamDefaultWriter) rejectedWithReleasedError() *goja.Promise {
+	return newRejectedPromise(writer.vu, newTypeError(writer.runtime, "the writer has been released").Err())
+}
+
+// setup implements the [SetUpWritableStreamDefaultWriter] algorithm.
+//
+// [SetUpWritableStreamDefaultWriter]: https://streams.spec.whatwg.org/#set-up-writable-stream-default-writer
+func (writer *WritableStreamDefaultWriter) setup(stream *WritableStream) {
+	rt := stream.runtime
+	writer.runtime, writer.vu = rt, stream.vu
+
+	// 1. If ! IsWritableStreamLocked(stream) is true, throw a TypeError exception.
+	if stream.isLocked() {
+		throw(rt, newTypeError(rt, "stream is locked"))
+	}
+
+	// 2. Set writer.[[stream]] to stream.
+	writer.stream = stream
+
+	// 3. Set stream.[[writer]] to writer.
+	stream.writer = writer
+	stream.Locked = true
+
+	// 4. Let state be stream.[[state]].
+	switch stream.state {
+	// 5. If state is "writable",
+	case WritableStreamStateWritable:
+		// 5.1. If ! WritableStreamCloseQueuedOrInFlight(stream) is false and stream.[[backpressure]] is true,
+		// set writer.[[readyPromise]] to a new promise.
+		// 5.2. Otherwise, set writer.[[readyPromise]] to a promise resolved with undefined.
+		writer.readyPromise = newPromiseCapability(rt)
+		if stream.closeQueuedOrInFlight() || !stream.backpressure {
+			writer.readyPromise.resolve(goja.Undefined())
+		}
+
+		// 5.3. Set writer.[[closedPromise]] to a new promise.
+		writer.closedPromise = newPromiseCapability(rt)
+
+	// 6. Otherwise, if state is "erroring",
+	case WritableStreamStateErroring:
+		// 6.1. Set writer.[[readyPromise]] to a promise rejected with stream.[[storedError]].
+		writer.readyPromise = newPromiseCapability(rt)
+		writer.readyPromise.reject(stream.storedError)
+
+		// 6.2. Set writer.[[readyPromise]].[[PromiseIsHandled]] to true.
+		setPromiseIsHandled(rt, writer.readyPromise.promise)
+
+		// 6.3. Set writer.[[closedPromise]] to a new promise.
+		writer.closedPromise = newPromiseCapability(rt)
+
+	// 7. Otherwise, if state is "closed",
+	case WritableStreamStateClosed:
+		// 7.1. Set writer.[[readyPromise]] to a promise resolved with undefined.
+		writer.readyPromise = newPromiseCapability(rt)
+		writer.readyPromise.resolve(goja.Undefined())
+
+		// 7.2. Set writer.[[closedPromise]] to a promise resolved with undefined.
+		writer.closedPromise = newPromiseCapability(rt)
+		writer.closedPromise.resolve(goja.Undefined())
+
+	// 8. Otherwise,
+	default:
+		// 8.1. Assert: state is "errored".
+		// 8.2. Let storedError be stream.[[storedError]].
+		storedError := stream.storedError
+
+		// 8.3. Set writer.[[readyPromise]] to a promise rejected with storedError.
+		// 8.4. Set writer.[[readyPromise]].[[PromiseIsHandled]] to true.
+		writer.readyPromise = newPromiseCapability(rt)
+		writer.readyPromise.reject(storedError)
+		setPromiseIsHandled(rt, writer.readyPromise.promise)
+
+		// 8.5. Set writer.[[closedPromise]] to a promise rejected with storedError.
+		// 8.6. Set writer.[[closedPromise]].[[PromiseIsHandled]] to true.
+		writer.closedPromise = newPromiseCapability(rt)
+		writer.closedPromise.reject(storedError)
+		setPromiseIsHandled(rt, writer.closedPromise.promise)
+	}
+}
+
+// close implements the [WritableStreamDefaultWriterClose] algorithm.
+//
+// [WritableStreamDefaultWriterClose]: https://streams.spec.whatwg.org/#writable-stream-default-writer-close
+func (writer *WritableStreamDefaultWriter) close() *goja.Promise {
+	// 1. Let stream be writer.[[stream]].
+	// 2. Assert: stream is not undefined.
+	// 3. Return ! WritableStreamClose(stream).
+	return writer.stream.close()
+}
+
+// closeWithErrorPropagation implements the [WritableStreamDefaultWriterCloseWithErrorPropagation] algorithm.
+//
+// [WritableStreamDefaultWriterCloseWithErrorPropagation]: https://streams.spec.whatwg.org/#writable-stream-default-writer-close-with-error-propagation
+func (writer *WritableStreamDefaultWriter) closeWithErrorPropagation() *goja.Promise {
+	// 1. Let stream be writer.[[stream]].
+	stream := writer.stream
+
+	// 2. Assert: stream is not undefined.
+	if stream == nil {
+		common.Throw(writer.runtime, newError(AssertionError, "stream is undefined"))
+	}
+
+	// 3. Let state be stream.[[state]].
+	state := stream.state
+
+	// 4. If ! WritableStreamCloseQueuedOrInFlight(stream) is true or state is "closed", return a promise
+	// resolved with undefined.
+	if stream.closeQueuedOrInFlight() || state == WritableStreamStateClosed {
+		return newResolvedPromise(writer.vu, goja.Undefined())
+	}
+
+	// 5. If state is "errored", return a promise rejected with stream.[[storedError]].
+	if state == WritableStreamStateErrored {
+		return newRejectedPromise(writer.vu, stream.storedError)
+	}
+
+	// 6. Assert: state is "writable" or "erroring".
+	// 7. Return ! WritableStreamDefaultWriterClose(writer).
+	return writer.close()
+}
+
+// ensureClosedPromiseRejected implements the [WritableStreamDefaultWriterEnsureClosedPromiseRejected] algorithm.
+//
+// [WritableStreamDefaultWriterEnsureClosedPromiseRejected]: https://streams.spec.whatwg.org/#writable-stream-default-writer-ensure-closed-promise-rejected
+func (writer *WritableStreamDefaultWriter) ensureClosedPromiseRejected(err goja.Value) {
+	// 1. If writer.[[closedPromise]].[[PromiseState]] is "pending", reject writer.[[closedPromise]] with error.
+	// 2. Otherwise, set writer.[[closedPromise]] to a promise rejected with error.
+	if !writer.closedPromise.isPending() {
+		writer.closedPromise = newPromiseCapability(writer.runtime)
+	}
+	writer.closedPromise.reject(err)
+
+	// 3. Set writer.[[closedPromise]].[[PromiseIsHandled]] to true.
+	setPromiseIsHandled(writer.runtime, writer.closedPromise.promise)
+}
+
+// ensureReadyPromiseRejected implements the [WritableStreamDefaultWriterEnsureReadyPromiseRejected] algorithm.
+//
+// [WritableStreamDefaultWriterEnsureReadyPromiseRejected]: https://streams.spec.whatwg.org/#writable-stream-default-writer-ensure-ready-promise-rejected
+func (writer *WritableStreamDefaultWriter) ensureReadyPromiseRejected(err goja.Value) {
+	// 1. If writer.[[readyPromise]].[[PromiseState]] is "pending", reject writer.[[readyPromise]] with error.
+	// 2. Otherwise, set writer.[[readyPromise]] to a promise rejected with error.
+	if !writer.readyPromise.isPending() {
+		writer.readyPromise = newPromiseCapability(writer.runtime)
+	}
+	writer.readyPromise.reject(err)
+
+	// 3. Set writer.[[readyPromise]].[[PromiseIsHandled]] to true.
+	setPromiseIsHandled(writer.runtime, writer.readyPromise.promise)
+}
+
+// getDesiredSize implements the [WritableStreamDefaultWriterGetDesiredSize] algorithm.
+//
+// [WritableStreamDefaultWriterGetDesiredSize]: https://streams.spec.whatwg.org/#writable-stream-default-writer-get-desired-size
+func (writer *WritableStreamDefaultWriter) getDesiredSize() goja.Value {
+	// 1. Let stream be writer.[[stream]].
+	stream := writer.stream
+
+	// 2. Let state be stream.[[state]].
+	switch stream.state {
+	// 3. If state is "errored" or "erroring", return null.
+	case WritableStreamStateErrored, WritableStreamStateErroring:
+		return goja.Null()
+	// 4. If state is "closed", return 0.
+	case WritableStreamStateClosed:
+		return writer.runtime.ToValue(0)
+	}
+
+	// 5. Return ! WritableStreamDefaultControllerGetDesiredSize(stream.[[controller]]).
+	return writer.runtime.ToValue(stream.controller.getDesiredSize())
+}
+
+// release implements the [WritableStreamDefaultWriterRelease] algorithm.
+//
+// [WritableStreamDefaultWriterRelease]: https://streams.spec.whatwg.org/#writable-stream-default-writer-release
+func (writer *WritableStreamDefaultWriter) release() {
+	// 1. Let stream be writer.[[stream]].
+	stream := writer.stream
+
+	// 2. Assert: stream is not undefined.
+	// 3. Assert: stream.[[writer]] is writer.
+	if stream == nil || stream.writer != writer {
+		common.Throw(writer.runtime, newError(AssertionError, "stream writer isn't writer"))
+	}
+
+	// 4. Let releasedError be a new TypeError.
+	releasedError := newTypeError(writer.runtime, "the writer has been released").Err()
+
+	// 5. Perform ! WritableStreamDefaultWriterEnsureReadyPromiseRejected(writer, releasedError).
+	writer.ensureReadyPromiseRejected(releasedError)
+
+	// 6. Perform ! WritableStreamDefaultWriterEnsureClosedPromiseRejected(writer, releasedError).
+	writer.ensureClosedPromiseRejected(releasedError)
+
+	// 7. Set stream.[[writer]] to undefined.
+	stream.writer = nil
+	stream.Locked = false
+
+	// 8. Set writer.[[stream]] to undefined.
+	writer.stream = nil
+}
+
+// write implements the [WritableStreamDefaultWriterWrite] algorithm.
+//
+// [WritableStreamDefaultWriterWrite]: https://streams.spec.whatwg.org/#writable-stream-default-writer-write
+func (writer *WritableStreamDefaultWriter) write(chunk goja.Value) *goja.Promise {
+	// 1. Let stream be writer.[[stream]].
+	stream := writer.stream
+
+	// 2. Assert: stream is not undefined.
+	// 3. Let controller be stream.[[controller]].
+	controller := stream.controller
+
+	// 4. Let chunkSize be ! WritableStreamDefaultControllerGetChunkSize(controller, chunk).
+	chunkSize := controller.getChunkSize(chunk)
+
+	// 5. If stream is not equal to writer.[[stream]], return a promise rejected with a TypeError exception.
+	if stream != writer.stream {
+		return writer.rejectedWithReleasedError()
+	}
+
+	// 6. Let state be stream.[[state]].
+	state := stream.state
+
+	// 7. If state is "errored", return a promise rejected with stream.[[storedError]].
+	if state == WritableStreamStateErrored {
+		return newRejectedPromise(writer.vu, stream.storedError)
+	}
+
+	// 8. If ! WritableStreamCloseQueuedOrInFlight(stream) is true or state is "closed", return a promise
+	// rejected with a TypeError exception indicating that the stream is closing or closed.
+	if stream.closeQueuedOrInFlight() || state == WritableStreamStateClosed {
+		return newRejectedPromise(writer.vu, newTypeError(writer.runtime, "the stream is closing or closed").Err())
+	}
+
+	// 9. If state is "erroring", return a promise rejected with stream.[[storedError]].
+	if state == WritableStreamStateErroring {
+		return newRejectedPromise(writer.vu, stream.storedError)
+	}
+
+	// 10. Assert: state is "writable".
+	// 11. Let promise be ! WritableStreamAddWriteRequest(stream).
+	promise := stream.addWriteRequest()
+
+	// 12. Perform ! WritableStreamDefaultControllerWrite(controller, chunk, chunkSize).
+	controller.write(chunk, chunkSize)
+
+	// 13. Return promise.
+	return promise
+}
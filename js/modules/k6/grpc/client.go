@@ -216,41 +216,32 @@ func (c *Client) Connect(addr string, params goja.Value) (bool, error) {
 		return false, fmt.Errorf("invalid grpc.connect() parameters: %w", err)
 	}
 
-	opts := grpcext.DefaultOptions(c.vu.State)
-
-	var tcred credentials.TransportCredentials
+	var tlsCfg *tls.Config
 	if !p.IsPlaintext {
-		tlsCfg := state.TLSConfig.Clone()
+		tlsCfg = state.TLSConfig.Clone()
 		if len(p.TLS) > 0 {
 			if tlsCfg, err = buildTLSConfigFromMap(tlsCfg, p.TLS); err != nil {
 				return false, err
 			}
 		}
-		tlsCfg.NextProtos = []string{"h2"}
-
-		tcred = credentials.NewTLS(tlsCfg)
-	} else {
-		tcred = insecure.NewCredentials()
-	}
-	opts = append(opts, grpc.WithTransportCredentials(tcred))
-
-	if ua := state.Options.UserAgent; ua.Valid {
-		opts = append(opts, grpc.WithUserAgent(ua.ValueOrZero()))
 	}
 
 	ctx, cancel := context.WithTimeout(c.vu.Context(), p.Timeout)
 	defer cancel()
 
-	if p.MaxReceiveSize > 0 {
-		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(int(p.MaxReceiveSize))))
-	}
-
-	if p.MaxSendSize > 0 {
-		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(int(p.MaxSendSize))))
-	}
-
 	c.addr = addr
-	c.conn, err = grpcext.Dial(ctx, addr, opts...)
+	if p.Protocol == grpcext.ProtocolGRPC {
+		c.conn, err = c.dial(ctx, addr, p, tlsCfg)
+	} else {
+		c.conn, err = grpcext.DialWeb(addr, c.vu.State, grpcext.WebOptions{
+			Protocol:       p.Protocol,
+			TLSConfig:      tlsCfg,
+			Plaintext:      p.IsPlaintext,
+			UserAgent:      state.Options.UserAgent.ValueOrZero(),
+			MaxReceiveSize: int(p.MaxReceiveSize),
+			MaxSendSize:    int(p.MaxSendSize),
+		})
+	}
 	if err != nil {
 		return false, err
 	}
@@ -273,6 +264,51 @@ func (c *Client) Connect(addr string, params goja.Value) (bool, error) {
 	return true, err
 }
 
+// dial establishes a native gRPC connection. If it's configured, it first
+// waits for all the backends to be healthy and balances the requests across
+// them.
+func (c *Client) dial(ctx context.Context, addr string, p *connectParams, tlsCfg *tls.Config) (*grpcext.Conn, error) {
+	opts := c.dialOptions(p, tlsCfg)
+
+	if p.HealthCheck {
+		if err := grpcext.WaitForHealthyBackends(ctx, addr, c.vu.State, p.HealthCheckService, opts...); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.LoadBalancing != "" {
+		return grpcext.DialBalanced(ctx, addr, c.vu.State, p.LoadBalancing, opts...)
+	}
+	return grpcext.Dial(ctx, addr, opts...)
+}
+
+// dialOptions returns the options of the native gRPC connections.
+func (c *Client) dialOptions(p *connectParams, tlsCfg *tls.Config) []grpc.DialOption {
+	opts := grpcext.DefaultOptions(c.vu.State)
+
+	var tcred credentials.TransportCredentials
+	if tlsCfg != nil {
+		tlsCfg.NextProtos = []string{"h2"}
+		tcred = credentials.NewTLS(tlsCfg)
+	} else {
+		tcred = insecure.NewCredentials()
+	}
+	opts = append(opts, grpc.WithTransportCredentials(tcred))
+
+	if ua := c.vu.State().Options.UserAgent; ua.Valid {
+		opts = append(opts, grpc.WithUserAgent(ua.ValueOrZero()))
+	}
+
+	if p.MaxReceiveSize > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(int(p.MaxReceiveSize))))
+	}
+
+	if p.MaxSendSize > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(int(p.MaxSendSize))))
+	}
+	return opts
+}
+
 // Invoke creates and calls a unary RPC by fully qualified method name
 func (c *Client) Invoke(
 	method string,
@@ -6,6 +6,8 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"io"
+	"net/http"
 	"strings"
 	"testing"
 
@@ -19,8 +21,10 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/known/wrapperspb"
@@ -199,6 +203,140 @@ func TestClient(t *testing.T) {
 				client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");`},
 			vuString: codeBlock{code: `client.connect("GRPCBIN_ADDR");`},
 		},
+		{
+			name: "ConnectInvalidProtocol",
+			initString: codeBlock{code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");`},
+			vuString: codeBlock{
+				code: `client.connect("GRPCBIN_ADDR", { protocol: "soap" });`,
+				err:  `invalid protocol value: 'soap'`,
+			},
+		},
+		{
+			name: "InvokeWithConnectProtocol",
+			initString: codeBlock{code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");`},
+			setup: func(tb *httpmultibin.HTTPMultiBin) {
+				tb.Mux.HandleFunc("/grpc.testing.TestService/UnaryCall", func(w http.ResponseWriter, r *http.Request) {
+					req := &grpc_testing.SimpleRequest{}
+					body, err := io.ReadAll(r.Body)
+					if err != nil || r.Header.Get("Content-Type") != "application/proto" || proto.Unmarshal(body, req) != nil {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					resp := &grpc_testing.SimpleResponse{}
+					if req.GetFillUsername() {
+						resp.Username = "k6-user"
+					}
+					data, _ := proto.Marshal(resp)
+					w.Header().Set("Content-Type", "application/proto")
+					_, _ = w.Write(data)
+				})
+			},
+			vuString: codeBlock{
+				code: `
+				client.connect("HTTPBIN_URL", { protocol: "connect" });
+				var resp = client.invoke("grpc.testing.TestService/UnaryCall", { fillUsername: true })
+				if (resp.status !== grpc.StatusOK || resp.message.username !== "k6-user") {
+					throw new Error("unexpected response: " + JSON.stringify(resp))
+				}`,
+				asserts: func(t *testing.T, rb *httpmultibin.HTTPMultiBin, samples chan metrics.SampleContainer, _ error) {
+					samplesBuf := metrics.GetBufferedSamples(samples)
+					assertMetricEmitted(t, metrics.GRPCReqDurationName, samplesBuf,
+						rb.Replacer.Replace("HTTPBIN_URL/grpc.testing.TestService/UnaryCall"))
+				},
+			},
+		},
+		{
+			name: "InvokeWithGRPCWebProtocol",
+			initString: codeBlock{code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");`},
+			setup: func(tb *httpmultibin.HTTPMultiBin) {
+				tb.Mux.HandleFunc("/grpc.testing.TestService/EmptyCall", func(w http.ResponseWriter, r *http.Request) {
+					if r.Header.Get("Content-Type") != "application/grpc-web+proto" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.Header().Set("Content-Type", "application/grpc-web+proto")
+					w.Header().Set("Grpc-Status", "5")
+					w.Header().Set("Grpc-Message", "nothing here")
+				})
+			},
+			vuString: codeBlock{
+				code: `
+				client.connect("HTTPBIN_URL", { protocol: "grpc-web" });
+				var resp = client.invoke("grpc.testing.TestService/EmptyCall", {})
+				if (resp.status !== grpc.StatusNotFound || resp.error.message !== "nothing here") {
+					throw new Error("unexpected response: " + JSON.stringify(resp))
+				}`,
+				asserts: func(t *testing.T, rb *httpmultibin.HTTPMultiBin, samples chan metrics.SampleContainer, _ error) {
+					samplesBuf := metrics.GetBufferedSamples(samples)
+					assertMetricEmitted(t, metrics.GRPCReqDurationName, samplesBuf,
+						rb.Replacer.Replace("HTTPBIN_URL/grpc.testing.TestService/EmptyCall"))
+				},
+			},
+		},
+		{
+			name: "ConnectInvalidLoadBalancing",
+			initString: codeBlock{code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");`},
+			vuString: codeBlock{
+				code: `client.connect("GRPCBIN_ADDR", { loadBalancing: "random" });`,
+				err:  `invalid loadBalancing value: 'random'`,
+			},
+		},
+		{
+			name: "ConnectHealthCheckWithWebProtocol",
+			initString: codeBlock{code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");`},
+			vuString: codeBlock{
+				code: `client.connect("HTTPBIN_URL", { protocol: "grpc-web", healthCheck: true });`,
+				err:  `loadBalancing and healthCheck are only supported by the 'grpc' protocol`,
+			},
+		},
+		{
+			name: "InvokeWithLoadBalancingAndHealthCheck",
+			initString: codeBlock{code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");`},
+			setup: func(tb *httpmultibin.HTTPMultiBin) {
+				grpc_health_v1.RegisterHealthServer(tb.ServerGRPC, healthStub{})
+				tb.GRPCStub.EmptyCallFunc = func(context.Context, *grpc_testing.Empty) (*grpc_testing.Empty, error) {
+					return &grpc_testing.Empty{}, nil
+				}
+			},
+			vuString: codeBlock{
+				code: `
+				client.connect("GRPCBIN_ADDR", { loadBalancing: "round_robin", healthCheck: "grpc.testing.TestService" });
+				var resp = client.invoke("grpc.testing.TestService/EmptyCall", {})
+				if (resp.status !== grpc.StatusOK) {
+					throw new Error("unexpected response: " + JSON.stringify(resp))
+				}`,
+				asserts: func(t *testing.T, rb *httpmultibin.HTTPMultiBin, samples chan metrics.SampleContainer, _ error) {
+					samplesBuf := metrics.GetBufferedSamples(samples)
+					assertMetricEmitted(t, metrics.GRPCReqDurationName, samplesBuf,
+						rb.Replacer.Replace("GRPCBIN_ADDR/grpc.testing.TestService/EmptyCall"))
+				},
+			},
+		},
+		{
+			name: "ConnectHealthCheckNotServing",
+			initString: codeBlock{code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");`},
+			setup: func(tb *httpmultibin.HTTPMultiBin) {
+				grpc_health_v1.RegisterHealthServer(tb.ServerGRPC, healthStub{})
+			},
+			vuString: codeBlock{
+				code: `client.connect("GRPCBIN_ADDR", { healthCheck: "unknown.Service", timeout: "300ms" });`,
+				err:  `the service "unknown.Service" of backend 127.0.0.1:`,
+			},
+		},
 		{
 			name: "InvokeNotFound",
 			initString: codeBlock{code: `
@@ -1369,3 +1507,18 @@ func TestClientConnectionReflectMetadata(t *testing.T) {
 
 	assert.True(t, foundReflectionCall, "expected to find a reflection call in the logs, but didn't")
 }
+
+// healthStub reports that the TestService is serving and that any other
+// service is unknown.
+type healthStub struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+func (healthStub) Check(
+	_ context.Context, req *grpc_health_v1.HealthCheckRequest,
+) (*grpc_health_v1.HealthCheckResponse, error) {
+	if req.GetService() == "grpc.testing.TestService" {
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN}, nil
+}
@@ -110,7 +110,7 @@ func (mi *ModuleInstance) stream(c goja.ConstructorCall) *goja.Object {
 		common.Throw(rt, fmt.Errorf("invalid GRPC Stream's method: %w", err))
 	}
 
-	p, err := newCallParams(mi.vu, c.Argument(2))
+	p, err := newStreamParams(mi.vu, c.Argument(2))
 	if err != nil {
 		common.Throw(rt, fmt.Errorf("invalid GRPC Stream's parameters: %w", err))
 	}
@@ -138,6 +138,9 @@ func (mi *ModuleInstance) stream(c goja.ConstructorCall) *goja.Object {
 		eventListeners: newEventListeners(),
 		obj:            rt.NewObject(),
 		tagsAndMeta:    &p.TagsAndMeta,
+
+		correlate: p.Correlate,
+		inFlight:  newInFlightMessages(p.MaxInFlight),
 	}
 
 	defineStream(rt, s)
@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"sync"
+	"time"
+)
+
+// inFlightMessage is a written message of a stream that isn't responded to yet.
+type inFlightMessage struct {
+	id     string
+	sentAt time.Time
+}
+
+// inFlightMessages keeps track of the written messages of a stream until their
+// responses are received, so the time between them can be measured and the
+// number of the messages in flight can be limited.
+type inFlightMessages struct {
+	max int64 // unlimited if 0
+
+	mu       sync.Mutex
+	messages []inFlightMessage // in the order they are sent
+	freed    chan struct{}
+}
+
+func newInFlightMessages(max int64) *inFlightMessages {
+	return &inFlightMessages{
+		max:   max,
+		freed: make(chan struct{}, 1),
+	}
+}
+
+// add adds a message right before it's sent, after waiting for the number of
+// the messages in flight to drop below the maximum. It returns false if done
+// is closed while waiting.
+func (ifm *inFlightMessages) add(id string, done <-chan struct{}) bool {
+	for {
+		ifm.mu.Lock()
+		if ifm.max == 0 || int64(len(ifm.messages)) < ifm.max {
+			ifm.messages = append(ifm.messages, inFlightMessage{id: id, sentAt: time.Now()})
+			ifm.mu.Unlock()
+			return true
+		}
+		ifm.mu.Unlock()
+
+		select {
+		case <-ifm.freed:
+		case <-done:
+			return false
+		}
+	}
+}
+
+// match removes the message a response is for and returns the time it was
+// sent. It's the oldest message with the same ID or just the oldest one, if
+// the messages aren't correlated by their IDs.
+func (ifm *inFlightMessages) match(id string, byID bool) (time.Time, bool) {
+	ifm.mu.Lock()
+	defer ifm.mu.Unlock()
+
+	for i, msg := range ifm.messages {
+		if byID && msg.id != id {
+			continue
+		}
+		ifm.messages = append(ifm.messages[:i], ifm.messages[i+1:]...)
+		select {
+		case ifm.freed <- struct{}{}:
+		default: // the sending is already notified
+		}
+		return msg.sentAt, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,61 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamCorrelationID(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		correlate   string
+		maxInFlight int64
+		id          string
+		tracked     bool
+	}{
+		"not tracked":   {},
+		"max in flight": {maxInFlight: 2, tracked: true},
+		"correlated":    {correlate: `(msg) => msg.id`, id: "1", tracked: true},
+		"no id":         {correlate: `() => null`},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rt := goja.New()
+			s := &stream{inFlight: newInFlightMessages(tc.maxInFlight)}
+			if tc.correlate != "" {
+				correlate, err := rt.RunString(tc.correlate)
+				require.NoError(t, err)
+				var ok bool
+				s.correlate, ok = goja.AssertFunction(correlate)
+				require.True(t, ok)
+			}
+
+			// the server responds only once to all of the written messages,
+			// like in client streaming, so only the tracked ones stay in flight
+			done := make(chan struct{})
+			for i := 0; i < 2; i++ {
+				msg, err := rt.RunString(`({ id: "1" })`)
+				require.NoError(t, err)
+				id, tracked, err := s.correlationID(msg)
+				require.NoError(t, err)
+				assert.Equal(t, tc.id, id)
+				assert.Equal(t, tc.tracked, tracked)
+				if tracked {
+					require.True(t, s.inFlight.add(id, done))
+				}
+			}
+			if tc.tracked {
+				assert.Len(t, s.inFlight.messages, 2)
+			} else {
+				assert.Empty(t, s.inFlight.messages)
+			}
+		})
+	}
+}
@@ -7,6 +7,9 @@ type instanceMetrics struct {
 	Streams                 *metrics.Metric
 	StreamsMessagesSent     *metrics.Metric
 	StreamsMessagesReceived *metrics.Metric
+	StreamsMessagesDuration *metrics.Metric
+	StreamsDuration         *metrics.Metric
+	StreamsTimeToFirstMsg   *metrics.Metric
 }
 
 // registerMetrics registers and returns the metrics in the provided registry
@@ -26,5 +29,19 @@ func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
 		return nil, err
 	}
 
+	if m.StreamsMessagesDuration, err = registry.NewMetric(
+		"grpc_streams_msgs_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.StreamsDuration, err = registry.NewMetric("grpc_streams_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.StreamsTimeToFirstMsg, err = registry.NewMetric(
+		"grpc_streams_time_to_first_msg", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
 	return m, nil
 }
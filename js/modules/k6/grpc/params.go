@@ -10,6 +10,7 @@ import (
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext/grpcext"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 	"google.golang.org/grpc/metadata"
@@ -39,27 +40,89 @@ func newCallParams(vu modules.VU, input goja.Value) (*callParams, error) {
 	params := input.ToObject(rt)
 
 	for _, k := range params.Keys() {
-		switch k {
-		case "metadata":
-			md, err := newMetadata(params.Get(k))
-			if err != nil {
-				return result, fmt.Errorf("invalid metadata param: %w", err)
-			}
+		if err := result.set(rt, k, params.Get(k)); err != nil {
+			return result, err
+		}
+	}
 
-			result.Metadata = md
-		case "tags":
-			if err := common.ApplyCustomUserTags(rt, &result.TagsAndMeta, params.Get(k)); err != nil {
-				return result, fmt.Errorf("metric tags: %w", err)
+	return result, nil
+}
+
+// set sets the call parameter with the key to the value.
+func (p *callParams) set(rt *goja.Runtime, k string, value goja.Value) error {
+	switch k {
+	case "metadata":
+		md, err := newMetadata(value)
+		if err != nil {
+			return fmt.Errorf("invalid metadata param: %w", err)
+		}
+
+		p.Metadata = md
+	case "tags":
+		if err := common.ApplyCustomUserTags(rt, &p.TagsAndMeta, value); err != nil {
+			return fmt.Errorf("metric tags: %w", err)
+		}
+	case "timeout":
+		var err error
+		p.Timeout, err = types.GetDurationValue(value.Export())
+		if err != nil {
+			return fmt.Errorf("invalid timeout value: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown param: %q", k)
+	}
+
+	return nil
+}
+
+// streamParams is the parameters of a stream, which are the call parameters
+// and the ones of the flow control and the timing of its messages.
+type streamParams struct {
+	callParams
+	// Correlate returns the correlation ID of a written or a received
+	// message, with which the responses are matched to the written messages.
+	Correlate goja.Callable
+	// MaxInFlight is the maximum number of written messages without a
+	// response, after which the rest wait to be sent. It's unlimited if 0.
+	MaxInFlight int64
+}
+
+// newStreamParams constructs the stream parameters from the input value.
+// if no input is given, the default values are used.
+func newStreamParams(vu modules.VU, input goja.Value) (*streamParams, error) {
+	result := &streamParams{
+		callParams: callParams{
+			Metadata:    metadata.New(nil),
+			TagsAndMeta: vu.State().Tags.GetCurrentValues(),
+		},
+	}
+
+	if common.IsNullish(input) {
+		return result, nil
+	}
+
+	rt := vu.Runtime()
+	params := input.ToObject(rt)
+
+	for _, k := range params.Keys() {
+		switch k {
+		case "correlate":
+			correlate, ok := goja.AssertFunction(params.Get(k))
+			if !ok {
+				return result, fmt.Errorf("invalid correlate value: '%#v', it needs to be a function", params.Get(k).Export())
 			}
-		case "timeout":
-			var err error
+			result.Correlate = correlate
+		case "maxInFlight":
 			v := params.Get(k).Export()
-			result.Timeout, err = types.GetDurationValue(v)
-			if err != nil {
-				return result, fmt.Errorf("invalid timeout value: %w", err)
+			maxInFlight, ok := v.(int64)
+			if !ok || maxInFlight < 0 {
+				return result, fmt.Errorf("invalid maxInFlight value: '%#v', it needs to be a positive integer", v)
 			}
+			result.MaxInFlight = maxInFlight
 		default:
-			return result, fmt.Errorf("unknown param: %q", k)
+			if err := result.set(rt, k, params.Get(k)); err != nil {
+				return result, err
+			}
 		}
 	}
 
@@ -128,6 +191,10 @@ type connectParams struct {
 	MaxReceiveSize        int64
 	MaxSendSize           int64
 	TLS                   map[string]interface{}
+	Protocol              grpcext.Protocol
+	LoadBalancing         grpcext.LoadBalancingPolicy
+	HealthCheck           bool
+	HealthCheckService    string
 }
 
 func newConnectParams(vu modules.VU, input goja.Value) (*connectParams, error) { //nolint:gocognit
@@ -138,6 +205,7 @@ func newConnectParams(vu modules.VU, input goja.Value) (*connectParams, error) {
 		MaxReceiveSize:        0,
 		MaxSendSize:           0,
 		ReflectionMetadata:    metadata.New(nil),
+		Protocol:              grpcext.ProtocolGRPC,
 	}
 
 	if common.IsNullish(input) {
@@ -198,11 +266,47 @@ func newConnectParams(vu modules.VU, input goja.Value) (*connectParams, error) {
 			if err := parseConnectTLSParam(result, v); err != nil {
 				return result, err
 			}
+		case "protocol":
+			protocol, ok := v.(string)
+			if !ok {
+				return result, fmt.Errorf("invalid protocol value: '%#v', it needs to be a string", v)
+			}
+			switch result.Protocol = grpcext.Protocol(protocol); result.Protocol {
+			case grpcext.ProtocolGRPC, grpcext.ProtocolGRPCWeb, grpcext.ProtocolConnect:
+			default:
+				return result, fmt.Errorf("invalid protocol value: '%s', it needs to be one of '%s', '%s' or '%s'",
+					protocol, grpcext.ProtocolGRPC, grpcext.ProtocolGRPCWeb, grpcext.ProtocolConnect)
+			}
+		case "loadBalancing":
+			policy, ok := v.(string)
+			if !ok {
+				return result, fmt.Errorf("invalid loadBalancing value: '%#v', it needs to be a string", v)
+			}
+			switch result.LoadBalancing = grpcext.LoadBalancingPolicy(policy); result.LoadBalancing {
+			case grpcext.LoadBalancingPickFirst, grpcext.LoadBalancingRoundRobin:
+			default:
+				return result, fmt.Errorf("invalid loadBalancing value: '%s', it needs to be '%s' or '%s'",
+					policy, grpcext.LoadBalancingPickFirst, grpcext.LoadBalancingRoundRobin)
+			}
+		case "healthCheck":
+			switch hc := v.(type) {
+			case bool:
+				result.HealthCheck = hc
+			case string:
+				result.HealthCheck, result.HealthCheckService = true, hc
+			default:
+				return result, fmt.Errorf("invalid healthCheck value: '%#v', it needs to be boolean or a service name", v)
+			}
 		default:
 			return result, fmt.Errorf("unknown connect param: %q", k)
 		}
 	}
 
+	if result.Protocol != grpcext.ProtocolGRPC && (result.LoadBalancing != "" || result.HealthCheck) {
+		return result, fmt.Errorf("loadBalancing and healthCheck are only supported by the '%s' protocol",
+			grpcext.ProtocolGRPC)
+	}
+
 	return result, nil
 }
 
@@ -62,6 +62,69 @@ func TestCallParamsInvalidInput(t *testing.T) {
 	}
 }
 
+func TestStreamParams(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		Name        string
+		JSON        string
+		MaxInFlight int64
+		Correlate   bool
+		ErrContains string
+	}{
+		{
+			Name: "Default",
+			JSON: `{}`,
+		},
+		{
+			Name:        "FlowControl",
+			JSON:        `{ maxInFlight: 10, correlate: function(msg) { return msg.id }, timeout: "1s" }`,
+			MaxInFlight: 10,
+			Correlate:   true,
+		},
+		{
+			Name:        "InvalidParam",
+			JSON:        `{ void: true }`,
+			ErrContains: `unknown param: "void"`,
+		},
+		{
+			Name:        "InvalidCorrelate",
+			JSON:        `{ correlate: "id" }`,
+			ErrContains: `invalid correlate value: '"id"', it needs to be a function`,
+		},
+		{
+			Name:        "InvalidMaxInFlight",
+			JSON:        `{ maxInFlight: -1 }`,
+			ErrContains: `invalid maxInFlight value: '-1', it needs to be a positive integer`,
+		},
+		{
+			Name:        "InvalidMaxInFlightType",
+			JSON:        `{ maxInFlight: "10" }`,
+			ErrContains: `invalid maxInFlight value: '"10"', it needs to be a positive integer`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			testRuntime, params := newParamsTestRuntime(t, tc.JSON)
+
+			p, err := newStreamParams(testRuntime.VU, params)
+			if tc.ErrContains != "" {
+				assert.ErrorContains(t, err, tc.ErrContains)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.MaxInFlight, p.MaxInFlight)
+			assert.Equal(t, tc.Correlate, p.Correlate != nil)
+		})
+	}
+}
+
 func TestCallParamsMetadata(t *testing.T) {
 	t.Parallel()
 
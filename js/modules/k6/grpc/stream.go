@@ -26,6 +26,11 @@ import (
 type message struct {
 	isClosing bool
 	msg       []byte
+
+	// the correlation ID of the message, it's only tracked in flight if
+	// it's expected to be responded to
+	id      string
+	tracked bool
 }
 
 const (
@@ -60,6 +65,10 @@ type stream struct {
 	eventListeners *eventListeners
 
 	timeoutCancel context.CancelFunc
+
+	correlate goja.Callable
+	inFlight  *inFlightMessages
+	startTime time.Time
 }
 
 // defineStream defines the goja.Object that is given to js to interact with the Stream
@@ -74,7 +83,7 @@ func defineStream(rt *goja.Runtime, s *stream) {
 		"end", rt.ToValue(s.end), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
 }
 
-func (s *stream) beginStream(p *callParams) error {
+func (s *stream) beginStream(p *streamParams) error {
 	tags := s.vu.State().Tags.GetCurrentValues()
 	req := &grpcext.StreamRequest{
 		Method:           s.method,
@@ -92,6 +101,7 @@ func (s *stream) beginStream(p *callParams) error {
 
 	s.timeoutCancel = cancel
 
+	s.startTime = time.Now()
 	stream, err := s.client.conn.NewStream(ctx, *req)
 	if err != nil {
 		return fmt.Errorf("failed to create a new stream: %w", err)
@@ -142,23 +152,31 @@ func (s *stream) loop() {
 	}
 }
 
-func (s *stream) queueMessage(msg interface{}) {
+func (s *stream) queueMessage(msg interface{}, receivedAt time.Time) {
 	metrics.PushIfNotDone(s.vu.Context(), s.vu.State().Samples, metrics.Sample{
 		TimeSeries: metrics.TimeSeries{
 			Metric: s.instanceMetrics.StreamsMessagesReceived,
 			Tags:   s.tagsAndMeta.Tags,
 		},
-		Time:     time.Now(),
+		Time:     receivedAt,
 		Metadata: s.tagsAndMeta.Metadata,
 		Value:    1,
 	})
 
 	s.tq.Queue(func() error {
 		rt := s.vu.Runtime()
+		value := rt.ToValue(msg)
+
+		if err := s.matchResponse(value, receivedAt); err != nil {
+			_ = s.closeWithError(err)
+
+			return err
+		}
+
 		listeners := s.eventListeners.all(eventData)
 
 		for _, messageListener := range listeners {
-			if _, err := messageListener(rt.ToValue(msg)); err != nil {
+			if _, err := messageListener(value); err != nil {
 				// TODO(olegbespalov) consider logging the error
 				_ = s.closeWithError(err)
 
@@ -169,12 +187,58 @@ func (s *stream) queueMessage(msg interface{}) {
 	})
 }
 
+// correlationID returns the correlation ID of a written or a received message
+// and whether it's tracked in flight. Without a correlate function, the
+// messages are only tracked, without IDs, if maxInFlight is set, since the
+// server may not respond to every written message, e.g. in client streaming.
+func (s *stream) correlationID(msg goja.Value) (string, bool, error) {
+	if s.correlate == nil {
+		return "", s.inFlight.max > 0, nil
+	}
+
+	id, err := s.correlate(goja.Undefined(), msg)
+	if err != nil {
+		return "", false, err
+	}
+	if common.IsNullish(id) {
+		return "", false, nil
+	}
+	return id.String(), true, nil
+}
+
+// matchResponse matches a received message to the written message it's the
+// response for, if any, and emits the time between them.
+func (s *stream) matchResponse(msg goja.Value, receivedAt time.Time) error {
+	id, ok, err := s.correlationID(msg)
+	if err != nil || !ok {
+		return err
+	}
+
+	sentAt, ok := s.inFlight.match(id, s.correlate != nil)
+	if !ok {
+		return nil
+	}
+
+	metrics.PushIfNotDone(s.vu.Context(), s.vu.State().Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: s.instanceMetrics.StreamsMessagesDuration,
+			Tags:   s.tagsAndMeta.Tags,
+		},
+		Time:     receivedAt,
+		Metadata: s.tagsAndMeta.Metadata,
+		Value:    metrics.D(receivedAt.Sub(sentAt)),
+	})
+	return nil
+}
+
 // readData reads data from the stream and forward them to the readDataChan
 func (s *stream) readData(wg *sync.WaitGroup) {
 	defer wg.Done()
 
+	firstMessage := true
 	for {
 		msg, err := s.stream.ReceiveConverted()
+		receivedAt := time.Now()
 
 		if err != nil && !isRegularClosing(err) {
 			s.logger.WithError(err).Debug("error while reading from the stream")
@@ -197,7 +261,20 @@ func (s *stream) readData(wg *sync.WaitGroup) {
 		}
 
 		if msg != nil || !reflect.ValueOf(msg).IsNil() {
-			s.queueMessage(msg)
+			if firstMessage {
+				firstMessage = false
+				metrics.PushIfNotDone(s.vu.Context(), s.vu.State().Samples, metrics.Sample{
+					TimeSeries: metrics.TimeSeries{
+						Metric: s.instanceMetrics.StreamsTimeToFirstMsg,
+						Tags:   s.tagsAndMeta.Tags,
+					},
+					Time:     receivedAt,
+					Metadata: s.tagsAndMeta.Metadata,
+					Value:    metrics.D(receivedAt.Sub(s.startTime)),
+				})
+			}
+
+			s.queueMessage(msg, receivedAt)
 		}
 	}
 }
@@ -235,6 +312,11 @@ func (s *stream) writeData(wg *sync.WaitGroup) {
 					return
 				}
 
+				// the message waits to be sent while too many are in flight
+				if msg.tracked && !s.inFlight.add(msg.id, s.done) {
+					return
+				}
+
 				err := s.stream.Send(msg.msg)
 				if err != nil {
 					s.processSendError(err)
@@ -318,7 +400,12 @@ func (s *stream) write(input goja.Value) {
 		s.logger.WithError(err).Warnf("can't marshal message")
 	}
 
-	s.writeQueueCh <- message{msg: b}
+	id, tracked, err := s.correlationID(input)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	s.writeQueueCh <- message{msg: b, id: id, tracked: tracked}
 }
 
 // end closes client the stream
@@ -356,6 +443,16 @@ func (s *stream) close(err error) {
 	s.logger.Debugf("stream %s is closing", s.method)
 	close(s.done)
 
+	metrics.PushIfNotDone(s.vu.Context(), s.vu.State().Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: s.instanceMetrics.StreamsDuration,
+			Tags:   s.tagsAndMeta.Tags,
+		},
+		Time:     time.Now(),
+		Metadata: s.tagsAndMeta.Metadata,
+		Value:    metrics.D(time.Since(s.startTime)),
+	})
+
 	s.tq.Queue(func() error {
 		return s.callEventListeners(eventEnd)
 	})
@@ -10,10 +10,12 @@ import (
 
 	"go.k6.io/k6/lib/testutils/grpcservice"
 	"go.k6.io/k6/lib/testutils/httpmultibin/grpc_wrappers_testing"
+	"go.k6.io/k6/metrics"
 
 	"github.com/dop251/goja"
 	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
@@ -270,6 +272,247 @@ func TestStream_ReceiveAllServerResponsesAfterEnd(t *testing.T) {
 	)
 }
 
+func TestStream_MessageTiming(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+
+	stub := &routeGuideStub{}
+	stub.routeChat = func(stream grpcservice.RouteGuide_RouteChatServer) error {
+		var notes []*grpcservice.RouteNote
+		for {
+			note, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			notes = append(notes, note)
+		}
+
+		// an unrelated note, which isn't a response to any of the written ones
+		if err := stream.Send(&grpcservice.RouteNote{Message: "other"}); err != nil {
+			return err
+		}
+
+		// the responses are in the reverse order
+		for i := len(notes) - 1; i >= 0; i-- {
+			time.Sleep(50 * time.Millisecond)
+			if err := stream.Send(notes[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	grpcservice.RegisterRouteGuideServer(ts.httpBin.ServerGRPC, stub)
+
+	initString := codeBlock{
+		code: `
+		var client = new grpc.Client();
+		client.load([], "../../../../lib/testutils/grpcservice/route_guide.proto");`,
+	}
+	vuString := codeBlock{
+		code: `
+		client.connect("GRPCBIN_ADDR");
+		let stream = new grpc.Stream(client, "main.RouteGuide/RouteChat", {
+			correlate: function (note) { return note.message === "other" ? null : note.message },
+		})
+		stream.on('data', function (note) {
+			call('Note:' + note.message);
+		});
+
+		stream.write({ message: "1" });
+		stream.write({ message: "2" });
+		stream.write({ message: "3" });
+		stream.end();
+		`,
+	}
+
+	val, err := ts.Run(initString.code)
+	assertResponse(t, initString, err, val, ts)
+
+	ts.ToVUContext()
+
+	val, err = ts.RunOnEventLoop(vuString.code)
+
+	assertResponse(t, vuString, err, val, ts)
+
+	assert.Equal(t, []string{"Note:other", "Note:3", "Note:2", "Note:1"}, ts.callRecorder.Recorded())
+
+	durations := map[string][]float64{}
+	for _, sc := range metrics.GetBufferedSamples(ts.samples) {
+		for _, sample := range sc.GetSamples() {
+			durations[sample.Metric.Name] = append(durations[sample.Metric.Name], sample.Value)
+		}
+	}
+	assert.Len(t, durations["grpc_streams_msgs_duration"], 3)
+	assert.Len(t, durations["grpc_streams_time_to_first_msg"], 1)
+	require.Len(t, durations["grpc_streams_duration"], 1)
+	assert.GreaterOrEqual(t, durations["grpc_streams_duration"][0], float64(150))
+	for _, d := range durations["grpc_streams_msgs_duration"] {
+		assert.LessOrEqual(t, d, durations["grpc_streams_duration"][0])
+	}
+}
+
+func TestStream_MaxInFlight(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+
+	var received []time.Time
+	stub := &routeGuideStub{}
+	stub.routeChat = func(stream grpcservice.RouteGuide_RouteChatServer) error {
+		for {
+			note, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			received = append(received, time.Now())
+
+			time.Sleep(100 * time.Millisecond)
+			if err := stream.Send(note); err != nil {
+				return err
+			}
+		}
+	}
+
+	grpcservice.RegisterRouteGuideServer(ts.httpBin.ServerGRPC, stub)
+
+	initString := codeBlock{
+		code: `
+		var client = new grpc.Client();
+		client.load([], "../../../../lib/testutils/grpcservice/route_guide.proto");`,
+	}
+	vuString := codeBlock{
+		code: `
+		client.connect("GRPCBIN_ADDR");
+		let stream = new grpc.Stream(client, "main.RouteGuide/RouteChat", { maxInFlight: 1 })
+		stream.on('data', function (note) {
+			call('Note:' + note.message);
+			if (note.message === "3") {
+				stream.end();
+			}
+		});
+
+		stream.write({ message: "1" });
+		stream.write({ message: "2" });
+		stream.write({ message: "3" });
+		`,
+	}
+
+	val, err := ts.Run(initString.code)
+	assertResponse(t, initString, err, val, ts)
+
+	ts.ToVUContext()
+
+	val, err = ts.RunOnEventLoop(vuString.code)
+
+	assertResponse(t, vuString, err, val, ts)
+
+	assert.Equal(t, []string{"Note:1", "Note:2", "Note:3"}, ts.callRecorder.Recorded())
+
+	// every message is only sent after the response to the previous one
+	require.Len(t, received, 3)
+	for i := 1; i < len(received); i++ {
+		assert.GreaterOrEqual(t, received[i].Sub(received[i-1]), 100*time.Millisecond)
+	}
+}
+
+func TestStream_ClientStreamingNotTracked(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+
+	stub := &routeGuideStub{}
+	stub.recordRoute = func(stream grpcservice.RouteGuide_RecordRouteServer) error {
+		var pointCount int32
+		for {
+			_, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return stream.SendAndClose(&grpcservice.RouteSummary{PointCount: pointCount})
+			}
+			if err != nil {
+				return err
+			}
+			pointCount++
+		}
+	}
+
+	grpcservice.RegisterRouteGuideServer(ts.httpBin.ServerGRPC, stub)
+
+	initString := codeBlock{
+		code: `
+		var client = new grpc.Client();
+		client.load([], "../../../../lib/testutils/grpcservice/route_guide.proto");`,
+	}
+	vuString := codeBlock{
+		code: `
+		client.connect("GRPCBIN_ADDR");
+		let stream = new grpc.Stream(client, "main.RouteGuide/RecordRoute")
+		stream.on('data', function (summary) {
+			call('Summary:' + summary.pointCount);
+		});
+
+		stream.write({ latitude: 1, longitude: 1 });
+		stream.write({ latitude: 2, longitude: 2 });
+		stream.write({ latitude: 3, longitude: 3 });
+		stream.end();
+		`,
+	}
+
+	val, err := ts.Run(initString.code)
+	assertResponse(t, initString, err, val, ts)
+
+	ts.ToVUContext()
+
+	val, err = ts.RunOnEventLoop(vuString.code)
+
+	assertResponse(t, vuString, err, val, ts)
+
+	assert.Equal(t, []string{"Summary:3"}, ts.callRecorder.Recorded())
+
+	counts := map[string]int{}
+	for _, sc := range metrics.GetBufferedSamples(ts.samples) {
+		for _, sample := range sc.GetSamples() {
+			counts[sample.Metric.Name]++
+		}
+	}
+	assert.Equal(t, 3, counts["grpc_streams_msgs_sent"])
+	assert.Equal(t, 1, counts["grpc_streams_msgs_received"])
+	// without correlate or maxInFlight, the single response isn't matched to any of the written messages
+	assert.Zero(t, counts["grpc_streams_msgs_duration"])
+}
+
+// routeGuideStub is a stub for RouteGuideServer
+// it has ability to override methods
+type routeGuideStub struct {
+	grpcservice.UnimplementedRouteGuideServer
+
+	recordRoute func(stream grpcservice.RouteGuide_RecordRouteServer) error
+	routeChat   func(stream grpcservice.RouteGuide_RouteChatServer) error
+}
+
+func (s *routeGuideStub) RecordRoute(stream grpcservice.RouteGuide_RecordRouteServer) error {
+	if s.recordRoute != nil {
+		return s.recordRoute(stream)
+	}
+
+	return status.Errorf(codes.Unimplemented, "method RecordRoute not implemented")
+}
+
+func (s *routeGuideStub) RouteChat(stream grpcservice.RouteGuide_RouteChatServer) error {
+	if s.routeChat != nil {
+		return s.routeChat(stream)
+	}
+
+	return status.Errorf(codes.Unimplemented, "method RouteChat not implemented")
+}
+
 // featureExplorerStub is a stub for FeatureExplorerServer
 // it has ability to override methods
 type featureExplorerStub struct {
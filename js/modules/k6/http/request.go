@@ -16,6 +16,7 @@ import (
 	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules/k6/experimental/streams"
 	"go.k6.io/k6/lib/netext/httpext"
 	"go.k6.io/k6/lib/types"
 )
@@ -122,17 +123,25 @@ func (c *Client) asyncRequest(method string, url goja.Value, args ...goja.Value)
 	return p, nil
 }
 
-// processResponse stores the body as an ArrayBuffer if indicated by
-// respType. This is done here instead of in httpext.readResponseBody to avoid
-// a reverse dependency on js/common or goja.
+// processResponse stores the body as an ArrayBuffer, or as a ReadableStream, if
+// indicated by respType. This is done here instead of in httpext.readResponseBody
+// to avoid a reverse dependency on js/common or goja.
 func (c *Client) processResponse(resp *httpext.Response, respType httpext.ResponseType) {
-	if respType == httpext.ResponseTypeBinary && resp.Body != nil {
-		b, ok := resp.Body.([]byte)
-		if !ok {
-			panic("got an unexpected type for the response body, only []byte is accepted")
-		}
-		resp.Body = c.moduleInstance.vu.Runtime().NewArrayBuffer(b)
+	if (respType != httpext.ResponseTypeBinary && respType != httpext.ResponseTypeStream) || resp.Body == nil {
+		return
+	}
+
+	b, ok := resp.Body.([]byte)
+	if !ok {
+		panic("got an unexpected type for the response body, only []byte is accepted")
 	}
+
+	if respType == httpext.ResponseTypeStream {
+		resp.Body = streams.NewReadableStreamFromReader(c.moduleInstance.vu, bytes.NewReader(b))
+		return
+	}
+
+	resp.Body = c.moduleInstance.vu.Runtime().NewArrayBuffer(b)
 }
 
 func (c *Client) responseFromHTTPext(resp *httpext.Response) *Response {
@@ -169,6 +178,7 @@ func (c *Client) parseRequest(
 		Timeout:          60 * time.Second,
 		Throw:            state.Options.Throw.Bool,
 		Redirects:        state.Options.MaxRedirects,
+		HTTP3:            state.Options.HTTP3.Bool,
 		Cookies:          make(map[string]*httpext.HTTPRequestCookie),
 		ResponseCallback: c.responseCallback,
 		TagsAndMeta:      c.moduleInstance.vu.State().Tags.GetCurrentValues(),
@@ -361,6 +371,8 @@ func (c *Client) parseRequest(
 				}
 			case "redirects":
 				result.Redirects = null.IntFrom(params.Get(k).ToInteger())
+			case "http3":
+				result.HTTP3 = params.Get(k).ToBoolean()
 			case "tags":
 				if err := common.ApplyCustomUserTags(rt, &result.TagsAndMeta, params.Get(k)); err != nil {
 					return nil, fmt.Errorf("invalid HTTP request metric tags: %w", err)
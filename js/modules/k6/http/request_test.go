@@ -618,6 +618,19 @@ func TestRequest(t *testing.T) {
 			})
 		}
 
+		t.Run("http3", func(t *testing.T) {
+			// the test state has no HTTP3Transport, so the https requests that
+			// opt in fail, while the plain http ones still use the Transport
+			_, err := rt.RunString(sr(`
+			var res = http.request("GET", "HTTPSBIN_URL/get", null, { http3: true, throw: false });
+			if (res.error != "HTTP/3 isn't available in this context") { throw new Error("wrong error: " + res.error); }
+			res = http.request("GET", "HTTPBIN_URL/get", null, { http3: true });
+			if (res.status != 200) { throw new Error("wrong status: " + res.status); }
+			`))
+			assert.NoError(t, err)
+			metrics.GetBufferedSamples(samples)
+		})
+
 		t.Run("cookies", func(t *testing.T) {
 			t.Run("access", func(t *testing.T) {
 				cookieJar, err := cookiejar.New(nil)
@@ -1675,6 +1688,55 @@ func TestResponseTypes(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestResponseTypeStream(t *testing.T) {
+	t.Parallel()
+	ts := newTestCase(t)
+	tb := ts.tb
+
+	binaryLen := 200000
+	binary := make([]byte, binaryLen)
+	for i := 0; i < binaryLen; i++ {
+		binary[i] = byte(i)
+	}
+	tb.Mux.HandleFunc("/get-bin", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write(binary)
+		assert.NoError(t, err)
+	}))
+
+	_, err := ts.runtime.RunOnEventLoop(tb.Replacer.Replace(`
+		var res = http.get("HTTPBIN_URL/get-bin", { responseType: "stream" });
+		if (res.status !== 200) {
+			throw new Error("unexpected status " + res.status);
+		}
+
+		var reader = res.body.getReader();
+		var received = 0;
+		function pump() {
+			return reader.read().then(function(result) {
+				if (result.done) {
+					return;
+				}
+
+				for (var i = 0; i < result.value.length; i++) {
+					if (result.value[i] !== (received + i) % 256) {
+						throw new Error("unexpected value at position " + (received + i));
+					}
+				}
+				received += result.value.length;
+
+				return pump();
+			});
+		}
+
+		pump().then(function() {
+			if (received !== ` + strconv.Itoa(binaryLen) + `) {
+				throw new Error("expected to receive ` + strconv.Itoa(binaryLen) + ` bytes, got " + received);
+			}
+		});
+	`))
+	assert.NoError(t, err)
+}
+
 func checkErrorCode(t testing.TB, sample metrics.Sample, code int, msg string) {
 	errorMsg, ok := sample.Tags.Get("error")
 	if msg == "" {
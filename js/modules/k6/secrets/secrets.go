@@ -0,0 +1,120 @@
+// Package secrets implements the k6/secrets module, giving the tests access to
+// the secrets of the sources configured with the --secret-source flag.
+//
+// The values of the secrets are redacted from the logs and the end-of-test
+// summary. They can't be fetched in the init context, so they can never be part
+// of the test's options, nor of an archive.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/js/promises"
+	"go.k6.io/k6/secretsource"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the secrets module for a single VU.
+	ModuleInstance struct {
+		vu      modules.VU
+		manager *secretsource.Manager
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new [RootModule] instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns a new
+// instance of our module for the given VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	var manager *secretsource.Manager
+	if initEnv := vu.InitEnv(); initEnv != nil && initEnv.TestPreInitState != nil {
+		manager = initEnv.SecretsManager
+	}
+	if manager == nil {
+		// Without any source, fetching a secret fails with an explanation.
+		manager, _ = secretsource.NewManager(nil, "")
+	}
+
+	return &ModuleInstance{vu: vu, manager: manager}
+}
+
+// Exports implements the modules.Module interface and returns the exports of
+// our module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]any{
+			"get":    mi.Get,
+			"source": mi.Source,
+		},
+	}
+}
+
+// Get returns a promise resolving to the value of the secret with the given key,
+// from the default source.
+func (mi *ModuleInstance) Get(key goja.Value) *goja.Promise {
+	return mi.get("", key)
+}
+
+// Source returns the object giving access to the secrets of the source with the
+// given name, through its get method.
+func (mi *ModuleInstance) Source(name string) *goja.Object {
+	rt := mi.vu.Runtime()
+
+	if !mi.manager.HasSource(name) {
+		common.Throw(rt, fmt.Errorf("no secret source named %q, the configured ones are: %s",
+			name, strings.Join(mi.manager.SourceNames(), ", ")))
+	}
+
+	obj := rt.NewObject()
+	if err := obj.Set("get", func(key goja.Value) *goja.Promise { return mi.get(name, key) }); err != nil {
+		common.Throw(rt, err)
+	}
+
+	return obj
+}
+
+func (mi *ModuleInstance) get(sourceName string, key goja.Value) *goja.Promise {
+	promise, resolve, reject := promises.New(mi.vu)
+
+	if mi.vu.State() == nil {
+		reject(errors.New("secrets can't be fetched in the init context, " +
+			"so they never end up in the test's options or archive"))
+		return promise
+	}
+
+	if common.IsNullish(key) {
+		reject(errors.New("the key of the secret must be a string"))
+		return promise
+	}
+	keyStr := key.String()
+
+	// Fetching the secret may involve IO, e.g. for the sources of extensions.
+	go func() {
+		value, err := mi.manager.Get(sourceName, keyStr)
+		if err != nil {
+			reject(err)
+			return
+		}
+
+		resolve(value)
+	}()
+
+	return promise
+}
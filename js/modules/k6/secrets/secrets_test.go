@@ -0,0 +1,120 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/secretsource"
+)
+
+type mapSource map[string]string
+
+func (s mapSource) Description() string {
+	return "map"
+}
+
+func (s mapSource) Get(key string) (string, error) {
+	value, ok := s[key]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return value, nil
+}
+
+func TestSecrets(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"get from the default source": `
+			const token = await secrets.get("token");
+			if (token !== "default-token") {
+				throw "unexpected token " + token;
+			}
+		`,
+		"get from a named source": `
+			const token = await secrets.source("other").get("token");
+			if (token !== "other-token") {
+				throw "unexpected token " + token;
+			}
+		`,
+		"missing secret": `
+			try {
+				await secrets.get("missing");
+				throw "expected get to be rejected";
+			} catch (e) {
+				if (!String(e).includes('unable to get the secret "missing" from the "default" source')) {
+					throw "unexpected rejection " + e;
+				}
+			}
+		`,
+		"unknown source": `
+			try {
+				secrets.source("unknown");
+				throw "expected source to throw";
+			} catch (e) {
+				if (!String(e).includes('no secret source named "unknown", the configured ones are: default, other')) {
+					throw "unexpected exception " + e;
+				}
+			}
+		`,
+	}
+
+	for name, script := range tests {
+		script := script
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			manager, err := secretsource.NewManager(map[string]secretsource.Source{
+				"default": mapSource{"token": "default-token"},
+				"other":   mapSource{"token": "other-token"},
+			}, "default")
+			require.NoError(t, err)
+
+			runtime := newConfiguredRuntime(t, manager)
+			runtime.MoveToVUContext(&lib.State{})
+
+			_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(script))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSecretsInitContext(t *testing.T) {
+	t.Parallel()
+
+	manager, err := secretsource.NewManager(map[string]secretsource.Source{"default": mapSource{"token": "t"}}, "default")
+	require.NoError(t, err)
+
+	_, err = newConfiguredRuntime(t, manager).RunOnEventLoop(wrapInAsyncLambda(`await secrets.get("token")`))
+	assert.ErrorContains(t, err, "secrets can't be fetched in the init context")
+}
+
+func TestSecretsWithoutSources(t *testing.T) {
+	t.Parallel()
+
+	runtime := newConfiguredRuntime(t, nil)
+	runtime.MoveToVUContext(&lib.State{})
+
+	_, err := runtime.RunOnEventLoop(wrapInAsyncLambda(`await secrets.get("token")`))
+	assert.ErrorContains(t, err, "no secret sources are configured")
+}
+
+func newConfiguredRuntime(t testing.TB, manager *secretsource.Manager) *modulestest.Runtime {
+	runtime := modulestest.NewRuntime(t)
+	runtime.VU.InitEnvField.SecretsManager = manager
+
+	m := New().NewModuleInstance(runtime.VU)
+	require.NoError(t, runtime.VU.RuntimeField.Set("secrets", m.Exports().Named))
+
+	return runtime
+}
+
+func wrapInAsyncLambda(input string) string {
+	// This makes it possible to use `await` freely on the "top" level
+	return "(async () => {\n " + input + "\n })()"
+}
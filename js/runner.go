@@ -30,6 +30,7 @@ import (
 	"go.k6.io/k6/lib/consts"
 	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/lib/netext/httpext"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/loader"
 	"go.k6.io/k6/metrics"
@@ -207,6 +208,8 @@ func (r *Runner) newVU(
 		_ = http2.ConfigureTransport(transport) // send over h2 protocol
 	}
 
+	http3Transport := httpext.NewHTTP3Transport(dialer, tlsConfig)
+
 	cookieJar, err := cookiejar.New(nil)
 	if err != nil {
 		return nil, err
@@ -219,6 +222,7 @@ func (r *Runner) newVU(
 		BundleInstance: *bi,
 		Runner:         r,
 		Transport:      transport,
+		HTTP3Transport: http3Transport,
 		Dialer:         dialer,
 		CookieJar:      cookieJar,
 		TLSConfig:      tlsConfig,
@@ -232,6 +236,7 @@ func (r *Runner) newVU(
 		Logger:         vu.Runner.preInitState.Logger,
 		Options:        vu.Runner.Bundle.Options,
 		Transport:      vu.Transport,
+		HTTP3Transport: vu.HTTP3Transport,
 		Dialer:         vu.Dialer,
 		TLSConfig:      vu.TLSConfig,
 		CookieJar:      cookieJar,
@@ -413,7 +418,13 @@ func (r *Runner) HandleSummary(ctx context.Context, summary *lib.Summary) (map[s
 	if err != nil {
 		return nil, fmt.Errorf("unexpected error while generating the summary: %w", err)
 	}
-	return getSummaryResult(rawResult)
+
+	result, err := getSummaryResult(rawResult)
+	if err != nil || r.preInitState.SecretsManager == nil {
+		return result, err
+	}
+
+	return redactSummaryResult(result, r.preInitState.SecretsManager.Redactor())
 }
 
 func (r *Runner) checkDeadline(ctx context.Context, name string, result goja.Value, err error) error {
@@ -451,7 +462,11 @@ func (r *Runner) SetOptions(opts lib.Options) error {
 			formatter = l.Formatter
 			level = l.Level
 		}
-		c, err := newFileConsole(opts.ConsoleOutput.String, formatter, level)
+		var hooks []logrus.Hook
+		if r.preInitState.SecretsManager != nil {
+			hooks = append(hooks, r.preInitState.SecretsManager.Redactor())
+		}
+		c, err := newFileConsole(opts.ConsoleOutput.String, formatter, level, hooks...)
 		if err != nil {
 			return err
 		}
@@ -601,14 +616,15 @@ func (r *Runner) getTimeoutFor(stage string) time.Duration {
 type VU struct {
 	BundleInstance
 
-	Runner    *Runner
-	Transport *http.Transport
-	Dialer    *netext.Dialer
-	CookieJar *cookiejar.Jar
-	TLSConfig *tls.Config
-	ID        uint64 // local to the current instance
-	IDGlobal  uint64 // global across all instances
-	iteration int64
+	Runner         *Runner
+	Transport      *http.Transport
+	HTTP3Transport *httpext.HTTP3Transport
+	Dialer         *netext.Dialer
+	CookieJar      *cookiejar.Jar
+	TLSConfig      *tls.Config
+	ID             uint64 // local to the current instance
+	IDGlobal       uint64 // global across all instances
+	iteration      int64
 
 	Console    *console
 	BufferPool *lib.BufferPool
@@ -866,6 +882,7 @@ func (u *VU) runFn(
 
 	if u.Runner.Bundle.Options.NoVUConnectionReuse.Bool {
 		u.Transport.CloseIdleConnections()
+		u.HTTP3Transport.CloseIdleConnections()
 	}
 
 	u.state.Samples <- u.Dialer.GetTrail(
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/dop251/goja"
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/secretsource"
 )
 
 // Copied from https://github.com/k6io/jslib.k6.io/tree/master/lib/k6-summary
@@ -159,3 +161,16 @@ func getSummaryResult(rawResult goja.Value) (map[string]io.Reader, error) {
 
 	return results, nil
 }
+
+// redactSummaryResult redacts the values of the secrets from the summary result.
+func redactSummaryResult(result map[string]io.Reader, redactor *secretsource.Redactor) (map[string]io.Reader, error) {
+	for path, reader := range result {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("error reading summary object %s: %w", path, err)
+		}
+		result[path] = strings.NewReader(redactor.Redact(string(data)))
+	}
+
+	return result, nil
+}
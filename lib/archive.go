@@ -267,6 +267,11 @@ func (arc *Archive) Write(out io.Writer) error {
 		if !ok {
 			continue
 		}
+		if uncachedfs, ok := filesystem.(fsext.UncachedOpener); ok {
+			if err = uncachedfs.CacheUncached(); err != nil {
+				return err
+			}
+		}
 		if cachedfs, ok := filesystem.(fsext.CacheLayerGetter); ok {
 			filesystem = cachedfs.GetCachingFs()
 		}
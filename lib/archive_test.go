@@ -282,6 +282,42 @@ func TestUsingCacheFromCacheOnReadFs(t *testing.T) {
 	require.Nil(t, data)
 }
 
+func TestArchiveUncachedFiles(t *testing.T) {
+	t.Parallel()
+	base := fsext.NewMemMapFs()
+	cached := fsext.NewMemMapFs()
+	require.NoError(t, fsext.WriteFile(base, "/data.csv", []byte(`a,b`), 0o644))
+	require.NoError(t, fsext.WriteFile(cached, "/script", []byte(`test`), 0o644))
+
+	fs := fsext.NewCacheOnReadFs(base, cached, 0)
+	f, err := fs.(fsext.UncachedOpener).OpenUncached("/data.csv")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	exists, err := fsext.Exists(cached, "/data.csv")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	arc := &Archive{
+		Type:        "js",
+		FilenameURL: &url.URL{Scheme: "file", Path: "/script"},
+		K6Version:   consts.Version,
+		Data:        []byte(`test`),
+		PwdURL:      &url.URL{Scheme: "file", Path: "/"},
+		Filesystems: map[string]fsext.Fs{"file": fs},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, arc.Write(buf))
+
+	newArc, err := ReadArchive(buf)
+	require.NoError(t, err)
+
+	data, err := fsext.ReadFile(newArc.Filesystems["file"], "/data.csv")
+	require.NoError(t, err)
+	require.Equal(t, "a,b", string(data))
+}
+
 func TestArchiveWithDataNotInFS(t *testing.T) {
 	t.Parallel()
 
@@ -196,6 +196,13 @@ type ExecutionState struct {
 	pauseStateLock      sync.RWMutex
 	totalPausedDuration time.Duration // only modified behind the lock
 	resumeNotify        chan struct{}
+
+	// gracefulStopNotify is closed when the test is gracefully stopped, e.g.
+	// from the REST API. The executors then stop starting new iterations and
+	// give the ones in progress up to their gracefulStop to finish, while the
+	// scenarios that haven't started yet are skipped.
+	gracefulStopNotify chan struct{}
+	gracefulStopOnce   *sync.Once
 }
 
 // NewExecutionState initializes all of the pointers in the ExecutionState
@@ -230,6 +237,8 @@ func NewExecutionState(
 		pauseStateLock:             sync.RWMutex{},
 		totalPausedDuration:        0, // Accessed only behind the pauseStateLock
 		resumeNotify:               resumeNotify,
+		gracefulStopNotify:         make(chan struct{}),
+		gracefulStopOnce:           new(sync.Once),
 	}
 }
 
@@ -455,6 +464,38 @@ func (es *ExecutionState) ResumeNotify() <-chan struct{} {
 	return es.resumeNotify
 }
 
+// StopGracefully stops the test execution gracefully, the same way it would
+// be stopped at the end of the regular duration of every scenario. It returns
+// an error if the test was already stopped.
+func (es *ExecutionState) StopGracefully() error {
+	stopped := false
+	es.gracefulStopOnce.Do(func() {
+		close(es.gracefulStopNotify)
+		stopped = true
+	})
+	if !stopped {
+		return errors.New("test execution was already stopped")
+	}
+	return nil
+}
+
+// GracefulStopNotify returns a channel which will be closed as soon as the
+// test execution is gracefully stopped.
+func (es *ExecutionState) GracefulStopNotify() <-chan struct{} {
+	return es.gracefulStopNotify
+}
+
+// IsStoppedGracefully returns whether the test execution was gracefully
+// stopped.
+func (es *ExecutionState) IsStoppedGracefully() bool {
+	select {
+	case <-es.gracefulStopNotify:
+		return true
+	default:
+		return false
+	}
+}
+
 // GetPlannedVU tries to get a pre-initialized VU from the buffer channel. This
 // shouldn't fail and should generally be an instantaneous action, but if it
 // doesn't happen for MaxTimeToWaitForPlannedVU (for example, because the system
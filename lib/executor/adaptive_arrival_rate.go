@@ -0,0 +1,394 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+const adaptiveArrivalRateType = "adaptive-arrival-rate"
+
+// adaptiveMaxPassingRateName is the name of the gauge metric, in which the
+// adaptive arrival-rate executor reports the highest rate, in iterations per
+// second, for which its conditions have passed.
+const adaptiveMaxPassingRateName = "adaptive_max_passing_rate"
+
+func init() {
+	lib.RegisterExecutorConfigType(
+		adaptiveArrivalRateType,
+		func(name string, rawJSON []byte) (lib.ExecutorConfig, error) {
+			config := NewAdaptiveArrivalRateConfig(name)
+			err := lib.StrictJSONUnmarshal(rawJSON, &config)
+			if err != nil {
+				return config, err
+			}
+			if !config.MaxVUs.Valid {
+				config.MaxVUs = config.PreAllocatedVUs
+			}
+			return config, nil
+		},
+	)
+}
+
+// AdaptiveArrivalRateConfig stores the config for the adaptive arrival-rate
+// executor, which searches for the highest arrival rate that the system under
+// test can sustain without breaching the configured conditions.
+type AdaptiveArrivalRateConfig struct {
+	BaseConfig
+	StartRate    null.Int           `json:"startRate"`
+	TimeUnit     types.NullDuration `json:"timeUnit"`
+	RateStep     null.Int           `json:"rateStep"`
+	MaxRate      null.Int           `json:"maxRate"` // optional, there's no upper limit if it's not set
+	StepDuration types.NullDuration `json:"stepDuration"`
+	Duration     types.NullDuration `json:"duration"`
+
+	// The conditions have the same format as the thresholds, e.g.
+	// `{"http_req_duration": ["p(95)<500"]}`, but they are evaluated only over
+	// the metric samples of every step.
+	Conditions map[string][]string `json:"conditions"`
+
+	PreAllocatedVUs null.Int `json:"preAllocatedVUs"`
+	MaxVUs          null.Int `json:"maxVUs"`
+}
+
+// NewAdaptiveArrivalRateConfig returns an AdaptiveArrivalRateConfig with default values
+func NewAdaptiveArrivalRateConfig(name string) AdaptiveArrivalRateConfig {
+	return AdaptiveArrivalRateConfig{
+		BaseConfig: NewBaseConfig(name, adaptiveArrivalRateType),
+		TimeUnit:   types.NewNullDuration(1*time.Second, false),
+	}
+}
+
+// Make sure we implement the lib.ExecutorConfig interface
+var _ lib.ExecutorConfig = &AdaptiveArrivalRateConfig{}
+
+// GetDescription returns a human-readable description of the executor options
+func (aarc AdaptiveArrivalRateConfig) GetDescription(et *lib.ExecutionTuple) string {
+	perSec := func(rate int64) float64 {
+		r, _ := getArrivalRatePerSec(getScaledArrivalRate(et.Segment, rate, aarc.TimeUnit.TimeDuration())).Float64()
+		return r
+	}
+	maxRate := "no limit"
+	if aarc.MaxRate.Valid {
+		maxRate = fmt.Sprintf("%.2f", perSec(aarc.MaxRate.Int64))
+	}
+	preAllocatedVUs, maxVUs := et.ScaleInt64(aarc.PreAllocatedVUs.Int64), et.ScaleInt64(aarc.MaxVUs.Int64)
+	maxVUsRange := fmt.Sprintf("maxVUs: %d", preAllocatedVUs)
+	if maxVUs > preAllocatedVUs {
+		maxVUsRange += fmt.Sprintf("-%d", maxVUs)
+	}
+
+	return fmt.Sprintf(
+		"Up to %s iterations/s from %.2f, +%.2f every %s while %s, for %s%s",
+		maxRate, perSec(aarc.StartRate.Int64), perSec(aarc.RateStep.Int64), aarc.StepDuration.Duration,
+		strings.Join(getConditionSources(aarc.Conditions), " && "), aarc.Duration.Duration, aarc.getBaseInfo(maxVUsRange),
+	)
+}
+
+// Validate makes sure all options are configured and valid
+func (aarc AdaptiveArrivalRateConfig) Validate() []error {
+	errors := aarc.BaseConfig.Validate()
+	if !aarc.StartRate.Valid {
+		errors = append(errors, fmt.Errorf("the start rate isn't specified"))
+	} else if aarc.StartRate.Int64 <= 0 {
+		errors = append(errors, fmt.Errorf("the start rate must be more than 0"))
+	}
+
+	if !aarc.RateStep.Valid {
+		errors = append(errors, fmt.Errorf("the rate step isn't specified"))
+	} else if aarc.RateStep.Int64 <= 0 {
+		errors = append(errors, fmt.Errorf("the rate step must be more than 0"))
+	}
+
+	if aarc.MaxRate.Valid && aarc.MaxRate.Int64 < aarc.StartRate.Int64 {
+		errors = append(errors, fmt.Errorf("the max rate can't be less than the start rate"))
+	}
+
+	if aarc.TimeUnit.TimeDuration() <= 0 {
+		errors = append(errors, fmt.Errorf("the timeUnit must be more than 0"))
+	}
+
+	if !aarc.Duration.Valid {
+		errors = append(errors, fmt.Errorf("the duration is unspecified"))
+	} else if aarc.Duration.TimeDuration() < minDuration {
+		errors = append(errors, fmt.Errorf(
+			"the duration must be at least %s, but is %s", minDuration, aarc.Duration,
+		))
+	}
+
+	if !aarc.StepDuration.Valid {
+		errors = append(errors, fmt.Errorf("the step duration is unspecified"))
+	} else if aarc.StepDuration.TimeDuration() < minDuration {
+		errors = append(errors, fmt.Errorf(
+			"the step duration must be at least %s, but is %s", minDuration, aarc.StepDuration,
+		))
+	} else if aarc.StepDuration.TimeDuration() > aarc.Duration.TimeDuration() {
+		errors = append(errors, fmt.Errorf("the step duration can't be longer than the duration"))
+	}
+
+	if len(aarc.Conditions) == 0 {
+		errors = append(errors, fmt.Errorf("at least one condition has to be specified"))
+	}
+	if _, err := lib.ParseMetricConditions(aarc.Conditions); err != nil {
+		errors = append(errors, err)
+	}
+
+	if !aarc.PreAllocatedVUs.Valid {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs isn't specified"))
+	} else if aarc.PreAllocatedVUs.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs can't be negative"))
+	}
+
+	if aarc.MaxVUs.Int64 < aarc.PreAllocatedVUs.Int64 {
+		errors = append(errors, fmt.Errorf("maxVUs can't be less than preAllocatedVUs"))
+	}
+
+	return errors
+}
+
+// getRateControlConfig returns the config of the externally controlled
+// arrival-rate executor that the adaptive one uses to start the iterations.
+func (aarc AdaptiveArrivalRateConfig) getRateControlConfig() ExternallyControlledArrivalRateConfig {
+	return ExternallyControlledArrivalRateConfig{
+		BaseConfig: aarc.BaseConfig,
+		ExternallyControlledArrivalRateConfigParams: ExternallyControlledArrivalRateConfigParams{
+			Rate:            aarc.StartRate,
+			TimeUnit:        aarc.TimeUnit,
+			Duration:        aarc.Duration,
+			PreAllocatedVUs: aarc.PreAllocatedVUs,
+			MaxVUs:          aarc.MaxVUs,
+		},
+	}
+}
+
+// GetExecutionRequirements returns the number of required VUs to run the
+// executor for its whole duration, the same way the constant arrival-rate
+// executor does.
+func (aarc AdaptiveArrivalRateConfig) GetExecutionRequirements(et *lib.ExecutionTuple) []lib.ExecutionStep {
+	return aarc.getRateControlConfig().GetExecutionRequirements(et)
+}
+
+// IsDistributable returns false, since the conditions are evaluated over the
+// metrics of the local k6 instance only.
+func (AdaptiveArrivalRateConfig) IsDistributable() bool {
+	return false
+}
+
+// NewExecutor creates a new AdaptiveArrivalRate executor
+func (aarc AdaptiveArrivalRateConfig) NewExecutor(es *lib.ExecutionState, logger *logrus.Entry) (lib.Executor, error) {
+	rateControl, err := aarc.getRateControlConfig().NewExecutor(es, logger)
+	if err != nil {
+		return nil, err
+	}
+	maxPassingRateMetric, err := es.Test.Registry.NewMetric(adaptiveMaxPassingRateName, metrics.Gauge)
+	if err != nil {
+		return nil, err
+	}
+
+	mex := rateControl.(*ExternallyControlledArrivalRate) //nolint:forcetypeassert
+	return &AdaptiveArrivalRate{
+		BaseExecutor:         mex.BaseExecutor,
+		config:               aarc,
+		rateControl:          mex,
+		maxPassingRateMetric: maxPassingRateMetric,
+	}, nil
+}
+
+// HasWork reports whether there is any work to be done for the given execution segment.
+func (aarc AdaptiveArrivalRateConfig) HasWork(et *lib.ExecutionTuple) bool {
+	return et.ScaleInt64(aarc.MaxVUs.Int64) > 0
+}
+
+// adaptiveRateSearch decides the rate of every step, based on whether the
+// conditions passed in the previous one.
+//
+// While the conditions pass, the rate is increased by the rate step, up to the
+// max rate. The first time they are breached, the rate is backed off by a step
+// and the search settles, i.e. the rate isn't increased anymore. If the
+// conditions are breached while settled, the rate is backed off again.
+type adaptiveRateSearch struct {
+	rate, step, maxRate int64 // maxRate is 0 if there's no limit
+
+	settled        bool
+	hasPassed      bool
+	maxPassingRate int64
+}
+
+// next records the result of the step with the current rate and returns the
+// rate for the next step.
+func (ars *adaptiveRateSearch) next(passed bool) int64 {
+	if passed {
+		if !ars.hasPassed || ars.rate > ars.maxPassingRate {
+			ars.maxPassingRate = ars.rate
+			ars.hasPassed = true
+		}
+		if !ars.settled {
+			if ars.maxRate > 0 && ars.rate+ars.step >= ars.maxRate {
+				ars.rate = ars.maxRate
+				ars.settled = ars.maxPassingRate == ars.maxRate
+			} else {
+				ars.rate += ars.step
+			}
+		}
+		return ars.rate
+	}
+
+	ars.settled = true
+	ars.rate -= ars.step
+	if ars.rate < 0 {
+		ars.rate = 0
+	}
+	// The previously passing rates aren't sustainable if they are higher.
+	if ars.hasPassed && ars.maxPassingRate > ars.rate {
+		ars.maxPassingRate = ars.rate
+	}
+	return ars.rate
+}
+
+// AdaptiveArrivalRate starts iterations at an arrival rate that is increased
+// step by step, while the configured conditions hold for the metric samples of
+// every step. It uses the externally controlled arrival-rate executor to start
+// the iterations and changes its rate after every step.
+type AdaptiveArrivalRate struct {
+	*BaseExecutor
+	config               AdaptiveArrivalRateConfig
+	rateControl          *ExternallyControlledArrivalRate
+	conditions           []*lib.MetricCondition
+	maxPassingRateMetric *metrics.Metric
+}
+
+// Make sure we implement the lib.Executor interface.
+var _ lib.Executor = &AdaptiveArrivalRate{}
+
+// GetConfig returns the configuration with which this executor was launched.
+func (aar *AdaptiveArrivalRate) GetConfig() lib.ExecutorConfig {
+	return aar.config
+}
+
+// Init starts observing the metrics that the conditions are based on.
+func (aar *AdaptiveArrivalRate) Init(ctx context.Context) error {
+	if err := aar.rateControl.Init(ctx); err != nil {
+		return err
+	}
+
+	liveMetrics := aar.executionState.Test.LiveMetrics
+	if liveMetrics == nil {
+		return errors.New("the adaptive-arrival-rate executor can't be used if both " +
+			"the end-of-test summary and the thresholds are disabled, since the metrics aren't processed")
+	}
+
+	var err error
+	aar.conditions, err = lib.InitMetricConditions(aar.config.Conditions, aar.executionState.Test.Registry, liveMetrics)
+	return err
+}
+
+// evaluateConditions checks the conditions over the metric samples since the
+// previous call and returns the ones that failed.
+func (aar *AdaptiveArrivalRate) evaluateConditions(stepDuration time.Duration) (failed []string) {
+	for _, cond := range aar.conditions {
+		passed, err := cond.Evaluate(stepDuration)
+		if err != nil {
+			aar.logger.WithError(err).Errorf("Error while evaluating the conditions on metric '%s'", cond.MetricName)
+			passed = false
+		}
+		if !passed {
+			failed = append(failed, cond.MetricName)
+		}
+	}
+	return failed
+}
+
+// getRatePerSec returns the given rate in iterations per second.
+func (aar *AdaptiveArrivalRate) getRatePerSec(rate int64) float64 {
+	return float64(rate) * float64(time.Second) / float64(aar.config.TimeUnit.TimeDuration())
+}
+
+// adjustRate evaluates the conditions after every step and updates the rate
+// of the iterations accordingly, until the context is done or the iterations
+// have stopped.
+func (aar *AdaptiveArrivalRate) adjustRate(ctx context.Context, out chan<- metrics.SampleContainer) {
+	search := &adaptiveRateSearch{
+		rate:    aar.config.StartRate.Int64,
+		step:    aar.config.RateStep.Int64,
+		maxRate: aar.config.MaxRate.Int64,
+	}
+	stepDuration := aar.config.StepDuration.TimeDuration()
+	metricTags := aar.getMetricTags(nil)
+
+	defer func() {
+		if search.hasPassed {
+			aar.logger.Infof("The highest passing rate was %.2f iterations/s", aar.getRatePerSec(search.maxPassingRate))
+		} else {
+			aar.logger.Warn("The conditions didn't pass for any of the evaluated rates")
+		}
+	}()
+
+	// Ignore the samples from before the start of the executor.
+	for _, cond := range aar.conditions {
+		cond.Reset()
+	}
+
+	ticker := time.NewTicker(stepDuration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		currentRate := search.rate
+		failed := aar.evaluateConditions(stepDuration)
+		newRate := search.next(len(failed) == 0)
+		aar.logger.WithFields(logrus.Fields{
+			"rate": currentRate, "failed": failed, "newRate": newRate, "maxPassingRate": search.maxPassingRate,
+		}).Debug("Evaluated the conditions of the adaptive arrival-rate step")
+
+		if search.hasPassed {
+			metrics.PushIfNotDone(ctx, out, metrics.Sample{
+				TimeSeries: metrics.TimeSeries{Metric: aar.maxPassingRateMetric, Tags: metricTags},
+				Time:       time.Now(),
+				Value:      aar.getRatePerSec(search.maxPassingRate),
+			})
+		}
+		if newRate == currentRate {
+			continue
+		}
+
+		newConfig := aar.rateControl.GetCurrentConfig().ExternallyControlledArrivalRateConfigParams
+		newConfig.Rate = null.IntFrom(newRate)
+		if err := aar.rateControl.UpdateConfig(ctx, newConfig); err != nil {
+			aar.logger.WithError(err).Debug("Stopped adjusting the rate")
+			return
+		}
+	}
+}
+
+// Run starts the iterations and adjusts their rate after every step, until
+// the end of the configured duration.
+func (aar *AdaptiveArrivalRate) Run(parentCtx context.Context, out chan<- metrics.SampleContainer) error {
+	ctx, cancel := context.WithCancel(parentCtx)
+	defer cancel()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		aar.adjustRate(ctx, out)
+	}()
+
+	err := aar.rateControl.Run(parentCtx, out)
+	cancel()
+	wg.Wait()
+	return err
+}
@@ -0,0 +1,171 @@
+package executor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+type testLiveMetrics map[string]func() metrics.Sink
+
+func (tlm testLiveMetrics) ObserveMetric(name string) (func() metrics.Sink, error) {
+	return tlm[name], nil
+}
+
+func getTestAdaptiveArrivalRateConfig() AdaptiveArrivalRateConfig {
+	return AdaptiveArrivalRateConfig{
+		BaseConfig:      BaseConfig{Name: "adaptive", Type: adaptiveArrivalRateType, GracefulStop: types.NullDurationFrom(0)},
+		StartRate:       null.IntFrom(10),
+		TimeUnit:        types.NullDurationFrom(time.Second),
+		RateStep:        null.IntFrom(10),
+		StepDuration:    types.NullDurationFrom(time.Second),
+		Duration:        types.NullDurationFrom(4500 * time.Millisecond),
+		Conditions:      map[string][]string{"test_iters": {"count<25"}},
+		PreAllocatedVUs: null.IntFrom(5),
+		MaxVUs:          null.IntFrom(5),
+	}
+}
+
+func TestAdaptiveArrivalRateRun(t *testing.T) {
+	t.Parallel()
+
+	var count, stepCount int64
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error {
+		atomic.AddInt64(&count, 1)
+		atomic.AddInt64(&stepCount, 1)
+		return nil
+	})
+
+	config := getTestAdaptiveArrivalRateConfig()
+	testRunState := getTestRunState(t, lib.Options{}, runner)
+	_, err := testRunState.Registry.NewMetric("test_iters", metrics.Counter)
+	require.NoError(t, err)
+	testRunState.LiveMetrics = testLiveMetrics{"test_iters": func() metrics.Sink {
+		return &metrics.CounterSink{Value: float64(atomic.SwapInt64(&stepCount, 0))}
+	}}
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	execReqs := config.GetExecutionRequirements(et)
+	es := lib.NewExecutionState(testRunState, et, lib.GetMaxPlannedVUs(execReqs), lib.GetMaxPossibleVUs(execReqs))
+	ctx, cancel, executor, _ := setupExecutor(t, config, es)
+	defer cancel()
+
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	require.NoError(t, executor.Run(ctx, engineOut))
+
+	// 10/s and 20/s pass, 30/s fails and the rate is backed off to 20/s
+	assert.InDelta(t, 10+20+30+20+10, atomic.LoadInt64(&count), 3)
+
+	var maxPassingRates []float64
+	for _, sc := range metrics.GetBufferedSamples(engineOut) {
+		for _, s := range sc.GetSamples() {
+			if s.Metric.Name == adaptiveMaxPassingRateName {
+				maxPassingRates = append(maxPassingRates, s.Value)
+			}
+		}
+	}
+	assert.Equal(t, []float64{10, 20, 20, 20}, maxPassingRates)
+}
+
+func TestAdaptiveArrivalRateNoLiveMetrics(t *testing.T) {
+	t.Parallel()
+
+	config := getTestAdaptiveArrivalRateConfig()
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error { return nil })
+	testRunState := getTestRunState(t, lib.Options{}, runner)
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(testRunState, et, 5, 5)
+
+	executor, err := config.NewExecutor(es, testRunState.Logger.WithField("executor", "adaptive"))
+	require.NoError(t, err)
+	assert.ErrorContains(t, executor.Init(context.Background()), "the metrics aren't processed")
+
+	testRunState.LiveMetrics = testLiveMetrics{}
+	assert.ErrorContains(t, executor.Init(context.Background()),
+		"invalid condition on metric 'test_iters'")
+}
+
+func TestAdaptiveRateSearch(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		maxRate        int64
+		results        []bool
+		expRates       []int64
+		expMaxPassing  int64
+		expHasPassed   bool
+		expSettledLast bool
+	}{
+		"ramp up": {
+			results:  []bool{true, true, true},
+			expRates: []int64{20, 30, 40}, expMaxPassing: 30, expHasPassed: true,
+		},
+		"back off and settle": {
+			results:  []bool{true, true, false, true, true},
+			expRates: []int64{20, 30, 20, 20, 20}, expMaxPassing: 20, expHasPassed: true, expSettledLast: true,
+		},
+		"back off twice": {
+			results:  []bool{true, false, false, true},
+			expRates: []int64{20, 10, 0, 0}, expMaxPassing: 0, expHasPassed: true, expSettledLast: true,
+		},
+		"never passes": {
+			results:  []bool{false, false},
+			expRates: []int64{0, 0}, expMaxPassing: 0, expSettledLast: true,
+		},
+		"max rate": {
+			maxRate:  25,
+			results:  []bool{true, true, true, true},
+			expRates: []int64{20, 25, 25, 25}, expMaxPassing: 25, expHasPassed: true, expSettledLast: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			search := &adaptiveRateSearch{rate: 10, step: 10, maxRate: tc.maxRate}
+			rates := make([]int64, 0, len(tc.results))
+			for _, passed := range tc.results {
+				rates = append(rates, search.next(passed))
+			}
+			assert.Equal(t, tc.expRates, rates)
+			assert.Equal(t, tc.expMaxPassing, search.maxPassingRate)
+			assert.Equal(t, tc.expHasPassed, search.hasPassed)
+			assert.Equal(t, tc.expSettledLast, search.settled)
+		})
+	}
+}
+
+func TestAdaptiveArrivalRateConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	config := getTestAdaptiveArrivalRateConfig()
+	assert.Empty(t, config.Validate())
+
+	config.StartRate = null.IntFrom(0)
+	config.MaxRate = null.IntFrom(-1)
+	config.StepDuration = types.NullDurationFrom(time.Minute)
+	config.Conditions = map[string][]string{"http_req_duration": {"p(95)<500 over 10s"}}
+	errs := config.Validate()
+	require.Len(t, errs, 4)
+	assert.Contains(t, errs[0].Error(), "the start rate must be more than 0")
+	assert.Contains(t, errs[1].Error(), "the max rate can't be less than the start rate")
+	assert.Contains(t, errs[2].Error(), "the step duration can't be longer than the duration")
+	assert.Contains(t, errs[3].Error(), "can't have a time window")
+
+	config.Conditions = nil
+	errs = config.Validate()
+	assert.Contains(t, errs[len(errs)-1].Error(), "at least one condition has to be specified")
+}
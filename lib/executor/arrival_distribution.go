@@ -0,0 +1,103 @@
+package executor
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	// arrivalDistributionConstant starts the iterations at perfectly even
+	// intervals, it's the default.
+	arrivalDistributionConstant = "constant"
+	// arrivalDistributionPoisson draws the time between the iterations from an
+	// exponential distribution, i.e. the iterations start as a Poisson process.
+	arrivalDistributionPoisson = "poisson"
+	// arrivalDistributionUniform draws the time between the iterations from a
+	// uniform distribution between 0 and twice the average interval.
+	arrivalDistributionUniform = "uniform"
+)
+
+// ArrivalDistributionConfig configures how the starts of the iterations of the
+// arrival-rate executors are distributed in time. It's embedded in their
+// configs, so the options are specified directly in the scenario.
+type ArrivalDistributionConfig struct {
+	ArrivalDistribution null.String `json:"arrivalDistribution"`
+	// The same seed has to be used by all instances of a distributed test, so
+	// if it's not specified, it's derived from the scenario name.
+	ArrivalSeed null.Int `json:"arrivalSeed"`
+}
+
+// Validate makes sure the arrival distribution is a supported one.
+func (adc ArrivalDistributionConfig) Validate() []error {
+	switch adc.ArrivalDistribution.String {
+	case "", arrivalDistributionConstant, arrivalDistributionPoisson, arrivalDistributionUniform:
+		return nil
+	default:
+		return []error{fmt.Errorf(
+			"unsupported arrivalDistribution '%s', it should be one of '%s', '%s' or '%s'",
+			adc.ArrivalDistribution.String,
+			arrivalDistributionConstant, arrivalDistributionPoisson, arrivalDistributionUniform,
+		)}
+	}
+}
+
+// getDescription returns the arrival distribution for the executor
+// descriptions, or an empty string for the default constant one.
+func (adc ArrivalDistributionConfig) getDescription() string {
+	if adc.ArrivalDistribution.String == "" || adc.ArrivalDistribution.String == arrivalDistributionConstant {
+		return ""
+	}
+	return fmt.Sprintf("arrivals: %s", adc.ArrivalDistribution.String)
+}
+
+// newArrivalSequence returns the sequence that determines when the iterations
+// of the given scenario start.
+func (adc ArrivalDistributionConfig) newArrivalSequence(scenarioName string) *arrivalSequence {
+	seed := adc.ArrivalSeed.Int64
+	if !adc.ArrivalSeed.Valid {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(scenarioName))
+		seed = int64(h.Sum64())
+	}
+	rng := rand.New(rand.NewSource(seed)) //nolint:gosec
+
+	seq := &arrivalSequence{}
+	switch adc.ArrivalDistribution.String {
+	case arrivalDistributionPoisson:
+		seq.nextInterval = rng.ExpFloat64
+	case arrivalDistributionUniform:
+		seq.nextInterval = func() float64 { return 2 * rng.Float64() }
+	}
+	return seq
+}
+
+// arrivalSequence returns the position of every iteration in the sequence of
+// all iterations of an arrival-rate executor, measured in average intervals
+// between them. With the constant distribution, the i-th iteration is simply
+// at position i. With the random distributions, the position is the sum of i
+// random intervals, each one of which is 1 on average.
+//
+// To stay correct when the test is split in execution segments, every
+// instance generates the whole sequence with the same seed and only starts the
+// iterations that fall in its segment, exactly like with the constant one.
+type arrivalSequence struct {
+	nextInterval func() float64 // nil for the constant distribution
+
+	index    int64
+	position float64
+}
+
+// at returns the position of the i-th iteration of the whole sequence. It has
+// to be called with non-decreasing values of i.
+func (as *arrivalSequence) at(i int64) float64 {
+	if as.nextInterval == nil {
+		return float64(i)
+	}
+	for ; as.index < i; as.index++ {
+		as.position += as.nextInterval()
+	}
+	return as.position
+}
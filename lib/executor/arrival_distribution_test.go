@@ -0,0 +1,119 @@
+package executor
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+)
+
+func TestArrivalSequence(t *testing.T) {
+	t.Parallel()
+
+	constant := ArrivalDistributionConfig{}.newArrivalSequence("test")
+	for _, i := range []int64{0, 1, 5, 1000} {
+		assert.Equal(t, float64(i), constant.at(i))
+	}
+
+	for _, distribution := range []string{arrivalDistributionPoisson, arrivalDistributionUniform} {
+		distribution := distribution
+		t.Run(distribution, func(t *testing.T) {
+			t.Parallel()
+
+			config := ArrivalDistributionConfig{ArrivalDistribution: null.StringFrom(distribution)}
+			seq, sameSeq := config.newArrivalSequence("test"), config.newArrivalSequence("test")
+			otherSeq := config.newArrivalSequence("other")
+
+			// the same positions have to be returned when some are skipped
+			assert.Equal(t, seq.at(10), sameSeq.at(10))
+			assert.NotEqual(t, seq.at(10), otherSeq.at(10))
+			prev := seq.at(10)
+			for i := int64(11); i < 100; i++ {
+				curr := seq.at(i)
+				assert.GreaterOrEqual(t, curr, prev)
+				prev = curr
+			}
+			assert.Equal(t, seq.at(100), sameSeq.at(100))
+
+			// the intervals are 1 on average
+			assert.InDelta(t, 100000, seq.at(100000), 2000)
+
+			config.ArrivalSeed = null.IntFrom(42)
+			assert.Equal(t, config.newArrivalSequence("test").at(50), config.newArrivalSequence("other").at(50))
+		})
+	}
+}
+
+func TestArrivalDistributionConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	config := NewConstantArrivalRateConfig("default")
+	config.Rate = null.IntFrom(10)
+	config.Duration = types.NullDurationFrom(time.Minute)
+	config.PreAllocatedVUs = null.IntFrom(1)
+	assert.Empty(t, config.Validate())
+
+	config.ArrivalDistribution = null.StringFrom(arrivalDistributionPoisson)
+	assert.Empty(t, config.Validate())
+
+	config.ArrivalDistribution = null.StringFrom("normal")
+	errs := config.Validate()
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "unsupported arrivalDistribution 'normal'")
+}
+
+func TestRampingArrivalRateCalSegmentsWithPoisson(t *testing.T) {
+	t.Parallel()
+
+	config := RampingArrivalRateConfig{
+		BaseConfig: BaseConfig{Name: "poisson"},
+		TimeUnit:   types.NullDurationFrom(time.Second),
+		StartRate:  null.IntFrom(0),
+		Stages: []Stage{
+			{Duration: types.NullDurationFrom(2 * time.Second), Target: null.IntFrom(100)},
+			{Duration: types.NullDurationFrom(2 * time.Second), Target: null.IntFrom(100)},
+			{Duration: types.NullDurationFrom(2 * time.Second), Target: null.IntFrom(0)},
+		},
+		ArrivalDistributionConfig: ArrivalDistributionConfig{
+			ArrivalDistribution: null.StringFrom(arrivalDistributionPoisson),
+		},
+	}
+
+	calAll := func(et *lib.ExecutionTuple) []time.Duration {
+		ch := make(chan time.Duration)
+		go config.cal(et, ch)
+		var result []time.Duration
+		for c := range ch {
+			result = append(result, c)
+		}
+		return result
+	}
+
+	expected := calAll(mustNewExecutionTuple(nil, nil))
+	assert.InDelta(t, 400, len(expected), 60)
+	assert.True(t, sort.SliceIsSorted(expected, func(i, j int) bool { return expected[i] < expected[j] }))
+
+	// the iterations of the poisson distribution shouldn't be evenly spaced
+	evenCount := 0
+	for i := 2; i < len(expected); i++ {
+		if expected[i]-expected[i-1] == expected[i-1]-expected[i-2] {
+			evenCount++
+		}
+	}
+	assert.Less(t, evenCount, len(expected)/10)
+
+	seq, err := lib.NewExecutionSegmentSequenceFromString("0,1/3,2/3,1")
+	require.NoError(t, err)
+	var union []time.Duration
+	for _, segment := range seq {
+		union = append(union, calAll(mustNewExecutionTuple(segment, &seq))...)
+	}
+	sort.Slice(union, func(i, j int) bool { return union[i] < union[j] })
+	assert.Equal(t, expected, union)
+}
@@ -27,6 +27,8 @@ type BaseConfig struct {
 	Name         string               `json:"-"` // set via the JS object key
 	Type         string               `json:"executor"`
 	StartTime    types.NullDuration   `json:"startTime"`
+	StartAfter   []string             `json:"startAfter,omitempty"`
+	StartWhen    map[string][]string  `json:"startWhen,omitempty"`
 	GracefulStop types.NullDuration   `json:"gracefulStop"`
 	Env          map[string]string    `json:"env"`
 	Exec         null.String          `json:"exec"` // function name, externally validated
@@ -68,6 +70,14 @@ func (bc BaseConfig) Validate() (errors []error) {
 	if bc.GracefulStop.Duration < 0 {
 		errors = append(errors, fmt.Errorf("the gracefulStop timeout can't be negative"))
 	}
+	for _, name := range bc.StartAfter {
+		if name == bc.Name {
+			errors = append(errors, fmt.Errorf("the scenario can't start after itself"))
+		}
+	}
+	if _, err := lib.ParseMetricConditions(bc.StartWhen); err != nil {
+		errors = append(errors, fmt.Errorf("invalid startWhen: %w", err))
+	}
 	return errors
 }
 
@@ -87,6 +97,19 @@ func (bc BaseConfig) GetStartTime() time.Duration {
 	return bc.StartTime.TimeDuration()
 }
 
+// GetStartAfter returns the names of the scenarios that have to finish before
+// this one starts.
+func (bc BaseConfig) GetStartAfter() []string {
+	return bc.StartAfter
+}
+
+// GetStartWhen returns the conditions, in the same format as the thresholds,
+// which have to pass for the metrics of the test so far, for the scenario to
+// start at all.
+func (bc BaseConfig) GetStartWhen() map[string][]string {
+	return bc.StartWhen
+}
+
 // GetGracefulStop returns how long k6 is supposed to wait for any still
 // running iterations to finish executing at the end of the normal executor
 // duration, before it actually kills them.
@@ -133,9 +156,15 @@ func (bc BaseConfig) getBaseInfo(facts ...string) string {
 	if bc.Exec.Valid {
 		facts = append(facts, fmt.Sprintf("exec: %s", bc.Exec.String))
 	}
+	if len(bc.StartAfter) > 0 {
+		facts = append(facts, fmt.Sprintf("startAfter: %s", strings.Join(bc.StartAfter, ", ")))
+	}
 	if bc.StartTime.Duration > 0 {
 		facts = append(facts, fmt.Sprintf("startTime: %s", bc.StartTime.Duration))
 	}
+	if len(bc.StartWhen) > 0 {
+		facts = append(facts, fmt.Sprintf("startWhen: %s", strings.Join(getConditionSources(bc.StartWhen), " && ")))
+	}
 	if bc.GracefulStop.Duration > 0 {
 		facts = append(facts, fmt.Sprintf("gracefulStop: %s", bc.GracefulStop.Duration))
 	}
@@ -43,6 +43,8 @@ type ConstantArrivalRateConfig struct {
 	// absolutely hard limit on the number of VUs the executor will use
 	PreAllocatedVUs null.Int `json:"preAllocatedVUs"`
 	MaxVUs          null.Int `json:"maxVUs"`
+
+	ArrivalDistributionConfig
 }
 
 // NewConstantArrivalRateConfig returns a ConstantArrivalRateConfig with default values
@@ -83,8 +85,12 @@ func (carc ConstantArrivalRateConfig) GetDescription(et *lib.ExecutionTuple) str
 		arrRatePerSec, _ = getArrivalRatePerSec(arrRate).Float64()
 	}
 
+	facts := []string{maxVUsRange}
+	if arrivals := carc.ArrivalDistributionConfig.getDescription(); arrivals != "" {
+		facts = append(facts, arrivals)
+	}
 	return fmt.Sprintf("%.2f iterations/s for %s%s", arrRatePerSec, carc.Duration.Duration,
-		carc.getBaseInfo(maxVUsRange))
+		carc.getBaseInfo(facts...))
 }
 
 // Validate makes sure all options are configured and valid
@@ -121,7 +127,7 @@ func (carc *ConstantArrivalRateConfig) Validate() []error {
 		errors = append(errors, fmt.Errorf("maxVUs can't be less than preAllocatedVUs"))
 	}
 
-	return errors
+	return append(errors, carc.ArrivalDistributionConfig.Validate()...)
 }
 
 // GetExecutionRequirements returns the number of required VUs to run the
@@ -212,7 +218,9 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 
 	returnedVUs := make(chan struct{})
 	waitOnProgressChannel := make(chan struct{})
-	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(parentCtx, duration, gracefulStop)
+	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(
+		parentCtx, duration, gracefulStop, car.executionState.GracefulStopNotify(),
+	)
 	defer func() {
 		cancel()
 		<-waitOnProgressChannel
@@ -321,11 +329,13 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 			int64(car.config.TimeUnit.TimeDuration()),
 		)).TimeDuration()
 
+	arrivals := car.config.newArrivalSequence(car.config.Name)
+
 	droppedIterationMetric := car.executionState.Test.BuiltinMetrics.DroppedIterations
 	shownWarning := false
 	metricTags := car.getMetricTags(nil)
 	for li, gi := 0, start; ; li, gi = li+1, gi+offsets[li%len(offsets)] {
-		t := notScaledTickerPeriod*time.Duration(gi) - time.Since(startTime)
+		t := time.Duration(float64(notScaledTickerPeriod)*arrivals.at(gi)) - time.Since(startTime)
 		timer.Reset(t)
 		select {
 		case <-timer.C:
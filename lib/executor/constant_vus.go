@@ -128,7 +128,9 @@ func (clv ConstantVUs) Run(parentCtx context.Context, _ chan<- metrics.SampleCon
 	gracefulStop := clv.config.GetGracefulStop()
 
 	waitOnProgressChannel := make(chan struct{})
-	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(parentCtx, duration, gracefulStop)
+	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(
+		parentCtx, duration, gracefulStop, clv.executionState.GracefulStopNotify(),
+	)
 	defer func() {
 		cancel()
 		<-waitOnProgressChannel
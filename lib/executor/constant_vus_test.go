@@ -3,6 +3,7 @@ package executor
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -52,3 +53,46 @@ func TestConstantVUsRun(t *testing.T) {
 	})
 	assert.Equal(t, uint64(50), totalIters)
 }
+
+func TestConstantVUsRunStoppedGracefully(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		gracefulStop   time.Duration
+		expInterrupted int64
+	}{
+		{gracefulStop: time.Second, expInterrupted: 0},
+		{gracefulStop: 100 * time.Millisecond, expInterrupted: 10},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.gracefulStop.String(), func(t *testing.T) {
+			t.Parallel()
+
+			var iterations, interrupted int64
+			runner := simpleRunner(func(ctx context.Context, _ *lib.State) error {
+				atomic.AddInt64(&iterations, 1)
+				select {
+				case <-ctx.Done():
+					atomic.AddInt64(&interrupted, 1)
+				case <-time.After(500 * time.Millisecond):
+				}
+				return nil
+			})
+
+			config := getTestConstantVUsConfig()
+			config.GracefulStop = types.NullDurationFrom(tc.gracefulStop)
+			config.Duration = types.NullDurationFrom(10 * time.Second)
+			test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+			defer test.cancel()
+
+			time.AfterFunc(100*time.Millisecond, func() { require.NoError(t, test.state.StopGracefully()) })
+			startTime := time.Now()
+			require.NoError(t, test.executor.Run(test.ctx, nil))
+
+			assert.Less(t, time.Since(startTime), time.Second)
+			assert.Equal(t, int64(10), atomic.LoadInt64(&iterations))
+			assert.Equal(t, tc.expInterrupted, atomic.LoadInt64(&interrupted))
+		})
+	}
+}
@@ -407,6 +407,63 @@ var configMapTestCases = []configMapTestCase{
 	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 20, "maxVUs": 50, "stages": [{"duration": "5m", "target": 10}], "timeUnit": "0s"}}`, exp{validationError: true}},
 	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 30, "maxVUs": 20, "stages": [{"duration": "5m", "target": 10}]}}`, exp{validationError: true}},
 	// TODO: more tests of mixed executors and execution plans
+	// startAfter and startWhen
+	{
+		`{"warmup": {"executor": "per-vu-iterations", "vus": 5, "iterations": 1, "maxDuration": "1m", "gracefulStop": "10s"},
+		  "load": {"executor": "constant-vus", "vus": 20, "duration": "5m", "startAfter": ["warmup"], "startTime": "5s",
+		    "startWhen": {"http_req_failed": ["rate<0.01"]}},
+		  "spike": {"executor": "constant-vus", "vus": 50, "duration": "1m", "startAfter": ["load", "warmup"]},
+		  "other": {"executor": "constant-vus", "vus": 1, "duration": "30s", "startTime": "2m"}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			assert.Empty(t, cm.Validate())
+			assert.Equal(t, []string{"warmup"}, cm["load"].GetStartAfter())
+			assert.Equal(t, map[string][]string{"http_req_failed": {"rate<0.01"}}, cm["load"].GetStartWhen())
+
+			et, err := lib.NewExecutionTuple(nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t,
+				"20 looping VUs for 5m0s (startAfter: warmup, startTime: 5s, startWhen: http_req_failed: rate<0.01, gracefulStop: 30s)",
+				cm["load"].GetDescription(et))
+
+			assert.Equal(t, map[string]time.Duration{
+				"warmup": 0,
+				"load":   75 * time.Second,
+				"spike":  75*time.Second + 5*time.Minute + 30*time.Second,
+				"other":  2 * time.Minute,
+			}, cm.GetPlannedStartTimes(et))
+
+			sortedNames := []string{}
+			for _, config := range cm.GetSortedConfigs() {
+				sortedNames = append(sortedNames, config.GetName())
+			}
+			assert.Equal(t, []string{"warmup", "load", "other", "spike"}, sortedNames)
+
+			// warmup may finish right away, so spike may run at the same time
+			// as other, but never at the same time as warmup or load
+			totalReqs := cm.GetFullExecutionRequirements(et)
+			assert.Equal(t, lib.ExecutionStep{TimeOffset: 5 * time.Second, PlannedVUs: 50}, totalReqs[2])
+			assert.Equal(t, lib.ExecutionStep{TimeOffset: 2 * time.Minute, PlannedVUs: 51}, totalReqs[3])
+			endOffset, isFinal := lib.GetEndOffset(totalReqs)
+			assert.Equal(t, 75*time.Second+5*time.Minute+30*time.Second+90*time.Second, endOffset)
+			assert.Equal(t, true, isFinal)
+			assert.Equal(t, uint64(51), lib.GetMaxPlannedVUs(totalReqs))
+		}},
+	},
+	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["aname"]}}`, exp{validationError: true}},
+	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["unknown"]}}`, exp{validationError: true}},
+	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": "other"}}`, exp{parseError: true}},
+	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startWhen": {"iterations": ["count>"]}}}`, exp{validationError: true}},
+	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startWhen": {"iterations": ["count>1 over 1s"]}}}`, exp{validationError: true}},
+	{
+		`{"a": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["b"]},
+		  "b": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["c"]},
+		  "c": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["a"]}}`,
+		exp{validationError: true, custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			errs := cm.Validate()
+			require.Len(t, errs, 1)
+			assert.Equal(t, "scenarios can't start after each other: a -> b -> c -> a", errs[0].Error())
+		}},
+	},
 
 	// scenario options
 	{
@@ -553,6 +553,8 @@ func (mex *ExternallyControlled) Run(parentCtx context.Context, _ chan<- metrics
 		select {
 		case <-ctx.Done():
 			return nil
+		case <-mex.executionState.GracefulStopNotify():
+			return nil // it doesn't support gracefulStop, so it stops like at the end of its duration
 		case updateConfigEvent := <-mex.newControlConfigs:
 			err := runState.handleConfigChange(currentControlConfig, updateConfigEvent.newConfig) //nolint:contextcheck
 			if err != nil {
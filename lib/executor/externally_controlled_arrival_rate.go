@@ -0,0 +1,625 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/ui/pb"
+)
+
+const externallyControlledArrivalRateType = "externally-controlled-arrival-rate"
+
+func init() {
+	lib.RegisterExecutorConfigType(
+		externallyControlledArrivalRateType,
+		func(name string, rawJSON []byte) (lib.ExecutorConfig, error) {
+			config := NewExternallyControlledArrivalRateConfig(name)
+			err := lib.StrictJSONUnmarshal(rawJSON, &config)
+			if err != nil {
+				return config, err
+			}
+			if !config.MaxVUs.Valid {
+				config.MaxVUs = config.PreAllocatedVUs
+			}
+			return config, nil
+		},
+	)
+}
+
+// ExternallyControlledArrivalRateConfigParams contains all of the options that
+// actually determine the scheduling of iterations in the externally controlled
+// arrival-rate executor.
+type ExternallyControlledArrivalRateConfigParams struct {
+	Rate     null.Int           `json:"rate"`
+	TimeUnit types.NullDuration `json:"timeUnit"`
+	Duration types.NullDuration `json:"duration"` // 0 is a valid value, meaning infinite duration
+
+	PreAllocatedVUs null.Int `json:"preAllocatedVUs"`
+	MaxVUs          null.Int `json:"maxVUs"`
+}
+
+// Validate just checks the control options in isolation.
+func (mecc ExternallyControlledArrivalRateConfigParams) Validate() (errors []error) {
+	if !mecc.Rate.Valid {
+		errors = append(errors, fmt.Errorf("the iteration rate isn't specified"))
+	} else if mecc.Rate.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the iteration rate can't be negative"))
+	}
+
+	if mecc.TimeUnit.TimeDuration() <= 0 {
+		errors = append(errors, fmt.Errorf("the timeUnit must be more than 0"))
+	}
+
+	if !mecc.Duration.Valid {
+		errors = append(errors, fmt.Errorf("the duration must be specified, for infinite duration use 0"))
+	} else if mecc.Duration.TimeDuration() < 0 {
+		errors = append(errors, fmt.Errorf(
+			"the duration can't be negative, for infinite duration use 0",
+		))
+	}
+
+	if !mecc.PreAllocatedVUs.Valid {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs isn't specified"))
+	} else if mecc.PreAllocatedVUs.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs can't be negative"))
+	}
+
+	if mecc.MaxVUs.Int64 < mecc.PreAllocatedVUs.Int64 {
+		errors = append(errors, fmt.Errorf("maxVUs can't be less than preAllocatedVUs"))
+	}
+
+	return errors
+}
+
+// ExternallyControlledArrivalRateConfig stores the current iteration rate, the
+// number of pre-allocated and max VUs and the executor duration. The duration
+// can be 0, which means "infinite duration", i.e. the user has to manually
+// abort the script.
+type ExternallyControlledArrivalRateConfig struct {
+	BaseConfig
+	ExternallyControlledArrivalRateConfigParams
+}
+
+// NewExternallyControlledArrivalRateConfig returns an
+// ExternallyControlledArrivalRateConfig with default values.
+func NewExternallyControlledArrivalRateConfig(name string) ExternallyControlledArrivalRateConfig {
+	return ExternallyControlledArrivalRateConfig{
+		BaseConfig: NewBaseConfig(name, externallyControlledArrivalRateType),
+		ExternallyControlledArrivalRateConfigParams: ExternallyControlledArrivalRateConfigParams{
+			TimeUnit: types.NewNullDuration(1*time.Second, false),
+		},
+	}
+}
+
+// Make sure we implement the lib.ExecutorConfig interface
+var _ lib.ExecutorConfig = &ExternallyControlledArrivalRateConfig{}
+
+// GetDescription returns a human-readable description of the executor options
+func (mec ExternallyControlledArrivalRateConfig) GetDescription(et *lib.ExecutionTuple) string {
+	duration := "infinite"
+	if mec.Duration.Duration != 0 {
+		duration = mec.Duration.String()
+	}
+	arrRatePerSec, _ := getArrivalRatePerSec(
+		getScaledArrivalRate(et.Segment, mec.Rate.Int64, mec.TimeUnit.TimeDuration()),
+	).Float64()
+
+	return fmt.Sprintf(
+		"Externally controlled execution with %.2f iterations/s, %d-%d VUs, %s duration",
+		arrRatePerSec, et.ScaleInt64(mec.PreAllocatedVUs.Int64), et.ScaleInt64(mec.MaxVUs.Int64), duration,
+	)
+}
+
+// Validate makes sure all options are configured and valid
+func (mec ExternallyControlledArrivalRateConfig) Validate() []error {
+	return append(mec.BaseConfig.Validate(), mec.ExternallyControlledArrivalRateConfigParams.Validate()...)
+}
+
+// GetExecutionRequirements reserves the configured number of pre-allocated
+// and max VUs for the whole duration of the executor. As with the externally
+// controlled executor, if 0 (i.e. infinite) duration is configured, the last
+// step that relinquishes these VUs isn't emitted.
+//
+// The VUs above the initial maxVUs, which can be added from the REST API
+// during the test run, aren't included, for the same reasons as in the
+// externally controlled executor.
+func (mec ExternallyControlledArrivalRateConfig) GetExecutionRequirements(et *lib.ExecutionTuple) []lib.ExecutionStep {
+	preAllocatedVUs := et.ScaleInt64(mec.PreAllocatedVUs.Int64)
+	startVUs := lib.ExecutionStep{
+		TimeOffset:      0,
+		PlannedVUs:      uint64(preAllocatedVUs),
+		MaxUnplannedVUs: uint64(et.ScaleInt64(mec.MaxVUs.Int64) - preAllocatedVUs),
+	}
+
+	maxDuration := mec.Duration.TimeDuration()
+	if maxDuration == 0 {
+		return []lib.ExecutionStep{startVUs}
+	}
+	return []lib.ExecutionStep{startVUs, {
+		TimeOffset:      maxDuration + mec.GracefulStop.TimeDuration(),
+		PlannedVUs:      0,
+		MaxUnplannedVUs: 0,
+	}}
+}
+
+// IsDistributable simply returns false because there's no way to reliably
+// distribute the externally controlled arrival-rate executor.
+func (ExternallyControlledArrivalRateConfig) IsDistributable() bool {
+	return false
+}
+
+// NewExecutor creates a new ExternallyControlledArrivalRate executor
+func (mec ExternallyControlledArrivalRateConfig) NewExecutor(
+	es *lib.ExecutionState, logger *logrus.Entry,
+) (lib.Executor, error) {
+	return &ExternallyControlledArrivalRate{
+		BaseExecutor:         NewBaseExecutor(mec, es, logger),
+		config:               mec,
+		currentControlConfig: mec.ExternallyControlledArrivalRateConfigParams,
+		configLock:           &sync.RWMutex{},
+		newControlConfigs:    make(chan updateArrivalRateConfigEvent),
+		pauseEvents:          make(chan pauseEvent),
+		hasStarted:           make(chan struct{}),
+		hasFinished:          make(chan struct{}),
+	}, nil
+}
+
+// HasWork reports whether there is any work to be done for the given execution segment.
+func (mec ExternallyControlledArrivalRateConfig) HasWork(_ *lib.ExecutionTuple) bool {
+	// The rate can always be increased via the REST API, so return true.
+	return true
+}
+
+type updateArrivalRateConfigEvent struct {
+	newConfig ExternallyControlledArrivalRateConfigParams
+	err       chan error
+}
+
+// ExternallyControlledArrivalRate is the open model counterpart of the
+// externally controlled executor. It starts iterations at a rate that, along
+// with the number of VUs that execute them, can be changed via the k6 REST API
+// during the test run. It implements the lib.PausableExecutor and the
+// lib.LiveUpdatableExecutor interfaces.
+type ExternallyControlledArrivalRate struct {
+	*BaseExecutor
+	config               ExternallyControlledArrivalRateConfig
+	currentControlConfig ExternallyControlledArrivalRateConfigParams
+	configLock           *sync.RWMutex
+	newControlConfigs    chan updateArrivalRateConfigEvent
+	pauseEvents          chan pauseEvent
+	hasStarted           chan struct{}
+	hasFinished          chan struct{}
+}
+
+// Make sure we implement all the interfaces
+var (
+	_ lib.Executor              = &ExternallyControlledArrivalRate{}
+	_ lib.PausableExecutor      = &ExternallyControlledArrivalRate{}
+	_ lib.LiveUpdatableExecutor = &ExternallyControlledArrivalRate{}
+)
+
+// GetCurrentConfig just returns the executor's current configuration.
+func (mex *ExternallyControlledArrivalRate) GetCurrentConfig() ExternallyControlledArrivalRateConfig {
+	mex.configLock.RLock()
+	defer mex.configLock.RUnlock()
+	return ExternallyControlledArrivalRateConfig{
+		BaseConfig: mex.config.BaseConfig,
+		ExternallyControlledArrivalRateConfigParams: mex.currentControlConfig,
+	}
+}
+
+// GetConfig just returns the executor's current configuration, it's basically
+// an alias of GetCurrentConfig that implements the more generic interface.
+func (mex *ExternallyControlledArrivalRate) GetConfig() lib.ExecutorConfig {
+	return mex.GetCurrentConfig()
+}
+
+// SetPaused pauses or resumes the executor. No new iterations are started while
+// it's paused, though the ones that are in progress are allowed to finish.
+func (mex *ExternallyControlledArrivalRate) SetPaused(paused bool) error {
+	select {
+	case <-mex.hasStarted:
+		event := pauseEvent{isPaused: paused, err: make(chan error)}
+		select {
+		case mex.pauseEvents <- event:
+			return <-event.err
+		case <-mex.hasFinished:
+			return nil
+		}
+	default:
+		return fmt.Errorf("cannot pause the externally controlled arrival-rate executor before it has started")
+	}
+}
+
+// UpdateConfig validates the supplied config and updates it in real time. It is
+// possible to update the configuration even before the executor has started,
+// e.g. when running k6 with --paused.
+//
+// Lowering the number of pre-allocated or max VUs doesn't release the VUs that
+// were already initialized, it only stops the executor from initializing new
+// ones above the new maxVUs.
+func (mex *ExternallyControlledArrivalRate) UpdateConfig(ctx context.Context, newConf interface{}) error {
+	newConfigParams, ok := newConf.(ExternallyControlledArrivalRateConfigParams)
+	if !ok {
+		return errors.New("invalid config type")
+	}
+	if errs := newConfigParams.Validate(); len(errs) != 0 {
+		return fmt.Errorf("invalid configuration supplied: %s", lib.ConcatErrors(errs, ", "))
+	}
+
+	if newConfigParams.Duration != mex.config.Duration {
+		return fmt.Errorf("the externally controlled arrival-rate executor duration cannot be changed")
+	}
+
+	mex.configLock.Lock() // guard against a simultaneous start of the test (which will close hasStarted)
+	select {
+	case <-mex.hasStarted:
+		mex.configLock.Unlock()
+		event := updateArrivalRateConfigEvent{newConfig: newConfigParams, err: make(chan error)}
+		select {
+		case mex.newControlConfigs <- event:
+			return <-event.err
+		case <-mex.hasFinished:
+			return fmt.Errorf("the externally controlled arrival-rate executor has already finished")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	case <-ctx.Done():
+		mex.configLock.Unlock()
+		return ctx.Err()
+	default:
+		mex.currentControlConfig = newConfigParams
+		mex.configLock.Unlock()
+		return nil
+	}
+}
+
+// externallyControlledArrivalRateRunState is created and initialized by the
+// Run() method of the externally controlled arrival-rate executor. It is used
+// to track the VUs of the executor and to handle the live config changes.
+type externallyControlledArrivalRateRunState struct {
+	ctx         context.Context
+	executor    *ExternallyControlledArrivalRate
+	vusPool     *activeVUPool
+	activeVUsWg *sync.WaitGroup
+
+	vus               int64 // the number of VUs that were initialized or are being initialized
+	maxVUs            int64 // the current (scaled) number of max VUs
+	reservedUnplanned int64 // the unplanned VUs that are included in the execution plan
+	tickerPeriod      int64 // the current time between iterations, used only for the progress display
+
+	runIteration func(context.Context, lib.ActiveVU) bool
+}
+
+// activateVU adds the VU to the pool of VUs that execute the iterations. The
+// VUs that weren't planned by the execution scheduler aren't returned to its
+// buffer at the end.
+func (rs *externallyControlledArrivalRateRunState) activateVU(initVU lib.InitializedVU, planned bool) {
+	executionState := rs.executor.executionState
+	returnVU := func(u lib.InitializedVU) {
+		if planned {
+			executionState.ReturnVU(u, false)
+		} else {
+			executionState.ModInitializedVUsCount(-1)
+		}
+		rs.activeVUsWg.Done()
+	}
+
+	rs.activeVUsWg.Add(1)
+	activeVU := initVU.Activate(getVUActivationParams(
+		rs.ctx, rs.executor.config.BaseConfig, returnVU,
+		rs.executor.nextIterationCounters,
+	))
+	rs.vusPool.AddVU(rs.ctx, activeVU, rs.runIteration)
+}
+
+// initializeVU initializes a new VU and adds it to the pool. The number of VUs
+// should already be incremented.
+func (rs *externallyControlledArrivalRateRunState) initializeVU() error {
+	executionState := rs.executor.executionState
+	logger := rs.executor.logger
+
+	var initVU lib.InitializedVU
+	var err error
+	planned := atomic.AddInt64(&rs.reservedUnplanned, -1) >= 0
+	if planned {
+		initVU, err = executionState.GetUnplannedVU(rs.ctx, logger)
+	} else {
+		initVU, err = executionState.InitializeNewVU(rs.ctx, logger)
+	}
+	if err != nil {
+		atomic.AddInt64(&rs.vus, -1)
+		if planned {
+			atomic.AddInt64(&rs.reservedUnplanned, 1)
+		}
+		return err
+	}
+	rs.activateVU(initVU, planned)
+	return nil
+}
+
+// getTickerPeriod returns the time between the iterations for the given config,
+// or 0 if no iterations should be started.
+func (rs *externallyControlledArrivalRateRunState) getTickerPeriod(
+	conf ExternallyControlledArrivalRateConfigParams,
+) time.Duration {
+	segment := rs.executor.executionState.ExecutionTuple.Segment
+	return getTickerPeriod(getScaledArrivalRate(segment, conf.Rate.Int64, conf.TimeUnit.TimeDuration())).TimeDuration()
+}
+
+func (rs *externallyControlledArrivalRateRunState) handleConfigChange(
+	newCfg ExternallyControlledArrivalRateConfigParams,
+) error {
+	et := rs.executor.executionState.ExecutionTuple
+	newPreAllocatedVUs := et.ScaleInt64(newCfg.PreAllocatedVUs.Int64)
+	newMaxVUs := et.ScaleInt64(newCfg.MaxVUs.Int64)
+
+	rs.executor.logger.WithFields(logrus.Fields{
+		"rate": newCfg.Rate.Int64, "timeUnit": newCfg.TimeUnit.TimeDuration(),
+		"preAllocatedVUs": newPreAllocatedVUs, "maxVUs": newMaxVUs,
+	}).Debug("Updating execution configuration...")
+
+	atomic.StoreInt64(&rs.maxVUs, newMaxVUs)
+	for atomic.LoadInt64(&rs.vus) < newPreAllocatedVUs {
+		select { // check if the user didn't try to abort k6 while we're scaling up the VUs
+		case <-rs.ctx.Done():
+			return rs.ctx.Err()
+		default: // do nothing
+		}
+		atomic.AddInt64(&rs.vus, 1)
+		if err := rs.initializeVU(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run starts iterations at the configured rate, which can be changed
+// dynamically, either for the specified duration, or until the test is
+// manually stopped.
+//
+//nolint:funlen
+func (mex *ExternallyControlledArrivalRate) Run(parentCtx context.Context, out chan<- metrics.SampleContainer) error {
+	mex.configLock.RLock()
+	// Safely get the current config - it's important that the close of the
+	// hasStarted channel is inside of the lock, so that there are no data races
+	// between it and the UpdateConfig() method.
+	currentControlConfig := mex.currentControlConfig
+	close(mex.hasStarted)
+	mex.configLock.RUnlock()
+	defer close(mex.hasFinished)
+
+	et := mex.executionState.ExecutionTuple
+	duration := currentControlConfig.Duration.TimeDuration()
+	startPreAllocatedVUs := et.ScaleInt64(mex.config.PreAllocatedVUs.Int64)
+
+	mex.logger.WithFields(logrus.Fields{
+		"type": externallyControlledArrivalRateType, "duration": duration,
+		"preAllocatedVUs": startPreAllocatedVUs, "maxVUs": et.ScaleInt64(mex.config.MaxVUs.Int64),
+	}).Debug("Starting executor run...")
+
+	var (
+		startTime                      time.Time
+		maxDurationCtx, regDurationCtx context.Context
+		cancel                         func()
+	)
+	if duration > 0 {
+		startTime, maxDurationCtx, regDurationCtx, cancel = getDurationContexts(
+			parentCtx, duration, mex.config.GetGracefulStop(), mex.executionState.GracefulStopNotify())
+	} else { // infinite duration, until the test is stopped
+		startTime = time.Now()
+		ctx, ctxCancel := context.WithCancel(parentCtx)
+		maxDurationCtx, regDurationCtx, cancel = ctx, ctx, ctxCancel
+		go func() {
+			select {
+			case <-mex.executionState.GracefulStopNotify():
+				ctxCancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	runState := &externallyControlledArrivalRateRunState{
+		executor:          mex,
+		vusPool:           newActiveVUPool(mex.executionState),
+		activeVUsWg:       &sync.WaitGroup{},
+		vus:               startPreAllocatedVUs,
+		maxVUs:            et.ScaleInt64(mex.config.MaxVUs.Int64),
+		reservedUnplanned: et.ScaleInt64(mex.config.MaxVUs.Int64) - startPreAllocatedVUs,
+		runIteration:      getIterationRunner(mex.executionState, mex.logger),
+	}
+
+	waitOnProgressChannel := make(chan struct{})
+	makeUnplannedVUCh := make(chan struct{})
+	returnedVUs := make(chan struct{})
+	defer func() {
+		close(makeUnplannedVUCh)
+		// Make sure all VUs aren't executing iterations anymore, for the cancel()
+		// below to deactivate them.
+		<-returnedVUs
+		// first close the vusPool so we wait for the gracefulShutdown
+		runState.vusPool.Close()
+		cancel()
+		runState.activeVUsWg.Wait()
+		<-waitOnProgressChannel
+	}()
+
+	progressFn := func() (float64, []string) {
+		progVUs := fmt.Sprintf("%d/%d VUs", runState.vusPool.Running(), atomic.LoadInt64(&runState.vus))
+
+		itersPerSec := 0.0
+		if currentTickerPeriod := atomic.LoadInt64(&runState.tickerPeriod); currentTickerPeriod > 0 {
+			itersPerSec = float64(time.Second) / float64(currentTickerPeriod)
+		}
+		progIters := fmt.Sprintf("%.2f iters/s", itersPerSec)
+
+		spent := time.Since(startTime)
+		if duration == 0 {
+			return 0, []string{progVUs, pb.GetFixedLengthDuration(spent, spent), progIters}
+		}
+		right := []string{progVUs, duration.String(), progIters}
+		if spent > duration {
+			return 1, right
+		}
+		right[1] = fmt.Sprintf("%s/%s", pb.GetFixedLengthDuration(spent, duration), duration)
+		return math.Min(1, float64(spent)/float64(duration)), right
+	}
+	mex.progress.Modify(pb.WithProgress(progressFn))
+	maxDurationCtx = lib.WithScenarioState(maxDurationCtx, &lib.ScenarioState{
+		Name:       mex.config.Name,
+		Executor:   mex.config.Type,
+		StartTime:  startTime,
+		ProgressFn: progressFn,
+	})
+	runState.ctx = maxDurationCtx
+
+	go func() {
+		trackProgress(parentCtx, maxDurationCtx, regDurationCtx, mex, progressFn)
+		close(waitOnProgressChannel)
+	}()
+
+	go func() {
+		defer close(returnedVUs)
+		for range makeUnplannedVUCh {
+			mex.logger.Debug("Starting initialization of an unplanned VU...")
+			if err := runState.initializeVU(); err != nil {
+				// TODO figure out how to return it to the Run goroutine
+				mex.logger.WithError(err).Error("Error while allocating unplanned VU")
+			} else {
+				mex.logger.Debug("The unplanned VU finished initializing successfully!")
+			}
+		}
+	}()
+
+	// Get the pre-allocated VUs in the local buffer
+	for i := int64(0); i < startPreAllocatedVUs; i++ {
+		initVU, err := mex.executionState.GetPlannedVU(mex.logger, false)
+		if err != nil {
+			return err
+		}
+		runState.activateVU(initVU, true)
+	}
+	// The config could have been changed before the start.
+	if err := runState.handleConfigChange(currentControlConfig); err != nil { //nolint:contextcheck
+		return err
+	}
+
+	tickerPeriod := runState.getTickerPeriod(currentControlConfig)
+	atomic.StoreInt64(&runState.tickerPeriod, int64(tickerPeriod))
+	nextTime := time.Now()
+	var lastTime time.Time
+
+	timer := time.NewTimer(time.Hour)
+	stopTimer := func() {
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+	}
+	stopTimer()
+
+	droppedIterationMetric := mex.executionState.Test.BuiltinMetrics.DroppedIterations
+	metricTags := mex.getMetricTags(nil)
+	regDurationDone := regDurationCtx.Done()
+	shownWarning := false
+	currentlyPaused := false
+	for {
+		var timerCh <-chan time.Time
+		// no iterations are started with a rate of 0 or while paused
+		if tickerPeriod > 0 && !currentlyPaused {
+			timer.Reset(time.Until(nextTime))
+			timerCh = timer.C
+		}
+
+		select {
+		case <-timerCh:
+			lastTime = nextTime
+			nextTime = nextTime.Add(tickerPeriod)
+			if runState.vusPool.TryRunIteration() {
+				continue
+			}
+
+			// Since there aren't any free VUs available, consider this iteration
+			// dropped - we aren't going to try to recover it, but
+			metrics.PushIfNotDone(parentCtx, out, metrics.Sample{
+				TimeSeries: metrics.TimeSeries{
+					Metric: droppedIterationMetric,
+					Tags:   metricTags,
+				},
+				Time:  time.Now(),
+				Value: 1,
+			})
+
+			// We'll try to start allocating another VU in the background,
+			// non-blockingly, if we haven't reached maxVUs yet...
+			maxVUs := atomic.LoadInt64(&runState.maxVUs)
+			if atomic.LoadInt64(&runState.vus) >= maxVUs {
+				if !shownWarning {
+					mex.logger.Warningf("Insufficient VUs, reached %d active VUs and cannot initialize more", maxVUs)
+					shownWarning = true
+				}
+				continue
+			}
+
+			atomic.AddInt64(&runState.vus, 1)
+			select {
+			case makeUnplannedVUCh <- struct{}{}: // great!
+			default: // we're already allocating a new VU
+				atomic.AddInt64(&runState.vus, -1)
+			}
+
+		case updateConfigEvent := <-mex.newControlConfigs:
+			stopTimer()
+			err := runState.handleConfigChange(updateConfigEvent.newConfig) //nolint:contextcheck
+			if err != nil {
+				updateConfigEvent.err <- err
+				if errors.Is(maxDurationCtx.Err(), err) {
+					return nil // we've already returned an error to the API client, but k6 should stop normally
+				}
+				return err
+			}
+			currentControlConfig = updateConfigEvent.newConfig
+			mex.configLock.Lock()
+			mex.currentControlConfig = updateConfigEvent.newConfig
+			mex.configLock.Unlock()
+			shownWarning = false
+
+			// The next iteration starts one new period after the previous one,
+			// or right away if there wasn't one or that time has already passed.
+			tickerPeriod = runState.getTickerPeriod(currentControlConfig)
+			atomic.StoreInt64(&runState.tickerPeriod, int64(tickerPeriod))
+			nextTime = lastTime.Add(tickerPeriod)
+			if now := time.Now(); nextTime.Before(now) {
+				nextTime = now
+			}
+			updateConfigEvent.err <- nil
+
+		case pauseEvent := <-mex.pauseEvents:
+			stopTimer()
+			if !pauseEvent.isPaused && currentlyPaused {
+				// The iterations that would have been started during the pause
+				// are neither started nor counted as dropped after it.
+				nextTime = time.Now()
+			}
+			currentlyPaused = pauseEvent.isPaused
+			pauseEvent.err <- nil
+
+		case <-regDurationDone:
+			stopTimer()
+			return nil
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+func getTestExternallyControlledArrivalRateConfig() ExternallyControlledArrivalRateConfig {
+	return ExternallyControlledArrivalRateConfig{
+		BaseConfig: BaseConfig{GracefulStop: types.NullDurationFrom(time.Second)},
+		ExternallyControlledArrivalRateConfigParams: ExternallyControlledArrivalRateConfigParams{
+			Rate:            null.IntFrom(10),
+			TimeUnit:        types.NullDurationFrom(time.Second),
+			Duration:        types.NullDurationFrom(2 * time.Second),
+			PreAllocatedVUs: null.IntFrom(2),
+			MaxVUs:          null.IntFrom(5),
+		},
+	}
+}
+
+func TestExternallyControlledArrivalRateRun(t *testing.T) {
+	t.Parallel()
+
+	var count int64
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error {
+		atomic.AddInt64(&count, 1)
+		return nil
+	})
+
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, getTestExternallyControlledArrivalRateConfig())
+	defer test.cancel()
+
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	var wg sync.WaitGroup
+	errCh := make(chan error, 1)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errCh <- test.executor.Run(test.ctx, engineOut)
+	}()
+
+	updateConfig := func(rate, preAllocatedVUs, maxVUs int64, errMsg string) {
+		newConfig := getTestExternallyControlledArrivalRateConfig().ExternallyControlledArrivalRateConfigParams
+		newConfig.Rate = null.IntFrom(rate)
+		newConfig.PreAllocatedVUs = null.IntFrom(preAllocatedVUs)
+		newConfig.MaxVUs = null.IntFrom(maxVUs)
+		err := test.executor.(*ExternallyControlledArrivalRate).UpdateConfig(test.ctx, newConfig)
+		if errMsg != "" {
+			assert.EqualError(t, err, errMsg)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+
+	time.Sleep(time.Second)
+	assert.InDelta(t, 10, atomic.LoadInt64(&count), 1)
+	assert.EqualValues(t, 2, test.state.GetInitializedVUsCount())
+
+	updateConfig(50, 4, 10, "")
+	assert.EqualValues(t, 4, test.state.GetInitializedVUsCount())
+	updateConfig(-1, 4, 10, "invalid configuration supplied: the iteration rate can't be negative")
+	updateConfig(50, 4, 3, "invalid configuration supplied: maxVUs can't be less than preAllocatedVUs")
+	currentConfig := test.executor.(*ExternallyControlledArrivalRate).GetCurrentConfig()
+	assert.Equal(t, null.IntFrom(50), currentConfig.Rate)
+	assert.Equal(t, null.IntFrom(10), currentConfig.MaxVUs)
+
+	wg.Wait()
+	require.NoError(t, <-errCh)
+	assert.InDelta(t, 60, atomic.LoadInt64(&count), 3)
+	assert.EqualValues(t, 0, test.state.GetCurrentlyActiveVUsCount())
+
+	updateConfig(10, 2, 5, "the externally controlled arrival-rate executor has already finished")
+}
+
+func TestExternallyControlledArrivalRatePause(t *testing.T) {
+	t.Parallel()
+
+	var count int64
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error {
+		atomic.AddInt64(&count, 1)
+		return nil
+	})
+
+	config := getTestExternallyControlledArrivalRateConfig()
+	config.Rate = null.IntFrom(20)
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+
+	mex := test.executor.(*ExternallyControlledArrivalRate)
+	require.EqualError(t, mex.SetPaused(true),
+		"cannot pause the externally controlled arrival-rate executor before it has started")
+
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	errCh := make(chan error, 1)
+	go func() { errCh <- test.executor.Run(test.ctx, engineOut) }()
+
+	time.Sleep(500 * time.Millisecond)
+	require.NoError(t, mex.SetPaused(true))
+	pausedCount := atomic.LoadInt64(&count)
+	assert.InDelta(t, 10, pausedCount, 2)
+
+	time.Sleep(time.Second)
+	assert.Equal(t, pausedCount, atomic.LoadInt64(&count))
+	require.NoError(t, mex.SetPaused(false))
+
+	require.NoError(t, <-errCh)
+	assert.InDelta(t, 20, atomic.LoadInt64(&count), 3)
+	for _, s := range metrics.GetBufferedSamples(engineOut) {
+		assert.NotEqual(t, metrics.DroppedIterationsName, s.GetSamples()[0].Metric.Name)
+	}
+}
+
+func TestExternallyControlledArrivalRateZeroRate(t *testing.T) {
+	t.Parallel()
+
+	var count int64
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error {
+		atomic.AddInt64(&count, 1)
+		return nil
+	})
+
+	config := getTestExternallyControlledArrivalRateConfig()
+	config.Rate = null.IntFrom(0)
+	config.Duration = types.NullDurationFrom(time.Second)
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+
+	// the config can be changed before the executor has started
+	newConfig := config.ExternallyControlledArrivalRateConfigParams
+	newConfig.PreAllocatedVUs = null.IntFrom(3)
+	require.NoError(t, test.executor.(*ExternallyControlledArrivalRate).UpdateConfig(test.ctx, newConfig))
+
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	require.NoError(t, test.executor.Run(test.ctx, engineOut))
+	assert.Zero(t, atomic.LoadInt64(&count))
+	assert.EqualValues(t, 3, test.state.GetInitializedVUsCount())
+}
+
+func TestExternallyControlledArrivalRateConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	config := NewExternallyControlledArrivalRateConfig("default")
+	errs := config.Validate()
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0].Error(), "the iteration rate isn't specified")
+	assert.Contains(t, errs[1].Error(), "the duration must be specified")
+	assert.Contains(t, errs[2].Error(), "the number of preAllocatedVUs isn't specified")
+
+	config.Rate = null.IntFrom(0)
+	config.Duration = types.NullDurationFrom(0)
+	config.PreAllocatedVUs = null.IntFrom(1)
+	config.MaxVUs = null.IntFrom(1)
+	assert.Empty(t, config.Validate())
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []lib.ExecutionStep{{PlannedVUs: 1}}, config.GetExecutionRequirements(et))
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -165,17 +166,40 @@ func getIterationRunner(
 //   - If the whole test is aborted, the parent context will be cancelled, so
 //     that will also cancel these contexts, thus the "general abort" case is
 //     handled transparently.
-func getDurationContexts(parentCtx context.Context, regularDuration, gracefulStop time.Duration) (
-	startTime time.Time, maxDurationCtx, regDurationCtx context.Context, maxDurationCancel func(),
-) {
+//   - If the test is gracefully stopped, i.e. stopNotify is closed, the
+//     regDurationCtx is cancelled right away and the maxDurationCtx after the
+//     graceful stop period, as if the regular duration had just ended.
+func getDurationContexts(
+	parentCtx context.Context, regularDuration, gracefulStop time.Duration, stopNotify <-chan struct{},
+) (startTime time.Time, maxDurationCtx, regDurationCtx context.Context, maxDurationCancel func()) {
 	startTime = time.Now()
 	maxEndTime := startTime.Add(regularDuration + gracefulStop)
 
 	maxDurationCtx, maxDurationCancel = context.WithDeadline(parentCtx, maxEndTime)
-	if gracefulStop == 0 {
-		return startTime, maxDurationCtx, maxDurationCtx, maxDurationCancel
+	regDurationCtx, regDurationCancel := maxDurationCtx, maxDurationCancel
+	if gracefulStop > 0 {
+		regDurationCtx, regDurationCancel = context.WithDeadline(maxDurationCtx, startTime.Add(regularDuration))
 	}
-	regDurationCtx, _ = context.WithDeadline(maxDurationCtx, startTime.Add(regularDuration)) //nolint:govet
+
+	go func() {
+		select {
+		case <-regDurationCtx.Done():
+			return
+		case <-stopNotify:
+		}
+		regDurationCancel()
+		if gracefulStop == 0 {
+			return
+		}
+		timer := time.NewTimer(gracefulStop)
+		defer timer.Stop()
+		select {
+		case <-maxDurationCtx.Done():
+		case <-timer.C:
+			maxDurationCancel()
+		}
+	}()
+
 	return startTime, maxDurationCtx, regDurationCtx, maxDurationCancel
 }
 
@@ -262,3 +286,16 @@ func getVUActivationParams(
 		GetNextIterationCounters: nextIterationCounters,
 	}
 }
+
+// getConditionSources returns the conditions in a stable order, in the
+// `metric: expression` format.
+func getConditionSources(conditions map[string][]string) []string {
+	var result []string
+	for metricName, expressions := range conditions {
+		for _, expr := range expressions {
+			result = append(result, metricName+": "+expr)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
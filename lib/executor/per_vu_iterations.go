@@ -139,7 +139,9 @@ func (pvi PerVUIterations) Run(parentCtx context.Context, out chan<- metrics.Sam
 	gracefulStop := pvi.config.GetGracefulStop()
 
 	waitOnProgressChannel := make(chan struct{})
-	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(parentCtx, duration, gracefulStop)
+	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(
+		parentCtx, duration, gracefulStop, pvi.executionState.GracefulStopNotify(),
+	)
 	defer func() {
 		cancel()
 		<-waitOnProgressChannel
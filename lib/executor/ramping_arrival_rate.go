@@ -43,6 +43,8 @@ type RampingArrivalRateConfig struct {
 	// absolutely hard limit on the number of VUs the executor will use
 	PreAllocatedVUs null.Int `json:"preAllocatedVUs"`
 	MaxVUs          null.Int `json:"maxVUs"`
+
+	ArrivalDistributionConfig
 }
 
 // NewRampingArrivalRateConfig returns a RampingArrivalRateConfig with default values
@@ -78,9 +80,13 @@ func (varc RampingArrivalRateConfig) GetDescription(et *lib.ExecutionTuple) stri
 		getScaledArrivalRate(et.Segment, maxUnscaledRate, varc.TimeUnit.TimeDuration()),
 	).Float64()
 
+	facts := []string{maxVUsRange}
+	if arrivals := varc.ArrivalDistributionConfig.getDescription(); arrivals != "" {
+		facts = append(facts, arrivals)
+	}
 	return fmt.Sprintf("Up to %.2f iterations/s for %s over %d stages%s",
 		maxArrRatePerSec, sumStagesDuration(varc.Stages),
-		len(varc.Stages), varc.getBaseInfo(maxVUsRange))
+		len(varc.Stages), varc.getBaseInfo(facts...))
 }
 
 // Validate makes sure all options are configured and valid
@@ -110,7 +116,7 @@ func (varc *RampingArrivalRateConfig) Validate() []error {
 		errors = append(errors, fmt.Errorf("maxVUs can't be less than preAllocatedVUs"))
 	}
 
-	return errors
+	return append(errors, varc.ArrivalDistributionConfig.Validate()...)
 }
 
 // GetExecutionRequirements returns the number of required VUs to run the
@@ -231,6 +237,10 @@ func (varr *RampingArrivalRate) Init(_ context.Context) error {
 // The specific implementation here can only go forward and does incorporate
 // the striping algorithm from the lib.ExecutionTuple for additional speed up but this could
 // possibly be refactored if need for this arises.
+//
+// With a random arrival distribution, the area at which the n-th event happens
+// isn't n, but its position in the seeded arrival sequence, which is the same
+// for all execution segments.
 func (varc RampingArrivalRateConfig) cal(et *lib.ExecutionTuple, ch chan<- time.Duration) {
 	start, offsets, _ := et.GetStripedOffsets()
 	li := -1
@@ -240,21 +250,28 @@ func (varc RampingArrivalRateConfig) cal(et *lib.ExecutionTuple, ch chan<- time.
 		return offsets[li%len(offsets)]
 	}
 	defer close(ch) // TODO: maybe this is not a good design - closing a channel we get
+	arrivals := varc.newArrivalSequence(varc.Name)
 	var (
 		stageStart                   time.Duration
 		timeUnit                     = float64(varc.TimeUnit.Duration)
 		doneSoFar, endCount, to, dur float64
 		from                         = float64(varc.StartRate.ValueOrZero()) / timeUnit
-		// start .. starts at 0 but the algorithm works with area so we need to start from 1 not 0
-		i = float64(start + 1)
+		// gi is the index of the iteration in the whole test, but the
+		// algorithm works with area so we need to start from 1 not 0
+		gi = start
+		i  = arrivals.at(gi) + 1
 	)
+	advance := func() {
+		gi += next()
+		i = arrivals.at(gi) + 1
+	}
 
 	for _, stage := range varc.Stages {
 		to = float64(stage.Target.ValueOrZero()) / timeUnit
 		dur = float64(stage.Duration.Duration)
 		if from != to { // ramp up/down
 			endCount += dur * ((to-from)/2 + from)
-			for ; i <= endCount; i += float64(next()) {
+			for ; i <= endCount; advance() {
 				// TODO: try to twist this in a way to be able to get i (the only changing part)
 				// somewhere where it is less in the middle of the equation
 				x := (from*dur - noNegativeSqrt(dur*(from*from*dur+2*(i-doneSoFar)*(to-from)))) / (from - to)
@@ -263,7 +280,7 @@ func (varc RampingArrivalRateConfig) cal(et *lib.ExecutionTuple, ch chan<- time.
 			}
 		} else {
 			endCount += dur * to
-			for ; i <= endCount; i += float64(next()) {
+			for ; i <= endCount; advance() {
 				ch <- time.Duration((i-doneSoFar)/to) + stageStart
 			}
 		}
@@ -326,7 +343,9 @@ func (varr RampingArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 
 	returnedVUs := make(chan struct{})
 	waitOnProgressChannel := make(chan struct{})
-	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(parentCtx, duration, gracefulStop)
+	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(
+		parentCtx, duration, gracefulStop, varr.executionState.GracefulStopNotify(),
+	)
 
 	vusPool := newActiveVUPool(varr.executionState)
 
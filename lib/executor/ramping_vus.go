@@ -499,7 +499,7 @@ func (vlv *RampingVUs) Run(ctx context.Context, _ chan<- metrics.SampleContainer
 	}
 	waitOnProgressChannel := make(chan struct{})
 	startTime, maxDurationCtx, regularDurationCtx, cancel := getDurationContexts(
-		ctx, regularDuration, maxDuration-regularDuration,
+		ctx, regularDuration, maxDuration-regularDuration, vlv.executionState.GracefulStopNotify(),
 	)
 	defer func() {
 		cancel()
@@ -546,11 +546,26 @@ func (vlv *RampingVUs) Run(ctx context.Context, _ chan<- metrics.SampleContainer
 		handleNewMaxAllowedVUs = runState.maxAllowedVUsHandlerStrategy()
 		handleNewScheduledVUs  = runState.scheduledVUsHandlerStrategy()
 	)
+	// The steps are interrupted when the test is gracefully stopped, in which
+	// case all VUs are gracefully stopped, until the maxDurationCtx is done.
+	stepsCtx, stopSteps := context.WithCancel(ctx)
+	defer stopSteps()
+	go func() {
+		select {
+		case <-vlv.executionState.GracefulStopNotify():
+			stopSteps()
+		case <-stepsCtx.Done():
+		}
+	}()
 	handledGracefulSteps := runState.iterateSteps(
-		ctx,
+		stepsCtx,
 		handleNewMaxAllowedVUs,
 		handleNewScheduledVUs,
 	)
+	if ctx.Err() == nil && stepsCtx.Err() != nil {
+		handleNewScheduledVUs(lib.ExecutionStep{PlannedVUs: 0})
+		return nil
+	}
 	go runState.runRemainingGracefulSteps(
 		ctx,
 		handleNewMaxAllowedVUs,
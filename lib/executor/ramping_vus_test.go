@@ -237,6 +237,44 @@ func TestRampingVUsGracefulStopStops(t *testing.T) {
 	require.NoError(t, <-errCh)
 }
 
+func TestRampingVUsStoppedGracefully(t *testing.T) {
+	t.Parallel()
+
+	config := RampingVUsConfig{
+		BaseConfig: BaseConfig{GracefulStop: types.NullDurationFrom(time.Second)},
+		StartVUs:   null.IntFrom(2),
+		Stages: []Stage{
+			{
+				Duration: types.NullDurationFrom(10 * time.Second),
+				Target:   null.IntFrom(2),
+			},
+		},
+	}
+
+	var iterations, interrupted int64
+	runner := simpleRunner(func(ctx context.Context, _ *lib.State) error {
+		atomic.AddInt64(&iterations, 1)
+		select {
+		case <-ctx.Done():
+			atomic.AddInt64(&interrupted, 1)
+		case <-time.After(300 * time.Millisecond):
+		}
+		return nil
+	})
+
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+
+	time.AfterFunc(100*time.Millisecond, func() { require.NoError(t, test.state.StopGracefully()) })
+	startTime := time.Now()
+	require.NoError(t, test.executor.Run(test.ctx, nil))
+
+	// the iterations in progress are finished and no new ones are started
+	assert.Less(t, time.Since(startTime), time.Second)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&iterations))
+	assert.Equal(t, int64(0), atomic.LoadInt64(&interrupted))
+}
+
 func TestRampingVUsGracefulRampDown(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,614 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/ui/pb"
+)
+
+const replayType = "replay"
+
+const (
+	replayFormatCSV   = "csv"
+	replayFormatJSONL = "jsonl"
+)
+
+func init() {
+	lib.RegisterExecutorConfigType(
+		replayType,
+		func(name string, rawJSON []byte) (lib.ExecutorConfig, error) {
+			config := NewReplayConfig(name)
+			err := lib.StrictJSONUnmarshal(rawJSON, &config)
+			if err != nil {
+				return config, err
+			}
+			if !config.MaxVUs.Valid {
+				config.MaxVUs = config.PreAllocatedVUs
+			}
+			return config, nil
+		},
+	)
+}
+
+// ReplayConfig stores the config for the replay executor, which starts an
+// iteration for every record of an access log, at the time of the record.
+type ReplayConfig struct {
+	BaseConfig
+	// File is a CSV file with a header row, or a file with a JSON object on
+	// every line. The records have to be sorted by their timestamps. Relative
+	// paths are resolved against the directory of the script, like in open().
+	File   null.String `json:"file"`
+	Format null.String `json:"format"` // by default, it's detected from the file extension
+
+	// The timestamps are either RFC 3339 strings or Unix timestamps in
+	// milliseconds, like the JS ones.
+	TimestampField null.String `json:"timestampField"`
+	// Speed is how many times faster than the original the records are
+	// replayed, e.g. 2 replays them twice as fast and 0.5 twice as slow.
+	Speed    null.Float         `json:"speed"`
+	Duration types.NullDuration `json:"duration"`
+
+	PreAllocatedVUs null.Int `json:"preAllocatedVUs"`
+	MaxVUs          null.Int `json:"maxVUs"`
+}
+
+// NewReplayConfig returns a ReplayConfig with default values
+func NewReplayConfig(name string) ReplayConfig {
+	return ReplayConfig{
+		BaseConfig:     NewBaseConfig(name, replayType),
+		TimestampField: null.NewString("timestamp", false),
+		Speed:          null.NewFloat(1, false),
+	}
+}
+
+// Make sure we implement the lib.ExecutorConfig and lib.DataFilesConfig interfaces
+var (
+	_ lib.ExecutorConfig  = &ReplayConfig{}
+	_ lib.DataFilesConfig = &ReplayConfig{}
+)
+
+// GetPreAllocatedVUs is just a helper method that returns the scaled pre-allocated VUs.
+func (rc ReplayConfig) GetPreAllocatedVUs(et *lib.ExecutionTuple) int64 {
+	return et.ScaleInt64(rc.PreAllocatedVUs.Int64)
+}
+
+// GetMaxVUs is just a helper method that returns the scaled max VUs.
+func (rc ReplayConfig) GetMaxVUs(et *lib.ExecutionTuple) int64 {
+	return et.ScaleInt64(rc.MaxVUs.Int64)
+}
+
+// getFormat returns the configured format of the file, or the one of its
+// extension.
+func (rc ReplayConfig) getFormat() string {
+	if rc.Format.Valid {
+		return rc.Format.String
+	}
+	if strings.EqualFold(path.Ext(rc.File.String), ".csv") {
+		return replayFormatCSV
+	}
+	return replayFormatJSONL
+}
+
+// GetDescription returns a human-readable description of the executor options
+func (rc ReplayConfig) GetDescription(et *lib.ExecutionTuple) string {
+	preAllocatedVUs, maxVUs := rc.GetPreAllocatedVUs(et), rc.GetMaxVUs(et)
+	maxVUsRange := fmt.Sprintf("maxVUs: %d", preAllocatedVUs)
+	if maxVUs > preAllocatedVUs {
+		maxVUsRange += fmt.Sprintf("-%d", maxVUs)
+	}
+
+	return fmt.Sprintf("Replay of %s at %gx speed for up to %s%s",
+		rc.File.String, rc.Speed.Float64, rc.Duration.Duration, rc.getBaseInfo(maxVUsRange))
+}
+
+// Validate makes sure all options are configured and valid
+func (rc ReplayConfig) Validate() []error {
+	errors := rc.BaseConfig.Validate()
+	if rc.File.String == "" {
+		errors = append(errors, fmt.Errorf("the file with the records isn't specified"))
+	}
+
+	if format := rc.getFormat(); format != replayFormatCSV && format != replayFormatJSONL {
+		errors = append(errors, fmt.Errorf(
+			"unsupported format '%s', it should be '%s' or '%s'", format, replayFormatCSV, replayFormatJSONL,
+		))
+	}
+
+	if rc.TimestampField.String == "" {
+		errors = append(errors, fmt.Errorf("the timestampField can't be empty"))
+	}
+
+	if rc.Speed.Float64 <= 0 || math.IsInf(rc.Speed.Float64, 0) {
+		errors = append(errors, fmt.Errorf("the speed must be more than 0"))
+	}
+
+	if !rc.Duration.Valid {
+		errors = append(errors, fmt.Errorf("the duration is unspecified"))
+	} else if rc.Duration.TimeDuration() < minDuration {
+		errors = append(errors, fmt.Errorf(
+			"the duration must be at least %s, but is %s", minDuration, rc.Duration,
+		))
+	}
+
+	if !rc.PreAllocatedVUs.Valid {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs isn't specified"))
+	} else if rc.PreAllocatedVUs.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs can't be negative"))
+	}
+
+	if rc.MaxVUs.Int64 < rc.PreAllocatedVUs.Int64 {
+		errors = append(errors, fmt.Errorf("maxVUs can't be less than preAllocatedVUs"))
+	}
+
+	return errors
+}
+
+// GetExecutionRequirements returns the number of required VUs to run the
+// executor for its whole duration (disregarding any startTime), including the
+// maximum waiting time for any iterations to gracefully stop. This is used by
+// the execution scheduler in its VU reservation calculations, so it knows how
+// many VUs to pre-initialize.
+func (rc ReplayConfig) GetExecutionRequirements(et *lib.ExecutionTuple) []lib.ExecutionStep {
+	return []lib.ExecutionStep{
+		{
+			TimeOffset:      0,
+			PlannedVUs:      uint64(rc.GetPreAllocatedVUs(et)),
+			MaxUnplannedVUs: uint64(rc.GetMaxVUs(et) - rc.GetPreAllocatedVUs(et)),
+		}, {
+			TimeOffset:      rc.Duration.TimeDuration() + rc.GracefulStop.TimeDuration(),
+			PlannedVUs:      0,
+			MaxUnplannedVUs: 0,
+		},
+	}
+}
+
+// NewExecutor creates a new Replay executor
+func (rc ReplayConfig) NewExecutor(es *lib.ExecutionState, logger *logrus.Entry) (lib.Executor, error) {
+	return &Replay{
+		BaseExecutor: NewBaseExecutor(rc, es, logger),
+		config:       rc,
+	}, nil
+}
+
+// HasWork reports whether there is any work to be done for the given execution segment.
+func (rc ReplayConfig) HasWork(et *lib.ExecutionTuple) bool {
+	return rc.GetMaxVUs(et) > 0
+}
+
+// GetDataFiles returns the file with the records, so it's loaded in the file
+// system of the test and included in its archive.
+func (rc ReplayConfig) GetDataFiles() []string {
+	if !rc.File.Valid {
+		return nil
+	}
+	return []string{rc.File.String}
+}
+
+// replayRecord is a record of the replayed file, together with its position
+// in the whole file and among the records of the local instance.
+type replayRecord struct {
+	localIndex, globalIndex uint64
+	offset                  time.Duration // since the first record, already adjusted by the speed
+	data                    map[string]interface{}
+}
+
+// replayReader reads the records of the file one by one.
+type replayReader interface {
+	read() (map[string]interface{}, error) // returns io.EOF after the last record
+}
+
+type csvReplayReader struct {
+	reader *csv.Reader
+	header []string
+}
+
+func newCSVReplayReader(r io.Reader) (*csvReplayReader, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the header of the CSV file: %w", err)
+	}
+	return &csvReplayReader{reader: reader, header: append([]string(nil), header...)}, nil
+}
+
+func (crr *csvReplayReader) read() (map[string]interface{}, error) {
+	fields, err := crr.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]interface{}, len(crr.header))
+	for i, name := range crr.header {
+		if i < len(fields) {
+			data[name] = fields[i]
+		}
+	}
+	return data, nil
+}
+
+type jsonlReplayReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newJSONLReplayReader(r io.Reader) *jsonlReplayReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return &jsonlReplayReader{scanner: scanner}
+}
+
+func (jrr *jsonlReplayReader) read() (map[string]interface{}, error) {
+	for jrr.scanner.Scan() {
+		jrr.line++
+		line := jrr.scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var data map[string]interface{}
+		if err := json.Unmarshal(line, &data); err != nil {
+			return nil, fmt.Errorf("invalid JSON object on line %d: %w", jrr.line, err)
+		}
+		return data, nil
+	}
+	if err := jrr.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// parseReplayTimestamp returns the time of a record, in nanoseconds since the
+// Unix epoch.
+func parseReplayTimestamp(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v * float64(time.Millisecond), nil
+	case string:
+		if ms, err := strconv.ParseFloat(v, 64); err == nil {
+			return ms * float64(time.Millisecond), nil
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return 0, fmt.Errorf("'%s' isn't an RFC 3339 timestamp or a Unix timestamp in milliseconds", v)
+		}
+		return float64(t.UnixNano()), nil
+	default:
+		return 0, fmt.Errorf("unsupported timestamp value '%v'", value)
+	}
+}
+
+// Replay starts an iteration for every record of an access log, with the same
+// time between them as in the log, adjusted by the configured speed. The
+// records are split between the execution segments, the same way the
+// iterations of the arrival-rate executors are, and the iterations can access
+// them with `exec.scenario.record` from the k6/execution module.
+type Replay struct {
+	*BaseExecutor
+	config ReplayConfig
+
+	file   io.ReadCloser
+	reader replayReader
+
+	// the records of the currently running iterations by their local index
+	inFlightRecords sync.Map
+}
+
+// Make sure we implement the lib.Executor interface.
+var _ lib.Executor = &Replay{}
+
+// GetConfig returns the configuration with which this executor was launched.
+func (r *Replay) GetConfig() lib.ExecutorConfig {
+	return r.config
+}
+
+// Init opens the file with the records, so any errors are reported before the
+// test has started. The file is read from the file system of the test archive,
+// where it's loaded by lib.ScenarioConfigs.LoadDataFiles().
+func (r *Replay) Init(_ context.Context) error {
+	arc := r.executionState.Test.Runner.MakeArchive()
+	if arc == nil {
+		return errors.New("the replay executor can only read the files of the test archive")
+	}
+	filename := fsext.Abs(arc.PwdURL.Path, r.config.File.String)
+	file, err := arc.Filesystems["file"].Open(filename)
+	if err != nil {
+		return fmt.Errorf("couldn't open the file with the records: %w", err)
+	}
+
+	if r.config.getFormat() == replayFormatCSV {
+		r.reader, err = newCSVReplayReader(file)
+		if err != nil {
+			_ = file.Close()
+			return err
+		}
+	} else {
+		r.reader = newJSONLReplayReader(file)
+	}
+	r.file = file
+	return nil
+}
+
+// getRecord returns the record of the given currently running iteration.
+func (r *Replay) getRecord(localIter uint64) (map[string]interface{}, bool) {
+	record, ok := r.inFlightRecords.Load(localIter)
+	if !ok {
+		return nil, false
+	}
+	return record.(*replayRecord).data, true //nolint:forcetypeassert
+}
+
+// readRecords reads the records of the local execution segment and sends
+// them to the returned channel, which is closed after the last one. The
+// global index of every record is its position in the whole file, so every
+// instance reads the whole file, but only keeps the records of its segment.
+func (r *Replay) readRecords(ctx context.Context) (<-chan *replayRecord, <-chan error) {
+	records := make(chan *replayRecord)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(records)
+		defer close(errCh)
+
+		start, offsets, _ := r.executionState.ExecutionTuple.GetStripedOffsets()
+		next, li := start, 0
+		var firstTimestamp float64
+		for gi := int64(0); ; gi++ {
+			data, err := r.reader.read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				errCh <- fmt.Errorf("couldn't read record %d: %w", gi, err)
+				return
+			}
+			// The timestamps of all records are parsed, since the offsets are
+			// from the first one in the file.
+			timestamp, err := parseReplayTimestamp(data[r.config.TimestampField.String])
+			if err != nil {
+				errCh <- fmt.Errorf("invalid timestamp of record %d: %w", gi, err)
+				return
+			}
+			if gi == 0 {
+				firstTimestamp = timestamp
+			}
+			if gi != next {
+				continue
+			}
+
+			record := &replayRecord{
+				localIndex:  uint64(li),
+				globalIndex: uint64(gi),
+				offset:      time.Duration((timestamp - firstTimestamp) / r.config.Speed.Float64),
+				data:        data,
+			}
+			next += offsets[li%len(offsets)]
+			li++
+
+			select {
+			case records <- record:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return records, errCh
+}
+
+// Run replays the records of the local execution segment, until all of them
+// are replayed or the duration is over.
+//
+//nolint:funlen
+func (r *Replay) Run(parentCtx context.Context, out chan<- metrics.SampleContainer) (err error) {
+	defer func() {
+		_ = r.file.Close()
+	}()
+
+	gracefulStop := r.config.GetGracefulStop()
+	duration := r.config.Duration.TimeDuration()
+	preAllocatedVUs := r.config.GetPreAllocatedVUs(r.executionState.ExecutionTuple)
+	maxVUs := r.config.GetMaxVUs(r.executionState.ExecutionTuple)
+
+	r.logger.WithFields(logrus.Fields{
+		"maxVUs": maxVUs, "preAllocatedVUs": preAllocatedVUs, "duration": duration,
+		"file": r.config.File.String, "speed": r.config.Speed.Float64, "type": r.config.GetType(),
+	}).Debug("Starting executor run...")
+
+	activeVUsWg := &sync.WaitGroup{}
+
+	returnedVUs := make(chan struct{})
+	waitOnProgressChannel := make(chan struct{})
+	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(
+		parentCtx, duration, gracefulStop, r.executionState.GracefulStopNotify(),
+	)
+	defer func() {
+		cancel()
+		<-waitOnProgressChannel
+	}()
+
+	// Every VU waits for the records on this channel and runs an iteration
+	// with each one of them.
+	vuRecords := make(chan *replayRecord)
+	vusWg := &sync.WaitGroup{}
+	defer func() {
+		// Make sure all VUs aren't executing iterations anymore, for the cancel()
+		// below to deactivate them.
+		<-returnedVUs
+		close(vuRecords)
+		vusWg.Wait()
+		cancel()
+		activeVUsWg.Wait()
+	}()
+	var activeVUsCount, runningVUsCount, replayedCount uint64
+
+	vusFmt := pb.GetFixedLengthIntFormat(maxVUs)
+	progressFn := func() (float64, []string) {
+		spent := time.Since(startTime)
+		progVUs := fmt.Sprintf(vusFmt+"/"+vusFmt+" VUs",
+			atomic.LoadUint64(&runningVUsCount), atomic.LoadUint64(&activeVUsCount))
+		progRecords := fmt.Sprintf("%d records", atomic.LoadUint64(&replayedCount))
+
+		right := []string{progVUs, duration.String(), progRecords}
+		if spent > duration {
+			return 1, right
+		}
+
+		spentDuration := pb.GetFixedLengthDuration(spent, duration)
+		right[1] = fmt.Sprintf("%s/%s", spentDuration, duration)
+
+		return math.Min(1, float64(spent)/float64(duration)), right
+	}
+	r.progress.Modify(pb.WithProgress(progressFn))
+	maxDurationCtx = lib.WithScenarioState(maxDurationCtx, &lib.ScenarioState{
+		Name:       r.config.Name,
+		Executor:   r.config.Type,
+		StartTime:  startTime,
+		ProgressFn: progressFn,
+		GetRecord:  r.getRecord,
+	})
+
+	go func() {
+		trackProgress(parentCtx, maxDurationCtx, regDurationCtx, r, progressFn)
+		close(waitOnProgressChannel)
+	}()
+
+	returnVU := func(u lib.InitializedVU) {
+		r.executionState.ReturnVU(u, false)
+		activeVUsWg.Done()
+	}
+
+	runIterationBasic := getIterationRunner(r.executionState, r.logger)
+	activateVU := func(initVU lib.InitializedVU) {
+		activeVUsWg.Add(1)
+		// The iteration counters of every iteration are the indexes of its
+		// record, so the records can be looked up by them.
+		var current *replayRecord
+		activeVU := initVU.Activate(getVUActivationParams(
+			maxDurationCtx, r.config.BaseConfig, returnVU,
+			func() (uint64, uint64) { return current.localIndex, current.globalIndex },
+		))
+		atomic.AddUint64(&activeVUsCount, 1)
+
+		vusWg.Add(1)
+		started := make(chan struct{})
+		go func() {
+			defer vusWg.Done()
+			close(started)
+			for current = range vuRecords {
+				atomic.AddUint64(&runningVUsCount, 1)
+				r.executionState.ModCurrentlyActiveVUsCount(+1)
+				r.inFlightRecords.Store(current.localIndex, current)
+				runIterationBasic(maxDurationCtx, activeVU)
+				r.inFlightRecords.Delete(current.localIndex)
+				r.executionState.ModCurrentlyActiveVUsCount(-1)
+				atomic.AddUint64(&runningVUsCount, ^uint64(0))
+			}
+		}()
+		<-started
+	}
+
+	remainingUnplannedVUs := maxVUs - preAllocatedVUs
+	makeUnplannedVUCh := make(chan struct{})
+	defer close(makeUnplannedVUCh)
+	go func() {
+		defer close(returnedVUs)
+		for range makeUnplannedVUCh {
+			r.logger.Debug("Starting initialization of an unplanned VU...")
+			initVU, err := r.executionState.GetUnplannedVU(maxDurationCtx, r.logger)
+			if err != nil {
+				r.logger.WithError(err).Error("Error while allocating unplanned VU")
+			} else {
+				r.logger.Debug("The unplanned VU finished initializing successfully!")
+				activateVU(initVU)
+			}
+		}
+	}()
+
+	// Get the pre-allocated VUs in the local buffer
+	for i := int64(0); i < preAllocatedVUs; i++ {
+		initVU, err := r.executionState.GetPlannedVU(r.logger, false)
+		if err != nil {
+			return err
+		}
+		activateVU(initVU)
+	}
+
+	readCtx, cancelRead := context.WithCancel(regDurationCtx)
+	defer cancelRead()
+	records, readErr := r.readRecords(readCtx)
+
+	timer := time.NewTimer(time.Hour * 24)
+	defer timer.Stop()
+	droppedIterationMetric := r.executionState.Test.BuiltinMetrics.DroppedIterations
+	shownWarning := false
+	metricTags := r.getMetricTags(nil)
+	for record := range records {
+		if record.offset >= duration {
+			r.logger.Debugf("The rest of the records are after the end of the %s duration", duration)
+			return nil
+		}
+
+		timer.Reset(record.offset - time.Since(startTime))
+		select {
+		case <-timer.C:
+		case <-regDurationCtx.Done():
+			return nil
+		}
+
+		select {
+		case vuRecords <- record:
+			atomic.AddUint64(&replayedCount, 1)
+			continue
+		default:
+		}
+
+		// Since there aren't any free VUs available, consider this iteration
+		// dropped - we aren't going to try to recover it, but
+		metrics.PushIfNotDone(parentCtx, out, metrics.Sample{
+			TimeSeries: metrics.TimeSeries{
+				Metric: droppedIterationMetric,
+				Tags:   metricTags,
+			},
+			Time:  time.Now(),
+			Value: 1,
+		})
+
+		// We'll try to start allocating another VU in the background,
+		// non-blockingly, if we have remainingUnplannedVUs...
+		if remainingUnplannedVUs == 0 {
+			if !shownWarning {
+				r.logger.Warningf("Insufficient VUs, reached %d active VUs and cannot initialize more", maxVUs)
+				shownWarning = true
+			}
+			continue
+		}
+
+		select {
+		case makeUnplannedVUCh <- struct{}{}: // great!
+			remainingUnplannedVUs--
+		default: // we're already allocating a new VU
+		}
+	}
+
+	return <-readErr
+}
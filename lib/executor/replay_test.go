@@ -0,0 +1,274 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+func getTestReplayConfig(file string) ReplayConfig {
+	config := NewReplayConfig("replay")
+	config.GracefulStop = types.NullDurationFrom(0)
+	config.File = null.StringFrom(file)
+	config.Duration = types.NullDurationFrom(2 * time.Second)
+	config.PreAllocatedVUs = null.IntFrom(2)
+	config.MaxVUs = null.IntFrom(2)
+	return config
+}
+
+// archiveRunner is a runner with a test archive, from which the replay
+// executor reads its records.
+type archiveRunner struct {
+	lib.Runner
+	archive *lib.Archive
+}
+
+func (r archiveRunner) MakeArchive() *lib.Archive {
+	return r.archive
+}
+
+func getTestReplayRunState(t *testing.T, runner lib.Runner, files map[string]string) *lib.TestRunState {
+	t.Helper()
+	fs := fsext.NewMemMapFs()
+	for name, data := range files {
+		require.NoError(t, fsext.WriteFile(fs, name, []byte(data), 0o644))
+	}
+	return getTestRunState(t, lib.Options{}, archiveRunner{
+		Runner: runner,
+		archive: &lib.Archive{
+			Filesystems: map[string]fsext.Fs{"file": fs},
+			PwdURL:      &url.URL{Scheme: "file", Path: "/test/"},
+		},
+	})
+}
+
+// getTestReplayLog returns a log with the given number of records, 100ms
+// apart, in the JSON lines format.
+func getTestReplayLog(count int) string {
+	var b strings.Builder
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&b, `{"timestamp": %d, "id": "r%d"}`+"\n", 1700000000000+i*100, i)
+	}
+	return b.String()
+}
+
+func TestReplayRun(t *testing.T) {
+	t.Parallel()
+
+	type iteration struct {
+		localIter, globalIter uint64
+		id                    interface{}
+		startedAfter          time.Duration
+	}
+	var mx sync.Mutex
+	var iterations []iteration
+	startTime := time.Now()
+	runner := simpleRunner(func(ctx context.Context, state *lib.State) error {
+		record, ok := lib.GetScenarioState(ctx).GetRecord(state.GetScenarioLocalVUIter())
+		require.True(t, ok)
+		mx.Lock()
+		iterations = append(iterations, iteration{
+			localIter:    state.GetScenarioLocalVUIter(),
+			globalIter:   state.GetScenarioGlobalVUIter(),
+			id:           record["id"],
+			startedAfter: time.Since(startTime),
+		})
+		mx.Unlock()
+		return nil
+	})
+
+	config := getTestReplayConfig("log.jsonl")
+	config.Speed = null.FloatFrom(2)
+	testRunState := getTestReplayRunState(t, runner, map[string]string{"/test/log.jsonl": getTestReplayLog(10)})
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(testRunState, et, 2, 2)
+	ctx, cancel, executor, _ := setupExecutor(t, config, es)
+	defer cancel()
+
+	startTime = time.Now()
+	require.NoError(t, executor.Run(ctx, make(chan metrics.SampleContainer, 100)))
+	assert.Less(t, time.Since(startTime), config.Duration.TimeDuration(), "it should stop after the last record")
+
+	require.Len(t, iterations, 10)
+	sort.Slice(iterations, func(i, j int) bool { return iterations[i].globalIter < iterations[j].globalIter })
+	for i, iter := range iterations {
+		assert.Equal(t, uint64(i), iter.localIter)
+		assert.Equal(t, uint64(i), iter.globalIter)
+		assert.Equal(t, fmt.Sprintf("r%d", i), iter.id)
+		// the records are 100ms apart, replayed at twice the speed
+		assert.InDelta(t, time.Duration(i)*50*time.Millisecond, iter.startedAfter, float64(40*time.Millisecond))
+	}
+}
+
+func TestReplayRecordsSegments(t *testing.T) {
+	t.Parallel()
+
+	log := getTestReplayLog(20)
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error { return nil })
+	seq, err := lib.NewExecutionSegmentSequenceFromString("0,1/4,1/2,1")
+	require.NoError(t, err)
+
+	var ids []string
+	for _, segment := range seq {
+		et, err := lib.NewExecutionTuple(segment, &seq)
+		require.NoError(t, err)
+		testRunState := getTestReplayRunState(t, runner, map[string]string{"/test/log.jsonl": log})
+		es := lib.NewExecutionState(testRunState, et, 2, 2)
+		executor, err := getTestReplayConfig("log.jsonl").NewExecutor(es, testRunState.Logger.WithField("executor", "replay"))
+		require.NoError(t, err)
+		require.NoError(t, executor.Init(context.Background()))
+
+		records, errCh := executor.(*Replay).readRecords(context.Background())
+		var localIndex uint64
+		for record := range records {
+			assert.Equal(t, localIndex, record.localIndex)
+			assert.Equal(t, fmt.Sprintf("r%d", record.globalIndex), record.data["id"])
+			assert.Equal(t, time.Duration(record.globalIndex)*100*time.Millisecond, record.offset)
+			ids = append(ids, record.data["id"].(string)) //nolint:forcetypeassert
+			localIndex++
+		}
+		require.NoError(t, <-errCh)
+	}
+
+	sort.Strings(ids)
+	expected := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		expected = append(expected, fmt.Sprintf("r%d", i))
+	}
+	sort.Strings(expected)
+	assert.Equal(t, expected, ids)
+}
+
+func TestReplayCSVRecords(t *testing.T) {
+	t.Parallel()
+
+	csvLog := "time,path\n" +
+		"2023-11-14T22:13:20Z,/a\n" +
+		"2023-11-14T22:13:20.5Z,/b\n" +
+		"invalid,/c\n"
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error { return nil })
+	testRunState := getTestReplayRunState(t, runner, map[string]string{"/logs/access.csv": csvLog})
+	config := getTestReplayConfig("/logs/access.csv")
+	config.TimestampField = null.StringFrom("time")
+	require.Empty(t, config.Validate())
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(testRunState, et, 2, 2)
+	executor, err := config.NewExecutor(es, testRunState.Logger.WithField("executor", "replay"))
+	require.NoError(t, err)
+	require.NoError(t, executor.Init(context.Background()))
+
+	records, errCh := executor.(*Replay).readRecords(context.Background())
+	var paths []interface{}
+	var offsets []time.Duration
+	for record := range records {
+		paths = append(paths, record.data["path"])
+		offsets = append(offsets, record.offset)
+	}
+	assert.Equal(t, []interface{}{"/a", "/b"}, paths)
+	assert.Equal(t, []time.Duration{0, 500 * time.Millisecond}, offsets)
+	assert.ErrorContains(t, <-errCh, "invalid timestamp of record 2")
+}
+
+func TestReplayInitErrors(t *testing.T) {
+	t.Parallel()
+
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error { return nil })
+	testRunState := getTestReplayRunState(t, runner, map[string]string{"/test/empty.csv": ""})
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(testRunState, et, 2, 2)
+
+	for file, expErr := range map[string]string{
+		"missing.jsonl": "couldn't open the file with the records",
+		"empty.csv":     "couldn't read the header of the CSV file",
+	} {
+		executor, err := getTestReplayConfig(file).NewExecutor(es, testRunState.Logger.WithField("executor", "replay"))
+		require.NoError(t, err)
+		assert.ErrorContains(t, executor.Init(context.Background()), expErr)
+	}
+}
+
+func TestReplayLoadDataFiles(t *testing.T) {
+	t.Parallel()
+
+	base := fsext.NewMemMapFs()
+	require.NoError(t, fsext.WriteFile(base, "/test/log.jsonl", []byte(getTestReplayLog(1)), 0o644))
+	fs := fsext.NewCacheOnReadFs(base, fsext.NewMemMapFs(), 0)
+	// like after the initialization of the first VU
+	fs.(fsext.OnlyCachedEnabler).AllowOnlyCached()
+	filesystems := map[string]fsext.Fs{"file": fs}
+	pwd := &url.URL{Scheme: "file", Path: "/test/"}
+
+	scs := lib.ScenarioConfigs{"replay": getTestReplayConfig("log.jsonl"), "other": NewConstantVUsConfig("other")}
+	require.NoError(t, scs.LoadDataFiles(filesystems, pwd))
+	exists, err := fsext.Exists(fs.(fsext.CacheLayerGetter).GetCachingFs(), "/test/log.jsonl")
+	require.NoError(t, err)
+	assert.True(t, exists, "the file should be in the cache layer, which is archived")
+
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error { return nil })
+	testRunState := getTestRunState(t, lib.Options{}, archiveRunner{
+		Runner:  runner,
+		archive: &lib.Archive{Filesystems: filesystems, PwdURL: pwd},
+	})
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(testRunState, et, 2, 2)
+	executor, err := scs["replay"].NewExecutor(es, testRunState.Logger.WithField("executor", "replay"))
+	require.NoError(t, err)
+	require.NoError(t, executor.Init(context.Background()))
+
+	scs = lib.ScenarioConfigs{"replay": getTestReplayConfig("missing.jsonl")}
+	assert.ErrorContains(t, scs.LoadDataFiles(filesystems, pwd),
+		"couldn't load the data file 'missing.jsonl' of scenario replay")
+}
+
+func TestReplayConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	config := getTestReplayConfig("log.jsonl")
+	assert.Empty(t, config.Validate())
+
+	config.File = null.StringFrom("")
+	config.Format = null.StringFrom("xml")
+	config.Speed = null.FloatFrom(0)
+	errs := config.Validate()
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0].Error(), "the file with the records isn't specified")
+	assert.Contains(t, errs[1].Error(), "unsupported format 'xml'")
+	assert.Contains(t, errs[2].Error(), "the speed must be more than 0")
+}
+
+func TestParseReplayTimestamp(t *testing.T) {
+	t.Parallel()
+
+	for value, expected := range map[interface{}]float64{
+		float64(1500):               1.5e9,
+		"1500":                      1.5e9,
+		"1970-01-01T00:00:01.5Z":    1.5e9,
+		"1970-01-01T01:00:01+01:00": 1e9,
+	} {
+		result, err := parseReplayTimestamp(value)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	}
+
+	_, err := parseReplayTimestamp(true)
+	assert.Error(t, err)
+}
@@ -171,7 +171,9 @@ func (si SharedIterations) Run(parentCtx context.Context, out chan<- metrics.Sam
 	gracefulStop := si.config.GetGracefulStop()
 
 	waitOnProgressChannel := make(chan struct{})
-	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(parentCtx, duration, gracefulStop)
+	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(
+		parentCtx, duration, gracefulStop, si.executionState.GracefulStopNotify(),
+	)
 	defer func() {
 		cancel()
 		<-waitOnProgressChannel
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"sort"
 	"strings"
 	"sync"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/metrics"
 	"go.k6.io/k6/ui/pb"
 )
@@ -65,6 +67,13 @@ type ExecutorConfig interface {
 	GetStartTime() time.Duration
 	GetGracefulStop() time.Duration
 
+	// The names of the scenarios that have to finish before this one starts,
+	// and the conditions on the metrics of the test so far, which have to
+	// pass for it to start at all. The startTime is counted after the
+	// scenarios in GetStartAfter() have finished.
+	GetStartAfter() []string
+	GetStartWhen() map[string][]string
+
 	// This is used to validate whether a particular script can run in the cloud
 	// or, in the future, in the native k6 distributed execution. Currently only
 	// the externally-controlled executor should return false.
@@ -105,6 +114,11 @@ type ScenarioState struct {
 	Name, Executor string
 	StartTime      time.Time
 	ProgressFn     func() (float64, []string)
+
+	// GetRecord returns the record of the given iteration of the scenario in
+	// the local instance, for the executors that start an iteration for
+	// every record of a data file, e.g. the replay one. It's nil otherwise.
+	GetRecord func(localIter uint64) (map[string]interface{}, bool)
 }
 
 // InitVUFunc is just a shorthand so we don't have to type the function
@@ -157,6 +171,12 @@ func RegisterExecutorConfigType(configType string, constructor ExecutorConfigCon
 	executorConfigConstructors[configType] = constructor
 }
 
+// DataFilesConfig is an optional interface for the executor configs that read
+// data files during the test run, e.g. the access log of the replay executor.
+type DataFilesConfig interface {
+	GetDataFiles() []string
+}
+
 // ScenarioConfigs can contain mixed executor config types
 type ScenarioConfigs map[string]ExecutorConfig
 
@@ -203,19 +223,233 @@ func (scs ScenarioConfigs) Validate() (errors []error) {
 				fmt.Errorf("scenario %s has configuration errors: %s", name, ConcatErrors(execErr, ", ")))
 		}
 	}
+	return append(errors, scs.validateStartAfter()...)
+}
+
+// LoadDataFiles loads the data files of the scenarios in the "file" file
+// system of the test, the same way as the files opened in the init context, so
+// the executors can read them from it and they are included in the test
+// archive. The file systems of an archived test already contain them.
+func (scs ScenarioConfigs) LoadDataFiles(filesystems map[string]fsext.Fs, pwd *url.URL) error {
+	fileCacher, ok := filesystems["file"].(fsext.FileCacher)
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(scs))
+	for name := range scs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		config, ok := scs[name].(DataFilesConfig)
+		if !ok {
+			continue
+		}
+		for _, filename := range config.GetDataFiles() {
+			if err := fileCacher.CacheFile(fsext.Abs(pwd.Path, filename)); err != nil {
+				return fmt.Errorf("couldn't load the data file '%s' of scenario %s: %w", filename, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateStartAfter checks that the scenarios in startAfter exist and that
+// there aren't any circular dependencies between them.
+func (scs ScenarioConfigs) validateStartAfter() (errors []error) {
+	names := make([]string, 0, len(scs))
+	for name := range scs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, dependency := range scs[name].GetStartAfter() {
+			if _, ok := scs[dependency]; !ok {
+				errors = append(errors, fmt.Errorf(
+					"scenario %s should start after scenario %s, which doesn't exist", name, dependency))
+			}
+		}
+	}
+
+	// A depth-first search, the scenarios that are being visited are
+	// false in the map and the already visited ones are true.
+	visited := make(map[string]bool, len(scs))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		done, seen := visited[name]
+		if done {
+			return nil
+		}
+		path = append(path, name)
+		if seen {
+			return fmt.Errorf("scenarios can't start after each other: %s", strings.Join(path, " -> "))
+		}
+		visited[name] = false
+		if config, ok := scs[name]; ok {
+			for _, dependency := range config.GetStartAfter() {
+				if err := visit(dependency, path); err != nil {
+					return err
+				}
+			}
+		}
+		visited[name] = true
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return append(errors, err)
+		}
+	}
 	return errors
 }
 
+// GetPlannedStartTimes returns the time offsets, from the beginning of the
+// test, at which the scenarios are planned to start. For the scenarios with
+// startAfter, it's the latest planned end of the scenarios they start after,
+// including their graceful stops, plus their own startTime.
+//
+// The scenarios actually start as soon as the ones they start after have
+// finished, which may be earlier, e.g. when an iterations-based scenario is
+// done before its maxDuration. GetFullExecutionRequirements() takes this into
+// account and reserves the VUs of such scenarios for the whole period during
+// which they might run.
+func (scs ScenarioConfigs) GetPlannedStartTimes(et *ExecutionTuple) map[string]time.Duration {
+	result := make(map[string]time.Duration, len(scs))
+	inProgress := make(map[string]bool, len(scs))
+	var getStartTime func(name string) time.Duration
+	getStartTime = func(name string) time.Duration {
+		if startTime, ok := result[name]; ok {
+			return startTime
+		}
+		config, ok := scs[name]
+		if !ok || inProgress[name] { // invalid configs, they are reported by Validate()
+			return 0
+		}
+		inProgress[name] = true
+		var dependenciesEnd time.Duration
+		for _, dependency := range config.GetStartAfter() {
+			if _, ok := scs[dependency]; !ok {
+				continue
+			}
+			end, _ := GetEndOffset(scs[dependency].GetExecutionRequirements(et))
+			if end += getStartTime(dependency); end > dependenciesEnd {
+				dependenciesEnd = end
+			}
+		}
+		result[name] = dependenciesEnd + config.GetStartTime()
+		return result[name]
+	}
+
+	for name := range scs {
+		getStartTime(name)
+	}
+	return result
+}
+
+// getEarliestStartTimes returns the earliest time offsets, from the beginning
+// of the test, at which the scenarios can start, i.e. when all of the scenarios
+// they start after finish right away.
+func (scs ScenarioConfigs) getEarliestStartTimes(et *ExecutionTuple) map[string]time.Duration {
+	result := make(map[string]time.Duration, len(scs))
+	inProgress := make(map[string]bool, len(scs))
+	var getStartTime func(name string) time.Duration
+	getStartTime = func(name string) time.Duration {
+		if startTime, ok := result[name]; ok {
+			return startTime
+		}
+		config, ok := scs[name]
+		if !ok || inProgress[name] { // invalid configs, they are reported by Validate()
+			return 0
+		}
+		inProgress[name] = true
+		var dependenciesStart time.Duration
+		for _, dependency := range config.GetStartAfter() {
+			// the scenarios without work are finished before the test starts
+			if _, ok := scs[dependency]; !ok || !scs[dependency].HasWork(et) {
+				continue
+			}
+			if start := getStartTime(dependency); start > dependenciesStart {
+				dependenciesStart = start
+			}
+		}
+		result[name] = dependenciesStart + config.GetStartTime()
+		return result[name]
+	}
+
+	for name := range scs {
+		getStartTime(name)
+	}
+	return result
+}
+
+// getStartAfterChains splits the given configs into chains of scenarios that
+// start after each other, directly or indirectly. The scenarios in a chain
+// never run at the same time. It returns the index of the chain of each config
+// and the number of chains.
+func (scs ScenarioConfigs) getStartAfterChains(configs []ExecutorConfig) ([]int, int) {
+	// all of the scenarios each scenario starts after, directly or indirectly
+	dependencies := make(map[string]map[string]bool, len(scs))
+	var getDependencies func(name string) map[string]bool
+	getDependencies = func(name string) map[string]bool {
+		if result, ok := dependencies[name]; ok {
+			return result
+		}
+		result := make(map[string]bool)
+		dependencies[name] = result // also guards against invalid circular configs
+		if config, ok := scs[name]; ok {
+			for _, dependency := range config.GetStartAfter() {
+				result[dependency] = true
+				for indirect := range getDependencies(dependency) {
+					result[indirect] = true
+				}
+			}
+		}
+		return result
+	}
+
+	chainIDs := make([]int, len(configs))
+	chains := [][]string{}
+	fitsInChain := func(name string, chain []string) bool {
+		for _, other := range chain {
+			if !getDependencies(name)[other] && !getDependencies(other)[name] {
+				return false
+			}
+		}
+		return true
+	}
+	for configID, config := range configs {
+		name := config.GetName()
+		chainIDs[configID] = len(chains)
+		for chainID, chain := range chains {
+			if fitsInChain(name, chain) {
+				chainIDs[configID] = chainID
+				break
+			}
+		}
+		if chainIDs[configID] == len(chains) {
+			chains = append(chains, nil)
+		}
+		chains[chainIDs[configID]] = append(chains[chainIDs[configID]], name)
+	}
+	return chainIDs, len(chains)
+}
+
 // GetSortedConfigs returns a slice with the executor configurations,
 // sorted in a consistent and predictable manner. It is useful when we want or
 // have to avoid using maps with string keys (and tons of string lookups in
 // them) and avoid the unpredictable iterations over Go maps. Slices allow us
 // constant-time lookups and ordered iterations.
 //
-// The configs in the returned slice will be sorted by their start times in an
-// ascending order, and alphabetically by their names (which are unique) if
-// there are ties.
+// The configs in the returned slice will be sorted by their planned start
+// times in an ascending order, and alphabetically by their names (which are
+// unique) if there are ties.
 func (scs ScenarioConfigs) GetSortedConfigs() []ExecutorConfig {
+	// The planned ends of the scenarios don't depend on the execution segment.
+	et, _ := NewExecutionTuple(nil, nil)
+	startTimes := scs.GetPlannedStartTimes(et)
 	configs := make([]ExecutorConfig, len(scs))
 
 	// Populate the configs slice with sorted executor configs
@@ -226,9 +460,9 @@ func (scs ScenarioConfigs) GetSortedConfigs() []ExecutorConfig {
 	}
 	sort.Slice(configs, func(a, b int) bool { // sort by (start time, name)
 		switch {
-		case configs[a].GetStartTime() < configs[b].GetStartTime():
+		case startTimes[configs[a].GetName()] < startTimes[configs[b].GetName()]:
 			return true
-		case configs[a].GetStartTime() == configs[b].GetStartTime():
+		case startTimes[configs[a].GetName()] == startTimes[configs[b].GetName()]:
 			return strings.Compare(configs[a].GetName(), configs[b].GetName()) < 0
 		default:
 			return false
@@ -242,20 +476,32 @@ func (scs ScenarioConfigs) GetSortedConfigs() []ExecutorConfig {
 // the configured executors. It takes into account their start times and their
 // individual VU requirements and calculates the total VU requirements for each
 // moment in the test execution.
+//
+// The scenarios with startAfter may start earlier than planned, so their
+// maximum VU requirements are reserved from their earliest possible start
+// until their planned end. The scenarios that start after each other never
+// run at the same time, so only the biggest of their requirements is counted.
 func (scs ScenarioConfigs) GetFullExecutionRequirements(et *ExecutionTuple) []ExecutionStep {
 	sortedConfigs := scs.GetSortedConfigs()
+	startTimes := scs.GetPlannedStartTimes(et)
+	earliestStartTimes := scs.getEarliestStartTimes(et)
+	chainIDs, chainsCount := scs.getStartAfterChains(sortedConfigs)
 
 	// Combine the steps and requirements from all different executors, and
-	// sort them by their time offset, counting the executors' startTimes as
-	// well.
+	// sort them by their time offset, counting the executors' planned start
+	// times as well.
 	type trackedStep struct {
 		ExecutionStep
 		configID int
 	}
 	trackedSteps := []trackedStep{}
 	for configID, config := range sortedConfigs { // orderly iteration over a slice
-		configStartTime := config.GetStartTime()
+		configStartTime := startTimes[config.GetName()]
 		configSteps := config.GetExecutionRequirements(et)
+		if earliestStartTime := earliestStartTimes[config.GetName()]; earliestStartTime < configStartTime {
+			configSteps = reserveExecutionSteps(configSteps, configStartTime-earliestStartTime)
+			configStartTime = earliestStartTime
+		}
 		for _, cs := range configSteps {
 			cs.TimeOffset += configStartTime // add the executor start time to the step time offset
 			trackedSteps = append(trackedSteps, trackedStep{cs, configID})
@@ -280,16 +526,26 @@ func (scs ScenarioConfigs) GetFullExecutionRequirements(et *ExecutionTuple) []Ex
 	currentTimeOffset := time.Duration(0)
 	currentPlannedVUs := make([]uint64, len(scs))
 	currentMaxUnplannedVUs := make([]uint64, len(scs))
-	sum := func(data []uint64) (result uint64) { // sigh...
-		for _, val := range data {
+	sumChains := func(getValue func(configID int) uint64) (result uint64) {
+		chainValues := make([]uint64, chainsCount)
+		for configID, chainID := range chainIDs {
+			if val := getValue(configID); val > chainValues[chainID] {
+				chainValues[chainID] = val
+			}
+		}
+		for _, val := range chainValues {
 			result += val
 		}
 		return result
 	}
 	consolidatedSteps := []ExecutionStep{}
 	addCurrentStepIfDifferent := func() {
-		newPlannedVUs := sum(currentPlannedVUs)
-		newMaxUnplannedVUs := sum(currentMaxUnplannedVUs)
+		newPlannedVUs := sumChains(func(configID int) uint64 {
+			return currentPlannedVUs[configID]
+		})
+		newMaxUnplannedVUs := sumChains(func(configID int) uint64 {
+			return currentPlannedVUs[configID] + currentMaxUnplannedVUs[configID]
+		}) - newPlannedVUs
 		stepsLen := len(consolidatedSteps)
 		if stepsLen == 0 ||
 			consolidatedSteps[stepsLen-1].PlannedVUs != newPlannedVUs ||
@@ -313,6 +569,24 @@ func (scs ScenarioConfigs) GetFullExecutionRequirements(et *ExecutionTuple) []Ex
 	return consolidatedSteps
 }
 
+// reserveExecutionSteps replaces the given execution steps with a single step
+// that reserves their maximum VU requirements, for their whole duration plus
+// the given delay, with which they might actually start.
+func reserveExecutionSteps(steps []ExecutionStep, delay time.Duration) []ExecutionStep {
+	if len(steps) == 0 {
+		return steps
+	}
+	maxPlannedVUs := GetMaxPlannedVUs(steps)
+	result := []ExecutionStep{{
+		PlannedVUs:      maxPlannedVUs,
+		MaxUnplannedVUs: GetMaxPossibleVUs(steps) - maxPlannedVUs,
+	}}
+	if endOffset, isFinal := GetEndOffset(steps); isFinal {
+		result = append(result, ExecutionStep{TimeOffset: endOffset + delay})
+	}
+	return result
+}
+
 // GetParsedExecutorConfig returns a struct instance corresponding to the supplied
 // config type. It will be fully initialized - with both the default values of
 // the type, as well as with whatever the user had specified in the JSON
@@ -11,6 +11,9 @@ import (
 // Fs represents a file system
 type Fs = afero.Fs
 
+// File represents a file in a file system
+type File = afero.File
+
 // FilePathSeparator is the FilePathSeparator to be used within a file system
 const FilePathSeparator = afero.FilePathSeparator
 
@@ -44,6 +47,17 @@ func NewOsFs() Fs {
 	return afero.NewOsFs()
 }
 
+// NewBasePathFs returns a Fs restricting all the operations to the given
+// directory of the provided fs, and the paths to be relative to it.
+func NewBasePathFs(fs Fs, path string) Fs {
+	return afero.NewBasePathFs(fs, path)
+}
+
+// Glob returns the names of all the files of the provided fs matching the pattern
+func Glob(fs Fs, pattern string) ([]string, error) {
+	return afero.Glob(fs, pattern)
+}
+
 // Exists checks if the provided path exists on the filesystem
 func Exists(fs Fs, path string) (bool, error) {
 	return afero.Exists(fs, path)
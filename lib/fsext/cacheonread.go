@@ -16,11 +16,13 @@ var ErrPathNeverRequestedBefore = errors.New("path never requested before")
 // that is used as cache
 type CacheOnReadFs struct {
 	afero.Fs
+	base  afero.Fs
 	cache afero.Fs
 
 	lock       *sync.Mutex
 	cachedOnly bool
 	cached     map[string]bool
+	uncached   map[string]bool
 }
 
 // OnlyCachedEnabler enables the mode of FS that allows to open
@@ -34,15 +36,30 @@ type CacheLayerGetter interface {
 	GetCachingFs() afero.Fs
 }
 
+// FileCacher caches files, even in the cached only mode of the FS
+type FileCacher interface {
+	CacheFile(path string) error
+}
+
+// UncachedOpener opens files directly from the base FS, without copying them to the
+// cache layer, e.g. large files that are read on demand. They are only copied to the
+// cache layer by CacheUncached, when an archive is made.
+type UncachedOpener interface {
+	OpenUncached(path string) (afero.File, error)
+	CacheUncached() error
+}
+
 // NewCacheOnReadFs returns a new CacheOnReadFs
 func NewCacheOnReadFs(base, layer afero.Fs, cacheTime time.Duration) afero.Fs {
 	return &CacheOnReadFs{
 		Fs:    afero.NewCacheOnReadFs(base, layer, cacheTime),
+		base:  base,
 		cache: layer,
 
 		lock:       &sync.Mutex{},
 		cachedOnly: false,
 		cached:     make(map[string]bool),
+		uncached:   make(map[string]bool),
 	}
 }
 
@@ -58,6 +75,60 @@ func (c *CacheOnReadFs) AllowOnlyCached() {
 	c.lock.Unlock()
 }
 
+// CacheFile copies the file to the cache layer and allows it to be opened in
+// the cached only mode, even if it wasn't opened before
+func (c *CacheOnReadFs) CacheFile(path string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// the underlying afero.CacheOnReadFs copies the file to the layer on open
+	f, err := c.Fs.Open(path)
+	if err != nil {
+		return err
+	}
+	c.cached[path] = true
+
+	return f.Close()
+}
+
+// OpenUncached opens the file from the base FS, without copying it to the cache layer,
+// and track the history of opened files like Open
+func (c *CacheOnReadFs) OpenUncached(path string) (afero.File, error) {
+	if err := c.checkOrRemember(path); err != nil {
+		return nil, err
+	}
+
+	f, err := c.base.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	c.uncached[path] = true
+	c.lock.Unlock()
+
+	return f, nil
+}
+
+// CacheUncached copies the files opened with OpenUncached to the cache layer
+func (c *CacheOnReadFs) CacheUncached() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for path := range c.uncached {
+		f, err := c.Fs.Open(path)
+		if err != nil {
+			return err
+		}
+		if err = f.Close(); err != nil {
+			return err
+		}
+		delete(c.uncached, path)
+	}
+
+	return nil
+}
+
 // Open opens file and track the history of opened files
 // if CacheOnReadFs is in the opened only mode it should return
 // an error if file wasn't open before
@@ -0,0 +1,80 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go.k6.io/k6/metrics"
+)
+
+// MetricCondition is the parsed condition for a single metric, in the same
+// format as the thresholds. Unlike the thresholds, the conditions are evaluated
+// over the metric samples of a specific period, e.g. of an adaptive
+// arrival-rate step, or until a scenario with startWhen conditions starts.
+type MetricCondition struct {
+	MetricName string
+
+	thresholds metrics.Thresholds
+	read       func() metrics.Sink
+}
+
+// ParseMetricConditions parses the conditions for each metric, sorted by the
+// name of the metric. The conditions can't have a time window.
+func ParseMetricConditions(conditions map[string][]string) ([]*MetricCondition, error) {
+	metricNames := make([]string, 0, len(conditions))
+	for metricName := range conditions {
+		metricNames = append(metricNames, metricName)
+	}
+	sort.Strings(metricNames)
+
+	result := make([]*MetricCondition, 0, len(metricNames))
+	for _, metricName := range metricNames {
+		thresholds := metrics.NewThresholds(conditions[metricName])
+		if err := thresholds.Parse(); err != nil {
+			return nil, fmt.Errorf("invalid condition on metric '%s': %w", metricName, err)
+		}
+		for _, th := range thresholds.Thresholds {
+			if th.IsWindowed() {
+				return nil, fmt.Errorf(
+					"invalid condition '%s' on metric '%s': the conditions are evaluated over a specific period, "+
+						"so they can't have a time window", th.Source, metricName,
+				)
+			}
+		}
+		result = append(result, &MetricCondition{MetricName: metricName, thresholds: thresholds})
+	}
+	return result, nil
+}
+
+// InitMetricConditions parses the given conditions, validates them against the
+// metrics in the registry and starts observing the samples of their metrics.
+func InitMetricConditions(
+	conditions map[string][]string, registry *metrics.Registry, liveMetrics LiveMetricsSource,
+) ([]*MetricCondition, error) {
+	result, err := ParseMetricConditions(conditions)
+	if err != nil {
+		return nil, err
+	}
+	for _, cond := range result {
+		if err = cond.thresholds.Validate(cond.MetricName, registry); err != nil {
+			return nil, fmt.Errorf("invalid condition on metric '%s': %w", cond.MetricName, err)
+		}
+		if cond.read, err = liveMetrics.ObserveMetric(cond.MetricName); err != nil {
+			return nil, fmt.Errorf("invalid condition on metric '%s': %w", cond.MetricName, err)
+		}
+	}
+	return result, nil
+}
+
+// Evaluate checks the condition over the samples of its metric since the
+// previous call of Evaluate() or Reset(), which were collected for the given
+// duration.
+func (mc *MetricCondition) Evaluate(duration time.Duration) (bool, error) {
+	return mc.thresholds.Run(mc.read(), duration)
+}
+
+// Reset discards the samples of the metric that were collected so far.
+func (mc *MetricCondition) Reset() {
+	mc.read()
+}
@@ -0,0 +1,99 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/metrics"
+)
+
+func TestParseMetricConditions(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		conditions map[string][]string
+		metrics    []string
+		err        string
+	}{
+		"empty": {},
+		"sorted": {
+			conditions: map[string][]string{"vus": {"value<10"}, "http_reqs": {"count>0", "rate>1"}},
+			metrics:    []string{"http_reqs", "vus"},
+		},
+		"invalid expression": {
+			conditions: map[string][]string{"http_reqs": {"count>"}},
+			err:        "invalid condition on metric 'http_reqs'",
+		},
+		"windowed": {
+			conditions: map[string][]string{"http_req_duration": {"p(95)<500 over 10s"}},
+			err: "invalid condition 'p(95)<500 over 10s' on metric 'http_req_duration': " +
+				"the conditions are evaluated over a specific period, so they can't have a time window",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			conditions, err := ParseMetricConditions(tc.conditions)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			var metricNames []string
+			for _, cond := range conditions {
+				metricNames = append(metricNames, cond.MetricName)
+			}
+			assert.Equal(t, tc.metrics, metricNames)
+		})
+	}
+}
+
+type testLiveMetrics struct {
+	sinks map[string]metrics.Sink
+}
+
+func (tlm testLiveMetrics) ObserveMetric(name string) (func() metrics.Sink, error) {
+	sink, ok := tlm.sinks[name]
+	if !ok {
+		return nil, errors.New("unknown metric")
+	}
+	return func() metrics.Sink {
+		result := sink
+		sink = metrics.NewSink(metrics.Counter)
+		return result
+	}, nil
+}
+
+func TestInitMetricConditions(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	_, err := registry.NewMetric("iters", metrics.Counter)
+	require.NoError(t, err)
+	sink := metrics.NewSink(metrics.Counter)
+	sink.Add(metrics.Sample{Value: 5})
+	liveMetrics := testLiveMetrics{sinks: map[string]metrics.Sink{"iters": sink}}
+
+	_, err = InitMetricConditions(map[string][]string{"iters": {"p(95)<1"}}, registry, liveMetrics)
+	require.ErrorContains(t, err, "invalid condition on metric 'iters'")
+	_, err = InitMetricConditions(map[string][]string{"missing": {"count>1"}}, registry, liveMetrics)
+	require.ErrorContains(t, err, "invalid condition on metric 'missing'")
+
+	conditions, err := InitMetricConditions(map[string][]string{"iters": {"count>=5"}}, registry, liveMetrics)
+	require.NoError(t, err)
+	require.Len(t, conditions, 1)
+	passed, err := conditions[0].Evaluate(time.Second)
+	require.NoError(t, err)
+	assert.True(t, passed)
+
+	// only the samples since the previous evaluation are used
+	passed, err = conditions[0].Evaluate(time.Second)
+	require.NoError(t, err)
+	assert.False(t, passed)
+}
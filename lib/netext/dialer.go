@@ -69,6 +69,31 @@ func (d *Dialer) DialContext(ctx context.Context, proto, addr string) (net.Conn,
 	return conn, err
 }
 
+// ListenPacket resolves addr in the same way DialContext() does, including
+// the blocked hostnames, hosts overrides and blacklisted IPs, and opens a UDP
+// socket for sending datagrams to it, e.g. for QUIC. The local IP of the
+// socket is the one from the embedded net.Dialer's LocalAddr, if it's set.
+func (d *Dialer) ListenPacket(ctx context.Context, addr string) (net.PacketConn, *net.UDPAddr, error) {
+	dialAddr, err := d.getDialAddr(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	remoteAddr, err := net.ResolveUDPAddr("udp", dialAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	localAddr := &net.UDPAddr{}
+	if tcpAddr, ok := d.Dialer.LocalAddr.(*net.TCPAddr); ok {
+		localAddr.IP = tcpAddr.IP
+	}
+	var lc net.ListenConfig
+	conn, err := lc.ListenPacket(ctx, "udp", localAddr.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	return &PacketConn{conn, &d.BytesRead, &d.BytesWritten}, remoteAddr, nil
+}
+
 // GetTrail creates a new NetTrail instance with the Dialer
 // sent and received data metrics and the supplied times and tags.
 // TODO: Refactor this according to
@@ -139,15 +164,23 @@ func (d *Dialer) getDialAddr(addr string) (string, error) {
 		return "", err
 	}
 
-	for _, ipnet := range d.Blacklist {
-		if ipnet.Contains(remote.IP) {
-			return "", BlackListedIPError{ip: remote.IP, net: ipnet}
-		}
+	if ipnet := d.blacklistedNet(remote.IP); ipnet != nil {
+		return "", BlackListedIPError{ip: remote.IP, net: ipnet}
 	}
 
 	return remote.String(), nil
 }
 
+// blacklistedNet returns the blacklisted range that contains the IP, if any.
+func (d *Dialer) blacklistedNet(ip net.IP) *lib.IPNet {
+	for _, ipnet := range d.Blacklist {
+		if ipnet.Contains(ip) {
+			return ipnet
+		}
+	}
+	return nil
+}
+
 func (d *Dialer) findRemote(addr string) (*types.Host, error) {
 	host, port, err := net.SplitHostPort(addr)
 	if err != nil {
@@ -184,6 +217,57 @@ func (d *Dialer) findRemote(addr string) (*types.Host, error) {
 	return types.NewHost(ip, port)
 }
 
+// ResolveAll returns the addresses of all the IPs the host of addr resolves
+// to, in the same way DialContext() resolves it, but without selecting a
+// single IP. The blacklisted IPs are skipped, unless all of them are. It's
+// used for the client-side load balancing, e.g. of the gRPC connections.
+func (d *Dialer) ResolveAll(addr string) ([]string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	multi, ok := d.Resolver.(allIPsResolver)
+	configured := d.Hosts != nil && (d.Hosts.Match(addr) != nil || d.Hosts.Match(host) != nil)
+	if !ok || configured || net.ParseIP(host) != nil {
+		// There is a single IP, so it's resolved in the same way as for dialing.
+		dialAddr, e := d.getDialAddr(addr)
+		if e != nil {
+			return nil, e
+		}
+		return []string{dialAddr}, nil
+	}
+
+	if d.BlockedHostnames != nil {
+		if match, blocked := d.BlockedHostnames.Contains(host); blocked {
+			return nil, BlockedHostError{hostname: host, match: match}
+		}
+	}
+
+	ips, err := multi.LookupIPAll(host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("lookup %s: no such host", host)
+	}
+
+	addrs := make([]string, 0, len(ips))
+	var blacklistErr error
+	for _, ip := range ips {
+		if ipnet := d.blacklistedNet(ip); ipnet != nil {
+			blacklistErr = BlackListedIPError{ip: ip, net: ipnet}
+			continue
+		}
+		addrs = append(addrs, net.JoinHostPort(ip.String(), port))
+	}
+	if len(addrs) == 0 {
+		return nil, blacklistErr
+	}
+
+	return addrs, nil
+}
+
 func (d *Dialer) getConfiguredHost(addr, host, port string) (*types.Host, error) {
 	if remote := d.Hosts.Match(addr); remote != nil {
 		return remote, nil
@@ -260,3 +344,44 @@ func (c *Conn) Write(b []byte) (int, error) {
 	}
 	return n, err
 }
+
+// PacketConn wraps net.PacketConn and keeps track of sent and received data size
+type PacketConn struct {
+	net.PacketConn
+
+	BytesRead, BytesWritten *int64
+}
+
+func (c *PacketConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, addr, err := c.PacketConn.ReadFrom(b)
+	if n > 0 {
+		atomic.AddInt64(c.BytesRead, int64(n))
+	}
+	return n, addr, err
+}
+
+func (c *PacketConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	n, err := c.PacketConn.WriteTo(b, addr)
+	if n > 0 {
+		atomic.AddInt64(c.BytesWritten, int64(n))
+	}
+	return n, err
+}
+
+// SetReadBuffer sets the size of the receive buffer of the underlying socket,
+// if it supports it. QUIC implementations usually want a bigger one.
+func (c *PacketConn) SetReadBuffer(bytes int) error {
+	if conn, ok := c.PacketConn.(interface{ SetReadBuffer(int) error }); ok {
+		return conn.SetReadBuffer(bytes)
+	}
+	return nil
+}
+
+// SetWriteBuffer sets the size of the send buffer of the underlying socket,
+// if it supports it.
+func (c *PacketConn) SetWriteBuffer(bytes int) error {
+	if conn, ok := c.PacketConn.(interface{ SetWriteBuffer(int) error }); ok {
+		return conn.SetWriteBuffer(bytes)
+	}
+	return nil
+}
@@ -113,6 +113,61 @@ func TestDialerAddrBlockHostnamesStar(t *testing.T) {
 	}
 }
 
+func TestDialerResolveAll(t *testing.T) {
+	t.Parallel()
+	resolver := mockresolver.New(map[string][]net.IP{
+		"example-resolver.com":         {net.ParseIP("1.2.3.4"), net.ParseIP("1.2.3.5"), net.ParseIP("2001:db8::10")},
+		"example-partly-deny.com":      {net.ParseIP("8.9.10.11"), net.ParseIP("1.2.3.6")},
+		"example-deny-resolver.com":    {net.ParseIP("8.9.10.11"), net.ParseIP("8.9.10.12")},
+		"example-blocked.resolver.com": {net.ParseIP("1.2.3.7")},
+	})
+	dialer := NewDialer(net.Dialer{}, NewResolver(resolver.LookupIPAll, 0, types.DNSfirst, types.DNSany))
+	hosts, err := types.NewHosts(map[string]types.Host{
+		"example.com":     {IP: net.ParseIP("3.4.5.6")},
+		"example.com:443": {IP: net.ParseIP("3.4.5.6"), Port: 8443},
+	})
+	require.NoError(t, err)
+	dialer.Hosts = hosts
+	ipNet, err := lib.ParseCIDR("8.9.10.0/24")
+	require.NoError(t, err)
+	dialer.Blacklist = []*lib.IPNet{ipNet}
+	blocked, err := types.NewHostnameTrie([]string{"*.resolver.com"})
+	require.NoError(t, err)
+	dialer.BlockedHostnames = blocked
+
+	testCases := []struct {
+		address      string
+		expAddresses []string
+		expErr       string
+	}{
+		{"example-resolver.com:80", []string{"1.2.3.4:80", "1.2.3.5:80", "[2001:db8::10]:80"}, ""},
+		{"example-partly-deny.com:80", []string{"1.2.3.6:80"}, ""},
+		{"example.com:80", []string{"3.4.5.6:80"}, ""},
+		{"example.com:443", []string{"3.4.5.6:8443"}, ""},
+		{"1.2.3.4:80", []string{"1.2.3.4:80"}, ""},
+		{"example-resolver.com", nil, "address example-resolver.com: missing port in address"},
+		{"example-deny-resolver.com:80", nil, "IP (8.9.10.12) is in a blacklisted range (8.9.10.0/24)"},
+		{"example-blocked.resolver.com:80", nil, "hostname (example-blocked.resolver.com) is in a blocked pattern (*.resolver.com)"},
+		{"no-such-host.com:80", nil, "lookup no-such-host.com: no such host"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.address, func(t *testing.T) {
+			t.Parallel()
+			addrs, err := dialer.ResolveAll(tc.address)
+
+			if tc.expErr != "" {
+				require.EqualError(t, err, tc.expErr)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.expAddresses, addrs)
+			}
+		})
+	}
+}
+
 // Benchmarks /etc/hosts like hostname mapping
 func BenchmarkDialerHosts(b *testing.B) {
 	hosts, err := types.NewHosts(map[string]types.Host{
@@ -0,0 +1,202 @@
+package grpcext
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
+)
+
+// LoadBalancingPolicy is the policy with which the requests of a connection
+// are balanced across the backends, i.e. all the addresses its host resolves
+// to.
+type LoadBalancingPolicy string
+
+const (
+	// LoadBalancingPickFirst sends all the requests to the first backend that
+	// can be connected to.
+	LoadBalancingPickFirst LoadBalancingPolicy = "pick_first"
+	// LoadBalancingRoundRobin sends every request to the next backend.
+	LoadBalancingRoundRobin LoadBalancingPolicy = "round_robin"
+)
+
+// resolverScheme is the scheme of the targets, the backends of which are
+// resolved by k6.
+const resolverScheme = "k6"
+
+// healthCheckInterval is how often the health of a backend is checked, until
+// it's serving.
+const healthCheckInterval = 100 * time.Millisecond
+
+const healthCheckMethod = "/grpc.health.v1.Health/Check"
+
+// allAddrsResolver is implemented by the dialers that can return all the
+// addresses a host resolves to, like netext.Dialer.
+type allAddrsResolver interface {
+	ResolveAll(addr string) ([]string, error)
+}
+
+// resolveBackends returns the addresses of the backends of addr, resolved by
+// the dialer of the VU, so its resolver, hosts and blocked hostnames are used.
+func resolveBackends(getState func() *lib.State, addr string) ([]string, error) {
+	if r, ok := getState().Dialer.(allAddrsResolver); ok {
+		return r.ResolveAll(addr)
+	}
+	return []string{addr}, nil
+}
+
+// DialBalanced establishes a gRPC connection, the requests of which are
+// balanced with the policy across all the addresses the host of addr resolves
+// to. The addresses are resolved again when any of the connections to them is
+// lost.
+func DialBalanced(
+	ctx context.Context, addr string, getState func() *lib.State, policy LoadBalancingPolicy,
+	options ...grpc.DialOption,
+) (*Conn, error) {
+	opts := make([]grpc.DialOption, 0, len(options)+2)
+	opts = append(opts, options...)
+	opts = append(opts,
+		grpc.WithResolvers(resolverBuilder{getState: getState}),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig":[{%q:{}}]}`, policy)),
+	)
+	return Dial(ctx, resolverScheme+":///"+addr, opts...)
+}
+
+// resolverBuilder builds the resolvers of the connections established with
+// DialBalanced().
+type resolverBuilder struct {
+	getState func() *lib.State
+}
+
+// Build implements the resolver.Builder interface. It resolves the backends
+// right away, so any errors are returned by the dialing.
+func (b resolverBuilder) Build(
+	target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions,
+) (resolver.Resolver, error) {
+	r := &backendsResolver{getState: b.getState, addr: target.Endpoint(), cc: cc}
+	state, err := r.resolve()
+	if err != nil {
+		return nil, err
+	}
+	_ = cc.UpdateState(state)
+	return r, nil
+}
+
+// Scheme implements the resolver.Builder interface.
+func (resolverBuilder) Scheme() string {
+	return resolverScheme
+}
+
+// backendsResolver resolves the backends of a connection with the dialer of
+// the VU.
+type backendsResolver struct {
+	getState func() *lib.State
+	addr     string
+	cc       resolver.ClientConn
+}
+
+func (r *backendsResolver) resolve() (resolver.State, error) {
+	backends, err := resolveBackends(r.getState, r.addr)
+	if err != nil {
+		return resolver.State{}, err
+	}
+	addrs := make([]resolver.Address, 0, len(backends))
+	for _, backend := range backends {
+		addrs = append(addrs, resolver.Address{Addr: backend})
+	}
+	return resolver.State{Addresses: addrs}, nil
+}
+
+// ResolveNow implements the resolver.Resolver interface.
+func (r *backendsResolver) ResolveNow(resolver.ResolveNowOptions) {
+	state, err := r.resolve()
+	if err != nil {
+		r.cc.ReportError(err)
+		return
+	}
+	_ = r.cc.UpdateState(state)
+}
+
+// Close implements the resolver.Resolver interface.
+func (r *backendsResolver) Close() {}
+
+// WaitForHealthyBackends waits until the service is SERVING, according to the
+// grpc.health.v1 protocol, on every backend of addr. An empty service is the
+// health of the whole server. Every backend is checked with its own
+// connection, which is established with the options, until the context is
+// done.
+func WaitForHealthyBackends(
+	ctx context.Context, addr string, getState func() *lib.State, service string, options ...grpc.DialOption,
+) error {
+	backends, err := resolveBackends(getState, addr)
+	if err != nil {
+		return err
+	}
+
+	opts := make([]grpc.DialOption, 0, len(options)+1)
+	opts = append(opts, options...)
+	// The backends are dialed directly, but the TLS verification and the
+	// :authority header are still for the host of addr.
+	opts = append(opts, grpc.WithAuthority(addr))
+
+	for _, backend := range backends {
+		if err = waitForHealthyBackend(ctx, getState, addr, backend, service, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func waitForHealthyBackend(
+	ctx context.Context, getState func() *lib.State, addr, backend, service string, opts []grpc.DialOption,
+) error {
+	conn, err := grpc.DialContext(ctx, "passthrough:///"+backend, opts...)
+	if err != nil {
+		return fmt.Errorf("the health of backend %s couldn't be checked: %w", backend, err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	client := grpc_health_v1.NewHealthClient(conn)
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		rpcCtx := withRPCState(ctx, &rpcState{tagsAndMeta: healthCheckTags(getState(), addr)})
+		resp, err := client.Check(rpcCtx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		switch {
+		case err == nil && resp.GetStatus() == grpc_health_v1.HealthCheckResponse_SERVING:
+			return nil
+		case err == nil:
+			lastErr = fmt.Errorf("its status is %s", resp.GetStatus())
+		case ctx.Err() == nil || lastErr == nil:
+			lastErr = err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("the service %q of backend %s isn't healthy: %w", service, backend, lastErr)
+		case <-ticker.C:
+		}
+	}
+}
+
+// healthCheckTags returns the tags of a health check request, which are the
+// same as the ones of the requests of the scripts.
+func healthCheckTags(state *lib.State, addr string) *metrics.TagsAndMeta {
+	ctm := state.Tags.GetCurrentValues()
+	enabled := state.Options.SystemTags
+	ctm.SetSystemTagOrMetaIfEnabled(enabled, metrics.TagURL, addr+healthCheckMethod)
+	ctm.SetSystemTagOrMetaIfEnabled(enabled, metrics.TagService, "grpc.health.v1.Health")
+	ctm.SetSystemTagOrMetaIfEnabled(enabled, metrics.TagMethod, "Check")
+	ctm.SetSystemTagOrMetaIfEnabled(enabled, metrics.TagName, healthCheckMethod)
+	return &ctm
+}
@@ -0,0 +1,224 @@
+package grpcext
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/lib/testutils/mockresolver"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+type testHealthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	checks int64
+	status int32
+}
+
+func (s *testHealthServer) Check(
+	context.Context, *grpc_health_v1.HealthCheckRequest,
+) (*grpc_health_v1.HealthCheckResponse, error) {
+	atomic.AddInt64(&s.checks, 1)
+	return &grpc_health_v1.HealthCheckResponse{
+		Status: grpc_health_v1.HealthCheckResponse_ServingStatus(atomic.LoadInt32(&s.status)),
+	}, nil
+}
+
+func (s *testHealthServer) setStatus(status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	atomic.StoreInt32(&s.status, int32(status))
+}
+
+// startTestBackends starts a gRPC server with the health service on the same
+// port of every one of the IPs, and returns their health services and the
+// port.
+func startTestBackends(t *testing.T, ips ...string) ([]*testHealthServer, string) {
+	t.Helper()
+	servers := make([]*testHealthServer, 0, len(ips))
+	port := "0"
+	for _, ip := range ips {
+		lis, err := net.Listen("tcp", net.JoinHostPort(ip, port))
+		require.NoError(t, err)
+		_, port, err = net.SplitHostPort(lis.Addr().String())
+		require.NoError(t, err)
+
+		hs := &testHealthServer{status: int32(grpc_health_v1.HealthCheckResponse_SERVING)}
+		srv := grpc.NewServer()
+		grpc_health_v1.RegisterHealthServer(srv, hs)
+		go func() {
+			_ = srv.Serve(lis)
+		}()
+		t.Cleanup(srv.Stop)
+		servers = append(servers, hs)
+	}
+	return servers, port
+}
+
+func getBalancingTestState(t *testing.T, ips ...string) (*lib.State, chan metrics.SampleContainer) {
+	t.Helper()
+	hostIPs := make([]net.IP, 0, len(ips))
+	for _, ip := range ips {
+		hostIPs = append(hostIPs, net.ParseIP(ip))
+	}
+	mr := mockresolver.New(map[string][]net.IP{"backends.test": hostIPs})
+	dialer := netext.NewDialer(net.Dialer{}, netext.NewResolver(mr.LookupIPAll, 0, types.DNSfirst, types.DNSany))
+	blocked, err := types.NewHostnameTrie([]string{"*.blocked.test"})
+	require.NoError(t, err)
+	dialer.BlockedHostnames = blocked
+
+	state, samples := getWebTestState(t)
+	state.Dialer = dialer
+	state.Options.SystemTags = metrics.NewSystemTagSet(metrics.TagStatus, metrics.TagBackend)
+	return state, samples
+}
+
+func testDialOptions(state *lib.State) []grpc.DialOption {
+	return append(
+		DefaultOptions(func() *lib.State { return state }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+}
+
+func healthCheck(t *testing.T, state *lib.State, conn *Conn) {
+	t.Helper()
+	ctm := state.Tags.GetCurrentValues()
+	resp, err := conn.Invoke(context.Background(), InvokeRequest{
+		Method: "/grpc.health.v1.Health/Check",
+		MethodDescriptor: grpc_health_v1.File_grpc_health_v1_health_proto.
+			Services().ByName("Health").Methods().ByName("Check"),
+		Message:     []byte(`{}`),
+		TagsAndMeta: &ctm,
+	})
+	require.NoError(t, err)
+	require.Equal(t, codes.OK, resp.Status)
+}
+
+func TestDialBalancedRoundRobin(t *testing.T) {
+	t.Parallel()
+	ips := []string{"127.0.0.1", "127.0.0.2"}
+	servers, port := startTestBackends(t, ips...)
+	state, samples := getBalancingTestState(t, ips...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	getState := func() *lib.State { return state }
+	conn, err := DialBalanced(ctx, "backends.test:"+port, getState, LoadBalancingRoundRobin, testDialOptions(state)...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	// Only the backends that are already connected are picked, so the other
+	// one may not get the first requests.
+	require.Eventually(t, func() bool {
+		healthCheck(t, state, conn)
+		return atomic.LoadInt64(&servers[0].checks) > 0 && atomic.LoadInt64(&servers[1].checks) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	backends := map[string]bool{}
+	for _, sc := range metrics.GetBufferedSamples(samples) {
+		for _, sample := range sc.GetSamples() {
+			backend, ok := sample.Tags.Get(metrics.TagBackend.String())
+			require.True(t, ok)
+			backends[backend] = true
+		}
+	}
+	assert.Equal(t, map[string]bool{
+		net.JoinHostPort(ips[0], port): true,
+		net.JoinHostPort(ips[1], port): true,
+	}, backends)
+}
+
+func TestDialBalancedPickFirst(t *testing.T) {
+	t.Parallel()
+	ips := []string{"127.0.0.1", "127.0.0.3"}
+	servers, port := startTestBackends(t, ips...)
+	state, _ := getBalancingTestState(t, ips...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	getState := func() *lib.State { return state }
+	conn, err := DialBalanced(ctx, "backends.test:"+port, getState, LoadBalancingPickFirst, testDialOptions(state)...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	for i := 0; i < 5; i++ {
+		healthCheck(t, state, conn)
+	}
+	assert.Equal(t, int64(5), atomic.LoadInt64(&servers[0].checks))
+	assert.Equal(t, int64(0), atomic.LoadInt64(&servers[1].checks))
+}
+
+func TestDialBalancedBlockedHostname(t *testing.T) {
+	t.Parallel()
+	state, _ := getBalancingTestState(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	getState := func() *lib.State { return state }
+	_, err := DialBalanced(ctx, "host.blocked.test:443", getState, LoadBalancingRoundRobin, testDialOptions(state)...)
+	require.ErrorContains(t, err, "hostname (host.blocked.test) is in a blocked pattern (*.blocked.test)")
+}
+
+func TestWaitForHealthyBackends(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Healthy", func(t *testing.T) {
+		t.Parallel()
+		ips := []string{"127.0.0.1", "127.0.0.4"}
+		servers, port := startTestBackends(t, ips...)
+		state, _ := getBalancingTestState(t, ips...)
+		servers[1].setStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		time.AfterFunc(300*time.Millisecond, func() {
+			servers[1].setStatus(grpc_health_v1.HealthCheckResponse_SERVING)
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		getState := func() *lib.State { return state }
+		err := WaitForHealthyBackends(ctx, "backends.test:"+port, getState, "", testDialOptions(state)...)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), atomic.LoadInt64(&servers[0].checks))
+		assert.Greater(t, atomic.LoadInt64(&servers[1].checks), int64(1))
+	})
+
+	t.Run("Unhealthy", func(t *testing.T) {
+		t.Parallel()
+		ips := []string{"127.0.0.1", "127.0.0.5"}
+		servers, port := startTestBackends(t, ips...)
+		state, _ := getBalancingTestState(t, ips...)
+		servers[1].setStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		getState := func() *lib.State { return state }
+		err := WaitForHealthyBackends(ctx, "backends.test:"+port, getState, "svc", testDialOptions(state)...)
+		require.EqualError(t, err, `the service "svc" of backend `+
+			net.JoinHostPort(ips[1], port)+" isn't healthy: its status is NOT_SERVING")
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		t.Parallel()
+		state, _ := getBalancingTestState(t, "127.0.0.1")
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		port := strconv.Itoa(lis.Addr().(*net.TCPAddr).Port) //nolint:forcetypeassert
+		require.NoError(t, lis.Close())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		getState := func() *lib.State { return state }
+		err = WaitForHealthyBackends(ctx, "backends.test:"+port, getState, "", testDialOptions(state)...)
+		require.ErrorContains(t, err, "the health of backend 127.0.0.1:"+port+" couldn't be checked")
+	})
+}
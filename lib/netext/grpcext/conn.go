@@ -96,6 +96,9 @@ func Dial(ctx context.Context, addr string, options ...grpc.DialOption) (*Conn,
 // Reflect returns using the reflection the FileDescriptorSet describing the service.
 func (c *Conn) Reflect(ctx context.Context) (*descriptorpb.FileDescriptorSet, error) {
 	rc := reflectionClient{Conn: c.raw}
+	if wc, ok := c.raw.(*webConn); ok {
+		rc.Conn = singleRequestConn{webConn: wc}
+	}
 	return rc.Reflect(ctx)
 }
 
@@ -246,6 +249,9 @@ func (h statsHandler) HandleRPC(ctx context.Context, stat grpcstats.RPCStats) {
 				stateRPC.tagsAndMeta.SetSystemTagOrMeta(metrics.TagIP, ip)
 			}
 		}
+		if state.Options.SystemTags.Has(metrics.TagBackend) && s.RemoteAddr != nil {
+			stateRPC.tagsAndMeta.SetSystemTagOrMeta(metrics.TagBackend, s.RemoteAddr.String())
+		}
 	case *grpcstats.End:
 		if state.Options.SystemTags.Has(metrics.TagStatus) {
 			stateRPC.tagsAndMeta.SetSystemTagOrMeta(metrics.TagStatus, strconv.Itoa(int(status.Code(s.Error))))
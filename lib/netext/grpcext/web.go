@@ -0,0 +1,704 @@
+package grpcext
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.k6.io/k6/lib"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	grpcstats "google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Protocol is the wire protocol of a gRPC connection.
+type Protocol string
+
+// The supported wire protocols. gRPC-Web and the Connect protocol are
+// HTTP-based, so they can be used with the services behind the proxies and
+// load balancers that don't support native gRPC.
+const (
+	ProtocolGRPC    Protocol = "grpc"
+	ProtocolGRPCWeb Protocol = "grpc-web"
+	ProtocolConnect Protocol = "connect"
+)
+
+const (
+	grpcWebContentType        = "application/grpc-web+proto"
+	connectUnaryContentType   = "application/proto"
+	connectStreamContentType  = "application/connect+proto"
+	connectProtocolVersion    = "1"
+	connectTrailerPrefix      = "trailer-"
+	defaultWebMaxReceiveSize  = 4 * 1024 * 1024 // the same as the default of grpc-go
+	defaultWebMaxSendSize     = math.MaxInt32
+	maxConnectTimeoutMsDigits = 10
+)
+
+// WebOptions are the options of the connections with the HTTP-based protocols.
+type WebOptions struct {
+	Protocol Protocol
+	// TLSConfig is used for the https URLs, the HTTP/2 support is negotiated
+	// with ALPN. Plaintext connections always use HTTP/1.1.
+	TLSConfig      *tls.Config
+	Plaintext      bool
+	UserAgent      string
+	MaxReceiveSize int
+	MaxSendSize    int
+}
+
+// DialWeb returns a connection that uses one of the HTTP-based protocols,
+// gRPC-Web or Connect, to call the methods. The address can be either a
+// host:port, or an URL with a path prefix, e.g. https://example.com/api. The
+// TCP connections are established by the first request, with the VU's dialer.
+//
+// Client streaming is only supported by the Connect protocol, and it's only
+// full-duplex over HTTP/2. Over HTTP/1.1, the responses are only received
+// after the request stream has ended.
+func DialWeb(addr string, getState func() *lib.State, opts WebOptions) (*Conn, error) {
+	if opts.Protocol != ProtocolGRPCWeb && opts.Protocol != ProtocolConnect {
+		return nil, fmt.Errorf("unsupported protocol '%s'", opts.Protocol)
+	}
+
+	baseURL := addr
+	if !strings.Contains(addr, "://") {
+		scheme := "https"
+		if opts.Plaintext {
+			scheme = "http"
+		}
+		baseURL = scheme + "://" + addr
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address '%s': %w", addr, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid address '%s': unsupported scheme '%s'", addr, u.Scheme)
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return getState().Dialer.DialContext(ctx, network, addr)
+		},
+		TLSClientConfig:   opts.TLSConfig,
+		ForceAttemptHTTP2: true,
+	}
+
+	conn := &webConn{
+		protocol:       opts.Protocol,
+		baseURL:        strings.TrimSuffix(u.String(), "/"),
+		client:         &http.Client{Transport: transport},
+		userAgent:      opts.UserAgent,
+		maxReceiveSize: opts.MaxReceiveSize,
+		maxSendSize:    opts.MaxSendSize,
+		stats:          statsHandler{getState: getState},
+	}
+	if conn.maxReceiveSize <= 0 {
+		conn.maxReceiveSize = defaultWebMaxReceiveSize
+	}
+	if conn.maxSendSize <= 0 {
+		conn.maxSendSize = defaultWebMaxSendSize
+	}
+	return &Conn{raw: conn}, nil
+}
+
+// webConn is a grpc.ClientConnInterface, which calls the methods with the
+// HTTP-based protocols. It reports the same stats as the native gRPC
+// connections, so the metrics and the HTTP debugging work the same way.
+type webConn struct {
+	protocol       Protocol
+	baseURL        string
+	client         *http.Client
+	userAgent      string
+	maxReceiveSize int
+	maxSendSize    int
+	stats          grpcstats.Handler
+}
+
+var _ clientConnCloser = &webConn{}
+
+// Invoke implements the grpc.ClientConnInterface.
+func (c *webConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	s := c.newStream(ctx, &grpc.StreamDesc{}, method, opts)
+	if err := s.SendMsg(args); err != nil && !errors.Is(err, io.EOF) {
+		s.finish(err)
+		return err
+	}
+	_ = s.CloseSend()
+
+	err := s.RecvMsg(reply)
+	if errors.Is(err, io.EOF) {
+		err = status.Error(codes.Internal, "the response doesn't have a message")
+		s.finish(err)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	// The stream has to end after the message, with the status of the call.
+	if err = s.recvEnd(); errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// NewStream implements the grpc.ClientConnInterface.
+func (c *webConn) NewStream(
+	ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	if desc.ClientStreams && c.protocol == ProtocolGRPCWeb {
+		return nil, status.Error(codes.Unimplemented, "client streaming isn't supported by gRPC-Web")
+	}
+	return c.newStream(ctx, desc, method, opts), nil
+}
+
+// Close closes the idle connections.
+func (c *webConn) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// newStream starts the request of the call. Its body is written by the
+// stream, so the request is sent in the background and the stream waits for
+// the response when it's needed.
+func (c *webConn) newStream(
+	ctx context.Context, desc *grpc.StreamDesc, method string, opts []grpc.CallOption,
+) *webStream {
+	s := &webStream{
+		conn:           c,
+		method:         method,
+		unary:          c.protocol == ProtocolConnect && !desc.ClientStreams && !desc.ServerStreams,
+		maxReceiveSize: c.maxReceiveSize,
+		maxSendSize:    c.maxSendSize,
+		header:         metadata.MD{},
+		trailer:        metadata.MD{},
+		responded:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case grpc.HeaderCallOption:
+			s.headerAddrs = append(s.headerAddrs, o.HeaderAddr)
+		case grpc.TrailerCallOption:
+			s.trailerAddrs = append(s.trailerAddrs, o.TrailerAddr)
+		case grpc.MaxRecvMsgSizeCallOption:
+			s.maxReceiveSize = o.MaxRecvMsgSize
+		case grpc.MaxSendMsgSizeCallOption:
+			s.maxSendSize = o.MaxSendMsgSize
+		}
+	}
+
+	ctx = c.stats.TagRPC(ctx, &grpcstats.RPCTagInfo{FullMethodName: method})
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.beginTime = time.Now()
+	c.stats.HandleRPC(s.ctx, &grpcstats.Begin{
+		Client:         true,
+		BeginTime:      s.beginTime,
+		IsClientStream: desc.ClientStreams,
+		IsServerStream: desc.ServerStreams,
+	})
+
+	body, bodyWriter := io.Pipe()
+	s.body = bodyWriter
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, c.baseURL+method, body)
+	if err != nil {
+		s.respErr = err
+		close(s.responded)
+		return s
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	s.setRequestHeaders(req.Header, md)
+
+	var outHeaderOnce sync.Once
+	req = req.WithContext(httptrace.WithClientTrace(s.ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			outHeaderOnce.Do(func() {
+				c.stats.HandleRPC(s.ctx, &grpcstats.OutHeader{
+					Client:     true,
+					Header:     md,
+					FullMethod: method,
+					RemoteAddr: info.Conn.RemoteAddr(),
+					LocalAddr:  info.Conn.LocalAddr(),
+				})
+			})
+		},
+	}))
+
+	go func() {
+		s.resp, s.respErr = c.client.Do(req) //nolint:bodyclose // it's closed by finish()
+		if s.respErr != nil {
+			_ = body.CloseWithError(s.respErr)
+		}
+		close(s.responded)
+	}()
+	return s
+}
+
+// webStream is a grpc.ClientStream of a call with one of the HTTP-based
+// protocols. The unary calls of the Connect protocol don't have their
+// messages enveloped, all other calls do.
+type webStream struct {
+	conn   *webConn
+	ctx    context.Context
+	cancel context.CancelFunc
+	method string
+	unary  bool
+
+	maxReceiveSize, maxSendSize int
+	headerAddrs, trailerAddrs   []*metadata.MD
+
+	body      *io.PipeWriter
+	responded chan struct{}
+	resp      *http.Response
+	respErr   error
+
+	beginTime time.Time
+	reader    *bufio.Reader
+	header    metadata.MD
+	trailer   metadata.MD
+
+	received   bool // if the message of the Connect unary call is received
+	finishOnce sync.Once
+	finished   bool
+	finalErr   error
+}
+
+var _ grpc.ClientStream = &webStream{}
+
+func (s *webStream) setRequestHeaders(h http.Header, md metadata.MD) {
+	var timeout time.Duration
+	deadline, hasDeadline := s.ctx.Deadline()
+	if hasDeadline {
+		timeout = time.Until(deadline)
+	}
+
+	if s.conn.protocol == ProtocolGRPCWeb {
+		h.Set("Content-Type", grpcWebContentType)
+		h.Set("X-Grpc-Web", "1")
+		if hasDeadline {
+			h.Set("Grpc-Timeout", encodeGRPCTimeout(timeout))
+		}
+		setMetadataHeaders(h, md, base64.StdEncoding)
+	} else {
+		if s.unary {
+			h.Set("Content-Type", connectUnaryContentType)
+		} else {
+			h.Set("Content-Type", connectStreamContentType)
+		}
+		h.Set("Connect-Protocol-Version", connectProtocolVersion)
+		if hasDeadline {
+			ms := timeout.Milliseconds()
+			if ms < 1 {
+				ms = 1
+			}
+			if value := strconv.FormatInt(ms, 10); len(value) <= maxConnectTimeoutMsDigits {
+				h.Set("Connect-Timeout-Ms", value)
+			}
+		}
+		setMetadataHeaders(h, md, base64.RawStdEncoding)
+	}
+	if s.conn.userAgent != "" {
+		h.Set("User-Agent", s.conn.userAgent)
+	}
+}
+
+// Header returns the header metadata of the response, it waits for it.
+func (s *webStream) Header() (metadata.MD, error) {
+	if err := s.waitResponse(); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return s.header.Copy(), nil
+}
+
+// Trailer returns the trailer metadata, which is only available after the
+// stream has ended.
+func (s *webStream) Trailer() metadata.MD {
+	return s.trailer.Copy()
+}
+
+// CloseSend ends the request stream.
+func (s *webStream) CloseSend() error {
+	return s.body.Close()
+}
+
+// Context returns the context of the stream.
+func (s *webStream) Context() context.Context {
+	return s.ctx
+}
+
+// SendMsg sends the message. It returns io.EOF if the stream has already
+// ended, in which case the status can be received with RecvMsg().
+func (s *webStream) SendMsg(m interface{}) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "unsupported message type %T", m)
+	}
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to marshal the message: %v", err)
+	}
+	if len(data) > s.maxSendSize {
+		return status.Errorf(codes.ResourceExhausted,
+			"trying to send message larger than max (%d vs. %d)", len(data), s.maxSendSize)
+	}
+
+	wireLength := len(data)
+	if s.unary {
+		_, err = s.body.Write(data)
+	} else {
+		err = writeEnvelope(s.body, 0, data)
+		wireLength += envelopeHeaderLen
+	}
+	if err != nil {
+		return io.EOF
+	}
+	s.conn.stats.HandleRPC(s.ctx, &grpcstats.OutPayload{
+		Client:     true,
+		Payload:    m,
+		Length:     len(data),
+		WireLength: wireLength,
+		SentTime:   time.Now(),
+	})
+	return nil
+}
+
+// RecvMsg receives the next message. It returns io.EOF when the stream has
+// ended successfully, or the status error of the call otherwise.
+func (s *webStream) RecvMsg(m interface{}) error {
+	if err := s.waitResponse(); err != nil {
+		return err
+	}
+	if s.finished {
+		return s.finalErr
+	}
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return s.finish(status.Errorf(codes.Internal, "unsupported message type %T", m))
+	}
+
+	data, err := s.recvData()
+	if err != nil {
+		return err
+	}
+	if err = proto.Unmarshal(data, msg); err != nil {
+		return s.finish(status.Errorf(codes.Internal, "failed to unmarshal the response: %v", err))
+	}
+	wireLength := len(data)
+	if !s.unary {
+		wireLength += envelopeHeaderLen
+	}
+	s.conn.stats.HandleRPC(s.ctx, &grpcstats.InPayload{
+		Client:     true,
+		Payload:    m,
+		Length:     len(data),
+		WireLength: wireLength,
+		RecvTime:   time.Now(),
+	})
+	return nil
+}
+
+// recvEnd receives the end of the stream, it's an error if there's another
+// message instead.
+func (s *webStream) recvEnd() error {
+	if s.finished {
+		return s.finalErr
+	}
+	if _, err := s.recvData(); err != nil {
+		return err
+	}
+	return s.finish(status.Error(codes.Internal, "the response has more than one message"))
+}
+
+// recvData returns the data of the next message, or it ends the stream if
+// there aren't any more.
+func (s *webStream) recvData() ([]byte, error) {
+	if s.unary {
+		if s.received {
+			trailer := metadataFromHeaders(s.resp.Header, connectTrailerPrefix)
+			return nil, s.finishWithTrailers(trailer, status.New(codes.OK, ""))
+		}
+		s.received = true
+		data, err := io.ReadAll(io.LimitReader(s.resp.Body, int64(s.maxReceiveSize)+1))
+		if err != nil {
+			return nil, s.finish(s.transportError(err))
+		}
+		if len(data) > s.maxReceiveSize {
+			return nil, s.finish(status.Errorf(codes.ResourceExhausted,
+				"received message larger than max (%d vs. %d)", len(data), s.maxReceiveSize))
+		}
+		return data, nil
+	}
+
+	flags, data, err := readEnvelope(s.reader, s.maxReceiveSize)
+	switch {
+	case errors.Is(err, io.EOF):
+		return nil, s.finishWithoutTrailers()
+	case err != nil:
+		if _, ok := status.FromError(err); !ok {
+			err = s.transportError(err)
+		}
+		return nil, s.finish(err)
+	case flags&flagCompressed != 0:
+		return nil, s.finish(status.Error(codes.Internal, "compressed messages aren't supported"))
+	case s.conn.protocol == ProtocolGRPCWeb && flags&flagGRPCWebTrailer != 0:
+		trailer, st, err := parseGRPCWebTrailers(data)
+		if err != nil {
+			return nil, s.finish(status.Error(codes.Internal, err.Error()))
+		}
+		if st == nil {
+			return nil, s.finish(status.Error(codes.Internal, "the trailers don't have a grpc-status"))
+		}
+		return nil, s.finishWithTrailers(trailer, st)
+	case s.conn.protocol == ProtocolConnect && flags&flagConnectEnd != 0:
+		trailer, st, err := parseConnectEndStream(data)
+		if err != nil {
+			return nil, s.finish(status.Error(codes.Internal, err.Error()))
+		}
+		return nil, s.finishWithTrailers(trailer, st)
+	default:
+		return data, nil
+	}
+}
+
+// finishWithoutTrailers ends a stream, the body of which has ended without
+// the trailers or the end of stream message. Only gRPC-Web can have them in
+// the HTTP trailers instead.
+func (s *webStream) finishWithoutTrailers() error {
+	if s.conn.protocol == ProtocolGRPCWeb {
+		st, err := grpcStatusFromHeaders(s.resp.Trailer)
+		if err != nil {
+			return s.finish(status.Error(codes.Internal, err.Error()))
+		}
+		if st != nil {
+			return s.finishWithTrailers(metadataFromHeaders(s.resp.Trailer, ""), st)
+		}
+		return s.finish(status.Error(codes.Internal, "the response ended without the trailers"))
+	}
+	return s.finish(status.Error(codes.Internal, "the response ended without the end of stream message"))
+}
+
+func (s *webStream) finishWithTrailers(trailer metadata.MD, st *status.Status) error {
+	s.trailer = trailer
+	return s.finish(st.Err())
+}
+
+// waitResponse waits for the headers of the response and checks them. The
+// responses without any messages, e.g. the errors, end the stream.
+func (s *webStream) waitResponse() error {
+	if s.reader != nil || s.finished {
+		return nil
+	}
+	<-s.responded // the request is canceled with the context of the stream
+	if s.respErr != nil {
+		return s.finish(s.transportError(s.respErr))
+	}
+
+	s.reader = bufio.NewReader(s.resp.Body)
+	s.header = metadataFromHeaders(s.resp.Header, "")
+	s.conn.stats.HandleRPC(s.ctx, &grpcstats.InHeader{
+		Client:     true,
+		Header:     s.header.Copy(),
+		FullMethod: s.method,
+	})
+	for _, addr := range s.headerAddrs {
+		*addr = s.header.Copy()
+	}
+
+	contentType := s.resp.Header.Get("Content-Type")
+	switch {
+	case s.conn.protocol == ProtocolGRPCWeb:
+		// The trailers-only responses have the status in the headers.
+		st, err := grpcStatusFromHeaders(s.resp.Header)
+		if err != nil {
+			return s.finish(status.Error(codes.Internal, err.Error()))
+		}
+		if st != nil {
+			return s.finishWithTrailers(s.header.Copy(), st)
+		}
+		if s.resp.StatusCode != http.StatusOK {
+			return s.finish(s.httpStatusError())
+		}
+		if !strings.HasPrefix(contentType, grpcWebContentType) && contentType != "application/grpc-web" {
+			return s.finish(status.Errorf(codes.Internal, "unexpected content-type '%s'", contentType))
+		}
+	case s.unary:
+		if s.resp.StatusCode != http.StatusOK {
+			return s.finish(s.connectUnaryError())
+		}
+		if contentType != connectUnaryContentType {
+			return s.finish(status.Errorf(codes.Internal, "unexpected content-type '%s'", contentType))
+		}
+	default:
+		if s.resp.StatusCode != http.StatusOK {
+			return s.finish(s.httpStatusError())
+		}
+		if contentType != connectStreamContentType {
+			return s.finish(status.Errorf(codes.Internal, "unexpected content-type '%s'", contentType))
+		}
+	}
+	return nil
+}
+
+// connectUnaryError returns the error of a failed unary call of the Connect
+// protocol, which is in the JSON body, with the trailers in the headers.
+func (s *webStream) connectUnaryError() error {
+	s.trailer = metadataFromHeaders(s.resp.Header, connectTrailerPrefix)
+	var ce connectError
+	data, err := io.ReadAll(io.LimitReader(s.resp.Body, int64(s.maxReceiveSize)))
+	if err != nil || json.Unmarshal(data, &ce) != nil || ce.Code == "" {
+		return s.httpStatusError()
+	}
+	return ce.status(codeFromHTTPStatus(s.resp.StatusCode)).Err()
+}
+
+func (s *webStream) httpStatusError() error {
+	return status.Errorf(codeFromHTTPStatus(s.resp.StatusCode),
+		"unexpected HTTP status code received from server: %d (%s)",
+		s.resp.StatusCode, http.StatusText(s.resp.StatusCode))
+}
+
+// transportError returns the status of a failed request, or of a response
+// that couldn't be read.
+func (s *webStream) transportError(err error) error {
+	if ctxErr := s.ctx.Err(); ctxErr != nil {
+		return status.FromContextError(ctxErr).Err()
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
+
+// finish ends the stream with the given status error, or with io.EOF if it's
+// nil, and reports the end of the call. It returns the final error, for
+// convenience.
+func (s *webStream) finish(err error) error {
+	s.finishOnce.Do(func() {
+		s.finished = true
+		s.finalErr = err
+		if err == nil {
+			s.finalErr = io.EOF
+		}
+		for _, addr := range s.trailerAddrs {
+			*addr = s.trailer.Copy()
+		}
+		if len(s.trailer) > 0 {
+			s.conn.stats.HandleRPC(s.ctx, &grpcstats.InTrailer{Client: true, Trailer: s.trailer.Copy()})
+		}
+		s.conn.stats.HandleRPC(s.ctx, &grpcstats.End{
+			Client:    true,
+			BeginTime: s.beginTime,
+			EndTime:   time.Now(),
+			Trailer:   s.trailer.Copy(),
+			Error:     err,
+		})
+
+		_ = s.body.Close()
+		s.cancel()
+		<-s.responded
+		if s.resp != nil {
+			_ = s.resp.Body.Close()
+		}
+	})
+	return s.finalErr
+}
+
+// singleRequestConn adapts the bidirectional streams, on which every request
+// is followed by a response, like the ones of the reflection client, to a
+// sequence of server streaming calls with a single request. So they work with
+// gRPC-Web and over HTTP/1.1, which aren't full-duplex.
+type singleRequestConn struct {
+	*webConn
+}
+
+// NewStream implements the grpc.ClientConnInterface.
+func (c singleRequestConn) NewStream(
+	ctx context.Context, _ *grpc.StreamDesc, method string, opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	return &singleRequestStream{conn: c.webConn, ctx: ctx, method: method, opts: opts}, nil
+}
+
+type singleRequestStream struct {
+	conn   *webConn
+	ctx    context.Context
+	method string
+	opts   []grpc.CallOption
+
+	request interface{}
+	closed  bool
+	last    *webStream
+}
+
+var _ grpc.ClientStream = &singleRequestStream{}
+
+func (s *singleRequestStream) Header() (metadata.MD, error) {
+	if s.last == nil {
+		return metadata.MD{}, nil
+	}
+	return s.last.Header()
+}
+
+func (s *singleRequestStream) Trailer() metadata.MD {
+	if s.last == nil {
+		return metadata.MD{}
+	}
+	return s.last.Trailer()
+}
+
+func (s *singleRequestStream) CloseSend() error {
+	s.closed = true
+	return nil
+}
+
+func (s *singleRequestStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *singleRequestStream) SendMsg(m interface{}) error {
+	if s.closed {
+		return io.EOF
+	}
+	s.request = m
+	return nil
+}
+
+// RecvMsg calls the method with the last sent request and receives its only
+// response.
+func (s *singleRequestStream) RecvMsg(m interface{}) error {
+	if s.request == nil {
+		if s.closed {
+			return io.EOF
+		}
+		return status.Error(codes.Internal, "a request has to be sent before every response is received")
+	}
+	request := s.request
+	s.request = nil
+
+	s.last = s.conn.newStream(s.ctx, &grpc.StreamDesc{ServerStreams: true}, s.method, s.opts)
+	if err := s.last.SendMsg(request); err != nil && !errors.Is(err, io.EOF) {
+		return s.last.finish(err)
+	}
+	_ = s.last.CloseSend()
+	if err := s.last.RecvMsg(m); err != nil {
+		return err
+	}
+	if err := s.last.recvEnd(); !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
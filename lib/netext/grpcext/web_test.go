@@ -0,0 +1,385 @@
+package grpcext
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+func getWebTestState(t *testing.T) (*lib.State, chan metrics.SampleContainer) {
+	t.Helper()
+	registry := metrics.NewRegistry()
+	samples := make(chan metrics.SampleContainer, 100)
+	return &lib.State{
+		Options: lib.Options{
+			SystemTags: metrics.NewSystemTagSet(metrics.TagStatus, metrics.TagIP),
+		},
+		Dialer: netext.NewDialer(net.Dialer{}, netext.NewResolver(
+			net.LookupIP, 0, types.DNSfirst, types.DNSpreferIPv4)),
+		Logger:         testutils.NewLogger(t),
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+		Samples:        samples,
+	}, samples
+}
+
+func dialTestWeb(t *testing.T, state *lib.State, protocol Protocol, handler http.HandlerFunc) *Conn {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	conn, err := DialWeb(srv.URL, func() *lib.State { return state }, WebOptions{Protocol: protocol})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func helloRequest(t *testing.T, greeting string) []byte {
+	t.Helper()
+	return []byte(`{"greeting":"` + greeting + `"}`)
+}
+
+// readTestRequestMessages reads the enveloped messages of a request.
+func readTestRequestMessages(t *testing.T, r *http.Request) []string {
+	t.Helper()
+	var greetings []string
+	reader := bufio.NewReader(r.Body)
+	for {
+		flags, data, err := readEnvelope(reader, 1024)
+		if errors.Is(err, io.EOF) {
+			return greetings
+		}
+		require.NoError(t, err)
+		require.Equal(t, byte(0), flags)
+		msg := dynamicpb.NewMessage(methodFromProto("SayHello").Input())
+		require.NoError(t, proto.Unmarshal(data, msg))
+		greetings = append(greetings, msg.Get(msg.Descriptor().Fields().ByName("greeting")).String())
+	}
+}
+
+func helloResponse(t *testing.T, reply string) []byte {
+	t.Helper()
+	msg := dynamicpb.NewMessage(methodFromProto("SayHello").Output())
+	require.NoError(t, protojson.Unmarshal([]byte(`{"reply":"`+reply+`"}`), msg))
+	data, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	return data
+}
+
+func TestWebInvokeConnect(t *testing.T) {
+	t.Parallel()
+
+	state, samples := getWebTestState(t)
+	conn := dialTestWeb(t, state, ProtocolConnect, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/hello.HelloService/SayHello", r.URL.Path)
+		assert.Equal(t, "application/proto", r.Header.Get("Content-Type"))
+		assert.Equal(t, "1", r.Header.Get("Connect-Protocol-Version"))
+		assert.NotEmpty(t, r.Header.Get("Connect-Timeout-Ms"))
+		assert.Equal(t, "bar", r.Header.Get("X-Foo"))
+		assert.Equal(t, "AQI", r.Header.Get("X-Data-Bin"), "binary metadata is base64 encoded without padding")
+
+		msg := dynamicpb.NewMessage(methodFromProto("SayHello").Input())
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, proto.Unmarshal(data, msg))
+
+		w.Header().Set("Content-Type", "application/proto")
+		w.Header().Set("X-Header", "header value")
+		w.Header().Set("Trailer-X-Trailer", "trailer value")
+		_, _ = w.Write(helloResponse(t, "hi "+msg.Get(msg.Descriptor().Fields().ByName("greeting")).String()))
+	})
+
+	res, err := conn.Invoke(context.Background(), InvokeRequest{
+		Method:           "/hello.HelloService/SayHello",
+		MethodDescriptor: methodFromProto("SayHello"),
+		Timeout:          time.Minute,
+		TagsAndMeta:      &metrics.TagsAndMeta{Tags: state.Tags.GetCurrentValues().Tags},
+		Message:          helloRequest(t, "k6"),
+		Metadata:         metadata.Pairs("x-foo", "bar", "x-data-bin", "\x01\x02"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, codes.OK, res.Status)
+	assert.Equal(t, map[string]interface{}{"reply": "hi k6"}, res.Message)
+	assert.Equal(t, []string{"header value"}, res.Headers["x-header"])
+	assert.NotContains(t, res.Headers, "trailer-x-trailer")
+	assert.Equal(t, []string{"trailer value"}, res.Trailers["x-trailer"])
+
+	containers := metrics.GetBufferedSamples(samples)
+	require.Len(t, containers, 1)
+	sample := containers[0].GetSamples()[0]
+	assert.Equal(t, metrics.GRPCReqDurationName, sample.Metric.Name)
+	assert.Equal(t, map[string]string{"status": "0", "ip": "127.0.0.1"}, sample.Tags.Map())
+}
+
+func TestWebInvokeConnectError(t *testing.T) {
+	t.Parallel()
+
+	state, _ := getWebTestState(t)
+	conn := dialTestWeb(t, state, ProtocolConnect, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":"not_found","message":"no greeting for you"}`))
+	})
+
+	res, err := conn.Invoke(context.Background(), InvokeRequest{
+		Method:           "/hello.HelloService/SayHello",
+		MethodDescriptor: methodFromProto("SayHello"),
+		TagsAndMeta:      &metrics.TagsAndMeta{Tags: state.Tags.GetCurrentValues().Tags},
+		Message:          helloRequest(t, "k6"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, codes.NotFound, res.Status)
+	assert.Equal(t, "no greeting for you", res.Error.(map[string]interface{})["message"]) //nolint:forcetypeassert
+}
+
+func TestWebInvokeGRPCWeb(t *testing.T) {
+	t.Parallel()
+
+	state, _ := getWebTestState(t)
+	conn := dialTestWeb(t, state, ProtocolGRPCWeb, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/grpc-web+proto", r.Header.Get("Content-Type"))
+		assert.Equal(t, "1", r.Header.Get("X-Grpc-Web"))
+		assert.Equal(t, []string{"k6"}, readTestRequestMessages(t, r))
+
+		w.Header().Set("Content-Type", "application/grpc-web+proto")
+		w.Header().Set("X-Header", "header value")
+		require.NoError(t, writeEnvelope(w, 0, helloResponse(t, "hi k6")))
+		require.NoError(t, writeEnvelope(w, flagGRPCWebTrailer,
+			[]byte("grpc-status: 0\r\nx-trailer: trailer value\r\n")))
+	})
+
+	res, err := conn.Invoke(context.Background(), InvokeRequest{
+		Method:           "/hello.HelloService/SayHello",
+		MethodDescriptor: methodFromProto("SayHello"),
+		TagsAndMeta:      &metrics.TagsAndMeta{Tags: state.Tags.GetCurrentValues().Tags},
+		Message:          helloRequest(t, "k6"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, codes.OK, res.Status)
+	assert.Equal(t, map[string]interface{}{"reply": "hi k6"}, res.Message)
+	assert.Equal(t, []string{"header value"}, res.Headers["x-header"])
+	assert.Equal(t, []string{"trailer value"}, res.Trailers["x-trailer"])
+}
+
+func TestWebInvokeGRPCWebErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		handler    http.HandlerFunc
+		expStatus  codes.Code
+		expMessage string
+	}{
+		"trailers only": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/grpc-web+proto")
+				w.Header().Set("Grpc-Status", "7")
+				w.Header().Set("Grpc-Message", "not%20allowed")
+			},
+			expStatus:  codes.PermissionDenied,
+			expMessage: "not allowed",
+		},
+		"HTTP status": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			expStatus:  codes.Unavailable,
+			expMessage: "unexpected HTTP status code received from server: 503 (Service Unavailable)",
+		},
+		"missing trailers": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/grpc-web+proto")
+				require.NoError(t, writeEnvelope(w, 0, helloResponse(t, "hi")))
+			},
+			expStatus:  codes.Internal,
+			expMessage: "the response ended without the trailers",
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			state, _ := getWebTestState(t)
+			conn := dialTestWeb(t, state, ProtocolGRPCWeb, tc.handler)
+			res, err := conn.Invoke(context.Background(), InvokeRequest{
+				Method:           "/hello.HelloService/SayHello",
+				MethodDescriptor: methodFromProto("SayHello"),
+				TagsAndMeta:      &metrics.TagsAndMeta{Tags: state.Tags.GetCurrentValues().Tags},
+				Message:          helloRequest(t, "k6"),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expStatus, res.Status)
+			assert.Equal(t, tc.expMessage, res.Error.(map[string]interface{})["message"]) //nolint:forcetypeassert
+		})
+	}
+}
+
+func TestWebStream(t *testing.T) {
+	t.Parallel()
+
+	handler := func(protocol Protocol) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			greetings := readTestRequestMessages(t, r)
+			if protocol == ProtocolGRPCWeb {
+				w.Header().Set("Content-Type", "application/grpc-web+proto")
+			} else {
+				w.Header().Set("Content-Type", "application/connect+proto")
+			}
+			for _, greeting := range greetings {
+				for i := 0; i < 2; i++ {
+					require.NoError(t, writeEnvelope(w, 0, helloResponse(t, "hi "+greeting)))
+				}
+			}
+			if protocol == ProtocolGRPCWeb {
+				require.NoError(t, writeEnvelope(w, flagGRPCWebTrailer, []byte("grpc-status: 0\r\n")))
+			} else {
+				require.NoError(t, writeEnvelope(w, flagConnectEnd, []byte(`{"metadata":{"x-trailer":["value"]}}`)))
+			}
+		}
+	}
+
+	testCases := map[string]struct {
+		protocol   Protocol
+		method     string
+		greetings  []string
+		expReplies []string
+	}{
+		"gRPC-Web server streaming": {
+			protocol: ProtocolGRPCWeb, method: "LotsOfReplies",
+			greetings: []string{"k6"}, expReplies: []string{"hi k6", "hi k6"},
+		},
+		"Connect server streaming": {
+			protocol: ProtocolConnect, method: "LotsOfReplies",
+			greetings: []string{"k6"}, expReplies: []string{"hi k6", "hi k6"},
+		},
+		"Connect bidirectional streaming": {
+			protocol: ProtocolConnect, method: "BidiHello",
+			greetings: []string{"a", "b"}, expReplies: []string{"hi a", "hi a", "hi b", "hi b"},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			state, _ := getWebTestState(t)
+			conn := dialTestWeb(t, state, tc.protocol, handler(tc.protocol))
+			stream, err := conn.NewStream(context.Background(), StreamRequest{
+				Method:           "/hello.HelloService/" + tc.method,
+				MethodDescriptor: methodFromProto(tc.method),
+				TagsAndMeta:      &metrics.TagsAndMeta{Tags: state.Tags.GetCurrentValues().Tags},
+			})
+			require.NoError(t, err)
+			for _, greeting := range tc.greetings {
+				require.NoError(t, stream.Send(helloRequest(t, greeting)))
+			}
+			require.NoError(t, stream.CloseSend())
+
+			var replies []string
+			for {
+				msg, err := stream.ReceiveConverted()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+				replies = append(replies, msg.(map[string]interface{})["reply"].(string)) //nolint:forcetypeassert
+			}
+			assert.Equal(t, tc.expReplies, replies)
+		})
+	}
+}
+
+func TestWebStreamGRPCWebClientStreaming(t *testing.T) {
+	t.Parallel()
+
+	state, _ := getWebTestState(t)
+	conn := dialTestWeb(t, state, ProtocolGRPCWeb, func(http.ResponseWriter, *http.Request) {
+		t.Error("no request should be sent")
+	})
+	_, err := conn.NewStream(context.Background(), StreamRequest{
+		Method:           "/hello.HelloService/LotsOfGreetings",
+		MethodDescriptor: methodFromProto("LotsOfGreetings"),
+		TagsAndMeta:      &metrics.TagsAndMeta{Tags: state.Tags.GetCurrentValues().Tags},
+	})
+	assert.ErrorContains(t, err, "client streaming isn't supported by gRPC-Web")
+}
+
+func TestWebSingleRequestStreams(t *testing.T) {
+	t.Parallel()
+
+	state, _ := getWebTestState(t)
+	requests := 0
+	conn := dialTestWeb(t, state, ProtocolGRPCWeb, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		greetings := readTestRequestMessages(t, r)
+		require.Len(t, greetings, 1)
+		w.Header().Set("Content-Type", "application/grpc-web+proto")
+		require.NoError(t, writeEnvelope(w, 0, helloResponse(t, "hi "+greetings[0])))
+		require.NoError(t, writeEnvelope(w, flagGRPCWebTrailer, []byte("grpc-status: 0\r\n")))
+	})
+
+	// the bidirectional streams are used like by the reflection client
+	md := methodFromProto("BidiHello")
+	stream, err := singleRequestConn{webConn: conn.raw.(*webConn)}.NewStream( //nolint:forcetypeassert
+		context.Background(), &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, "/hello.HelloService/BidiHello")
+	require.NoError(t, err)
+	for _, greeting := range []string{"a", "b"} {
+		req := dynamicpb.NewMessage(md.Input())
+		require.NoError(t, protojson.Unmarshal(helloRequest(t, greeting), req))
+		require.NoError(t, stream.SendMsg(req))
+		resp := dynamicpb.NewMessage(md.Output())
+		require.NoError(t, stream.RecvMsg(resp))
+		assert.Equal(t, "hi "+greeting, resp.Get(md.Output().Fields().ByName("reply")).String())
+	}
+	require.NoError(t, stream.CloseSend())
+	assert.ErrorIs(t, stream.RecvMsg(dynamicpb.NewMessage(md.Output())), io.EOF)
+	assert.Equal(t, 2, requests)
+}
+
+func TestDialWebInvalid(t *testing.T) {
+	t.Parallel()
+
+	getState := func() *lib.State { return nil }
+	_, err := DialWeb("localhost:8080", getState, WebOptions{Protocol: ProtocolGRPC})
+	assert.ErrorContains(t, err, "unsupported protocol 'grpc'")
+	_, err = DialWeb("ws://localhost:8080", getState, WebOptions{Protocol: ProtocolConnect})
+	assert.ErrorContains(t, err, "unsupported scheme 'ws'")
+}
+
+func TestEncodeGRPCTimeout(t *testing.T) {
+	t.Parallel()
+
+	for timeout, expected := range map[time.Duration]string{
+		0:                       "0n",
+		1500 * time.Nanosecond:  "1500n",
+		time.Second:             "1000000u",
+		2 * time.Minute:         "120000m",
+		48 * time.Hour:          "172800S",
+		100000000 * time.Minute: "1666667H",
+	} {
+		assert.Equal(t, expected, encodeGRPCTimeout(timeout), timeout)
+	}
+}
@@ -0,0 +1,298 @@
+package grpcext
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// The flags of the length-prefixed messages of both gRPC-Web and the Connect
+// streaming protocol.
+const (
+	flagCompressed     = 0b00000001
+	flagConnectEnd     = 0b00000010
+	flagGRPCWebTrailer = 0b10000000
+)
+
+const envelopeHeaderLen = 5
+
+// writeEnvelope writes the message, prefixed with the flags and its length.
+func writeEnvelope(w io.Writer, flags byte, data []byte) error {
+	header := make([]byte, envelopeHeaderLen)
+	header[0] = flags
+	binary.BigEndian.PutUint32(header[1:], uint32(len(data)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// readEnvelope reads a length-prefixed message. It returns io.EOF if there
+// aren't any more messages and io.ErrUnexpectedEOF if the last one is cut.
+func readEnvelope(r *bufio.Reader, maxSize int) (flags byte, data []byte, err error) {
+	header := make([]byte, envelopeHeaderLen)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if uint64(size) > uint64(maxSize) {
+		return 0, nil, status.Errorf(codes.ResourceExhausted,
+			"received message larger than max (%d vs. %d)", size, maxSize)
+	}
+	data = make([]byte, size)
+	if _, err = io.ReadFull(r, data); err != nil {
+		if err == io.EOF { //nolint:errorlint // io.ReadFull returns io.EOF as is
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+	return header[0], data, nil
+}
+
+// reservedHeaders are the headers that are set by the protocols, so they
+// can't be sent as metadata and aren't returned as such.
+//
+//nolint:gochecknoglobals
+var reservedHeaders = map[string]bool{
+	"content-type":             true,
+	"content-length":           true,
+	"content-encoding":         true,
+	"accept-encoding":          true,
+	"user-agent":               true,
+	"te":                       true,
+	"grpc-timeout":             true,
+	"grpc-status":              true,
+	"grpc-message":             true,
+	"grpc-status-details-bin":  true,
+	"grpc-encoding":            true,
+	"grpc-accept-encoding":     true,
+	"x-grpc-web":               true,
+	"connect-protocol-version": true,
+	"connect-timeout-ms":       true,
+	"connect-content-encoding": true,
+	"connect-accept-encoding":  true,
+}
+
+// setMetadataHeaders adds the metadata to the HTTP headers. The values of the
+// binary metadata, which have the -bin suffix, are encoded with base64.
+func setMetadataHeaders(h http.Header, md metadata.MD, encoding *base64.Encoding) {
+	for key, values := range md {
+		if reservedHeaders[key] {
+			continue
+		}
+		for _, value := range values {
+			if strings.HasSuffix(key, "-bin") {
+				value = encoding.EncodeToString([]byte(value))
+			}
+			h.Add(key, value)
+		}
+	}
+}
+
+// metadataFromHeaders returns the metadata of the HTTP headers, with the
+// given prefix, if any, removed from their keys. The headers without it are
+// skipped if it's set.
+func metadataFromHeaders(h http.Header, prefix string) metadata.MD {
+	md := metadata.MD{}
+	for key, values := range h {
+		key = strings.ToLower(key)
+		if prefix != "" {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			key = strings.TrimPrefix(key, prefix)
+		} else if strings.HasPrefix(key, connectTrailerPrefix) {
+			continue
+		}
+		if reservedHeaders[key] {
+			continue
+		}
+		for _, value := range values {
+			if strings.HasSuffix(key, "-bin") {
+				if decoded, err := decodeBinaryHeader(value); err == nil {
+					value = string(decoded)
+				}
+			}
+			md.Append(key, value)
+		}
+	}
+	return md
+}
+
+// decodeBinaryHeader decodes the base64 value of a binary header, which can
+// be with or without padding.
+func decodeBinaryHeader(value string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(strings.TrimRight(value, "="))
+}
+
+// encodeGRPCTimeout returns the value of the grpc-timeout header, which is at
+// most 8 digits, followed by the unit.
+func encodeGRPCTimeout(timeout time.Duration) string {
+	if timeout <= 0 {
+		return "0n"
+	}
+	const maxValue = 99999999
+	for _, unit := range []struct {
+		d time.Duration
+		s string
+	}{
+		{time.Nanosecond, "n"}, {time.Microsecond, "u"}, {time.Millisecond, "m"},
+		{time.Second, "S"}, {time.Minute, "M"}, {time.Hour, "H"},
+	} {
+		if value := (timeout + unit.d - 1) / unit.d; value <= maxValue {
+			return strconv.FormatInt(int64(value), 10) + unit.s
+		}
+	}
+	return strconv.Itoa(maxValue) + "H"
+}
+
+// parseGRPCWebTrailers parses the trailers message of gRPC-Web, which are
+// formatted like HTTP/1 headers.
+func parseGRPCWebTrailers(data []byte) (metadata.MD, *status.Status, error) {
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(string(data) + "\r\n")))
+	header, err := reader.ReadMIMEHeader()
+	if err != nil && err != io.EOF { //nolint:errorlint // it's returned as is
+		return nil, nil, fmt.Errorf("invalid trailers: %w", err)
+	}
+	st, err := grpcStatusFromHeaders(http.Header(header))
+	if err != nil {
+		return nil, nil, err
+	}
+	return metadataFromHeaders(http.Header(header), ""), st, nil
+}
+
+// grpcStatusFromHeaders returns the status of the grpc-status, grpc-message and
+// grpc-status-details-bin headers or trailers, or nil if they aren't set.
+func grpcStatusFromHeaders(h http.Header) (*status.Status, error) {
+	code := h.Get("grpc-status")
+	if code == "" {
+		return nil, nil //nolint:nilnil // there isn't a status
+	}
+	if details := h.Get("grpc-status-details-bin"); details != "" {
+		data, err := decodeBinaryHeader(details)
+		if err != nil {
+			return nil, fmt.Errorf("invalid grpc-status-details-bin: %w", err)
+		}
+		st := &spb.Status{}
+		if err = proto.Unmarshal(data, st); err != nil {
+			return nil, fmt.Errorf("invalid grpc-status-details-bin: %w", err)
+		}
+		return status.FromProto(st), nil
+	}
+	value, err := strconv.ParseUint(code, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grpc-status '%s'", code)
+	}
+	message, err := url.PathUnescape(h.Get("grpc-message"))
+	if err != nil {
+		message = h.Get("grpc-message")
+	}
+	return status.New(codes.Code(value), message), nil
+}
+
+// codeFromHTTPStatus maps the HTTP status codes of the responses that don't
+// have a gRPC status, e.g. of a proxy, to the gRPC codes. It's the same
+// mapping for both gRPC-Web and the Connect protocol.
+func codeFromHTTPStatus(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.Internal
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.Unimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+// connectCodes maps the codes of the Connect protocol to the gRPC ones.
+//
+//nolint:gochecknoglobals
+var connectCodes = map[string]codes.Code{
+	"canceled":            codes.Canceled,
+	"unknown":             codes.Unknown,
+	"invalid_argument":    codes.InvalidArgument,
+	"deadline_exceeded":   codes.DeadlineExceeded,
+	"not_found":           codes.NotFound,
+	"already_exists":      codes.AlreadyExists,
+	"permission_denied":   codes.PermissionDenied,
+	"resource_exhausted":  codes.ResourceExhausted,
+	"failed_precondition": codes.FailedPrecondition,
+	"aborted":             codes.Aborted,
+	"out_of_range":        codes.OutOfRange,
+	"unimplemented":       codes.Unimplemented,
+	"internal":            codes.Internal,
+	"unavailable":         codes.Unavailable,
+	"data_loss":           codes.DataLoss,
+	"unauthenticated":     codes.Unauthenticated,
+}
+
+// connectError is the JSON error of the Connect protocol.
+type connectError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details []struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	} `json:"details"`
+}
+
+// status returns the gRPC status of the error, the details of which are
+// converted to the google.protobuf.Any messages.
+func (ce *connectError) status(fallback codes.Code) *status.Status {
+	code, ok := connectCodes[ce.Code]
+	if !ok {
+		code = fallback
+	}
+	st := &spb.Status{Code: int32(code), Message: ce.Message}
+	for _, detail := range ce.Details {
+		value, err := decodeBinaryHeader(detail.Value)
+		if err != nil {
+			continue
+		}
+		st.Details = append(st.Details, &anypb.Any{TypeUrl: "type.googleapis.com/" + detail.Type, Value: value})
+	}
+	return status.FromProto(st)
+}
+
+// connectEndStream is the last message of the streams of the Connect protocol.
+type connectEndStream struct {
+	Error    *connectError       `json:"error"`
+	Metadata map[string][]string `json:"metadata"`
+}
+
+// parseConnectEndStream parses the end of stream message of the Connect
+// protocol, with the status and the trailers of the stream.
+func parseConnectEndStream(data []byte) (metadata.MD, *status.Status, error) {
+	var end connectEndStream
+	if err := json.Unmarshal(data, &end); err != nil {
+		return nil, nil, fmt.Errorf("invalid end of stream message: %w", err)
+	}
+	st := status.New(codes.OK, "")
+	if end.Error != nil {
+		st = end.Error.status(codes.Unknown)
+	}
+	return metadataFromHeaders(end.Metadata, ""), st, nil
+}
@@ -178,7 +178,7 @@ func readResponseBody(
 	switch respType {
 	case ResponseTypeText:
 		result = buf.String()
-	case ResponseTypeBinary:
+	case ResponseTypeBinary, ResponseTypeStream:
 		// Copy the data to a new slice before we return the buffer to the pool,
 		// because buf.Bytes() points to the underlying buffer byte slice.
 		// The ArrayBuffer wrapping will be done in the js/modules/k6/http
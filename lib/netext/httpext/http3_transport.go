@@ -0,0 +1,317 @@
+package httpext
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/quic-go/quic-go/logging"
+	"golang.org/x/net/idna"
+
+	"go.k6.io/k6/lib/netext"
+)
+
+// HTTP3Proto is the value of the proto tag of the requests sent over HTTP/3.
+const HTTP3Proto = "HTTP/3"
+
+// HTTP3Transport is an http.RoundTripper that sends the requests over HTTP/3.
+//
+// The QUIC connections are dialed through the k6 netext.Dialer, so the DNS
+// resolver, the hosts overrides, the blocked hostnames and the blacklisted IPs
+// apply to them in the same way they do for the TCP ones. Since the QUIC
+// handshake combines the transport and the TLS handshakes, the time until the
+// first packet from the server is reported as connecting and the rest of the
+// handshake as TLS handshaking.
+type HTTP3Transport struct {
+	dialer    *netext.Dialer
+	transport *http3.RoundTripper
+
+	connsLock sync.Mutex
+	conns     map[string]*http3Conn
+}
+
+var _ http.RoundTripper = &HTTP3Transport{}
+
+// http3Conn is a QUIC connection to an authority, which other requests can
+// wait for while it's being dialed.
+type http3Conn struct {
+	ready chan struct{}
+	conn  quic.EarlyConnection
+	err   error
+}
+
+// http3Request is stored in the context of each request, so the dial function,
+// which only gets the context, can report the connection to its tracer.
+type http3Request struct {
+	trace     *httptrace.ClientTrace
+	gotConn   bool
+	hasNoBody bool
+}
+
+type http3RequestKey struct{}
+
+// NewHTTP3Transport returns a new HTTP3Transport that dials the QUIC
+// connections through the given dialer and uses the given TLS config for them.
+func NewHTTP3Transport(dialer *netext.Dialer, tlsConfig *tls.Config) *HTTP3Transport {
+	t := &HTTP3Transport{
+		dialer: dialer,
+		conns:  make(map[string]*http3Conn),
+	}
+	t.transport = &http3.RoundTripper{
+		TLSClientConfig:    tlsConfig,
+		DisableCompression: true,
+		Dial:               t.dial,
+	}
+	return t
+}
+
+// RoundTrip is the implementation of http.RoundTripper
+func (t *HTTP3Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := httptrace.WithClientTrace(req.Context(), noopClientTrace())
+	h3req := &http3Request{
+		trace:     httptrace.ContextClientTrace(ctx),
+		hasNoBody: req.Body == nil || req.Body == http.NoBody,
+	}
+	ctx = context.WithValue(ctx, http3RequestKey{}, h3req)
+	req = req.WithContext(ctx)
+	if !h3req.hasNoBody {
+		req.Body = &http3RequestBody{ReadCloser: req.Body, trace: h3req.trace}
+	}
+
+	addr := http3Authority(req.URL.Host)
+	h3req.trace.GetConn(addr)
+	if conn := t.waitConn(ctx, addr); conn != nil {
+		h3req.reportConn(conn, true)
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if !h3req.gotConn {
+		// Another request was dialing the connection, and it wasn't
+		// ready yet when we checked for it above.
+		if conn := t.waitConn(ctx, addr); conn != nil {
+			h3req.reportConn(conn, true)
+		}
+	}
+	h3req.trace.GotFirstResponseByte()
+
+	resp.Proto = HTTP3Proto
+	return resp, nil
+}
+
+// CloseIdleConnections closes the QUIC connections that aren't used by any
+// request at the moment.
+func (t *HTTP3Transport) CloseIdleConnections() {
+	t.transport.CloseIdleConnections()
+}
+
+// Close closes all the QUIC connections.
+func (t *HTTP3Transport) Close() error {
+	return t.transport.Close()
+}
+
+// waitConn returns the connection to addr, if there is one, waiting for it
+// if it's still being dialed by another request.
+func (t *HTTP3Transport) waitConn(ctx context.Context, addr string) quic.EarlyConnection {
+	t.connsLock.Lock()
+	c := t.conns[addr]
+	t.connsLock.Unlock()
+	if c == nil {
+		return nil
+	}
+
+	select {
+	case <-c.ready:
+	case <-ctx.Done():
+		return nil
+	}
+	if c.err != nil || c.conn.Context().Err() != nil {
+		return nil
+	}
+	return c.conn
+}
+
+func (t *HTTP3Transport) forgetConn(addr string, c *http3Conn) {
+	t.connsLock.Lock()
+	if t.conns[addr] == c {
+		delete(t.conns, addr)
+	}
+	t.connsLock.Unlock()
+}
+
+// dial is used by the http3.RoundTripper to open a new connection to addr,
+// with the context of the request that needs it.
+func (t *HTTP3Transport) dial(
+	ctx context.Context, addr string, tlsConfig *tls.Config, quicConfig *quic.Config,
+) (quic.EarlyConnection, error) {
+	h3req, ok := ctx.Value(http3RequestKey{}).(*http3Request)
+	if !ok {
+		h3req = &http3Request{trace: noopClientTrace()}
+	}
+
+	c := &http3Conn{ready: make(chan struct{})}
+	t.connsLock.Lock()
+	t.conns[addr] = c
+	t.connsLock.Unlock()
+
+	c.conn, c.err = t.dialConn(ctx, h3req.trace, addr, tlsConfig, quicConfig)
+	close(c.ready)
+	if c.err != nil {
+		t.forgetConn(addr, c)
+		return nil, c.err
+	}
+	go func() {
+		<-c.conn.Context().Done()
+		t.forgetConn(addr, c)
+	}()
+
+	if !h3req.gotConn {
+		h3req.reportConn(c.conn, false)
+	}
+	return c.conn, nil
+}
+
+func (t *HTTP3Transport) dialConn(
+	ctx context.Context, trace *httptrace.ClientTrace, addr string, tlsConfig *tls.Config, quicConfig *quic.Config,
+) (quic.EarlyConnection, error) {
+	packetConn, remoteAddr, err := t.dialer.ListenPacket(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	remote := remoteAddr.String()
+
+	// The first packet from the server ends the connecting phase, the same
+	// way the TCP handshake would, and starts the TLS handshaking one.
+	var connected sync.Once
+	trace.ConnectStart("udp", remote)
+	if quicConfig == nil {
+		quicConfig = &quic.Config{}
+	} else {
+		quicConfig = quicConfig.Clone()
+	}
+	quicConfig.Tracer = func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+		return &logging.ConnectionTracer{
+			ReceivedLongHeaderPacket: func(*logging.ExtendedHeader, logging.ByteCount, logging.ECN, []logging.Frame) {
+				connected.Do(func() {
+					trace.ConnectDone("udp", remote, nil)
+					trace.TLSHandshakeStart()
+				})
+			},
+		}
+	}
+
+	conn, err := quic.DialEarly(ctx, packetConn, remoteAddr, tlsConfig, quicConfig)
+	if err != nil {
+		_ = packetConn.Close()
+		connected.Do(func() { trace.ConnectDone("udp", remote, err) })
+		return nil, err
+	}
+	// quic-go doesn't close the packet connections it didn't create itself
+	go func() {
+		<-conn.Context().Done()
+		_ = packetConn.Close()
+	}()
+
+	select {
+	case <-conn.HandshakeComplete():
+	case <-conn.Context().Done():
+		return nil, errors.New("the QUIC connection was closed during the handshake")
+	case <-ctx.Done():
+		_ = conn.CloseWithError(0, "")
+		return nil, ctx.Err()
+	}
+	connected.Do(func() {
+		trace.ConnectDone("udp", remote, nil)
+		trace.TLSHandshakeStart()
+	})
+	trace.TLSHandshakeDone(conn.ConnectionState().TLS, nil)
+
+	return conn, nil
+}
+
+// reportConn calls the GotConn hook of the request, and the WroteRequest one
+// if the request has no body, since the headers are sent straight away.
+func (r *http3Request) reportConn(conn quic.EarlyConnection, reused bool) {
+	r.gotConn = true
+	r.trace.GotConn(httptrace.GotConnInfo{Conn: quicConnInfo{conn: conn}, Reused: reused})
+	if r.hasNoBody {
+		r.trace.WroteRequest(httptrace.WroteRequestInfo{})
+	}
+}
+
+// http3RequestBody calls the WroteRequest hook once the whole request body
+// has been read by the http3.RoundTripper.
+type http3RequestBody struct {
+	io.ReadCloser
+	trace *httptrace.ClientTrace
+	done  bool
+}
+
+func (b *http3RequestBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if !b.done && err != nil {
+		b.done = true
+		if errors.Is(err, io.EOF) {
+			b.trace.WroteRequest(httptrace.WroteRequestInfo{})
+		} else {
+			b.trace.WroteRequest(httptrace.WroteRequestInfo{Err: err})
+		}
+	}
+	return n, err
+}
+
+// quicConnInfo exposes the addresses of a QUIC connection as a net.Conn for
+// the httptrace.GotConnInfo; none of the other net.Conn methods are used.
+type quicConnInfo struct {
+	net.Conn
+	conn quic.EarlyConnection
+}
+
+func (c quicConnInfo) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+func (c quicConnInfo) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// noopClientTrace returns a trace with all the hooks HTTP3Transport calls, so
+// they don't have to be checked for nil when it's composed with another one.
+func noopClientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn:              func(string) {},
+		ConnectStart:         func(string, string) {},
+		ConnectDone:          func(string, string, error) {},
+		TLSHandshakeStart:    func() {},
+		TLSHandshakeDone:     func(tls.ConnectionState, error) {},
+		GotConn:              func(httptrace.GotConnInfo) {},
+		WroteRequest:         func(httptrace.WroteRequestInfo) {},
+		GotFirstResponseByte: func() {},
+	}
+}
+
+// http3Authority returns the host:port the http3.RoundTripper uses as the
+// key of its connections for the given URL host.
+func http3Authority(host string) string {
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname, port = host, "443"
+	}
+	if a, err := idna.ToASCII(hostname); err == nil {
+		hostname = a
+	}
+	if strings.HasPrefix(hostname, "[") && strings.HasSuffix(hostname, "]") {
+		return hostname + ":" + port
+	}
+	return net.JoinHostPort(hostname, port)
+}
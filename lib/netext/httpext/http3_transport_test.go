@@ -0,0 +1,172 @@
+package httpext
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+// newHTTP3TestServer starts an HTTP/3 server on localhost, with the
+// certificate of the httptest TLS servers, which is valid for example.com.
+// It returns the port of the server and a TLS config that trusts it.
+func newHTTP3TestServer(t *testing.T, handler http.Handler) (int, *tls.Config) {
+	t.Helper()
+
+	tlsSrv := httptest.NewTLSServer(handler)
+	t.Cleanup(tlsSrv.Close)
+	certPool := x509.NewCertPool()
+	certPool.AddCert(tlsSrv.Certificate())
+
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &http3.Server{
+		Handler:   handler,
+		TLSConfig: http3.ConfigureTLSConfig(&tls.Config{Certificates: tlsSrv.TLS.Certificates}), //nolint:gosec
+	}
+	go func() { _ = srv.Serve(packetConn) }()
+	t.Cleanup(func() {
+		_ = srv.Close()
+		_ = packetConn.Close()
+	})
+
+	return packetConn.LocalAddr().(*net.UDPAddr).Port, &tls.Config{RootCAs: certPool} //nolint:gosec
+}
+
+func newHTTP3TestState(t *testing.T, tlsConfig *tls.Config, blocked ...string) (*lib.State, chan metrics.SampleContainer) {
+	t.Helper()
+
+	hosts, err := types.NewHosts(map[string]types.Host{
+		"example.com":     {IP: net.ParseIP("127.0.0.1")},
+		"www.blocked.com": {IP: net.ParseIP("127.0.0.1")},
+	})
+	require.NoError(t, err)
+	blockedHostnames, err := types.NewHostnameTrie(blocked)
+	require.NoError(t, err)
+	dialer := &netext.Dialer{Hosts: hosts, BlockedHostnames: blockedHostnames}
+
+	http3Transport := NewHTTP3Transport(dialer, tlsConfig)
+	t.Cleanup(func() { _ = http3Transport.Close() })
+
+	samples := make(chan metrics.SampleContainer, 10)
+	registry := metrics.NewRegistry()
+	return &lib.State{
+		Options: lib.Options{
+			SystemTags: &metrics.DefaultSystemTagSet,
+		},
+		Transport:      http.DefaultTransport,
+		HTTP3Transport: http3Transport,
+		Dialer:         dialer,
+		Samples:        samples,
+		Logger:         logrus.New(),
+		BufferPool:     lib.NewBufferPool(),
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+	}, samples
+}
+
+func makeHTTP3Request(
+	t *testing.T, state *lib.State, samples chan metrics.SampleContainer, method, rawURL string, body []byte,
+) (*Response, *Trail, error) {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	req := &http.Request{Method: method, URL: u, Header: make(http.Header)}
+	preq := &ParsedHTTPRequest{
+		Req:          req,
+		URL:          &URL{u: u, URL: rawURL},
+		Body:         bytes.NewBuffer(body),
+		Timeout:      10 * time.Second,
+		ResponseType: ResponseTypeText,
+		HTTP3:        true,
+		TagsAndMeta:  state.Tags.GetCurrentValues(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	res, err := MakeRequest(ctx, state, preq)
+
+	trail, _ := (<-samples).(*Trail)
+	return res, trail, err
+}
+
+func TestHTTP3Transport(t *testing.T) {
+	t.Parallel()
+
+	port, tlsConfig := newHTTP3TestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(append([]byte(r.Proto+" "), body...))
+	}))
+	state, samples := newHTTP3TestState(t, tlsConfig)
+	rawURL := "https://example.com:" + strconv.Itoa(port) + "/"
+
+	res, trail, err := makeHTTP3Request(t, state, samples, http.MethodGet, rawURL, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 200, res.Status)
+	assert.Equal(t, HTTP3Proto, res.Proto)
+	assert.Equal(t, "HTTP/3.0 ", res.Body)
+	assert.Equal(t, netext.TLS_1_3, res.TLSVersion)
+	require.NotNil(t, trail)
+	assert.False(t, trail.ConnReused)
+	assert.Equal(t, "127.0.0.1:"+strconv.Itoa(port), trail.ConnRemoteAddr.String())
+	assert.Greater(t, trail.Connecting, time.Duration(0))
+	assert.Greater(t, trail.TLSHandshaking, time.Duration(0))
+	tags := trail.Tags.Map()
+	assert.Equal(t, HTTP3Proto, tags["proto"])
+	assert.Equal(t, "200", tags["status"])
+
+	res, trail, err = makeHTTP3Request(t, state, samples, http.MethodPost, rawURL, []byte("body"))
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/3.0 body", res.Body)
+	require.NotNil(t, trail)
+	assert.True(t, trail.ConnReused)
+	assert.Zero(t, trail.Connecting)
+	assert.Zero(t, trail.TLSHandshaking)
+	assert.Equal(t, HTTP3Proto, trail.Tags.Map()["proto"])
+}
+
+func TestHTTP3TransportBlockedHostname(t *testing.T) {
+	t.Parallel()
+
+	port, tlsConfig := newHTTP3TestServer(t, http.NotFoundHandler())
+	state, samples := newHTTP3TestState(t, tlsConfig, "*.blocked.com")
+
+	rawURL := "https://www.blocked.com:" + strconv.Itoa(port) + "/"
+	res, trail, err := makeHTTP3Request(t, state, samples, http.MethodGet, rawURL, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Status)
+	assert.Equal(t, "hostname is blocked", res.Error)
+	assert.Equal(t, int(blockedHostnameErrorCode), res.ErrorCode)
+	require.NotNil(t, trail)
+	assert.Equal(t, "0", trail.Tags.Map()["status"])
+}
+
+func TestHTTP3TransportUnavailable(t *testing.T) {
+	t.Parallel()
+
+	state, samples := newHTTP3TestState(t, &tls.Config{}) //nolint:gosec
+	state.HTTP3Transport = nil
+
+	res, _, err := makeHTTP3Request(t, state, samples, http.MethodGet, "https://example.com/", nil)
+	require.NoError(t, err)
+	assert.Contains(t, res.Error, errHTTP3Unavailable.Error())
+}
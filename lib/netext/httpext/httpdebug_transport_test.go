@@ -0,0 +1,50 @@
+package httpext
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/secretsource"
+)
+
+func TestHTTPDebugTransportRedactsSecrets(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	redactor := secretsource.NewRedactor()
+	redactor.AddHook(logger)
+	redactor.Add("s3cr3t-p4ss")
+
+	transport := httpDebugTransport{
+		originalTransport: http.DefaultTransport,
+		httpDebugOption:   "full",
+		logger:            logger,
+	}
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/?token=s3cr3t-p4ss", nil)
+	require.NoError(t, err)
+	req.SetBasicAuth("user", "s3cr3t-p4ss")
+	res, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	dump := buf.String()
+	assert.Contains(t, dump, "Authorization: Basic ")
+	assert.Contains(t, dump, secretsource.RedactedValue)
+	assert.NotContains(t, dump, "s3cr3t-p4ss")
+	assert.NotContains(t, dump, base64.StdEncoding.EncodeToString([]byte("user:s3cr3t-p4ss")))
+}
@@ -48,6 +48,7 @@ type ParsedHTTPRequest struct {
 	ResponseCallback func(int) bool
 	Compressions     []CompressionType
 	Redirects        null.Int
+	HTTP3            bool
 	ActiveJar        *cookiejar.Jar
 	Cookies          map[string]*HTTPRequestCookie
 	TagsAndMeta      metrics.TagsAndMeta
@@ -181,7 +182,7 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		}
 	}
 
-	tracerTransport := newTransport(ctx, state, &preq.TagsAndMeta, preq.ResponseCallback)
+	tracerTransport := newTransport(ctx, state, &preq.TagsAndMeta, preq.ResponseCallback, preq.HTTP3)
 	var transport http.RoundTripper = tracerTransport
 
 	if state.Options.HTTPDebug.String != "" {
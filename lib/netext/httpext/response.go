@@ -29,6 +29,11 @@ const (
 	// want to  measure, but we don't care about their responses' contents. This is the
 	// default value for all requests if the global discardResponseBodies is enablled.
 	ResponseTypeNone
+	// ResponseTypeStream causes k6 to return the response body as a ReadableStream
+	// of bytes, as defined by the k6/experimental/streams module. The body is still
+	// read by k6 as part of the request, but it's handed over to the script chunk by
+	// chunk, so large bodies don't need to be held in the JS runtime's memory at once.
+	ResponseTypeStream
 )
 
 // ResponseTimings is a struct to put all timings for a given HTTP response/request
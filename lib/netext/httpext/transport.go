@@ -22,6 +22,7 @@ type transport struct {
 	state            *lib.State
 	tagsAndMeta      *metrics.TagsAndMeta
 	responseCallback func(int) bool
+	http3            bool
 
 	lastRequest     *unfinishedRequest
 	lastRequestLock *sync.Mutex
@@ -54,18 +55,21 @@ var _ http.RoundTripper = &transport{}
 // newTransport returns a new http.RoundTripper implementation that wraps around
 // the provided state's Transport. It uses a httpext.Tracer to measure all HTTP
 // requests made through it and annotates and emits the recorded metric samples
-// through the state.Samples channel.
+// through the state.Samples channel. If http3 is true, the https requests are
+// sent through the state's HTTP3Transport instead.
 func newTransport(
 	ctx context.Context,
 	state *lib.State,
 	tagsAndMeta *metrics.TagsAndMeta,
 	responseCallback func(int) bool,
+	http3 bool,
 ) *transport {
 	return &transport{
 		ctx:              ctx,
 		state:            state,
 		tagsAndMeta:      tagsAndMeta,
 		responseCallback: responseCallback,
+		http3:            http3,
 		lastRequestLock:  new(sync.Mutex),
 	}
 }
@@ -197,6 +201,18 @@ func (t *transport) processLastSavedRequest(lastErr error) *finishedRequest {
 	return nil
 }
 
+var errHTTP3Unavailable = errors.New("HTTP/3 isn't available in this context")
+
+// roundTripper returns the underlying http.RoundTripper for the request. Only
+// the https requests can be sent over HTTP/3, so redirects to plain http ones
+// still go through the state's Transport.
+func (t *transport) roundTripper(req *http.Request) http.RoundTripper {
+	if t.http3 && req.URL.Scheme == "https" {
+		return t.state.HTTP3Transport
+	}
+	return t.state.Transport
+}
+
 // RoundTrip is the implementation of http.RoundTripper
 func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	t.processLastSavedRequest(nil)
@@ -204,7 +220,13 @@ func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	ctx := req.Context()
 	tracer := &Tracer{}
 	reqWithTracer := req.WithContext(httptrace.WithClientTrace(ctx, tracer.Trace()))
-	resp, err := t.state.Transport.RoundTrip(reqWithTracer)
+	var resp *http.Response
+	var err error
+	if roundTripper := t.roundTripper(req); roundTripper != nil {
+		resp, err = roundTripper.RoundTrip(reqWithTracer)
+	} else {
+		err = errHTTP3Unavailable
+	}
 
 	var netError net.Error
 	if errors.As(err, &netError) && netError.Timeout() {
@@ -17,6 +17,12 @@ type Resolver interface {
 	LookupIP(host string) (net.IP, error)
 }
 
+// allIPsResolver is a Resolver that can also return all the IPs of a host,
+// instead of a single selected one.
+type allIPsResolver interface {
+	LookupIPAll(host string) ([]net.IP, error)
+}
+
 type resolver struct {
 	resolve     MultiResolver
 	selectIndex types.DNSSelect
@@ -76,11 +82,34 @@ func (r *resolver) LookupIP(host string) (net.IP, error) {
 	return r.selectOne(host, ips), nil
 }
 
+// LookupIPAll returns all the IPs resolved for host, filtered according to
+// the configured policy option.
+func (r *resolver) LookupIPAll(host string) ([]net.IP, error) {
+	ips, err := r.resolve(host)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.applyPolicy(ips), nil
+}
+
 // LookupIP returns a single IP resolved for host, selected according to the
 // configured select and policy options. Results are cached per host and will be
 // refreshed if the last lookup time exceeds the configured TTL (not the TTL
 // returned in the DNS record).
 func (r *cacheResolver) LookupIP(host string) (net.IP, error) {
+	ips, err := r.LookupIPAll(host)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.selectOne(host, ips), nil
+}
+
+// LookupIPAll returns all the IPs resolved for host, filtered according to
+// the configured policy option. Results are cached the same way as the ones
+// of LookupIP().
+func (r *cacheResolver) LookupIPAll(host string) ([]net.IP, error) {
 	r.cm.Lock()
 
 	var ips []net.IP
@@ -101,7 +130,7 @@ func (r *cacheResolver) LookupIP(host string) (net.IP, error) {
 
 	r.cm.Unlock()
 
-	return r.selectOne(host, ips), nil
+	return ips, nil
 }
 
 func (r *resolver) selectOne(host string, ips []net.IP) net.IP {
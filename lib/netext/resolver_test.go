@@ -96,4 +96,39 @@ func TestResolver(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("LookupIPAll", func(t *testing.T) {
+		t.Parallel()
+		testCases := []struct {
+			ttl    time.Duration
+			pol    types.DNSPolicy
+			expIPs []net.IP
+		}{
+			{0, types.DNSonlyIPv4, []net.IP{
+				net.ParseIP("127.0.0.10"),
+				net.ParseIP("127.0.0.11"),
+				net.ParseIP("127.0.0.12"),
+			}},
+			{time.Second, types.DNSpreferIPv6, []net.IP{
+				net.ParseIP("2001:db8::10"),
+				net.ParseIP("2001:db8::11"),
+				net.ParseIP("2001:db8::12"),
+			}},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(fmt.Sprintf("%s_%s", tc.ttl, tc.pol), func(t *testing.T) {
+				t.Parallel()
+				r := NewResolver(mr.LookupIPAll, tc.ttl, types.DNSroundRobin, tc.pol)
+				ar, ok := r.(allIPsResolver)
+				require.True(t, ok)
+				for i := 0; i < 2; i++ {
+					ips, err := ar.LookupIPAll(host)
+					require.NoError(t, err)
+					assert.Equal(t, tc.expIPs, ips)
+				}
+			})
+		}
+	})
 }
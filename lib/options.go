@@ -298,6 +298,9 @@ type Options struct {
 	// Disable keep-alive connections
 	NoConnectionReuse null.Bool `json:"noConnectionReuse" envconfig:"K6_NO_CONNECTION_REUSE"`
 
+	// Send the https requests over HTTP/3 (QUIC) instead of HTTP/1.1 or HTTP/2.
+	HTTP3 null.Bool `json:"http3" envconfig:"K6_HTTP3"`
+
 	// Do not reuse connections between VU iterations. This gives more realistic results (depending
 	// on what you're looking for), but you need to raise various kernel limits or you'll get
 	// errors about running out of file handles or sockets, or being unable to bind addresses.
@@ -321,6 +324,10 @@ type Options struct {
 	// Summary time unit for summary metrics (response times) in CLI output
 	SummaryTimeUnit null.String `json:"summaryTimeUnit" envconfig:"K6_SUMMARY_TIME_UNIT"`
 
+	// Which kind of sink to use for the trend metrics, either "values" (the
+	// default) or "histogram", which has a bounded memory usage
+	TrendSink null.String `json:"trendSink" envconfig:"K6_TREND_SINK"`
+
 	// Which system tags to include with metrics ("method", "vu" etc.)
 	// Use pointer for identifying whether user provide any tag or not.
 	SystemTags *metrics.SystemTagSet `json:"systemTags" envconfig:"K6_SYSTEM_TAGS"`
@@ -463,6 +470,9 @@ func (o Options) Apply(opts Options) Options {
 	if opts.NoConnectionReuse.Valid {
 		o.NoConnectionReuse = opts.NoConnectionReuse
 	}
+	if opts.HTTP3.Valid {
+		o.HTTP3 = opts.HTTP3
+	}
 	if opts.NoVUConnectionReuse.Valid {
 		o.NoVUConnectionReuse = opts.NoVUConnectionReuse
 	}
@@ -484,6 +494,9 @@ func (o Options) Apply(opts Options) Options {
 	if opts.SummaryTimeUnit.Valid {
 		o.SummaryTimeUnit = opts.SummaryTimeUnit
 	}
+	if opts.TrendSink.Valid {
+		o.TrendSink = opts.TrendSink
+	}
 	if opts.SystemTags != nil {
 		o.SystemTags = opts.SystemTags
 	}
@@ -534,6 +547,11 @@ func (o Options) Validate() []error {
 					o.ExecutionSegment, o.ExecutionSegmentSequence))
 		}
 	}
+	if o.TrendSink.Valid && o.TrendSink.String != metrics.TrendSinkValues &&
+		o.TrendSink.String != metrics.TrendSinkHistogram {
+		errors = append(errors, fmt.Errorf("invalid trend sink '%s', use '%s' or '%s'",
+			o.TrendSink.String, metrics.TrendSinkValues, metrics.TrendSinkHistogram))
+	}
 	return append(errors, o.Scenarios.Validate()...)
 }
 
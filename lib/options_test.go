@@ -405,6 +405,12 @@ func TestOptions(t *testing.T) {
 		assert.True(t, opts.NoConnectionReuse.Valid)
 		assert.True(t, opts.NoConnectionReuse.Bool)
 	})
+	t.Run("HTTP3", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{HTTP3: null.BoolFrom(true)})
+		assert.True(t, opts.HTTP3.Valid)
+		assert.True(t, opts.HTTP3.Bool)
+	})
 	t.Run("NoVUConnectionReuse", func(t *testing.T) {
 		t.Parallel()
 		opts := Options{}.Apply(Options{NoVUConnectionReuse: null.BoolFrom(true)})
@@ -543,6 +549,17 @@ func TestOptions(t *testing.T) {
 		opts := Options{}.Apply(Options{SummaryTrendStats: stats})
 		assert.Equal(t, stats, opts.SummaryTrendStats)
 	})
+	t.Run("TrendSink", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{TrendSink: null.StringFrom("histogram")})
+		assert.Equal(t, null.StringFrom("histogram"), opts.TrendSink)
+		assert.Empty(t, opts.Validate())
+
+		opts = Options{}.Apply(Options{TrendSink: null.StringFrom("tdigest")})
+		errs := opts.Validate()
+		require.Len(t, errs, 1)
+		assert.ErrorContains(t, errs[0], "invalid trend sink 'tdigest'")
+	})
 	t.Run("RunTags", func(t *testing.T) {
 		t.Parallel()
 		tags := map[string]string{"myTag": "hello"}
@@ -634,6 +651,11 @@ func TestOptionsEnv(t *testing.T) {
 			"true":  null.BoolFrom(true),
 			"false": null.BoolFrom(false),
 		},
+		{"HTTP3", "K6_HTTP3"}: {
+			"":      null.Bool{},
+			"true":  null.BoolFrom(true),
+			"false": null.BoolFrom(false),
+		},
 		{"NoVUConnectionReuse", "K6_NO_VU_CONNECTION_REUSE"}: {
 			"":      null.Bool{},
 			"true":  null.BoolFrom(true),
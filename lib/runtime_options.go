@@ -44,6 +44,14 @@ type RuntimeOptions struct {
 	SummaryExport null.String `json:"summaryExport"`
 	KeyWriter     null.String `json:"-"`
 	TracesOutput  null.String `json:"tracesOutput"`
+
+	// Directory the k6/experimental/fs module is allowed to write files to. It's
+	// never part of an archive, as the tests run from archives can't write files.
+	FSOutputDir null.String `json:"-"`
+
+	// Secret sources of the k6/secrets module, as $type=$argument strings. They're
+	// never part of an archive, neither are the secrets, which are local to the run.
+	SecretSources []string `json:"-"`
 }
 
 // ValidateCompatibilityMode checks if the provided val is a valid compatibility mode
@@ -7,8 +7,10 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"go.k6.io/k6/event"
+	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/lib/trace"
 	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/secretsource"
 )
 
 // TestPreInitState contains all of the state that can be gathered and built
@@ -22,6 +24,15 @@ type TestPreInitState struct {
 	LookupEnv      func(key string) (val string, ok bool)
 	Logger         logrus.FieldLogger
 	TracerProvider *trace.TracerProvider
+
+	// OutputFS is the file system of the output directory, where the
+	// k6/experimental/fs module writes its files. It's nil when no output
+	// directory is configured, e.g. when running an archive.
+	OutputFS fsext.Fs
+
+	// SecretsManager hands out the secrets of the k6/secrets module, it's nil
+	// when no secret source is configured.
+	SecretsManager *secretsource.Manager
 }
 
 // TestRunState contains the pre-init state as well as all of the state and
@@ -35,10 +46,28 @@ type TestRunState struct {
 
 	GroupSummary *GroupSummary // TODO(@mstoykov): move and rename
 
+	// LiveMetrics gives the executors access to the metric samples while the
+	// test is running. It's nil if k6 doesn't process the metrics, e.g. when
+	// both the end-of-test summary and the thresholds are disabled.
+	LiveMetrics LiveMetricsSource
+
+	// FlushMetrics synchronously sends the metric samples emitted so far to the
+	// outputs and to LiveMetrics. It's nil if the outputs haven't been started.
+	FlushMetrics func()
+
 	// TODO: add other properties that are computed or derived after init, e.g.
 	// thresholds?
 }
 
+// LiveMetricsSource is implemented by the metrics engine, so the executors can
+// adjust the test execution based on the metric samples during the test run.
+type LiveMetricsSource interface {
+	// ObserveMetric starts aggregating the samples of the given metric or
+	// sub-metric (e.g. `http_req_duration{status:200}`). Every call of the
+	// returned function returns a sink with the samples since its previous call.
+	ObserveMetric(name string) (func() metrics.Sink, error)
+}
+
 // GroupSummaryDescription is the description of the GroupSummary used to identify and ignore it
 // for the purposes of the cli descriptions.
 const GroupSummaryDescription = "Internal Group Summary output"
@@ -51,6 +51,10 @@ type State struct {
 	CookieJar *cookiejar.Jar
 	TLSConfig *tls.Config
 
+	// HTTP3Transport is used instead of Transport for the https requests that
+	// opted in HTTP/3, either with the global http3 option or per request.
+	HTTP3Transport http.RoundTripper
+
 	// Rate limits.
 	RPSLimit *rate.Limiter
 
@@ -31,6 +31,13 @@ type MetricsEngine struct {
 	metricsWithThresholds   []*metrics.Metric
 	breachedThresholdsCount uint32
 
+	// Only set if the per-time-series aggregation was enabled
+	timeSeriesSinks *timeSeriesSinks
+
+	// The sinks of the metrics that are observed during the test run, e.g. by
+	// the executors, see ObserveMetric()
+	liveSinks map[*metrics.Metric][]*liveSink
+
 	// TODO: completely refactor:
 	//   - make these private, add a method to export the raw data
 	//   - do not use an unnecessary map for the observed metrics
@@ -118,6 +125,12 @@ func (me *MetricsEngine) markObserved(metric *metrics.Metric) {
 // initializes both the thresholds themselves, as well as any submetrics that
 // were referenced in them.
 func (me *MetricsEngine) InitSubMetricsAndThresholds(options lib.Options, onlyLogErrors bool) error {
+	// This has to happen before any of the sub-metrics are created and before
+	// any samples are ingested, since it replaces the existing Trend sinks.
+	if options.TrendSink.String == metrics.TrendSinkHistogram {
+		me.registry.UseHistogramTrendSinks()
+	}
+
 	for metricName, thresholds := range options.Thresholds {
 		metric, err := me.getThresholdMetricOrSubmetric(metricName)
 
@@ -158,15 +171,13 @@ func (me *MetricsEngine) InitSubMetricsAndThresholds(options lib.Options, onlyLo
 
 // StartThresholdCalculations spins up a new goroutine to crunch thresholds and
 // returns a callback that will stop the goroutine and finalizes calculations.
+// It's started even if no thresholds were defined, since they can be added
+// during the test run, see SetThresholds().
 func (me *MetricsEngine) StartThresholdCalculations(
 	ingester *OutputIngester,
 	abortRun func(error),
 	getCurrentTestRunDuration func() time.Duration,
 ) (finalize func() (breached []string)) {
-	if len(me.metricsWithThresholds) == 0 {
-		return nil // no thresholds were defined
-	}
-
 	stop := make(chan struct{})
 	done := make(chan struct{})
 
@@ -185,6 +196,7 @@ func (me *MetricsEngine) StartThresholdCalculations(
 						strings.Join(breached, ", "),
 					)
 					me.logger.Debug(err.Error())
+					me.logFailedWindows()
 					err = errext.WithAbortReasonIfNone(
 						errext.WithExitCodeIfNone(err, exitcodes.ThresholdsHaveFailed), errext.AbortedByThreshold,
 					)
@@ -208,10 +220,35 @@ func (me *MetricsEngine) StartThresholdCalculations(
 		<-done
 
 		breached, _ := me.evaluateThresholds(false, getCurrentTestRunDuration)
+		me.logFailedWindows()
 		return breached
 	}
 }
 
+// logFailedWindows reports the time windows in which the windowed thresholds
+// (e.g. `p(95)<300 over 1m`) have failed, since they are not visible in the
+// end-of-test summary.
+func (me *MetricsEngine) logFailedWindows() {
+	me.MetricsLock.Lock()
+	defer me.MetricsLock.Unlock()
+
+	for _, m := range me.metricsWithThresholds {
+		for _, threshold := range m.Thresholds.Thresholds {
+			if len(threshold.FailedWindows) == 0 {
+				continue
+			}
+			windows := make([]string, len(threshold.FailedWindows))
+			for i, w := range threshold.FailedWindows {
+				windows[i] = w.String()
+			}
+			me.logger.WithField("metric_name", m.Name).Warnf(
+				"Threshold '%s' on metric '%s' failed in the time windows %s",
+				threshold.Source, m.Name, strings.Join(windows, ", "),
+			)
+		}
+	}
+}
+
 // evaluateThresholds processes all of the thresholds.
 //
 // TODO: refactor, optimize
@@ -222,6 +259,10 @@ func (me *MetricsEngine) evaluateThresholds(
 	me.MetricsLock.Lock()
 	defer me.MetricsLock.Unlock()
 
+	if len(me.metricsWithThresholds) == 0 {
+		atomic.StoreUint32(&me.breachedThresholdsCount, 0)
+		return nil, false
+	}
 	t := getCurrentTestRunDuration()
 
 	me.logger.Debugf("Running thresholds on %d metrics...", len(me.metricsWithThresholds))
@@ -6,6 +6,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/testutils"
 	"go.k6.io/k6/metrics"
@@ -41,6 +43,38 @@ func TestNewMetricsEngineWithThresholds(t *testing.T) {
 	assert.Len(t, me.metricsWithThresholds, 2)
 }
 
+func TestMetricsEngineHistogramTrendSinks(t *testing.T) {
+	t.Parallel()
+
+	me := newTestMetricsEngine(t)
+	trend, err := me.registry.NewMetric("trend1", metrics.Trend)
+	require.NoError(t, err)
+	counter, err := me.registry.NewMetric("counter1", metrics.Counter)
+	require.NoError(t, err)
+
+	opts := lib.Options{
+		TrendSink: null.StringFrom(metrics.TrendSinkHistogram),
+		Thresholds: map[string]metrics.Thresholds{
+			"trend1{tag:value}": {Thresholds: []*metrics.Threshold{}},
+		},
+	}
+	require.NoError(t, me.InitSubMetricsAndThresholds(opts, false))
+
+	for _, m := range []*metrics.Metric{trend, me.metricsWithThresholds[0]} {
+		sink, ok := m.Sink.(*metrics.TrendSink)
+		require.True(t, ok)
+		for i := 0; i < 1000; i++ {
+			sink.Add(metrics.Sample{Value: float64(i)})
+		}
+		assert.InEpsilon(t, 949.05, sink.P(.95), .01)
+	}
+	assert.IsType(t, &metrics.CounterSink{}, counter.Sink)
+
+	newTrend, err := me.registry.NewMetric("trend2", metrics.Trend)
+	require.NoError(t, err)
+	assert.Equal(t, newTrend.Sink, metrics.NewHistogramTrendSink())
+}
+
 func TestMetricsEngineGetThresholdMetricOrSubmetricError(t *testing.T) {
 	t.Parallel()
 
@@ -153,6 +187,185 @@ func TestMetricsEngineEvaluateIgnoreEmptySink(t *testing.T) {
 	assert.Empty(t, breached)
 }
 
+func TestMetricsEngineEvaluateWindowedThresholds(t *testing.T) {
+	t.Parallel()
+
+	me := newTestMetricsEngine(t)
+	m1, err := me.registry.NewMetric("m1", metrics.Trend)
+	require.NoError(t, err)
+
+	ths := metrics.NewThresholds([]string{"p(95)<300 over 10s"})
+	require.NoError(t, ths.Parse())
+	ths.Thresholds[0].AbortOnFail = true
+	require.NoError(t, me.InitSubMetricsAndThresholds(lib.Options{
+		Thresholds: map[string]metrics.Thresholds{"m1": ths},
+	}, false))
+
+	ingester := me.CreateIngester()
+	require.NoError(t, ingester.Start())
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		ingester.AddMetricSamples([]metrics.SampleContainer{
+			metrics.Sample{TimeSeries: metrics.TimeSeries{Metric: m1}, Time: now.Add(-time.Minute), Value: 100},
+			metrics.Sample{TimeSeries: metrics.TimeSeries{Metric: m1}, Time: now.Add(-time.Second), Value: 1000},
+		})
+	}
+	require.NoError(t, ingester.Stop())
+
+	testRunDuration := func() time.Duration { return 2 * time.Minute }
+	breached, abort := me.evaluateThresholds(true, testRunDuration)
+	assert.True(t, abort)
+	assert.Equal(t, []string{"m1"}, breached)
+	assert.Equal(t,
+		[]metrics.ThresholdWindow{{From: 110 * time.Second, To: 2 * time.Minute}},
+		m1.Thresholds.Thresholds[0].FailedWindows,
+	)
+}
+
+func TestMetricsEngineObserveMetric(t *testing.T) {
+	t.Parallel()
+
+	me := newTestMetricsEngine(t)
+	m1, err := me.registry.NewMetric("m1", metrics.Rate)
+	require.NoError(t, err)
+
+	_, err = me.ObserveMetric("m2")
+	require.ErrorContains(t, err, "metric 'm2' does not exist in the script")
+	readAll, err := me.ObserveMetric("m1")
+	require.NoError(t, err)
+	readSub, err := me.ObserveMetric("m1{tag:a}")
+	require.NoError(t, err)
+
+	ingester := me.CreateIngester()
+	require.NoError(t, ingester.Start())
+	addSamples := func(tag string, values ...float64) {
+		for _, v := range values {
+			ingester.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+				TimeSeries: metrics.TimeSeries{Metric: m1, Tags: me.registry.RootTagSet().With("tag", tag)},
+				Value:      v,
+			}})
+		}
+		ingester.flushMetrics()
+	}
+
+	addSamples("a", 1, 1, 0)
+	addSamples("b", 0)
+	assert.Equal(t, &metrics.RateSink{Trues: 2, Total: 4}, readAll())
+	assert.Equal(t, &metrics.RateSink{Trues: 2, Total: 3}, readSub())
+
+	addSamples("a", 0)
+	assert.Equal(t, &metrics.RateSink{Trues: 0, Total: 1}, readAll())
+	assert.Equal(t, &metrics.RateSink{Trues: 0, Total: 1}, readSub())
+	assert.True(t, readAll().IsEmpty())
+	require.NoError(t, ingester.Stop())
+
+	// the main sinks are not affected
+	assert.Equal(t, &metrics.RateSink{Trues: 2, Total: 5}, m1.Sink)
+
+	// observing a sub-metric doesn't add it to the metric and the summary
+	assert.Empty(t, m1.Submetrics)
+	assert.NotContains(t, me.ObservedMetrics, "m1{tag:a}")
+}
+
+func TestMetricsEngineObserveMetricWithStop(t *testing.T) {
+	t.Parallel()
+
+	me := newTestMetricsEngine(t)
+	m1, err := me.registry.NewMetric("m1", metrics.Rate)
+	require.NoError(t, err)
+
+	_, _, err = me.ObserveMetricWithStop("m2")
+	require.ErrorContains(t, err, "metric 'm2' does not exist in the script")
+	readAll, err := me.ObserveMetric("m1")
+	require.NoError(t, err)
+	readStopped, stop, err := me.ObserveMetricWithStop("m1")
+	require.NoError(t, err)
+	_, stopSub, err := me.ObserveMetricWithStop("m1{tag:a}")
+	require.NoError(t, err)
+	_, _, err = me.ObserveMetricWithStop("m1{tag:a")
+	require.ErrorContains(t, err, "missing ending bracket")
+
+	ingester := me.CreateIngester()
+	require.NoError(t, ingester.Start())
+	addSamples := func(values ...float64) {
+		for _, v := range values {
+			ingester.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+				TimeSeries: metrics.TimeSeries{Metric: m1, Tags: me.registry.RootTagSet()},
+				Value:      v,
+			}})
+		}
+		ingester.flushMetrics()
+	}
+
+	addSamples(1)
+	stop()
+	addSamples(1, 0)
+	assert.Equal(t, &metrics.RateSink{Trues: 1, Total: 1}, readStopped())
+	assert.True(t, readStopped().IsEmpty())
+	assert.Equal(t, &metrics.RateSink{Trues: 2, Total: 3}, readAll())
+	require.NoError(t, ingester.Stop())
+
+	// the main sinks are not affected
+	assert.Equal(t, &metrics.RateSink{Trues: 2, Total: 3}, m1.Sink)
+
+	// stopping an observer of a sub-metric only removes its own live sink
+	stopSub()
+	assert.Len(t, me.liveSinks[m1], 1)
+	assert.Empty(t, m1.Submetrics)
+}
+
+func TestMetricsEngineSetAndDeleteThresholds(t *testing.T) {
+	t.Parallel()
+
+	me := newTestMetricsEngine(t)
+	m1, err := me.registry.NewMetric("m1", metrics.Counter)
+	require.NoError(t, err)
+	require.NoError(t, me.InitSubMetricsAndThresholds(lib.Options{}, false))
+	assert.False(t, me.HasThresholds())
+
+	_, err = me.SetThresholds("m2", metrics.NewThresholds([]string{"count<5"}))
+	assert.ErrorContains(t, err, `no metric name "m2" found`)
+	_, err = me.SetThresholds("m1", metrics.NewThresholds([]string{"p(95)<5"}))
+	assert.ErrorContains(t, err, "unsupported aggregation method")
+	_, err = me.SetThresholds("m1", metrics.NewThresholds([]string{"count<"}))
+	assert.ErrorContains(t, err, "invalid thresholds on metric 'm1'")
+
+	name, err := me.SetThresholds("m1{tag:a}", metrics.NewThresholds([]string{"count<5"}))
+	require.NoError(t, err)
+	assert.Equal(t, "m1{tag:a}", name)
+	_, err = me.SetThresholds("m1", metrics.NewThresholds([]string{"count<5"}))
+	require.NoError(t, err)
+	assert.True(t, me.HasThresholds())
+	assert.Contains(t, me.ObservedMetrics, "m1{tag:a}")
+
+	m1.Sink.Add(metrics.Sample{Value: 6})
+	breached, _ := me.evaluateThresholds(false, zeroTestRunDuration)
+	assert.Equal(t, []string{"m1"}, breached)
+
+	// replacing the thresholds changes the result of the next evaluation
+	_, err = me.SetThresholds("m1", metrics.NewThresholds([]string{"count<10"}))
+	require.NoError(t, err)
+	breached, _ = me.evaluateThresholds(false, zeroTestRunDuration)
+	assert.Empty(t, breached)
+
+	thresholds := me.GetThresholds()
+	require.Len(t, thresholds, 2)
+	assert.Equal(t, "count<10", thresholds["m1"].Thresholds[0].Source)
+	assert.Equal(t, "count<5", thresholds["m1{tag:a}"].Thresholds[0].Source)
+
+	name, err = me.DeleteThresholds("m1")
+	require.NoError(t, err)
+	assert.Equal(t, "m1", name)
+	_, err = me.DeleteThresholds("m1")
+	assert.ErrorIs(t, err, ErrNoThresholds)
+	_, _, err = me.GetMetricThresholds("m1")
+	assert.ErrorIs(t, err, ErrNoThresholds)
+	_, thresholdsA, err := me.GetMetricThresholds("m1{tag:a}")
+	require.NoError(t, err)
+	assert.Len(t, thresholdsA.Thresholds, 1)
+	assert.Len(t, me.GetThresholds(), 1)
+}
+
 func newTestMetricsEngine(t *testing.T) *MetricsEngine {
 	m, err := NewMetricsEngine(metrics.NewRegistry(), testutils.NewLogger(t))
 	require.NoError(t, err)
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -13,7 +14,7 @@ const (
 	timeSeriesFirstLimit = 100_000
 )
 
-var _ output.Output = &OutputIngester{}
+var _ output.WithFlush = &OutputIngester{}
 
 // IngesterDescription is a short description for ingester.
 // This variable is used from a function in cmd/ui file for matching this output
@@ -29,6 +30,10 @@ type OutputIngester struct {
 	metricsEngine   *MetricsEngine
 	periodicFlusher *output.PeriodicFlusher
 	cardinality     *cardinalityControl
+
+	// flushLock makes sure the samples taken from the buffer by a periodic
+	// flush are processed before the ones taken by Flush(), and before it returns
+	flushLock sync.Mutex
 }
 
 // Description returns a human-readable description of the output.
@@ -58,8 +63,16 @@ func (oi *OutputIngester) Stop() error {
 	return nil
 }
 
+// Flush synchronously writes the buffered samples to the MetricsEngine.
+func (oi *OutputIngester) Flush() {
+	oi.flushMetrics()
+}
+
 // flushMetrics Writes samples to the MetricsEngine
 func (oi *OutputIngester) flushMetrics() {
+	oi.flushLock.Lock()
+	defer oi.flushLock.Unlock()
+
 	sampleContainers := oi.GetBufferedSamples()
 	if len(sampleContainers) == 0 {
 		return
@@ -88,6 +101,8 @@ func (oi *OutputIngester) flushMetrics() {
 			m := sample.Metric               // this should have come from the Registry, no need to look it up
 			oi.metricsEngine.markObserved(m) // mark it as observed so it shows in the end-of-test summary
 			m.Sink.Add(sample)               // finally, add its value to its own sink
+			m.Thresholds.AddSample(sample)   // and to the windowed thresholds, if there are any
+			oi.metricsEngine.addToLiveSinks(sample)
 
 			// and also to the same for any submetrics that match the metric sample
 			for _, sm := range m.Submetrics {
@@ -96,9 +111,18 @@ func (oi *OutputIngester) flushMetrics() {
 				}
 				oi.metricsEngine.markObserved(sm.Metric)
 				sm.Metric.Sink.Add(sample)
+				sm.Metric.Thresholds.AddSample(sample)
 			}
 
 			oi.cardinality.Add(sample.TimeSeries)
+
+			if tss := oi.metricsEngine.timeSeriesSinks; tss != nil && tss.add(sample) {
+				oi.logger.Warnf(
+					"The limit of %d time series with separately aggregated metrics was hit, the samples "+
+						"of any new time series will be aggregated together, tagged with %s=true",
+					tss.limit, OverflowTagName,
+				)
+			}
 		}
 	}
 
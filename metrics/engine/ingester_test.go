@@ -205,3 +205,31 @@ func newTestPreInitState(tb testing.TB) *lib.TestPreInitState {
 		BuiltinMetrics: metrics.RegisterBuiltinMetrics(reg),
 	}
 }
+
+func TestIngesterOutputFlush(t *testing.T) {
+	t.Parallel()
+
+	piState := newTestPreInitState(t)
+	testMetric, err := piState.Registry.NewMetric("test_metric", metrics.Counter)
+	require.NoError(t, err)
+
+	ingester := OutputIngester{
+		logger: piState.Logger,
+		metricsEngine: &MetricsEngine{
+			ObservedMetrics: make(map[string]*metrics.Metric),
+		},
+		cardinality: newCardinalityControl(),
+	}
+	ingester.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+		TimeSeries: metrics.TimeSeries{Metric: testMetric},
+		Value:      21,
+	}})
+
+	// it doesn't need the periodic flusher to be started
+	ingester.Flush()
+
+	metric := ingester.metricsEngine.ObservedMetrics["test_metric"]
+	require.NotNil(t, metric)
+	assert.Equal(t, 21.0, metric.Sink.(*metrics.CounterSink).Value)
+	assert.Empty(t, ingester.GetBufferedSamples())
+}
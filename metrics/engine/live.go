@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+var _ lib.LiveMetricsSource = &MetricsEngine{}
+
+// liveSink aggregates the samples of a metric since the last time it was read.
+// For a sub-metric, it's a live sink of the parent metric that only aggregates
+// the samples with the sub-metric's tags.
+type liveSink struct {
+	tags *metrics.TagSet
+	sink metrics.Sink
+}
+
+// ObserveMetric starts aggregating the samples of the given metric or
+// sub-metric (e.g. `http_req_duration{status:200}`) in a separate sink. Every
+// call of the returned function returns the samples that were ingested since
+// its previous call, or since ObserveMetric() was called for the first one.
+//
+// It can be called while the samples are being ingested, which is how the
+// executors use it to make decisions based on the current metric values.
+func (me *MetricsEngine) ObserveMetric(name string) (func() metrics.Sink, error) {
+	read, _, err := me.ObserveMetricWithStop(name)
+	return read, err
+}
+
+// ObserveMetricWithStop is like ObserveMetric(), but it also returns a
+// function that stops the aggregation of the samples, for the observers that
+// don't last for the whole test run, e.g. the REST API's metrics stream.
+//
+// Unlike thresholds, observing a sub-metric doesn't add it to the metric, so
+// it doesn't show in the end-of-test summary and it isn't kept once stopped.
+func (me *MetricsEngine) ObserveMetricWithStop(name string) (read func() metrics.Sink, stop func(), err error) {
+	me.MetricsLock.Lock()
+	defer me.MetricsLock.Unlock()
+
+	metric, tags, err := me.getMetricAndSubmetricTags(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ls := &liveSink{tags: tags, sink: metrics.NewSink(metric.Type)}
+	if me.liveSinks == nil {
+		me.liveSinks = make(map[*metrics.Metric][]*liveSink)
+	}
+	me.liveSinks[metric] = append(me.liveSinks[metric], ls)
+
+	read = func() metrics.Sink {
+		me.MetricsLock.Lock()
+		defer me.MetricsLock.Unlock()
+
+		sink := ls.sink
+		ls.sink = metrics.NewSink(metric.Type)
+		return sink
+	}
+	stop = func() {
+		me.MetricsLock.Lock()
+		defer me.MetricsLock.Unlock()
+
+		sinks := me.liveSinks[metric]
+		for i, s := range sinks {
+			if s != ls {
+				continue
+			}
+			if len(sinks) == 1 {
+				delete(me.liveSinks, metric)
+			} else {
+				me.liveSinks[metric] = append(sinks[:i:i], sinks[i+1:]...)
+			}
+			return
+		}
+	}
+	return read, stop, nil
+}
+
+// getMetricAndSubmetricTags returns the metric with the given name, and the
+// tags of the sub-metric, if the name is one (e.g. `http_req_duration{status:200}`).
+func (me *MetricsEngine) getMetricAndSubmetricTags(name string) (*metrics.Metric, *metrics.TagSet, error) {
+	metricName, submetricDefinition, isSubmetric := strings.Cut(name, "{")
+
+	metric := me.registry.Get(metricName)
+	if metric == nil {
+		return nil, nil, fmt.Errorf("metric '%s' does not exist in the script", metricName)
+	}
+	if !isSubmetric {
+		return metric, nil, nil
+	}
+
+	if !strings.HasSuffix(submetricDefinition, "}") {
+		return nil, nil, fmt.Errorf("missing ending bracket, sub-metric format needs to be 'metric{key:value}'")
+	}
+	tags, err := metric.SubmetricTags(strings.TrimSuffix(submetricDefinition, "}"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return metric, tags, nil
+}
+
+// addToLiveSinks adds the sample to the live sinks of its metric, if there are
+// any with matching tags. The MetricsLock should be held while calling it.
+func (me *MetricsEngine) addToLiveSinks(sample metrics.Sample) {
+	for _, ls := range me.liveSinks[sample.Metric] {
+		if ls.tags == nil || sample.Tags.Contains(ls.tags) {
+			ls.sink.Add(sample)
+		}
+	}
+}
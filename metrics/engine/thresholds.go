@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/metrics"
+)
+
+// ErrNoThresholds is returned when the thresholds of a metric that doesn't
+// have any are requested or deleted.
+var ErrNoThresholds = errors.New("the metric doesn't have any thresholds")
+
+// GetThresholds returns a copy of the thresholds of all metrics and
+// sub-metrics that have any, by the metric name.
+func (me *MetricsEngine) GetThresholds() map[string]metrics.Thresholds {
+	me.MetricsLock.Lock()
+	defer me.MetricsLock.Unlock()
+
+	result := make(map[string]metrics.Thresholds, len(me.metricsWithThresholds))
+	for _, m := range me.metricsWithThresholds {
+		result[m.Name] = copyThresholds(m.Thresholds)
+	}
+	return result
+}
+
+// HasThresholds returns whether any metric or sub-metric has thresholds.
+func (me *MetricsEngine) HasThresholds() bool {
+	me.MetricsLock.Lock()
+	defer me.MetricsLock.Unlock()
+	return len(me.metricsWithThresholds) > 0
+}
+
+// GetMetricThresholds returns a copy of the thresholds of the given metric or
+// sub-metric, or ErrNoThresholds if it doesn't have any.
+func (me *MetricsEngine) GetMetricThresholds(name string) (string, metrics.Thresholds, error) {
+	me.MetricsLock.Lock()
+	defer me.MetricsLock.Unlock()
+
+	metric, idx, err := me.findMetricWithThresholds(name)
+	if err != nil {
+		return "", metrics.Thresholds{}, err
+	}
+	if idx < 0 {
+		return metric.Name, metrics.Thresholds{}, ErrNoThresholds
+	}
+	return metric.Name, copyThresholds(metric.Thresholds), nil
+}
+
+// SetThresholds sets the thresholds of the given metric or sub-metric,
+// replacing any that it had before. It's safe to call during the test run,
+// the new thresholds are used from their next evaluation onwards, including
+// the final one at the end of the test.
+func (me *MetricsEngine) SetThresholds(name string, thresholds metrics.Thresholds) (string, error) {
+	if err := thresholds.Parse(); err != nil {
+		return "", fmt.Errorf("invalid thresholds on metric '%s': %w", name, err)
+	}
+	if err := thresholds.Validate(name, me.registry); err != nil {
+		return "", err
+	}
+
+	me.MetricsLock.Lock()
+	defer me.MetricsLock.Unlock()
+
+	metric, idx, err := me.findMetricWithThresholds(name)
+	if err != nil {
+		return "", err
+	}
+	metric.Thresholds = thresholds
+	metric.Tainted = null.Bool{}
+	if idx < 0 {
+		me.metricsWithThresholds = append(me.metricsWithThresholds, metric)
+	}
+
+	me.markObserved(metric)
+	if metric.Sub != nil {
+		me.markObserved(metric.Sub.Parent)
+	}
+	return metric.Name, nil
+}
+
+// DeleteThresholds removes all of the thresholds of the given metric or
+// sub-metric, or returns ErrNoThresholds if it doesn't have any.
+func (me *MetricsEngine) DeleteThresholds(name string) (string, error) {
+	me.MetricsLock.Lock()
+	defer me.MetricsLock.Unlock()
+
+	metric, idx, err := me.findMetricWithThresholds(name)
+	if err != nil {
+		return "", err
+	}
+	if idx < 0 {
+		return metric.Name, ErrNoThresholds
+	}
+	metric.Thresholds = metrics.Thresholds{}
+	metric.Tainted = null.Bool{}
+	me.metricsWithThresholds = append(me.metricsWithThresholds[:idx], me.metricsWithThresholds[idx+1:]...)
+	return metric.Name, nil
+}
+
+// findMetricWithThresholds returns the given metric or sub-metric and its
+// index in metricsWithThresholds, or -1 if it doesn't have any thresholds. The
+// MetricsLock should be held while calling it.
+func (me *MetricsEngine) findMetricWithThresholds(name string) (*metrics.Metric, int, error) {
+	metric, err := me.getThresholdMetricOrSubmetric(name)
+	if err != nil {
+		return nil, -1, err
+	}
+	for i, m := range me.metricsWithThresholds {
+		if m == metric {
+			return metric, i, nil
+		}
+	}
+	return metric, -1, nil
+}
+
+func copyThresholds(thresholds metrics.Thresholds) metrics.Thresholds {
+	result := metrics.Thresholds{
+		Thresholds: make([]*metrics.Threshold, len(thresholds.Thresholds)),
+		Abort:      thresholds.Abort,
+	}
+	for i, t := range thresholds.Thresholds {
+		c := *t
+		c.FailedWindows = append([]metrics.ThresholdWindow(nil), t.FailedWindows...)
+		result.Thresholds[i] = &c
+	}
+	return result
+}
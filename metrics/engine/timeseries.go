@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"go.k6.io/k6/metrics"
+)
+
+// OverflowTagName is the name of the tag of the per-metric time series, in
+// which the samples of all new time series are aggregated after the limit of
+// time series sinks has been reached.
+const OverflowTagName = "k6_series_overflow"
+
+// timeSeriesSinks aggregates the metric samples separately for every time
+// series (i.e. a metric and tag set combination), up to a limit.
+type timeSeriesSinks struct {
+	limit     int
+	sinks     map[metrics.TimeSeries]metrics.Sink
+	overflows map[*metrics.Metric]metrics.TimeSeries
+	limitHit  bool
+	registry  *metrics.Registry
+}
+
+func newTimeSeriesSinks(registry *metrics.Registry, limit int) *timeSeriesSinks {
+	return &timeSeriesSinks{
+		limit:     limit,
+		sinks:     make(map[metrics.TimeSeries]metrics.Sink),
+		overflows: make(map[*metrics.Metric]metrics.TimeSeries),
+		registry:  registry,
+	}
+}
+
+// add records the sample in the sink of its time series. It returns true only
+// the first time the limit of time series is hit.
+func (tss *timeSeriesSinks) add(sample metrics.Sample) bool {
+	sink, ok := tss.sinks[sample.TimeSeries]
+	if ok {
+		sink.Add(sample)
+		return false
+	}
+
+	ts := sample.TimeSeries
+	justHitLimit := false
+	if len(tss.sinks)-len(tss.overflows) >= tss.limit {
+		justHitLimit = !tss.limitHit
+		tss.limitHit = true
+		ts = tss.overflowTimeSeries(sample.Metric)
+		sink, ok = tss.sinks[ts]
+	}
+	if !ok {
+		sink = newTimeSeriesSink(sample.Metric.Type)
+		tss.sinks[ts] = sink
+	}
+	sink.Add(sample)
+	return justHitLimit
+}
+
+func (tss *timeSeriesSinks) overflowTimeSeries(m *metrics.Metric) metrics.TimeSeries {
+	ts, ok := tss.overflows[m]
+	if !ok {
+		ts = metrics.TimeSeries{
+			Metric: m,
+			Tags:   tss.registry.RootTagSet().With(OverflowTagName, "true"),
+		}
+		tss.overflows[m] = ts
+	}
+	return ts
+}
+
+// newTimeSeriesSink returns a sink for the given metric type, with a bounded
+// memory usage even for Trend metrics, since there could be a lot of them.
+func newTimeSeriesSink(mt metrics.MetricType) metrics.Sink {
+	if mt == metrics.Trend {
+		return metrics.NewHistogramTrendSink()
+	}
+	return metrics.NewSink(mt)
+}
+
+// EnableTimeSeriesSinks makes the engine aggregate the metric samples for every
+// time series (i.e. a metric and tag set combination) separately, in addition
+// to the aggregation per metric. To limit the memory usage, after the given
+// number of time series is reached, the samples of any new time series are
+// aggregated in a single time series per metric, tagged with OverflowTagName.
+//
+// It has to be called before any metric samples are ingested.
+func (me *MetricsEngine) EnableTimeSeriesSinks(limit int) {
+	me.timeSeriesSinks = newTimeSeriesSinks(me.registry, limit)
+}
+
+// TimeSeriesSinksEnabled returns whether EnableTimeSeriesSinks() was called.
+func (me *MetricsEngine) TimeSeriesSinksEnabled() bool {
+	return me.timeSeriesSinks != nil
+}
+
+// VisitTimeSeriesSinks calls the given callback for the sink of every time
+// series, if they are enabled. The MetricsLock should be held while calling it
+// and the sinks shouldn't be modified by the callback.
+func (me *MetricsEngine) VisitTimeSeriesSinks(callback func(metrics.TimeSeries, metrics.Sink)) {
+	if me.timeSeriesSinks == nil {
+		return
+	}
+	for ts, sink := range me.timeSeriesSinks.sinks {
+		callback(ts, sink)
+	}
+}
@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+)
+
+const (
+	// histogramResolution is the smallest difference between values that the
+	// histogram can distinguish. Trend values are usually durations in
+	// milliseconds, so this gives us a microsecond precision for them.
+	histogramResolution = .001
+
+	// histogramSubBucketsBits controls the relative error of the histogram.
+	// Every power of two range is split into 2^7=128 sub-buckets, so the
+	// percentile estimations are within ~0.8% of the actual values.
+	histogramSubBucketsBits = uint64(7)
+)
+
+// histogram is a fixed-error, mergeable histogram with a bounded memory usage,
+// based on the same base-2 exponential buckets that the cloud output uses.
+// Values are upscaled by the resolution and then bucketed by their magnitude,
+// so the number of buckets only depends on the range of the recorded values
+// and not on their count.
+//
+// Negative values are recorded in a separate set of buckets with their
+// absolute value, which allows us to support any Trend metric.
+type histogram struct {
+	buckets         map[uint32]uint64
+	negativeBuckets map[uint32]uint64
+	count           uint64
+}
+
+func newHistogram() *histogram {
+	return &histogram{
+		buckets:         make(map[uint32]uint64),
+		negativeBuckets: make(map[uint32]uint64),
+	}
+}
+
+// add records a single value in the histogram.
+func (h *histogram) add(v float64) {
+	h.count++
+	if v < 0 {
+		h.negativeBuckets[histogramBucketIndex(-v)]++
+		return
+	}
+	h.buckets[histogramBucketIndex(v)]++
+}
+
+// merge adds all of the values recorded in the other histogram to this one.
+func (h *histogram) merge(other *histogram) {
+	for index, count := range other.buckets {
+		h.buckets[index] += count
+	}
+	for index, count := range other.negativeBuckets {
+		h.negativeBuckets[index] += count
+	}
+	h.count += other.count
+}
+
+// quantile estimates the value at the given quantile (between 0 and 1) by
+// linearly interpolating inside of the bucket that contains it. The result is
+// clamped between the given actual min and max values.
+func (h *histogram) quantile(q, minValue, maxValue float64) float64 {
+	switch {
+	case h.count == 0:
+		return 0
+	case q <= 0:
+		return minValue
+	case q >= 1:
+		return maxValue
+	}
+
+	// The same rank calculation that the TrendSink uses for the raw values.
+	rank := q * float64(h.count-1)
+	var seen float64
+	result := maxValue
+	h.walk(func(lower, upper float64, count uint64) bool {
+		if rank >= seen+float64(count) {
+			seen += float64(count)
+			return true
+		}
+		// Assume the values are evenly distributed inside of the bucket.
+		fraction := (rank - seen + 0.5) / float64(count)
+		result = lower + (upper-lower)*fraction
+		return false
+	})
+
+	return math.Max(minValue, math.Min(maxValue, result))
+}
+
+// walk calls the callback for all non-empty buckets, in ascending value order,
+// until it returns false.
+func (h *histogram) walk(callback func(lower, upper float64, count uint64) bool) {
+	negative := sortedBucketIndexes(h.negativeBuckets)
+	for i := len(negative) - 1; i >= 0; i-- {
+		lower, upper := histogramBucketBounds(negative[i])
+		if !callback(-upper, -lower, h.negativeBuckets[negative[i]]) {
+			return
+		}
+	}
+	for _, index := range sortedBucketIndexes(h.buckets) {
+		lower, upper := histogramBucketBounds(index)
+		if !callback(lower, upper, h.buckets[index]) {
+			return
+		}
+	}
+}
+
+func sortedBucketIndexes(buckets map[uint32]uint64) []uint32 {
+	indexes := make([]uint32, 0, len(buckets))
+	for index := range buckets {
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+	return indexes
+}
+
+// histogramBucketIndex returns the index of the bucket for the given
+// non-negative value. See the cloud output's resolveBucketIndex() for the
+// detailed explanation of the math behind it.
+func histogramBucketIndex(v float64) uint32 {
+	upscaled := v / histogramResolution
+	if upscaled >= math.MaxUint32 {
+		// Values this big are more than 49 days in milliseconds, so we just
+		// count them in the last bucket, they are still accounted in max.
+		upscaled = math.MaxUint32
+	}
+	u := uint64(math.Ceil(upscaled))
+
+	const k = histogramSubBucketsBits
+	if u < 1<<(k+1) {
+		return uint32(u)
+	}
+	nkdiff := uint64(bits.Len64(u>>k)) - 1
+	return uint32((nkdiff << k) + (u >> nkdiff))
+}
+
+// histogramBucketBounds returns the (lower, upper] range of the values that
+// fall in the bucket with the given index, i.e. it's the inverse function of
+// histogramBucketIndex().
+func histogramBucketBounds(index uint32) (lower, upper float64) {
+	const k = histogramSubBucketsBits
+	i := uint64(index)
+	if i < 1<<(k+1) {
+		if i == 0 {
+			return 0, 0
+		}
+		return float64(i-1) * histogramResolution, float64(i) * histogramResolution
+	}
+
+	nkdiff := (i >> k) - 1
+	sub := i - (nkdiff << k)
+	from := sub << nkdiff
+	to := ((sub + 1) << nkdiff) - 1
+	return float64(from-1) * histogramResolution, float64(to) * histogramResolution
+}
@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramBucketBounds(t *testing.T) {
+	t.Parallel()
+
+	for _, v := range []float64{0, .0005, .001, .127, .128, .129, 1, 42.5, 1000, 123456.789} {
+		index := histogramBucketIndex(v)
+		lower, upper := histogramBucketBounds(index)
+		if v == 0 {
+			assert.Equal(t, uint32(0), index)
+			continue
+		}
+		assert.Greater(t, v, lower, "value %f, index %d", v, index)
+		assert.LessOrEqual(t, v, upper+histogramResolution/2, "value %f, index %d", v, index)
+	}
+}
+
+func TestHistogramTrendSinkPercentiles(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(42)) //nolint:gosec
+	values := make([]float64, 100000)
+	exact, estimated := NewTrendSink(), NewHistogramTrendSink()
+	for i := range values {
+		values[i] = r.ExpFloat64() * 250
+		exact.Add(Sample{Value: values[i]})
+		estimated.Add(Sample{Value: values[i]})
+	}
+	sort.Float64s(values)
+
+	assert.Equal(t, exact.Count(), estimated.Count())
+	assert.Equal(t, exact.Min(), estimated.Min())
+	assert.Equal(t, exact.Max(), estimated.Max())
+	assert.InDelta(t, exact.Avg(), estimated.Avg(), 1e-9)
+	assert.Len(t, estimated.values, 0)
+	assert.Less(t, len(estimated.hist.buckets), 2000)
+
+	for _, pct := range []float64{0, .01, .1, .5, .9, .95, .99, .999, 1} {
+		expected := exact.P(pct)
+		actual := estimated.P(pct)
+		assert.InEpsilon(t, expected, actual, .01, "p(%g): expected %f, got %f", pct*100, expected, actual)
+	}
+}
+
+func TestHistogramTrendSinkNegativeValues(t *testing.T) {
+	t.Parallel()
+
+	sink := NewHistogramTrendSink()
+	for i := -100; i <= 100; i++ {
+		sink.Add(Sample{Value: float64(i)})
+	}
+	assert.Equal(t, -100.0, sink.P(0))
+	assert.Equal(t, 100.0, sink.P(1))
+	assert.InDelta(t, 0, sink.P(.5), .5)
+	assert.InEpsilon(t, -50, sink.P(.25), .01)
+	assert.InEpsilon(t, 50, sink.P(.75), .01)
+}
+
+func TestTrendSinkMerge(t *testing.T) {
+	t.Parallel()
+
+	t.Run("values", func(t *testing.T) {
+		t.Parallel()
+
+		a, b := NewTrendSink(), NewTrendSink()
+		for i := 1; i <= 10; i++ {
+			a.Add(Sample{Value: float64(i)})
+			b.Add(Sample{Value: float64(i + 10)})
+		}
+		a.Merge(b)
+		assert.Equal(t, uint64(20), a.Count())
+		assert.Equal(t, 1.0, a.Min())
+		assert.Equal(t, 20.0, a.Max())
+		assert.Equal(t, 10.5, a.P(.5))
+		assert.Nil(t, a.hist)
+	})
+
+	t.Run("histograms", func(t *testing.T) {
+		t.Parallel()
+
+		sinks := make([]*TrendSink, 4)
+		combined := NewTrendSink()
+		for i := range sinks {
+			sinks[i] = NewHistogramTrendSink()
+			for j := 0; j < 1000; j++ {
+				v := float64(i*1000+j) / 10
+				sinks[i].Add(Sample{Value: v})
+				combined.Add(Sample{Value: v})
+			}
+		}
+
+		merged := NewHistogramTrendSink()
+		for _, s := range sinks {
+			merged.Merge(s)
+		}
+		assert.Equal(t, combined.Count(), merged.Count())
+		assert.Equal(t, combined.Min(), merged.Min())
+		assert.Equal(t, combined.Max(), merged.Max())
+		assert.InDelta(t, combined.Avg(), merged.Avg(), 1e-9)
+		for _, pct := range []float64{.5, .9, .95, .99} {
+			assert.InEpsilon(t, combined.P(pct), merged.P(pct), .01)
+		}
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		t.Parallel()
+
+		values, hist := NewTrendSink(), NewHistogramTrendSink()
+		values.Add(Sample{Value: 5})
+		hist.Add(Sample{Value: -5})
+		hist.Add(Sample{Value: 15})
+		values.Merge(hist)
+
+		require.NotNil(t, values.hist)
+		assert.Nil(t, values.values)
+		assert.Equal(t, uint64(3), values.Count())
+		assert.Equal(t, -5.0, values.Min())
+		assert.Equal(t, 15.0, values.Max())
+		assert.Equal(t, 15.0, values.Total())
+		assert.InEpsilon(t, 5, values.P(.5), .01)
+		assert.False(t, math.IsNaN(values.P(.99)))
+	})
+}
@@ -39,25 +39,9 @@ type Submetric struct {
 // and adds it to the metric's submetrics list.
 func (m *Metric) AddSubmetric(keyValues string) (*Submetric, error) {
 	keyValues = strings.TrimSpace(keyValues)
-	if len(keyValues) == 0 {
-		return nil, fmt.Errorf("submetric criteria for metric '%s' cannot be empty", m.Name)
-	}
-	kvs := strings.Split(keyValues, ",")
-	tags := m.registry.RootTagSet()
-	for _, kv := range kvs {
-		if kv == "" {
-			continue
-		}
-		parts := strings.SplitN(kv, ":", 2)
-
-		key := strings.Trim(strings.TrimSpace(parts[0]), `"'`)
-		if len(parts) != 2 {
-			tags = tags.With(key, "")
-			continue
-		}
-
-		value := strings.Trim(strings.TrimSpace(parts[1]), `"'`)
-		tags = tags.With(key, value)
+	tags, err := m.SubmetricTags(keyValues)
+	if err != nil {
+		return nil, err
 	}
 
 	for _, sm := range m.Submetrics {
@@ -81,6 +65,34 @@ func (m *Metric) AddSubmetric(keyValues string) (*Submetric, error) {
 	return subMetric, nil
 }
 
+// SubmetricTags returns the tags matched by the submetric with the given
+// key:value definition, without adding the submetric to the metric.
+func (m *Metric) SubmetricTags(keyValues string) (*TagSet, error) {
+	keyValues = strings.TrimSpace(keyValues)
+	if len(keyValues) == 0 {
+		return nil, fmt.Errorf("submetric criteria for metric '%s' cannot be empty", m.Name)
+	}
+	kvs := strings.Split(keyValues, ",")
+	tags := m.registry.RootTagSet()
+	for _, kv := range kvs {
+		if kv == "" {
+			continue
+		}
+		parts := strings.SplitN(kv, ":", 2)
+
+		key := strings.Trim(strings.TrimSpace(parts[0]), `"'`)
+		if len(parts) != 2 {
+			tags = tags.With(key, "")
+			continue
+		}
+
+		value := strings.Trim(strings.TrimSpace(parts[1]), `"'`)
+		tags = tags.With(key, value)
+	}
+
+	return tags, nil
+}
+
 // ErrMetricNameParsing indicates parsing a metric name failed
 var ErrMetricNameParsing = errors.New("parsing metric name failed")
 
@@ -14,6 +14,8 @@ type Registry struct {
 	l       sync.RWMutex
 
 	rootTagSet *atlas.Node
+
+	histogramTrends bool
 }
 
 // NewRegistry returns a new registry
@@ -96,7 +98,12 @@ func (r *Registry) newMetric(name string, mt MetricType, vt ...ValueType) *Metri
 		valueType = vt[0]
 	}
 
-	sink := NewSink(mt)
+	var sink Sink
+	if mt == Trend && r.histogramTrends {
+		sink = NewHistogramTrendSink()
+	} else {
+		sink = NewSink(mt)
+	}
 	return &Metric{
 		registry: r,
 		Name:     name,
@@ -106,6 +113,26 @@ func (r *Registry) newMetric(name string, mt MetricType, vt ...ValueType) *Metri
 	}
 }
 
+// UseHistogramTrendSinks makes the registry create histogram-based Trend sinks
+// (see NewHistogramTrendSink) for all new Trend metrics and sub-metrics. The
+// sinks of the already registered Trend metrics are replaced as well, so it
+// should be called before any samples are added to them.
+func (r *Registry) UseHistogramTrendSinks() {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	r.histogramTrends = true
+	for _, m := range r.metrics {
+		if m.Type != Trend {
+			continue
+		}
+		m.Sink = NewHistogramTrendSink()
+		for _, sm := range m.Submetrics {
+			sm.Metric.Sink = NewHistogramTrendSink()
+		}
+	}
+}
+
 // Get returns the Metric with the given name. If that metric doesn't exist,
 // Get() will return a nil value.
 func (r *Registry) Get(name string) *Metric {
@@ -95,15 +95,32 @@ func (g *GaugeSink) Format(_ time.Duration) map[string]float64 {
 	return map[string]float64{"value": g.Value}
 }
 
-// NewTrendSink makes a Trend sink with the OpenHistogram circllhist histogram.
+// The possible values of the trendSink option, which selects the kind of sinks
+// that are used for the Trend metrics.
+const (
+	TrendSinkValues    = "values"
+	TrendSinkHistogram = "histogram"
+)
+
+// NewTrendSink makes a Trend sink that keeps all of the values it receives, so
+// its percentiles are exact, but its memory usage grows with every sample.
 func NewTrendSink() *TrendSink {
 	return &TrendSink{}
 }
 
+// NewHistogramTrendSink makes a Trend sink that records the values in a
+// fixed-error histogram instead of keeping them, so its memory usage is
+// bounded. Its min, max, avg and count values are exact, while the percentiles
+// are estimated with a relative error of less than 1%.
+func NewHistogramTrendSink() *TrendSink {
+	return &TrendSink{hist: newHistogram()}
+}
+
 // TrendSink is a sink for a Trend
 type TrendSink struct {
 	values []float64
 	sorted bool
+	hist   *histogram // if set, it's used instead of the values slice
 
 	count    uint64
 	min, max float64
@@ -115,25 +132,72 @@ func (t *TrendSink) IsEmpty() bool { return t.count == 0 }
 
 // Add a single sample into the trend
 func (t *TrendSink) Add(s Sample) {
+	t.addValue(s.Value)
+}
+
+func (t *TrendSink) addValue(v float64) {
 	if t.count == 0 {
-		t.max, t.min = s.Value, s.Value
+		t.max, t.min = v, v
 	} else {
-		if s.Value > t.max {
-			t.max = s.Value
+		if v > t.max {
+			t.max = v
 		}
-		if s.Value < t.min {
-			t.min = s.Value
+		if v < t.min {
+			t.min = v
 		}
 	}
 
-	t.values = append(t.values, s.Value)
-	t.sorted = false
+	if t.hist != nil {
+		t.hist.add(v)
+	} else {
+		t.values = append(t.values, v)
+		t.sorted = false
+	}
 	t.count++
-	t.sum += s.Value
+	t.sum += v
+}
+
+// Merge adds all of the values from the other sink into this one. Both kinds of
+// Trend sinks can be merged with each other, though if this sink is backed by
+// a histogram, its values stay estimated.
+func (t *TrendSink) Merge(other *TrendSink) {
+	if other.count == 0 {
+		return
+	}
+	if other.hist == nil {
+		for _, v := range other.values {
+			t.addValue(v)
+		}
+		return
+	}
+
+	if t.hist == nil {
+		// We can't recover the exact values from the other histogram, so
+		// this sink has to become histogram-based as well.
+		t.hist = newHistogram()
+		for _, v := range t.values {
+			t.hist.add(v)
+		}
+		t.values, t.sorted = nil, false
+	}
+	t.hist.merge(other.hist)
+
+	if t.count == 0 || other.min < t.min {
+		t.min = other.min
+	}
+	if t.count == 0 || other.max > t.max {
+		t.max = other.max
+	}
+	t.count += other.count
+	t.sum += other.sum
 }
 
 // P calculates the given percentile from sink values.
 func (t *TrendSink) P(pct float64) float64 {
+	if t.hist != nil {
+		return t.hist.quantile(pct, t.min, t.max)
+	}
+
 	switch t.count {
 	case 0:
 		return 0
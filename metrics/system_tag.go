@@ -38,10 +38,11 @@ const (
 	TagVU   // non-indexable
 	TagOCSPStatus
 	TagIP
+	TagBackend
 )
 
 // DefaultSystemTagSet includes all of the system tags emitted with metrics by default.
-// Other tags that are not enabled by default include: iter, vu, ocsp_status, ip
+// Other tags that are not enabled by default include: iter, vu, ocsp_status, ip, backend
 //
 //nolint:gochecknoglobals
 var DefaultSystemTagSet = SystemTagSet(
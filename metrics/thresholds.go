@@ -24,8 +24,13 @@ type Threshold struct {
 	// AbortGracePeriod is a the minimum amount of time a test should be running before a failing
 	// this threshold will abort the test
 	AbortGracePeriod types.NullDuration
+	// FailedWindows holds the time ranges in which a windowed threshold (e.g.
+	// `p(95)<300 over 1m`) has failed, overlapping windows are merged together
+	FailedWindows []ThresholdWindow
 	// parsed is the threshold expression parsed from the Source
 	parsed *thresholdExpression
+	// window keeps the recent samples for a windowed threshold
+	window *thresholdWindow
 }
 
 func newThreshold(src string, abortOnFail bool, gracePeriod types.NullDuration) *Threshold {
@@ -85,6 +90,51 @@ func (t *Threshold) run(sinks map[string]float64) (bool, error) {
 	return passes, err
 }
 
+// IsWindowed returns true if the threshold should be evaluated over a sliding
+// time window instead of over the whole test run.
+func (t *Threshold) IsWindowed() bool {
+	return t.parsed != nil && t.parsed.Window > 0
+}
+
+// runWindow evaluates a windowed threshold over the samples in the window that
+// ends now and returns whether it passes for it. If it doesn't, the window is
+// recorded in FailedWindows and the threshold stays failed for the rest of the
+// test run, even if the subsequent windows pass.
+func (t *Threshold) runWindow(now time.Time, timeSpentInTest time.Duration) (bool, error) {
+	if t.window == nil {
+		return true, nil // no samples were added yet
+	}
+
+	sink := t.window.aggregate(now)
+	if sink == nil {
+		return true, nil // no samples in the current window
+	}
+
+	// At the start of the test, the windows are shorter than configured.
+	windowLength := t.parsed.Window
+	if timeSpentInTest < windowLength {
+		windowLength = timeSpentInTest
+	}
+
+	sinks, err := sinkValues(sink, windowLength, []*Threshold{t})
+	if err != nil {
+		return false, err
+	}
+	passes, err := t.runNoTaint(sinks)
+	if err != nil || passes {
+		return passes, err
+	}
+
+	failed := ThresholdWindow{From: timeSpentInTest - windowLength, To: timeSpentInTest}
+	if n := len(t.FailedWindows); n > 0 && t.FailedWindows[n-1].To >= failed.From {
+		t.FailedWindows[n-1].To = failed.To
+	} else {
+		t.FailedWindows = append(t.FailedWindows, failed)
+	}
+	t.LastFailed = true
+	return false, nil
+}
+
 type thresholdConfig struct {
 	Threshold        string             `json:"threshold"`
 	AbortOnFail      bool               `json:"abortOnFail"`
@@ -144,32 +194,68 @@ func newThresholdsWithConfig(configs []thresholdConfig) Thresholds {
 
 func (ts *Thresholds) runAll(timeSpentInTest time.Duration) (bool, error) {
 	succeeded := true
+	now := time.Now()
 	for i, threshold := range ts.Thresholds {
-		b, err := threshold.run(ts.sinked)
+		var b bool
+		var err error
+		if threshold.IsWindowed() {
+			b, err = threshold.runWindow(now, timeSpentInTest)
+		} else {
+			b, err = threshold.run(ts.sinked)
+		}
 		if err != nil {
 			return false, fmt.Errorf("threshold %d run error: %w", i, err)
 		}
 
-		if !b {
+		// A windowed threshold could pass for the current window, but it
+		// would still be failed if any of the previous windows failed.
+		if threshold.LastFailed {
 			succeeded = false
+		}
 
-			if ts.Abort || !threshold.AbortOnFail {
-				continue
-			}
-
-			ts.Abort = !threshold.AbortGracePeriod.Valid ||
-				threshold.AbortGracePeriod.Duration < types.Duration(timeSpentInTest)
+		// Windowed thresholds only abort the test if the current window is
+		// failing, so early spikes can't trip abortOnFail later on.
+		if b || ts.Abort || !threshold.AbortOnFail {
+			continue
 		}
+
+		ts.Abort = !threshold.AbortGracePeriod.Valid ||
+			threshold.AbortGracePeriod.Duration < types.Duration(timeSpentInTest)
 	}
 
 	return succeeded, nil
 }
 
+// AddSample records the sample for the windowed thresholds, so they can be
+// evaluated over the recent samples. It's a no-op if there are none of them.
+func (ts *Thresholds) AddSample(s Sample) {
+	for _, threshold := range ts.Thresholds {
+		if !threshold.IsWindowed() {
+			continue
+		}
+		if threshold.window == nil {
+			threshold.window = newThresholdWindow(threshold.parsed.Window)
+		}
+		threshold.window.add(s)
+	}
+}
+
 // Run processes all the thresholds with the provided Sink at the provided time and returns if any
 // of them fails
 func (ts *Thresholds) Run(sink Sink, duration time.Duration) (bool, error) {
-	// Initialize the sinks store
-	ts.sinked = make(map[string]float64)
+	sinked, err := sinkValues(sink, duration, ts.Thresholds)
+	if err != nil {
+		return false, err
+	}
+	ts.sinked = sinked
+
+	return ts.runAll(duration)
+}
+
+// sinkValues extracts the values that the given thresholds can be asserted
+// against from the sink, with the duration used to calculate the counter rate.
+func sinkValues(sink Sink, duration time.Duration, thresholds []*Threshold) (map[string]float64, error) {
+	sinked := make(map[string]float64)
 
 	// FIXME: Remove this comment as soon as the metrics.Sink does not expose Format anymore.
 	//
@@ -180,37 +266,37 @@ func (ts *Thresholds) Run(sink Sink, duration time.Duration) (bool, error) {
 	// For more details, see https://github.com/grafana/k6/issues/2320
 	switch sinkImpl := sink.(type) {
 	case *CounterSink:
-		ts.sinked["count"] = sinkImpl.Value
-		ts.sinked["rate"] = sinkImpl.Value / (float64(duration) / float64(time.Second))
+		sinked["count"] = sinkImpl.Value
+		sinked["rate"] = sinkImpl.Value / (float64(duration) / float64(time.Second))
 	case *GaugeSink:
-		ts.sinked["value"] = sinkImpl.Value
+		sinked["value"] = sinkImpl.Value
 	case *TrendSink:
-		ts.sinked["min"] = sinkImpl.Min()
-		ts.sinked["max"] = sinkImpl.Max()
-		ts.sinked["avg"] = sinkImpl.Avg()
-		ts.sinked["med"] = sinkImpl.P(0.5)
+		sinked["min"] = sinkImpl.Min()
+		sinked["max"] = sinkImpl.Max()
+		sinked["avg"] = sinkImpl.Avg()
+		sinked["med"] = sinkImpl.P(0.5)
 
 		// Parse the percentile thresholds and insert them in
 		// the sinks mapping.
-		for _, threshold := range ts.Thresholds {
+		for _, threshold := range thresholds {
 			if threshold.parsed.AggregationMethod != tokenPercentile {
 				continue
 			}
 
 			key := fmt.Sprintf("p(%g)", threshold.parsed.AggregationValue.Float64)
-			ts.sinked[key] = sinkImpl.P(threshold.parsed.AggregationValue.Float64 / 100)
+			sinked[key] = sinkImpl.P(threshold.parsed.AggregationValue.Float64 / 100)
 		}
 	case *RateSink:
 		// We want to avoid division by zero, which
 		// would lead to [#2520](https://github.com/grafana/k6/issues/2520)
 		if sinkImpl.Total > 0 {
-			ts.sinked["rate"] = float64(sinkImpl.Trues) / float64(sinkImpl.Total)
+			sinked["rate"] = float64(sinkImpl.Trues) / float64(sinkImpl.Total)
 		}
 	default:
-		return false, fmt.Errorf("unable to run Thresholds; reason: unknown sink type")
+		return nil, fmt.Errorf("unable to run Thresholds; reason: unknown sink type")
 	}
 
-	return ts.runAll(duration)
+	return sinked, nil
 }
 
 // Parse parses the Thresholds and fills each Threshold.parsed field with the result.
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib/types"
 )
 
 // thresholdExpression holds the parsed result of a threshold expression,
@@ -27,6 +30,11 @@ type thresholdExpression struct {
 
 	// Value holds the value parsed from the threshold expression.
 	Value float64
+
+	// Window holds the length of the sliding time window that the expression
+	// should be evaluated over, for instance 1m for `p(95) < 300 over 1m`.
+	// A zero value means that the whole test run is used.
+	Window time.Duration
 }
 
 // SinkKey computes the key used to index a thresholdExpression in the engine's sinks.
@@ -53,10 +61,12 @@ func (te *thresholdExpression) SinkKey() string {
 // as defined in a JS script (for instance p(95)<1000), into a thresholdExpression
 // instance.
 //
-// It is expected to be of the form: `aggregation_method operator value`.
+// It is expected to be of the form: `aggregation_method operator value [window]`.
 // As defined by the following BNF:
 // ```
+// expression          -> assertion (whitespace+ window)?
 // assertion           -> aggregation_method whitespace* operator whitespace* float
+// window              -> "over" whitespace+ duration | "window=" duration
 // aggregation_method  -> trend | rate | gauge | counter
 // counter             -> "count" | "rate"
 // gauge               -> "value"
@@ -65,13 +75,19 @@ func (te *thresholdExpression) SinkKey() string {
 // percentile          -> "p(" float ")"
 // operator            -> ">" | ">=" | "<=" | "<" | "==" | "===" | "!="
 // float               -> digit+ ("." digit+)?
+// duration            -> a positive duration, like "30s", "1m30s" or "1d"
 // digit               -> "0" | "1" | "2" | "3" | "4" | "5" | "6" | "7" | "8" | "9"
 // whitespace          -> " "
 // ```
 func parseThresholdExpression(input string) (*thresholdExpression, error) {
+	assertion, window, err := parseThresholdWindow(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing threshold expression's %q window; reason: %w", input, err)
+	}
+
 	// Scanning makes no assumption on the underlying values, and only
 	// checks that the expression has the right format.
-	method, operator, value, err := scanThresholdExpression(input)
+	method, operator, value, err := scanThresholdExpression(assertion)
 	if err != nil {
 		return nil, fmt.Errorf("failed parsing threshold expression %q; reason: %w", input, err)
 	}
@@ -97,11 +113,51 @@ func parseThresholdExpression(input string) (*thresholdExpression, error) {
 		AggregationValue:  parsedMethodValue,
 		Operator:          operator,
 		Value:             parsedValue,
+		Window:            window,
 	}
 
 	return condition, nil
 }
 
+// Define accepted threshold expression window tokens
+const (
+	tokenOver   = "over"
+	tokenWindow = "window="
+)
+
+// parseThresholdWindow splits the optional window suffix, either of the form
+// `over 1m` or `window=1m`, from the rest of the threshold expression and
+// parses its duration. A zero window is returned if there is no suffix.
+func parseThresholdWindow(input string) (string, time.Duration, error) {
+	fields := strings.Fields(input)
+	if len(fields) < 2 {
+		return input, 0, nil
+	}
+
+	var rawWindow string
+	last := fields[len(fields)-1]
+	switch {
+	case strings.HasPrefix(last, tokenWindow):
+		rawWindow = strings.TrimPrefix(last, tokenWindow)
+		input = strings.TrimSpace(input[:strings.LastIndex(input, last)])
+	case len(fields) > 2 && fields[len(fields)-2] == tokenOver:
+		rawWindow = last
+		input = strings.TrimSpace(input[:strings.LastIndex(input, tokenOver)])
+	default:
+		return input, 0, nil
+	}
+
+	window, err := types.ParseExtendedDuration(rawWindow)
+	if err != nil {
+		return "", 0, err
+	}
+	if window <= 0 {
+		return "", 0, fmt.Errorf("the window duration should be positive, but it was %q", rawWindow)
+	}
+
+	return input, window, nil
+}
+
 // Define accepted threshold expression operators tokens
 const (
 	tokenLessEqual     = "<="
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/guregu/null.v3"
@@ -40,6 +41,40 @@ func TestParseThresholdExpression(t *testing.T) {
 			wantExpression: &thresholdExpression{AggregationMethod: "count", Operator: ">", Value: 20},
 			wantErr:        false,
 		},
+		{
+			name:  "valid threshold expression with an over window",
+			input: "p(95) < 300 over 1m",
+			wantExpression: &thresholdExpression{
+				AggregationMethod: "p", AggregationValue: null.FloatFrom(95), Operator: "<", Value: 300, Window: time.Minute,
+			},
+			wantErr: false,
+		},
+		{
+			name:  "valid threshold expression with a window= window",
+			input: "rate < 0.01 window=30s",
+			wantExpression: &thresholdExpression{
+				AggregationMethod: "rate", Operator: "<", Value: 0.01, Window: 30 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name:           "invalid window duration fails",
+			input:          "rate<0.01 over 1x",
+			wantExpression: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "non-positive window duration fails",
+			input:          "rate<0.01 window=0s",
+			wantExpression: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "missing window duration fails",
+			input:          "rate<0.01 over",
+			wantExpression: nil,
+			wantErr:        true,
+		},
 	}
 	for _, testCase := range tests {
 		testCase := testCase
@@ -42,7 +42,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 	}{
 		{
 			name:             "valid expression using the > operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 1},
 			wantOk:           true,
@@ -50,7 +50,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the > operator over passing threshold and defined abort grace period",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(2 * time.Second),
 			sinks:            map[string]float64{"rate": 1},
 			wantOk:           true,
@@ -58,7 +58,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the >= operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreaterEqual, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreaterEqual, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.01},
 			wantOk:           true,
@@ -66,7 +66,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the <= operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLessEqual, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLessEqual, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.01},
 			wantOk:           true,
@@ -74,7 +74,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the < operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLess, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLess, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.00001},
 			wantOk:           true,
@@ -82,7 +82,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the == operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLooselyEqual, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLooselyEqual, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.01},
 			wantOk:           true,
@@ -90,7 +90,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the === operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenStrictlyEqual, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenStrictlyEqual, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.01},
 			wantOk:           true,
@@ -98,7 +98,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using != operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenBangEqual, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenBangEqual, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.02},
 			wantOk:           true,
@@ -106,7 +106,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression over failing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.00001},
 			wantOk:           false,
@@ -114,7 +114,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression over non-existing sink",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"med": 27.2},
 			wantOk:           true,
@@ -124,7 +124,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 			// The ParseThresholdCondition constructor should ensure that no invalid
 			// operator gets through, but let's protect our future selves anyhow.
 			name:             "invalid expression operator",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, "&", 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, "&", 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.00001},
 			wantOk:           false,
@@ -168,7 +168,7 @@ func BenchmarkRunNoTaint(b *testing.B) {
 		LastFailed:       false,
 		AbortOnFail:      false,
 		AbortGracePeriod: types.NullDurationFrom(2 * time.Second),
-		parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01},
+		parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01, 0},
 	}
 
 	sinks := map[string]float64{"rate": 1}
@@ -749,6 +749,103 @@ func TestThresholdsRun(t *testing.T) {
 	}
 }
 
+func TestThresholdRunWindow(t *testing.T) {
+	t.Parallel()
+
+	thresholds := NewThresholds([]string{"p(95)<300 over 10s"})
+	require.NoError(t, thresholds.Parse())
+	threshold := thresholds.Thresholds[0]
+
+	metric := &Metric{Name: "my_trend", Type: Trend}
+	start := time.Now()
+	addSamples := func(from, to time.Duration, value float64) {
+		for d := from; d < to; d += 100 * time.Millisecond {
+			thresholds.AddSample(Sample{
+				TimeSeries: TimeSeries{Metric: metric},
+				Time:       start.Add(d),
+				Value:      value,
+			})
+		}
+	}
+	addSamples(time.Second, 5*time.Second, 1000)
+	addSamples(15*time.Second, 25*time.Second, 100)
+
+	passes, err := threshold.runWindow(start.Add(6*time.Second), 6*time.Second)
+	require.NoError(t, err)
+	assert.False(t, passes)
+	assert.True(t, threshold.LastFailed)
+	assert.Equal(t, []ThresholdWindow{{From: 0, To: 6 * time.Second}}, threshold.FailedWindows)
+
+	// Overlapping failed windows are merged together.
+	passes, err = threshold.runWindow(start.Add(12*time.Second), 12*time.Second)
+	require.NoError(t, err)
+	assert.False(t, passes)
+	assert.Equal(t, []ThresholdWindow{{From: 0, To: 12 * time.Second}}, threshold.FailedWindows)
+
+	// The bad samples are out of the window, so it passes and the old slots
+	// are dropped, but the threshold stays failed.
+	passes, err = threshold.runWindow(start.Add(26*time.Second), 26*time.Second)
+	require.NoError(t, err)
+	assert.True(t, passes)
+	assert.True(t, threshold.LastFailed)
+	assert.Equal(t, []ThresholdWindow{{From: 0, To: 12 * time.Second}}, threshold.FailedWindows)
+	assert.LessOrEqual(t, len(threshold.window.slots), thresholdWindowSlots)
+
+	// Nothing in the window
+	passes, err = threshold.runWindow(start.Add(time.Minute), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, passes)
+	assert.Empty(t, threshold.window.slots)
+}
+
+func TestThresholdsRunWindowed(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name                   string
+		source                 string
+		metricType             MetricType
+		oldValue, recentValue  float64
+		wantPasses, wantAborts bool
+	}{
+		{"old spike is ignored", "p(95)<300 over 10s", Trend, 1000, 100, true, false},
+		{"recent spike fails", "p(95)<300 over 10s", Trend, 100, 1000, false, true},
+		{"old errors are ignored", "rate<0.01 window=10s", Rate, 1, 0, true, false},
+		{"recent errors fail", "rate<0.01 window=10s", Rate, 0, 1, false, true},
+		{"counter rate in window", "rate<15 window=10s", Counter, 100, 1, true, false},
+		{"gauge last value in window", "value<50 over 10s", Gauge, 100, 10, true, false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			thresholds := NewThresholds([]string{tc.source})
+			require.NoError(t, thresholds.Parse())
+			thresholds.Thresholds[0].AbortOnFail = true
+
+			metric := &Metric{Name: "my_metric", Type: tc.metricType, Sink: NewSink(tc.metricType)}
+			now := time.Now()
+			for i := 0; i < 100; i++ {
+				for _, s := range []Sample{
+					{TimeSeries: TimeSeries{Metric: metric}, Time: now.Add(-time.Minute), Value: tc.oldValue},
+					{TimeSeries: TimeSeries{Metric: metric}, Time: now.Add(-time.Second), Value: tc.recentValue},
+				} {
+					metric.Sink.Add(s)
+					thresholds.AddSample(s)
+				}
+			}
+
+			passes, err := thresholds.Run(metric.Sink, 2*time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantPasses, passes)
+			assert.Equal(t, tc.wantAborts, thresholds.Abort)
+			assert.Equal(t, !tc.wantPasses, len(thresholds.Thresholds[0].FailedWindows) == 1)
+		})
+	}
+}
+
 func TestThresholdsJSON(t *testing.T) {
 	t.Parallel()
 
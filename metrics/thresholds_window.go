@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// thresholdWindowSlots is the number of time slots that the window of a
+// windowed threshold is split into. The samples in every slot are aggregated
+// together, so the memory usage of a window doesn't depend on the number of
+// samples, and the window slides forward with the granularity of a slot.
+const thresholdWindowSlots = 30
+
+// ThresholdWindow is a time range, relative to the start of the test run, in
+// which a windowed threshold (e.g. `p(95)<300 over 1m`) has failed.
+type ThresholdWindow struct {
+	From time.Duration `json:"from"`
+	To   time.Duration `json:"to"`
+}
+
+// String returns the window as a human-readable range, e.g. "1m0s-2m30s".
+func (tw ThresholdWindow) String() string {
+	return fmt.Sprintf("%s-%s", tw.From, tw.To)
+}
+
+// thresholdWindow keeps the recent samples of a metric aggregated in time
+// slots, so a threshold can be evaluated over a sliding time window.
+type thresholdWindow struct {
+	length   time.Duration
+	slotSize time.Duration
+	slots    map[int64]Sink // keyed by the sample time divided by slotSize
+}
+
+func newThresholdWindow(length time.Duration) *thresholdWindow {
+	slotSize := length / thresholdWindowSlots
+	if slotSize <= 0 {
+		slotSize = 1
+	}
+	return &thresholdWindow{
+		length:   length,
+		slotSize: slotSize,
+		slots:    make(map[int64]Sink),
+	}
+}
+
+// add records the sample in the slot for its time.
+func (w *thresholdWindow) add(s Sample) {
+	slot := s.Time.UnixNano() / int64(w.slotSize)
+	sink, ok := w.slots[slot]
+	if !ok {
+		if s.Metric.Type == Trend {
+			// Slots have to be merged, so they always use a histogram.
+			sink = NewHistogramTrendSink()
+		} else {
+			sink = NewSink(s.Metric.Type)
+		}
+		w.slots[slot] = sink
+	}
+	sink.Add(s)
+}
+
+// aggregate merges all of the slots in the window that ends at the given time
+// into a single sink and drops the slots that are older than that. It returns
+// nil if there were no samples in the window.
+func (w *thresholdWindow) aggregate(end time.Time) Sink {
+	firstSlot := end.Add(-w.length).UnixNano()/int64(w.slotSize) + 1
+	var result Sink
+	// Slots have to be merged in order, so the last gauge value wins.
+	for _, slot := range w.sortedSlots() {
+		sink := w.slots[slot]
+		if slot < firstSlot {
+			delete(w.slots, slot)
+			continue
+		}
+		if result == nil {
+			result = newWindowSink(sink)
+		}
+		mergeWindowSink(result, sink)
+	}
+	return result
+}
+
+func (w *thresholdWindow) sortedSlots() []int64 {
+	slots := make([]int64, 0, len(w.slots))
+	for slot := range w.slots {
+		slots = append(slots, slot)
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+	return slots
+}
+
+// newWindowSink returns an empty sink of the same kind as the given one.
+func newWindowSink(sink Sink) Sink {
+	switch sink.(type) {
+	case *CounterSink:
+		return &CounterSink{}
+	case *GaugeSink:
+		return &GaugeSink{}
+	case *TrendSink:
+		return NewHistogramTrendSink()
+	default:
+		return &RateSink{}
+	}
+}
+
+// mergeWindowSink adds the values of the src sink into the dst one, which
+// should be of the same kind.
+func mergeWindowSink(dst, src Sink) {
+	switch dstImpl := dst.(type) {
+	case *CounterSink:
+		srcImpl := src.(*CounterSink) //nolint:forcetypeassert
+		dstImpl.Value += srcImpl.Value
+		if dstImpl.First.IsZero() {
+			dstImpl.First = srcImpl.First
+		}
+	case *GaugeSink:
+		srcImpl := src.(*GaugeSink) //nolint:forcetypeassert
+		dstImpl.Value = srcImpl.Value
+		if !dstImpl.minSet || srcImpl.Max > dstImpl.Max {
+			dstImpl.Max = srcImpl.Max
+		}
+		if !dstImpl.minSet || srcImpl.Min < dstImpl.Min {
+			dstImpl.Min = srcImpl.Min
+		}
+		dstImpl.minSet = true
+	case *TrendSink:
+		dstImpl.Merge(src.(*TrendSink)) //nolint:forcetypeassert
+	case *RateSink:
+		srcImpl := src.(*RateSink) //nolint:forcetypeassert
+		dstImpl.Trues += srcImpl.Trues
+		dstImpl.Total += srcImpl.Total
+	}
+}
@@ -17,6 +17,9 @@ type Manager struct {
 	logger  logrus.FieldLogger
 
 	testStopCallback func(error)
+
+	flushRequests chan chan struct{}
+	stopped       chan struct{}
 }
 
 // NewManager returns a new manager for the given outputs.
@@ -25,6 +28,8 @@ func NewManager(outputs []Output, logger logrus.FieldLogger, testStopCallback fu
 		outputs:          outputs,
 		logger:           logger.WithField("component", "output-manager"),
 		testStopCallback: testStopCallback,
+		flushRequests:    make(chan chan struct{}),
+		stopped:          make(chan struct{}),
 	}
 }
 
@@ -55,6 +60,7 @@ func (om *Manager) Start(samplesChan chan metrics.SampleContainer) (wait func(),
 
 	go func() {
 		defer wg.Done()
+		defer close(om.stopped)
 		ticker := time.NewTicker(sendBatchToOutputsRate)
 		defer ticker.Stop()
 
@@ -70,6 +76,15 @@ func (om *Manager) Start(samplesChan chan metrics.SampleContainer) (wait func(),
 			case <-ticker.C:
 				sendToOutputs(buffer)
 				buffer = make([]metrics.SampleContainer, 0, cap(buffer))
+			case done := <-om.flushRequests:
+				// The samples already in the channel were sent before the flush was requested.
+				for i := len(samplesChan); i > 0; i-- {
+					buffer = append(buffer, <-samplesChan)
+				}
+				sendToOutputs(buffer)
+				buffer = make([]metrics.SampleContainer, 0, cap(buffer))
+				om.flushOutputs()
+				close(done)
 			}
 		}
 	}()
@@ -82,6 +97,27 @@ func (om *Manager) Start(samplesChan chan metrics.SampleContainer) (wait func(),
 	return wait, finish, nil
 }
 
+// Flush synchronously sends the samples received so far to the outputs, and
+// flushes the outputs that buffer them, see WithFlush. It can only be called
+// between Start() and the closing of the samples channel, it returns
+// immediately otherwise.
+func (om *Manager) Flush() {
+	done := make(chan struct{})
+	select {
+	case om.flushRequests <- done:
+		<-done
+	case <-om.stopped:
+	}
+}
+
+func (om *Manager) flushOutputs() {
+	for _, out := range om.outputs {
+		if fout, ok := out.(WithFlush); ok {
+			fout.Flush()
+		}
+	}
+}
+
 // startOutputs spins up all configured outputs. If some output fails to start,
 // it stops the already started ones. This may take some time, since some
 // outputs make initial network requests to set up whatever remote services are
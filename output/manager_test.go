@@ -0,0 +1,72 @@
+package output
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+)
+
+type flushOutput struct {
+	SampleBuffer
+
+	mu      sync.Mutex
+	flushed []metrics.SampleContainer
+}
+
+func (o *flushOutput) Description() string { return "flush" }
+func (o *flushOutput) Start() error        { return nil }
+func (o *flushOutput) Stop() error         { return nil }
+
+func (o *flushOutput) Flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.flushed = append(o.flushed, o.GetBufferedSamples()...)
+}
+
+func (o *flushOutput) getFlushed() []metrics.SampleContainer {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.flushed
+}
+
+func TestManagerFlush(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	metric, err := registry.NewMetric("my_metric", metrics.Counter)
+	require.NoError(t, err)
+	sample := metrics.Sample{
+		TimeSeries: metrics.TimeSeries{Metric: metric, Tags: registry.RootTagSet()},
+		Time:       time.Now(),
+		Value:      1,
+	}
+
+	out := &flushOutput{}
+	om := NewManager([]Output{out}, testutils.NewLogger(t), func(error) {})
+
+	samples := make(chan metrics.SampleContainer, 10)
+	wait, finish, err := om.Start(samples)
+	require.NoError(t, err)
+
+	samples <- sample
+	samples <- sample
+	om.Flush()
+	assert.Len(t, out.getFlushed(), 2)
+
+	om.Flush()
+	assert.Len(t, out.getFlushed(), 2)
+
+	close(samples)
+	wait()
+	finish(nil)
+
+	// it doesn't block once the samples channel is closed
+	om.Flush()
+	assert.Len(t, out.getFlushed(), 2)
+}
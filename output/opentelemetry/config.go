@@ -0,0 +1,240 @@
+package opentelemetry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mstoykov/envconfig"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib/types"
+)
+
+const (
+	protocolGRPC = "grpc"
+	protocolHTTP = "http"
+
+	defaultGRPCEndpoint = "localhost:4317"
+	defaultHTTPEndpoint = "localhost:4318"
+)
+
+// Config represents the configuration of the OpenTelemetry output.
+type Config struct {
+	// Connection.
+	Protocol              null.String        `json:"protocol,omitempty" envconfig:"K6_OTEL_PROTOCOL"`
+	Endpoint              null.String        `json:"endpoint,omitempty" envconfig:"K6_OTEL_ENDPOINT"`
+	URLPath               null.String        `json:"urlPath,omitempty" envconfig:"K6_OTEL_URL_PATH"`
+	Headers               map[string]string  `json:"headers,omitempty" envconfig:"K6_OTEL_HEADERS"`
+	Insecure              null.Bool          `json:"insecure,omitempty" envconfig:"K6_OTEL_INSECURE"`
+	TLSInsecureSkipVerify null.Bool          `json:"tlsInsecureSkipVerify,omitempty" envconfig:"K6_OTEL_TLS_INSECURE_SKIP_VERIFY"` //nolint:lll
+	TLSCACert             null.String        `json:"tlsCACert,omitempty" envconfig:"K6_OTEL_TLS_CA_CERT"`
+	TLSClientCert         null.String        `json:"tlsClientCert,omitempty" envconfig:"K6_OTEL_TLS_CLIENT_CERT"`
+	TLSClientKey          null.String        `json:"tlsClientKey,omitempty" envconfig:"K6_OTEL_TLS_CLIENT_KEY"`
+	Timeout               types.NullDuration `json:"timeout,omitempty" envconfig:"K6_OTEL_TIMEOUT"`
+	PushInterval          types.NullDuration `json:"pushInterval,omitempty" envconfig:"K6_OTEL_PUSH_INTERVAL"`
+
+	// Metrics.
+	MetricPrefix       null.String       `json:"metricPrefix,omitempty" envconfig:"K6_OTEL_METRIC_PREFIX"`
+	ResourceAttributes map[string]string `json:"resourceAttributes,omitempty" envconfig:"K6_OTEL_RESOURCE_ATTRIBUTES"`
+	HistogramBuckets   []float64         `json:"histogramBuckets,omitempty" envconfig:"K6_OTEL_HISTOGRAM_BUCKETS"`
+}
+
+// NewConfig creates a new OpenTelemetry output config with the default values.
+func NewConfig() Config {
+	return Config{
+		Protocol:              null.NewString(protocolGRPC, false),
+		URLPath:               null.NewString("/v1/metrics", false),
+		Insecure:              null.NewBool(false, false),
+		TLSInsecureSkipVerify: null.NewBool(false, false),
+		Timeout:               types.NewNullDuration(10*time.Second, false),
+		PushInterval:          types.NewNullDuration(10*time.Second, false),
+		MetricPrefix:          null.NewString("k6_", false),
+
+		// The default boundaries of the OpenTelemetry SDKs, they work well
+		// enough for the durations in milliseconds that most Trends record.
+		HistogramBuckets: []float64{0, 5, 10, 25, 50, 75, 100, 250, 500, 750, 1000, 2500, 5000, 7500, 10000},
+	}
+}
+
+// Apply applies the valid config options to the receiver.
+func (c Config) Apply(cfg Config) Config {
+	if cfg.Protocol.Valid {
+		c.Protocol = cfg.Protocol
+	}
+	if cfg.Endpoint.Valid {
+		c.Endpoint = cfg.Endpoint
+	}
+	if cfg.URLPath.Valid {
+		c.URLPath = cfg.URLPath
+	}
+	if len(cfg.Headers) > 0 {
+		c.Headers = cfg.Headers
+	}
+	if cfg.Insecure.Valid {
+		c.Insecure = cfg.Insecure
+	}
+	if cfg.TLSInsecureSkipVerify.Valid {
+		c.TLSInsecureSkipVerify = cfg.TLSInsecureSkipVerify
+	}
+	if cfg.TLSCACert.Valid {
+		c.TLSCACert = cfg.TLSCACert
+	}
+	if cfg.TLSClientCert.Valid {
+		c.TLSClientCert = cfg.TLSClientCert
+	}
+	if cfg.TLSClientKey.Valid {
+		c.TLSClientKey = cfg.TLSClientKey
+	}
+	if cfg.Timeout.Valid {
+		c.Timeout = cfg.Timeout
+	}
+	if cfg.PushInterval.Valid {
+		c.PushInterval = cfg.PushInterval
+	}
+	if cfg.MetricPrefix.Valid {
+		c.MetricPrefix = cfg.MetricPrefix
+	}
+	if len(cfg.ResourceAttributes) > 0 {
+		c.ResourceAttributes = cfg.ResourceAttributes
+	}
+	if len(cfg.HistogramBuckets) > 0 {
+		c.HistogramBuckets = cfg.HistogramBuckets
+	}
+	return c
+}
+
+// Validate checks that the config values are consistent.
+func (c Config) Validate() error {
+	if c.Protocol.String != protocolGRPC && c.Protocol.String != protocolHTTP {
+		return fmt.Errorf("invalid protocol '%s', use '%s' or '%s'", c.Protocol.String, protocolGRPC, protocolHTTP)
+	}
+	if c.Endpoint.String == "" {
+		return errors.New("the endpoint can't be empty")
+	}
+	if c.PushInterval.Duration <= 0 {
+		return errors.New("the push interval should be positive")
+	}
+	if c.Timeout.Duration <= 0 {
+		return errors.New("the timeout should be positive")
+	}
+	if c.TLSClientCert.String != "" && c.TLSClientKey.String == "" ||
+		c.TLSClientCert.String == "" && c.TLSClientKey.String != "" {
+		return errors.New("both the TLS client certificate and key should be specified")
+	}
+	for i := 1; i < len(c.HistogramBuckets); i++ {
+		if c.HistogramBuckets[i] <= c.HistogramBuckets[i-1] {
+			return errors.New("the histogram buckets should be in a strictly increasing order")
+		}
+	}
+	return nil
+}
+
+// tlsConfig builds the TLS configuration for the connection to the endpoint.
+// It returns nil if an insecure (plaintext) connection should be used.
+func (c Config) tlsConfig() (*tls.Config, error) {
+	if c.Insecure.Bool {
+		return nil, nil //nolint:nilnil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.TLSInsecureSkipVerify.Bool, //nolint:gosec
+		MinVersion:         tls.VersionTLS12,
+	}
+	if c.TLSCACert.String != "" {
+		pem, err := os.ReadFile(c.TLSCACert.String)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read the TLS CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("couldn't parse the TLS CA certificate '%s'", c.TLSCACert.String)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.TLSClientCert.String != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLSClientCert.String, c.TLSClientKey.String)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't load the TLS client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// ParseArg parses the output argument, which is the collector endpoint. It can
+// be either a `host:port` pair, used with the configured protocol, or a URL,
+// in which case the http(s) scheme selects the HTTP protocol and the path, if
+// any, overrides the default one.
+func ParseArg(arg string) (Config, error) {
+	c := Config{}
+	if arg == "" {
+		return c, nil
+	}
+	if !strings.Contains(arg, "://") {
+		c.Endpoint = null.StringFrom(arg)
+		return c, nil
+	}
+
+	u, err := url.Parse(arg)
+	if err != nil {
+		return c, err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		c.Protocol = null.StringFrom(protocolHTTP)
+	case "grpc", "grpcs":
+		c.Protocol = null.StringFrom(protocolGRPC)
+	default:
+		return c, fmt.Errorf("unsupported endpoint scheme '%s', use http(s) or grpc(s)", u.Scheme)
+	}
+	c.Insecure = null.BoolFrom(u.Scheme == "http" || u.Scheme == "grpc")
+	c.Endpoint = null.StringFrom(u.Host)
+	if u.Path != "" && u.Path != "/" {
+		c.URLPath = null.StringFrom(u.Path)
+	}
+	return c, nil
+}
+
+// GetConsolidatedConfig combines {default config values + JSON config +
+// environment vars + argument}, and returns the final result.
+func GetConsolidatedConfig(jsonRawConf json.RawMessage, env map[string]string, arg string) (Config, error) {
+	result := NewConfig()
+	if jsonRawConf != nil {
+		jsonConf := Config{}
+		if err := json.Unmarshal(jsonRawConf, &jsonConf); err != nil {
+			return result, err
+		}
+		result = result.Apply(jsonConf)
+	}
+
+	envConfig := Config{}
+	if err := envconfig.Process("", &envConfig, func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}); err != nil {
+		return result, err
+	}
+	result = result.Apply(envConfig)
+
+	argConf, err := ParseArg(arg)
+	if err != nil {
+		return result, err
+	}
+	result = result.Apply(argConf)
+
+	if !result.Endpoint.Valid {
+		result.Endpoint = null.NewString(defaultGRPCEndpoint, false)
+		if result.Protocol.String == protocolHTTP {
+			result.Endpoint = null.NewString(defaultHTTPEndpoint, false)
+		}
+	}
+
+	return result, result.Validate()
+}
@@ -0,0 +1,108 @@
+package opentelemetry
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib/types"
+)
+
+func TestParseArg(t *testing.T) {
+	t.Parallel()
+	testdata := map[string]Config{
+		"": {},
+		"collector:4317": {
+			Endpoint: null.StringFrom("collector:4317"),
+		},
+		"http://collector:4318": {
+			Protocol: null.StringFrom(protocolHTTP),
+			Endpoint: null.StringFrom("collector:4318"),
+			Insecure: null.BoolFrom(true),
+		},
+		"https://collector/otlp/v1/metrics": {
+			Protocol: null.StringFrom(protocolHTTP),
+			Endpoint: null.StringFrom("collector"),
+			URLPath:  null.StringFrom("/otlp/v1/metrics"),
+			Insecure: null.BoolFrom(false),
+		},
+		"grpc://collector:4317": {
+			Protocol: null.StringFrom(protocolGRPC),
+			Endpoint: null.StringFrom("collector:4317"),
+			Insecure: null.BoolFrom(true),
+		},
+	}
+	for arg, expected := range testdata {
+		arg, expected := arg, expected
+		t.Run(arg, func(t *testing.T) {
+			t.Parallel()
+			c, err := ParseArg(arg)
+			require.NoError(t, err)
+			assert.Equal(t, expected, c)
+		})
+	}
+
+	_, err := ParseArg("udp://collector:4317")
+	assert.EqualError(t, err, "unsupported endpoint scheme 'udp', use http(s) or grpc(s)")
+}
+
+func TestGetConsolidatedConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+		c, err := GetConsolidatedConfig(nil, nil, "")
+		require.NoError(t, err)
+		assert.Equal(t, protocolGRPC, c.Protocol.String)
+		assert.Equal(t, defaultGRPCEndpoint, c.Endpoint.String)
+		assert.Equal(t, 10*time.Second, c.PushInterval.TimeDuration())
+		assert.Equal(t, "k6_", c.MetricPrefix.String)
+
+		c, err = GetConsolidatedConfig(json.RawMessage(`{"protocol":"http"}`), nil, "")
+		require.NoError(t, err)
+		assert.Equal(t, defaultHTTPEndpoint, c.Endpoint.String)
+		assert.Equal(t, "/v1/metrics", c.URLPath.String)
+	})
+
+	t.Run("precedence", func(t *testing.T) {
+		t.Parallel()
+		jsonConf := json.RawMessage(`{
+			"endpoint": "json:4317",
+			"pushInterval": "3s",
+			"headers": {"Authorization": "Bearer token"},
+			"resourceAttributes": {"env": "json"}
+		}`)
+		env := map[string]string{
+			"K6_OTEL_PUSH_INTERVAL":       "5s",
+			"K6_OTEL_RESOURCE_ATTRIBUTES": "env:staging,team:perf",
+			"K6_OTEL_HISTOGRAM_BUCKETS":   "1,10,100",
+		}
+		c, err := GetConsolidatedConfig(jsonConf, env, "arg:4317")
+		require.NoError(t, err)
+		assert.Equal(t, null.StringFrom("arg:4317"), c.Endpoint)
+		assert.Equal(t, types.NullDurationFrom(5*time.Second), c.PushInterval)
+		assert.Equal(t, map[string]string{"Authorization": "Bearer token"}, c.Headers)
+		assert.Equal(t, map[string]string{"env": "staging", "team": "perf"}, c.ResourceAttributes)
+		assert.Equal(t, []float64{1, 10, 100}, c.HistogramBuckets)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		testdata := map[string]string{
+			`{"protocol":"udp"}`:                  "invalid protocol 'udp', use 'grpc' or 'http'",
+			`{"pushInterval":"-1s"}`:              "the push interval should be positive",
+			`{"tlsClientCert":"cert.pem"}`:        "both the TLS client certificate and key should be specified",
+			`{"histogramBuckets":[1, 100, 10]}`:   "the histogram buckets should be in a strictly increasing order",
+			`{"endpoint":"", "protocol":"grpc"}`:  "the endpoint can't be empty",
+			`{"timeout":"0s", "protocol":"grpc"}`: "the timeout should be positive",
+		}
+		for conf, expErr := range testdata {
+			_, err := GetConsolidatedConfig(json.RawMessage(conf), nil, "")
+			assert.EqualError(t, err, expErr, conf)
+		}
+	})
+}
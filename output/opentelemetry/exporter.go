@@ -0,0 +1,145 @@
+package opentelemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"go.k6.io/k6/lib/consts"
+)
+
+// exporter sends the OTLP metrics to a collector.
+type exporter interface {
+	export(ctx context.Context, req *collectorpb.ExportMetricsServiceRequest) error
+	close() error
+}
+
+func newExporter(conf Config) (exporter, error) {
+	tlsConfig, err := conf.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if conf.Protocol.String == protocolHTTP {
+		return newHTTPExporter(conf, tlsConfig), nil
+	}
+
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.NewClient(conf.Endpoint.String,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUserAgent("k6/"+consts.Version),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create the gRPC client: %w", err)
+	}
+	return &grpcExporter{
+		conn:    conn,
+		client:  collectorpb.NewMetricsServiceClient(conn),
+		headers: metadata.New(conf.Headers),
+	}, nil
+}
+
+// grpcExporter exports the metrics with the OTLP/gRPC protocol.
+type grpcExporter struct {
+	conn    *grpc.ClientConn
+	client  collectorpb.MetricsServiceClient
+	headers metadata.MD
+}
+
+func (e *grpcExporter) export(ctx context.Context, req *collectorpb.ExportMetricsServiceRequest) error {
+	ctx = metadata.NewOutgoingContext(ctx, e.headers)
+	res, err := e.client.Export(ctx, req)
+	if err != nil {
+		return err
+	}
+	return partialSuccessError(res.GetPartialSuccess())
+}
+
+func (e *grpcExporter) close() error {
+	return e.conn.Close()
+}
+
+// httpExporter exports the metrics with the OTLP/HTTP protocol, using the
+// binary protobuf encoding.
+type httpExporter struct {
+	client  *http.Client
+	url     string
+	headers map[string]string
+}
+
+func newHTTPExporter(conf Config, tlsConfig *tls.Config) *httpExporter {
+	scheme := "https"
+	if tlsConfig == nil {
+		scheme = "http"
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+	transport.TLSClientConfig = tlsConfig
+
+	u := url.URL{Scheme: scheme, Host: conf.Endpoint.String, Path: conf.URLPath.String}
+	return &httpExporter{
+		client:  &http.Client{Transport: transport},
+		url:     u.String(),
+		headers: conf.Headers,
+	}
+}
+
+func (e *httpExporter) export(ctx context.Context, req *collectorpb.ExportMetricsServiceRequest) error {
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range e.headers {
+		httpReq.Header.Set(k, v)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("User-Agent", "k6/"+consts.Version)
+
+	res, err := e.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("the collector responded with status %d: %s", res.StatusCode, bytes.TrimSpace(resBody))
+	}
+
+	exportRes := &collectorpb.ExportMetricsServiceResponse{}
+	if err := proto.Unmarshal(resBody, exportRes); err != nil {
+		// The response body is optional, so we only care about the status.
+		return nil //nolint:nilerr
+	}
+	return partialSuccessError(exportRes.GetPartialSuccess())
+}
+
+func (e *httpExporter) close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+func partialSuccessError(ps *collectorpb.ExportMetricsPartialSuccess) error {
+	if ps == nil || ps.GetRejectedDataPoints() == 0 {
+		return nil
+	}
+	return fmt.Errorf("the collector rejected %d data points: %s", ps.GetRejectedDataPoints(), ps.GetErrorMessage())
+}
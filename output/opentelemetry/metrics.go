@@ -0,0 +1,190 @@
+package opentelemetry
+
+import (
+	"sort"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+
+	"go.k6.io/k6/metrics"
+)
+
+// seriesState is the cumulative aggregation of the samples of a single time
+// series (i.e. a metric and tag set combination) since the start of the test.
+type seriesState struct {
+	attributes []*commonpb.KeyValue
+
+	value        float64 // the sum for Counters and the last value for Gauges
+	trues, total uint64  // for Rates
+
+	// For Trends.
+	count        uint64
+	sum          float64
+	min, max     float64
+	bucketCounts []uint64
+}
+
+// aggregator keeps the cumulative state of all time series, so the OTLP data
+// points can be exported with the cumulative temporality that is expected by
+// most backends.
+type aggregator struct {
+	prefix  string
+	buckets []float64
+	start   time.Time
+
+	series map[metrics.TimeSeries]*seriesState
+}
+
+func newAggregator(prefix string, buckets []float64, start time.Time) *aggregator {
+	return &aggregator{
+		prefix:  prefix,
+		buckets: buckets,
+		start:   start,
+		series:  make(map[metrics.TimeSeries]*seriesState),
+	}
+}
+
+// add aggregates the sample in the state of its time series.
+func (a *aggregator) add(sample metrics.Sample) {
+	state, ok := a.series[sample.TimeSeries]
+	if !ok {
+		state = &seriesState{attributes: tagsToAttributes(sample.Tags)}
+		if sample.Metric.Type == metrics.Trend {
+			state.bucketCounts = make([]uint64, len(a.buckets)+1)
+		}
+		a.series[sample.TimeSeries] = state
+	}
+
+	switch sample.Metric.Type {
+	case metrics.Counter:
+		state.value += sample.Value
+	case metrics.Gauge:
+		state.value = sample.Value
+	case metrics.Rate:
+		state.total++
+		if sample.Value != 0 {
+			state.trues++
+		}
+	case metrics.Trend:
+		if state.count == 0 || sample.Value < state.min {
+			state.min = sample.Value
+		}
+		if state.count == 0 || sample.Value > state.max {
+			state.max = sample.Value
+		}
+		state.count++
+		state.sum += sample.Value
+		// The OTLP bucket i counts the values in (bounds[i-1], bounds[i]].
+		state.bucketCounts[sort.SearchFloat64s(a.buckets, sample.Value)]++
+	}
+}
+
+// export converts the current state of all time series to OTLP metrics, one
+// per k6 metric and with a data point for each of its time series.
+func (a *aggregator) export(now time.Time) []*metricpb.Metric {
+	start, end := uint64(a.start.UnixNano()), uint64(now.UnixNano()) //nolint:gosec
+
+	byMetric := make(map[*metrics.Metric]*metricpb.Metric)
+	for ts, state := range a.series {
+		m, ok := byMetric[ts.Metric]
+		if !ok {
+			m = a.newMetric(ts.Metric)
+			byMetric[ts.Metric] = m
+		}
+
+		switch data := m.Data.(type) {
+		case *metricpb.Metric_Sum:
+			data.Sum.DataPoints = append(data.Sum.DataPoints, &metricpb.NumberDataPoint{
+				Attributes:        state.attributes,
+				StartTimeUnixNano: start,
+				TimeUnixNano:      end,
+				Value:             &metricpb.NumberDataPoint_AsDouble{AsDouble: state.value},
+			})
+		case *metricpb.Metric_Gauge:
+			value := state.value
+			if ts.Metric.Type == metrics.Rate && state.total > 0 {
+				value = float64(state.trues) / float64(state.total)
+			}
+			data.Gauge.DataPoints = append(data.Gauge.DataPoints, &metricpb.NumberDataPoint{
+				Attributes:   state.attributes,
+				TimeUnixNano: end,
+				Value:        &metricpb.NumberDataPoint_AsDouble{AsDouble: value},
+			})
+		case *metricpb.Metric_Histogram:
+			data.Histogram.DataPoints = append(data.Histogram.DataPoints, &metricpb.HistogramDataPoint{
+				Attributes:        state.attributes,
+				StartTimeUnixNano: start,
+				TimeUnixNano:      end,
+				Count:             state.count,
+				Sum:               &state.sum,
+				Min:               &state.min,
+				Max:               &state.max,
+				ExplicitBounds:    a.buckets,
+				BucketCounts:      append([]uint64(nil), state.bucketCounts...),
+			})
+		}
+	}
+
+	result := make([]*metricpb.Metric, 0, len(byMetric))
+	for _, m := range byMetric {
+		result = append(result, m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// newMetric returns an empty OTLP metric for the k6 one. Counters are mapped
+// to monotonic sums, Gauges and Rates to gauges and Trends to histograms.
+func (a *aggregator) newMetric(m *metrics.Metric) *metricpb.Metric {
+	result := &metricpb.Metric{
+		Name:        a.prefix + m.Name,
+		Description: "k6 " + m.Type.String() + " metric " + m.Name,
+		Unit:        metricUnit(m),
+	}
+
+	switch m.Type {
+	case metrics.Counter:
+		result.Data = &metricpb.Metric_Sum{Sum: &metricpb.Sum{
+			AggregationTemporality: metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			IsMonotonic:            true,
+		}}
+	case metrics.Trend:
+		result.Data = &metricpb.Metric_Histogram{Histogram: &metricpb.Histogram{
+			AggregationTemporality: metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		}}
+	default:
+		result.Data = &metricpb.Metric_Gauge{Gauge: &metricpb.Gauge{}}
+	}
+	return result
+}
+
+// metricUnit returns the UCUM unit of the metric values.
+func metricUnit(m *metrics.Metric) string {
+	switch {
+	case m.Contains == metrics.Time:
+		return "ms"
+	case m.Contains == metrics.Data:
+		return "By"
+	case m.Type == metrics.Rate:
+		return "1"
+	default:
+		return ""
+	}
+}
+
+func tagsToAttributes(tags *metrics.TagSet) []*commonpb.KeyValue {
+	return mapToAttributes(tags.Map())
+}
+
+func mapToAttributes(m map[string]string) []*commonpb.KeyValue {
+	attributes := make([]*commonpb.KeyValue, 0, len(m))
+	for k, v := range m {
+		attributes = append(attributes, &commonpb.KeyValue{
+			Key:   k,
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}},
+		})
+	}
+	sort.Slice(attributes, func(i, j int) bool { return attributes[i].Key < attributes[j].Key })
+	return attributes
+}
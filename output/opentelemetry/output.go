@@ -0,0 +1,139 @@
+// Package opentelemetry provides an output that exports the k6 metrics to an
+// OpenTelemetry collector, or any other backend that supports the OTLP metrics
+// protocol, over gRPC or HTTP.
+package opentelemetry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+
+	"go.k6.io/k6/lib/consts"
+	"go.k6.io/k6/output"
+)
+
+// Output exports the k6 metrics as OTLP sums, gauges and histograms.
+type Output struct {
+	output.SampleBuffer
+
+	config   Config
+	logger   logrus.FieldLogger
+	exporter exporter
+	resource *resourcepb.Resource
+
+	// The aggregator is only used by the flushing goroutine.
+	aggregator      *aggregator
+	periodicFlusher *output.PeriodicFlusher
+}
+
+var _ output.Output = new(Output)
+
+// New returns a new OpenTelemetry output.
+func New(params output.Params) (output.Output, error) {
+	return newOutput(params)
+}
+
+func newOutput(params output.Params) (*Output, error) {
+	conf, err := GetConsolidatedConfig(params.JSONConfig, params.Environment, params.ConfigArgument)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := map[string]string{
+		"service.name":    "k6",
+		"service.version": consts.Version,
+	}
+	for k, v := range conf.ResourceAttributes {
+		attributes[k] = v
+	}
+
+	return &Output{
+		config: conf,
+		logger: params.Logger.WithFields(logrus.Fields{
+			"output": "OpenTelemetry",
+		}),
+		resource: &resourcepb.Resource{Attributes: mapToAttributes(attributes)},
+	}, nil
+}
+
+// Description returns a human-readable description of the output.
+func (o *Output) Description() string {
+	return fmt.Sprintf("opentelemetry (%s %s)", o.config.Protocol.String, o.config.Endpoint.String)
+}
+
+// Start initializes the exporter and starts the goroutine for metric flushing.
+func (o *Output) Start() error {
+	o.logger.Debug("Starting...")
+	exp, err := newExporter(o.config)
+	if err != nil {
+		return err
+	}
+	o.exporter = exp
+	o.aggregator = newAggregator(o.config.MetricPrefix.String, o.config.HistogramBuckets, time.Now())
+
+	pf, err := output.NewPeriodicFlusher(o.config.PushInterval.TimeDuration(), o.flushMetrics)
+	if err != nil {
+		return err
+	}
+	o.logger.Debug("Started!")
+	o.periodicFlusher = pf
+
+	return nil
+}
+
+// Stop flushes any remaining metrics, stops the goroutine and closes the
+// connection to the collector.
+func (o *Output) Stop() error {
+	o.logger.Debug("Stopping...")
+	defer o.logger.Debug("Stopped!")
+	o.periodicFlusher.Stop()
+	return o.exporter.close()
+}
+
+func (o *Output) flushMetrics() {
+	samples := o.GetBufferedSamples()
+	for _, container := range samples {
+		for _, sample := range container.GetSamples() {
+			o.aggregator.add(sample)
+		}
+	}
+	if len(o.aggregator.series) == 0 {
+		return
+	}
+
+	req := o.newExportRequest(o.aggregator.export(time.Now()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.config.Timeout.TimeDuration())
+	defer cancel()
+
+	startTime := time.Now()
+	if err := o.exporter.export(ctx, req); err != nil {
+		o.logger.WithError(err).Error("Couldn't export the metrics")
+		return
+	}
+	t := time.Since(startTime)
+	o.logger.WithField("t", t).Debug("Metrics exported!")
+
+	if t > o.config.PushInterval.TimeDuration() {
+		o.logger.WithField("t", t).
+			Warn("The flush operation took higher than the expected set push interval. If you see this message multiple times then the setup or configuration need to be adjusted to achieve a sustainable rate.") //nolint:lll
+	}
+}
+
+func (o *Output) newExportRequest(ms []*metricpb.Metric) *collectorpb.ExportMetricsServiceRequest {
+	return &collectorpb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricpb.ResourceMetrics{{
+			Resource: o.resource,
+			ScopeMetrics: []*metricpb.ScopeMetrics{{
+				Scope:   &commonpb.InstrumentationScope{Name: "k6", Version: consts.Version},
+				Metrics: ms,
+			}},
+		}},
+	}
+}
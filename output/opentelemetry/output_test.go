@@ -0,0 +1,291 @@
+package opentelemetry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/output"
+)
+
+// collector is a minimal stand-in for an OpenTelemetry collector, which just
+// records the received export requests and headers.
+type collector struct {
+	collectorpb.UnimplementedMetricsServiceServer
+
+	mx       sync.Mutex
+	requests []*collectorpb.ExportMetricsServiceRequest
+	headers  []map[string]string
+}
+
+func (c *collector) Export(
+	ctx context.Context, req *collectorpb.ExportMetricsServiceRequest,
+) (*collectorpb.ExportMetricsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	headers := make(map[string]string)
+	for k, v := range md {
+		headers[k] = v[0]
+	}
+	c.record(req, headers)
+	return &collectorpb.ExportMetricsServiceResponse{}, nil
+}
+
+func (c *collector) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	req := &collectorpb.ExportMetricsServiceRequest{}
+	if err == nil {
+		err = proto.Unmarshal(body, req)
+	}
+	if err != nil || r.Header.Get("Content-Type") != "application/x-protobuf" || r.URL.Path != "/v1/metrics" {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	headers := make(map[string]string)
+	for k := range r.Header {
+		headers[k] = r.Header.Get(k)
+	}
+	c.record(req, headers)
+
+	res, _ := proto.Marshal(&collectorpb.ExportMetricsServiceResponse{})
+	rw.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = rw.Write(res)
+}
+
+func (c *collector) record(req *collectorpb.ExportMetricsServiceRequest, headers map[string]string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.requests = append(c.requests, req)
+	c.headers = append(c.headers, headers)
+}
+
+func (c *collector) last(t *testing.T) (*collectorpb.ExportMetricsServiceRequest, map[string]string) {
+	t.Helper()
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	require.NotEmpty(t, c.requests)
+	return c.requests[len(c.requests)-1], c.headers[len(c.headers)-1]
+}
+
+func startGRPCCollector(t *testing.T) (*collector, string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	c := &collector{}
+	srv := grpc.NewServer()
+	collectorpb.RegisterMetricsServiceServer(srv, c)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return c, lis.Addr().String()
+}
+
+func getTestSamples(t *testing.T) metrics.Samples {
+	t.Helper()
+	registry := metrics.NewRegistry()
+	reqs, err := registry.NewMetric("http_reqs", metrics.Counter)
+	require.NoError(t, err)
+	duration, err := registry.NewMetric("http_req_duration", metrics.Trend, metrics.Time)
+	require.NoError(t, err)
+	failed, err := registry.NewMetric("http_req_failed", metrics.Rate)
+	require.NoError(t, err)
+	vus, err := registry.NewMetric("vus", metrics.Gauge)
+	require.NoError(t, err)
+
+	now := time.Now()
+	var samples metrics.Samples
+	for i, status := range []string{"200", "200", "500"} {
+		tags := registry.RootTagSet().WithTagsFromMap(map[string]string{"status": status})
+		samples = append(samples,
+			metrics.Sample{TimeSeries: metrics.TimeSeries{Metric: reqs, Tags: tags}, Time: now, Value: 1},
+			metrics.Sample{TimeSeries: metrics.TimeSeries{Metric: duration, Tags: tags}, Time: now, Value: float64(i+1) * 40},
+			metrics.Sample{TimeSeries: metrics.TimeSeries{Metric: failed, Tags: tags}, Time: now, Value: float64(i % 2)},
+		)
+	}
+	for _, v := range []float64{3, 5} {
+		samples = append(samples, metrics.Sample{
+			TimeSeries: metrics.TimeSeries{Metric: vus, Tags: registry.RootTagSet()},
+			Time:       now,
+			Value:      v,
+		})
+	}
+	return samples
+}
+
+func findMetric(t *testing.T, req *collectorpb.ExportMetricsServiceRequest, name string) *metricpb.Metric {
+	t.Helper()
+	require.Len(t, req.ResourceMetrics, 1)
+	require.Len(t, req.ResourceMetrics[0].ScopeMetrics, 1)
+	for _, m := range req.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		if m.Name == name {
+			return m
+		}
+	}
+	require.Failf(t, "metric not found", "no metric %s in the request", name)
+	return nil
+}
+
+func attributesMap(attributes []*commonpb.KeyValue) map[string]string {
+	result := make(map[string]string, len(attributes))
+	for _, kv := range attributes {
+		result[kv.Key] = kv.Value.GetStringValue()
+	}
+	return result
+}
+
+func runOutput(t *testing.T, jsonConfig string, samples metrics.Samples) {
+	t.Helper()
+	out, err := New(output.Params{
+		Logger:     testutils.NewLogger(t),
+		JSONConfig: json.RawMessage(jsonConfig),
+	})
+	require.NoError(t, err)
+	require.NoError(t, out.Start())
+	out.AddMetricSamples([]metrics.SampleContainer{samples})
+	require.NoError(t, out.Stop())
+}
+
+func assertExportedMetrics(t *testing.T, req *collectorpb.ExportMetricsServiceRequest) {
+	t.Helper()
+
+	resource := attributesMap(req.ResourceMetrics[0].Resource.Attributes)
+	assert.Equal(t, "k6", resource["service.name"])
+	assert.Equal(t, "staging", resource["env"])
+
+	reqs := findMetric(t, req, "k6_http_reqs").GetSum()
+	require.NotNil(t, reqs)
+	assert.True(t, reqs.IsMonotonic)
+	assert.Equal(t, metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, reqs.AggregationTemporality)
+	values := make(map[string]float64)
+	for _, dp := range reqs.DataPoints {
+		values[attributesMap(dp.Attributes)["status"]] = dp.GetAsDouble()
+	}
+	assert.Equal(t, map[string]float64{"200": 2, "500": 1}, values)
+
+	vus := findMetric(t, req, "k6_vus").GetGauge()
+	require.NotNil(t, vus)
+	require.Len(t, vus.DataPoints, 1)
+	assert.Equal(t, 5.0, vus.DataPoints[0].GetAsDouble())
+
+	failed := findMetric(t, req, "k6_http_req_failed").GetGauge()
+	require.NotNil(t, failed)
+	rates := make(map[string]float64)
+	for _, dp := range failed.DataPoints {
+		rates[attributesMap(dp.Attributes)["status"]] = dp.GetAsDouble()
+	}
+	assert.Equal(t, map[string]float64{"200": 0.5, "500": 0}, rates)
+
+	durationMetric := findMetric(t, req, "k6_http_req_duration")
+	assert.Equal(t, "ms", durationMetric.Unit)
+	duration := durationMetric.GetHistogram()
+	require.NotNil(t, duration)
+	for _, dp := range duration.DataPoints {
+		if attributesMap(dp.Attributes)["status"] != "200" {
+			continue
+		}
+		assert.Equal(t, uint64(2), dp.Count)
+		assert.Equal(t, 120.0, dp.GetSum())
+		assert.Equal(t, 40.0, dp.GetMin())
+		assert.Equal(t, 80.0, dp.GetMax())
+		assert.Equal(t, []float64{50, 100}, dp.ExplicitBounds)
+		assert.Equal(t, []uint64{1, 1, 0}, dp.BucketCounts)
+	}
+}
+
+func TestOutputGRPC(t *testing.T) {
+	t.Parallel()
+	c, addr := startGRPCCollector(t)
+
+	runOutput(t, `{
+		"endpoint": "`+addr+`",
+		"insecure": true,
+		"headers": {"x-api-key": "secret"},
+		"resourceAttributes": {"env": "staging"},
+		"histogramBuckets": [50, 100]
+	}`, getTestSamples(t))
+
+	req, headers := c.last(t)
+	assert.Equal(t, "secret", headers["x-api-key"])
+	assertExportedMetrics(t, req)
+}
+
+func TestOutputHTTP(t *testing.T) {
+	t.Parallel()
+
+	for _, secure := range []bool{false, true} {
+		secure := secure
+		name := "plaintext"
+		if secure {
+			name = "tls"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c := &collector{}
+			var srv *httptest.Server
+			if secure {
+				srv = httptest.NewTLSServer(c)
+			} else {
+				srv = httptest.NewServer(c)
+			}
+			t.Cleanup(srv.Close)
+
+			runOutput(t, `{
+				"protocol": "http",
+				"endpoint": "`+srv.Listener.Addr().String()+`",
+				"insecure": `+map[bool]string{true: "false", false: "true"}[secure]+`,
+				"tlsInsecureSkipVerify": true,
+				"headers": {"X-Api-Key": "secret"},
+				"resourceAttributes": {"env": "staging"},
+				"histogramBuckets": [50, 100]
+			}`, getTestSamples(t))
+
+			req, headers := c.last(t)
+			assert.Equal(t, "secret", headers["X-Api-Key"])
+			assertExportedMetrics(t, req)
+		})
+	}
+}
+
+func TestOutputCumulative(t *testing.T) {
+	t.Parallel()
+	c, addr := startGRPCCollector(t)
+
+	out, err := newOutput(output.Params{
+		Logger:     testutils.NewLogger(t),
+		JSONConfig: json.RawMessage(`{"endpoint": "` + addr + `", "insecure": true, "pushInterval": "1h"}`),
+	})
+	require.NoError(t, err)
+	require.NoError(t, out.Start())
+
+	samples := getTestSamples(t)
+	out.AddMetricSamples([]metrics.SampleContainer{samples})
+	out.flushMetrics()
+	out.AddMetricSamples([]metrics.SampleContainer{samples})
+	require.NoError(t, out.Stop())
+
+	req, _ := c.last(t)
+	var total float64
+	for _, dp := range findMetric(t, req, "k6_http_reqs").GetSum().DataPoints {
+		total += dp.GetAsDouble()
+		assert.NotZero(t, dp.StartTimeUnixNano)
+		assert.Greater(t, dp.TimeUnixNano, dp.StartTimeUnixNano)
+	}
+	assert.Equal(t, 6.0, total)
+}
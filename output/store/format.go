@@ -0,0 +1,339 @@
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+// The metrics store file format is:
+//
+//	magic | version | record...  | footer offset | trailer magic
+//
+// Every record is a kind byte, the uvarint length of its payload and the
+// payload itself. The header and footer payloads are JSON, while the sample
+// blocks are zstd-compressed and columnar, i.e. all series IDs come first,
+// then all times, values and sample metadata. The definitions of the metrics
+// and time series that are first used in a block are written at its start,
+// so the samples can be read even from files that weren't finalized, e.g.
+// when k6 was killed. The footer and trailer are written only when the test
+// run finishes normally.
+const (
+	magic         = "K6MSTORE"
+	trailerMagic  = "K6MSTEND"
+	formatVersion = 1
+
+	recordHeader = 'H'
+	recordBlock  = 'B'
+	recordFooter = 'F'
+
+	trailerSize = 8 + len(trailerMagic)
+)
+
+// maxRecordSize protects the reader from allocating huge buffers for
+// corrupted files.
+const maxRecordSize = 1 << 30
+
+// Header contains the metadata about the test run that is written at the
+// start of every metrics store file.
+type Header struct {
+	K6Version  string      `json:"k6Version"`
+	StartTime  time.Time   `json:"startTime"`
+	ScriptPath string      `json:"scriptPath,omitempty"`
+	Options    lib.Options `json:"options"`
+}
+
+// Footer contains the metadata about the test run that is known only after
+// it finishes.
+type Footer struct {
+	EndTime time.Time    `json:"endTime"`
+	Samples uint64       `json:"samples"`
+	Metrics []MetricInfo `json:"metrics"`
+}
+
+// MetricInfo describes a metric that has samples in the file.
+type MetricInfo struct {
+	Name     string             `json:"name"`
+	Type     metrics.MetricType `json:"type"`
+	Contains metrics.ValueType  `json:"contains"`
+}
+
+// blockEncoder encodes samples to the uncompressed payloads of sample blocks.
+// It keeps track of the metrics and time series that were already defined in
+// previous blocks.
+type blockEncoder struct {
+	startTime time.Time
+	metricIDs map[*metrics.Metric]uint64
+	seriesIDs map[metrics.TimeSeries]uint64
+	metrics   []MetricInfo
+	buf       []byte
+}
+
+func newBlockEncoder(startTime time.Time) *blockEncoder {
+	return &blockEncoder{
+		startTime: startTime,
+		metricIDs: make(map[*metrics.Metric]uint64),
+		seriesIDs: make(map[metrics.TimeSeries]uint64),
+	}
+}
+
+func (e *blockEncoder) encode(samples []metrics.Sample) []byte {
+	var newMetrics []*metrics.Metric
+	var newSeries []metrics.TimeSeries
+	for _, s := range samples {
+		if _, ok := e.metricIDs[s.Metric]; !ok {
+			e.metricIDs[s.Metric] = uint64(len(e.metricIDs))
+			e.metrics = append(e.metrics, MetricInfo{Name: s.Metric.Name, Type: s.Metric.Type, Contains: s.Metric.Contains})
+			newMetrics = append(newMetrics, s.Metric)
+		}
+		if _, ok := e.seriesIDs[s.TimeSeries]; !ok {
+			e.seriesIDs[s.TimeSeries] = uint64(len(e.seriesIDs))
+			newSeries = append(newSeries, s.TimeSeries)
+		}
+	}
+
+	b := e.buf[:0]
+	b = binary.AppendUvarint(b, uint64(len(newMetrics)))
+	for _, m := range newMetrics {
+		b = appendString(b, m.Name)
+		b = append(b, byte(m.Type), byte(m.Contains))
+	}
+
+	b = binary.AppendUvarint(b, uint64(len(newSeries)))
+	for _, ts := range newSeries {
+		b = binary.AppendUvarint(b, e.metricIDs[ts.Metric])
+		b = appendStringMap(b, ts.Tags.Map())
+	}
+
+	b = binary.AppendUvarint(b, uint64(len(samples)))
+	for _, s := range samples {
+		b = binary.AppendUvarint(b, e.seriesIDs[s.TimeSeries])
+	}
+	// times are nanoseconds since the start of the test run, delta-encoded
+	var prev int64
+	for _, s := range samples {
+		t := s.Time.Sub(e.startTime).Nanoseconds()
+		b = binary.AppendVarint(b, t-prev)
+		prev = t
+	}
+	for _, s := range samples {
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(s.Value))
+	}
+	for _, s := range samples {
+		b = appendStringMap(b, s.Metadata)
+	}
+
+	e.buf = b
+	return b
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendStringMap(b []byte, m map[string]string) []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b = binary.AppendUvarint(b, uint64(len(keys)))
+	for _, k := range keys {
+		b = appendString(b, k)
+		b = appendString(b, m[k])
+	}
+	return b
+}
+
+var errCorruptedBlock = errors.New("corrupted sample block")
+
+// blockDecoder decodes the payloads of sample blocks. The metrics are
+// created in the given registry.
+type blockDecoder struct {
+	registry  *metrics.Registry
+	startTime time.Time
+	metrics   []*metrics.Metric
+	series    []metrics.TimeSeries
+}
+
+// payloadReader is a helper for reading the values of a block payload.
+type payloadReader struct {
+	b   []byte
+	err error
+}
+
+func (r *payloadReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.err = errCorruptedBlock
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *payloadReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.b)
+	if n <= 0 {
+		r.err = errCorruptedBlock
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *payloadReader) bytes(n uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if uint64(len(r.b)) < n {
+		r.err = errCorruptedBlock
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *payloadReader) string() string {
+	return string(r.bytes(r.uvarint()))
+}
+
+func (r *payloadReader) stringMap() map[string]string {
+	n := r.uvarint()
+	if n == 0 || r.err != nil {
+		return nil
+	}
+	m := make(map[string]string, n)
+	for i := uint64(0); i < n && r.err == nil; i++ {
+		k := r.string()
+		m[k] = r.string()
+	}
+	return m
+}
+
+// count reads a number of elements, which can't be more than the remaining
+// bytes, since every element takes at least a byte.
+func (r *payloadReader) count() int {
+	n := r.uvarint()
+	if n > uint64(len(r.b)) {
+		r.err = errCorruptedBlock
+		return 0
+	}
+	return int(n)
+}
+
+func (d *blockDecoder) decode(payload []byte) ([]metrics.Sample, error) {
+	r := &payloadReader{b: payload}
+
+	for i, n := 0, r.count(); i < n && r.err == nil; i++ {
+		name := r.string()
+		types := r.bytes(2)
+		if r.err != nil {
+			break
+		}
+		m, err := d.registry.NewMetric(name, metrics.MetricType(types[0]), metrics.ValueType(types[1]))
+		if err != nil {
+			return nil, err
+		}
+		d.metrics = append(d.metrics, m)
+	}
+
+	for i, n := 0, r.count(); i < n && r.err == nil; i++ {
+		metricID := r.uvarint()
+		tags := r.stringMap()
+		if r.err != nil {
+			break
+		}
+		if metricID >= uint64(len(d.metrics)) {
+			return nil, errCorruptedBlock
+		}
+		d.series = append(d.series, metrics.TimeSeries{
+			Metric: d.metrics[metricID],
+			Tags:   d.registry.RootTagSet().WithTagsFromMap(tags),
+		})
+	}
+
+	n := r.count()
+	samples := make([]metrics.Sample, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		seriesID := r.uvarint()
+		if seriesID >= uint64(len(d.series)) {
+			return nil, errCorruptedBlock
+		}
+		samples[i].TimeSeries = d.series[seriesID]
+	}
+	var t int64
+	for i := 0; i < n && r.err == nil; i++ {
+		t += r.varint()
+		samples[i].Time = d.startTime.Add(time.Duration(t))
+	}
+	for i := 0; i < n && r.err == nil; i++ {
+		if v := r.bytes(8); v != nil {
+			samples[i].Value = math.Float64frombits(binary.LittleEndian.Uint64(v))
+		}
+	}
+	for i := 0; i < n && r.err == nil; i++ {
+		samples[i].Metadata = r.stringMap()
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(r.b) != 0 {
+		return nil, errCorruptedBlock
+	}
+	return samples, nil
+}
+
+// writeRecord writes a single record with the given kind and payload.
+func writeRecord(w io.Writer, kind byte, payload []byte) error {
+	header := binary.AppendUvarint([]byte{kind}, uint64(len(payload)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// readRecord reads a single record and returns its kind and payload.
+func readRecord(r io.ByteReader, rd io.Reader) (byte, []byte, error) {
+	kind, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, nil, unexpectedEOF(err)
+	}
+	if size > maxRecordSize {
+		return 0, nil, fmt.Errorf("record with an invalid size %d", size)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(rd, payload); err != nil {
+		return 0, nil, unexpectedEOF(err)
+	}
+	return kind, payload, nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
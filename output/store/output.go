@@ -0,0 +1,206 @@
+// Package store implements an output that writes all metric samples to a
+// compact binary file, which can be read back after the test run to generate
+// its summary again, e.g. with different thresholds or for a subset of the
+// samples, without running the test again.
+package store
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/lib/consts"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/output"
+)
+
+// Bigger blocks are compressed better, so the samples are flushed less often
+// than in the other file outputs.
+const flushPeriod = 1 * time.Second
+
+// Output writes all metric samples to a metrics store file.
+type Output struct {
+	output.SampleBuffer
+
+	params          output.Params
+	periodicFlusher *output.PeriodicFlusher
+
+	logger   logrus.FieldLogger
+	filename string
+
+	file    io.WriteCloser
+	w       *countingWriter
+	bw      *bufio.Writer
+	zstd    *zstd.Encoder
+	encoder *blockEncoder
+	samples uint64
+	err     error
+}
+
+// New returns a new metrics store output.
+func New(params output.Params) (output.Output, error) {
+	if params.ConfigArgument == "" {
+		return nil, errors.New("the store output requires a file name, e.g. --out store=results.k6m")
+	}
+	return &Output{
+		params:   params,
+		filename: params.ConfigArgument,
+		logger: params.Logger.WithFields(logrus.Fields{
+			"output":   "store",
+			"filename": params.ConfigArgument,
+		}),
+	}, nil
+}
+
+// Description returns a human-readable description of the output.
+func (o *Output) Description() string {
+	return fmt.Sprintf("store (%s)", o.filename)
+}
+
+// Start creates the file, writes its header and starts the goroutine for
+// metric flushing.
+func (o *Output) Start() error {
+	o.logger.Debug("Starting...")
+
+	file, err := o.params.FS.Create(o.filename)
+	if err != nil {
+		return err
+	}
+	o.file = file
+	o.w = &countingWriter{w: file}
+	o.bw = bufio.NewWriter(o.w)
+
+	o.zstd, err = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	if err != nil {
+		return err
+	}
+
+	header := Header{
+		K6Version: consts.Version,
+		// without the monotonic clock reading, the sample times are encoded
+		// relative to the same wall clock time that is written in the file
+		StartTime: time.Now().Round(0),
+		Options:   o.params.ScriptOptions,
+	}
+	if o.params.ScriptPath != nil {
+		header.ScriptPath = o.params.ScriptPath.String()
+	}
+	o.encoder = newBlockEncoder(header.StartTime)
+
+	if err := o.writeHeader(header); err != nil {
+		return err
+	}
+
+	pf, err := output.NewPeriodicFlusher(flushPeriod, o.flushMetrics)
+	if err != nil {
+		return err
+	}
+	o.logger.Debug("Started!")
+	o.periodicFlusher = pf
+
+	return nil
+}
+
+// Stop flushes any remaining metrics, writes the footer and closes the file.
+func (o *Output) Stop() error {
+	o.logger.Debug("Stopping...")
+	defer o.logger.Debug("Stopped!")
+	o.periodicFlusher.Stop()
+
+	if o.err == nil {
+		o.err = o.writeFooter()
+	}
+	_ = o.zstd.Close()
+	if err := o.file.Close(); err != nil && o.err == nil {
+		o.err = err
+	}
+	return o.err
+}
+
+func (o *Output) writeHeader(header Header) error {
+	payload, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	if _, err := o.bw.WriteString(magic); err != nil {
+		return err
+	}
+	if err := o.bw.WriteByte(formatVersion); err != nil {
+		return err
+	}
+	if err := writeRecord(o.bw, recordHeader, payload); err != nil {
+		return err
+	}
+	return o.bw.Flush()
+}
+
+func (o *Output) writeFooter() error {
+	payload, err := json.Marshal(Footer{
+		EndTime: time.Now(),
+		Samples: o.samples,
+		Metrics: o.encoder.metrics,
+	})
+	if err != nil {
+		return err
+	}
+
+	offset := o.w.n + int64(o.bw.Buffered())
+	if err := writeRecord(o.bw, recordFooter, payload); err != nil {
+		return err
+	}
+	trailer := binary.LittleEndian.AppendUint64(nil, uint64(offset))
+	if _, err := o.bw.Write(append(trailer, trailerMagic...)); err != nil {
+		return err
+	}
+	return o.bw.Flush()
+}
+
+func (o *Output) flushMetrics() {
+	if o.err != nil {
+		return // the error was already logged, the file is probably unusable
+	}
+
+	var samples []metrics.Sample
+	for _, sc := range o.GetBufferedSamples() {
+		samples = append(samples, sc.GetSamples()...)
+	}
+	if len(samples) == 0 {
+		return
+	}
+
+	start := time.Now()
+	block := o.zstd.EncodeAll(o.encoder.encode(samples), nil)
+	if err := writeRecord(o.bw, recordBlock, block); err != nil {
+		o.err = err
+	} else {
+		o.err = o.bw.Flush()
+	}
+	if o.err != nil {
+		o.logger.WithError(o.err).Error("Couldn't write the metric samples to the file")
+		return
+	}
+	o.samples += uint64(len(samples))
+
+	o.logger.WithField("t", time.Since(start)).WithField("count", len(samples)).WithField("size", len(block)).
+		Debug("Wrote metrics to the file")
+}
+
+// countingWriter counts the bytes written to the file, so the offset of the
+// footer is known.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,185 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/output"
+)
+
+func generateTestSamples(t testing.TB) []metrics.Sample {
+	registry := metrics.NewRegistry()
+
+	metric1, err := registry.NewMetric("my_metric1", metrics.Gauge)
+	require.NoError(t, err)
+	metric2, err := registry.NewMetric("my_metric2", metrics.Trend, metrics.Time)
+	require.NoError(t, err)
+
+	now := time.Now()
+	return []metrics.Sample{
+		{
+			TimeSeries: metrics.TimeSeries{Metric: metric1, Tags: registry.RootTagSet().With("tag1", "val1")},
+			Time:       now,
+			Value:      1,
+			Metadata:   map[string]string{"meta1": "foo", "meta2": "bar"},
+		},
+		{
+			TimeSeries: metrics.TimeSeries{Metric: metric2, Tags: registry.RootTagSet()},
+			Time:       now.Add(-time.Second),
+			Value:      2.5,
+		},
+		{
+			TimeSeries: metrics.TimeSeries{Metric: metric1, Tags: registry.RootTagSet().With("tag1", "val1")},
+			Time:       now.Add(time.Second),
+			Value:      -3,
+		},
+		{
+			TimeSeries: metrics.TimeSeries{
+				Metric: metric2,
+				Tags:   registry.RootTagSet().With("tag1", "val2").With("tag2", "val3"),
+			},
+			Time:  now.Add(2 * time.Second),
+			Value: 4,
+		},
+	}
+}
+
+func writeTestFile(t testing.TB, fs fsext.Fs, filename string) []metrics.Sample {
+	out, err := New(output.Params{
+		Logger:         testutils.NewLogger(t),
+		FS:             fs,
+		ConfigArgument: filename,
+		ScriptOptions:  lib.Options{VUs: null.IntFrom(5)},
+	})
+	require.NoError(t, err)
+	require.NoError(t, out.Start())
+
+	samples := generateTestSamples(t)
+	out.AddMetricSamples([]metrics.SampleContainer{samples[0], samples[1]})
+	out.(*Output).flushMetrics() // so there are two blocks
+	out.AddMetricSamples([]metrics.SampleContainer{samples[2], samples[3]})
+	require.NoError(t, out.Stop())
+	return samples
+}
+
+func readAll(t testing.TB, r *Reader) []metrics.Sample {
+	var samples []metrics.Sample
+	for {
+		block, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return samples
+		}
+		require.NoError(t, err)
+		samples = append(samples, block...)
+	}
+}
+
+func assertSamplesEqual(t testing.TB, expected, actual []metrics.Sample) {
+	require.Len(t, actual, len(expected))
+	for i, exp := range expected {
+		act := actual[i]
+		assert.Equal(t, exp.Metric.Name, act.Metric.Name)
+		assert.Equal(t, exp.Metric.Type, act.Metric.Type)
+		assert.Equal(t, exp.Metric.Contains, act.Metric.Contains)
+		assert.Equal(t, exp.Tags.Map(), act.Tags.Map())
+		assert.True(t, exp.Time.Equal(act.Time), "%s != %s", exp.Time, act.Time)
+		assert.Equal(t, exp.Value, act.Value)
+		assert.Equal(t, exp.Metadata, act.Metadata)
+	}
+}
+
+func TestStoreOutputRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	fs := fsext.NewMemMapFs()
+	expected := writeTestFile(t, fs, "/results.k6m")
+
+	file, err := fs.Open("/results.k6m")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, file.Close()) }()
+
+	footer, err := ReadFooter(file)
+	require.NoError(t, err)
+	require.NotNil(t, footer)
+	assert.EqualValues(t, 4, footer.Samples)
+	assert.Equal(t, []MetricInfo{
+		{Name: "my_metric1", Type: metrics.Gauge, Contains: metrics.Default},
+		{Name: "my_metric2", Type: metrics.Trend, Contains: metrics.Time},
+	}, footer.Metrics)
+
+	_, err = file.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+
+	registry := metrics.NewRegistry()
+	r, err := NewReader(file, registry)
+	require.NoError(t, err)
+	defer r.Close()
+
+	assert.Equal(t, null.IntFrom(5), r.Header().Options.VUs)
+	assertSamplesEqual(t, expected, readAll(t, r))
+	require.NotNil(t, r.Footer())
+	assert.Equal(t, footer.Samples, r.Footer().Samples)
+	assert.NotNil(t, registry.Get("my_metric1"))
+}
+
+func TestStoreOutputUnfinalizedFile(t *testing.T) {
+	t.Parallel()
+
+	fs := fsext.NewMemMapFs()
+	expected := writeTestFile(t, fs, "/results.k6m")
+
+	data, err := fsext.ReadFile(fs, "/results.k6m")
+	require.NoError(t, err)
+	// cut the footer and trailer, as if k6 was killed during the test run
+	footerOffset := bytes.LastIndexByte(data[:len(data)-trailerSize], recordFooter)
+	require.Greater(t, footerOffset, 0)
+	data = data[:footerOffset]
+
+	footer, err := ReadFooter(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Nil(t, footer)
+
+	r, err := NewReader(bytes.NewReader(data), metrics.NewRegistry())
+	require.NoError(t, err)
+	defer r.Close()
+	assertSamplesEqual(t, expected, readAll(t, r))
+	assert.Nil(t, r.Footer())
+
+	// a partially written block is an error
+	r, err = NewReader(bytes.NewReader(data[:len(data)-3]), metrics.NewRegistry())
+	require.NoError(t, err)
+	defer r.Close()
+	_, err = r.Next()
+	require.NoError(t, err)
+	_, err = r.Next()
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestStoreOutputErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(output.Params{Logger: testutils.NewLogger(t)})
+	assert.ErrorContains(t, err, "the store output requires a file name")
+
+	out, err := New(output.Params{
+		Logger:         testutils.NewLogger(t),
+		FS:             fsext.NewReadOnlyFs(fsext.NewMemMapFs()),
+		ConfigArgument: "/results.k6m",
+	})
+	require.NoError(t, err)
+	assert.Error(t, out.Start())
+
+	_, err = NewReader(bytes.NewReader([]byte(`{"type":"Point"}`)), metrics.NewRegistry())
+	assert.ErrorContains(t, err, "the file isn't a k6 metrics store file")
+}
@@ -0,0 +1,155 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+
+	"go.k6.io/k6/metrics"
+)
+
+// Reader reads the samples from a metrics store file.
+type Reader struct {
+	br      *bufio.Reader
+	zstd    *zstd.Decoder
+	decoder *blockDecoder
+	header  Header
+	footer  *Footer
+}
+
+// NewReader reads the header of the metrics store file in r and returns a
+// Reader for its samples. The metrics of the samples are created in the
+// given registry.
+func NewReader(r io.Reader, registry *metrics.Registry) (*Reader, error) {
+	br := bufio.NewReader(r)
+
+	start := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(br, start); err != nil || string(start[:len(magic)]) != magic {
+		return nil, errors.New("the file isn't a k6 metrics store file")
+	}
+	if version := start[len(magic)]; version != formatVersion {
+		return nil, fmt.Errorf("unsupported metrics store file version %d", version)
+	}
+
+	kind, payload, err := readRecord(br, br)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the metrics store file header: %w", err)
+	}
+	if kind != recordHeader {
+		return nil, errors.New("the metrics store file doesn't start with a header")
+	}
+	var header Header
+	if err := json.Unmarshal(payload, &header); err != nil {
+		return nil, fmt.Errorf("couldn't parse the metrics store file header: %w", err)
+	}
+
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{
+		br:      br,
+		zstd:    dec,
+		decoder: &blockDecoder{registry: registry, startTime: header.StartTime},
+		header:  header,
+	}, nil
+}
+
+// Header returns the header of the file.
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Footer returns the footer of the file after all of its samples were read,
+// or nil if the file doesn't have one, e.g. because k6 was killed.
+func (r *Reader) Footer() *Footer {
+	return r.footer
+}
+
+// Next returns the samples in the next block of the file. It returns io.EOF
+// after the last one.
+func (r *Reader) Next() ([]metrics.Sample, error) {
+	for r.footer == nil {
+		kind, payload, err := readRecord(r.br, r.br)
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case recordBlock:
+			raw, err := r.zstd.DecodeAll(payload, nil)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't decompress a sample block: %w", err)
+			}
+			return r.decoder.decode(raw)
+		case recordFooter:
+			footer := &Footer{}
+			if err := json.Unmarshal(payload, footer); err != nil {
+				return nil, fmt.Errorf("couldn't parse the metrics store file footer: %w", err)
+			}
+			r.footer = footer
+		default:
+			// records from newer k6 versions that this one doesn't know about
+			continue
+		}
+	}
+	return nil, io.EOF
+}
+
+// Close releases the resources of the reader. It doesn't close the file.
+func (r *Reader) Close() {
+	r.zstd.Close()
+}
+
+// ReadFooter reads only the footer at the end of the given metrics store
+// file. It returns nil if the file doesn't have a footer.
+func ReadFooter(rs io.ReadSeeker) (*Footer, error) {
+	size, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if size < int64(len(magic)+1+trailerSize) {
+		return nil, nil //nolint:nilnil
+	}
+
+	trailer := make([]byte, trailerSize)
+	if _, err := rs.Seek(size-int64(trailerSize), io.SeekStart); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rs, trailer); err != nil {
+		return nil, err
+	}
+	if string(trailer[8:]) != trailerMagic {
+		return nil, nil //nolint:nilnil
+	}
+
+	offset := int64(binary.LittleEndian.Uint64(trailer[:8]))
+	if offset <= 0 || offset >= size-int64(trailerSize) {
+		return nil, errors.New("the metrics store file has an invalid footer offset")
+	}
+	if _, err := rs.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	record := make([]byte, size-int64(trailerSize)-offset)
+	if _, err := io.ReadFull(rs, record); err != nil {
+		return nil, err
+	}
+
+	br := bytes.NewReader(record)
+	kind, payload, err := readRecord(br, br)
+	if err != nil || kind != recordFooter {
+		return nil, errors.New("the metrics store file has an invalid footer")
+	}
+	footer := &Footer{}
+	if err := json.Unmarshal(payload, footer); err != nil {
+		return nil, fmt.Errorf("couldn't parse the metrics store file footer: %w", err)
+	}
+	return footer, nil
+}
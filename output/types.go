@@ -88,6 +88,14 @@ type WithStopWithTestError interface {
 	StopWithTestError(testRunErr error) error // nil testRunErr means error-free test run
 }
 
+// WithFlush is an output that buffers the samples it receives, and that can
+// process them synchronously when Flush() is called, e.g. so the metrics
+// engine has all the samples emitted so far before evaluating some conditions.
+type WithFlush interface {
+	Output
+	Flush()
+}
+
 // WithBuiltinMetrics means the output can receive the builtin metrics.
 type WithBuiltinMetrics interface {
 	Output
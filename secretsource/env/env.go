@@ -0,0 +1,43 @@
+// Package env implements a secret source reading the secrets from the
+// environment variables of the k6 process.
+package env
+
+import (
+	"errors"
+	"fmt"
+
+	"go.k6.io/k6/secretsource"
+)
+
+// Prefix is the prefix of the environment variables holding the secrets: the
+// secret with the key "token" is the value of the K6_SECRET_token variable.
+//
+// These variables are never exposed to the tests through __ENV, even when
+// the system environment variables are, so they never end up in archives.
+const Prefix = "K6_SECRET_"
+
+type source struct {
+	env map[string]string
+}
+
+// New returns a secret source reading the secrets from the environment.
+func New(params secretsource.Params) (secretsource.Source, error) {
+	if params.ConfigArgument != "" {
+		return nil, fmt.Errorf("the env secret source doesn't take any argument, got %q", params.ConfigArgument)
+	}
+
+	return &source{env: params.Environment}, nil
+}
+
+func (s *source) Description() string {
+	return fmt.Sprintf("environment variables %s*", Prefix)
+}
+
+func (s *source) Get(key string) (string, error) {
+	value, ok := s.env[Prefix+key]
+	if !ok {
+		return "", errors.New("no such environment variable " + Prefix + key)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,30 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/secretsource"
+)
+
+func TestEnvSource(t *testing.T) {
+	t.Parallel()
+
+	source, err := New(secretsource.Params{Environment: map[string]string{
+		"K6_SECRET_token": "s3cr3t",
+		"token":           "not a secret",
+	}})
+	require.NoError(t, err)
+
+	token, err := source.Get("token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", token)
+
+	_, err = source.Get("missing")
+	assert.ErrorContains(t, err, "no such environment variable K6_SECRET_missing")
+
+	_, err = New(secretsource.Params{ConfigArgument: "prefix=FOO_"})
+	assert.ErrorContains(t, err, "doesn't take any argument")
+}
@@ -0,0 +1,12 @@
+package secretsource
+
+import "go.k6.io/k6/ext"
+
+// Constructor returns an instance of a secret source extension module.
+type Constructor func(Params) (Source, error)
+
+// RegisterExtension registers the given secret source extension constructor. This
+// function panics if a module with the same name is already registered.
+func RegisterExtension(name string, c Constructor) {
+	ext.Register(name, ext.SecretSourceExtension, c)
+}
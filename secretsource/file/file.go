@@ -0,0 +1,200 @@
+// Package file implements a secret source reading the secrets from a local file,
+// encrypted with a passphrase.
+//
+// The file holds one key=value pair per line, and it's encrypted the same way as
+// by OpenSSL, with AES-256-CBC and a PBKDF2 key derivation:
+//
+//	openssl enc -aes-256-cbc -pbkdf2 -salt -in secrets.txt -out secrets.enc -pass env:K6_SECRETS_PASSPHRASE
+//
+// The encrypted file may also be base64 encoded, i.e. created with the -a option.
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/secretsource"
+)
+
+const (
+	// DefaultPassphraseEnv is the environment variable holding the passphrase of
+	// the file, unless another one is set with the passphraseEnv option.
+	DefaultPassphraseEnv = "K6_SECRETS_PASSPHRASE"
+
+	// defaultIterations is the number of PBKDF2 iterations used by OpenSSL by default.
+	defaultIterations = 10000
+
+	saltHeader = "Salted__"
+	saltSize   = 8
+	keySize    = 32
+)
+
+type source struct {
+	filename string
+	secrets  map[string]string
+}
+
+// New returns a secret source reading the secrets from the encrypted file set in
+// the config argument, either as the filename option or as the whole argument.
+//
+// The file is decrypted once, and its secrets are kept in memory.
+func New(params secretsource.Params) (secretsource.Source, error) {
+	filename, passphraseEnv, iterations, err := parseArg(params.ConfigArgument)
+	if err != nil {
+		return nil, err
+	}
+
+	passphrase, ok := params.Environment[passphraseEnv]
+	if !ok || passphrase == "" {
+		return nil, fmt.Errorf("the passphrase of the secrets file must be set with the %s environment variable",
+			passphraseEnv)
+	}
+
+	path := fsext.Abs(params.Pwd, filename)
+	data, err := fsext.ReadFile(params.FS, path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the secrets file: %w", err)
+	}
+
+	plaintext, err := decrypt(data, []byte(passphrase), iterations)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt the secrets file %q: %w", filename, err)
+	}
+
+	secrets, err := parseSecrets(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets file %q: %w", filename, err)
+	}
+
+	return &source{filename: filename, secrets: secrets}, nil
+}
+
+func parseArg(arg string) (filename, passphraseEnv string, iterations int, err error) {
+	passphraseEnv, iterations = DefaultPassphraseEnv, defaultIterations
+
+	if !strings.Contains(arg, "=") {
+		filename = arg
+	} else {
+		for _, pair := range strings.Split(arg, ",") {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return "", "", 0, fmt.Errorf("couldn't parse %q as argument for the file secret source", arg)
+			}
+
+			switch key {
+			case "filename":
+				filename = value
+			case "passphraseEnv":
+				passphraseEnv = value
+			case "iterations":
+				iterations, err = strconv.Atoi(value)
+				if err != nil || iterations < 1 {
+					return "", "", 0, fmt.Errorf("the iterations of the file secret source must be a positive number, got %q",
+						value)
+				}
+			default:
+				return "", "", 0, fmt.Errorf("unknown key %q as argument for the file secret source", key)
+			}
+		}
+	}
+
+	if filename == "" {
+		return "", "", 0, errors.New("the file secret source requires a filename")
+	}
+
+	return filename, passphraseEnv, iterations, nil
+}
+
+// decrypt decrypts the data encrypted by OpenSSL's enc command, with the
+// aes-256-cbc cipher and the pbkdf2 option.
+func decrypt(data, passphrase []byte, iterations int) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(saltHeader)) {
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(data)), ""))
+		if err != nil || !bytes.HasPrefix(decoded, []byte(saltHeader)) {
+			return nil, errors.New("it isn't a salted file encrypted with OpenSSL's enc command")
+		}
+		data = decoded
+	}
+
+	if len(data) < len(saltHeader)+saltSize {
+		return nil, errors.New("the encrypted data is truncated")
+	}
+	salt := data[len(saltHeader) : len(saltHeader)+saltSize]
+	ciphertext := data[len(saltHeader)+saltSize:]
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, errors.New("the encrypted data is truncated")
+	}
+
+	derived := pbkdf2.Key(passphrase, salt, iterations, keySize+aes.BlockSize, sha256.New)
+	block, err := aes.NewCipher(derived[:keySize])
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, derived[keySize:]).CryptBlocks(plaintext, ciphertext)
+
+	// An invalid padding is what a wrong passphrase most likely results in.
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, errors.New("bad decrypt, the passphrase may be wrong")
+	}
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		if int(b) != padding {
+			return nil, errors.New("bad decrypt, the passphrase may be wrong")
+		}
+	}
+
+	return plaintext[:len(plaintext)-padding], nil
+}
+
+// parseSecrets parses the key=value pairs of the decrypted file, ignoring the
+// empty lines and the comments starting with #.
+//
+// The errors never include the content of the lines, as it's likely a secret.
+func parseSecrets(data []byte) (map[string]string, error) {
+	secrets := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, "=")
+		if key = strings.TrimSpace(key); !ok || key == "" {
+			return nil, fmt.Errorf("line %d isn't a key=value pair", line)
+		}
+		secrets[key] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+func (s *source) Description() string {
+	return fmt.Sprintf("file %s", s.filename)
+}
+
+func (s *source) Get(key string) (string, error) {
+	value, ok := s.secrets[key]
+	if !ok {
+		return "", errors.New("no such secret in the file")
+	}
+
+	return value, nil
+}
@@ -0,0 +1,146 @@
+package file
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/secretsource"
+)
+
+// encryptedSecrets was created with:
+//
+//	openssl enc -aes-256-cbc -pbkdf2 -salt -a -in secrets.txt -pass pass:correct-horse
+//
+// from a file holding a comment, the token=s3cr3t-t0k3n and password = hunter2 pairs,
+// and an empty line.
+const encryptedSecrets = `U2FsdGVkX19rjFs0Xasg/rj9vApR11OGNTB8qOcNSfYVVLeHB7Q0U97ZWR2ejYJO
+qT1iEGqmeJ3jff8RlZ/dP6pbgtr2xNNYPrMZWrMefY0=
+`
+
+// encryptedWithIterations was created, and then base64 encoded, with:
+//
+//	openssl enc -aes-256-cbc -pbkdf2 -iter 1000 -salt -in secrets.txt -pass pass:pw
+//
+// from a file holding the token=other pair.
+const encryptedWithIterations = "U2FsdGVkX181WNaH/+Bpy4zvV90TDMyFtO4fYu2IH5Q="
+
+func newTestParams(t *testing.T, arg string, env map[string]string) secretsource.Params {
+	t.Helper()
+
+	fs := fsext.NewMemMapFs()
+	require.NoError(t, fsext.WriteFile(fs, "/test/secrets.enc", []byte(encryptedSecrets), 0o644))
+
+	raw, err := base64.StdEncoding.DecodeString(encryptedWithIterations)
+	require.NoError(t, err)
+	require.NoError(t, fsext.WriteFile(fs, "/test/raw.enc", raw, 0o644))
+
+	require.NoError(t, fsext.WriteFile(fs, "/test/plain.txt", []byte("token=plain\n"), 0o644))
+
+	return secretsource.Params{
+		ConfigArgument: arg,
+		Environment:    env,
+		FS:             fs,
+		Pwd:            "/test",
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	t.Parallel()
+
+	source, err := New(newTestParams(t, "secrets.enc", map[string]string{DefaultPassphraseEnv: "correct-horse"}))
+	require.NoError(t, err)
+	assert.Equal(t, "file secrets.enc", source.Description())
+
+	token, err := source.Get("token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t-t0k3n", token)
+
+	password, err := source.Get("password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", password)
+
+	_, err = source.Get("missing")
+	assert.ErrorContains(t, err, "no such secret")
+}
+
+func TestFileSourceOptions(t *testing.T) {
+	t.Parallel()
+
+	source, err := New(newTestParams(t,
+		"filename=/test/raw.enc,passphraseEnv=MY_PASSPHRASE,iterations=1000",
+		map[string]string{"MY_PASSPHRASE": "pw"},
+	))
+	require.NoError(t, err)
+
+	token, err := source.Get("token")
+	require.NoError(t, err)
+	assert.Equal(t, "other", token)
+}
+
+func TestFileSourceErrors(t *testing.T) {
+	t.Parallel()
+
+	passphrase := map[string]string{DefaultPassphraseEnv: "correct-horse"}
+
+	tests := map[string]struct {
+		arg string
+		env map[string]string
+		err string
+	}{
+		"no filename": {
+			arg: "", env: passphrase,
+			err: "requires a filename",
+		},
+		"unknown option": {
+			arg: "filename=secrets.enc,foo=bar", env: passphrase,
+			err: `unknown key "foo"`,
+		},
+		"invalid iterations": {
+			arg: "filename=secrets.enc,iterations=-1", env: passphrase,
+			err: "must be a positive number",
+		},
+		"no passphrase": {
+			arg: "secrets.enc",
+			err: "K6_SECRETS_PASSPHRASE environment variable",
+		},
+		"missing file": {
+			arg: "missing.enc", env: passphrase,
+			err: "unable to read the secrets file",
+		},
+		"not encrypted": {
+			arg: "plain.txt", env: passphrase,
+			err: "isn't a salted file encrypted with OpenSSL",
+		},
+		"wrong passphrase": {
+			arg: "secrets.enc", env: map[string]string{DefaultPassphraseEnv: "wrong"},
+			err: "the passphrase may be wrong",
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(newTestParams(t, tc.arg, tc.env))
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestParseSecrets(t *testing.T) {
+	t.Parallel()
+
+	secrets, err := parseSecrets([]byte("a=1\r\n# comment\n\n b = 2=3 \nempty=\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2=3", "empty": ""}, secrets)
+
+	// The content of the invalid line, likely a secret, isn't part of the error.
+	_, err = parseSecrets([]byte("a=1\ns3cr3t\n"))
+	require.Error(t, err)
+	assert.Equal(t, "line 2 isn't a key=value pair", err.Error())
+}
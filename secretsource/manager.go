@@ -0,0 +1,84 @@
+package secretsource
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Manager hands out the secrets of the configured sources to the tests, and
+// keeps track of their values so that they are redacted from k6's outputs.
+type Manager struct {
+	sources       map[string]Source
+	defaultSource string
+	redactor      *Redactor
+}
+
+// NewManager returns a manager for the given sources, keyed by their names. The
+// default source is the one used when a test doesn't ask for a specific source,
+// it must be one of the given sources, unless there is none.
+func NewManager(sources map[string]Source, defaultSource string) (*Manager, error) {
+	if len(sources) > 0 {
+		if _, ok := sources[defaultSource]; !ok {
+			return nil, fmt.Errorf("the default secret source %q isn't one of the configured sources", defaultSource)
+		}
+	}
+
+	return &Manager{
+		sources:       sources,
+		defaultSource: defaultSource,
+		redactor:      NewRedactor(),
+	}, nil
+}
+
+// Get returns the value of the secret with the given key, from the source with
+// the given name, or from the default source if the name is empty.
+//
+// The value is redacted from everything logged from then on.
+func (m *Manager) Get(sourceName, key string) (string, error) {
+	if len(m.sources) == 0 {
+		return "", errors.New("no secret sources are configured, use the --secret-source flag to add one")
+	}
+	if key == "" {
+		return "", errors.New("the key of the secret can't be empty")
+	}
+
+	if sourceName == "" {
+		sourceName = m.defaultSource
+	}
+	source, ok := m.sources[sourceName]
+	if !ok {
+		return "", fmt.Errorf("no secret source named %q, the configured ones are: %s", sourceName, strings.Join(m.SourceNames(), ", "))
+	}
+
+	value, err := source.Get(key)
+	if err != nil {
+		return "", fmt.Errorf("unable to get the secret %q from the %q source: %w", key, sourceName, err)
+	}
+	m.redactor.Add(value)
+
+	return value, nil
+}
+
+// HasSource returns true if there is a source with the given name.
+func (m *Manager) HasSource(name string) bool {
+	_, ok := m.sources[name]
+	return ok
+}
+
+// SourceNames returns the sorted names of the configured sources.
+func (m *Manager) SourceNames() []string {
+	names := make([]string, 0, len(m.sources))
+	for name := range m.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Redactor returns the redactor of the values of the secrets fetched so far.
+func (m *Manager) Redactor() *Redactor {
+	return m.redactor
+}
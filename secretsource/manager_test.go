@@ -0,0 +1,70 @@
+package secretsource
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mapSource map[string]string
+
+func (s mapSource) Description() string {
+	return "map"
+}
+
+func (s mapSource) Get(key string) (string, error) {
+	value, ok := s[key]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return value, nil
+}
+
+func TestManager(t *testing.T) {
+	t.Parallel()
+
+	m, err := NewManager(map[string]Source{
+		"first":  mapSource{"token": "first-token"},
+		"second": mapSource{"token": "second-token"},
+	}, "second")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"first", "second"}, m.SourceNames())
+	assert.True(t, m.HasSource("first"))
+	assert.False(t, m.HasSource("third"))
+
+	token, err := m.Get("", "token")
+	require.NoError(t, err)
+	assert.Equal(t, "second-token", token)
+
+	token, err = m.Get("first", "token")
+	require.NoError(t, err)
+	assert.Equal(t, "first-token", token)
+
+	// Only the fetched values are redacted.
+	assert.Equal(t, RedactedValue+" "+RedactedValue+" other", m.Redactor().Redact("first-token second-token other"))
+
+	_, err = m.Get("third", "token")
+	assert.ErrorContains(t, err, `no secret source named "third", the configured ones are: first, second`)
+
+	_, err = m.Get("first", "missing")
+	assert.ErrorContains(t, err, `unable to get the secret "missing" from the "first" source: not found`)
+
+	_, err = m.Get("", "")
+	assert.ErrorContains(t, err, "can't be empty")
+}
+
+func TestManagerWithoutSources(t *testing.T) {
+	t.Parallel()
+
+	m, err := NewManager(nil, "")
+	require.NoError(t, err)
+
+	_, err = m.Get("", "token")
+	assert.ErrorContains(t, err, "no secret sources are configured")
+
+	_, err = NewManager(map[string]Source{"first": mapSource{}}, "second")
+	assert.ErrorContains(t, err, `the default secret source "second" isn't one of the configured sources`)
+}
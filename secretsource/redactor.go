@@ -0,0 +1,164 @@
+package secretsource
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RedactedValue replaces the values of the secrets in k6's outputs.
+const RedactedValue = "***SECRET_REDACTED***"
+
+// Redactor replaces the values of the secrets with [RedactedValue].
+//
+// It's also a logrus hook, redacting the messages and the fields of the log
+// entries, before they are written or sent anywhere.
+type Redactor struct {
+	mu       sync.RWMutex
+	values   map[string]struct{}
+	replacer *strings.Replacer
+}
+
+var _ logrus.Hook = &Redactor{}
+
+// NewRedactor returns a redactor without any value to redact.
+func NewRedactor() *Redactor {
+	return &Redactor{values: make(map[string]struct{})}
+}
+
+// Add adds a value to be redacted, along with its encoded forms, see [encodedValues].
+func (r *Redactor) Add(value string) {
+	if value == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.values[value]; ok {
+		return
+	}
+	r.values[value] = struct{}{}
+	for _, encoded := range encodedValues(value) {
+		r.values[encoded] = struct{}{}
+	}
+
+	// The longest values come first, so that a value containing another one is
+	// entirely redacted.
+	values := make([]string, 0, len(r.values))
+	for v := range r.values {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+
+	oldnew := make([]string, 0, 2*len(values))
+	for _, v := range values {
+		oldnew = append(oldnew, v, RedactedValue)
+	}
+	r.replacer = strings.NewReplacer(oldnew...)
+}
+
+// minEncodedLength is the minimum length of the encoded forms of a value that
+// are redacted, the shorter ones being too likely to be part of unrelated text.
+const minEncodedLength = 4
+
+// encodedValues returns the forms of the given value that can be found in the
+// requests and responses, e.g. in the dumps of httpDebug: URL-escaped, or
+// base64-encoded like in a Basic Authorization header.
+//
+// The value is rarely encoded alone in base64, e.g. it's preceded by the
+// username in Basic authorization headers. So, for each of the three possible
+// alignments of the value in the encoded data, only the characters that depend
+// on the value's bytes alone are returned.
+func encodedValues(value string) []string {
+	var encoded []string
+	add := func(v string) {
+		if v != value && len(v) >= minEncodedLength {
+			encoded = append(encoded, v)
+		}
+	}
+
+	add(url.QueryEscape(value))
+	add(url.PathEscape(value))
+
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding} {
+		for shift := 0; shift < 3; shift++ {
+			data := encoding.EncodeToString(append(make([]byte, shift), value...))
+			// Each character encodes 6 bits, the first ones may include the
+			// preceding bytes, and the last ones the following bytes.
+			start := (8*shift + 5) / 6
+			end := 8 * (shift + len(value)) / 6
+			if start < end {
+				add(data[start:end])
+			}
+		}
+	}
+
+	return encoded
+}
+
+// Redact returns the given string, with the values of the secrets redacted.
+func (r *Redactor) Redact(s string) string {
+	r.mu.RLock()
+	replacer := r.replacer
+	r.mu.RUnlock()
+
+	if replacer == nil {
+		return s
+	}
+
+	return replacer.Replace(s)
+}
+
+// Levels implements the logrus.Hook interface, the entries of all the levels are redacted.
+func (r *Redactor) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements the logrus.Hook interface, it redacts the entry's message and fields.
+func (r *Redactor) Fire(entry *logrus.Entry) error {
+	entry.Message = r.Redact(entry.Message)
+
+	// The fields are a copy, specific to the entry being logged, so they can be modified.
+	for key, value := range entry.Data {
+		var s string
+		switch v := value.(type) {
+		case string:
+			s = v
+		case error:
+			s = v.Error()
+		case fmt.Stringer:
+			s = v.String()
+		default:
+			continue
+		}
+
+		if redacted := r.Redact(s); redacted != s {
+			entry.Data[key] = redacted
+		}
+	}
+
+	return nil
+}
+
+// AddHook adds the redactor as the first hook of the given logger, so that the
+// entries are redacted before any other hook gets them, e.g. to send them to Loki.
+func (r *Redactor) AddHook(logger *logrus.Logger) {
+	hooks := make(logrus.LevelHooks)
+	hooks.Add(r)
+	for level, levelHooks := range logger.Hooks {
+		hooks[level] = append(hooks[level], levelHooks...)
+	}
+
+	logger.ReplaceHooks(hooks)
+}
@@ -0,0 +1,109 @@
+package secretsource
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"go.k6.io/k6/lib/testutils"
+)
+
+func TestRedactorRedact(t *testing.T) {
+	t.Parallel()
+
+	r := NewRedactor()
+	assert.Equal(t, "nothing to redact", r.Redact("nothing to redact"))
+
+	r.Add("secret")
+	r.Add("a-secret-token")
+	r.Add("")
+	r.Add("secret")
+
+	assert.Equal(t,
+		"the ***SECRET_REDACTED*** and the ***SECRET_REDACTED***, but not the token",
+		r.Redact("the secret and the a-secret-token, but not the token"),
+	)
+}
+
+func TestRedactorRedactEncoded(t *testing.T) {
+	t.Parallel()
+
+	r := NewRedactor()
+	r.Add("s3cr3t/p4ss?")
+
+	// the secret is redacted whatever its alignment in the base64-encoded data
+	for _, username := range []string{"u", "us", "user"} {
+		for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding} {
+			encoded := encoding.EncodeToString([]byte(username + ":s3cr3t/p4ss?"))
+			redacted := r.Redact("Authorization: Basic " + encoded)
+			assert.Contains(t, redacted, RedactedValue, encoded)
+			assert.NotContains(t, redacted, encoded)
+		}
+	}
+
+	assert.Equal(t, "/login?password="+RedactedValue+"&user=me",
+		r.Redact("/login?password="+url.QueryEscape("s3cr3t/p4ss?")+"&user=me"))
+	assert.Equal(t, "/users/"+RedactedValue, r.Redact("/users/"+url.PathEscape("s3cr3t/p4ss?")))
+	assert.Equal(t, "nothing to redact", r.Redact("nothing to redact"))
+}
+
+func TestRedactorConcurrentUse(t *testing.T) {
+	t.Parallel()
+
+	r := NewRedactor()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			r.Add("secret")
+		}()
+		go func() {
+			defer wg.Done()
+			_ = r.Redact("secret")
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, RedactedValue, r.Redact("secret"))
+}
+
+func TestRedactorHook(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	// The redactor comes before the hooks added beforehand.
+	hook := testutils.NewLogHook(logrus.InfoLevel)
+	logger.AddHook(hook)
+
+	r := NewRedactor()
+	r.AddHook(logger)
+	r.Add("s3cr3t")
+
+	logger.WithField("token", "s3cr3t").WithError(errors.New("invalid s3cr3t")).WithField("n", 42).
+		Info("the token is s3cr3t")
+
+	assert.NotContains(t, buf.String(), "s3cr3t")
+	assert.Contains(t, buf.String(), `"msg":"the token is ***SECRET_REDACTED***"`)
+
+	entries := hook.Drain()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "the token is ***SECRET_REDACTED***", entries[0].Message)
+		assert.Equal(t, logrus.Fields{
+			"token": RedactedValue,
+			"error": "invalid " + RedactedValue,
+			"n":     42,
+		}, entries[0].Data)
+	}
+}
@@ -0,0 +1,37 @@
+// Package secretsource contains the interfaces that k6 secret sources (and secret
+// source extensions) have to implement, as well as the manager handing out their
+// secrets to the tests and redacting them from everything k6 outputs.
+package secretsource
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/lib/fsext"
+)
+
+// Params contains all possible constructor parameters a secret source may need.
+type Params struct {
+	// ConfigArgument is the part of the --secret-source $Type=$ConfigArgument flag
+	// specific to the source, i.e. without the name and default options.
+	ConfigArgument string
+
+	Logger      logrus.FieldLogger
+	Environment map[string]string
+	FS          fsext.Fs
+	Pwd         string
+}
+
+// A Source abstracts a store of secrets, such as an encrypted file or a vault.
+//
+// The values it returns are only known to the test which fetched them: k6
+// redacts them from its logs, its end-of-test summary, and never writes
+// them into archives.
+type Source interface {
+	// Description returns a human-readable description of the source that will be
+	// shown in `k6 run`. For extensions it probably should include the version as well.
+	Description() string
+
+	// Get returns the value of the secret with the given key. It may be called
+	// concurrently, and it's never called on the event loop, so it can block.
+	Get(key string) (string, error)
+}